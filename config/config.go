@@ -1,8 +1,13 @@
 package config
 
 import (
+	"fmt"
 	"os"
 	"path/filepath"
+	"slices"
+	"strings"
+	"syscall"
+	"time"
 
 	"gopkg.in/yaml.v3"
 )
@@ -59,6 +64,507 @@ type IntegrationsConfig struct {
 	GitHub *GitHubConfig `yaml:"github,omitempty" json:"github,omitempty"`
 }
 
+// LoggingConfig configures the log files written to ~/.config/maily/logs
+type LoggingConfig struct {
+	Level      string `yaml:"level" json:"level"`             // debug, info, warn, error
+	Format     string `yaml:"format" json:"format"`           // text or json
+	MaxSizeMB  int    `yaml:"max_size_mb" json:"max_size_mb"` // rotate when a log file exceeds this size
+	MaxBackups int    `yaml:"max_backups" json:"max_backups"` // number of rotated files to keep
+}
+
+// CardDAVConfig is an address book synced by `maily contacts sync`
+type CardDAVConfig struct {
+	Name     string `yaml:"name" json:"name"`
+	URL      string `yaml:"url" json:"url"`                               // address book collection URL
+	Username string `yaml:"username,omitempty" json:"username,omitempty"` // basic auth (Fastmail, Nextcloud)
+	Password string `yaml:"password,omitempty" json:"password,omitempty"` // app password
+	Token    string `yaml:"token,omitempty" json:"token,omitempty"`       // OAuth bearer token (Google)
+}
+
+// ContactsConfig configures address books used for recipient autocomplete
+type ContactsConfig struct {
+	CardDAV []CardDAVConfig `yaml:"carddav,omitempty" json:"carddav,omitempty"`
+}
+
+// CacheLimit bounds the email bodies kept in the local cache for a folder. Pruned emails
+// keep their metadata; their body is fetched again when opened. Zero means no limit.
+type CacheLimit struct {
+	MaxSizeMB  int `yaml:"max_size_mb,omitempty" json:"max_size_mb,omitempty"`   // bodies kept per account and folder
+	MaxAgeDays int `yaml:"max_age_days,omitempty" json:"max_age_days,omitempty"` // bodies of older emails are dropped
+}
+
+// CacheConfig sets cache limits for every folder, with per-folder overrides of single fields
+type CacheConfig struct {
+	CacheLimit `yaml:",inline"`
+	Folders    map[string]CacheLimit `yaml:"folders,omitempty" json:"folders,omitempty"` // keyed by mailbox name, e.g. INBOX
+}
+
+// Limit returns the cache limit for a folder: the folder's own fields, and the
+// defaults for those it leaves unset
+func (c *CacheConfig) Limit(mailbox string) CacheLimit {
+	if c == nil {
+		return CacheLimit{}
+	}
+	limit := c.CacheLimit
+	if folder, ok := c.Folders[mailbox]; ok {
+		if folder.MaxSizeMB != 0 {
+			limit.MaxSizeMB = folder.MaxSizeMB
+		}
+		if folder.MaxAgeDays != 0 {
+			limit.MaxAgeDays = folder.MaxAgeDays
+		}
+	}
+	return limit
+}
+
+// Bounds converts the limit into the receive-time cutoff and byte budget used for pruning
+func (l CacheLimit) Bounds(now time.Time) (olderThan time.Time, maxBytes int64) {
+	if l.MaxAgeDays > 0 {
+		olderThan = now.AddDate(0, 0, -l.MaxAgeDays)
+	}
+	return olderThan, int64(l.MaxSizeMB) * 1024 * 1024
+}
+
+// Retry defaults for queued operations that fail
+const (
+	DefaultOpMaxRetries = 5
+	DefaultOpBackoff    = 10 * time.Second
+	maxOpBackoff        = time.Hour
+)
+
+// PendingOpsConfig controls how queued operations (delete, move, mark read) are retried.
+// An operation that fails MaxRetries times is set aside for review in the TUI (o).
+type PendingOpsConfig struct {
+	MaxRetries     int `yaml:"max_retries,omitempty" json:"max_retries,omitempty"`         // attempts before giving up
+	BackoffSeconds int `yaml:"backoff_seconds,omitempty" json:"backoff_seconds,omitempty"` // wait after the first failure, doubled after each retry
+}
+
+// RetryPolicy returns the attempts allowed and the wait after an operation's nth failure
+func (c *PendingOpsConfig) RetryPolicy() (maxRetries int, backoff func(failures int) time.Duration) {
+	maxRetries, base := DefaultOpMaxRetries, DefaultOpBackoff
+	if c != nil && c.MaxRetries > 0 {
+		maxRetries = c.MaxRetries
+	}
+	if c != nil && c.BackoffSeconds > 0 {
+		base = time.Duration(c.BackoffSeconds) * time.Second
+	}
+	return maxRetries, func(failures int) time.Duration {
+		wait := base
+		for i := 1; i < failures && wait < maxOpBackoff; i++ {
+			wait *= 2
+		}
+		return min(wait, maxOpBackoff)
+	}
+}
+
+// Print formats; other formats are produced by the print command
+const (
+	PrintText = "text"
+	PrintHTML = "html"
+	PrintPDF  = "pdf"
+)
+
+// PrintConfig controls how emails are printed to files by `maily print` and p in the read view
+type PrintConfig struct {
+	Format  string `yaml:"format,omitempty" json:"format,omitempty"`   // text (default), html, or a format made by Command such as pdf
+	Command string `yaml:"command,omitempty" json:"command,omitempty"` // converts {input}, an HTML file, to {output}
+	Dir     string `yaml:"dir,omitempty" json:"dir,omitempty"`         // where files are written, defaults to ~/Downloads/maily
+}
+
+// OutputFormat returns the configured format, text when unset
+func (c *PrintConfig) OutputFormat() string {
+	if c == nil || c.Format == "" {
+		return PrintText
+	}
+	return c.Format
+}
+
+// Reply quoting styles
+const (
+	QuoteTop    = "top"    // reply above the quoted email
+	QuoteBottom = "bottom" // reply below the quoted email
+	QuoteNone   = "none"   // don't quote the email
+)
+
+// ReplyConfig controls how replies quote the email being replied to
+type ReplyConfig struct {
+	QuoteStyle     string `yaml:"quote_style,omitempty" json:"quote_style,omitempty"`         // top (default), bottom or none
+	MaxQuoteDepth  int    `yaml:"max_quote_depth,omitempty" json:"max_quote_depth,omitempty"` // quote levels kept, counting the replied email; 0 keeps all
+	StripSignature bool   `yaml:"strip_signature,omitempty" json:"strip_signature,omitempty"` // drop the text after a "-- " signature line
+}
+
+// Style returns the configured quoting style, top when unset
+func (c *ReplyConfig) Style() string {
+	if c == nil || c.QuoteStyle == "" {
+		return QuoteTop
+	}
+	return c.QuoteStyle
+}
+
+// SpellCheckConfig controls spell checking in the compose body
+type SpellCheckConfig struct {
+	Disabled   bool   `yaml:"disabled,omitempty" json:"disabled,omitempty"`
+	Dictionary string `yaml:"dictionary,omitempty" json:"dictionary,omitempty"` // hunspell dictionary such as en_GB; defaults to one for the UI language
+}
+
+// Enabled reports whether the compose body is spell checked, which it is unless disabled
+func (c *SpellCheckConfig) Enabled() bool {
+	return c == nil || !c.Disabled
+}
+
+// LinksConfig controls opening links from emails
+type LinksConfig struct {
+	TrustedSenders []string `yaml:"trusted_senders,omitempty" json:"trusted_senders,omitempty"` // addresses or @domains whose links open without asking
+}
+
+// Trusted reports whether links in mail from sender open without the confirmation dialog
+func (c *LinksConfig) Trusted(sender string) bool {
+	if c == nil {
+		return false
+	}
+	sender = strings.ToLower(sender)
+	for _, t := range c.TrustedSenders {
+		t = strings.ToLower(strings.TrimSpace(t))
+		if t == sender || (strings.HasPrefix(t, "@") && strings.HasSuffix(sender, t)) {
+			return true
+		}
+	}
+	return false
+}
+
+// TodayConfig controls the today dashboard
+type TodayConfig struct {
+	VIPs           []string `yaml:"vips,omitempty" json:"vips,omitempty"`                       // addresses or @domains whose mail is always important
+	UnreadOnly     bool     `yaml:"unread_only,omitempty" json:"unread_only,omitempty"`         // start with only unread emails shown
+	ImportantOnly  bool     `yaml:"important_only,omitempty" json:"important_only,omitempty"`   // start with only important emails shown
+	HiddenAccounts []string `yaml:"hidden_accounts,omitempty" json:"hidden_accounts,omitempty"` // accounts whose emails the dashboard leaves out
+}
+
+// HiddenAccount reports whether the dashboard leaves out the account's emails
+func (c *TodayConfig) HiddenAccount(account string) bool {
+	if c == nil {
+		return false
+	}
+	for _, a := range c.HiddenAccounts {
+		if strings.EqualFold(strings.TrimSpace(a), account) {
+			return true
+		}
+	}
+	return false
+}
+
+// VIP reports whether sender, a bare address, is one of the VIP senders
+func (c *TodayConfig) VIP(sender string) bool {
+	if c == nil {
+		return false
+	}
+	sender = strings.ToLower(sender)
+	for _, v := range c.VIPs {
+		v = strings.ToLower(strings.TrimSpace(v))
+		if v == sender || (strings.HasPrefix(v, "@") && strings.HasSuffix(sender, v)) {
+			return true
+		}
+	}
+	return false
+}
+
+// DigestConfig has the server mail the daily digest, as `maily digest --send-to` does
+type DigestConfig struct {
+	SendTo  string `yaml:"send_to,omitempty" json:"send_to,omitempty"` // recipients, comma-separated; empty doesn't send
+	At      string `yaml:"at,omitempty" json:"at,omitempty"`           // time of day as HH:MM; defaults to 07:00
+	Account string `yaml:"account,omitempty" json:"account,omitempty"` // account sending it; defaults to the first
+}
+
+// Scheduled reports whether the server mails the digest
+func (c *DigestConfig) Scheduled() bool {
+	return c != nil && strings.TrimSpace(c.SendTo) != ""
+}
+
+// Due returns when the digest is mailed on now's day; an invalid time falls back to 07:00
+func (c *DigestConfig) Due(now time.Time) time.Time {
+	hour, minute := 7, 0
+	if c != nil && c.At != "" {
+		if t, err := time.Parse("15:04", c.At); err == nil {
+			hour, minute = t.Hour(), t.Minute()
+		}
+	}
+	return time.Date(now.Year(), now.Month(), now.Day(), hour, minute, 0, 0, now.Location())
+}
+
+// Date styles
+const (
+	DatesRelative = "relative" // "5m ago", "yesterday" and weekdays for the last week
+	DatesAbsolute = "absolute" // always the time or day
+)
+
+// DatesConfig controls how email dates are shown
+type DatesConfig struct {
+	Style string `yaml:"style,omitempty" json:"style,omitempty"` // relative (default) or absolute
+	Clock int    `yaml:"clock,omitempty" json:"clock,omitempty"` // 12 or 24; defaults to the language's clock
+}
+
+// Relative reports whether recent dates are shown as how long ago they were
+func (c *DatesConfig) Relative() bool {
+	return c == nil || c.Style != DatesAbsolute
+}
+
+// ClockHours returns 12 or 24 for the configured clock, 0 to follow the language
+func (c *DatesConfig) ClockHours() int {
+	if c == nil || (c.Clock != 12 && c.Clock != 24) {
+		return 0
+	}
+	return c.Clock
+}
+
+// Mail list row densities
+const (
+	DensityCompact = "compact" // one line per email
+	DensityRelaxed = "relaxed" // a blank line between emails
+)
+
+// ListColumn is one column of the mail list: checkbox, flags, account, from, subject,
+// snippet, size or date
+type ListColumn struct {
+	Name  string `yaml:"name" json:"name"`
+	Width int    `yaml:"width,omitempty" json:"width,omitempty"` // in characters; 0 uses the column's default, subject and snippet then share the free space
+}
+
+// ListConfig controls which columns the mail list shows, how tightly rows are packed and
+// how each folder is sorted
+type ListConfig struct {
+	Columns []ListColumn      `yaml:"columns,omitempty" json:"columns,omitempty"` // in display order; defaults to checkbox, flags, from, subject, date
+	Density string            `yaml:"density,omitempty" json:"density,omitempty"` // compact (default) or relaxed
+	Sort    map[string]string `yaml:"sort,omitempty" json:"sort,omitempty"`       // keyed by mailbox name: date (default), size, sender, subject or unread
+
+	HideAvatars bool `yaml:"hide_avatars,omitempty" json:"hide_avatars,omitempty"` // no sender initials in list rows and the read header
+}
+
+// Avatars reports whether senders' coloured initials are drawn, which they are unless hidden
+func (c *ListConfig) Avatars() bool {
+	return c == nil || !c.HideAvatars
+}
+
+// Relaxed reports whether the list leaves a blank line between emails
+func (c *ListConfig) Relaxed() bool {
+	return c != nil && c.Density == DensityRelaxed
+}
+
+// SortOrder returns how a folder is sorted, empty for newest first
+func (c *ListConfig) SortOrder(mailbox string) string {
+	if c == nil {
+		return ""
+	}
+	return c.Sort[mailbox]
+}
+
+// SetSortOrder remembers how a folder is sorted; newest first, the default, isn't stored
+func (c *ListConfig) SetSortOrder(mailbox, order string) {
+	if order == "" || order == "date" {
+		delete(c.Sort, mailbox)
+		return
+	}
+	if c.Sort == nil {
+		c.Sort = make(map[string]string)
+	}
+	c.Sort[mailbox] = order
+}
+
+// ListColumns returns the configured columns, nil for the default layout
+func (c *ListConfig) ListColumns() []ListColumn {
+	if c == nil {
+		return nil
+	}
+	return c.Columns
+}
+
+// Preview pane positions
+const (
+	PreviewRight  = "right"  // beside the list
+	PreviewBottom = "bottom" // below the list
+)
+
+// PreviewConfig controls the preview pane shown beside or below the mail list
+type PreviewConfig struct {
+	Enabled  bool   `yaml:"enabled,omitempty" json:"enabled,omitempty"`   // shown at startup; toggle with v
+	Position string `yaml:"position,omitempty" json:"position,omitempty"` // right (default) or bottom
+	Ratio    int    `yaml:"ratio,omitempty" json:"ratio,omitempty"`       // percent of the screen kept for the list, 20-80; defaults to 50
+	Lines    int    `yaml:"lines,omitempty" json:"lines,omitempty"`       // body lines shown; defaults to 20
+}
+
+// Shown reports whether the preview pane is open at startup
+func (c *PreviewConfig) Shown() bool {
+	return c != nil && c.Enabled
+}
+
+// Below reports whether the preview pane goes below the list instead of beside it
+func (c *PreviewConfig) Below() bool {
+	return c != nil && c.Position == PreviewBottom
+}
+
+// ListPercent returns the share of the width, or height when below, kept for the list
+func (c *PreviewConfig) ListPercent() int {
+	if c == nil || c.Ratio == 0 {
+		return 50
+	}
+	return min(80, max(20, c.Ratio))
+}
+
+// BodyLines returns how many lines of the body the preview shows
+func (c *PreviewConfig) BodyLines() int {
+	if c == nil || c.Lines <= 0 {
+		return 20
+	}
+	return c.Lines
+}
+
+// CalendarConfig controls the calendar view
+type CalendarConfig struct {
+	SearchDays    int               `yaml:"search_days,omitempty" json:"search_days,omitempty"`       // days before and after today searched with /; defaults to 365
+	Colors        map[string]string `yaml:"colors,omitempty" json:"colors,omitempty"`                 // calendar name to "#RRGGBB", in place of the calendar's own color
+	TravelMinutes int               `yaml:"travel_minutes,omitempty" json:"travel_minutes,omitempty"` // time to get to events with a location; reminders fire that much earlier
+	Travel        map[string]int    `yaml:"travel,omitempty" json:"travel,omitempty"`                 // minutes to a place named in the location, in place of travel_minutes
+	WeekStart     string            `yaml:"week_start,omitempty" json:"week_start,omitempty"`         // monday or sunday; defaults to the language's first day
+	WeekNumbers   bool              `yaml:"week_numbers,omitempty" json:"week_numbers,omitempty"`     // ISO week numbers beside the month grid
+}
+
+// First days of the week
+const (
+	WeekStartMonday = "monday"
+	WeekStartSunday = "sunday"
+)
+
+// FirstWeekday returns the configured first day of the week, "" to follow the language
+func (c *CalendarConfig) FirstWeekday() string {
+	if c == nil {
+		return ""
+	}
+	switch day := strings.ToLower(c.WeekStart); day {
+	case WeekStartMonday, WeekStartSunday:
+		return day
+	}
+	return ""
+}
+
+// ShowWeekNumbers reports whether the month grid shows ISO week numbers
+func (c *CalendarConfig) ShowWeekNumbers() bool {
+	return c != nil && c.WeekNumbers
+}
+
+// SearchRange returns how many days before and after today calendar search covers
+func (c *CalendarConfig) SearchRange() int {
+	if c == nil || c.SearchDays <= 0 {
+		return 365
+	}
+	return c.SearchDays
+}
+
+// Color returns the color configured for the named calendar, matching the name without
+// regard to case; "" when there's none
+func (c *CalendarConfig) Color(name string) string {
+	if c == nil {
+		return ""
+	}
+	for calendar, color := range c.Colors {
+		if strings.EqualFold(calendar, name) {
+			return color
+		}
+	}
+	return ""
+}
+
+// TravelTime returns how long it takes to get to an event's location: the minutes set
+// for a place named in it (the longest name when several are), else travel_minutes
+func (c *CalendarConfig) TravelTime(location string) time.Duration {
+	if c == nil || strings.TrimSpace(location) == "" {
+		return 0
+	}
+	minutes, matched := c.TravelMinutes, ""
+	for place, m := range c.Travel {
+		if len(place) > len(matched) && strings.Contains(strings.ToLower(location), strings.ToLower(place)) {
+			minutes, matched = m, place
+		}
+	}
+	return time.Duration(max(minutes, 0)) * time.Minute
+}
+
+// Retention rule actions
+const (
+	RetentionDelete  = "delete"  // move to trash
+	RetentionArchive = "archive" // move to the archive folder
+)
+
+// RetentionRule cleans up cached mail that matches every set condition and is older than OlderThanDays.
+// Rules only run during sync once enabled; preview them with `maily retention`.
+type RetentionRule struct {
+	Name          string `yaml:"name" json:"name"`
+	Enabled       bool   `yaml:"enabled" json:"enabled"`
+	Action        string `yaml:"action" json:"action"` // delete or archive
+	OlderThanDays int    `yaml:"older_than_days" json:"older_than_days"`
+	Account       string `yaml:"account,omitempty" json:"account,omitempty"` // empty means all accounts
+	Mailbox       string `yaml:"mailbox,omitempty" json:"mailbox,omitempty"` // defaults to INBOX
+	From          string `yaml:"from,omitempty" json:"from,omitempty"`       // substring of the From header
+	Subject       string `yaml:"subject,omitempty" json:"subject,omitempty"` // substring of the subject
+	List          string `yaml:"list,omitempty" json:"list,omitempty"`       // substring of the List-Id, or "*" for any mailing list
+}
+
+// SmartFolder is a saved filter listed with the folders, showing the cached emails of a
+// mailbox that match every condition set on it
+type SmartFolder struct {
+	Name        string `yaml:"name" json:"name"`
+	Account     string `yaml:"account,omitempty" json:"account,omitempty"`         // empty means all accounts
+	Mailbox     string `yaml:"mailbox,omitempty" json:"mailbox,omitempty"`         // defaults to INBOX
+	From        string `yaml:"from,omitempty" json:"from,omitempty"`               // substring of the From header
+	Subject     string `yaml:"subject,omitempty" json:"subject,omitempty"`         // substring of the subject
+	Unread      bool   `yaml:"unread,omitempty" json:"unread,omitempty"`           // only unread emails
+	Attachments bool   `yaml:"attachments,omitempty" json:"attachments,omitempty"` // only emails with attachments
+	Days        int    `yaml:"days,omitempty" json:"days,omitempty"`               // only emails from the last this many days
+}
+
+// FolderMailbox returns the mailbox the smart folder filters
+func (f SmartFolder) FolderMailbox() string {
+	if f.Mailbox == "" {
+		return "INBOX"
+	}
+	return f.Mailbox
+}
+
+// AppliesTo reports whether the smart folder is shown for the given account
+func (f SmartFolder) AppliesTo(account string) bool {
+	return f.Account == "" || strings.EqualFold(f.Account, account)
+}
+
+// Hook events
+const (
+	HookNewMail       = "new_mail"       // unread mail arrived in the inbox
+	HookImportantMail = "important_mail" // unread mail from a VIP or marked important by Gmail arrived
+	HookSyncError     = "sync_error"     // a background sync failed
+	HookEventSoon     = "event_soon"     // a calendar event starts within Minutes
+)
+
+// Hook runs a command or POSTs to a URL when the server sees an event, with the event
+// as JSON: on the command's stdin, or as the request body
+type Hook struct {
+	Event   string `yaml:"event" json:"event"`                         // one of the Hook* events
+	Command string `yaml:"command,omitempty" json:"command,omitempty"` // run with sh -c
+	URL     string `yaml:"url,omitempty" json:"url,omitempty"`
+	Account string `yaml:"account,omitempty" json:"account,omitempty"` // mail events of this account only; empty means all
+	Minutes int    `yaml:"minutes,omitempty" json:"minutes,omitempty"` // for event_soon: how long before the start; defaults to 10
+}
+
+// Lead returns how long before an event's start an event_soon hook runs
+func (h Hook) Lead() time.Duration {
+	if h.Minutes <= 0 {
+		return 10 * time.Minute
+	}
+	return time.Duration(h.Minutes) * time.Minute
+}
+
+// AppliesTo reports whether the hook runs for an event of the account; events without
+// an account, like event_soon, match every hook
+func (h Hook) AppliesTo(account string) bool {
+	return h.Account == "" || account == "" || strings.EqualFold(h.Account, account)
+}
+
 type Config struct {
 	MaxEmails    int    `yaml:"max_emails" json:"max_emails"`
 	DefaultLabel string `yaml:"default_label" json:"default_label"`
@@ -74,6 +580,70 @@ type Config struct {
 
 	// External integrations
 	Integrations *IntegrationsConfig `yaml:"integrations,omitempty" json:"integrations,omitempty"`
+
+	// Log level, format and rotation
+	Logging *LoggingConfig `yaml:"logging,omitempty" json:"logging,omitempty"`
+
+	// Address books synced for recipient autocomplete
+	Contacts *ContactsConfig `yaml:"contacts,omitempty" json:"contacts,omitempty"`
+
+	// Mailing list IDs (List-Id) hidden from the inbox; still shown in the newsletters view
+	MutedLists []string `yaml:"muted_lists,omitempty" json:"muted_lists,omitempty"`
+
+	// Saved filters listed with the folders
+	SmartFolders []SmartFolder `yaml:"smart_folders,omitempty" json:"smart_folders,omitempty"`
+
+	// Auto-cleanup rules evaluated by the server after each sync
+	Retention []RetentionRule `yaml:"retention,omitempty" json:"retention,omitempty"`
+
+	// Size and age limits for cached email bodies, applied after each sync
+	Cache *CacheConfig `yaml:"cache,omitempty" json:"cache,omitempty"`
+
+	// Retry policy for queued operations
+	PendingOps *PendingOpsConfig `yaml:"pending_ops,omitempty" json:"pending_ops,omitempty"`
+
+	// Printing emails to text, HTML or PDF files
+	Print *PrintConfig `yaml:"print,omitempty" json:"print,omitempty"`
+
+	// How replies quote the email being replied to
+	Reply *ReplyConfig `yaml:"reply,omitempty" json:"reply,omitempty"`
+
+	// Signature added below the cursor in new emails and replies, after a "-- " line
+	Signature string `yaml:"signature,omitempty" json:"signature,omitempty"`
+
+	// Look up recipient domains' mail servers (MX records) before sending
+	CheckRecipientDomains bool `yaml:"check_recipient_domains,omitempty" json:"check_recipient_domains,omitempty"`
+
+	// Spell checking in the compose body
+	SpellCheck *SpellCheckConfig `yaml:"spell_check,omitempty" json:"spell_check,omitempty"`
+
+	// Opening links from emails
+	Links *LinksConfig `yaml:"links,omitempty" json:"links,omitempty"`
+
+	// Relative or absolute dates and the clock they use
+	Dates *DatesConfig `yaml:"dates,omitempty" json:"dates,omitempty"`
+
+	// Columns and row density of the mail list
+	List *ListConfig `yaml:"list,omitempty" json:"list,omitempty"`
+
+	// Preview pane beside or below the mail list
+	Preview *PreviewConfig `yaml:"preview,omitempty" json:"preview,omitempty"`
+
+	// Calendar search, colors, travel times and weeks
+	Calendar *CalendarConfig `yaml:"calendar,omitempty" json:"calendar,omitempty"`
+
+	// Filters of the today dashboard's emails
+	Today *TodayConfig `yaml:"today,omitempty" json:"today,omitempty"`
+
+	// Morning digest of events, important mail and follow-ups, mailed by the server
+	Digest *DigestConfig `yaml:"digest,omitempty" json:"digest,omitempty"`
+
+	// Commands and webhooks the server runs on new mail, sync errors and upcoming events
+	Hooks []Hook `yaml:"hooks,omitempty" json:"hooks,omitempty"`
+
+	// Named groups of accounts; only accounts in the active profiles are shown and synced
+	Profiles       []Profile `yaml:"profiles,omitempty" json:"profiles,omitempty"`
+	ActiveProfiles []string  `yaml:"active_profiles,omitempty" json:"active_profiles,omitempty"`
 }
 
 func DefaultConfig() Config {
@@ -84,41 +654,110 @@ func DefaultConfig() Config {
 	}
 }
 
+// Load reads config.yml. Settings that can fall back to their defaults don't stop it;
+// LoadWithWarnings lists them. Fatal problems return the config along with a
+// *ValidationError listing them.
 func Load() (Config, error) {
-	configDir, err := getConfigDir()
+	cfg, _, err := LoadWithWarnings()
+	return cfg, err
+}
+
+// LoadWithWarnings reads config.yml like Load and also returns the invalid settings it
+// replaced with their defaults
+func LoadWithWarnings() (Config, []Problem, error) {
+	configPath, err := Path()
 	if err != nil {
-		return DefaultConfig(), err
+		return DefaultConfig(), nil, err
 	}
 
-	configPath := filepath.Join(configDir, configFileName)
+	cfg, err := read(configPath)
+	if err != nil {
+		return DefaultConfig(), nil, err
+	}
+	var warnings, fatal []Problem
+	for _, problem := range cfg.Validate() {
+		if problem.Fatal {
+			fatal = append(fatal, problem)
+		} else {
+			warnings = append(warnings, problem)
+		}
+	}
+	applyDefaults(&cfg)
+	if len(fatal) > 0 {
+		return cfg, warnings, &ValidationError{Path: configPath, Problems: fatal}
+	}
+	return cfg, warnings, nil
+}
+
+// read decodes config.yml as written, without defaults; a missing file is the default
+// config
+func read(configPath string) (Config, error) {
 	data, err := os.ReadFile(configPath)
 	if err != nil {
 		if os.IsNotExist(err) {
 			return DefaultConfig(), nil
 		}
-		return DefaultConfig(), err
+		return Config{}, err
 	}
-
 	var cfg Config
 	if err := yaml.Unmarshal(data, &cfg); err != nil {
-		return DefaultConfig(), err
+		return Config{}, fmt.Errorf("%s: %w", configPath, err)
 	}
+	return cfg, nil
+}
 
-	// Apply defaults for zero values
-	if cfg.MaxEmails == 0 {
+// applyDefaults fills in unset and invalid top-level settings
+func applyDefaults(cfg *Config) {
+	if cfg.MaxEmails <= 0 {
 		cfg.MaxEmails = 50
 	}
 	if cfg.DefaultLabel == "" {
 		cfg.DefaultLabel = "INBOX"
 	}
-	if cfg.Theme == "" {
+	if !slices.Contains(Themes, cfg.Theme) {
 		cfg.Theme = "default"
 	}
+}
 
+func (c Config) Save() error {
+	unlock, err := lock()
+	if err != nil {
+		return err
+	}
+	defer unlock()
+	return c.save()
+}
+
+// Update applies a change to config.yml as it is on disk and saves it. Several maily
+// instances may run at once, and each saving its own copy of the config would undo the
+// settings the others saved since it loaded. The rest of the file is saved as written,
+// invalid settings included, rather than with the defaults Load puts in their place.
+func Update(change func(*Config)) (Config, error) {
+	unlock, err := lock()
+	if err != nil {
+		return Config{}, err
+	}
+	defer unlock()
+
+	configPath, err := Path()
+	if err != nil {
+		return Config{}, err
+	}
+	cfg, err := read(configPath)
+	if err != nil {
+		return cfg, err
+	}
+	change(&cfg)
+	if err := cfg.save(); err != nil {
+		return cfg, err
+	}
+	applyDefaults(&cfg)
 	return cfg, nil
 }
 
-func (c Config) Save() error {
+// save writes config.yml through a temporary file, so other instances never read it
+// half written; the caller holds the lock
+func (c Config) save() error {
 	configDir, err := getConfigDir()
 	if err != nil {
 		return err
@@ -134,7 +773,42 @@ func (c Config) Save() error {
 		return err
 	}
 
-	return os.WriteFile(configPath, data, 0600)
+	tmp, err := os.CreateTemp(configDir, configFileName+".*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), configPath)
+}
+
+// lock takes the lock serializing changes to config.yml between processes
+func lock() (unlock func(), err error) {
+	configDir, err := getConfigDir()
+	if err != nil {
+		return nil, err
+	}
+	if err := os.MkdirAll(configDir, 0700); err != nil {
+		return nil, err
+	}
+	f, err := os.OpenFile(filepath.Join(configDir, "config.lock"), os.O_CREATE|os.O_RDWR, 0600)
+	if err != nil {
+		return nil, err
+	}
+	if err := syscall.Flock(int(f.Fd()), syscall.LOCK_EX); err != nil {
+		f.Close()
+		return nil, err
+	}
+	return func() {
+		syscall.Flock(int(f.Fd()), syscall.LOCK_UN)
+		f.Close()
+	}, nil
 }
 
 func getConfigDir() (string, error) {
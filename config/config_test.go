@@ -0,0 +1,40 @@
+package config
+
+import (
+	"testing"
+
+	"gopkg.in/yaml.v3"
+)
+
+func TestCacheLimit(t *testing.T) {
+	var cfg Config
+	data := `
+cache:
+  max_size_mb: 200
+  max_age_days: 90
+  folders:
+    "[Gmail]/All Mail":
+      max_age_days: 30
+    Archive:
+      max_size_mb: 50
+      max_age_days: 365
+`
+	if err := yaml.Unmarshal([]byte(data), &cfg); err != nil {
+		t.Fatal(err)
+	}
+	tests := map[string]CacheLimit{
+		"INBOX":            {MaxSizeMB: 200, MaxAgeDays: 90},
+		"[Gmail]/All Mail": {MaxSizeMB: 200, MaxAgeDays: 30},
+		"Archive":          {MaxSizeMB: 50, MaxAgeDays: 365},
+	}
+	for mailbox, want := range tests {
+		if got := cfg.Cache.Limit(mailbox); got != want {
+			t.Errorf("Limit(%q) = %+v, want %+v", mailbox, got, want)
+		}
+	}
+
+	var none *CacheConfig
+	if got := none.Limit("INBOX"); got != (CacheLimit{}) {
+		t.Errorf("Limit() without a cache section = %+v, want no limit", got)
+	}
+}
@@ -0,0 +1,60 @@
+package config
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// ProfileAll is the pseudo-profile that selects every account
+const ProfileAll = "all"
+
+// Profile groups a subset of accounts under a name (e.g. "work", "personal")
+type Profile struct {
+	Name     string   `yaml:"name" json:"name"`
+	Accounts []string `yaml:"accounts" json:"accounts"` // account emails
+}
+
+// GetProfile returns the profile with the given name, or nil if it doesn't exist
+func (c Config) GetProfile(name string) *Profile {
+	for i := range c.Profiles {
+		if c.Profiles[i].Name == name {
+			return &c.Profiles[i]
+		}
+	}
+	return nil
+}
+
+// AccountActive reports whether an account belongs to one of the active profiles.
+// Every account is active when no profile is selected.
+func (c Config) AccountActive(email string) bool {
+	if len(c.ActiveProfiles) == 0 {
+		return true
+	}
+	for _, name := range c.ActiveProfiles {
+		if p := c.GetProfile(name); p != nil && slices.Contains(p.Accounts, email) {
+			return true
+		}
+	}
+	return false
+}
+
+// SetActiveProfiles selects the given profiles; "all" clears the selection
+func (c *Config) SetActiveProfiles(names []string) error {
+	if len(names) == 0 || slices.Contains(names, ProfileAll) {
+		c.ActiveProfiles = nil
+		return nil
+	}
+	for _, name := range names {
+		if c.GetProfile(name) == nil {
+			return fmt.Errorf("unknown profile: %s", name)
+		}
+	}
+	c.ActiveProfiles = names
+	return nil
+}
+
+// ActiveProfileLabel returns the active profile names for display, empty when all accounts are shown
+func (c Config) ActiveProfileLabel() string {
+	return strings.Join(c.ActiveProfiles, ", ")
+}
@@ -0,0 +1,462 @@
+package config
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Themes lists the theme names config.yml may select
+var Themes = []string{"default"}
+
+// AICLIs lists the CLI tools an AI provider of type cli can name
+var AICLIs = []string{"claude", "codex", "gemini", "opencode", "crush", "mistral", "vibe", "ollama"}
+
+// Values accepted by settings whose meaning lives outside this package
+var (
+	logLevels   = []string{"debug", "info", "warn", "error"}
+	logFormats  = []string{"text", "json"}
+	listColumns = []string{"checkbox", "flags", "account", "from", "subject", "snippet", "size", "date"}
+	sortOrders  = []string{"date", "size", "sender", "subject", "unread"}
+	hookEvents  = []string{HookNewMail, HookImportantMail, HookSyncError, HookEventSoon}
+	hexColor    = regexp.MustCompile(`^#[0-9a-fA-F]{6}$`)
+)
+
+// Problem is one invalid setting in config.yml
+type Problem struct {
+	Field   string `json:"field,omitempty"` // path of the setting, e.g. ai_providers[1].model; empty for the whole file
+	Line    int    `json:"line,omitempty"`  // line in config.yml, 0 when unknown
+	Message string `json:"message"`
+	Fatal   bool   `json:"fatal,omitempty"` // maily can't go on with the default in its place
+}
+
+func (p Problem) String() string {
+	var b strings.Builder
+	if p.Line > 0 {
+		fmt.Fprintf(&b, "line %d: ", p.Line)
+	}
+	if p.Field != "" {
+		b.WriteString(p.Field + ": ")
+	}
+	b.WriteString(p.Message)
+	return b.String()
+}
+
+// ValidationError is returned by Load when config.yml has fatal problems. The config is
+// still loaded, for callers that only read settings unaffected by them.
+type ValidationError struct {
+	Path     string
+	Problems []Problem
+}
+
+func (e *ValidationError) Error() string {
+	msg := fmt.Sprintf("%s: %s", e.Path, e.Problems[0])
+	if len(e.Problems) > 1 {
+		msg += fmt.Sprintf(" (and %d more problems)", len(e.Problems)-1)
+	}
+	return msg + " - run 'maily config check' for details"
+}
+
+// Path returns where config.yml lives
+func Path() (string, error) {
+	configDir, err := getConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(configDir, configFileName), nil
+}
+
+// Check reads config.yml and returns every problem in it: YAML errors, unknown keys and
+// invalid values. A missing file has no problems.
+func Check() ([]Problem, error) {
+	configPath, err := Path()
+	if err != nil {
+		return nil, err
+	}
+	data, err := os.ReadFile(configPath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return checkData(data), nil
+}
+
+// checkData decodes strictly so misspelt keys are reported too, then validates the values
+func checkData(data []byte) []Problem {
+	var root yaml.Node
+	if err := yaml.Unmarshal(data, &root); err != nil {
+		return yamlProblems(err)
+	}
+
+	var cfg Config
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	decoder.KnownFields(true)
+	var problems []Problem
+	if err := decoder.Decode(&cfg); err != nil && !errors.Is(err, io.EOF) {
+		problems = yamlProblems(err)
+	}
+	for _, p := range cfg.Validate() {
+		p.Line = lineOf(&root, p.Field)
+		problems = append(problems, p)
+	}
+	slices.SortStableFunc(problems, func(a, b Problem) int { return a.Line - b.Line })
+	return problems
+}
+
+// yaml.v3 messages: the position, a key that isn't a setting and a value of the wrong type
+var (
+	yamlLine     = regexp.MustCompile(`^(?:yaml: )?line (\d+): `)
+	unknownField = regexp.MustCompile(`^field (\S+) not found in type \S+$`)
+	wrongType    = regexp.MustCompile("^cannot unmarshal !!\\w+ `(.*)` into (\\S+)$")
+)
+
+// typeNames describe the Go types of settings in the terms of config.yml
+var typeNames = map[string]string{
+	"int":    "a whole number",
+	"bool":   "true or false",
+	"string": "text",
+}
+
+// yamlProblems splits a decoding error into one problem per message
+func yamlProblems(err error) []Problem {
+	messages := []string{err.Error()}
+	var typeErr *yaml.TypeError
+	if errors.As(err, &typeErr) {
+		messages = typeErr.Errors
+	}
+	problems := make([]Problem, 0, len(messages))
+	for _, msg := range messages {
+		var p Problem
+		if m := yamlLine.FindStringSubmatch(msg); m != nil {
+			p.Line, _ = strconv.Atoi(m[1])
+			msg = msg[len(m[0]):]
+		}
+		p.Message = strings.TrimPrefix(msg, "yaml: ")
+		if m := unknownField.FindStringSubmatch(p.Message); m != nil {
+			p.Field, p.Message = m[1], "unknown setting, misspelt or misplaced?"
+		}
+		if m := wrongType.FindStringSubmatch(p.Message); m != nil {
+			want, ok := typeNames[m[2]]
+			if !ok {
+				want = "a list or section"
+				if m[2] == "[]string" {
+					want = "a list"
+				}
+			}
+			p.Message = fmt.Sprintf("%q should be %s", m[1], want)
+		}
+		problems = append(problems, p)
+	}
+	return problems
+}
+
+// lineOf finds the line of a setting, or of its closest parent when it isn't in the file
+func lineOf(root *yaml.Node, field string) int {
+	node := root
+	if node.Kind == yaml.DocumentNode && len(node.Content) > 0 {
+		node = node.Content[0]
+	}
+	line := 0
+	for _, part := range fieldParts(field) {
+		var next *yaml.Node
+		switch node.Kind {
+		case yaml.MappingNode:
+			for i := 0; i+1 < len(node.Content); i += 2 {
+				if node.Content[i].Value == part {
+					// Point at the key: the value of a list or map starts on a later line
+					line = node.Content[i].Line
+					next = node.Content[i+1]
+					break
+				}
+			}
+		case yaml.SequenceNode:
+			if i, err := strconv.Atoi(part); err == nil && i < len(node.Content) {
+				next = node.Content[i]
+				line = next.Line
+			}
+		}
+		if next == nil {
+			break
+		}
+		node = next
+	}
+	return line
+}
+
+// fieldParts splits a path like hooks[2].url into hooks, 2 and url
+func fieldParts(field string) []string {
+	var parts []string
+	for _, part := range strings.Split(field, ".") {
+		name, index, ok := strings.Cut(part, "[")
+		if name != "" {
+			parts = append(parts, name)
+		}
+		if ok {
+			parts = append(parts, strings.TrimSuffix(index, "]"))
+		}
+	}
+	return parts
+}
+
+// Validate returns every invalid setting, without line numbers
+func (c Config) Validate() []Problem {
+	var problems []Problem
+	add := func(field, format string, args ...any) {
+		problems = append(problems, Problem{Field: field, Message: fmt.Sprintf(format, args...)})
+	}
+	// A retention rule deletes or archives mail, so one that isn't as written must not
+	// run with a guessed default
+	fatal := func(field, format string, args ...any) {
+		add(field, format, args...)
+		problems[len(problems)-1].Fatal = true
+	}
+	oneOf := func(field, value string, allowed []string) {
+		if value != "" && !slices.Contains(allowed, value) {
+			add(field, "unknown value %q (use %s)", value, orList(allowed))
+		}
+	}
+	nonNegative := func(field string, value int) {
+		if value < 0 {
+			add(field, "must not be negative, got %d", value)
+		}
+	}
+	webURL := func(field, value string) {
+		if u, err := url.Parse(value); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			add(field, "%q is not an http or https URL", value)
+		}
+	}
+
+	if c.MaxEmails < 0 {
+		add("max_emails", "must be positive, got %d", c.MaxEmails)
+	}
+	oneOf("theme", c.Theme, Themes)
+
+	for i, p := range c.AIProviders {
+		field := fmt.Sprintf("ai_providers[%d]", i)
+		switch p.Type {
+		case AIProviderTypeCLI:
+			if p.Name == "" {
+				add(field+".name", "is required for type cli (use %s)", orList(AICLIs))
+			} else {
+				oneOf(field+".name", p.Name, AICLIs)
+			}
+		case AIProviderTypeAPI:
+			if p.APIKey == "" {
+				add(field+".api_key", "is required for type api")
+			}
+			if p.BaseURL != "" {
+				webURL(field+".base_url", p.BaseURL)
+			}
+		case "":
+			add(field+".type", "is required (use cli or api)")
+		default:
+			add(field+".type", "unknown value %q (use cli or api)", p.Type)
+		}
+		if p.Model == "" {
+			add(field+".model", "is required")
+		}
+	}
+
+	if n := c.Notifications; n != nil && n.Telegram != nil && n.Telegram.Enabled {
+		if n.Telegram.BotToken == "" {
+			add("notifications.telegram.bot_token", "is required when telegram is enabled")
+		}
+		if n.Telegram.ChatID == "" {
+			add("notifications.telegram.chat_id", "is required when telegram is enabled")
+		}
+	}
+	if i := c.Integrations; i != nil && i.GitHub != nil && i.GitHub.Enabled && i.GitHub.Token == "" {
+		add("integrations.github.token", "is required when github is enabled")
+	}
+
+	if l := c.Logging; l != nil {
+		oneOf("logging.level", l.Level, logLevels)
+		oneOf("logging.format", l.Format, logFormats)
+		nonNegative("logging.max_size_mb", l.MaxSizeMB)
+		nonNegative("logging.max_backups", l.MaxBackups)
+	}
+
+	if c.Contacts != nil {
+		for i, book := range c.Contacts.CardDAV {
+			field := fmt.Sprintf("contacts.carddav[%d]", i)
+			if book.URL == "" {
+				add(field+".url", "is required")
+			} else {
+				webURL(field+".url", book.URL)
+			}
+		}
+	}
+
+	for i, f := range c.SmartFolders {
+		field := fmt.Sprintf("smart_folders[%d]", i)
+		if f.Name == "" {
+			add(field+".name", "is required")
+		}
+		nonNegative(field+".days", f.Days)
+	}
+
+	for i, rule := range c.Retention {
+		field := fmt.Sprintf("retention[%d]", i)
+		if rule.Action == "" {
+			fatal(field+".action", "is required (use delete or archive)")
+		} else if actions := []string{RetentionDelete, RetentionArchive}; !slices.Contains(actions, rule.Action) {
+			fatal(field+".action", "unknown value %q (use %s)", rule.Action, orList(actions))
+		}
+		if rule.OlderThanDays < 1 {
+			fatal(field+".older_than_days", "must be at least 1")
+		}
+		if rule.From == "" && rule.Subject == "" && rule.List == "" {
+			fatal(field, "needs at least one of from, subject or list")
+		}
+	}
+
+	if c.Cache != nil {
+		nonNegative("cache.max_size_mb", c.Cache.MaxSizeMB)
+		nonNegative("cache.max_age_days", c.Cache.MaxAgeDays)
+		for _, mailbox := range sortedKeys(c.Cache.Folders) {
+			limit := c.Cache.Folders[mailbox]
+			nonNegative("cache.folders."+mailbox+".max_size_mb", limit.MaxSizeMB)
+			nonNegative("cache.folders."+mailbox+".max_age_days", limit.MaxAgeDays)
+		}
+	}
+
+	if o := c.PendingOps; o != nil {
+		nonNegative("pending_ops.max_retries", o.MaxRetries)
+		nonNegative("pending_ops.backoff_seconds", o.BackoffSeconds)
+	}
+
+	if p := c.Print; p != nil {
+		switch format := p.OutputFormat(); format {
+		case PrintText, PrintHTML:
+		default:
+			if p.Command == "" {
+				add("print.format", "%s needs print.command to convert the HTML", format)
+			}
+		}
+	}
+
+	if r := c.Reply; r != nil {
+		oneOf("reply.quote_style", r.QuoteStyle, []string{QuoteTop, QuoteBottom, QuoteNone})
+		nonNegative("reply.max_quote_depth", r.MaxQuoteDepth)
+	}
+
+	if d := c.Dates; d != nil {
+		oneOf("dates.style", d.Style, []string{DatesRelative, DatesAbsolute})
+		if d.Clock != 0 && d.Clock != 12 && d.Clock != 24 {
+			add("dates.clock", "must be 12 or 24, got %d", d.Clock)
+		}
+	}
+
+	if l := c.List; l != nil {
+		for i, column := range l.Columns {
+			field := fmt.Sprintf("list.columns[%d]", i)
+			if column.Name == "" {
+				add(field+".name", "is required (use %s)", orList(listColumns))
+			} else {
+				oneOf(field+".name", column.Name, listColumns)
+			}
+			nonNegative(field+".width", column.Width)
+		}
+		oneOf("list.density", l.Density, []string{DensityCompact, DensityRelaxed})
+		for _, mailbox := range sortedKeys(l.Sort) {
+			oneOf("list.sort."+mailbox, l.Sort[mailbox], sortOrders)
+		}
+	}
+
+	if p := c.Preview; p != nil {
+		oneOf("preview.position", p.Position, []string{PreviewRight, PreviewBottom})
+		if p.Ratio != 0 && (p.Ratio < 20 || p.Ratio > 80) {
+			add("preview.ratio", "must be between 20 and 80, got %d", p.Ratio)
+		}
+		nonNegative("preview.lines", p.Lines)
+	}
+
+	if cal := c.Calendar; cal != nil {
+		nonNegative("calendar.search_days", cal.SearchDays)
+		nonNegative("calendar.travel_minutes", cal.TravelMinutes)
+		for _, name := range sortedKeys(cal.Colors) {
+			if !hexColor.MatchString(cal.Colors[name]) {
+				add("calendar.colors."+name, "%q is not a #RRGGBB color", cal.Colors[name])
+			}
+		}
+		for _, place := range sortedKeys(cal.Travel) {
+			nonNegative("calendar.travel."+place, cal.Travel[place])
+		}
+		if cal.WeekStart != "" && cal.FirstWeekday() == "" {
+			add("calendar.week_start", "unknown value %q (use monday or sunday)", cal.WeekStart)
+		}
+	}
+
+	if d := c.Digest; d != nil && d.At != "" {
+		if _, err := time.Parse("15:04", d.At); err != nil {
+			add("digest.at", "%q is not a time of day as HH:MM", d.At)
+		}
+	}
+
+	for i, hook := range c.Hooks {
+		field := fmt.Sprintf("hooks[%d]", i)
+		if hook.Event == "" {
+			add(field+".event", "is required (use %s)", orList(hookEvents))
+		} else {
+			oneOf(field+".event", hook.Event, hookEvents)
+		}
+		if hook.Command == "" && hook.URL == "" {
+			add(field, "needs a command or a url")
+		}
+		if hook.URL != "" {
+			webURL(field+".url", hook.URL)
+		}
+		nonNegative(field+".minutes", hook.Minutes)
+	}
+
+	seen := make(map[string]bool)
+	for i, p := range c.Profiles {
+		field := fmt.Sprintf("profiles[%d].name", i)
+		switch {
+		case p.Name == "":
+			add(field, "is required")
+		case p.Name == ProfileAll:
+			add(field, "%q is reserved for every account", ProfileAll)
+		case seen[p.Name]:
+			add(field, "profile %q is defined twice", p.Name)
+		}
+		seen[p.Name] = true
+	}
+	for i, name := range c.ActiveProfiles {
+		if c.GetProfile(name) == nil {
+			add(fmt.Sprintf("active_profiles[%d]", i), "unknown profile %q", name)
+		}
+	}
+
+	return problems
+}
+
+// orList joins values as "a, b or c"
+func orList(values []string) string {
+	if len(values) == 1 {
+		return values[0]
+	}
+	return strings.Join(values[:len(values)-1], ", ") + " or " + values[len(values)-1]
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	slices.Sort(keys)
+	return keys
+}
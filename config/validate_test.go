@@ -0,0 +1,185 @@
+package config
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestCheckData(t *testing.T) {
+	tests := []struct {
+		name string
+		yaml string
+		want []Problem // Field, Line and Fatal; the message is checked to be set
+	}{
+		{
+			name: "valid",
+			yaml: "max_emails: 100\ntheme: default\n",
+		},
+		{
+			name: "empty file",
+			yaml: "",
+		},
+		{
+			name: "negative max_emails",
+			yaml: "theme: default\nmax_emails: -5\n",
+			want: []Problem{{Field: "max_emails", Line: 2}},
+		},
+		{
+			name: "unknown theme",
+			yaml: "theme: solarized\n",
+			want: []Problem{{Field: "theme", Line: 1}},
+		},
+		{
+			name: "misspelt key",
+			yaml: "max_email: 10\n",
+			want: []Problem{{Field: "max_email", Line: 1}},
+		},
+		{
+			name: "wrong type",
+			yaml: "max_emails: lots\n",
+			want: []Problem{{Line: 1}},
+		},
+		{
+			name: "syntax error",
+			yaml: "theme: [default\n",
+			want: []Problem{{Line: 1}},
+		},
+		{
+			name: "ai provider without model or type",
+			yaml: "ai_providers:\n  - name: claude\n",
+			want: []Problem{{Field: "ai_providers[0].type", Line: 2}, {Field: "ai_providers[0].model", Line: 2}},
+		},
+		{
+			name: "api provider with a bad base_url",
+			yaml: "ai_providers:\n  - type: api\n    model: m\n    api_key: k\n    base_url: ftp://example.com\n",
+			want: []Problem{{Field: "ai_providers[0].base_url", Line: 5}},
+		},
+		{
+			name: "retention rule problems are fatal",
+			yaml: "retention:\n  - action: shred\n    older_than_days: 0\n",
+			want: []Problem{
+				{Field: "retention[0].action", Line: 2, Fatal: true},
+				{Field: "retention[0]", Line: 2, Fatal: true},
+				{Field: "retention[0].older_than_days", Line: 3, Fatal: true},
+			},
+		},
+		{
+			name: "hook without command or url",
+			yaml: "hooks:\n  - event: new_mail\n",
+			want: []Problem{{Field: "hooks[0]", Line: 2}},
+		},
+		{
+			name: "dates clock",
+			yaml: "dates:\n  clock: 13\n",
+			want: []Problem{{Field: "dates.clock", Line: 2}},
+		},
+		{
+			name: "calendar color",
+			yaml: "calendar:\n  colors:\n    work: red\n",
+			want: []Problem{{Field: "calendar.colors.work", Line: 3}},
+		},
+		{
+			name: "unknown active profile",
+			yaml: "profiles:\n  - name: work\nactive_profiles: [home]\n",
+			want: []Problem{{Field: "active_profiles[0]", Line: 3}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := checkData([]byte(tt.yaml))
+			if len(got) != len(tt.want) {
+				t.Fatalf("checkData() = %v, want %d problems", got, len(tt.want))
+			}
+			for i, want := range tt.want {
+				p := got[i]
+				if p.Field != want.Field || p.Line != want.Line || p.Fatal != want.Fatal || p.Message == "" {
+					t.Errorf("problem %d = %+v, want field %q line %d fatal %v", i, p, want.Field, want.Line, want.Fatal)
+				}
+			}
+		})
+	}
+}
+
+func TestProblemString(t *testing.T) {
+	tests := []struct {
+		problem Problem
+		want    string
+	}{
+		{Problem{Field: "theme", Line: 3, Message: "bad"}, "line 3: theme: bad"},
+		{Problem{Field: "theme", Message: "bad"}, "theme: bad"},
+		{Problem{Line: 1, Message: "bad"}, "line 1: bad"},
+	}
+	for _, tt := range tests {
+		if got := tt.problem.String(); got != tt.want {
+			t.Errorf("String() = %q, want %q", got, tt.want)
+		}
+	}
+}
+
+// writeConfig puts config.yml in a temporary home directory
+func writeConfig(t *testing.T, content string) string {
+	t.Helper()
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	path := filepath.Join(home, ".config", "maily", configFileName)
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestLoadWarnsAboutFixableProblems(t *testing.T) {
+	writeConfig(t, "max_emails: -1\ntheme: solarized\nlanguage: ko\n")
+	cfg, warnings, err := LoadWithWarnings()
+	if err != nil {
+		t.Fatalf("LoadWithWarnings() error = %v, want only warnings", err)
+	}
+	if len(warnings) != 2 {
+		t.Errorf("warnings = %v, want max_emails and theme", warnings)
+	}
+	if cfg.MaxEmails != 50 || cfg.Theme != "default" || cfg.Language != "ko" {
+		t.Errorf("cfg = max_emails %d, theme %q, language %q; want the defaults and ko", cfg.MaxEmails, cfg.Theme, cfg.Language)
+	}
+}
+
+func TestLoadFailsOnFatalProblems(t *testing.T) {
+	writeConfig(t, "theme: solarized\nretention:\n  - action: delete\n    older_than_days: 30\n")
+	_, warnings, err := LoadWithWarnings()
+	var invalid *ValidationError
+	if !errors.As(err, &invalid) {
+		t.Fatalf("LoadWithWarnings() error = %v, want a ValidationError", err)
+	}
+	if len(invalid.Problems) != 1 || invalid.Problems[0].Field != "retention[0]" {
+		t.Errorf("fatal problems = %v, want retention[0]", invalid.Problems)
+	}
+	if len(warnings) != 1 || warnings[0].Field != "theme" {
+		t.Errorf("warnings = %v, want theme", warnings)
+	}
+}
+
+func TestUpdateKeepsSettingsAsWritten(t *testing.T) {
+	path := writeConfig(t, "max_emails: -1\ntheme: solarized\n")
+	cfg, err := Update(func(c *Config) { c.Language = "ja" })
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.MaxEmails != 50 {
+		t.Errorf("returned max_emails = %d, want the default", cfg.MaxEmails)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	saved := string(data)
+	for _, want := range []string{"max_emails: -1", "theme: solarized", "language: ja"} {
+		if !strings.Contains(saved, want) {
+			t.Errorf("saved config.yml lacks %q:\n%s", want, saved)
+		}
+	}
+}
@@ -16,6 +16,7 @@ type ParsedEvent struct {
 	Notes              string `json:"notes,omitempty"`      // Additional details, URLs, descriptions
 	AlarmMinutesBefore int    `json:"alarm_minutes_before"` // 0 means not specified
 	AlarmSpecified     bool   `json:"alarm_specified"`      // true if user explicitly mentioned reminder
+	AllDay             bool   `json:"all_day"`              // no times, such as a holiday or a trip
 }
 
 // ParseEventResponse parses the AI JSON response into a ParsedEvent
@@ -96,12 +97,14 @@ Respond with ONLY a JSON object (no markdown, no explanation):
   "location": "location if mentioned, otherwise empty string",
   "notes": "additional details, URLs, agenda, description",
   "alarm_minutes_before": 5,
-  "alarm_specified": true
+  "alarm_specified": true,
+  "all_day": false
 }
 
 Rules:
 - start_time and end_time must be in RFC3339 format with timezone
 - If no duration specified, default to 1 hour
+- For events without times (holidays, vacations, conferences), set all_day=true, start_time to midnight on the first day and end_time to midnight on the last day; they may span several days
 - If user says "remind me X minutes before" or similar, set alarm_minutes_before and alarm_specified=true
 - If no reminder mentioned, set alarm_minutes_before=0 and alarm_specified=false
 - Extract location if mentioned (e.g., "at the coffee shop")
@@ -184,3 +187,75 @@ Rules:
 
 Respond with ONLY the JSON or NO_EVENTS_FOUND, no other text.`, now.Format(time.RFC3339), from, subject, body)
 }
+
+// ParseDatePrompt builds a prompt for reading a single date from natural language, such
+// as "the friday before christmas"
+func ParseDatePrompt(input string, now time.Time) string {
+	return fmt.Sprintf(`Convert this description of a day into a date.
+
+Current date/time: %s
+
+Description: %s
+
+Rules:
+- Use the current date to interpret relative dates like "last monday" or "two weeks ago"
+- A day without a year is the most recent such day, never one in the future
+- If the description is not a date, respond with exactly: UNKNOWN
+
+Respond with ONLY the date in YYYY-MM-DD format or UNKNOWN, no other text.`, now.Format(time.RFC3339), input)
+}
+
+// ParseDateResponse reads the YYYY-MM-DD date from the AI response to ParseDatePrompt
+func ParseDateResponse(response string) (time.Time, error) {
+	response = stripMarkdownCodeFences(response)
+	if response == "UNKNOWN" {
+		return time.Time{}, fmt.Errorf("not a date")
+	}
+	date, err := time.ParseInLocation("2006-01-02", response, time.Local)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("failed to parse AI response: %w", err)
+	}
+	return date, nil
+}
+
+// TriageEmail is one of the emails TriagePrompt scores
+type TriageEmail struct {
+	From    string
+	Subject string
+	Snippet string
+}
+
+// TriagePrompt builds a prompt scoring how much each email needs the reader's
+// attention, from 1 (can be ignored) to 5 (needs a reply or action today)
+func TriagePrompt(emails []TriageEmail) string {
+	var list strings.Builder
+	for i, e := range emails {
+		fmt.Fprintf(&list, "%d. From: %s\n   Subject: %s\n   %s\n", i+1, e.From, e.Subject, e.Snippet)
+	}
+	return fmt.Sprintf(`Score how much each of these emails needs its reader's attention.
+
+%s
+Scores:
+- 5: needs a reply or action today (a person asking something, a deadline, a security alert)
+- 4: from a person and worth reading today
+- 3: useful but can wait
+- 2: automated notifications, receipts, updates
+- 1: newsletters, promotions, spam
+
+Respond with ONLY a JSON array of %d integer scores in the order of the emails, such as [5, 1, 3], no other text.`, list.String(), len(emails))
+}
+
+// ParseTriageResponse reads the scores from the AI response to TriagePrompt, one for
+// each of the count emails in their order
+func ParseTriageResponse(response string, count int) ([]int, error) {
+	response = stripMarkdownCodeFences(response)
+
+	var scores []int
+	if err := json.Unmarshal([]byte(response), &scores); err != nil {
+		return nil, fmt.Errorf("failed to parse AI response: %w", err)
+	}
+	if len(scores) != count {
+		return nil, fmt.Errorf("AI scored %d emails instead of %d", len(scores), count)
+	}
+	return scores, nil
+}
@@ -0,0 +1,59 @@
+package ai
+
+import (
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestTriagePrompt(t *testing.T) {
+	prompt := TriagePrompt([]TriageEmail{
+		{From: "Boss <boss@corp.com>", Subject: "Budget", Snippet: "Can you send the numbers?"},
+		{From: "news@list.com", Subject: "Weekly", Snippet: "This week in..."},
+	})
+
+	for _, want := range []string{
+		"1. From: Boss <boss@corp.com>\n   Subject: Budget\n   Can you send the numbers?\n",
+		"2. From: news@list.com\n   Subject: Weekly\n",
+		"JSON array of 2 integer scores",
+	} {
+		if !strings.Contains(prompt, want) {
+			t.Errorf("prompt is missing %q:\n%s", want, prompt)
+		}
+	}
+}
+
+func TestParseTriageResponse(t *testing.T) {
+	tests := []struct {
+		name     string
+		response string
+		count    int
+		want     []int
+		wantErr  bool
+	}{
+		{name: "plain", response: "[5, 1, 3]", count: 3, want: []int{5, 1, 3}},
+		{name: "surrounding space", response: "\n  [4, 2]  \n", count: 2, want: []int{4, 2}},
+		{name: "code fence", response: "```json\n[2, 5]\n```", count: 2, want: []int{2, 5}},
+		{name: "too few scores", response: "[5, 1]", count: 3, wantErr: true},
+		{name: "too many scores", response: "[5, 1, 3, 2]", count: 3, wantErr: true},
+		{name: "not json", response: "The first email is important.", count: 1, wantErr: true},
+		{name: "not integers", response: `["high", "low"]`, count: 2, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseTriageResponse(tt.response, tt.count)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("ParseTriageResponse(%q) = %v, want an error", tt.response, got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ParseTriageResponse(%q) error: %v", tt.response, err)
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("ParseTriageResponse(%q) = %v, want %v", tt.response, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,377 @@
+package ai
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"maily/internal/dateparse"
+)
+
+// ParseEventLocally reads an event from quick-add input without an AI CLI, for when
+// none is installed or it fails: the first line is the event, the lines below it are
+// its notes. dayFirst reads 1/5 as the 1st of May. ok is false when no day or time was
+// found.
+func ParseEventLocally(input string, now time.Time, dayFirst bool) (*ParsedEvent, bool) {
+	lines := strings.SplitN(strings.TrimSpace(input), "\n", 2)
+	e, ok := parseQuickAdd(lines[0], now, dayFirst)
+	if !ok {
+		return nil, false
+	}
+	parsed := &ParsedEvent{
+		Title:     e.title,
+		StartTime: e.start.Format(time.RFC3339),
+		EndTime:   e.end.Format(time.RFC3339),
+		Location:  e.location,
+		AllDay:    e.allDay,
+	}
+	if len(lines) == 2 {
+		parsed.Notes = strings.TrimSpace(lines[1])
+	}
+	return parsed, true
+}
+
+// quickEvent is an event parseQuickAdd read
+type quickEvent struct {
+	title    string
+	location string
+	start    time.Time
+	end      time.Time
+	allDay   bool
+}
+
+// parseQuickAdd reads an event from a line such as "lunch with Sam tomorrow 1pm at
+// Luigi's": the day (today, tomorrow, fri, next tue, jan 5, 1/5 with the day first
+// when dayFirst, in 3 days), the time (3pm, 14:00, noon, 3-4pm, in 2 hours), how long
+// (for 90 min, for 3 days), and a place after " at "; the other words are the title.
+// Events without a time are all day, and ones without a day are on the next time it's
+// that time of day. Days are read by dateparse, so month and weekday names may also be
+// in the language maily is shown in. ok is false when there's neither.
+func parseQuickAdd(input string, now time.Time, dayFirst bool) (quickEvent, bool) {
+	words := strings.Fields(input)
+	p := quickParser{
+		now:      now,
+		today:    time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location()),
+		dayFirst: dayFirst,
+		words:    make([]string, len(words)),
+	}
+	for i, w := range words {
+		p.words[i] = strings.TrimRight(strings.TrimLeft(strings.ToLower(w), "("), ",;!?.)")
+	}
+
+	used := make([]bool, len(words))
+	for i := 0; i < len(words); i++ {
+		n := p.match(i)
+		if n == 0 {
+			continue
+		}
+		for j := i; j < i+n; j++ {
+			used[j] = true
+		}
+		if i > 0 && !used[i-1] && quickConnectors[p.words[i-1]] {
+			used[i-1] = true
+		}
+		i += n - 1
+	}
+	if !p.hasDate && !p.hasTime {
+		return quickEvent{}, false
+	}
+
+	var e quickEvent
+	var rest, restLower []string
+	for i, w := range words {
+		if !used[i] {
+			rest = append(rest, w)
+			restLower = append(restLower, p.words[i])
+		}
+	}
+	// The last " at " that's left starts the place
+	e.title = strings.Join(rest, " ")
+	for i := len(rest) - 2; i > 0; i-- {
+		if restLower[i] == "at" || restLower[i] == "@" {
+			e.title = strings.Join(rest[:i], " ")
+			e.location = strings.Join(rest[i+1:], " ")
+			break
+		}
+	}
+	e.title = strings.Trim(e.title, " ,-–")
+
+	if p.evening && !p.hasTime {
+		p.start, p.hasTime = 19*60, true
+	}
+	if !p.hasTime {
+		e.allDay = true
+		last := p.date.AddDate(0, 0, max(p.days, 1)-1)
+		e.start = p.date
+		e.end = time.Date(last.Year(), last.Month(), last.Day(), 23, 59, 59, 0, last.Location())
+		return e, true
+	}
+
+	day := p.date
+	if !p.hasDate {
+		day = p.today
+		if at(day, p.start).Before(now) {
+			day = day.AddDate(0, 0, 1)
+		}
+	}
+	e.start = at(day, p.start)
+	switch {
+	case p.hasEnd:
+		e.end = at(day, p.end)
+		if !e.end.After(e.start) {
+			e.end = e.end.AddDate(0, 0, 1)
+		}
+	case p.length > 0:
+		e.end = e.start.Add(p.length)
+	case p.days > 0:
+		e.end = e.start.AddDate(0, 0, p.days)
+	default:
+		e.end = e.start.Add(time.Hour)
+	}
+	return e, true
+}
+
+// at returns the time minutes after midnight on day
+func at(day time.Time, minutes int) time.Time {
+	return time.Date(day.Year(), day.Month(), day.Day(), minutes/60, minutes%60, 0, 0, day.Location())
+}
+
+// quickConnectors are the words before a day or time that go with it: "on fri", "at 3pm"
+var quickConnectors = map[string]bool{"on": true, "at": true, "from": true, "@": true}
+
+// quickParser holds what parseQuickAdd has found so far
+type quickParser struct {
+	now, today time.Time
+	dayFirst   bool
+	words      []string // lower case, without surrounding punctuation
+
+	date       time.Time
+	hasDate    bool
+	start, end int // minutes after midnight
+	hasTime    bool
+	hasEnd     bool
+	length     time.Duration // from "for 90 min"
+	days       int           // from "for 3 days"
+	evening    bool          // tonight, 7pm unless a time is given
+}
+
+// match reads a day, time or length at word i, returning how many words it took
+func (p *quickParser) match(i int) int {
+	w := p.words[i:]
+	if n := p.matchRelative(w); n > 0 {
+		return n
+	}
+	if n := p.matchLength(w); n > 0 {
+		return n
+	}
+	if !p.hasDate {
+		if date, n := p.day(w); n > 0 {
+			p.date, p.hasDate = date, true
+			return n
+		}
+	}
+	if !p.hasTime {
+		return p.matchTime(w, i > 0 && p.words[i-1] == "at")
+	}
+	return 0
+}
+
+// matchRelative reads "in 2 hours", "in 30 min" or "in 3 days"
+func (p *quickParser) matchRelative(w []string) int {
+	if len(w) < 2 || w[0] != "in" {
+		return 0
+	}
+	n, unit, k := dateparse.Amount(w[1:])
+	switch {
+	case k == 0:
+		return 0
+	case unit == time.Minute || unit == time.Hour:
+		if p.hasDate || p.hasTime {
+			return 0
+		}
+		t := p.now.Add(time.Duration(n * float64(unit))).Truncate(time.Minute)
+		p.date = time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+		p.start = t.Hour()*60 + t.Minute()
+		p.hasDate, p.hasTime = true, true
+	case p.hasDate:
+		return 0
+	case unit == dateparse.MonthUnit:
+		p.date, p.hasDate = p.today.AddDate(0, int(n), 0), true
+	case unit == dateparse.YearUnit:
+		p.date, p.hasDate = p.today.AddDate(int(n), 0, 0), true
+	default:
+		p.date, p.hasDate = p.today.AddDate(0, 0, int(n*float64(unit/(24*time.Hour)))), true
+	}
+	return 1 + k
+}
+
+// matchLength reads "for 90 min", "for 2 hours" or "for 3 days"
+func (p *quickParser) matchLength(w []string) int {
+	if len(w) < 2 || w[0] != "for" || p.length > 0 || p.days > 0 {
+		return 0
+	}
+	n, unit, k := dateparse.Amount(w[1:])
+	switch {
+	case k == 0 || unit == dateparse.MonthUnit || unit == dateparse.YearUnit:
+		return 0
+	case unit == time.Minute || unit == time.Hour:
+		p.length = time.Duration(n * float64(unit))
+	default:
+		p.days = int(n * float64(unit/(24*time.Hour)))
+	}
+	return 1 + k
+}
+
+// day reads a day at the start of w, with any "the" before it: today, tonight,
+// tomorrow, the day after tomorrow, fri, next tue, next week, jan 5, the 5th of
+// january, 2025-01-05 and 1/5
+func (p *quickParser) day(w []string) (time.Time, int) {
+	if w[0] == "the" && len(w) > 1 {
+		if date, n := p.day(w[1:]); n > 0 {
+			return date, n + 1
+		}
+		return time.Time{}, 0
+	}
+	switch w[0] {
+	case "today":
+		return p.today, 1
+	case "tonight":
+		p.evening = true
+		return p.today, 1
+	case "tomorrow", "tmrw", "tmr":
+		return p.today.AddDate(0, 0, 1), 1
+	}
+	if len(w) >= 3 && w[0] == "day" && w[1] == "after" && w[2] == "tomorrow" {
+		return p.today.AddDate(0, 0, 2), 3
+	}
+	if w[0] == "next" || w[0] == "this" {
+		if len(w) < 2 {
+			return time.Time{}, 0
+		}
+		if day, ok := dateparse.Weekday(w[1]); ok {
+			return dateparse.WeekdayFrom(p.today, day, dateparse.Future, w[0] == "next"), 2
+		}
+		switch {
+		case w[0] == "next" && w[1] == "week":
+			return p.today.AddDate(0, 0, 7), 2
+		case w[0] == "next" && w[1] == "month":
+			return p.today.AddDate(0, 1, 0), 2
+		}
+		return time.Time{}, 0
+	}
+	// A month's name before a weekday's, as Spanish and Italian "mar" is both
+	if date, n := dateparse.Date(w, p.today, dateparse.Future, p.dayFirst); n > 0 {
+		return date, n
+	}
+	if day, ok := dateparse.Weekday(w[0]); ok {
+		return dateparse.WeekdayFrom(p.today, day, dateparse.Future, false), 1
+	}
+	return time.Time{}, 0
+}
+
+// matchTime reads a time or a span of time: 3pm, 3:30 pm, 15:00, noon, 3-4pm, 2pm to
+// 4pm, 9:00–10:30. A bare hour is only a time after "at".
+func (p *quickParser) matchTime(w []string, afterAt bool) int {
+	if from, to, ok := strings.Cut(strings.ReplaceAll(w[0], "–", "-"), "-"); ok {
+		a, n := readClock([]string{from}, true)
+		b, m := readClock([]string{to}, true)
+		if n == 0 || m == 0 || !(a.exact() || b.exact()) {
+			return 0
+		}
+		p.setSpan(a, b)
+		return 1
+	}
+
+	a, n := readClock(w, afterAt)
+	if n == 0 {
+		return 0
+	}
+	if len(w) > n+1 && quickUntil[w[n]] {
+		if b, m := readClock(w[n+1:], true); m > 0 {
+			p.setSpan(a, b)
+			return n + 1 + m
+		}
+	}
+	p.start, p.hasTime = a.minutes(), true
+	return n
+}
+
+// quickUntil are the words between the start and end of a span of time
+var quickUntil = map[string]bool{"-": true, "–": true, "to": true, "until": true, "till": true}
+
+// setSpan sets the start and end time; a start without am or pm takes the end's,
+// unless that would put it after the end, so 11-1pm starts at 11am
+func (p *quickParser) setSpan(a, b clockReading) {
+	if a.meridiem == "" && b.meridiem != "" {
+		a.meridiem = b.meridiem
+		if a.minutes() > b.minutes() {
+			a.meridiem = "am"
+		}
+	}
+	p.start, p.end = a.minutes(), b.minutes()
+	p.hasTime, p.hasEnd = true, true
+}
+
+// clockReading is a time of day as written
+type clockReading struct {
+	hour, minute int
+	meridiem     string // "am", "pm" or "" when not given
+	colon        bool   // written with minutes, 3:00 rather than 3
+	short        bool   // a one-digit hour
+}
+
+// exact reports whether the time can't be a plain number
+func (c clockReading) exact() bool {
+	return c.colon || c.meridiem != ""
+}
+
+// minutes returns the minutes after midnight; an hour from 1 to 6 with neither am nor
+// pm nor a leading zero is in the afternoon, as in "at 3"
+func (c clockReading) minutes() int {
+	h := c.hour
+	switch {
+	case c.meridiem == "am":
+		h %= 12
+	case c.meridiem == "pm":
+		h = h%12 + 12
+	case c.short && h >= 1 && h <= 6:
+		h += 12
+	}
+	return h*60 + c.minute
+}
+
+var clockRe = regexp.MustCompile(`^(\d{1,2})(?::(\d{2}))?(am|pm|a|p)?$`)
+
+// readClock reads a time of day at the start of w and how many words it took; bare
+// allows an hour on its own, such as the 3 in "at 3"
+func readClock(w []string, bare bool) (clockReading, int) {
+	switch w[0] {
+	case "noon", "midday":
+		return clockReading{hour: 12, meridiem: "pm"}, 1
+	case "midnight":
+		return clockReading{hour: 12, meridiem: "am"}, 1
+	}
+	m := clockRe.FindStringSubmatch(w[0])
+	if m == nil {
+		return clockReading{}, 0
+	}
+	c := clockReading{colon: m[2] != "", short: len(m[1]) == 1}
+	c.hour, _ = strconv.Atoi(m[1])
+	c.minute, _ = strconv.Atoi(m[2])
+	n := 1
+	if m[3] != "" {
+		c.meridiem = strings.TrimSuffix(m[3], "m") + "m"
+	} else if len(w) > 1 {
+		if mer := strings.ReplaceAll(w[1], ".", ""); mer == "am" || mer == "pm" {
+			c.meridiem, n = mer, 2
+		}
+	}
+	if !c.exact() && !bare {
+		return clockReading{}, 0
+	}
+	if c.minute > 59 || c.hour > 23 || (c.meridiem != "" && (c.hour < 1 || c.hour > 12)) {
+		return clockReading{}, 0
+	}
+	return c, n
+}
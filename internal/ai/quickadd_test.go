@@ -0,0 +1,95 @@
+package ai
+
+import (
+	"testing"
+	"time"
+
+	"maily/internal/i18n"
+)
+
+func TestParseQuickAdd(t *testing.T) {
+	// A Wednesday morning
+	now := time.Date(2025, 3, 5, 10, 0, 0, 0, time.UTC)
+	at := func(month time.Month, day, hour, minute int) time.Time {
+		return time.Date(2025, month, day, hour, minute, 0, 0, time.UTC)
+	}
+	endOf := func(month time.Month, day int) time.Time {
+		return time.Date(2025, month, day, 23, 59, 59, 0, time.UTC)
+	}
+
+	tests := []struct {
+		input      string
+		dayFirst   bool
+		title      string
+		location   string
+		start, end time.Time
+		allDay     bool
+	}{
+		{input: "lunch with Sam tomorrow 1pm at Luigi's", title: "lunch with Sam", location: "Luigi's", start: at(3, 6, 13, 0), end: at(3, 6, 14, 0)},
+		{input: "standup fri 9:30 for 15 min", title: "standup", start: at(3, 7, 9, 30), end: at(3, 7, 9, 45)},
+		{input: "review next wed 3-4pm", title: "review", start: at(3, 12, 15, 0), end: at(3, 12, 16, 0)},
+		{input: "planning 11-1pm", title: "planning", start: at(3, 5, 11, 0), end: at(3, 5, 13, 0)},
+		{input: "call mom at 3", title: "call mom", start: at(3, 5, 15, 0), end: at(3, 5, 16, 0)},
+		{input: "gym 8am", title: "gym", start: at(3, 6, 8, 0), end: at(3, 6, 9, 0)},
+		{input: "dinner tonight", title: "dinner", start: at(3, 5, 19, 0), end: at(3, 5, 20, 0)},
+		{input: "break in 2 hours", title: "break", start: at(3, 5, 12, 0), end: at(3, 5, 13, 0)},
+		{input: "dentist mar 20 noon", title: "dentist", start: at(3, 20, 12, 0), end: at(3, 20, 13, 0)},
+		{input: "conference 12/3 for 3 days", dayFirst: true, title: "conference", start: at(3, 12, 0, 0), end: endOf(3, 14), allDay: true},
+		{input: "conference 3/12 for 3 days", title: "conference", start: at(3, 12, 0, 0), end: endOf(3, 14), allDay: true},
+		{input: "holiday the 5th of june", title: "holiday", start: at(6, 5, 0, 0), end: endOf(6, 5), allDay: true},
+		{input: "trip in 3 days", title: "trip", start: at(3, 8, 0, 0), end: endOf(3, 8), allDay: true},
+		{input: "party on saturday 8pm – 11pm", title: "party", start: at(3, 8, 20, 0), end: at(3, 8, 23, 0)},
+		{input: "launch the day after tomorrow", title: "launch", start: at(3, 7, 0, 0), end: endOf(3, 7), allDay: true},
+		{input: "late shift 10pm-2am", title: "late shift", start: at(3, 5, 22, 0), end: at(3, 6, 2, 0)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			e, ok := parseQuickAdd(tt.input, now, tt.dayFirst)
+			if !ok {
+				t.Fatal("parseQuickAdd() found no day or time")
+			}
+			if e.title != tt.title || e.location != tt.location || e.allDay != tt.allDay {
+				t.Errorf("got title %q, location %q, all day %v; want %q, %q, %v", e.title, e.location, e.allDay, tt.title, tt.location, tt.allDay)
+			}
+			if !e.start.Equal(tt.start) || !e.end.Equal(tt.end) {
+				t.Errorf("got %s to %s, want %s to %s", e.start, e.end, tt.start, tt.end)
+			}
+		})
+	}
+}
+
+func TestParseQuickAddWithoutDateOrTime(t *testing.T) {
+	now := time.Date(2025, 3, 5, 10, 0, 0, 0, time.UTC)
+	for _, input := range []string{"buy milk", "read chapter 3", "call 5 people", ""} {
+		if e, ok := parseQuickAdd(input, now, false); ok {
+			t.Errorf("parseQuickAdd(%q) = %+v, want no event", input, e)
+		}
+	}
+}
+
+func TestParseEventLocallyNotes(t *testing.T) {
+	now := time.Date(2025, 3, 5, 10, 0, 0, 0, time.UTC)
+	parsed, ok := ParseEventLocally("sync tomorrow 2pm\nagenda: budget", now, false)
+	if !ok {
+		t.Fatal("ParseEventLocally() found no event")
+	}
+	if parsed.Title != "sync" || parsed.Notes != "agenda: budget" || parsed.StartTime != "2025-03-06T14:00:00Z" {
+		t.Errorf("ParseEventLocally() = %+v", parsed)
+	}
+}
+
+func TestParseQuickAddLocalNames(t *testing.T) {
+	t.Cleanup(func() { i18n.Init("en") })
+	if err := i18n.Init("de"); err != nil {
+		t.Fatal(err)
+	}
+	now := time.Date(2025, 3, 5, 10, 0, 0, 0, time.UTC)
+	e, ok := parseQuickAdd("Zahnarzt 5. Dezember 10:00", now, true)
+	if !ok || e.title != "Zahnarzt" || !e.start.Equal(time.Date(2025, 12, 5, 10, 0, 0, 0, time.UTC)) {
+		t.Errorf("parseQuickAdd() = %+v, %v; want Zahnarzt on the 5th of December at 10:00", e, ok)
+	}
+	e, ok = parseQuickAdd("Training Freitag 18:00", now, true)
+	if !ok || e.title != "Training" || !e.start.Equal(time.Date(2025, 3, 7, 18, 0, 0, 0, time.UTC)) {
+		t.Errorf("parseQuickAdd() = %+v, %v; want Training on Friday at 18:00", e, ok)
+	}
+}
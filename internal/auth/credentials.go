@@ -19,6 +19,9 @@ const (
 	ProviderGmail = "gmail"
 	ProviderYahoo = "yahoo"
 	ProviderQQ    = "qq"
+
+	// ProviderMaildir is a local Maildir kept in sync by another tool, such as mbsync
+	ProviderMaildir = "maildir"
 )
 
 // Gmail IMAP/SMTP hosts
@@ -58,6 +61,21 @@ type Credentials struct {
 	SMTPHost string `yaml:"smtp_host"`
 	SMTPPort int    `yaml:"smtp_port"`
 	Provider string `yaml:"provider"`
+
+	Compress bool        `yaml:"compress,omitempty"` // IMAP COMPRESS=DEFLATE when the server offers it
+	TLS      *TLSOptions `yaml:"tls,omitempty"`      // how the IMAP and SMTP connections are secured; nil uses the defaults
+	Proxy    string      `yaml:"proxy,omitempty"`    // socks5:// or http:// URL for IMAP and SMTP, "direct" to ignore ALL_PROXY
+
+	MaildirPath string `yaml:"maildir_path,omitempty"` // root of a Maildir account's folders
+	NotmuchDB   string `yaml:"notmuch_db,omitempty"`   // notmuch database indexing the Maildir, for search and tags
+}
+
+// TLSOptions tightens or adapts how the IMAP and SMTP connections are secured
+type TLSOptions struct {
+	MinVersion string   `yaml:"min_version,omitempty"` // 1.2 or 1.3; defaults to Go's minimum, TLS 1.2
+	CAFile     string   `yaml:"ca_file,omitempty"`     // PEM certificates trusted besides the system's, for corporate servers
+	PinSHA256  []string `yaml:"pin_sha256,omitempty"`  // base64 SHA-256 public key hashes, one of which the server's chain must carry
+	StartTLS   bool     `yaml:"starttls,omitempty"`    // connect to IMAP in plain text (usually port 143) and upgrade with STARTTLS
 }
 
 type Account struct {
@@ -65,6 +83,18 @@ type Account struct {
 	Provider    string      `yaml:"provider"`
 	Credentials Credentials `yaml:"credentials"`
 	Avatar      string      `yaml:"avatar,omitempty"`
+	Color       string      `yaml:"color,omitempty"` // accent color, e.g. "#F59E0B"
+
+	MaxAttachmentMB int    `yaml:"max_attachment_mb,omitempty"` // overrides the provider's attachment size limit
+	WebURL          string `yaml:"web_url,omitempty"`           // web mail URL template with {email}, {message_id} and {subject}
+}
+
+// DisplayName returns the account's display name, falling back to the email address
+func (a Account) DisplayName() string {
+	if a.Name != "" {
+		return a.Name
+	}
+	return a.Credentials.Email
 }
 
 type AccountStore struct {
@@ -107,6 +137,16 @@ func QQCredentials(email, password string) Credentials {
 	}
 }
 
+// MaildirCredentials describes a local Maildir account. Mail is sent with the
+// sendmail command (msmtp and the like) unless an SMTP host is set.
+func MaildirCredentials(email, path string) Credentials {
+	return Credentials{
+		Email:       email,
+		Provider:    ProviderMaildir,
+		MaildirPath: path,
+	}
+}
+
 func LoadAccountStore() (*AccountStore, error) {
 	configDir, err := getConfigDir()
 	if err != nil {
@@ -149,6 +189,18 @@ func (s *AccountStore) Save() error {
 	return os.WriteFile(storePath, data, 0600)
 }
 
+// Filter returns a copy of the store with only the accounts for which keep returns true.
+// The result is a view for display and syncing and must not be saved.
+func (s *AccountStore) Filter(keep func(email string) bool) *AccountStore {
+	filtered := &AccountStore{}
+	for _, a := range s.Accounts {
+		if keep(a.Credentials.Email) {
+			filtered.Accounts = append(filtered.Accounts, a)
+		}
+	}
+	return filtered
+}
+
 func (s *AccountStore) AddAccount(account Account) {
 	// Check if account with same email exists
 	for i, a := range s.Accounts {
@@ -170,6 +222,17 @@ func (s *AccountStore) RemoveAccount(email string) bool {
 	return false
 }
 
+// UpdatePassword replaces the stored password for an account, keeping everything else
+func (s *AccountStore) UpdatePassword(email, password string) bool {
+	for i, a := range s.Accounts {
+		if a.Credentials.Email == email {
+			s.Accounts[i].Credentials.Password = password
+			return true
+		}
+	}
+	return false
+}
+
 func (s *AccountStore) GetAccount(email string) *Account {
 	for _, a := range s.Accounts {
 		if a.Credentials.Email == email {
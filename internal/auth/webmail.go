@@ -0,0 +1,57 @@
+package auth
+
+import (
+	"net/url"
+	"strings"
+)
+
+// Web mail URLs per provider. {email}, {message_id} and {subject} are replaced with the
+// account address and the email's Message-ID and subject.
+const (
+	gmailWebURL   = "https://mail.google.com/mail/u/{email}/#search/rfc822msgid%3A{message_id}"
+	yahooWebURL   = "https://mail.yahoo.com/d/search/keyword={subject}"
+	outlookWebURL = "https://outlook.live.com/mail/0/"
+	office365URL  = "https://outlook.office.com/mail/"
+)
+
+// Personal Microsoft domains, served by outlook.live.com
+var outlookDomains = []string{"outlook.com", "hotmail.com", "live.com", "msn.com"}
+
+// WebMailTemplate returns the account's web mail URL template: web_url from
+// accounts.yml, else the provider's. Empty when the provider has no web UI maily knows.
+func (a Account) WebMailTemplate() string {
+	if a.WebURL != "" {
+		return a.WebURL
+	}
+	if a.Provider == ProviderGmail {
+		return gmailWebURL
+	}
+	if a.Provider == ProviderYahoo {
+		return yahooWebURL
+	}
+	_, domain, _ := strings.Cut(strings.ToLower(a.Credentials.Email), "@")
+	for _, d := range outlookDomains {
+		if domain == d {
+			return outlookWebURL
+		}
+	}
+	if strings.EqualFold(a.Credentials.IMAPHost, "outlook.office365.com") {
+		return office365URL
+	}
+	return ""
+}
+
+// WebMailURL returns the URL that shows an email in the provider's web UI, and whether
+// the URL finds the email itself rather than just opening the mailbox
+func (a Account) WebMailURL(messageID, subject string) (string, bool) {
+	template := a.WebMailTemplate()
+	if template == "" {
+		return "", false
+	}
+	specific := strings.Contains(template, "{message_id}") || strings.Contains(template, "{subject}")
+	return strings.NewReplacer(
+		"{email}", url.PathEscape(a.Credentials.Email),
+		"{message_id}", url.QueryEscape(strings.Trim(messageID, "<> ")),
+		"{subject}", url.QueryEscape(subject),
+	).Replace(template), specific
+}
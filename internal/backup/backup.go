@@ -0,0 +1,176 @@
+package backup
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	"golang.org/x/crypto/scrypt"
+)
+
+// Archive layout: magic | salt | nonce | AES-256-GCM(tar.gz)
+const (
+	magic     = "MAILYBAK1"
+	saltSize  = 16
+	keySize   = 32
+	scryptN   = 1 << 15
+	scryptR   = 8
+	scryptP   = 1
+	headerLen = len(magic) + saltSize
+)
+
+var (
+	ErrNotBackup     = errors.New("not a maily backup file")
+	ErrBadPassphrase = errors.New("wrong passphrase or corrupted backup")
+)
+
+// File is a single file stored in a backup
+type File struct {
+	Name string
+	Data []byte
+}
+
+// Write encrypts files with a key derived from passphrase and writes the archive to w
+func Write(w io.Writer, files []File, passphrase string) error {
+	if passphrase == "" {
+		return errors.New("passphrase is required")
+	}
+
+	plain, err := pack(files)
+	if err != nil {
+		return err
+	}
+
+	salt := make([]byte, saltSize)
+	if _, err := rand.Read(salt); err != nil {
+		return err
+	}
+	gcm, err := newGCM(passphrase, salt)
+	if err != nil {
+		return err
+	}
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return err
+	}
+
+	header := append([]byte(magic), salt...)
+	sealed := gcm.Seal(nil, nonce, plain, header)
+
+	for _, part := range [][]byte{header, nonce, sealed} {
+		if _, err := w.Write(part); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Read decrypts an archive produced by Write and returns its files
+func Read(r io.Reader, passphrase string) ([]File, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	if len(data) < headerLen || string(data[:len(magic)]) != magic {
+		return nil, ErrNotBackup
+	}
+
+	header := data[:headerLen]
+	salt := header[len(magic):]
+	gcm, err := newGCM(passphrase, salt)
+	if err != nil {
+		return nil, err
+	}
+
+	rest := data[headerLen:]
+	if len(rest) < gcm.NonceSize() {
+		return nil, ErrNotBackup
+	}
+	nonce, sealed := rest[:gcm.NonceSize()], rest[gcm.NonceSize():]
+
+	plain, err := gcm.Open(nil, nonce, sealed, header)
+	if err != nil {
+		return nil, ErrBadPassphrase
+	}
+	return unpack(plain)
+}
+
+func newGCM(passphrase string, salt []byte) (cipher.AEAD, error) {
+	key, err := scrypt.Key([]byte(passphrase), salt, scryptN, scryptR, scryptP, keySize)
+	if err != nil {
+		return nil, err
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// pack bundles files into a gzipped tar stream
+func pack(files []File) ([]byte, error) {
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	tw := tar.NewWriter(gz)
+
+	now := time.Now()
+	for _, f := range files {
+		hdr := &tar.Header{
+			Name:    f.Name,
+			Mode:    0600,
+			Size:    int64(len(f.Data)),
+			ModTime: now,
+		}
+		if err := tw.WriteHeader(hdr); err != nil {
+			return nil, err
+		}
+		if _, err := tw.Write(f.Data); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := tw.Close(); err != nil {
+		return nil, err
+	}
+	if err := gz.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// unpack reads the files from a gzipped tar stream
+func unpack(data []byte) ([]File, error) {
+	gz, err := gzip.NewReader(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("invalid backup contents: %w", err)
+	}
+	defer gz.Close()
+
+	var files []File
+	tr := tar.NewReader(gz)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("invalid backup contents: %w", err)
+		}
+		if hdr.Typeflag != tar.TypeReg {
+			continue
+		}
+		content, err := io.ReadAll(tr)
+		if err != nil {
+			return nil, err
+		}
+		files = append(files, File{Name: hdr.Name, Data: content})
+	}
+	return files, nil
+}
@@ -0,0 +1,42 @@
+package backup
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+)
+
+func TestWriteReadRoundTrip(t *testing.T) {
+	files := []File{
+		{Name: "accounts.yml", Data: []byte("accounts:\n  - name: me@example.com\n    credentials:\n      password: secret\n")},
+		{Name: "config.yml", Data: []byte("max_emails: 50\n")},
+	}
+
+	var buf bytes.Buffer
+	if err := Write(&buf, files, "correct horse"); err != nil {
+		t.Fatalf("Write error: %v", err)
+	}
+	if bytes.Contains(buf.Bytes(), []byte("secret")) {
+		t.Fatal("backup contains plaintext password")
+	}
+
+	got, err := Read(bytes.NewReader(buf.Bytes()), "correct horse")
+	if err != nil {
+		t.Fatalf("Read error: %v", err)
+	}
+	if len(got) != len(files) {
+		t.Fatalf("got %d files, want %d", len(got), len(files))
+	}
+	for i := range files {
+		if got[i].Name != files[i].Name || !bytes.Equal(got[i].Data, files[i].Data) {
+			t.Errorf("file %d = %q, want %q", i, got[i].Name, files[i].Name)
+		}
+	}
+
+	if _, err := Read(bytes.NewReader(buf.Bytes()), "wrong"); !errors.Is(err, ErrBadPassphrase) {
+		t.Errorf("Read with wrong passphrase error = %v, want ErrBadPassphrase", err)
+	}
+	if _, err := Read(bytes.NewReader([]byte("hello")), "correct horse"); !errors.Is(err, ErrNotBackup) {
+		t.Errorf("Read of garbage error = %v, want ErrNotBackup", err)
+	}
+}
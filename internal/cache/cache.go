@@ -3,9 +3,9 @@ package cache
 import (
 	"database/sql"
 	"encoding/json"
-	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
 	"time"
 
 	"github.com/emersion/go-imap/v2"
@@ -37,8 +37,18 @@ type CachedEmail struct {
 	Snippet      string       `json:"snippet"`
 	BodyHTML     string       `json:"body_html"`
 	Unread       bool         `json:"unread"`
+	Answered     bool         `json:"answered,omitempty"`
+	Forwarded    bool         `json:"forwarded,omitempty"`
+	Flagged      bool         `json:"flagged,omitempty"`
+	Size         int64        `json:"size,omitempty"` // RFC822.SIZE, 0 when not fetched
 	References   string       `json:"references,omitempty"`
 	Attachments  []Attachment `json:"attachments,omitempty"`
+
+	ListID              string `json:"list_id,omitempty"`
+	ListUnsubscribe     string `json:"list_unsubscribe,omitempty"`
+	ListUnsubscribePost string `json:"list_unsubscribe_post,omitempty"`
+
+	Labels []string `json:"labels,omitempty"` // Gmail labels, set by SetLabels
 }
 
 // Metadata tracks mailbox sync state
@@ -49,27 +59,49 @@ type Metadata struct {
 
 // Operation types for pending operations
 const (
-	OpDelete    = "delete"
-	OpMoveTrash = "move_trash"
-	OpMarkRead  = "mark_read"
+	OpDelete     = "delete"
+	OpMoveTrash  = "move_trash"
+	OpMarkRead   = "mark_read"
+	OpMarkUnread = "mark_unread"
+	OpMoveSpam   = "move_spam"
+	OpNotSpam    = "not_spam" // move from the spam folder back to INBOX
+	OpArchive    = "archive"
 )
 
 // PendingOp represents a pending email operation to be synced
 type PendingOp struct {
-	ID        int64
-	Account   string
-	Mailbox   string
-	Operation string
-	UID       imap.UID
-	CreatedAt time.Time
-	Retries   int
-	LastError string
+	ID          int64     `json:"id"`
+	Account     string    `json:"account"`
+	Mailbox     string    `json:"mailbox"`
+	Operation   string    `json:"operation"`
+	UID         imap.UID  `json:"uid"`
+	CreatedAt   time.Time `json:"created_at"`
+	Retries     int       `json:"retries"`
+	LastError   string    `json:"last_error,omitempty"`
+	NextAttempt time.Time `json:"next_attempt"`        // not retried before this time
+	FailedAt    time.Time `json:"failed_at,omitempty"` // set once retries are exhausted; the op waits for review
+
+	// Read state the email had when a flag op was queued, FlagStateRead or FlagStateUnread.
+	// Empty when unknown, or when a retry from review should apply the op regardless.
+	ExpectedState string `json:"expected_state,omitempty"`
+}
+
+// Read states recorded with flag ops to detect changes made on other devices
+const (
+	FlagStateRead   = "read"
+	FlagStateUnread = "unread"
+)
+
+// Failed reports whether the operation ran out of retries
+func (op PendingOp) Failed() bool {
+	return !op.FailedAt.IsZero()
 }
 
 // Status constants for op_logs
 const (
-	StatusSuccess = "success"
-	StatusFailed  = "failed"
+	StatusSuccess  = "success"
+	StatusFailed   = "failed"
+	StatusConflict = "conflict" // skipped: the email changed on another device
 )
 
 // OpLog represents a completed operation log entry
@@ -89,8 +121,11 @@ type OpLog struct {
 type Cache struct {
 	db     *sql.DB
 	dbPath string
+	sealer *sealer // encrypts bodies and snippets; nil when the cache is not encrypted
 }
 
+// schema is the baseline schema (migration 1). Don't edit it: later changes are added
+// as steps in migrations, so existing databases are upgraded in order.
 const schema = `
 CREATE TABLE IF NOT EXISTS mailbox_metadata (
     account TEXT NOT NULL,
@@ -116,6 +151,9 @@ CREATE TABLE IF NOT EXISTS emails (
     body_html TEXT NOT NULL DEFAULT '',
     unread INTEGER NOT NULL DEFAULT 1,
     references_hdr TEXT NOT NULL DEFAULT '',
+    list_id TEXT NOT NULL DEFAULT '',
+    list_unsubscribe TEXT NOT NULL DEFAULT '',
+    list_unsubscribe_post TEXT NOT NULL DEFAULT '',
     PRIMARY KEY (account, mailbox, uid)
 );
 
@@ -163,11 +201,29 @@ CREATE TABLE IF NOT EXISTS op_logs (
     processed_at INTEGER NOT NULL
 );
 
+CREATE TABLE IF NOT EXISTS sync_runs (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    account TEXT NOT NULL,
+    mailbox TEXT NOT NULL,
+    started_at INTEGER NOT NULL,
+    duration_ms INTEGER NOT NULL DEFAULT 0,
+    fetched INTEGER NOT NULL DEFAULT 0,
+    error TEXT NOT NULL DEFAULT ''
+);
+
+CREATE TABLE IF NOT EXISTS contacts (
+    email TEXT NOT NULL PRIMARY KEY COLLATE NOCASE,
+    name TEXT NOT NULL DEFAULT '',
+    source TEXT NOT NULL DEFAULT '',
+    updated_at INTEGER NOT NULL DEFAULT 0
+);
+
 CREATE INDEX IF NOT EXISTS idx_emails_date ON emails(account, mailbox, internal_date DESC);
 CREATE INDEX IF NOT EXISTS idx_emails_internal_date ON emails(internal_date);
 CREATE INDEX IF NOT EXISTS idx_pending_ops_account ON pending_ops(account);
 CREATE INDEX IF NOT EXISTS idx_op_logs_account ON op_logs(account);
 CREATE INDEX IF NOT EXISTS idx_op_logs_processed ON op_logs(processed_at DESC);
+CREATE INDEX IF NOT EXISTS idx_sync_runs_account ON sync_runs(account, started_at DESC);
 `
 
 // New creates a new cache instance with SQLite backend
@@ -192,18 +248,24 @@ func NewWithPath(dbPath string) (*Cache, error) {
 }
 
 func openDB(dbPath string) (*Cache, error) {
-	db, err := sql.Open("sqlite3", dbPath+"?_journal=WAL&_timeout=5000&_fk=1")
+	// Several processes share the database: the server, every TUI and CLI commands. The
+	// busy timeout makes a writer wait for the others, and transactions take the write lock
+	// when they begin, as one upgrading from a read fails at once instead of waiting.
+	db, err := sql.Open("sqlite3", dbPath+"?_journal=WAL&_timeout=5000&_fk=1&_txlock=immediate")
 	if err != nil {
 		return nil, err
 	}
 
-	// Create schema
-	if _, err := db.Exec(schema); err != nil {
+	if err := migrate(db); err != nil {
 		db.Close()
-		return nil, fmt.Errorf("failed to create schema: %w", err)
+		return nil, err
 	}
 
 	c := &Cache{db: db, dbPath: dbPath}
+	if err := c.loadEncryption(); err != nil {
+		db.Close()
+		return nil, err
+	}
 
 	// Clean up old JSON cache directory if it exists
 	c.cleanupOldCache()
@@ -231,6 +293,12 @@ func (c *Cache) Close() error {
 	return nil
 }
 
+// Snapshot writes a consistent copy of the database to path
+func (c *Cache) Snapshot(path string) error {
+	_, err := c.db.Exec("VACUUM INTO ?", path)
+	return err
+}
+
 // AcquireLock tries to acquire the sync lock for an account
 // Returns true if lock acquired, false if already locked
 func (c *Cache) AcquireLock(account string) (bool, error) {
@@ -359,11 +427,31 @@ func (c *Cache) SaveMetadata(account, mailbox string, meta *Metadata) error {
 	return err
 }
 
+// Setting returns a value stored in the cache's settings, "" when it isn't set
+func (c *Cache) Setting(key string) (string, error) {
+	var value string
+	err := c.db.QueryRow("SELECT value FROM settings WHERE key = ?", key).Scan(&value)
+	if err == sql.ErrNoRows {
+		return "", nil
+	}
+	return value, err
+}
+
+// SetSetting stores a value in the cache's settings
+func (c *Cache) SetSetting(key, value string) error {
+	_, err := c.db.Exec("INSERT OR REPLACE INTO settings (key, value) VALUES (?, ?)", key, value)
+	return err
+}
+
+// emailColumns are the columns scanEmails reads, in its order
+const emailColumns = `uid, message_id, internal_date, from_addr, reply_to, to_addr, cc,
+		       subject, date, snippet, body_html, unread, references_hdr,
+		       list_id, list_unsubscribe, list_unsubscribe_post, labels, size, flags`
+
 // LoadEmails loads all cached emails for a mailbox, sorted by InternalDate descending
 func (c *Cache) LoadEmails(account, mailbox string) ([]CachedEmail, error) {
 	rows, err := c.db.Query(`
-		SELECT uid, message_id, internal_date, from_addr, reply_to, to_addr, cc,
-		       subject, date, snippet, body_html, unread, references_hdr
+		SELECT `+emailColumns+`
 		FROM emails
 		WHERE account = ? AND mailbox = ?
 		ORDER BY internal_date DESC
@@ -373,34 +461,43 @@ func (c *Cache) LoadEmails(account, mailbox string) ([]CachedEmail, error) {
 	}
 	defer rows.Close()
 
+	return c.scanEmails(account, mailbox, rows), nil
+}
+
+// scanEmails reads the emailColumns of each row, with the emails' attachments
+func (c *Cache) scanEmails(account, mailbox string, rows *sql.Rows) []CachedEmail {
 	var emails []CachedEmail
 	for rows.Next() {
 		var email CachedEmail
 		var uid uint32
 		var internalDate, date int64
-		var unread int
+		var unread, flags int
+		var labels string
 
 		err := rows.Scan(
 			&uid, &email.MessageID, &internalDate, &email.From, &email.ReplyTo,
 			&email.To, &email.Cc, &email.Subject, &date, &email.Snippet, &email.BodyHTML,
-			&unread, &email.References,
+			&unread, &email.References, &email.ListID, &email.ListUnsubscribe, &email.ListUnsubscribePost,
+			&labels, &email.Size, &flags,
 		)
 		if err != nil {
 			continue
 		}
 
 		email.UID = imap.UID(uid)
+		email.Snippet, email.BodyHTML = c.openText(email.Snippet), c.openText(email.BodyHTML)
+		email.Labels = decodeLabels(labels)
 		email.InternalDate = time.Unix(internalDate, 0)
 		email.Date = time.Unix(date, 0)
 		email.Unread = unread == 1
+		email.unpackFlags(flags)
 
 		// Load attachments
 		email.Attachments, _ = c.loadAttachments(account, mailbox, uid)
 
 		emails = append(emails, email)
 	}
-
-	return emails, nil
+	return emails
 }
 
 // loadAttachments loads attachments for an email
@@ -428,47 +525,131 @@ func (c *Cache) loadAttachments(account, mailbox string, uid uint32) ([]Attachme
 
 // LoadEmailsLimit loads up to limit emails, sorted by InternalDate descending
 func (c *Cache) LoadEmailsLimit(account, mailbox string, limit int) ([]CachedEmail, error) {
+	return c.LoadEmailPage(account, mailbox, 0, limit)
+}
+
+// EmailFilter narrows the emails LoadEmailsFiltered returns; zero fields don't filter
+type EmailFilter struct {
+	Since          time.Time // received at or after
+	Before         time.Time // received before
+	UnreadOnly     bool
+	From           string // From header contains, ignoring case
+	Subject        string // subject contains, ignoring case
+	HasAttachments bool
+	Limit          int // 0 for all that match
+}
+
+// where returns the SQL condition selecting the mailbox's emails that match the filter,
+// and its arguments
+func (filter EmailFilter) where(account, mailbox string) (string, []any) {
+	where := "account = ? AND mailbox = ?"
+	args := []any{account, mailbox}
+	if !filter.Since.IsZero() {
+		where += " AND internal_date >= ?"
+		args = append(args, filter.Since.Unix())
+	}
+	if !filter.Before.IsZero() {
+		where += " AND internal_date < ?"
+		args = append(args, filter.Before.Unix())
+	}
+	if filter.UnreadOnly {
+		where += " AND unread = 1"
+	}
+	if filter.From != "" {
+		where += ` AND from_addr LIKE ? ESCAPE '\'`
+		args = append(args, likePattern(filter.From))
+	}
+	if filter.Subject != "" {
+		where += ` AND subject LIKE ? ESCAPE '\'`
+		args = append(args, likePattern(filter.Subject))
+	}
+	if filter.HasAttachments {
+		where += ` AND EXISTS (SELECT 1 FROM attachments a
+			WHERE a.account = emails.account AND a.mailbox = emails.mailbox AND a.email_uid = emails.uid)`
+	}
+	return where, args
+}
+
+// likePattern matches text anywhere, with LIKE's wildcards in it taken literally
+func likePattern(text string) string {
+	text = strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`).Replace(text)
+	return "%" + text + "%"
+}
+
+// LoadEmailsFiltered loads the emails matching filter, sorted by InternalDate
+// descending. The dates use the (account, mailbox, internal_date) index and unread
+// emails the partial index on them.
+func (c *Cache) LoadEmailsFiltered(account, mailbox string, filter EmailFilter) ([]CachedEmail, error) {
+	where, args := filter.where(account, mailbox)
+	limit := -1 // SQLite's no limit
+	if filter.Limit > 0 {
+		limit = filter.Limit
+	}
+
 	rows, err := c.db.Query(`
-		SELECT uid, message_id, internal_date, from_addr, reply_to, to_addr, cc,
-		       subject, date, snippet, body_html, unread, references_hdr
+		SELECT `+emailColumns+`
 		FROM emails
-		WHERE account = ? AND mailbox = ?
-		ORDER BY internal_date DESC
+		WHERE `+where+`
+		ORDER BY internal_date DESC, uid DESC
 		LIMIT ?
-	`, account, mailbox, limit)
+	`, append(args, limit)...)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
 
-	var emails []CachedEmail
-	for rows.Next() {
-		var email CachedEmail
-		var uid uint32
-		var internalDate, date int64
-		var unread int
+	return c.scanEmails(account, mailbox, rows), nil
+}
 
-		err := rows.Scan(
-			&uid, &email.MessageID, &internalDate, &email.From, &email.ReplyTo,
-			&email.To, &email.Cc, &email.Subject, &date, &email.Snippet, &email.BodyHTML,
-			&unread, &email.References,
-		)
-		if err != nil {
-			continue
-		}
+// LoadEmailPage loads up to limit emails starting at offset, sorted by InternalDate
+// descending, so list views can page through large mailboxes without loading them whole
+func (c *Cache) LoadEmailPage(account, mailbox string, offset, limit int) ([]CachedEmail, error) {
+	return c.LoadEmailPageSorted(account, mailbox, offset, limit, SortDate)
+}
 
-		email.UID = imap.UID(uid)
-		email.InternalDate = time.Unix(internalDate, 0)
-		email.Date = time.Unix(date, 0)
-		email.Unread = unread == 1
+// Sort orders of list pages
+const (
+	SortDate    = "date"    // newest first
+	SortSize    = "size"    // largest first; emails cached before sizes were fetched last
+	SortSender  = "sender"  // by sender name, A to Z
+	SortSubject = "subject" // A to Z
+	SortUnread  = "unread"  // unread first, then newest
+)
 
-		// Load attachments
-		email.Attachments, _ = c.loadAttachments(account, mailbox, uid)
+// SortOrders lists the sort orders in the order the list cycles through them
+var SortOrders = []string{SortDate, SortSize, SortSender, SortSubject, SortUnread}
+
+// orderBy returns the ORDER BY terms of a sort order, newest first among equals.
+// Unknown orders sort by date.
+func orderBy(sort string) string {
+	switch sort {
+	case SortSize:
+		return "size DESC, internal_date DESC, uid DESC"
+	case SortSender:
+		return "from_addr COLLATE NOCASE, internal_date DESC, uid DESC"
+	case SortSubject:
+		return "subject COLLATE NOCASE, internal_date DESC, uid DESC"
+	case SortUnread:
+		return "unread DESC, internal_date DESC, uid DESC"
+	}
+	return "internal_date DESC, uid DESC"
+}
 
-		emails = append(emails, email)
+// LoadEmailPageSorted is LoadEmailPage in one of the sort orders
+func (c *Cache) LoadEmailPageSorted(account, mailbox string, offset, limit int, sort string) ([]CachedEmail, error) {
+	rows, err := c.db.Query(`
+		SELECT `+emailColumns+`
+		FROM emails
+		WHERE account = ? AND mailbox = ?
+		ORDER BY `+orderBy(sort)+`
+		LIMIT ? OFFSET ?
+	`, account, mailbox, limit, offset)
+	if err != nil {
+		return nil, err
 	}
+	defer rows.Close()
 
-	return emails, nil
+	return c.scanEmails(account, mailbox, rows), nil
 }
 
 // SaveEmail saves a single email to cache
@@ -487,13 +668,17 @@ func (c *Cache) SaveEmail(account, mailbox string, email CachedEmail) error {
 	_, err = tx.Exec(`
 		INSERT OR REPLACE INTO emails
 		(account, mailbox, uid, message_id, internal_date, from_addr, reply_to,
-		 to_addr, cc, subject, date, snippet, body_html, unread, references_hdr)
-		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+		 to_addr, cc, subject, date, snippet, body_html, unread, references_hdr,
+		 list_id, list_unsubscribe, list_unsubscribe_post, labels, size, flags)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, `+keepLabels+`, `+keepSize+`, ?)
 	`,
 		account, mailbox, uint32(email.UID), email.MessageID,
 		email.InternalDate.Unix(), email.From, email.ReplyTo, email.To, email.Cc,
-		email.Subject, email.Date.Unix(), email.Snippet, email.BodyHTML,
-		unread, email.References,
+		email.Subject, email.Date.Unix(), c.sealText(email.Snippet), c.sealText(email.BodyHTML),
+		unread, email.References, email.ListID, email.ListUnsubscribe, email.ListUnsubscribePost,
+		account, mailbox, uint32(email.UID),
+		email.Size, account, mailbox, uint32(email.UID),
+		email.packFlags(),
 	)
 	if err != nil {
 		return err
@@ -537,13 +722,15 @@ func (c *Cache) InsertEmailMetadataIfMissing(account, mailbox string, email Cach
 	result, err := tx.Exec(`
 		INSERT OR IGNORE INTO emails
 		(account, mailbox, uid, message_id, internal_date, from_addr, reply_to,
-		 to_addr, cc, subject, date, snippet, body_html, unread, references_hdr)
-		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+		 to_addr, cc, subject, date, snippet, body_html, unread, references_hdr,
+		 list_id, list_unsubscribe, list_unsubscribe_post, size, flags)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
 	`,
 		account, mailbox, uint32(email.UID), email.MessageID,
 		email.InternalDate.Unix(), email.From, email.ReplyTo, email.To, email.Cc,
-		email.Subject, email.Date.Unix(), email.Snippet, email.BodyHTML,
-		unread, email.References,
+		email.Subject, email.Date.Unix(), c.sealText(email.Snippet), c.sealText(email.BodyHTML),
+		unread, email.References, email.ListID, email.ListUnsubscribe, email.ListUnsubscribePost,
+		email.Size, email.packFlags(),
 	)
 	if err != nil {
 		return false, err
@@ -577,6 +764,101 @@ func (c *Cache) InsertEmailMetadataIfMissing(account, mailbox string, email Cach
 	return true, nil
 }
 
+// SaveEmailsBatch saves many emails in a single transaction with prepared statements,
+// returning how many were written. With overwrite, cached emails are replaced as in
+// SaveEmail; without it, existing rows are kept as in InsertEmailMetadataIfMissing,
+// apart from their answered, forwarded and flagged marks, which follow the server.
+// An email that fails to save is skipped rather than failing the batch.
+func (c *Cache) SaveEmailsBatch(account, mailbox string, emails []CachedEmail, overwrite bool) (int, error) {
+	if len(emails) == 0 {
+		return 0, nil
+	}
+
+	tx, err := c.db.Begin()
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	insert := "INSERT OR IGNORE"
+	if overwrite {
+		insert = "INSERT OR REPLACE"
+	}
+	emailStmt, err := tx.Prepare(insert + ` INTO emails
+		(account, mailbox, uid, message_id, internal_date, from_addr, reply_to,
+		 to_addr, cc, subject, date, snippet, body_html, unread, references_hdr,
+		 list_id, list_unsubscribe, list_unsubscribe_post, labels, size, flags)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ` + keepLabels + `, ` + keepSize + `, ?)
+	`)
+	if err != nil {
+		return 0, err
+	}
+	defer emailStmt.Close()
+
+	marksStmt, err := tx.Prepare("UPDATE emails SET flags = ? WHERE account = ? AND mailbox = ? AND uid = ?")
+	if err != nil {
+		return 0, err
+	}
+	defer marksStmt.Close()
+
+	deleteAttStmt, err := tx.Prepare("DELETE FROM attachments WHERE account = ? AND mailbox = ? AND email_uid = ?")
+	if err != nil {
+		return 0, err
+	}
+	defer deleteAttStmt.Close()
+
+	attStmt, err := tx.Prepare(`
+		INSERT OR IGNORE INTO attachments
+		(account, mailbox, email_uid, part_id, filename, content_type, size, encoding)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?)
+	`)
+	if err != nil {
+		return 0, err
+	}
+	defer attStmt.Close()
+
+	saved := 0
+	for _, email := range emails {
+		unread := 0
+		if email.Unread {
+			unread = 1
+		}
+
+		result, err := emailStmt.Exec(
+			account, mailbox, uint32(email.UID), email.MessageID,
+			email.InternalDate.Unix(), email.From, email.ReplyTo, email.To, email.Cc,
+			email.Subject, email.Date.Unix(), c.sealText(email.Snippet), c.sealText(email.BodyHTML),
+			unread, email.References, email.ListID, email.ListUnsubscribe, email.ListUnsubscribePost,
+			account, mailbox, uint32(email.UID),
+			email.Size, account, mailbox, uint32(email.UID),
+			email.packFlags(),
+		)
+		if err != nil {
+			continue
+		}
+		if affected, err := result.RowsAffected(); err != nil || affected == 0 {
+			marksStmt.Exec(email.packFlags(), account, mailbox, uint32(email.UID))
+			continue
+		}
+
+		if overwrite {
+			deleteAttStmt.Exec(account, mailbox, uint32(email.UID))
+		}
+		for _, att := range email.Attachments {
+			attStmt.Exec(
+				account, mailbox, uint32(email.UID), att.PartID, att.Filename,
+				att.ContentType, att.Size, att.Encoding,
+			)
+		}
+		saved++
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, err
+	}
+	return saved, nil
+}
+
 // DeleteEmail deletes an email from cache
 func (c *Cache) DeleteEmail(account, mailbox string, uid imap.UID) error {
 	_, err := c.db.Exec(
@@ -637,17 +919,18 @@ func (c *Cache) GetEmail(account, mailbox string, uid imap.UID) (*CachedEmail, e
 	var email CachedEmail
 	var uidVal uint32
 	var internalDate, date int64
-	var unread int
+	var unread, flags int
+	var labels string
 
 	err := c.db.QueryRow(`
-		SELECT uid, message_id, internal_date, from_addr, reply_to, to_addr, cc,
-		       subject, date, snippet, body_html, unread, references_hdr
+		SELECT `+emailColumns+`
 		FROM emails
 		WHERE account = ? AND mailbox = ? AND uid = ?
 	`, account, mailbox, uint32(uid)).Scan(
 		&uidVal, &email.MessageID, &internalDate, &email.From, &email.ReplyTo,
 		&email.To, &email.Cc, &email.Subject, &date, &email.Snippet, &email.BodyHTML,
-		&unread, &email.References,
+		&unread, &email.References, &email.ListID, &email.ListUnsubscribe, &email.ListUnsubscribePost,
+		&labels, &email.Size, &flags,
 	)
 
 	if err == sql.ErrNoRows {
@@ -658,9 +941,12 @@ func (c *Cache) GetEmail(account, mailbox string, uid imap.UID) (*CachedEmail, e
 	}
 
 	email.UID = imap.UID(uidVal)
+	email.Snippet, email.BodyHTML = c.openText(email.Snippet), c.openText(email.BodyHTML)
+	email.Labels = decodeLabels(labels)
 	email.InternalDate = time.Unix(internalDate, 0)
 	email.Date = time.Unix(date, 0)
 	email.Unread = unread == 1
+	email.unpackFlags(flags)
 
 	// Load attachments
 	email.Attachments, _ = c.loadAttachments(account, mailbox, uidVal)
@@ -668,6 +954,61 @@ func (c *Cache) GetEmail(account, mailbox string, uid imap.UID) (*CachedEmail, e
 	return &email, nil
 }
 
+// FindUIDByMessageID looks up a cached email's UID by its Message-ID header
+func (c *Cache) FindUIDByMessageID(account, mailbox, messageID string) (imap.UID, bool, error) {
+	var uid uint32
+	err := c.db.QueryRow(
+		"SELECT uid FROM emails WHERE account = ? AND mailbox = ? AND message_id IN (?, ?) LIMIT 1",
+		account, mailbox, messageID, "<"+messageID+">",
+	).Scan(&uid)
+	if err == sql.ErrNoRows {
+		return 0, false, nil
+	}
+	if err != nil {
+		return 0, false, err
+	}
+	return imap.UID(uid), true, nil
+}
+
+// OldestUID returns the lowest cached UID of a mailbox, the point older pages are fetched from
+func (c *Cache) OldestUID(account, mailbox string) (imap.UID, bool, error) {
+	var uid sql.NullInt64
+	err := c.db.QueryRow("SELECT MIN(uid) FROM emails WHERE account = ? AND mailbox = ?", account, mailbox).Scan(&uid)
+	if err != nil || !uid.Valid {
+		return 0, false, err
+	}
+	return imap.UID(uid.Int64), true, nil
+}
+
+// Bits of the flags column
+const (
+	flagAnswered = 1 << iota
+	flagForwarded
+	flagFlagged
+)
+
+// packFlags returns the flags column for an email's answered, forwarded and flagged state
+func (e CachedEmail) packFlags() int {
+	flags := 0
+	if e.Answered {
+		flags |= flagAnswered
+	}
+	if e.Forwarded {
+		flags |= flagForwarded
+	}
+	if e.Flagged {
+		flags |= flagFlagged
+	}
+	return flags
+}
+
+// unpackFlags sets an email's answered, forwarded and flagged state from the flags column
+func (e *CachedEmail) unpackFlags(flags int) {
+	e.Answered = flags&flagAnswered != 0
+	e.Forwarded = flags&flagForwarded != 0
+	e.Flagged = flags&flagFlagged != 0
+}
+
 // UpdateEmailFlags updates only the Unread flag of a cached email
 func (c *Cache) UpdateEmailFlags(account, mailbox string, uid imap.UID, unread bool) error {
 	unreadVal := 0
@@ -682,15 +1023,86 @@ func (c *Cache) UpdateEmailFlags(account, mailbox string, uid imap.UID, unread b
 	return err
 }
 
+// UpdateEmailMarks updates only the answered, forwarded and flagged state of a cached
+// email, taking them from e
+func (c *Cache) UpdateEmailMarks(account, mailbox string, e CachedEmail) error {
+	_, err := c.db.Exec(
+		"UPDATE emails SET flags = ? WHERE account = ? AND mailbox = ? AND uid = ?",
+		e.packFlags(), account, mailbox, uint32(e.UID),
+	)
+	return err
+}
+
+// MarkEmailAnswered sets the answered mark of a cached email, keeping its other marks
+func (c *Cache) MarkEmailAnswered(account, mailbox string, uid imap.UID) error {
+	_, err := c.db.Exec(
+		"UPDATE emails SET flags = flags | ? WHERE account = ? AND mailbox = ? AND uid = ?",
+		flagAnswered, account, mailbox, uint32(uid),
+	)
+	return err
+}
+
 // UpdateEmailBody updates the body content of a cached email
 func (c *Cache) UpdateEmailBody(account, mailbox string, uid imap.UID, bodyHTML, snippet string) error {
 	_, err := c.db.Exec(
 		"UPDATE emails SET body_html = ?, snippet = ? WHERE account = ? AND mailbox = ? AND uid = ?",
-		bodyHTML, snippet, account, mailbox, uint32(uid),
+		c.sealText(bodyHTML), c.sealText(snippet), account, mailbox, uint32(uid),
 	)
 	return err
 }
 
+// keepLabels carries a cached email's labels over when the row is replaced; labels
+// are only written by SetLabels
+const keepLabels = "COALESCE((SELECT labels FROM emails WHERE account = ? AND mailbox = ? AND uid = ?), '')"
+
+// keepSize carries a cached email's size over when the row is replaced by one whose
+// size wasn't fetched
+const keepSize = "COALESCE(NULLIF(?, 0), (SELECT size FROM emails WHERE account = ? AND mailbox = ? AND uid = ?), 0)"
+
+// SetLabels stores the Gmail labels of cached emails. UIDs that aren't cached are ignored.
+func (c *Cache) SetLabels(account, mailbox string, labels map[imap.UID][]string) error {
+	if len(labels) == 0 {
+		return nil
+	}
+	tx, err := c.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	stmt, err := tx.Prepare("UPDATE emails SET labels = ? WHERE account = ? AND mailbox = ? AND uid = ?")
+	if err != nil {
+		return err
+	}
+	defer stmt.Close()
+
+	for uid, l := range labels {
+		if _, err := stmt.Exec(encodeLabels(l), account, mailbox, uint32(uid)); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+func encodeLabels(labels []string) string {
+	if len(labels) == 0 {
+		return ""
+	}
+	data, _ := json.Marshal(labels)
+	return string(data)
+}
+
+func decodeLabels(value string) []string {
+	if value == "" {
+		return nil
+	}
+	var labels []string
+	if err := json.Unmarshal([]byte(value), &labels); err != nil {
+		return nil
+	}
+	return labels
+}
+
 // IsFresh returns true if the cache was synced within the given duration
 func (c *Cache) IsFresh(account, mailbox string, maxAge time.Duration) bool {
 	meta, err := c.LoadMetadata(account, mailbox)
@@ -721,22 +1133,41 @@ func (c *Cache) AddPendingOp(account, mailbox, operation string, uid imap.UID) e
 	return err
 }
 
-// GetPendingOps returns all pending operations, optionally filtered by account
-func (c *Cache) GetPendingOps(account string) ([]PendingOp, error) {
-	var rows *sql.Rows
-	var err error
+// AddFlagOp queues a mark read/unread op along with the read state the email had, so a
+// change made on another device in the meantime isn't overwritten
+func (c *Cache) AddFlagOp(account, mailbox, operation string, uid imap.UID, expectedState string) error {
+	_, err := c.db.Exec(`
+		INSERT INTO pending_ops (account, mailbox, operation, uid, created_at, expected_state)
+		VALUES (?, ?, ?, ?, ?, ?)
+	`, account, mailbox, operation, uint32(uid), time.Now().Unix(), expectedState)
+	return err
+}
 
+// GetPendingOps returns all pending operations, optionally filtered by account,
+// including failed ones awaiting review
+func (c *Cache) GetPendingOps(account string) ([]PendingOp, error) {
 	if account == "" {
-		rows, err = c.db.Query(`
-			SELECT id, account, mailbox, operation, uid, created_at, retries, last_error
-			FROM pending_ops ORDER BY created_at ASC
-		`)
-	} else {
-		rows, err = c.db.Query(`
-			SELECT id, account, mailbox, operation, uid, created_at, retries, last_error
-			FROM pending_ops WHERE account = ? ORDER BY created_at ASC
-		`, account)
+		return c.queryPendingOps("1 = 1")
 	}
+	return c.queryPendingOps("account = ?", account)
+}
+
+// GetDuePendingOps returns the operations whose retry time has come, skipping failed ones
+func (c *Cache) GetDuePendingOps(now time.Time) ([]PendingOp, error) {
+	return c.queryPendingOps("failed_at = 0 AND next_attempt <= ?", now.Unix())
+}
+
+// GetFailedOps returns the operations of an account that ran out of retries
+func (c *Cache) GetFailedOps(account string) ([]PendingOp, error) {
+	return c.queryPendingOps("failed_at > 0 AND account = ?", account)
+}
+
+func (c *Cache) queryPendingOps(where string, args ...any) ([]PendingOp, error) {
+	rows, err := c.db.Query(`
+		SELECT id, account, mailbox, operation, uid, created_at, retries, last_error,
+		       next_attempt, failed_at, expected_state
+		FROM pending_ops WHERE `+where+` ORDER BY created_at ASC
+	`, args...)
 	if err != nil {
 		return nil, err
 	}
@@ -746,13 +1177,17 @@ func (c *Cache) GetPendingOps(account string) ([]PendingOp, error) {
 	for rows.Next() {
 		var op PendingOp
 		var uid uint32
-		var createdAt int64
+		var createdAt, nextAttempt, failedAt int64
 		if err := rows.Scan(&op.ID, &op.Account, &op.Mailbox, &op.Operation,
-			&uid, &createdAt, &op.Retries, &op.LastError); err != nil {
+			&uid, &createdAt, &op.Retries, &op.LastError, &nextAttempt, &failedAt, &op.ExpectedState); err != nil {
 			continue
 		}
 		op.UID = imap.UID(uid)
 		op.CreatedAt = time.Unix(createdAt, 0)
+		op.NextAttempt = time.Unix(nextAttempt, 0)
+		if failedAt > 0 {
+			op.FailedAt = time.Unix(failedAt, 0)
+		}
 		ops = append(ops, op)
 	}
 	return ops, nil
@@ -764,14 +1199,38 @@ func (c *Cache) RemovePendingOp(id int64) error {
 	return err
 }
 
-// UpdatePendingOpError updates the retry count and last error for a pending operation
-func (c *Cache) UpdatePendingOpError(id int64, errMsg string) error {
+// UpdatePendingOpError records a failed attempt and when to try the operation again
+func (c *Cache) UpdatePendingOpError(id int64, errMsg string, retryAt time.Time) error {
+	_, err := c.db.Exec(`
+		UPDATE pending_ops SET retries = retries + 1, last_error = ?, next_attempt = ? WHERE id = ?
+	`, errMsg, retryAt.Unix(), id)
+	return err
+}
+
+// FailPendingOp records a final failed attempt and sets the operation aside for review
+func (c *Cache) FailPendingOp(id int64, errMsg string) error {
 	_, err := c.db.Exec(`
-		UPDATE pending_ops SET retries = retries + 1, last_error = ? WHERE id = ?
-	`, errMsg, id)
+		UPDATE pending_ops SET retries = retries + 1, last_error = ?, failed_at = ? WHERE id = ?
+	`, errMsg, time.Now().Unix(), id)
 	return err
 }
 
+// RetryFailedOp puts a failed operation back in the queue with a fresh retry budget. A flag
+// op retried from review is applied even if the email changed on another device.
+func (c *Cache) RetryFailedOp(id int64) error {
+	_, err := c.db.Exec(`
+		UPDATE pending_ops SET retries = 0, next_attempt = 0, failed_at = 0, expected_state = '' WHERE id = ?
+	`, id)
+	return err
+}
+
+// CountFailedOps returns the number of operations of an account awaiting review
+func (c *Cache) CountFailedOps(account string) (int, error) {
+	var count int
+	err := c.db.QueryRow("SELECT COUNT(*) FROM pending_ops WHERE failed_at > 0 AND account = ?", account).Scan(&count)
+	return count, err
+}
+
 // GetPendingOpsCount returns the count of pending operations
 func (c *Cache) GetPendingOpsCount() (int, error) {
 	var count int
@@ -786,6 +1245,72 @@ func (c *Cache) CountEmails(account, mailbox string) (int, error) {
 	return count, err
 }
 
+// CountEmailsFiltered returns the count of emails matching filter, ignoring its Limit
+func (c *Cache) CountEmailsFiltered(account, mailbox string, filter EmailFilter) (int, error) {
+	where, args := filter.where(account, mailbox)
+	var count int
+	err := c.db.QueryRow("SELECT COUNT(*) FROM emails WHERE "+where, args...).Scan(&count)
+	return count, err
+}
+
+// EmailSize is the sender and approximate size of a cached email, for sizing up what a
+// cleanup would remove
+type EmailSize struct {
+	UID    imap.UID
+	From   string
+	ListID string
+	Size   int64 // in bytes: RFC822.SIZE, or the cached body and attachments
+}
+
+// LoadEmailSizes returns the sender and size of each cached email in a mailbox, newest
+// first. Emails cached before their size was fetched count their cached body and
+// attachments, where a body not fetched yet counts nothing.
+func (c *Cache) LoadEmailSizes(account, mailbox string) ([]EmailSize, error) {
+	rows, err := c.db.Query(`
+		SELECT e.uid, e.from_addr, e.list_id,
+		       CASE WHEN e.size > 0 THEN e.size
+		            ELSE length(e.body_html) + length(e.snippet) + COALESCE(SUM(a.size), 0) END
+		FROM emails e
+		LEFT JOIN attachments a
+		  ON a.account = e.account AND a.mailbox = e.mailbox AND a.email_uid = e.uid
+		WHERE e.account = ? AND e.mailbox = ?
+		GROUP BY e.uid
+		ORDER BY e.internal_date DESC, e.uid DESC
+	`, account, mailbox)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var sizes []EmailSize
+	for rows.Next() {
+		var s EmailSize
+		var uid uint32
+		if err := rows.Scan(&uid, &s.From, &s.ListID, &s.Size); err != nil {
+			return nil, err
+		}
+		s.UID = imap.UID(uid)
+		sizes = append(sizes, s)
+	}
+	return sizes, rows.Err()
+}
+
+// CountNewer returns the count of emails received at or after the given time, which is
+// the position of the first older email in the newest-first list
+func (c *Cache) CountNewer(account, mailbox string, since time.Time) (int, error) {
+	var count int
+	err := c.db.QueryRow("SELECT COUNT(*) FROM emails WHERE account = ? AND mailbox = ? AND internal_date >= ?",
+		account, mailbox, since.Unix()).Scan(&count)
+	return count, err
+}
+
+// CountUnread returns the count of unread emails for an account/mailbox
+func (c *Cache) CountUnread(account, mailbox string) (int, error) {
+	var count int
+	err := c.db.QueryRow("SELECT COUNT(*) FROM emails WHERE account = ? AND mailbox = ? AND unread = 1", account, mailbox).Scan(&count)
+	return count, err
+}
+
 // LogOp inserts a completed operation into op_logs
 func (c *Cache) LogOp(op PendingOp, status string, errMsg string) error {
 	_, err := c.db.Exec(`
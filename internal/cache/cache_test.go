@@ -1,7 +1,12 @@
 package cache
 
 import (
+	"database/sql"
+	"fmt"
 	"os"
+	"path/filepath"
+	"strings"
+	"sync"
 	"testing"
 	"time"
 
@@ -82,6 +87,281 @@ func TestCacheSaveLoadAndFlags(t *testing.T) {
 	}
 }
 
+func TestCacheCountUnread(t *testing.T) {
+	setTempHome(t)
+
+	c, err := New()
+	if err != nil {
+		t.Fatalf("New() error: %v", err)
+	}
+	defer c.Close()
+
+	account := "user@example.com"
+	now := time.Now()
+	for uid := 1; uid <= 4; uid++ {
+		email := CachedEmail{UID: imap.UID(uid), InternalDate: now, Unread: uid%2 == 1}
+		if err := c.SaveEmail(account, "INBOX", email); err != nil {
+			t.Fatalf("SaveEmail %d error: %v", uid, err)
+		}
+	}
+	if err := c.SaveEmail(account, "Archive", CachedEmail{UID: 1, InternalDate: now, Unread: true}); err != nil {
+		t.Fatalf("SaveEmail archive error: %v", err)
+	}
+
+	if n, err := c.CountUnread(account, "INBOX"); err != nil || n != 2 {
+		t.Fatalf("CountUnread INBOX = %d, %v; want 2", n, err)
+	}
+	if err := c.UpdateEmailFlags(account, "INBOX", 1, false); err != nil {
+		t.Fatalf("UpdateEmailFlags error: %v", err)
+	}
+	if n, err := c.CountUnread(account, "INBOX"); err != nil || n != 1 {
+		t.Fatalf("CountUnread after read = %d, %v; want 1", n, err)
+	}
+	if n, err := c.CountUnread("other@example.com", "INBOX"); err != nil || n != 0 {
+		t.Fatalf("CountUnread other account = %d, %v; want 0", n, err)
+	}
+}
+
+func TestCacheCountNewer(t *testing.T) {
+	setTempHome(t)
+
+	c, err := New()
+	if err != nil {
+		t.Fatalf("New() error: %v", err)
+	}
+	defer c.Close()
+
+	account := "user@example.com"
+	day := time.Date(2024, 12, 1, 0, 0, 0, 0, time.UTC)
+	for uid := 1; uid <= 5; uid++ {
+		email := CachedEmail{UID: imap.UID(uid), InternalDate: day.AddDate(0, 0, uid-3)}
+		if err := c.SaveEmail(account, "INBOX", email); err != nil {
+			t.Fatalf("SaveEmail %d error: %v", uid, err)
+		}
+	}
+
+	tests := []struct {
+		since time.Time
+		want  int
+	}{
+		{day, 3},
+		{day.Add(time.Hour), 2},
+		{day.AddDate(0, 0, -10), 5},
+		{day.AddDate(0, 0, 10), 0},
+	}
+	for _, tt := range tests {
+		if n, err := c.CountNewer(account, "INBOX", tt.since); err != nil || n != tt.want {
+			t.Errorf("CountNewer(%v) = %d, %v; want %d", tt.since, n, err, tt.want)
+		}
+	}
+}
+
+func TestCacheLoadEmailPage(t *testing.T) {
+	setTempHome(t)
+
+	c, err := New()
+	if err != nil {
+		t.Fatalf("New() error: %v", err)
+	}
+	defer c.Close()
+
+	account := "user@example.com"
+	mailbox := "INBOX"
+	now := time.Now()
+
+	for uid := 1; uid <= 5; uid++ {
+		email := CachedEmail{
+			UID:          imap.UID(uid),
+			InternalDate: now.Add(time.Duration(uid) * time.Minute),
+		}
+		if err := c.SaveEmail(account, mailbox, email); err != nil {
+			t.Fatalf("SaveEmail %d error: %v", uid, err)
+		}
+	}
+
+	page, err := c.LoadEmailPage(account, mailbox, 2, 2)
+	if err != nil {
+		t.Fatalf("LoadEmailPage error: %v", err)
+	}
+	if len(page) != 2 || page[0].UID != 3 || page[1].UID != 2 {
+		t.Fatalf("expected UIDs [3 2], got %d emails: %+v", len(page), page)
+	}
+
+	last, err := c.LoadEmailPage(account, mailbox, 4, 2)
+	if err != nil {
+		t.Fatalf("LoadEmailPage last error: %v", err)
+	}
+	if len(last) != 1 || last[0].UID != 1 {
+		t.Fatalf("expected last page with UID 1, got %+v", last)
+	}
+}
+
+func TestCacheLoadEmailPageSorted(t *testing.T) {
+	setTempHome(t)
+
+	c, err := New()
+	if err != nil {
+		t.Fatalf("New() error: %v", err)
+	}
+	defer c.Close()
+
+	account := "user@example.com"
+	mailbox := "INBOX"
+	now := time.Now()
+
+	emails := []CachedEmail{
+		{UID: 1, From: "carol@example.com", Subject: "beta", Size: 300, InternalDate: now.Add(1 * time.Minute)},
+		{UID: 2, From: "Alice <alice@example.com>", Subject: "Gamma", Size: 100, Unread: true, InternalDate: now.Add(2 * time.Minute)},
+		{UID: 3, From: "bob@example.com", Subject: "alpha", Size: 200, InternalDate: now.Add(3 * time.Minute)},
+	}
+	for _, email := range emails {
+		if err := c.SaveEmail(account, mailbox, email); err != nil {
+			t.Fatalf("SaveEmail %d error: %v", email.UID, err)
+		}
+	}
+
+	// Saving an email again without its size keeps the size already cached
+	if err := c.SaveEmail(account, mailbox, CachedEmail{UID: 1, From: "carol@example.com", Subject: "beta", InternalDate: now.Add(1 * time.Minute)}); err != nil {
+		t.Fatalf("SaveEmail without size error: %v", err)
+	}
+
+	tests := []struct {
+		sort string
+		want []imap.UID
+	}{
+		{SortDate, []imap.UID{3, 2, 1}},
+		{SortSize, []imap.UID{1, 3, 2}},
+		{SortSender, []imap.UID{2, 3, 1}},
+		{SortSubject, []imap.UID{3, 1, 2}},
+		{SortUnread, []imap.UID{2, 3, 1}},
+	}
+	for _, tt := range tests {
+		page, err := c.LoadEmailPageSorted(account, mailbox, 0, 10, tt.sort)
+		if err != nil {
+			t.Fatalf("LoadEmailPageSorted(%s) error: %v", tt.sort, err)
+		}
+		var got []imap.UID
+		for _, email := range page {
+			got = append(got, email.UID)
+		}
+		if fmt.Sprint(got) != fmt.Sprint(tt.want) {
+			t.Errorf("sort %s: got UIDs %v, want %v", tt.sort, got, tt.want)
+		}
+	}
+
+	email, err := c.GetEmail(account, mailbox, 1)
+	if err != nil || email == nil || email.Size != 300 {
+		t.Fatalf("GetEmail size = %+v (%v), want 300", email, err)
+	}
+}
+
+func TestCacheLoadEmailsFiltered(t *testing.T) {
+	setTempHome(t)
+
+	c, err := New()
+	if err != nil {
+		t.Fatalf("New() error: %v", err)
+	}
+	defer c.Close()
+
+	account := "user@example.com"
+	day := time.Date(2024, 12, 1, 0, 0, 0, 0, time.UTC)
+	for uid := 1; uid <= 5; uid++ {
+		email := CachedEmail{UID: imap.UID(uid), InternalDate: day.AddDate(0, 0, uid-3), Unread: uid%2 == 1, From: "Bob <bob@example.com>"}
+		if uid <= 2 {
+			email.From = "Team <team@example.com>"
+		}
+		if uid == 4 {
+			email.Subject = "50% off"
+		}
+		if uid == 3 {
+			email.Attachments = []Attachment{{PartID: "2", Filename: "report.pdf"}}
+		}
+		if err := c.SaveEmail(account, "INBOX", email); err != nil {
+			t.Fatalf("SaveEmail %d error: %v", uid, err)
+		}
+	}
+
+	tests := []struct {
+		name   string
+		filter EmailFilter
+		want   []imap.UID
+	}{
+		{"all", EmailFilter{}, []imap.UID{5, 4, 3, 2, 1}},
+		{"since", EmailFilter{Since: day}, []imap.UID{5, 4, 3}},
+		{"before", EmailFilter{Before: day}, []imap.UID{2, 1}},
+		{"between", EmailFilter{Since: day.AddDate(0, 0, -1), Before: day.AddDate(0, 0, 1)}, []imap.UID{3, 2}},
+		{"unread", EmailFilter{UnreadOnly: true}, []imap.UID{5, 3, 1}},
+		{"unread since", EmailFilter{Since: day, UnreadOnly: true}, []imap.UID{5, 3}},
+		{"limit", EmailFilter{Since: day, Limit: 2}, []imap.UID{5, 4}},
+		{"none", EmailFilter{Since: day.AddDate(0, 0, 10)}, nil},
+		{"from", EmailFilter{From: "TEAM@"}, []imap.UID{2, 1}},
+		{"from unread", EmailFilter{From: "team", UnreadOnly: true}, []imap.UID{1}},
+		{"subject", EmailFilter{Subject: "50%"}, []imap.UID{4}},
+		{"subject wildcard", EmailFilter{Subject: "5_%"}, nil},
+		{"attachments", EmailFilter{HasAttachments: true}, []imap.UID{3}},
+	}
+	for _, tt := range tests {
+		emails, err := c.LoadEmailsFiltered(account, "INBOX", tt.filter)
+		if err != nil {
+			t.Fatalf("%s: LoadEmailsFiltered error: %v", tt.name, err)
+		}
+		var got []imap.UID
+		for _, e := range emails {
+			got = append(got, e.UID)
+		}
+		if fmt.Sprint(got) != fmt.Sprint(tt.want) {
+			t.Errorf("%s: got UIDs %v, want %v", tt.name, got, tt.want)
+		}
+		if tt.filter.Limit == 0 {
+			count, err := c.CountEmailsFiltered(account, "INBOX", tt.filter)
+			if err != nil {
+				t.Fatalf("%s: CountEmailsFiltered error: %v", tt.name, err)
+			}
+			if count != len(tt.want) {
+				t.Errorf("%s: count %d, want %d", tt.name, count, len(tt.want))
+			}
+		}
+	}
+}
+
+func TestCacheLoadEmailSizes(t *testing.T) {
+	setTempHome(t)
+
+	c, err := New()
+	if err != nil {
+		t.Fatalf("New() error: %v", err)
+	}
+	defer c.Close()
+
+	account := "user@example.com"
+	now := time.Now()
+	emails := []CachedEmail{
+		{UID: 1, InternalDate: now.Add(-time.Hour), From: "a@example.com", BodyHTML: "12345"},
+		{UID: 2, InternalDate: now, From: "b@example.com", ListID: "<news.example.com>",
+			Attachments: []Attachment{{PartID: "2", Size: 100}, {PartID: "3", Size: 50}}},
+	}
+	for _, e := range emails {
+		if err := c.SaveEmail(account, "INBOX", e); err != nil {
+			t.Fatalf("SaveEmail %d error: %v", e.UID, err)
+		}
+	}
+
+	sizes, err := c.LoadEmailSizes(account, "INBOX")
+	if err != nil {
+		t.Fatalf("LoadEmailSizes error: %v", err)
+	}
+	if len(sizes) != 2 {
+		t.Fatalf("got %d sizes, want 2", len(sizes))
+	}
+	if sizes[0].UID != 2 || sizes[0].ListID != "<news.example.com>" || sizes[0].Size != 150 {
+		t.Errorf("newest = %+v, want UID 2 from the list with 150 bytes of attachments", sizes[0])
+	}
+	if sizes[1].UID != 1 || sizes[1].From != "a@example.com" || sizes[1].Size < 5 {
+		t.Errorf("oldest = %+v, want UID 1 with at least its 5-byte body", sizes[1])
+	}
+}
+
 func TestCacheCleanup(t *testing.T) {
 	setTempHome(t)
 
@@ -223,6 +503,159 @@ func TestCacheAcquireLockMismatchedStart(t *testing.T) {
 	}
 }
 
+func TestCacheSaveEmailsBatch(t *testing.T) {
+	setTempHome(t)
+
+	c, err := New()
+	if err != nil {
+		t.Fatalf("New() error: %v", err)
+	}
+	defer c.Close()
+
+	account := "user@example.com"
+	mailbox := "INBOX"
+	now := time.Now()
+
+	existing := CachedEmail{UID: imap.UID(1), InternalDate: now, Subject: "cached", BodyHTML: "<p>body</p>"}
+	if err := c.SaveEmail(account, mailbox, existing); err != nil {
+		t.Fatalf("SaveEmail error: %v", err)
+	}
+
+	batch := []CachedEmail{
+		{UID: imap.UID(1), InternalDate: now, Subject: "refetched"},
+		{UID: imap.UID(2), InternalDate: now, Subject: "new", Attachments: []Attachment{{PartID: "2", Filename: "a.pdf"}}},
+	}
+
+	saved, err := c.SaveEmailsBatch(account, mailbox, batch, false)
+	if err != nil {
+		t.Fatalf("SaveEmailsBatch error: %v", err)
+	}
+	if saved != 1 {
+		t.Fatalf("expected 1 new email saved, got %d", saved)
+	}
+	kept, _ := c.GetEmail(account, mailbox, imap.UID(1))
+	if kept == nil || kept.Subject != "cached" || kept.BodyHTML == "" {
+		t.Fatalf("expected cached email to be kept, got %#v", kept)
+	}
+	added, _ := c.GetEmail(account, mailbox, imap.UID(2))
+	if added == nil || len(added.Attachments) != 1 {
+		t.Fatalf("expected new email with 1 attachment, got %#v", added)
+	}
+
+	saved, err = c.SaveEmailsBatch(account, mailbox, batch, true)
+	if err != nil {
+		t.Fatalf("SaveEmailsBatch overwrite error: %v", err)
+	}
+	if saved != 2 {
+		t.Fatalf("expected 2 emails saved with overwrite, got %d", saved)
+	}
+	replaced, _ := c.GetEmail(account, mailbox, imap.UID(1))
+	if replaced == nil || replaced.Subject != "refetched" {
+		t.Fatalf("expected cached email to be replaced, got %#v", replaced)
+	}
+}
+
+func TestCachePruneBodies(t *testing.T) {
+	setTempHome(t)
+
+	c, err := New()
+	if err != nil {
+		t.Fatalf("New() error: %v", err)
+	}
+	defer c.Close()
+
+	account := "user@example.com"
+	mailbox := "INBOX"
+	now := time.Now()
+
+	for uid := 1; uid <= 4; uid++ {
+		email := CachedEmail{
+			UID:          imap.UID(uid),
+			InternalDate: now.AddDate(0, 0, -10*uid),
+			BodyHTML:     "0123456789",
+		}
+		if err := c.SaveEmail(account, mailbox, email); err != nil {
+			t.Fatalf("SaveEmail %d error: %v", uid, err)
+		}
+	}
+
+	// Email 4 is 40 days old; then only the newest 20 bytes of bodies fit
+	pruned, err := c.PruneBodies(account, mailbox, now.AddDate(0, 0, -35), 20)
+	if err != nil {
+		t.Fatalf("PruneBodies error: %v", err)
+	}
+	if pruned != 2 {
+		t.Fatalf("expected 2 bodies pruned, got %d", pruned)
+	}
+
+	for uid, wantBody := range map[int]bool{1: true, 2: true, 3: false, 4: false} {
+		email, _ := c.GetEmail(account, mailbox, imap.UID(uid))
+		if email == nil {
+			t.Fatalf("expected email %d to keep its metadata", uid)
+		}
+		if (email.BodyHTML != "") != wantBody {
+			t.Errorf("email %d: body kept = %v, want %v", uid, email.BodyHTML != "", wantBody)
+		}
+	}
+}
+
+func TestCacheEncryption(t *testing.T) {
+	setTempHome(t)
+
+	c, err := New()
+	if err != nil {
+		t.Fatalf("New() error: %v", err)
+	}
+	defer c.Close()
+
+	account := "user@example.com"
+	mailbox := "INBOX"
+	now := time.Now()
+
+	plain := CachedEmail{UID: imap.UID(1), InternalDate: now, Snippet: "hello", BodyHTML: "<p>secret</p>"}
+	if err := c.SaveEmail(account, mailbox, plain); err != nil {
+		t.Fatalf("SaveEmail error: %v", err)
+	}
+
+	key := make([]byte, keySize)
+	n, err := c.encryptWith(key)
+	if err != nil {
+		t.Fatalf("encryptWith error: %v", err)
+	}
+	if n != 1 {
+		t.Fatalf("expected 1 email encrypted, got %d", n)
+	}
+
+	// Emails saved after enabling are encrypted on write
+	later := CachedEmail{UID: imap.UID(2), InternalDate: now, BodyHTML: "<p>later</p>"}
+	if err := c.SaveEmail(account, mailbox, later); err != nil {
+		t.Fatalf("SaveEmail error: %v", err)
+	}
+
+	var stored string
+	c.db.QueryRow("SELECT body_html FROM emails WHERE uid = 2").Scan(&stored)
+	if !strings.HasPrefix(stored, encPrefix) || strings.Contains(stored, "later") {
+		t.Fatalf("expected body stored encrypted, got %q", stored)
+	}
+
+	email, _ := c.GetEmail(account, mailbox, imap.UID(1))
+	if email == nil || email.BodyHTML != plain.BodyHTML || email.Snippet != plain.Snippet {
+		t.Fatalf("expected decrypted email, got %#v", email)
+	}
+
+	n, err = c.Decrypt()
+	if err != nil {
+		t.Fatalf("Decrypt error: %v", err)
+	}
+	if n != 2 || c.Encrypted() {
+		t.Fatalf("expected 2 emails decrypted and encryption off, got %d, %v", n, c.Encrypted())
+	}
+	c.db.QueryRow("SELECT body_html FROM emails WHERE uid = 2").Scan(&stored)
+	if stored != later.BodyHTML {
+		t.Fatalf("expected plaintext body after Decrypt, got %q", stored)
+	}
+}
+
 func TestCacheAttachments(t *testing.T) {
 	setTempHome(t)
 
@@ -264,6 +697,49 @@ func TestCacheAttachments(t *testing.T) {
 	}
 }
 
+func TestCacheMigrations(t *testing.T) {
+	dbPath := filepath.Join(t.TempDir(), "maily.db")
+
+	// A database from before list headers and versioning existed
+	legacy, err := sql.Open("sqlite3", dbPath)
+	if err != nil {
+		t.Fatalf("sql.Open error: %v", err)
+	}
+	_, err = legacy.Exec(`CREATE TABLE emails (
+		account TEXT NOT NULL, mailbox TEXT NOT NULL, uid INTEGER NOT NULL,
+		internal_date INTEGER NOT NULL, subject TEXT NOT NULL DEFAULT '',
+		PRIMARY KEY (account, mailbox, uid))`)
+	legacy.Close()
+	if err != nil {
+		t.Fatalf("creating legacy table: %v", err)
+	}
+
+	c, err := NewWithPath(dbPath)
+	if err != nil {
+		t.Fatalf("NewWithPath error: %v", err)
+	}
+	version, err := currentVersion(c.db)
+	if err != nil || version != schemaVersion() {
+		t.Fatalf("expected schema version %d, got %d (%v)", schemaVersion(), version, err)
+	}
+	var count int
+	c.db.QueryRow("SELECT COUNT(*) FROM pragma_table_info('emails') WHERE name = 'list_unsubscribe'").Scan(&count)
+	if count != 1 {
+		t.Fatalf("expected list_unsubscribe column to be added")
+	}
+
+	// A newer maily has migrated past what this build knows
+	if _, err := c.db.Exec("INSERT INTO schema_migrations (version, applied_at) VALUES (?, 0)", schemaVersion()+1); err != nil {
+		t.Fatalf("insert version error: %v", err)
+	}
+	c.Close()
+
+	if c, err := NewWithPath(dbPath); err == nil {
+		c.Close()
+		t.Fatalf("expected opening a newer schema to fail")
+	}
+}
+
 func TestCacheMetadata(t *testing.T) {
 	setTempHome(t)
 
@@ -340,3 +816,459 @@ func TestCacheIsFresh(t *testing.T) {
 		t.Fatalf("expected not fresh with old metadata")
 	}
 }
+
+func TestCacheSyncStats(t *testing.T) {
+	setTempHome(t)
+
+	c, err := New()
+	if err != nil {
+		t.Fatalf("New() error: %v", err)
+	}
+	defer c.Close()
+
+	account := "user@example.com"
+	now := time.Now()
+
+	runs := []SyncRun{
+		{Account: account, Mailbox: "INBOX", StartedAt: now.Add(-2 * time.Hour), Duration: 2 * time.Second, Fetched: 100},
+		{Account: account, Mailbox: "INBOX", StartedAt: now.Add(-time.Hour), Duration: 4 * time.Second, Fetched: 0, Error: "timeout"},
+		{Account: account, Mailbox: "INBOX", StartedAt: now.Add(-10 * 24 * time.Hour), Duration: time.Second, Fetched: 50},
+	}
+	for _, run := range runs {
+		if err := c.RecordSyncRun(run); err != nil {
+			t.Fatalf("RecordSyncRun error: %v", err)
+		}
+	}
+	if err := c.AddPendingOp(account, "INBOX", OpDelete, imap.UID(7)); err != nil {
+		t.Fatalf("AddPendingOp error: %v", err)
+	}
+
+	stats, err := c.GetSyncStats(account, now.Add(-7*24*time.Hour))
+	if err != nil {
+		t.Fatalf("GetSyncStats error: %v", err)
+	}
+
+	if stats.TotalSyncs != 2 || stats.FailedSyncs != 1 {
+		t.Fatalf("syncs = %d/%d failed, want 2/1", stats.TotalSyncs, stats.FailedSyncs)
+	}
+	if stats.TotalFetched != 100 {
+		t.Fatalf("TotalFetched = %d, want 100", stats.TotalFetched)
+	}
+	if stats.AvgDuration != 3*time.Second {
+		t.Fatalf("AvgDuration = %v, want 3s", stats.AvgDuration)
+	}
+	if stats.LastError != "timeout" || stats.LastDuration != 4*time.Second {
+		t.Fatalf("last run = %q/%v, want timeout/4s", stats.LastError, stats.LastDuration)
+	}
+	if stats.PendingOps != 1 {
+		t.Fatalf("PendingOps = %d, want 1", stats.PendingOps)
+	}
+	if stats.ErrorRate() != 0.5 {
+		t.Fatalf("ErrorRate = %v, want 0.5", stats.ErrorRate())
+	}
+}
+
+func TestCacheLabels(t *testing.T) {
+	setTempHome(t)
+
+	c, err := New()
+	if err != nil {
+		t.Fatalf("New() error: %v", err)
+	}
+	defer c.Close()
+
+	account, mailbox := "user@gmail.com", "INBOX"
+	email := CachedEmail{UID: 7, Subject: "Invoice", InternalDate: time.Now(), Date: time.Now()}
+	if err := c.SaveEmail(account, mailbox, email); err != nil {
+		t.Fatalf("SaveEmail error: %v", err)
+	}
+	if err := c.SetLabels(account, mailbox, map[imap.UID][]string{7: {"\\Important", "Work"}}); err != nil {
+		t.Fatalf("SetLabels error: %v", err)
+	}
+
+	// Replacing the row, as when the body is fetched, keeps the labels
+	email.BodyHTML = "<p>Due</p>"
+	if err := c.SaveEmail(account, mailbox, email); err != nil {
+		t.Fatalf("SaveEmail error: %v", err)
+	}
+	got, err := c.GetEmail(account, mailbox, 7)
+	if err != nil || got == nil {
+		t.Fatalf("GetEmail error: %v", err)
+	}
+	if len(got.Labels) != 2 || got.Labels[1] != "Work" {
+		t.Fatalf("expected labels to survive replace, got %v", got.Labels)
+	}
+
+	if err := c.SetLabels(account, mailbox, map[imap.UID][]string{7: {}}); err != nil {
+		t.Fatalf("SetLabels error: %v", err)
+	}
+	emails, _ := c.LoadEmails(account, mailbox)
+	if len(emails) != 1 || emails[0].Labels != nil {
+		t.Fatalf("expected labels to be cleared, got %v", emails)
+	}
+}
+
+func TestCacheMessageFlags(t *testing.T) {
+	setTempHome(t)
+
+	c, err := New()
+	if err != nil {
+		t.Fatalf("New() error: %v", err)
+	}
+	defer c.Close()
+
+	account, mailbox := "user@example.com", "INBOX"
+	emails := []CachedEmail{
+		{UID: 1, Subject: "replied", InternalDate: time.Now(), Answered: true, Forwarded: true},
+		{UID: 2, Subject: "starred", InternalDate: time.Now(), Flagged: true},
+		{UID: 3, Subject: "plain", InternalDate: time.Now()},
+	}
+	if _, err := c.SaveEmailsBatch(account, mailbox, emails, false); err != nil {
+		t.Fatalf("SaveEmailsBatch error: %v", err)
+	}
+
+	loaded, err := c.LoadEmails(account, mailbox)
+	if err != nil || len(loaded) != 3 {
+		t.Fatalf("LoadEmails: %d emails, error %v", len(loaded), err)
+	}
+	for _, got := range loaded {
+		want := emails[got.UID-1]
+		if got.Answered != want.Answered || got.Forwarded != want.Forwarded || got.Flagged != want.Flagged {
+			t.Errorf("email %d: got answered=%v forwarded=%v flagged=%v", got.UID, got.Answered, got.Forwarded, got.Flagged)
+		}
+	}
+
+	// Replacing the row takes the flags of the email saved
+	emails[1].Flagged = false
+	if err := c.SaveEmail(account, mailbox, emails[1]); err != nil {
+		t.Fatalf("SaveEmail error: %v", err)
+	}
+	got, err := c.GetEmail(account, mailbox, 2)
+	if err != nil || got == nil || got.Flagged {
+		t.Fatalf("expected email 2 to be unflagged, got %#v (error %v)", got, err)
+	}
+
+	// A batch that keeps existing rows still takes the server's marks, leaving the
+	// rest of the row alone
+	if err := c.UpdateEmailFlags(account, mailbox, 3, true); err != nil {
+		t.Fatalf("UpdateEmailFlags error: %v", err)
+	}
+	refetched := []CachedEmail{{UID: 3, Subject: "changed", InternalDate: time.Now(), Flagged: true}}
+	if _, err := c.SaveEmailsBatch(account, mailbox, refetched, false); err != nil {
+		t.Fatalf("SaveEmailsBatch error: %v", err)
+	}
+	got, err = c.GetEmail(account, mailbox, 3)
+	if err != nil || got == nil || !got.Flagged || got.Subject != "plain" || !got.Unread {
+		t.Fatalf("expected email 3 flagged and otherwise unchanged, got %#v (error %v)", got, err)
+	}
+
+	// Marking an email answered keeps its other marks
+	if err := c.MarkEmailAnswered(account, mailbox, 3); err != nil {
+		t.Fatalf("MarkEmailAnswered error: %v", err)
+	}
+	got, err = c.GetEmail(account, mailbox, 3)
+	if err != nil || got == nil || !got.Answered || !got.Flagged {
+		t.Fatalf("expected email 3 answered and flagged, got %#v (error %v)", got, err)
+	}
+}
+
+func TestPendingOpRetries(t *testing.T) {
+	setTempHome(t)
+
+	c, err := New()
+	if err != nil {
+		t.Fatalf("New() error: %v", err)
+	}
+	defer c.Close()
+
+	account := "user@example.com"
+	if err := c.AddPendingOp(account, "INBOX", OpMoveTrash, 42); err != nil {
+		t.Fatalf("AddPendingOp error: %v", err)
+	}
+	now := time.Now()
+	ops, _ := c.GetDuePendingOps(now)
+	if len(ops) != 1 {
+		t.Fatalf("expected 1 due op, got %d", len(ops))
+	}
+	id := ops[0].ID
+
+	// Backing off hides the op until its retry time
+	if err := c.UpdatePendingOpError(id, "timeout", now.Add(time.Minute)); err != nil {
+		t.Fatalf("UpdatePendingOpError error: %v", err)
+	}
+	if ops, _ := c.GetDuePendingOps(now); len(ops) != 0 {
+		t.Fatalf("expected op to wait for its retry time")
+	}
+	if ops, _ := c.GetDuePendingOps(now.Add(2 * time.Minute)); len(ops) != 1 || ops[0].Retries != 1 {
+		t.Fatalf("expected op to be due after backoff, got %+v", ops)
+	}
+
+	// Exhausted ops are set aside for review
+	if err := c.FailPendingOp(id, "no such message"); err != nil {
+		t.Fatalf("FailPendingOp error: %v", err)
+	}
+	if ops, _ := c.GetDuePendingOps(now.Add(time.Hour)); len(ops) != 0 {
+		t.Fatalf("expected failed op not to be retried")
+	}
+	failed, _ := c.GetFailedOps(account)
+	if len(failed) != 1 || !failed[0].Failed() || failed[0].LastError != "no such message" {
+		t.Fatalf("expected one failed op, got %+v", failed)
+	}
+
+	if err := c.RetryFailedOp(id); err != nil {
+		t.Fatalf("RetryFailedOp error: %v", err)
+	}
+	if n, _ := c.CountFailedOps(account); n != 0 {
+		t.Fatalf("expected no failed ops after retry, got %d", n)
+	}
+	if ops, _ := c.GetDuePendingOps(now); len(ops) != 1 || ops[0].Retries != 0 {
+		t.Fatalf("expected retried op to be due with a fresh budget, got %+v", ops)
+	}
+}
+
+func TestFlagOpExpectedState(t *testing.T) {
+	setTempHome(t)
+
+	c, err := New()
+	if err != nil {
+		t.Fatalf("New() error: %v", err)
+	}
+	defer c.Close()
+
+	account := "user@example.com"
+	if err := c.AddFlagOp(account, "INBOX", OpMarkUnread, 9, FlagStateRead); err != nil {
+		t.Fatalf("AddFlagOp error: %v", err)
+	}
+	ops, _ := c.GetDuePendingOps(time.Now())
+	if len(ops) != 1 || ops[0].Operation != OpMarkUnread || ops[0].ExpectedState != FlagStateRead {
+		t.Fatalf("expected queued flag op with its expected state, got %+v", ops)
+	}
+
+	// Retrying from review applies the op whatever the email's state
+	c.FailPendingOp(ops[0].ID, "conflict")
+	if err := c.RetryFailedOp(ops[0].ID); err != nil {
+		t.Fatalf("RetryFailedOp error: %v", err)
+	}
+	ops, _ = c.GetDuePendingOps(time.Now())
+	if len(ops) != 1 || ops[0].ExpectedState != "" {
+		t.Fatalf("expected retry to clear the expected state, got %+v", ops)
+	}
+}
+
+func TestTrashOrigins(t *testing.T) {
+	setTempHome(t)
+
+	c, err := New()
+	if err != nil {
+		t.Fatalf("New() error: %v", err)
+	}
+	defer c.Close()
+
+	account := "user@example.com"
+	if err := c.RecordTrashOrigin(account, "<a@example.com>", "Work"); err != nil {
+		t.Fatalf("RecordTrashOrigin error: %v", err)
+	}
+	c.RecordTrashOrigin(account, "<b@example.com>", "INBOX")
+	c.RecordTrashOrigin(account, "", "INBOX") // no Message-ID, nothing to key on
+
+	if mailbox, ok, _ := c.TrashOrigin(account, "<a@example.com>"); !ok || mailbox != "Work" {
+		t.Fatalf("expected origin Work, got %q (found %v)", mailbox, ok)
+	}
+	if _, ok, _ := c.TrashOrigin("other@example.com", "<a@example.com>"); ok {
+		t.Fatal("expected origins to be kept per account")
+	}
+
+	c.ForgetTrashOrigin(account, "<a@example.com>")
+	if _, ok, _ := c.TrashOrigin(account, "<a@example.com>"); ok {
+		t.Fatal("expected forgotten origin to be gone")
+	}
+
+	c.ClearTrashOrigins(account)
+	if _, ok, _ := c.TrashOrigin(account, "<b@example.com>"); ok {
+		t.Fatal("expected origins cleared after emptying trash")
+	}
+}
+
+func TestLastViewed(t *testing.T) {
+	setTempHome(t)
+
+	c, err := New()
+	if err != nil {
+		t.Fatalf("New() error: %v", err)
+	}
+	defer c.Close()
+
+	account := "user@example.com"
+	if seen, err := c.LastViewed(account, "INBOX"); err != nil || !seen.IsZero() {
+		t.Fatalf("expected never viewed, got %v (err %v)", seen, err)
+	}
+
+	first := time.Unix(1700000000, 0)
+	if err := c.SetLastViewed(account, "INBOX", first); err != nil {
+		t.Fatalf("SetLastViewed error: %v", err)
+	}
+	c.SetLastViewed(account, "INBOX", first.Add(time.Hour))
+	c.SetLastViewed(account, "Work", first)
+
+	if seen, _ := c.LastViewed(account, "INBOX"); !seen.Equal(first.Add(time.Hour)) {
+		t.Fatalf("expected the latest view, got %v", seen)
+	}
+	if seen, _ := c.LastViewed(account, "Work"); !seen.Equal(first) {
+		t.Fatalf("expected views kept per folder, got %v", seen)
+	}
+	if seen, _ := c.LastViewed("other@example.com", "INBOX"); !seen.IsZero() {
+		t.Fatalf("expected views kept per account, got %v", seen)
+	}
+}
+
+func TestLocalDrafts(t *testing.T) {
+	setTempHome(t)
+
+	c, err := New()
+	if err != nil {
+		t.Fatalf("New() error: %v", err)
+	}
+	defer c.Close()
+
+	now := time.Unix(1700000000, 0)
+	if err := c.SaveLocalDraft(LocalDraft{ID: "b", Account: "user@example.com", Data: "second", UpdatedAt: now.Add(time.Minute)}); err != nil {
+		t.Fatalf("SaveLocalDraft error: %v", err)
+	}
+	c.SaveLocalDraft(LocalDraft{ID: "a", Account: "user@example.com", Data: "first", UpdatedAt: now})
+	c.SaveLocalDraft(LocalDraft{ID: "a", Account: "user@example.com", Data: "first, edited", UpdatedAt: now})
+
+	drafts, err := c.LocalDrafts()
+	if err != nil {
+		t.Fatalf("LocalDrafts error: %v", err)
+	}
+	if len(drafts) != 2 || drafts[0].ID != "a" || drafts[0].Data != "first, edited" || drafts[1].ID != "b" {
+		t.Fatalf("expected both drafts oldest first with the latest save, got %+v", drafts)
+	}
+
+	c.DeleteLocalDraft("a")
+	if drafts, _ := c.LocalDrafts(); len(drafts) != 1 || drafts[0].ID != "b" {
+		t.Fatalf("expected only draft b left, got %+v", drafts)
+	}
+}
+
+func TestThreadRules(t *testing.T) {
+	setTempHome(t)
+
+	c, err := New()
+	if err != nil {
+		t.Fatalf("New() error: %v", err)
+	}
+	defer c.Close()
+
+	account := "user@example.com"
+	ids := ThreadIDs("<reply@example.com>", "<root@example.com>")
+	if len(ids) != 2 || ids[0] != "<reply@example.com>" || ids[1] != "<root@example.com>" {
+		t.Fatalf("unexpected thread IDs %v", ids)
+	}
+	if err := c.SetThreadRule(account, ids, ThreadMute); err != nil {
+		t.Fatalf("SetThreadRule error: %v", err)
+	}
+
+	// A later reply to the root finds the thread and joins it
+	rule, err := c.GetThreadRule(account, ThreadIDs("<later@example.com>", "<root@example.com>"))
+	if err != nil || rule == nil || rule.Rule != ThreadMute || rule.Thread != "<reply@example.com>" {
+		t.Fatalf("expected muted thread, got %+v (%v)", rule, err)
+	}
+	c.JoinThread(account, "<later@example.com>", *rule)
+	if rules, _ := c.GetThreadRules(account); len(rules) != 3 || rules["<later@example.com>"].Rule != ThreadMute {
+		t.Fatalf("expected 3 muted messages, got %v", rules)
+	}
+	if rule, _ := c.GetThreadRule("other@example.com", ids); rule != nil {
+		t.Fatal("expected rules to be kept per account")
+	}
+
+	// Following the thread from any message replaces the rule for all of it
+	c.SetThreadRule(account, []string{"<later@example.com>"}, ThreadFollow)
+	if rules, _ := c.GetThreadRules(account); len(rules) != 3 || rules["<root@example.com>"].Rule != ThreadFollow {
+		t.Fatalf("expected the whole thread followed, got %v", rules)
+	}
+
+	c.SetThreadRule(account, []string{"<later@example.com>"}, "")
+	if rules, _ := c.GetThreadRules(account); len(rules) != 0 {
+		t.Fatalf("expected no rules after unfollowing, got %v", rules)
+	}
+}
+
+// Two TUIs and the server each open the cache, and write to it at the same time
+func TestCacheConcurrentClients(t *testing.T) {
+	dbPath := filepath.Join(t.TempDir(), "maily.db")
+
+	// Opening migrates the schema, which the first instances race to do
+	const clients = 4
+	caches := make([]*Cache, clients)
+	errs := make(chan error, clients*100)
+	var wg sync.WaitGroup
+	for i := range caches {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			c, err := NewWithPath(dbPath)
+			if err != nil {
+				errs <- fmt.Errorf("client %d: NewWithPath: %w", i, err)
+				return
+			}
+			caches[i] = c
+		}()
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		t.Fatal(err)
+	}
+	for _, c := range caches {
+		defer c.Close()
+	}
+
+	account, mailbox := "user@example.com", "INBOX"
+	now := time.Now()
+	errs = make(chan error, clients*100)
+	for i, c := range caches {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := range 25 {
+				uid := imap.UID(i*100 + j + 1)
+				email := CachedEmail{UID: uid, InternalDate: now, Subject: "hello", Unread: true}
+				if err := c.SaveEmail(account, mailbox, email); err != nil {
+					errs <- fmt.Errorf("client %d: SaveEmail: %w", i, err)
+					return
+				}
+				if _, err := c.SaveEmailsBatch(account, mailbox, []CachedEmail{email}, true); err != nil {
+					errs <- fmt.Errorf("client %d: SaveEmailsBatch: %w", i, err)
+					return
+				}
+				if err := c.UpdateEmailFlags(account, mailbox, uid, false); err != nil {
+					errs <- fmt.Errorf("client %d: UpdateEmailFlags: %w", i, err)
+					return
+				}
+				if _, err := c.LoadEmails(account, mailbox); err != nil {
+					errs <- fmt.Errorf("client %d: LoadEmails: %w", i, err)
+					return
+				}
+			}
+		}()
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		t.Fatal(err)
+	}
+
+	emails, err := caches[0].LoadEmails(account, mailbox)
+	if err != nil {
+		t.Fatalf("LoadEmails error: %v", err)
+	}
+	if len(emails) != clients*25 {
+		t.Fatalf("expected %d emails, got %d", clients*25, len(emails))
+	}
+	for _, e := range emails {
+		if e.Unread {
+			t.Fatalf("expected email %d to be marked read", e.UID)
+		}
+	}
+}
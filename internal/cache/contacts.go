@@ -0,0 +1,71 @@
+package cache
+
+import (
+	"time"
+
+	"maily/internal/contacts"
+)
+
+// LoadContacts returns all contacts ordered by name, then email
+func (c *Cache) LoadContacts() ([]contacts.Contact, error) {
+	rows, err := c.db.Query(`
+		SELECT email, name, source, updated_at FROM contacts
+		ORDER BY name = '', name COLLATE NOCASE, email COLLATE NOCASE
+	`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var result []contacts.Contact
+	for rows.Next() {
+		var ct contacts.Contact
+		var updatedAt int64
+		if err := rows.Scan(&ct.Email, &ct.Name, &ct.Source, &updatedAt); err != nil {
+			continue
+		}
+		ct.UpdatedAt = time.Unix(updatedAt, 0)
+		result = append(result, ct)
+	}
+	return result, rows.Err()
+}
+
+// SaveContacts inserts or replaces contacts keyed by email address
+func (c *Cache) SaveContacts(list []contacts.Contact) error {
+	tx, err := c.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	stmt, err := tx.Prepare(`
+		INSERT INTO contacts (email, name, source, updated_at) VALUES (?, ?, ?, ?)
+		ON CONFLICT(email) DO UPDATE SET
+			name = excluded.name, source = excluded.source, updated_at = excluded.updated_at
+	`)
+	if err != nil {
+		return err
+	}
+	defer stmt.Close()
+
+	for _, ct := range list {
+		if _, err := stmt.Exec(ct.Email, ct.Name, ct.Source, ct.UpdatedAt.Unix()); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+// ImportContacts merges incoming contacts into the address book by email
+// address and returns how many were added and updated
+func (c *Cache) ImportContacts(incoming []contacts.Contact) (added, updated int, err error) {
+	existing, err := c.LoadContacts()
+	if err != nil {
+		return 0, 0, err
+	}
+	changed, added := contacts.Merge(existing, incoming)
+	if err := c.SaveContacts(changed); err != nil {
+		return 0, 0, err
+	}
+	return added, len(changed) - added, nil
+}
@@ -0,0 +1,301 @@
+package cache
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"database/sql"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"maily/internal/keychain"
+)
+
+// Encrypted bodies and snippets are stored as encPrefix + base64(nonce | AES-256-GCM ciphertext).
+// Values without the prefix are plaintext, so a partly migrated database still reads.
+const (
+	encPrefix  = "enc1:"
+	keySize    = 32
+	keyService = "maily"
+	keyAccount = "cache-key"
+	keyFile    = "cache.key" // fallback when the OS has no keychain
+
+	settingEncryption = "encryption"
+	encryptionAESGCM  = "aes-256-gcm"
+
+	rewriteBatch = 500
+)
+
+// sealer encrypts and decrypts cached email text with the cache key
+type sealer struct {
+	gcm cipher.AEAD
+}
+
+func newSealer(key []byte) (*sealer, error) {
+	if len(key) != keySize {
+		return nil, fmt.Errorf("cache key must be %d bytes", keySize)
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	gcm, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+	return &sealer{gcm: gcm}, nil
+}
+
+func (s *sealer) seal(plain string) (string, error) {
+	if plain == "" || strings.HasPrefix(plain, encPrefix) {
+		return plain, nil
+	}
+	nonce := make([]byte, s.gcm.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", err
+	}
+	sealed := s.gcm.Seal(nonce, nonce, []byte(plain), nil)
+	return encPrefix + base64.StdEncoding.EncodeToString(sealed), nil
+}
+
+func (s *sealer) open(value string) (string, error) {
+	if !strings.HasPrefix(value, encPrefix) {
+		return value, nil
+	}
+	data, err := base64.StdEncoding.DecodeString(value[len(encPrefix):])
+	if err != nil {
+		return "", err
+	}
+	if len(data) < s.gcm.NonceSize() {
+		return "", errors.New("encrypted value too short")
+	}
+	nonce, ciphertext := data[:s.gcm.NonceSize()], data[s.gcm.NonceSize():]
+	plain, err := s.gcm.Open(nil, nonce, ciphertext, nil)
+	if err != nil {
+		return "", err
+	}
+	return string(plain), nil
+}
+
+// sealText encrypts a body or snippet for storage when encryption is enabled
+func (c *Cache) sealText(plain string) string {
+	if c.sealer == nil {
+		return plain
+	}
+	sealed, err := c.sealer.seal(plain)
+	if err != nil {
+		// Never fall back to writing plaintext into an encrypted cache
+		return ""
+	}
+	return sealed
+}
+
+// openText decrypts a stored body or snippet. A value that cannot be decrypted reads as
+// empty, which makes callers fetch the body from the server again.
+func (c *Cache) openText(value string) string {
+	if !strings.HasPrefix(value, encPrefix) {
+		return value
+	}
+	if c.sealer == nil {
+		return ""
+	}
+	plain, err := c.sealer.open(value)
+	if err != nil {
+		return ""
+	}
+	return plain
+}
+
+// loadEncryption turns on encryption when the database was encrypted with Encrypt
+func (c *Cache) loadEncryption() error {
+	var mode string
+	err := c.db.QueryRow("SELECT value FROM settings WHERE key = ?", settingEncryption).Scan(&mode)
+	if err == sql.ErrNoRows {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if mode != encryptionAESGCM {
+		return fmt.Errorf("unsupported cache encryption %q", mode)
+	}
+
+	key, _, err := c.loadKey(false)
+	if err != nil {
+		return fmt.Errorf("cache is encrypted but its key is unavailable (%w); remove %s to start a fresh cache", err, c.dbPath)
+	}
+	c.sealer, err = newSealer(key)
+	return err
+}
+
+// loadKey reads the cache key from the OS keychain, or the key file next to the database
+// on systems without one. With create, a missing key is generated and stored. Returns the
+// key and where it is kept.
+func (c *Cache) loadKey(create bool) ([]byte, string, error) {
+	keyPath := filepath.Join(filepath.Dir(c.dbPath), keyFile)
+
+	encoded, err := keychain.Get(keyService, keyAccount)
+	where := "OS keychain"
+	if err != nil {
+		if !errors.Is(err, keychain.ErrNotFound) && !errors.Is(err, keychain.ErrUnavailable) {
+			return nil, "", err
+		}
+		data, fileErr := os.ReadFile(keyPath)
+		switch {
+		case fileErr == nil:
+			encoded, where = strings.TrimSpace(string(data)), keyPath
+		case !os.IsNotExist(fileErr):
+			return nil, "", fileErr
+		case !create:
+			return nil, "", keychain.ErrNotFound
+		default:
+			return c.createKey(keyPath)
+		}
+	}
+
+	key, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil || len(key) != keySize {
+		return nil, "", fmt.Errorf("invalid cache key in %s", where)
+	}
+	return key, where, nil
+}
+
+// createKey generates a cache key and stores it in the OS keychain, falling back to a
+// file readable only by the user
+func (c *Cache) createKey(keyPath string) ([]byte, string, error) {
+	key := make([]byte, keySize)
+	if _, err := rand.Read(key); err != nil {
+		return nil, "", err
+	}
+	encoded := base64.StdEncoding.EncodeToString(key)
+
+	err := keychain.Set(keyService, keyAccount, encoded)
+	if err == nil {
+		return key, "OS keychain", nil
+	}
+	if !errors.Is(err, keychain.ErrUnavailable) {
+		return nil, "", err
+	}
+	if err := os.WriteFile(keyPath, []byte(encoded+"\n"), 0600); err != nil {
+		return nil, "", err
+	}
+	return key, keyPath, nil
+}
+
+// Encrypted reports whether cached bodies and snippets are encrypted at rest
+func (c *Cache) Encrypted() bool {
+	return c.sealer != nil
+}
+
+// Encrypt enables encryption of cached bodies and snippets with a key kept in the OS
+// keychain, creating the key if needed, and encrypts the rows already cached. Returns
+// the number of emails encrypted and where the key is stored.
+func (c *Cache) Encrypt() (int, string, error) {
+	key, where, err := c.loadKey(true)
+	if err != nil {
+		return 0, "", err
+	}
+	n, err := c.encryptWith(key)
+	return n, where, err
+}
+
+func (c *Cache) encryptWith(key []byte) (int, error) {
+	s, err := newSealer(key)
+	if err != nil {
+		return 0, err
+	}
+	// Record the setting first so emails cached while rows are rewritten get encrypted too
+	if _, err := c.db.Exec("INSERT OR REPLACE INTO settings (key, value) VALUES (?, ?)", settingEncryption, encryptionAESGCM); err != nil {
+		return 0, err
+	}
+	c.sealer = s
+	return c.rewriteBodies(s.seal)
+}
+
+// Decrypt stores cached bodies and snippets as plaintext again and turns encryption off.
+// The key is left in the keychain. Returns the number of emails decrypted.
+func (c *Cache) Decrypt() (int, error) {
+	if c.sealer == nil {
+		return 0, nil
+	}
+	n, err := c.rewriteBodies(c.sealer.open)
+	if err != nil {
+		return n, err
+	}
+	if _, err := c.db.Exec("DELETE FROM settings WHERE key = ?", settingEncryption); err != nil {
+		return n, err
+	}
+	c.sealer = nil
+	return n, nil
+}
+
+// rewriteBodies applies transform to the body and snippet of every cached email, in
+// batches so large caches are neither loaded whole nor locked for long. Returns the
+// number of emails changed.
+func (c *Cache) rewriteBodies(transform func(string) (string, error)) (int, error) {
+	type row struct {
+		id            int64
+		snippet, body string
+	}
+
+	changed := 0
+	var last int64
+	for {
+		rows, err := c.db.Query(`
+			SELECT rowid, snippet, body_html FROM emails
+			WHERE rowid > ? AND (body_html != '' OR snippet != '')
+			ORDER BY rowid LIMIT ?
+		`, last, rewriteBatch)
+		if err != nil {
+			return changed, err
+		}
+		var batch []row
+		for rows.Next() {
+			var r row
+			if err := rows.Scan(&r.id, &r.snippet, &r.body); err != nil {
+				rows.Close()
+				return changed, err
+			}
+			batch = append(batch, r)
+		}
+		rows.Close()
+		if len(batch) == 0 {
+			return changed, nil
+		}
+		last = batch[len(batch)-1].id
+
+		tx, err := c.db.Begin()
+		if err != nil {
+			return changed, err
+		}
+		n := 0
+		for _, r := range batch {
+			snippet, err := transform(r.snippet)
+			if err != nil {
+				tx.Rollback()
+				return changed, err
+			}
+			body, err := transform(r.body)
+			if err != nil {
+				tx.Rollback()
+				return changed, err
+			}
+			if snippet == r.snippet && body == r.body {
+				continue
+			}
+			if _, err := tx.Exec("UPDATE emails SET snippet = ?, body_html = ? WHERE rowid = ?", snippet, body, r.id); err != nil {
+				tx.Rollback()
+				return changed, err
+			}
+			n++
+		}
+		if err := tx.Commit(); err != nil {
+			return changed, err
+		}
+		changed += n
+	}
+}
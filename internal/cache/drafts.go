@@ -0,0 +1,52 @@
+package cache
+
+import "time"
+
+// Emails being written in the TUI are autosaved here, each on its own, so they outlive a
+// crash or quitting with drafts set aside. Their content is encrypted like email bodies.
+
+// LocalDraft is an autosaved email being written
+type LocalDraft struct {
+	ID        string
+	Account   string // sender
+	Data      string // the draft as encoded by the TUI
+	UpdatedAt time.Time
+}
+
+// SaveLocalDraft creates or replaces an autosaved draft
+func (c *Cache) SaveLocalDraft(draft LocalDraft) error {
+	_, err := c.db.Exec(
+		"INSERT OR REPLACE INTO local_drafts (id, account, data, updated_at) VALUES (?, ?, ?, ?)",
+		draft.ID, draft.Account, c.sealText(draft.Data), draft.UpdatedAt.Unix(),
+	)
+	return err
+}
+
+// LocalDrafts returns the autosaved drafts, oldest first. Drafts that can't be decrypted
+// have empty Data.
+func (c *Cache) LocalDrafts() ([]LocalDraft, error) {
+	rows, err := c.db.Query("SELECT id, account, data, updated_at FROM local_drafts ORDER BY updated_at, id")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var drafts []LocalDraft
+	for rows.Next() {
+		var draft LocalDraft
+		var updatedAt int64
+		if err := rows.Scan(&draft.ID, &draft.Account, &draft.Data, &updatedAt); err != nil {
+			return nil, err
+		}
+		draft.Data = c.openText(draft.Data)
+		draft.UpdatedAt = time.Unix(updatedAt, 0)
+		drafts = append(drafts, draft)
+	}
+	return drafts, rows.Err()
+}
+
+// DeleteLocalDraft drops an autosaved draft once it was sent, saved to the server or discarded
+func (c *Cache) DeleteLocalDraft(id string) error {
+	_, err := c.db.Exec("DELETE FROM local_drafts WHERE id = ?", id)
+	return err
+}
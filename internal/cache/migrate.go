@@ -0,0 +1,210 @@
+package cache
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+// migration upgrades the schema by one version. Steps run in order inside a transaction
+// and are recorded in schema_migrations, so each runs once per database.
+type migration struct {
+	version int
+	name    string
+	apply   func(tx *sql.Tx) error
+}
+
+// migrations lists every schema change in order. Append new steps with the next version;
+// never edit or reorder released ones.
+var migrations = []migration{
+	{1, "baseline schema", func(tx *sql.Tx) error {
+		_, err := tx.Exec(schema)
+		return err
+	}},
+	{2, "mailing list headers", func(tx *sql.Tx) error {
+		// Databases created before the baseline included these columns lack them
+		for _, column := range []string{"list_id", "list_unsubscribe", "list_unsubscribe_post"} {
+			if err := addColumn(tx, "emails", column, "TEXT NOT NULL DEFAULT ''"); err != nil {
+				return err
+			}
+		}
+		return nil
+	}},
+	{3, "settings table", func(tx *sql.Tx) error {
+		_, err := tx.Exec(`
+			CREATE TABLE IF NOT EXISTS settings (
+			    key TEXT NOT NULL PRIMARY KEY,
+			    value TEXT NOT NULL DEFAULT ''
+			)
+		`)
+		return err
+	}},
+	{4, "gmail labels", func(tx *sql.Tx) error {
+		// JSON array of X-GM-LABELS, empty for servers without labels
+		return addColumn(tx, "emails", "labels", "TEXT NOT NULL DEFAULT ''")
+	}},
+	{5, "pending op backoff", func(tx *sql.Tx) error {
+		if err := addColumn(tx, "pending_ops", "next_attempt", "INTEGER NOT NULL DEFAULT 0"); err != nil {
+			return err
+		}
+		return addColumn(tx, "pending_ops", "failed_at", "INTEGER NOT NULL DEFAULT 0")
+	}},
+	{6, "flag op expected state", func(tx *sql.Tx) error {
+		return addColumn(tx, "pending_ops", "expected_state", "TEXT NOT NULL DEFAULT ''")
+	}},
+	{7, "trash origins", func(tx *sql.Tx) error {
+		_, err := tx.Exec(`
+			CREATE TABLE IF NOT EXISTS trash_origins (
+			    account TEXT NOT NULL,
+			    message_id TEXT NOT NULL,
+			    mailbox TEXT NOT NULL,
+			    trashed_at INTEGER NOT NULL,
+			    PRIMARY KEY (account, message_id)
+			)
+		`)
+		return err
+	}},
+	{8, "thread rules", func(tx *sql.Tx) error {
+		_, err := tx.Exec(`
+			CREATE TABLE IF NOT EXISTS thread_rules (
+			    account TEXT NOT NULL,
+			    message_id TEXT NOT NULL,
+			    thread TEXT NOT NULL,
+			    rule TEXT NOT NULL,
+			    created_at INTEGER NOT NULL,
+			    PRIMARY KEY (account, message_id)
+			)
+		`)
+		return err
+	}},
+	{9, "unread email index", func(tx *sql.Tx) error {
+		// Databases created before the baseline included the column lack it
+		if err := addColumn(tx, "emails", "unread", "INTEGER NOT NULL DEFAULT 0"); err != nil {
+			return err
+		}
+		_, err := tx.Exec(`CREATE INDEX IF NOT EXISTS idx_emails_unread ON emails(account, mailbox, internal_date DESC) WHERE unread = 1`)
+		return err
+	}},
+	{10, "message size", func(tx *sql.Tx) error {
+		// RFC822.SIZE, 0 for emails cached before it was fetched
+		if err := addColumn(tx, "emails", "size", "INTEGER NOT NULL DEFAULT 0"); err != nil {
+			return err
+		}
+		_, err := tx.Exec(`CREATE INDEX IF NOT EXISTS idx_emails_size ON emails(account, mailbox, size DESC)`)
+		return err
+	}},
+	{11, "folder views", func(tx *sql.Tx) error {
+		_, err := tx.Exec(`
+			CREATE TABLE IF NOT EXISTS folder_views (
+			    account TEXT NOT NULL,
+			    mailbox TEXT NOT NULL,
+			    seen_at INTEGER NOT NULL,
+			    PRIMARY KEY (account, mailbox)
+			)
+		`)
+		return err
+	}},
+	{12, "local drafts", func(tx *sql.Tx) error {
+		_, err := tx.Exec(`
+			CREATE TABLE IF NOT EXISTS local_drafts (
+			    id TEXT NOT NULL PRIMARY KEY,
+			    account TEXT NOT NULL,
+			    data TEXT NOT NULL,
+			    updated_at INTEGER NOT NULL
+			)
+		`)
+		return err
+	}},
+	{13, "message flags", func(tx *sql.Tx) error {
+		// Bits for \Answered, $Forwarded and \Flagged, 0 for emails cached before them
+		return addColumn(tx, "emails", "flags", "INTEGER NOT NULL DEFAULT 0")
+	}},
+}
+
+// schemaVersion is the version this build of maily writes
+func schemaVersion() int {
+	return migrations[len(migrations)-1].version
+}
+
+// migrate brings the database up to schemaVersion, refusing to open a database written
+// by a newer maily whose schema this build doesn't know
+func migrate(db *sql.DB) error {
+	_, err := db.Exec(`
+		CREATE TABLE IF NOT EXISTS schema_migrations (
+		    version INTEGER NOT NULL PRIMARY KEY,
+		    name TEXT NOT NULL DEFAULT '',
+		    applied_at INTEGER NOT NULL
+		)
+	`)
+	if err != nil {
+		return fmt.Errorf("failed to create migrations table: %w", err)
+	}
+
+	current, err := currentVersion(db)
+	if err != nil {
+		return err
+	}
+	if current > schemaVersion() {
+		return fmt.Errorf("cache database has schema version %d but this maily only supports %d; update maily (run 'maily update')", current, schemaVersion())
+	}
+
+	for _, m := range migrations {
+		if m.version <= current {
+			continue
+		}
+		if err := applyMigration(db, m); err != nil {
+			return fmt.Errorf("failed to migrate schema to version %d (%s): %w", m.version, m.name, err)
+		}
+	}
+	return nil
+}
+
+func currentVersion(db *sql.DB) (int, error) {
+	var version int
+	if err := db.QueryRow("SELECT COALESCE(MAX(version), 0) FROM schema_migrations").Scan(&version); err != nil {
+		return 0, err
+	}
+	return version, nil
+}
+
+func applyMigration(db *sql.DB, m migration) error {
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	// Another process may have applied it since the version was read
+	var done int
+	if err := tx.QueryRow("SELECT COUNT(*) FROM schema_migrations WHERE version = ?", m.version).Scan(&done); err != nil {
+		return err
+	}
+	if done > 0 {
+		return nil
+	}
+
+	if err := m.apply(tx); err != nil {
+		return err
+	}
+	if _, err := tx.Exec(
+		"INSERT INTO schema_migrations (version, name, applied_at) VALUES (?, ?, ?)",
+		m.version, m.name, time.Now().Unix(),
+	); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// addColumn adds a column to a table unless it already exists
+func addColumn(tx *sql.Tx, table, column, definition string) error {
+	var count int
+	err := tx.QueryRow("SELECT COUNT(*) FROM pragma_table_info(?) WHERE name = ?", table, column).Scan(&count)
+	if err != nil {
+		return err
+	}
+	if count > 0 {
+		return nil
+	}
+	_, err = tx.Exec(fmt.Sprintf("ALTER TABLE %s ADD COLUMN %s %s", table, column, definition))
+	return err
+}
@@ -0,0 +1,168 @@
+package cache
+
+import (
+	"os"
+	"time"
+)
+
+// StorageStats summarizes how much of the cache a mailbox uses
+type StorageStats struct {
+	Account   string `json:"account"`
+	Mailbox   string `json:"mailbox"`
+	Emails    int    `json:"emails"`
+	Bodies    int    `json:"bodies"`     // emails with a cached body
+	BodyBytes int64  `json:"body_bytes"` // size of cached bodies and snippets
+}
+
+// StorageStats returns per-mailbox cache usage, largest first
+func (c *Cache) StorageStats() ([]StorageStats, error) {
+	rows, err := c.db.Query(`
+		SELECT account, mailbox, COUNT(*),
+		       SUM(CASE WHEN body_html != '' OR snippet != '' THEN 1 ELSE 0 END),
+		       COALESCE(SUM(LENGTH(body_html) + LENGTH(snippet)), 0)
+		FROM emails
+		GROUP BY account, mailbox
+		ORDER BY 5 DESC
+	`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var stats []StorageStats
+	for rows.Next() {
+		var s StorageStats
+		if err := rows.Scan(&s.Account, &s.Mailbox, &s.Emails, &s.Bodies, &s.BodyBytes); err != nil {
+			return nil, err
+		}
+		stats = append(stats, s)
+	}
+	return stats, rows.Err()
+}
+
+// Mailboxes returns the mailboxes with cached emails for an account
+func (c *Cache) Mailboxes(account string) ([]string, error) {
+	rows, err := c.db.Query("SELECT DISTINCT mailbox FROM emails WHERE account = ? ORDER BY mailbox", account)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var mailboxes []string
+	for rows.Next() {
+		var mailbox string
+		if err := rows.Scan(&mailbox); err != nil {
+			return nil, err
+		}
+		mailboxes = append(mailboxes, mailbox)
+	}
+	return mailboxes, rows.Err()
+}
+
+// PruneBodies drops cached bodies of emails received before olderThan (if not zero), then
+// the oldest bodies until the mailbox's bodies fit in maxBytes (if above zero). Metadata is
+// kept, so pruned emails stay listed and their body is fetched again when opened.
+// Returns the number of bodies dropped.
+func (c *Cache) PruneBodies(account, mailbox string, olderThan time.Time, maxBytes int64) (int, error) {
+	tx, err := c.db.Begin()
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	pruned := 0
+	if !olderThan.IsZero() {
+		result, err := tx.Exec(`
+			UPDATE emails SET body_html = '', snippet = ''
+			WHERE account = ? AND mailbox = ? AND internal_date < ? AND (body_html != '' OR snippet != '')
+		`, account, mailbox, olderThan.Unix())
+		if err != nil {
+			return 0, err
+		}
+		affected, _ := result.RowsAffected()
+		pruned += int(affected)
+	}
+
+	if maxBytes > 0 {
+		rows, err := tx.Query(`
+			SELECT uid, LENGTH(body_html) + LENGTH(snippet)
+			FROM emails
+			WHERE account = ? AND mailbox = ? AND (body_html != '' OR snippet != '')
+			ORDER BY internal_date DESC
+		`, account, mailbox)
+		if err != nil {
+			return 0, err
+		}
+		var total int64
+		var over []uint32
+		for rows.Next() {
+			var uid uint32
+			var size int64
+			if err := rows.Scan(&uid, &size); err != nil {
+				rows.Close()
+				return 0, err
+			}
+			total += size
+			if total > maxBytes {
+				over = append(over, uid)
+			}
+		}
+		rows.Close()
+
+		stmt, err := tx.Prepare("UPDATE emails SET body_html = '', snippet = '' WHERE account = ? AND mailbox = ? AND uid = ?")
+		if err != nil {
+			return 0, err
+		}
+		defer stmt.Close()
+		for _, uid := range over {
+			if _, err := stmt.Exec(account, mailbox, uid); err != nil {
+				return 0, err
+			}
+		}
+		pruned += len(over)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, err
+	}
+	return pruned, nil
+}
+
+// PruneAccount prunes the bodies of every cached mailbox of an account to the cutoff and
+// byte budget bounds returns for it, returning the number of bodies dropped
+func (c *Cache) PruneAccount(account string, bounds func(mailbox string) (olderThan time.Time, maxBytes int64)) (int, error) {
+	mailboxes, err := c.Mailboxes(account)
+	if err != nil {
+		return 0, err
+	}
+	pruned := 0
+	for _, mailbox := range mailboxes {
+		olderThan, maxBytes := bounds(mailbox)
+		if olderThan.IsZero() && maxBytes <= 0 {
+			continue
+		}
+		n, err := c.PruneBodies(account, mailbox, olderThan, maxBytes)
+		if err != nil {
+			return pruned, err
+		}
+		pruned += n
+	}
+	return pruned, nil
+}
+
+// Vacuum rebuilds the database file, returning space freed by pruning to the OS
+func (c *Cache) Vacuum() error {
+	_, err := c.db.Exec("VACUUM")
+	return err
+}
+
+// FileSize returns the size of the database on disk, including its write-ahead log
+func (c *Cache) FileSize() int64 {
+	var size int64
+	for _, path := range []string{c.dbPath, c.dbPath + "-wal"} {
+		if info, err := os.Stat(path); err == nil {
+			size += info.Size()
+		}
+	}
+	return size
+}
@@ -0,0 +1,110 @@
+package cache
+
+import (
+	"database/sql"
+	"time"
+
+	"maily/internal/mail"
+)
+
+// syncRunRetention is how long sync run history is kept
+const syncRunRetention = 30 * 24 * time.Hour
+
+// SyncRun records the outcome of a single mailbox sync
+type SyncRun struct {
+	Account   string
+	Mailbox   string
+	StartedAt time.Time
+	Duration  time.Duration
+	Fetched   int    // number of messages fetched from IMAP
+	Error     string // empty on success
+}
+
+// SyncStats summarizes sync history and queue state for an account
+type SyncStats struct {
+	Account      string        `json:"account"`
+	TotalSyncs   int           `json:"total_syncs"`
+	FailedSyncs  int           `json:"failed_syncs"`
+	LastSync     time.Time     `json:"last_sync"`
+	LastDuration time.Duration `json:"last_duration"`
+	AvgDuration  time.Duration `json:"avg_duration"`
+	LastFetched  int           `json:"last_fetched"`
+	TotalFetched int           `json:"total_fetched"`
+	LastError    string        `json:"last_error,omitempty"`
+	PendingOps   int           `json:"pending_ops"`
+	FailedOps    int           `json:"failed_ops"`
+
+	Connection *mail.ConnStats `json:"connection,omitempty"` // the server's latest IMAP connection
+}
+
+// ErrorRate returns the fraction of failed syncs (0 when there were none)
+func (s SyncStats) ErrorRate() float64 {
+	if s.TotalSyncs == 0 {
+		return 0
+	}
+	return float64(s.FailedSyncs) / float64(s.TotalSyncs)
+}
+
+// RecordSyncRun stores a sync run and prunes history past the retention window
+func (c *Cache) RecordSyncRun(run SyncRun) error {
+	_, err := c.db.Exec(`
+		INSERT INTO sync_runs (account, mailbox, started_at, duration_ms, fetched, error)
+		VALUES (?, ?, ?, ?, ?, ?)
+	`, run.Account, run.Mailbox, run.StartedAt.Unix(), run.Duration.Milliseconds(), run.Fetched, run.Error)
+	if err != nil {
+		return err
+	}
+
+	_, err = c.db.Exec("DELETE FROM sync_runs WHERE started_at < ?", time.Now().Add(-syncRunRetention).Unix())
+	return err
+}
+
+// GetSyncStats summarizes sync runs and operations for an account since the given time
+func (c *Cache) GetSyncStats(account string, since time.Time) (*SyncStats, error) {
+	stats := &SyncStats{Account: account}
+
+	var avgMs sql.NullFloat64
+	var totalFetched sql.NullInt64
+	err := c.db.QueryRow(`
+		SELECT COUNT(*),
+		       COALESCE(SUM(CASE WHEN error != '' THEN 1 ELSE 0 END), 0),
+		       AVG(duration_ms),
+		       SUM(fetched)
+		FROM sync_runs WHERE account = ? AND started_at >= ?
+	`, account, since.Unix()).Scan(&stats.TotalSyncs, &stats.FailedSyncs, &avgMs, &totalFetched)
+	if err != nil {
+		return nil, err
+	}
+	stats.AvgDuration = time.Duration(avgMs.Float64) * time.Millisecond
+	stats.TotalFetched = int(totalFetched.Int64)
+
+	// Most recent run (regardless of window)
+	var startedAt, durationMs int64
+	err = c.db.QueryRow(`
+		SELECT started_at, duration_ms, fetched, error
+		FROM sync_runs WHERE account = ?
+		ORDER BY started_at DESC, id DESC LIMIT 1
+	`, account).Scan(&startedAt, &durationMs, &stats.LastFetched, &stats.LastError)
+	if err != nil && err != sql.ErrNoRows {
+		return nil, err
+	}
+	if err == nil {
+		stats.LastSync = time.Unix(startedAt, 0)
+		stats.LastDuration = time.Duration(durationMs) * time.Millisecond
+	}
+
+	if err := c.db.QueryRow(
+		"SELECT COUNT(*) FROM pending_ops WHERE account = ?", account,
+	).Scan(&stats.PendingOps); err != nil {
+		return nil, err
+	}
+
+	if err := c.db.QueryRow(
+		"SELECT COUNT(*) FROM op_logs WHERE account = ? AND status = ? AND processed_at >= ?",
+		account, StatusFailed, since.Unix(),
+	).Scan(&stats.FailedOps); err != nil {
+		return nil, err
+	}
+
+	return stats, nil
+}
@@ -0,0 +1,136 @@
+package cache
+
+import (
+	"database/sql"
+	"strings"
+	"time"
+)
+
+// Thread rules
+const (
+	ThreadMute   = "mute"   // archive new mail in the thread as it arrives
+	ThreadFollow = "follow" // notify about new mail in the thread
+)
+
+// A message only names the message it replies to, so a muted or followed thread is kept
+// as the Message-IDs known to be in it, under the ID it was first muted or followed by.
+// A new message replying to any of them joins the thread.
+
+// ThreadRule is the rule of the thread a message is in
+type ThreadRule struct {
+	Thread string // the thread's key, the first Message-ID recorded for it
+	Rule   string // ThreadMute or ThreadFollow
+}
+
+// ThreadIDs returns the Message-IDs that place a message in a thread: its own and the
+// ones it replies to
+func ThreadIDs(messageID, references string) []string {
+	var ids []string
+	for _, id := range append([]string{messageID}, strings.Fields(references)...) {
+		if id != "" {
+			ids = append(ids, id)
+		}
+	}
+	return ids
+}
+
+// SetThreadRule mutes or follows the thread of messageIDs, replacing its rule; an empty
+// rule unmutes or unfollows it
+func (c *Cache) SetThreadRule(account string, messageIDs []string, rule string) error {
+	if len(messageIDs) == 0 {
+		return nil
+	}
+	tx, err := c.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	thread := messageIDs[0]
+	if existing, err := threadRule(tx, account, messageIDs); err != nil {
+		return err
+	} else if existing != nil {
+		thread = existing.Thread
+	}
+	if rule == "" {
+		if _, err := tx.Exec("DELETE FROM thread_rules WHERE account = ? AND thread = ?", account, thread); err != nil {
+			return err
+		}
+		return tx.Commit()
+	}
+
+	if _, err := tx.Exec("UPDATE thread_rules SET rule = ? WHERE account = ? AND thread = ?", rule, account, thread); err != nil {
+		return err
+	}
+	now := time.Now().Unix()
+	for _, id := range messageIDs {
+		_, err := tx.Exec(
+			"INSERT OR REPLACE INTO thread_rules (account, message_id, thread, rule, created_at) VALUES (?, ?, ?, ?, ?)",
+			account, id, thread, rule, now,
+		)
+		if err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+// GetThreadRule returns the rule of the thread any of messageIDs is in, nil for none
+func (c *Cache) GetThreadRule(account string, messageIDs []string) (*ThreadRule, error) {
+	return threadRule(c.db, account, messageIDs)
+}
+
+// querier is a *sql.DB or *sql.Tx
+type querier interface {
+	QueryRow(query string, args ...any) *sql.Row
+}
+
+func threadRule(q querier, account string, messageIDs []string) (*ThreadRule, error) {
+	for _, id := range messageIDs {
+		var r ThreadRule
+		err := q.QueryRow(
+			"SELECT thread, rule FROM thread_rules WHERE account = ? AND message_id = ?",
+			account, id,
+		).Scan(&r.Thread, &r.Rule)
+		if err == sql.ErrNoRows {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		return &r, nil
+	}
+	return nil, nil
+}
+
+// GetThreadRules returns the rule of every Message-ID in a muted or followed thread
+func (c *Cache) GetThreadRules(account string) (map[string]ThreadRule, error) {
+	rows, err := c.db.Query("SELECT message_id, thread, rule FROM thread_rules WHERE account = ?", account)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	rules := make(map[string]ThreadRule)
+	for rows.Next() {
+		var id string
+		var r ThreadRule
+		if err := rows.Scan(&id, &r.Thread, &r.Rule); err != nil {
+			return nil, err
+		}
+		rules[id] = r
+	}
+	return rules, rows.Err()
+}
+
+// JoinThread adds a message to a muted or followed thread, so replies to it are caught too
+func (c *Cache) JoinThread(account, messageID string, rule ThreadRule) error {
+	if messageID == "" {
+		return nil
+	}
+	_, err := c.db.Exec(
+		"INSERT OR IGNORE INTO thread_rules (account, message_id, thread, rule, created_at) VALUES (?, ?, ?, ?, ?)",
+		account, messageID, rule.Thread, rule.Rule, time.Now().Unix(),
+	)
+	return err
+}
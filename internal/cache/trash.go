@@ -0,0 +1,49 @@
+package cache
+
+import (
+	"database/sql"
+	"time"
+)
+
+// Moving a message to trash gives it a new UID, so the folder it came from is kept by
+// Message-ID until it's restored or the trash is emptied.
+
+// RecordTrashOrigin remembers the folder a message was in before it was moved to trash
+func (c *Cache) RecordTrashOrigin(account, messageID, mailbox string) error {
+	if messageID == "" {
+		return nil
+	}
+	_, err := c.db.Exec(
+		"INSERT OR REPLACE INTO trash_origins (account, message_id, mailbox, trashed_at) VALUES (?, ?, ?, ?)",
+		account, messageID, mailbox, time.Now().Unix(),
+	)
+	return err
+}
+
+// TrashOrigin returns the folder a trashed message came from, if it was recorded
+func (c *Cache) TrashOrigin(account, messageID string) (string, bool, error) {
+	var mailbox string
+	err := c.db.QueryRow(
+		"SELECT mailbox FROM trash_origins WHERE account = ? AND message_id = ?",
+		account, messageID,
+	).Scan(&mailbox)
+	if err == sql.ErrNoRows {
+		return "", false, nil
+	}
+	if err != nil {
+		return "", false, err
+	}
+	return mailbox, true, nil
+}
+
+// ForgetTrashOrigin drops the origin of a message once it left the trash
+func (c *Cache) ForgetTrashOrigin(account, messageID string) error {
+	_, err := c.db.Exec("DELETE FROM trash_origins WHERE account = ? AND message_id = ?", account, messageID)
+	return err
+}
+
+// ClearTrashOrigins drops the origins recorded for an account, after its trash was emptied
+func (c *Cache) ClearTrashOrigins(account string) error {
+	_, err := c.db.Exec("DELETE FROM trash_origins WHERE account = ?", account)
+	return err
+}
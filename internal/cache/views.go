@@ -0,0 +1,34 @@
+package cache
+
+import (
+	"database/sql"
+	"time"
+)
+
+// The mail list marks the emails that arrived since a folder was last opened, so the
+// time each folder was last viewed is kept per account.
+
+// LastViewed returns when a folder was last opened, zero if it never was
+func (c *Cache) LastViewed(account, mailbox string) (time.Time, error) {
+	var seenAt int64
+	err := c.db.QueryRow(
+		"SELECT seen_at FROM folder_views WHERE account = ? AND mailbox = ?",
+		account, mailbox,
+	).Scan(&seenAt)
+	if err == sql.ErrNoRows {
+		return time.Time{}, nil
+	}
+	if err != nil {
+		return time.Time{}, err
+	}
+	return time.Unix(seenAt, 0), nil
+}
+
+// SetLastViewed records when a folder was opened
+func (c *Cache) SetLastViewed(account, mailbox string, t time.Time) error {
+	_, err := c.db.Exec(
+		"INSERT OR REPLACE INTO folder_views (account, mailbox, seen_at) VALUES (?, ?, ?)",
+		account, mailbox, t.Unix(),
+	)
+	return err
+}
@@ -22,7 +22,24 @@ type Event struct {
 	Notes              string
 	Calendar           string
 	AllDay             bool
-	AlarmMinutesBefore int // Minutes before event to trigger alarm (0 = no alarm)
+	AlarmMinutesBefore int    // Minutes before event to trigger alarm (0 = no alarm)
+	TimeZone           string // IANA name of the zone the event was created in, "" for local
+	Attendees          []Attendee
+}
+
+// Attendee response statuses, as reported by the calendar backend
+const (
+	AttendeePending   = "pending"
+	AttendeeAccepted  = "accepted"
+	AttendeeDeclined  = "declined"
+	AttendeeTentative = "tentative"
+)
+
+// Attendee is a person invited to an event
+type Attendee struct {
+	Name   string
+	Email  string
+	Status string // one of the Attendee* statuses, empty when the backend doesn't say
 }
 
 // Calendar represents a calendar source
@@ -40,7 +57,8 @@ type Client interface {
 	// ListEvents returns events within the given time range
 	ListEvents(start, end time.Time) ([]Event, error)
 
-	// CreateEvent creates a new event and returns its ID
+	// CreateEvent creates a new event and returns its ID. EventKit can't add
+	// attendees, so they're left out; they're invited by email instead.
 	CreateEvent(event Event) (string, error)
 
 	// DeleteEvent removes an event by its ID
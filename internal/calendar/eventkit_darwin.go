@@ -107,6 +107,12 @@ func (c *eventKitClient) ListEvents(start, end time.Time) ([]Event, error) {
 		Calendar   string `json:"calendar"`
 		CalendarID string `json:"calendarID"`
 		AllDay     bool   `json:"allDay"`
+		TimeZone   string `json:"timeZone"`
+		Attendees  []struct {
+			Name   string `json:"name"`
+			Email  string `json:"email"`
+			Status string `json:"status"`
+		} `json:"attendees"`
 	}
 
 	if err := json.Unmarshal([]byte(jsonStr), &rawEvents); err != nil {
@@ -124,6 +130,10 @@ func (c *eventKitClient) ListEvents(start, end time.Time) ([]Event, error) {
 			Notes:     re.Notes,
 			Calendar:  re.Calendar,
 			AllDay:    re.AllDay,
+			TimeZone:  re.TimeZone,
+		}
+		for _, a := range re.Attendees {
+			events[i].Attendees = append(events[i].Attendees, Attendee{Name: a.Name, Email: a.Email, Status: a.Status})
 		}
 	}
 
@@ -143,6 +153,9 @@ func (c *eventKitClient) CreateEvent(event Event) (string, error) {
 	cNotes := C.CString(event.Notes)
 	defer C.free(unsafe.Pointer(cNotes))
 
+	cTimeZone := C.CString(event.TimeZone)
+	defer C.free(unsafe.Pointer(cTimeZone))
+
 	allDay := C.int(0)
 	if event.AllDay {
 		allDay = C.int(1)
@@ -157,6 +170,7 @@ func (c *eventKitClient) CreateEvent(event Event) (string, error) {
 		cNotes,
 		allDay,
 		C.int(event.AlarmMinutesBefore),
+		cTimeZone,
 	)
 
 	if cEventID == nil {
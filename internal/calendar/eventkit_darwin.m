@@ -24,6 +24,32 @@ static char* copyString(NSString *str) {
     return copy;
 }
 
+// participantStatus maps an attendee's response to the status names calendar.Attendee uses
+static NSString* participantStatus(EKParticipantStatus status) {
+    switch (status) {
+        case EKParticipantStatusAccepted:
+            return @"accepted";
+        case EKParticipantStatusDeclined:
+            return @"declined";
+        case EKParticipantStatusTentative:
+            return @"tentative";
+        case EKParticipantStatusPending:
+            return @"pending";
+        default:
+            return @"";
+    }
+}
+
+// participantEmail returns the address of an attendee's mailto: URL
+static NSString* participantEmail(EKParticipant *participant) {
+    NSURL *url = participant.URL;
+    if (url == nil) return @"";
+    if ([[url.scheme lowercaseString] isEqualToString:@"mailto"]) {
+        return url.resourceSpecifier ?: @"";
+    }
+    return url.absoluteString ?: @"";
+}
+
 static NSString* colorToHex(CGColorRef cgColor) {
     if (cgColor == NULL) return @"#808080";
 
@@ -181,6 +207,15 @@ char* ListEvents(long long startTimestamp, long long endTimestamp) {
 
         NSMutableArray *eventDicts = [NSMutableArray array];
         for (EKEvent *event in events) {
+            NSMutableArray *attendees = [NSMutableArray array];
+            for (EKParticipant *participant in event.attendees) {
+                [attendees addObject:@{
+                    @"name": participant.name ?: @"",
+                    @"email": participantEmail(participant),
+                    @"status": participantStatus(participant.participantStatus)
+                }];
+            }
+
             NSDictionary *dict = @{
                 @"id": event.eventIdentifier ?: @"",
                 @"title": event.title ?: @"",
@@ -190,7 +225,9 @@ char* ListEvents(long long startTimestamp, long long endTimestamp) {
                 @"notes": event.notes ?: @"",
                 @"calendar": event.calendar.title ?: @"",
                 @"calendarID": event.calendar.calendarIdentifier ?: @"",
-                @"allDay": @(event.allDay)
+                @"allDay": @(event.allDay),
+                @"timeZone": event.timeZone.name ?: @"",
+                @"attendees": attendees
             };
             [eventDicts addObject:dict];
         }
@@ -213,7 +250,7 @@ char* ListEvents(long long startTimestamp, long long endTimestamp) {
 
 char* CreateEvent(const char* title, long long startTimestamp, long long endTimestamp,
                   const char* calendarID, const char* location, const char* notes, int allDay,
-                  int alarmMinutesBefore) {
+                  int alarmMinutesBefore, const char* timeZone) {
     @autoreleasepool {
         if (!accessGranted) {
             if (RequestCalendarAccess() != EK_SUCCESS) {
@@ -229,6 +266,14 @@ char* CreateEvent(const char* title, long long startTimestamp, long long endTime
         event.endDate = [NSDate dateWithTimeIntervalSince1970:(NSTimeInterval)endTimestamp];
         event.allDay = (allDay != 0);
 
+        // Events without a zone keep the system's
+        if (timeZone != NULL && strlen(timeZone) > 0) {
+            NSTimeZone *zone = [NSTimeZone timeZoneWithName:[NSString stringWithUTF8String:timeZone]];
+            if (zone != nil) {
+                event.timeZone = zone;
+            }
+        }
+
         if (location != NULL && strlen(location) > 0) {
             event.location = [NSString stringWithUTF8String:location];
         }
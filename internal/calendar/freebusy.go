@@ -0,0 +1,69 @@
+package calendar
+
+import (
+	"sort"
+	"time"
+)
+
+// Free slots are offered on the half hour during the working day, so a conflict isn't
+// answered with a slot in the middle of the night
+const (
+	slotStep       = 30 * time.Minute
+	dayStartHour   = 8
+	dayEndHour     = 20
+	slotSearchDays = 2 // the proposed day and the next
+)
+
+// Conflicts returns the events overlapping start to end. All-day events, such as
+// holidays and birthdays, don't block time.
+func Conflicts(events []Event, start, end time.Time) []Event {
+	var conflicts []Event
+	for _, e := range events {
+		if !e.AllDay && e.StartTime.Before(end) && e.EndTime.After(start) {
+			conflicts = append(conflicts, e)
+		}
+	}
+	return conflicts
+}
+
+// FreeSlots returns up to n start times, nearest to start first, where an event as long
+// as start to end fits between the events, during the day on the proposed day or the
+// next one and not before now
+func FreeSlots(events []Event, start, end, now time.Time, n int) []time.Time {
+	duration := end.Sub(start)
+	loc := start.Location()
+
+	var free []time.Time
+	for d := 0; d < slotSearchDays; d++ {
+		// Each slot is built from the wall clock, so days when the clocks change still
+		// start at 8:00
+		year, month, day := start.AddDate(0, 0, d).Date()
+		last := time.Date(year, month, day, dayEndHour, 0, 0, 0, loc)
+		for m := dayStartHour * 60; ; m += int(slotStep / time.Minute) {
+			t := time.Date(year, month, day, 0, m, 0, 0, loc)
+			if t.Add(duration).After(last) {
+				break
+			}
+			if t.Equal(start) || t.Before(now) {
+				continue
+			}
+			if len(Conflicts(events, t, t.Add(duration))) == 0 {
+				free = append(free, t)
+			}
+		}
+	}
+
+	distance := func(t time.Time) time.Duration {
+		if d := t.Sub(start); d >= 0 {
+			return d
+		}
+		return start.Sub(t)
+	}
+	sort.SliceStable(free, func(i, j int) bool {
+		return distance(free[i]) < distance(free[j])
+	})
+	if len(free) > n {
+		free = free[:n]
+	}
+	return free
+}
@@ -0,0 +1,117 @@
+package calendar
+
+import (
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestConflicts(t *testing.T) {
+	at := func(hour, minute int) time.Time { return time.Date(2025, 6, 10, hour, minute, 0, 0, time.UTC) }
+	events := []Event{
+		{Title: "standup", StartTime: at(9, 0), EndTime: at(9, 30)},
+		{Title: "lunch", StartTime: at(12, 0), EndTime: at(13, 0)},
+		{Title: "holiday", StartTime: at(0, 0), EndTime: at(23, 59), AllDay: true},
+	}
+	tests := []struct {
+		start, end time.Time
+		want       []string
+	}{
+		{at(9, 0), at(10, 0), []string{"standup"}},
+		{at(9, 30), at(12, 0), nil}, // touching isn't overlapping
+		{at(8, 0), at(12, 30), []string{"standup", "lunch"}},
+		{at(14, 0), at(15, 0), nil},
+	}
+	for _, tt := range tests {
+		var got []string
+		for _, e := range Conflicts(events, tt.start, tt.end) {
+			got = append(got, e.Title)
+		}
+		if !slices.Equal(got, tt.want) {
+			t.Errorf("Conflicts(%s-%s) = %v, want %v", tt.start.Format("15:04"), tt.end.Format("15:04"), got, tt.want)
+		}
+	}
+}
+
+func TestFreeSlots(t *testing.T) {
+	at := func(day, hour, minute int) time.Time { return time.Date(2025, 6, day, hour, minute, 0, 0, time.UTC) }
+	events := []Event{
+		{StartTime: at(10, 9, 0), EndTime: at(10, 10, 0)},
+		{StartTime: at(10, 10, 30), EndTime: at(10, 12, 0)},
+		{StartTime: at(10, 0, 0), EndTime: at(11, 0, 0), AllDay: true},
+	}
+	tests := []struct {
+		name       string
+		start, end time.Time
+		now        time.Time
+		n          int
+		want       []time.Time
+	}{
+		{
+			name:  "nearest first",
+			start: at(10, 10, 0), end: at(10, 11, 0),
+			now:  at(10, 7, 0),
+			n:    3,
+			want: []time.Time{at(10, 8, 0), at(10, 12, 0), at(10, 12, 30)},
+		},
+		{
+			name:  "not in the past",
+			start: at(10, 10, 0), end: at(10, 11, 0),
+			now:  at(10, 12, 10),
+			n:    3,
+			want: []time.Time{at(10, 12, 30), at(10, 13, 0), at(10, 13, 30)},
+		},
+		{
+			name:  "into the next day",
+			start: at(10, 19, 30), end: at(10, 21, 0),
+			now:  at(10, 7, 0),
+			n:    3,
+			want: []time.Time{at(10, 18, 30), at(10, 18, 0), at(10, 17, 30)},
+		},
+		{
+			name:  "the working day is over",
+			start: at(10, 19, 30), end: at(10, 20, 30),
+			now:  at(10, 19, 45),
+			n:    2,
+			want: []time.Time{at(11, 8, 0), at(11, 8, 30)},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := FreeSlots(events, tt.start, tt.end, tt.now, tt.n)
+			if !slices.EqualFunc(got, tt.want, time.Time.Equal) {
+				t.Errorf("FreeSlots() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFreeSlotsWhenClocksChange(t *testing.T) {
+	ny, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, date := range []time.Time{
+		time.Date(2025, 3, 9, 0, 0, 0, 0, ny),  // clocks go forward at 2:00
+		time.Date(2025, 11, 2, 0, 0, 0, 0, ny), // and back at 2:00
+	} {
+		year, month, day := date.Date()
+		at := func(hour, minute int) time.Time { return time.Date(year, month, day, hour, minute, 0, 0, ny) }
+		events := []Event{{StartTime: at(8, 30), EndTime: at(19, 0)}}
+
+		got := FreeSlots(events, at(8, 30), at(9, 30), date.AddDate(0, 0, -1), 40)
+		var today []string
+		for _, t := range got {
+			if t.Day() == day {
+				today = append(today, t.Format("15:04"))
+			}
+		}
+		// 8:00 is too short before the event; only 19:00 fits after it
+		if want := []string{"19:00"}; !slices.Equal(today, want) {
+			t.Errorf("%s: free on the day at %v, want %v", date.Format(time.DateOnly), today, want)
+		}
+		if len(got) < 2 || got[1].Day() == day || got[1].Hour() != 8 || got[1].Minute() != 0 {
+			t.Errorf("%s: free at %v, want 8:00 the next day after 19:00", date.Format(time.DateOnly), got)
+		}
+	}
+}
@@ -0,0 +1,283 @@
+package calendar
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// partStats maps attendee statuses to iCalendar PARTSTAT values
+var partStats = map[string]string{
+	AttendeePending:   "NEEDS-ACTION",
+	AttendeeAccepted:  "ACCEPTED",
+	AttendeeDeclined:  "DECLINED",
+	AttendeeTentative: "TENTATIVE",
+}
+
+// ExportICS returns the events as an iCalendar file other calendar apps can import
+func ExportICS(events []Event) []byte {
+	var b strings.Builder
+	line := func(s string) {
+		b.WriteString(foldLine(s))
+		b.WriteString("\r\n")
+	}
+
+	line("BEGIN:VCALENDAR")
+	line("PRODID:-//maily//maily//EN")
+	line("VERSION:2.0")
+	line("CALSCALE:GREGORIAN")
+	line("METHOD:PUBLISH")
+	for _, event := range events {
+		line("BEGIN:VEVENT")
+		eventLines(line, event)
+		for _, a := range event.Attendees {
+			params := "PARTSTAT=" + partStats[AttendeePending]
+			if stat, ok := partStats[a.Status]; ok {
+				params = "PARTSTAT=" + stat
+			}
+			if a.Name != "" {
+				params = "CN=" + icsParam(a.Name) + ";" + params
+			}
+			line("ATTENDEE;" + params + ":mailto:" + a.Email)
+		}
+		if event.AlarmMinutesBefore > 0 {
+			line("BEGIN:VALARM")
+			line("ACTION:DISPLAY")
+			line("DESCRIPTION:" + icsText(event.Title))
+			line(fmt.Sprintf("TRIGGER:-PT%dM", event.AlarmMinutesBefore))
+			line("END:VALARM")
+		}
+		line("END:VEVENT")
+	}
+	line("END:VCALENDAR")
+	return []byte(b.String())
+}
+
+// ParseICS reads the events in an iCalendar (.ics) file. Recurring events are read as
+// their first occurrence. Times with a TZID are in that zone, and ones with neither a
+// zone nor a Z in local time; a TZID that isn't a zone maily knows is an error, rather
+// than a guess that would move the event.
+func ParseICS(r io.Reader) ([]Event, error) {
+	lines, err := unfoldICS(r)
+	if err != nil {
+		return nil, err
+	}
+
+	var events []Event
+	var event *Event
+	var end time.Time
+	var duration time.Duration
+	var inAlarm bool
+
+	for _, line := range lines {
+		name, params, value, ok := icsProperty(line)
+		if !ok {
+			continue
+		}
+
+		switch {
+		case name == "BEGIN" && strings.EqualFold(value, "VEVENT"):
+			event = &Event{}
+			end, duration, inAlarm = time.Time{}, 0, false
+			continue
+		case name == "BEGIN" && strings.EqualFold(value, "VALARM"):
+			inAlarm = true
+			continue
+		case name == "END" && strings.EqualFold(value, "VALARM"):
+			inAlarm = false
+			continue
+		case name == "END" && strings.EqualFold(value, "VEVENT"):
+			if event != nil && !event.StartTime.IsZero() {
+				event.EndTime = eventEnd(*event, end, duration)
+				events = append(events, *event)
+			}
+			event = nil
+			continue
+		case event == nil:
+			continue
+		case inAlarm:
+			if name == "TRIGGER" && icsParamValue(params, "RELATED") != "END" {
+				if d, ok := parseICSDuration(value); ok && d <= 0 {
+					event.AlarmMinutesBefore = int(-d / time.Minute)
+				}
+			}
+			continue
+		}
+
+		switch name {
+		case "UID":
+			event.ID = value
+		case "SUMMARY":
+			event.Title = icsUnescape(value)
+		case "LOCATION":
+			event.Location = icsUnescape(value)
+		case "DESCRIPTION":
+			event.Notes = icsUnescape(value)
+		case "DTSTART":
+			t, allDay, zone, err := parseICSTime(params, value)
+			if err != nil {
+				return nil, fmt.Errorf("event %q: DTSTART: %w", event.Title, err)
+			}
+			event.StartTime, event.AllDay, event.TimeZone = t, allDay, zone
+		case "DTEND":
+			t, _, _, err := parseICSTime(params, value)
+			if err != nil {
+				return nil, fmt.Errorf("event %q: DTEND: %w", event.Title, err)
+			}
+			end = t
+		case "DURATION":
+			if d, ok := parseICSDuration(value); ok {
+				duration = d
+			}
+		case "ATTENDEE":
+			email := value
+			if len(email) > 7 && strings.EqualFold(email[:7], "mailto:") {
+				email = email[7:]
+			}
+			a := Attendee{Name: icsParamValue(params, "CN"), Email: email}
+			for status, stat := range partStats {
+				if strings.EqualFold(icsParamValue(params, "PARTSTAT"), stat) {
+					a.Status = status
+				}
+			}
+			event.Attendees = append(event.Attendees, a)
+		}
+	}
+	return events, nil
+}
+
+// eventEnd returns when an event read from a file ends. iCalendar ends all-day events
+// at the start of the day after, where maily ends them the second before; without an
+// end or duration they last a day, or no time at all.
+func eventEnd(event Event, end time.Time, duration time.Duration) time.Time {
+	switch {
+	case end.IsZero() && duration > 0:
+		end = event.StartTime.Add(duration)
+	case end.IsZero() && event.AllDay:
+		end = event.StartTime.AddDate(0, 0, 1)
+	case end.IsZero():
+		return event.StartTime
+	}
+	if event.AllDay {
+		if !end.After(event.StartTime) {
+			end = event.StartTime.AddDate(0, 0, 1)
+		}
+		return end.Add(-time.Second)
+	}
+	return end
+}
+
+// parseICSTime reads a DATE or DATE-TIME value, returning whether it's a date and the
+// zone it's in when a TZID names one
+func parseICSTime(params []string, value string) (t time.Time, date bool, zone string, err error) {
+	if strings.EqualFold(icsParamValue(params, "VALUE"), "DATE") || len(value) == 8 {
+		t, err = time.ParseInLocation("20060102", value, time.Local)
+		return t, true, "", err
+	}
+	if strings.HasSuffix(value, "Z") {
+		t, err = time.Parse("20060102T150405Z", value)
+		return t, false, "", err
+	}
+	loc := time.Local
+	if tzid := icsParamValue(params, "TZID"); tzid != "" {
+		if loc, err = Location(tzid); err != nil {
+			return time.Time{}, false, "", err
+		}
+		zone = tzid
+	}
+	t, err = time.ParseInLocation("20060102T150405", value, loc)
+	return t, false, zone, err
+}
+
+var icsDurationRe = regexp.MustCompile(`^([+-])?P(?:(\d+)W)?(?:(\d+)D)?(?:T(?:(\d+)H)?(?:(\d+)M)?(?:(\d+)S)?)?$`)
+
+// parseICSDuration reads a DURATION value such as PT1H30M or -P1D
+func parseICSDuration(value string) (time.Duration, bool) {
+	m := icsDurationRe.FindStringSubmatch(strings.ToUpper(value))
+	if m == nil {
+		return 0, false
+	}
+	var d time.Duration
+	for i, unit := range []time.Duration{7 * 24 * time.Hour, 24 * time.Hour, time.Hour, time.Minute, time.Second} {
+		n, _ := strconv.Atoi(m[i+2])
+		d += time.Duration(n) * unit
+	}
+	if m[1] == "-" {
+		d = -d
+	}
+	return d, true
+}
+
+// unfoldICS joins folded content lines (RFC 5545 section 3.1)
+func unfoldICS(r io.Reader) ([]string, error) {
+	var lines []string
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 4*1024*1024) // attachments make long lines
+	for scanner.Scan() {
+		line := strings.TrimRight(scanner.Text(), "\r")
+		if len(line) > 0 && (line[0] == ' ' || line[0] == '\t') && len(lines) > 0 {
+			lines[len(lines)-1] += line[1:]
+			continue
+		}
+		lines = append(lines, line)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("reading iCalendar: %w", err)
+	}
+	return lines, nil
+}
+
+// icsProperty splits "DTSTART;TZID=Europe/Berlin:20250105T140000" into DTSTART,
+// [TZID=Europe/Berlin] and 20250105T140000
+func icsProperty(line string) (name string, params []string, value string, ok bool) {
+	colon := -1
+	quoted := false
+	for i, r := range line {
+		if r == '"' {
+			quoted = !quoted
+		} else if r == ':' && !quoted {
+			colon = i
+			break
+		}
+	}
+	if colon < 0 {
+		return "", nil, "", false
+	}
+	parts := strings.Split(line[:colon], ";")
+	return strings.ToUpper(parts[0]), parts[1:], line[colon+1:], true
+}
+
+// icsParamValue returns the value of the named parameter, unquoted
+func icsParamValue(params []string, name string) string {
+	for _, p := range params {
+		if key, value, ok := strings.Cut(p, "="); ok && strings.EqualFold(key, name) {
+			return strings.Trim(value, `"`)
+		}
+	}
+	return ""
+}
+
+// icsUnescape decodes the escapes icsText writes
+func icsUnescape(s string) string {
+	if !strings.Contains(s, `\`) {
+		return s
+	}
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] == '\\' && i+1 < len(s) {
+			i++
+			if s[i] == 'n' || s[i] == 'N' {
+				b.WriteByte('\n')
+			} else {
+				b.WriteByte(s[i])
+			}
+			continue
+		}
+		b.WriteByte(s[i])
+	}
+	return b.String()
+}
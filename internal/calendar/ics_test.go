@@ -0,0 +1,156 @@
+package calendar
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+// icsFile joins content lines into an iCalendar file holding one event
+func icsFile(lines ...string) string {
+	return "BEGIN:VCALENDAR\r\nBEGIN:VEVENT\r\n" + strings.Join(lines, "\r\n") + "\r\nEND:VEVENT\r\nEND:VCALENDAR\r\n"
+}
+
+func TestParseICS(t *testing.T) {
+	berlin, err := time.LoadLocation("Europe/Berlin")
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		name string
+		ics  string
+		want Event
+	}{
+		{
+			name: "utc times",
+			ics:  icsFile("UID:1", "SUMMARY:Standup", "DTSTART:20250602T090000Z", "DTEND:20250602T091500Z"),
+			want: Event{
+				ID: "1", Title: "Standup",
+				StartTime: time.Date(2025, 6, 2, 9, 0, 0, 0, time.UTC),
+				EndTime:   time.Date(2025, 6, 2, 9, 15, 0, 0, time.UTC),
+			},
+		},
+		{
+			name: "times in a named zone",
+			ics:  icsFile("SUMMARY:Lunch", "DTSTART;TZID=Europe/Berlin:20250105T120000", "DURATION:PT1H30M"),
+			want: Event{
+				Title:     "Lunch",
+				StartTime: time.Date(2025, 1, 5, 12, 0, 0, 0, berlin),
+				EndTime:   time.Date(2025, 1, 5, 13, 30, 0, 0, berlin),
+				TimeZone:  "Europe/Berlin",
+			},
+		},
+		{
+			name: "all day",
+			ics:  icsFile("SUMMARY:Holiday", "DTSTART;VALUE=DATE:20251225", "DTEND;VALUE=DATE:20251227"),
+			want: Event{
+				Title:     "Holiday",
+				StartTime: time.Date(2025, 12, 25, 0, 0, 0, 0, time.Local),
+				EndTime:   time.Date(2025, 12, 26, 23, 59, 59, 0, time.Local),
+				AllDay:    true,
+			},
+		},
+		{
+			name: "folded and escaped text, alarm and attendees",
+			ics: icsFile(
+				"SUMMARY:Plan\\, review",
+				"DESCRIPTION:line one\\nline",
+				"  two",
+				"DTSTART:20250602T090000Z",
+				`ATTENDEE;CN="Ana Li";PARTSTAT=ACCEPTED:mailto:ana@example.com`,
+				"ATTENDEE:MAILTO:bo@example.com",
+				"BEGIN:VALARM",
+				"TRIGGER:-PT15M",
+				"END:VALARM",
+			),
+			want: Event{
+				Title:     "Plan, review",
+				Notes:     "line one\nline two",
+				StartTime: time.Date(2025, 6, 2, 9, 0, 0, 0, time.UTC),
+				EndTime:   time.Date(2025, 6, 2, 9, 0, 0, 0, time.UTC),
+				Attendees: []Attendee{
+					{Name: "Ana Li", Email: "ana@example.com", Status: AttendeeAccepted},
+					{Email: "bo@example.com"},
+				},
+				AlarmMinutesBefore: 15,
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			events, err := ParseICS(strings.NewReader(tt.ics))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(events) != 1 {
+				t.Fatalf("ParseICS() = %d events, want 1", len(events))
+			}
+			got := events[0]
+			if !got.StartTime.Equal(tt.want.StartTime) || !got.EndTime.Equal(tt.want.EndTime) {
+				t.Errorf("times %v - %v, want %v - %v", got.StartTime, got.EndTime, tt.want.StartTime, tt.want.EndTime)
+			}
+			got.StartTime, got.EndTime = tt.want.StartTime, tt.want.EndTime
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ParseICS() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseICSUnknownZone(t *testing.T) {
+	_, err := ParseICS(strings.NewReader(icsFile("SUMMARY:Call", "DTSTART;TZID=Mars/Olympus_Mons:20250602T090000")))
+	if err == nil || !strings.Contains(err.Error(), "Mars/Olympus_Mons") {
+		t.Errorf("ParseICS() error = %v, want it to name the unknown zone", err)
+	}
+}
+
+func TestExportICSRoundTrip(t *testing.T) {
+	events := []Event{
+		{
+			ID:        "a1",
+			Title:     "Review; notes, and \\ more",
+			StartTime: time.Date(2025, 6, 2, 9, 0, 0, 0, time.UTC),
+			EndTime:   time.Date(2025, 6, 2, 10, 0, 0, 0, time.UTC),
+			Location:  "Room 4",
+			Notes:     "first line\nsecond line " + strings.Repeat("long ", 30),
+			Attendees: []Attendee{
+				{Name: "Ana Li", Email: "ana@example.com", Status: AttendeeDeclined},
+				{Email: "bo@example.com", Status: AttendeePending},
+			},
+			AlarmMinutesBefore: 10,
+		},
+		{
+			ID:        "a2",
+			Title:     "Trip",
+			StartTime: time.Date(2025, 7, 1, 0, 0, 0, 0, time.Local),
+			EndTime:   time.Date(2025, 7, 3, 23, 59, 59, 0, time.Local),
+			AllDay:    true,
+		},
+	}
+	data := ExportICS(events)
+	for _, line := range strings.Split(string(data), "\r\n") {
+		if len(line) > 75 {
+			t.Errorf("line longer than 75 octets: %q", line)
+		}
+	}
+
+	parsed, err := ParseICS(strings.NewReader(string(data)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(parsed) != len(events) {
+		t.Fatalf("read back %d events, want %d", len(parsed), len(events))
+	}
+	for i, want := range events {
+		got := parsed[i]
+		want.ID += "@maily"
+		if !got.StartTime.Equal(want.StartTime) || !got.EndTime.Equal(want.EndTime) {
+			t.Errorf("event %d: times %v - %v, want %v - %v", i, got.StartTime, got.EndTime, want.StartTime, want.EndTime)
+		}
+		got.StartTime, got.EndTime = want.StartTime, want.EndTime
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("event %d read back as %+v, want %+v", i, got, want)
+		}
+	}
+}
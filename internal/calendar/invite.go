@@ -0,0 +1,125 @@
+package calendar
+
+import (
+	"fmt"
+	"net/mail"
+	"strings"
+	"time"
+)
+
+// ParseAttendees parses a comma-separated list of addresses, such as
+// "ana@example.com, Bo <bo@example.com>"; empty input has no attendees
+func ParseAttendees(list string) ([]Attendee, error) {
+	if strings.TrimSpace(list) == "" {
+		return nil, nil
+	}
+	addrs, err := mail.ParseAddressList(list)
+	if err != nil {
+		return nil, fmt.Errorf("invalid attendees: %w", err)
+	}
+	attendees := make([]Attendee, len(addrs))
+	for i, a := range addrs {
+		attendees[i] = Attendee{Name: a.Name, Email: a.Address}
+	}
+	return attendees, nil
+}
+
+// FormatAttendees returns attendees as the comma-separated list ParseAttendees reads
+func FormatAttendees(attendees []Attendee) string {
+	list := make([]string, len(attendees))
+	for i, a := range attendees {
+		list[i] = (&mail.Address{Name: a.Name, Address: a.Email}).String()
+	}
+	return strings.Join(list, ", ")
+}
+
+// Invitation returns an iCalendar METHOD:REQUEST inviting the event's attendees, from
+// organizer. The event's ID is the invitation's UID, so sending it again after the
+// event changes updates the invitees' copy instead of adding another.
+func Invitation(event Event, organizer string) []byte {
+	var b strings.Builder
+	line := func(s string) {
+		b.WriteString(foldLine(s))
+		b.WriteString("\r\n")
+	}
+
+	line("BEGIN:VCALENDAR")
+	line("PRODID:-//maily//maily//EN")
+	line("VERSION:2.0")
+	line("CALSCALE:GREGORIAN")
+	line("METHOD:REQUEST")
+	line("BEGIN:VEVENT")
+	eventLines(line, event)
+	line("ORGANIZER:mailto:" + organizer)
+	for _, a := range event.Attendees {
+		params := "ROLE=REQ-PARTICIPANT;PARTSTAT=NEEDS-ACTION;RSVP=TRUE"
+		if a.Name != "" {
+			params = "CN=" + icsParam(a.Name) + ";" + params
+		}
+		line("ATTENDEE;" + params + ":mailto:" + a.Email)
+	}
+	line("SEQUENCE:0")
+	line("STATUS:CONFIRMED")
+	line("END:VEVENT")
+	line("END:VCALENDAR")
+	return []byte(b.String())
+}
+
+// eventLines writes the VEVENT properties invitations and exports share: the UID,
+// times, title, location and notes
+func eventLines(line func(string), event Event) {
+	line("UID:" + event.ID + "@maily")
+	line("DTSTAMP:" + icsTime(time.Now()))
+	if event.AllDay {
+		line("DTSTART;VALUE=DATE:" + event.StartTime.Format("20060102"))
+		line("DTEND;VALUE=DATE:" + event.EndTime.AddDate(0, 0, 1).Format("20060102"))
+	} else {
+		line("DTSTART:" + icsTime(event.StartTime))
+		line("DTEND:" + icsTime(event.EndTime))
+	}
+	line("SUMMARY:" + icsText(event.Title))
+	if event.Location != "" {
+		line("LOCATION:" + icsText(event.Location))
+	}
+	if event.Notes != "" {
+		line("DESCRIPTION:" + icsText(event.Notes))
+	}
+}
+
+func icsTime(t time.Time) string {
+	return t.UTC().Format("20060102T150405Z")
+}
+
+// icsText escapes a TEXT value (RFC 5545 section 3.3.11)
+func icsText(s string) string {
+	return strings.NewReplacer(
+		`\`, `\\`,
+		";", `\;`,
+		",", `\,`,
+		"\r\n", `\n`,
+		"\n", `\n`,
+	).Replace(s)
+}
+
+// icsParam quotes a parameter value; double quotes can't be escaped, so they're dropped
+func icsParam(s string) string {
+	return `"` + strings.ReplaceAll(s, `"`, "") + `"`
+}
+
+// foldLine splits a content line longer than 75 octets into continuation lines,
+// without cutting a UTF-8 sequence in two
+func foldLine(s string) string {
+	const limit = 75
+	var b strings.Builder
+	n := 0
+	for _, r := range s {
+		size := len(string(r))
+		if n+size > limit {
+			b.WriteString("\r\n ")
+			n = 1
+		}
+		b.WriteRune(r)
+		n += size
+	}
+	return b.String()
+}
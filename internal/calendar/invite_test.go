@@ -0,0 +1,133 @@
+package calendar
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+	"unicode/utf8"
+)
+
+func TestParseAttendees(t *testing.T) {
+	tests := []struct {
+		list string
+		want []Attendee
+		err  bool
+	}{
+		{"", nil, false},
+		{"  ", nil, false},
+		{"ana@example.com", []Attendee{{Email: "ana@example.com"}}, false},
+		{"ana@example.com, Bo Li <bo@example.com>", []Attendee{{Email: "ana@example.com"}, {Name: "Bo Li", Email: "bo@example.com"}}, false},
+		{`"Doe, Jo" <jo@example.com>`, []Attendee{{Name: "Doe, Jo", Email: "jo@example.com"}}, false},
+		{"not an address", nil, true},
+		{"ana@example.com,", []Attendee{{Email: "ana@example.com"}}, false},
+	}
+	for _, tt := range tests {
+		got, err := ParseAttendees(tt.list)
+		if (err != nil) != tt.err || !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("ParseAttendees(%q) = %+v, %v; want %+v, error %v", tt.list, got, err, tt.want, tt.err)
+		}
+	}
+}
+
+func TestFormatAttendeesRoundTrip(t *testing.T) {
+	attendees := []Attendee{{Email: "ana@example.com"}, {Name: "Doe, Jo", Email: "jo@example.com"}, {Name: "Zoë", Email: "zoe@example.com"}}
+	got, err := ParseAttendees(FormatAttendees(attendees))
+	if err != nil || !reflect.DeepEqual(got, attendees) {
+		t.Errorf("ParseAttendees(FormatAttendees()) = %+v, %v; want %+v", got, err, attendees)
+	}
+}
+
+// unfold joins an iCalendar's continuation lines, checking every line is CRLF ended
+// and at most 75 octets
+func unfold(t *testing.T, ics []byte) []string {
+	t.Helper()
+	s := string(ics)
+	if !strings.HasSuffix(s, "\r\n") {
+		t.Fatalf("the calendar doesn't end with CRLF: %q", s)
+	}
+	var lines []string
+	for _, raw := range strings.Split(strings.TrimSuffix(s, "\r\n"), "\r\n") {
+		if len(raw) > 75 || strings.Contains(raw, "\n") || !utf8.ValidString(raw) {
+			t.Errorf("bad content line %q", raw)
+		}
+		if strings.HasPrefix(raw, " ") && len(lines) > 0 {
+			lines[len(lines)-1] += raw[1:]
+			continue
+		}
+		lines = append(lines, raw)
+	}
+	return lines
+}
+
+func TestInvitation(t *testing.T) {
+	event := Event{
+		ID:        "abc123",
+		Title:     "Plan; budget, Q3",
+		StartTime: time.Date(2025, 6, 10, 15, 0, 0, 0, time.FixedZone("CEST", 2*3600)),
+		EndTime:   time.Date(2025, 6, 10, 16, 30, 0, 0, time.FixedZone("CEST", 2*3600)),
+		Location:  "Room 4",
+		Notes:     "Bring numbers\nand " + strings.Repeat("ideas ✨ ", 20),
+		Attendees: []Attendee{{Name: `Jo "JD" Doe`, Email: "jo@example.com"}, {Email: "ana@example.com"}},
+	}
+	lines := unfold(t, Invitation(event, "me@example.com"))
+	for _, want := range []string{
+		"METHOD:REQUEST",
+		"UID:abc123@maily",
+		"DTSTART:20250610T130000Z",
+		"DTEND:20250610T143000Z",
+		`SUMMARY:Plan\; budget\, Q3`,
+		"LOCATION:Room 4",
+		`DESCRIPTION:Bring numbers\nand ideas ✨ `,
+		"ORGANIZER:mailto:me@example.com",
+		`ATTENDEE;CN="Jo JD Doe";ROLE=REQ-PARTICIPANT;PARTSTAT=NEEDS-ACTION;RSVP=TRUE:mailto:jo@example.com`,
+		"ATTENDEE;ROLE=REQ-PARTICIPANT;PARTSTAT=NEEDS-ACTION;RSVP=TRUE:mailto:ana@example.com",
+	} {
+		found := false
+		for _, l := range lines {
+			if strings.HasPrefix(l, want) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			t.Errorf("invitation lacks %q:\n%s", want, strings.Join(lines, "\n"))
+		}
+	}
+	if lines[0] != "BEGIN:VCALENDAR" || lines[len(lines)-1] != "END:VCALENDAR" {
+		t.Errorf("invitation isn't one VCALENDAR: first %q, last %q", lines[0], lines[len(lines)-1])
+	}
+}
+
+func TestInvitationAllDay(t *testing.T) {
+	event := Event{
+		ID:        "day",
+		Title:     "Offsite",
+		StartTime: time.Date(2025, 6, 10, 0, 0, 0, 0, time.UTC),
+		EndTime:   time.Date(2025, 6, 11, 23, 59, 59, 0, time.UTC),
+		AllDay:    true,
+	}
+	ics := strings.Join(unfold(t, Invitation(event, "me@example.com")), "\n")
+	// DTEND of an all-day event is the day after the last one
+	for _, want := range []string{"DTSTART;VALUE=DATE:20250610", "DTEND;VALUE=DATE:20250612"} {
+		if !strings.Contains(ics, want) {
+			t.Errorf("all-day invitation lacks %q:\n%s", want, ics)
+		}
+	}
+}
+
+func TestFoldLine(t *testing.T) {
+	long := "DESCRIPTION:" + strings.Repeat("日本語", 30)
+	folded := foldLine(long)
+	for _, part := range strings.Split(folded, "\r\n") {
+		if len(part) > 75 || !utf8.ValidString(part) {
+			t.Errorf("folded part %q is too long or cuts a character", part)
+		}
+	}
+	if got := strings.ReplaceAll(folded, "\r\n ", ""); got != long {
+		t.Errorf("unfolded = %q, want %q", got, long)
+	}
+	if got := foldLine("SUMMARY:short"); got != "SUMMARY:short" {
+		t.Errorf("foldLine(short) = %q", got)
+	}
+}
@@ -0,0 +1,34 @@
+package calendar
+
+import (
+	"sort"
+	"strings"
+)
+
+// Search returns the events whose title, location or notes contain every word of
+// query, ignoring case, soonest first
+func Search(events []Event, query string) []Event {
+	words := strings.Fields(strings.ToLower(query))
+	if len(words) == 0 {
+		return nil
+	}
+
+	var matches []Event
+	for _, e := range events {
+		text := strings.ToLower(e.Title + "\n" + e.Location + "\n" + e.Notes)
+		found := true
+		for _, w := range words {
+			if !strings.Contains(text, w) {
+				found = false
+				break
+			}
+		}
+		if found {
+			matches = append(matches, e)
+		}
+	}
+	sort.SliceStable(matches, func(i, j int) bool {
+		return matches[i].StartTime.Before(matches[j].StartTime)
+	})
+	return matches
+}
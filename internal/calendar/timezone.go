@@ -0,0 +1,186 @@
+package calendar
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+	_ "time/tzdata" // zones load on systems without the database installed
+)
+
+// zoneDirs are where the IANA time zone database is usually installed; $ZONEINFO
+// comes first when set
+var zoneDirs = []string{
+	"/usr/share/zoneinfo",
+	"/var/db/timezone/zoneinfo",
+	"/usr/lib/zoneinfo",
+	"/usr/share/lib/zoneinfo",
+}
+
+// zoneRegions are the top-level areas of the database's canonical names; the rest,
+// such as US/Pacific or Brazil/East, are old aliases
+var zoneRegions = []string{
+	"Africa", "America", "Antarctica", "Arctic", "Asia", "Atlantic",
+	"Australia", "Europe", "Indian", "Pacific",
+}
+
+// commonZones stand in for the database on systems without one installed
+var commonZones = []string{
+	"America/Anchorage", "America/Chicago", "America/Denver", "America/Los_Angeles",
+	"America/Mexico_City", "America/New_York", "America/Sao_Paulo", "America/Toronto",
+	"Asia/Dubai", "Asia/Hong_Kong", "Asia/Kolkata", "Asia/Seoul", "Asia/Shanghai",
+	"Asia/Singapore", "Asia/Tokyo", "Australia/Sydney", "Europe/Berlin", "Europe/London",
+	"Europe/Madrid", "Europe/Moscow", "Europe/Paris", "Pacific/Auckland",
+	"Pacific/Honolulu", "UTC",
+}
+
+var (
+	zonesOnce sync.Once
+	zones     []string
+)
+
+// TimeZones returns the canonical names in the IANA time zone database, such as
+// "Europe/Berlin", sorted
+func TimeZones() []string {
+	zonesOnce.Do(func() {
+		dirs := zoneDirs
+		if dir := os.Getenv("ZONEINFO"); dir != "" {
+			dirs = append([]string{dir}, dirs...)
+		}
+		for _, dir := range dirs {
+			if zones = readZones(dir); len(zones) > 0 {
+				return
+			}
+		}
+		zones = commonZones
+	})
+	return zones
+}
+
+func readZones(dir string) []string {
+	var names []string
+	for _, region := range zoneRegions {
+		filepath.WalkDir(filepath.Join(dir, region), func(path string, d fs.DirEntry, err error) error {
+			if err != nil || d.IsDir() || !isZoneFile(path) {
+				return nil
+			}
+			if name, err := filepath.Rel(dir, path); err == nil {
+				names = append(names, filepath.ToSlash(name))
+			}
+			return nil
+		})
+	}
+	if len(names) == 0 {
+		return nil
+	}
+	names = append(names, "UTC")
+	sort.Strings(names)
+	return names
+}
+
+// isZoneFile reports whether path is a compiled zone, skipping tables like zone.tab
+func isZoneFile(path string) bool {
+	f, err := os.Open(path)
+	if err != nil {
+		return false
+	}
+	defer f.Close()
+	magic := make([]byte, 4)
+	_, err = f.Read(magic)
+	return err == nil && string(magic) == "TZif"
+}
+
+// LocalZone returns the IANA name of the local time zone, "" when it can't be told
+func LocalZone() string {
+	if tz := strings.TrimPrefix(os.Getenv("TZ"), ":"); tz != "" {
+		if _, err := time.LoadLocation(tz); err == nil {
+			return tz
+		}
+	}
+	target, err := os.Readlink("/etc/localtime")
+	if err != nil {
+		return ""
+	}
+	if i := strings.Index(target, "zoneinfo/"); i >= 0 {
+		return strings.TrimPrefix(target[i+len("zoneinfo/"):], "Etc/")
+	}
+	return ""
+}
+
+// Location returns the named time zone, the local one for "". A zone that can't be
+// loaded is an error, with the local zone to fall back on.
+func Location(name string) (*time.Location, error) {
+	if name == "" {
+		return time.Local, nil
+	}
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		return time.Local, fmt.Errorf("unknown time zone %q: %w", name, err)
+	}
+	return loc, nil
+}
+
+// Elsewhere reports whether the event was created in a time zone whose clock differs
+// from the local one at the time of the event, or in one that can't be loaded
+func (e Event) Elsewhere() bool {
+	if e.TimeZone == "" || e.AllDay {
+		return false
+	}
+	loc, err := Location(e.TimeZone)
+	if err != nil {
+		return true
+	}
+	_, there := e.StartTime.In(loc).Zone()
+	_, here := e.StartTime.In(time.Local).Zone()
+	return there != here
+}
+
+// StartsOn reports whether the event starts on day, in the local time zone. Days are
+// midnight to midnight, which is 23 or 25 hours long on the days clocks change.
+func (e Event) StartsOn(day time.Time) bool {
+	start, end := localDay(day)
+	t := e.StartTime.In(time.Local)
+	return !t.Before(start) && t.Before(end)
+}
+
+// On reports whether any part of the event falls on day, in the local time zone, so
+// that multi-day events are on each day they span; one ending at midnight doesn't
+// reach into the next day
+func (e Event) On(day time.Time) bool {
+	if !e.EndTime.After(e.StartTime) {
+		return e.StartsOn(day)
+	}
+	start, end := localDay(day)
+	return e.StartTime.Before(end) && e.EndTime.After(start)
+}
+
+// DayOf returns which of the event's days day is, counting from 1, and how many days
+// the event spans
+func (e Event) DayOf(day time.Time) (n, days int) {
+	first, _ := localDay(e.StartTime)
+	last := first
+	if e.EndTime.After(e.StartTime) {
+		last, _ = localDay(e.EndTime.Add(-time.Nanosecond))
+	}
+	today, _ := localDay(day)
+	return daysBetween(first, today) + 1, daysBetween(first, last) + 1
+}
+
+// localDay returns the local midnights that begin and end t's day
+func localDay(t time.Time) (start, end time.Time) {
+	t = t.In(time.Local)
+	start = time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.Local)
+	return start, start.AddDate(0, 0, 1)
+}
+
+// daysBetween counts the calendar days from a to b, whatever the hours between them
+func daysBetween(a, b time.Time) int {
+	ay, am, ad := a.Date()
+	by, bm, bd := b.Date()
+	days := time.Date(by, bm, bd, 0, 0, 0, 0, time.UTC).Sub(time.Date(ay, am, ad, 0, 0, 0, 0, time.UTC))
+	return int(days.Hours() / 24)
+}
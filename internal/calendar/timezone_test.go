@@ -0,0 +1,40 @@
+package calendar
+
+import (
+	"testing"
+	"time"
+)
+
+func TestLocation(t *testing.T) {
+	if loc, err := Location(""); err != nil || loc != time.Local {
+		t.Errorf(`Location("") = %v, %v; want the local zone`, loc, err)
+	}
+	if loc, err := Location("Asia/Kolkata"); err != nil || loc.String() != "Asia/Kolkata" {
+		t.Errorf(`Location("Asia/Kolkata") = %v, %v`, loc, err)
+	}
+	loc, err := Location("Mars/Olympus_Mons")
+	if err == nil {
+		t.Error("Location() of an unknown zone didn't fail")
+	}
+	if loc != time.Local {
+		t.Errorf("Location() of an unknown zone = %v, want the local zone to fall back on", loc)
+	}
+}
+
+func TestElsewhere(t *testing.T) {
+	start := time.Date(2025, 6, 2, 9, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name  string
+		event Event
+		want  bool
+	}{
+		{"no zone", Event{StartTime: start}, false},
+		{"all day", Event{StartTime: start, TimeZone: "Asia/Kolkata", AllDay: true}, false},
+		{"unknown zone", Event{StartTime: start, TimeZone: "Mars/Olympus_Mons"}, true},
+	}
+	for _, tt := range tests {
+		if got := tt.event.Elsewhere(); got != tt.want {
+			t.Errorf("%s: Elsewhere() = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
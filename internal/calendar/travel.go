@@ -0,0 +1,21 @@
+package calendar
+
+import "strings"
+
+// onlineHints are found in the locations of meetings held online
+var onlineHints = []string{
+	"://", "zoom.us", "meet.google", "teams.microsoft", "webex", "whereby.com",
+	"online", "virtual", "video call",
+}
+
+// Online reports whether a location is a meeting link or an online meeting, which
+// there's no traveling to
+func Online(location string) bool {
+	location = strings.ToLower(location)
+	for _, hint := range onlineHints {
+		if strings.Contains(location, hint) {
+			return true
+		}
+	}
+	return false
+}
@@ -3,27 +3,126 @@ package cli
 import (
 	"fmt"
 	"os"
+	"regexp"
 
 	"github.com/spf13/cobra"
+	"maily/config"
 	"maily/internal/auth"
+	"maily/internal/client"
 	"maily/internal/i18n"
+	"maily/internal/mail"
+	"maily/internal/server"
+	"maily/internal/ui/components"
 )
 
+var (
+	accountName          string
+	accountColor         string
+	accountMaxAttachment int
+	accountNotmuchDB     string
+	accountCompress      bool
+	accountProxy         string
+)
+
+// colorPattern accepts hex colors (#RRGGBB) and ANSI 256 color numbers
+var colorPattern = regexp.MustCompile(`^(#[0-9a-fA-F]{6}|[0-9]{1,3})$`)
+
 var accountsCmd = &cobra.Command{
 	Use:   "accounts",
 	Short: "List all accounts",
 	Run: func(cmd *cobra.Command, args []string) {
+		initLanguage()
 		handleAccounts()
 	},
 }
 
+var accountsSetCmd = &cobra.Command{
+	Use:   "set <email>",
+	Short: "Set an account's display name, accent color, attachment limit, notmuch database, compression and proxy",
+	Example: `  maily accounts set me@gmail.com --name Personal --color "#10B981"
+  maily accounts set work@corp.com --name Work --color 208
+  maily accounts set me@gmail.com --color ""   # back to the default color
+  maily accounts set work@corp.com --max-attachment-mb 10   # server rejects larger mail
+  maily accounts set work@corp.com --max-attachment-mb 0    # back to the provider limit
+  maily accounts set me@example.com --notmuch-db ~/Mail      # search and tag a Maildir account with notmuch
+  maily accounts set me@gmail.com --compress                 # deflate IMAP traffic on slow links
+  maily accounts set me@gmail.com --compress=false
+  maily accounts set me@work.com --proxy socks5://localhost:1080
+  maily accounts set me@work.com --proxy direct                # ignore ALL_PROXY for this account`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		handleAccountsSet(cmd, args[0])
+	},
+}
+
+var accountsPinsCmd = &cobra.Command{
+	Use:   "pins <email>",
+	Short: "Show the key pins of an account's IMAP server certificates",
+	Long: `Connect to the account's IMAP server and print the SHA-256 pins of the certificates it
+presents, server certificate first. Copy one into the account's tls settings in
+accounts.yml to refuse any other certificate:
+
+  tls:
+    pin_sha256: ["sha256/..."]`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		handleAccountsPins(args[0])
+	},
+}
+
+func init() {
+	accountsSetCmd.Flags().StringVar(&accountName, "name", "", "Display name shown in the TUI")
+	accountsSetCmd.Flags().StringVar(&accountColor, "color", "", "Accent color (#RRGGBB or ANSI 0-255)")
+	accountsSetCmd.Flags().IntVar(&accountMaxAttachment, "max-attachment-mb", 0, "Total attachment size limit in MB (0 uses the provider's limit)")
+	accountsSetCmd.Flags().BoolVar(&accountCompress, "compress", false, "Compress IMAP traffic (COMPRESS=DEFLATE) when the server supports it")
+	accountsSetCmd.Flags().StringVar(&accountNotmuchDB, "notmuch-db", "", "notmuch database to search and tag with (Maildir accounts only, \"\" to stop)")
+	accountsSetCmd.Flags().StringVar(&accountProxy, "proxy", "", "socks5:// or http:// proxy for IMAP and SMTP, \"direct\" to ignore ALL_PROXY, \"\" to use ALL_PROXY")
+	accountsCmd.AddCommand(accountsSetCmd)
+	accountsCmd.AddCommand(accountsPinsCmd)
+}
+
+// accountJSON is the --json representation of an account (credentials omitted)
+type accountJSON struct {
+	Email    string `json:"email"`
+	Name     string `json:"name"`
+	Provider string `json:"provider"`
+	Color    string `json:"color,omitempty"`
+	Active   bool   `json:"active"` // in the active profiles
+
+	MaxAttachmentMB int64 `json:"max_attachment_mb"` // effective attachment size limit
+}
+
 func handleAccounts() {
 	store, err := auth.LoadAccountStore()
 	if err != nil {
+		if jsonOutput {
+			fail("%v", err)
+		}
 		fmt.Printf("%s: %v\n", i18n.T("common.error"), err)
 		os.Exit(1)
 	}
 
+	if jsonOutput {
+		cfg, _ := config.Load()
+		accounts := make([]accountJSON, 0, len(store.Accounts))
+		for _, acc := range store.Accounts {
+			accounts = append(accounts, accountJSON{
+				Email:    acc.Credentials.Email,
+				Name:     acc.DisplayName(),
+				Provider: acc.Provider,
+				Color:    acc.Color,
+				Active:   cfg.AccountActive(acc.Credentials.Email),
+
+				MaxAttachmentMB: mail.AttachmentLimit(&acc) / (1024 * 1024),
+			})
+		}
+		printJSON(accounts)
+		if len(accounts) == 0 {
+			os.Exit(exitNoResults)
+		}
+		return
+	}
+
 	if len(store.Accounts) == 0 {
 		fmt.Println(i18n.T("cli.no_accounts"))
 		fmt.Println(i18n.T("cli.login_hint"))
@@ -33,8 +132,136 @@ func handleAccounts() {
 	fmt.Println()
 	fmt.Println("  " + i18n.T("cli.available_providers"))
 	fmt.Println()
-	for _, acc := range store.Accounts {
-		fmt.Printf("  %s (%s)\n", acc.Credentials.Email, acc.Provider)
+	for i, acc := range store.Accounts {
+		badge := components.RenderAccountBadge(acc.DisplayName(), components.AccountColor(acc.Color, i))
+		if acc.DisplayName() == acc.Credentials.Email {
+			fmt.Printf("  %s (%s)\n", badge, acc.Provider)
+		} else {
+			fmt.Printf("  %s %s (%s)\n", badge, acc.Credentials.Email, acc.Provider)
+		}
 	}
 	fmt.Println()
 }
+
+func handleAccountsSet(cmd *cobra.Command, email string) {
+	changed := false
+	for _, flag := range []string{"name", "color", "max-attachment-mb", "notmuch-db", "compress", "proxy"} {
+		changed = changed || cmd.Flags().Changed(flag)
+	}
+	if !changed {
+		fmt.Println("Nothing to change: pass --name, --color, --max-attachment-mb, --notmuch-db, --compress and/or --proxy")
+		os.Exit(1)
+	}
+	if accountMaxAttachment < 0 {
+		fmt.Println("Invalid --max-attachment-mb: must be 0 or more")
+		os.Exit(1)
+	}
+	if accountProxy != "" {
+		if err := mail.ValidateProxy(accountProxy); err != nil {
+			fmt.Printf("Invalid --proxy: %v\n", err)
+			os.Exit(1)
+		}
+	}
+	if accountColor != "" && !colorPattern.MatchString(accountColor) {
+		fmt.Printf("Invalid color '%s': use #RRGGBB or an ANSI color number (0-255)\n", accountColor)
+		os.Exit(1)
+	}
+
+	store, err := auth.LoadAccountStore()
+	if err != nil {
+		fmt.Printf("%s: %v\n", i18n.T("common.error"), err)
+		os.Exit(1)
+	}
+
+	idx := -1
+	for i, acc := range store.Accounts {
+		if acc.Credentials.Email == email {
+			idx = i
+			break
+		}
+	}
+	if idx < 0 {
+		fmt.Printf("%s\n", i18n.T("cli.account_not_found", map[string]any{"Email": email}))
+		os.Exit(1)
+	}
+
+	acc := &store.Accounts[idx]
+	if cmd.Flags().Changed("name") {
+		acc.Name = accountName
+		if acc.Name == "" {
+			acc.Name = email
+		}
+	}
+	if cmd.Flags().Changed("color") {
+		acc.Color = accountColor
+	}
+	if cmd.Flags().Changed("max-attachment-mb") {
+		acc.MaxAttachmentMB = accountMaxAttachment
+	}
+	if cmd.Flags().Changed("compress") {
+		if acc.Credentials.Provider == auth.ProviderMaildir {
+			fmt.Println("--compress applies to IMAP accounts; a Maildir account has no connection")
+			os.Exit(1)
+		}
+		acc.Credentials.Compress = accountCompress
+	}
+	if cmd.Flags().Changed("proxy") {
+		if acc.Credentials.Provider == auth.ProviderMaildir && acc.Credentials.SMTPHost == "" {
+			fmt.Println("--proxy applies to IMAP and SMTP; this Maildir account sends with sendmail")
+			os.Exit(1)
+		}
+		acc.Credentials.Proxy = accountProxy
+	}
+	if cmd.Flags().Changed("notmuch-db") {
+		if accountNotmuchDB != "" && acc.Credentials.Provider != auth.ProviderMaildir {
+			fmt.Println("notmuch only indexes local mail: --notmuch-db needs a Maildir account")
+			os.Exit(1)
+		}
+		acc.Credentials.NotmuchDB = ""
+		if accountNotmuchDB != "" {
+			acc.Credentials.NotmuchDB = absPath(accountNotmuchDB)
+		}
+	}
+
+	if err := store.Save(); err != nil {
+		fmt.Printf("%s: %v\n", i18n.T("common.error"), err)
+		os.Exit(1)
+	}
+
+	// Connection settings apply from the server's next login
+	if (cmd.Flags().Changed("compress") || cmd.Flags().Changed("proxy") || cmd.Flags().Changed("notmuch-db")) && server.IsServerRunning() {
+		if c, err := client.Connect(); err == nil {
+			c.ReloadAccounts()
+			c.Close()
+		}
+	}
+
+	badge := components.RenderAccountBadge(acc.DisplayName(), components.AccountColor(acc.Color, idx))
+	fmt.Printf("Updated %s %s\n", email, badge)
+}
+
+func handleAccountsPins(email string) {
+	store, err := auth.LoadAccountStore()
+	if err != nil {
+		fail("%v", err)
+	}
+	acc := store.GetAccount(email)
+	if acc == nil {
+		fail("%s", i18n.T("cli.account_not_found", map[string]any{"Email": email}))
+	}
+	if acc.Credentials.Provider == auth.ProviderMaildir {
+		fail("%s is a Maildir account, with no server to pin", email)
+	}
+
+	pins, err := mail.ServerPins(&acc.Credentials)
+	if err != nil {
+		fail("%v", err)
+	}
+	if jsonOutput {
+		printJSON(pins)
+		return
+	}
+	for _, pin := range pins {
+		fmt.Println(pin)
+	}
+}
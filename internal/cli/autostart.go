@@ -0,0 +1,128 @@
+package cli
+
+import (
+	"encoding/xml"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+)
+
+// The server can start at login, so mail syncs and notifications arrive without
+// opening maily first: a launchd agent on macOS, a systemd user unit on Linux.
+
+const (
+	launchdLabel = "com.maily.server"
+	systemdUnit  = "maily.service"
+)
+
+// autostartPath returns where the login item for the server lives
+func autostartPath() (string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	switch runtime.GOOS {
+	case "darwin":
+		return filepath.Join(home, "Library", "LaunchAgents", launchdLabel+".plist"), nil
+	case "linux":
+		configDir := os.Getenv("XDG_CONFIG_HOME")
+		if configDir == "" {
+			configDir = filepath.Join(home, ".config")
+		}
+		return filepath.Join(configDir, "systemd", "user", systemdUnit), nil
+	}
+	return "", fmt.Errorf("starting the server at login isn't supported on %s", runtime.GOOS)
+}
+
+// enableAutostart installs a login item running 'maily server start' and returns its path
+func enableAutostart() (string, error) {
+	path, err := autostartPath()
+	if err != nil {
+		return "", err
+	}
+	executable, err := os.Executable()
+	if err != nil {
+		return "", err
+	}
+	if resolved, err := filepath.EvalSymlinks(executable); err == nil {
+		executable = resolved
+	}
+
+	var content string
+	if runtime.GOOS == "darwin" {
+		content = launchdPlist(executable)
+	} else {
+		content = systemdService(executable)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return "", err
+	}
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		return "", err
+	}
+
+	// launchd loads agents from LaunchAgents at login by itself
+	if runtime.GOOS == "linux" {
+		if out, err := exec.Command("systemctl", "--user", "enable", systemdUnit).CombinedOutput(); err != nil {
+			return path, fmt.Errorf("systemctl --user enable %s: %v: %s", systemdUnit, err, strings.TrimSpace(string(out)))
+		}
+	}
+	return path, nil
+}
+
+// disableAutostart removes the login item, returning its path
+func disableAutostart() (string, error) {
+	path, err := autostartPath()
+	if err != nil {
+		return "", err
+	}
+	if _, err := os.Stat(path); os.IsNotExist(err) {
+		return path, nil
+	}
+	if runtime.GOOS == "linux" {
+		// Best effort: the unit file is removed either way
+		exec.Command("systemctl", "--user", "disable", systemdUnit).Run()
+	}
+	if err := os.Remove(path); err != nil {
+		return "", err
+	}
+	return path, nil
+}
+
+func launchdPlist(executable string) string {
+	var escaped strings.Builder
+	xml.EscapeText(&escaped, []byte(executable))
+	return fmt.Sprintf(`<?xml version="1.0" encoding="UTF-8"?>
+<!DOCTYPE plist PUBLIC "-//Apple//DTD PLIST 1.0//EN" "http://www.apple.com/DTDs/PropertyList-1.0.dtd">
+<plist version="1.0">
+<dict>
+	<key>Label</key>
+	<string>%s</string>
+	<key>ProgramArguments</key>
+	<array>
+		<string>%s</string>
+		<string>server</string>
+		<string>start</string>
+	</array>
+	<key>RunAtLoad</key>
+	<true/>
+</dict>
+</plist>
+`, launchdLabel, escaped.String())
+}
+
+func systemdService(executable string) string {
+	return fmt.Sprintf(`[Unit]
+Description=maily mail sync server
+
+[Service]
+ExecStart=%q server start
+Restart=on-failure
+
+[Install]
+WantedBy=default.target
+`, executable)
+}
@@ -0,0 +1,238 @@
+package cli
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/spf13/cobra"
+	"golang.org/x/term"
+	"maily/config"
+	"maily/internal/backup"
+	"maily/internal/cache"
+	"maily/internal/server"
+)
+
+const (
+	backupAccountsFile = "accounts.yml"
+	backupConfigFile   = "config.yml"
+	backupCacheFile    = "maily.db"
+)
+
+// passphraseEnv lets scripts supply the backup passphrase without a terminal
+const passphraseEnv = "MAILY_BACKUP_PASSPHRASE"
+
+var (
+	backupOutput       string
+	backupIncludeCache bool
+	restoreForce       bool
+)
+
+var backupCmd = &cobra.Command{
+	Use:   "backup",
+	Short: "Create an encrypted backup of accounts and settings",
+	Long: `Create a passphrase-encrypted archive of your accounts, config, and optionally the email cache.
+
+The archive is encrypted with AES-256-GCM using a key derived from your passphrase,
+so stored passwords and API keys never leave this machine in plaintext.`,
+	Example: `  maily backup
+  maily backup -o ~/maily.mbak --include-cache`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if err := handleBackup(); err != nil {
+			fmt.Printf("Error: %v\n", err)
+			os.Exit(1)
+		}
+	},
+}
+
+var restoreCmd = &cobra.Command{
+	Use:   "restore <file>",
+	Short: "Restore accounts and settings from a backup",
+	Long:  "Decrypt a backup created by 'maily backup' and restore it into ~/.config/maily.",
+	Example: `  maily restore maily-backup-20250101.mbak
+  maily restore ~/maily.mbak --force`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		if err := handleRestore(args[0]); err != nil {
+			fmt.Printf("Error: %v\n", err)
+			os.Exit(1)
+		}
+	},
+}
+
+func init() {
+	backupCmd.Flags().StringVarP(&backupOutput, "output", "o", "", "Output file (default: maily-backup-<date>.mbak)")
+	backupCmd.Flags().BoolVar(&backupIncludeCache, "include-cache", false, "Include the email cache database")
+	restoreCmd.Flags().BoolVar(&restoreForce, "force", false, "Overwrite existing accounts and settings")
+	rootCmd.AddCommand(backupCmd)
+	rootCmd.AddCommand(restoreCmd)
+}
+
+func handleBackup() error {
+	configDir, err := config.GetConfigDir()
+	if err != nil {
+		return err
+	}
+
+	var files []backup.File
+	for _, name := range []string{backupAccountsFile, backupConfigFile} {
+		data, err := os.ReadFile(filepath.Join(configDir, name))
+		if os.IsNotExist(err) {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		files = append(files, backup.File{Name: name, Data: data})
+	}
+	if len(files) == 0 {
+		return fmt.Errorf("nothing to back up in %s", configDir)
+	}
+
+	if backupIncludeCache {
+		data, err := snapshotCache()
+		if err != nil {
+			return fmt.Errorf("failed to snapshot cache: %w", err)
+		}
+		files = append(files, backup.File{Name: backupCacheFile, Data: data})
+	}
+
+	passphrase, err := readPassphrase(true)
+	if err != nil {
+		return err
+	}
+
+	output := backupOutput
+	if output == "" {
+		output = fmt.Sprintf("maily-backup-%s.mbak", time.Now().Format("20060102"))
+	}
+
+	var buf bytes.Buffer
+	if err := backup.Write(&buf, files, passphrase); err != nil {
+		return err
+	}
+	if err := os.WriteFile(output, buf.Bytes(), 0600); err != nil {
+		return err
+	}
+
+	fmt.Printf("Backup written to %s (%d files)\n", output, len(files))
+	return nil
+}
+
+// snapshotCache copies the SQLite cache without stopping the server
+func snapshotCache() ([]byte, error) {
+	c, err := cache.New()
+	if err != nil {
+		return nil, err
+	}
+	defer c.Close()
+
+	tmpDir, err := os.MkdirTemp("", "maily-backup-")
+	if err != nil {
+		return nil, err
+	}
+	defer os.RemoveAll(tmpDir)
+
+	path := filepath.Join(tmpDir, backupCacheFile)
+	if err := c.Snapshot(path); err != nil {
+		return nil, err
+	}
+	return os.ReadFile(path)
+}
+
+func handleRestore(path string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	passphrase, err := readPassphrase(false)
+	if err != nil {
+		return err
+	}
+
+	files, err := backup.Read(f, passphrase)
+	if err != nil {
+		return err
+	}
+
+	configDir, err := config.GetConfigDir()
+	if err != nil {
+		return err
+	}
+
+	hasCache := false
+	for _, file := range files {
+		switch file.Name {
+		case backupAccountsFile, backupConfigFile:
+		case backupCacheFile:
+			hasCache = true
+		default:
+			return fmt.Errorf("unexpected file in backup: %s", file.Name)
+		}
+		if !restoreForce {
+			if _, err := os.Stat(filepath.Join(configDir, file.Name)); err == nil {
+				return fmt.Errorf("%s already exists (use --force to overwrite)", file.Name)
+			}
+		}
+	}
+
+	if hasCache && server.IsServerRunning() {
+		return fmt.Errorf("server is running; stop it first with 'maily server stop'")
+	}
+
+	if err := os.MkdirAll(configDir, 0700); err != nil {
+		return err
+	}
+
+	for _, file := range files {
+		target := filepath.Join(configDir, file.Name)
+		if file.Name == backupCacheFile {
+			// Stale WAL files would be replayed on top of the restored database
+			os.Remove(target + "-wal")
+			os.Remove(target + "-shm")
+		}
+		if err := os.WriteFile(target, file.Data, 0600); err != nil {
+			return err
+		}
+		fmt.Printf("Restored %s\n", file.Name)
+	}
+	return nil
+}
+
+// readPassphrase reads the backup passphrase from the environment or the terminal
+func readPassphrase(confirm bool) (string, error) {
+	if p := os.Getenv(passphraseEnv); p != "" {
+		return p, nil
+	}
+	if !term.IsTerminal(int(os.Stdin.Fd())) {
+		return "", fmt.Errorf("no terminal for passphrase prompt; set %s", passphraseEnv)
+	}
+
+	fmt.Print("Passphrase: ")
+	first, err := term.ReadPassword(int(os.Stdin.Fd()))
+	fmt.Println()
+	if err != nil {
+		return "", err
+	}
+	if len(first) == 0 {
+		return "", fmt.Errorf("passphrase cannot be empty")
+	}
+	if !confirm {
+		return string(first), nil
+	}
+
+	fmt.Print("Confirm passphrase: ")
+	second, err := term.ReadPassword(int(os.Stdin.Fd()))
+	fmt.Println()
+	if err != nil {
+		return "", err
+	}
+	if string(first) != string(second) {
+		return "", fmt.Errorf("passphrases do not match")
+	}
+	return string(first), nil
+}
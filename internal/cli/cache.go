@@ -0,0 +1,327 @@
+package cli
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/spf13/cobra"
+	"maily/config"
+	"maily/internal/auth"
+	"maily/internal/cache"
+	"maily/internal/server"
+)
+
+var (
+	cacheAccount      string
+	cachePruneAgeDays int
+	cachePruneMaxSize int
+	cachePruneVacuum  bool
+)
+
+var cacheCmd = &cobra.Command{
+	Use:   "cache",
+	Short: "Inspect and shrink the local email cache",
+	Long: `Inspect and shrink the local email cache (~/.config/maily/maily.db).
+
+Limits for cached email bodies can be set in config.yml; the server applies them after
+each sync. Pruned emails keep their metadata and their body is fetched again when opened.
+
+  cache:
+    max_size_mb: 200      # per account and folder
+    max_age_days: 90
+    folders:
+      "[Gmail]/All Mail":
+        max_age_days: 30`,
+}
+
+var cacheStatsCmd = &cobra.Command{
+	Use:   "stats",
+	Short: "Show cache size per account and folder",
+	Example: `  maily cache stats
+  maily cache stats --json`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		handleCacheStats()
+	},
+}
+
+var cachePruneCmd = &cobra.Command{
+	Use:   "prune",
+	Short: "Drop cached bodies beyond the configured limits, keeping metadata",
+	Example: `  maily cache prune
+  maily cache prune --older-than-days 30 --vacuum
+  maily cache prune -a me@gmail.com --max-size-mb 100`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		handleCachePrune(cmd)
+	},
+}
+
+var cacheVacuumCmd = &cobra.Command{
+	Use:   "vacuum",
+	Short: "Rebuild the cache database to reclaim free space",
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		handleCacheVacuum()
+	},
+}
+
+var cacheEncryptCmd = &cobra.Command{
+	Use:   "encrypt",
+	Short: "Encrypt cached email bodies at rest",
+	Long: `Encrypt cached email bodies and snippets with AES-256-GCM.
+
+The key is generated once and stored in the OS keychain (macOS Keychain, or the Secret
+Service via secret-tool on Linux). Where no keychain is available it is written to
+~/.config/maily/cache.key, readable only by you. Emails cached afterwards are encrypted
+as they are saved. Headers such as sender and subject stay searchable in plaintext.
+
+A backup made with --include-cache can only be read where the key is available.`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		handleCacheEncrypt(true)
+	},
+}
+
+var cacheDecryptCmd = &cobra.Command{
+	Use:   "decrypt",
+	Short: "Store cached email bodies unencrypted again",
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		handleCacheEncrypt(false)
+	},
+}
+
+func init() {
+	cachePruneCmd.Flags().StringVarP(&cacheAccount, "account", "a", "", "Only prune this account")
+	cachePruneCmd.Flags().IntVar(&cachePruneAgeDays, "older-than-days", 0, "Drop bodies of emails older than this (overrides config)")
+	cachePruneCmd.Flags().IntVar(&cachePruneMaxSize, "max-size-mb", 0, "Keep at most this many MB of bodies per folder (overrides config)")
+	cachePruneCmd.Flags().BoolVar(&cachePruneVacuum, "vacuum", false, "Vacuum the database afterwards")
+	cacheCmd.AddCommand(cacheStatsCmd)
+	cacheCmd.AddCommand(cachePruneCmd)
+	cacheCmd.AddCommand(cacheVacuumCmd)
+	cacheCmd.AddCommand(cacheEncryptCmd)
+	cacheCmd.AddCommand(cacheDecryptCmd)
+	rootCmd.AddCommand(cacheCmd)
+}
+
+// cacheStatsJSON is the --json representation of cache usage
+type cacheStatsJSON struct {
+	FileBytes int64                `json:"file_bytes"`
+	Encrypted bool                 `json:"encrypted"`
+	Mailboxes []cache.StorageStats `json:"mailboxes"`
+}
+
+func handleCacheStats() {
+	c, err := cache.New()
+	if err != nil {
+		fail("opening cache: %v", err)
+	}
+	defer c.Close()
+
+	stats, err := c.StorageStats()
+	if err != nil {
+		c.Close()
+		fail("reading cache: %v", err)
+	}
+
+	if jsonOutput {
+		if stats == nil {
+			stats = []cache.StorageStats{}
+		}
+		printJSON(cacheStatsJSON{FileBytes: c.FileSize(), Encrypted: c.Encrypted(), Mailboxes: stats})
+		return
+	}
+
+	encryption := "off"
+	if c.Encrypted() {
+		encryption = "on"
+	}
+	fmt.Printf("Cache database: %s (encryption %s)\n", formatBytes(c.FileSize()), encryption)
+	var accounts []string
+	byAccount := make(map[string][]cache.StorageStats)
+	for _, s := range stats {
+		if _, ok := byAccount[s.Account]; !ok {
+			accounts = append(accounts, s.Account)
+		}
+		byAccount[s.Account] = append(byAccount[s.Account], s)
+	}
+	for _, account := range accounts {
+		var emails, bodies int
+		var size int64
+		for _, s := range byAccount[account] {
+			emails += s.Emails
+			bodies += s.Bodies
+			size += s.BodyBytes
+		}
+		fmt.Printf("\n%s  %s in bodies (%d of %d emails)\n", account, formatBytes(size), bodies, emails)
+		for _, s := range byAccount[account] {
+			fmt.Printf("  %-30s  %10s  %6d bodies  %6d emails\n", truncate(s.Mailbox, 30), formatBytes(s.BodyBytes), s.Bodies, s.Emails)
+		}
+	}
+}
+
+func handleCachePrune(cmd *cobra.Command) {
+	cfg, err := config.Load()
+	if err != nil {
+		fail("loading config: %v", err)
+	}
+	overridden := cmd.Flags().Changed("older-than-days") || cmd.Flags().Changed("max-size-mb")
+	if cfg.Cache == nil && !overridden {
+		fail("no cache limits configured - add cache to config.yml or pass --older-than-days/--max-size-mb")
+	}
+
+	store, err := auth.LoadAccountStore()
+	if err != nil {
+		fail("%v", err)
+	}
+	accounts := store.Accounts
+	if cacheAccount != "" {
+		account, err := resolveAccount(store, cacheAccount)
+		if err != nil {
+			fail("%v", err)
+		}
+		accounts = []auth.Account{*account}
+	}
+
+	c, err := cache.New()
+	if err != nil {
+		fail("opening cache: %v", err)
+	}
+	defer c.Close()
+
+	now := time.Now()
+	bounds := func(mailbox string) (time.Time, int64) {
+		limit := cfg.Cache.Limit(mailbox)
+		if overridden {
+			limit = config.CacheLimit{MaxAgeDays: cachePruneAgeDays, MaxSizeMB: cachePruneMaxSize}
+		}
+		return limit.Bounds(now)
+	}
+
+	before := c.FileSize()
+	total := 0
+	for _, account := range accounts {
+		pruned, err := c.PruneAccount(account.Credentials.Email, bounds)
+		if err != nil {
+			c.Close()
+			fail("%s: %v", account.Credentials.Email, err)
+		}
+		if pruned > 0 && !jsonOutput {
+			fmt.Printf("%s: dropped %d bodies\n", account.Credentials.Email, pruned)
+		}
+		total += pruned
+	}
+
+	if cachePruneVacuum {
+		if err := c.Vacuum(); err != nil {
+			c.Close()
+			fail("vacuum: %v", err)
+		}
+	}
+
+	if jsonOutput {
+		printJSON(map[string]any{"pruned": total, "before_bytes": before, "after_bytes": c.FileSize()})
+		return
+	}
+	fmt.Printf("Dropped %d bodies", total)
+	if cachePruneVacuum {
+		fmt.Printf(", database %s -> %s", formatBytes(before), formatBytes(c.FileSize()))
+	} else if total > 0 {
+		fmt.Print(" (run 'maily cache vacuum' to shrink the file)")
+	}
+	fmt.Println()
+}
+
+func handleCacheVacuum() {
+	c, err := cache.New()
+	if err != nil {
+		fail("opening cache: %v", err)
+	}
+	defer c.Close()
+
+	before := c.FileSize()
+	if err := c.Vacuum(); err != nil {
+		c.Close()
+		fail("vacuum: %v (is a sync running? try again once it finishes)", err)
+	}
+	after := c.FileSize()
+
+	if jsonOutput {
+		printJSON(map[string]any{"before_bytes": before, "after_bytes": after})
+		return
+	}
+	fmt.Printf("Database %s -> %s\n", formatBytes(before), formatBytes(after))
+}
+
+// handleCacheEncrypt encrypts (or with encrypt false, decrypts) the cached bodies in place
+func handleCacheEncrypt(encrypt bool) {
+	// A running server keeps its own connection and would go on writing in the old format
+	if server.IsServerRunning() {
+		fail("server is running; stop it first with 'maily server stop'")
+	}
+
+	c, err := cache.New()
+	if err != nil {
+		fail("opening cache: %v", err)
+	}
+	defer c.Close()
+
+	if encrypt {
+		already := c.Encrypted()
+		n, where, err := c.Encrypt()
+		if err != nil {
+			c.Close()
+			fail("encrypting cache: %v", err)
+		}
+		// Rebuild the file so plaintext left in freed pages is not kept on disk
+		vacuumErr := c.Vacuum()
+		if jsonOutput {
+			printJSON(map[string]any{"encrypted": n, "key": where})
+			return
+		}
+		if already {
+			fmt.Printf("Cache is already encrypted; encrypted %d remaining emails\n", n)
+		} else {
+			fmt.Printf("Encrypted %d cached emails\n", n)
+		}
+		fmt.Printf("Key stored in %s\n", where)
+		if vacuumErr != nil {
+			fmt.Printf("Warning: vacuum failed (%v); run 'maily cache vacuum' to drop old plaintext pages\n", vacuumErr)
+		}
+		return
+	}
+
+	if !c.Encrypted() {
+		if jsonOutput {
+			printJSON(map[string]any{"decrypted": 0})
+			return
+		}
+		fmt.Println("Cache is not encrypted")
+		return
+	}
+	n, err := c.Decrypt()
+	if err != nil {
+		c.Close()
+		fail("decrypting cache: %v", err)
+	}
+	if jsonOutput {
+		printJSON(map[string]any{"decrypted": n})
+		return
+	}
+	fmt.Printf("Decrypted %d cached emails\n", n)
+}
+
+// formatBytes formats a byte count for display
+func formatBytes(size int64) string {
+	const unit = 1024
+	if size < unit {
+		return fmt.Sprintf("%d B", size)
+	}
+	div, exp := int64(unit), 0
+	for n := size / unit; n >= unit; n /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %cB", float64(size)/float64(div), "KMGTPE"[exp])
+}
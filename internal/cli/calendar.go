@@ -8,12 +8,16 @@ import (
 	"time"
 
 	tea "github.com/charmbracelet/bubbletea"
+	"github.com/mattn/go-runewidth"
 	"github.com/spf13/cobra"
 	"maily/config"
 	"maily/internal/ai"
+	"maily/internal/auth"
+	"maily/internal/cache"
 	"maily/internal/calendar"
 	"maily/internal/i18n"
 	"maily/internal/ui"
+	"maily/internal/ui/utils"
 )
 
 var calendarCmd = &cobra.Command{
@@ -26,7 +30,10 @@ var calendarCmd = &cobra.Command{
 	},
 }
 
-var calendarAddDebug bool
+var (
+	calendarAccount  string
+	calendarAddDebug bool
+)
 
 var calendarAddCmd = &cobra.Command{
 	Use:   "add [natural language description]",
@@ -62,6 +69,7 @@ var calendarListCmd = &cobra.Command{
 }
 
 func init() {
+	calendarCmd.Flags().StringVarP(&calendarAccount, "account", "a", "", "Account to send invitations from (default: the first account)")
 	calendarAddCmd.Flags().BoolVar(&calendarAddDebug, "debug", false, "Show raw AI response")
 	calendarCmd.AddCommand(calendarAddCmd)
 	calendarCmd.AddCommand(calendarListCmd)
@@ -90,40 +98,45 @@ func runCalendarList() {
 }
 
 func runCalendarAdd(input string) {
-	// Check AI availability
+	var parsed *ai.ParsedEvent
 	aiClient := ai.NewClient()
-	if !aiClient.Available() {
-		fmt.Println("Error: No AI CLI found.")
+	if aiClient.Available() {
+		fmt.Printf("Using %s to parse: %q\n", aiClient.Provider(), input)
 		fmt.Println()
-		fmt.Println("Install one of the following:")
-		fmt.Println("  - claude (Claude Code)")
-		fmt.Println("  - codex  (Codex CLI)")
-		fmt.Println("  - gemini (Gemini CLI)")
-		fmt.Println("  - ollama (Ollama)")
-		os.Exit(1)
-	}
-
-	fmt.Printf("Using %s to parse: %q\n", aiClient.Provider(), input)
-	fmt.Println()
-
-	// Parse natural language using AI
-	prompt := ai.ParseCalendarEventPrompt(input, time.Now())
-	response, err := aiClient.Call(prompt)
-	if err != nil {
-		fmt.Printf("Error: %v\n", err)
-		os.Exit(1)
-	}
-
-	// Debug: show raw AI response
-	if calendarAddDebug {
-		fmt.Printf("AI response:\n%s\n\n", response)
-	}
 
-	// Parse the JSON response
-	parsed, err := ai.ParseEventResponse(response)
-	if err != nil {
-		fmt.Printf("Error parsing AI response: %v\n", err)
-		os.Exit(1)
+		// Parse natural language using AI
+		prompt := ai.ParseCalendarEventPrompt(input, time.Now())
+		response, err := aiClient.Call(prompt)
+		if err == nil {
+			// Debug: show raw AI response
+			if calendarAddDebug {
+				fmt.Printf("AI response:\n%s\n\n", response)
+			}
+			parsed, err = ai.ParseEventResponse(response)
+		}
+		if err != nil {
+			// Fall back to the built-in parser
+			local, ok := ai.ParseEventLocally(input, time.Now(), i18n.DayFirst())
+			if !ok {
+				fmt.Printf("Error: %v\n", err)
+				os.Exit(1)
+			}
+			fmt.Printf("%s failed (%v); using the built-in parser.\n\n", aiClient.Provider(), err)
+			parsed = local
+		}
+	} else {
+		var ok bool
+		if parsed, ok = ai.ParseEventLocally(input, time.Now(), i18n.DayFirst()); !ok {
+			fmt.Printf("Error: no day or time found in %q.\n", input)
+			fmt.Println()
+			fmt.Println("Write it like \"tomorrow 3pm\", \"fri\", \"jan 5 14:00\" or \"in 2 hours\",")
+			fmt.Println("or install one of the following to describe events freely:")
+			fmt.Println("  - claude (Claude Code)")
+			fmt.Println("  - codex  (Codex CLI)")
+			fmt.Println("  - gemini (Gemini CLI)")
+			fmt.Println("  - ollama (Ollama)")
+			os.Exit(1)
+		}
 	}
 
 	startTime, err := parsed.GetStartTime()
@@ -140,11 +153,11 @@ func runCalendarAdd(input string) {
 
 	// Step 1: Show parsed event
 	fmt.Println("  ┌─ Parsed Event ─────────────────────────────────┐")
-	fmt.Printf("  │  Title:    %-37s│\n", truncate(parsed.Title, 37))
-	fmt.Printf("  │  Date:     %-37s│\n", startTime.Format("Monday, Jan 2, 2006"))
-	fmt.Printf("  │  Time:     %-37s│\n", fmt.Sprintf("%s - %s", startTime.Format("3:04 PM"), endTime.Format("3:04 PM")))
+	fmt.Printf("  │  Title:    %s│\n", utils.FitWidth(parsed.Title, 37))
+	fmt.Printf("  │  Date:     %s│\n", utils.FitWidth(startTime.Format("Monday, Jan 2, 2006"), 37))
+	fmt.Printf("  │  Time:     %s│\n", utils.FitWidth(eventTimes(parsed.AllDay, startTime, endTime), 37))
 	if parsed.Location != "" {
-		fmt.Printf("  │  Location: %-37s│\n", truncate(parsed.Location, 37))
+		fmt.Printf("  │  Location: %s│\n", utils.FitWidth(parsed.Location, 37))
 	}
 	fmt.Println("  └────────────────────────────────────────────────┘")
 
@@ -184,17 +197,17 @@ func runCalendarAdd(input string) {
 	// Step 4: Final confirmation with all details
 	fmt.Println()
 	fmt.Println("  ┌─ Confirm Event ────────────────────────────────┐")
-	fmt.Printf("  │  Title:    %-37s│\n", truncate(parsed.Title, 37))
-	fmt.Printf("  │  Date:     %-37s│\n", startTime.Format("Monday, Jan 2, 2006"))
-	fmt.Printf("  │  Time:     %-37s│\n", fmt.Sprintf("%s - %s", startTime.Format("3:04 PM"), endTime.Format("3:04 PM")))
+	fmt.Printf("  │  Title:    %s│\n", utils.FitWidth(parsed.Title, 37))
+	fmt.Printf("  │  Date:     %s│\n", utils.FitWidth(startTime.Format("Monday, Jan 2, 2006"), 37))
+	fmt.Printf("  │  Time:     %s│\n", utils.FitWidth(eventTimes(parsed.AllDay, startTime, endTime), 37))
 	if parsed.Location != "" {
-		fmt.Printf("  │  Location: %-37s│\n", truncate(parsed.Location, 37))
+		fmt.Printf("  │  Location: %s│\n", utils.FitWidth(parsed.Location, 37))
 	}
-	fmt.Printf("  │  Calendar: %-37s│\n", truncate(calendarName, 37))
+	fmt.Printf("  │  Calendar: %s│\n", utils.FitWidth(calendarName, 37))
 	if alarmMinutes > 0 {
-		fmt.Printf("  │  Reminder: %-37s│\n", fmt.Sprintf("%d minutes before", alarmMinutes))
+		fmt.Printf("  │  Reminder: %s│\n", utils.FitWidth(fmt.Sprintf("%d minutes before", alarmMinutes), 37))
 	} else {
-		fmt.Printf("  │  Reminder: %-37s│\n", "None")
+		fmt.Printf("  │  Reminder: %s│\n", utils.FitWidth("None", 37))
 	}
 	fmt.Println("  └────────────────────────────────────────────────┘")
 
@@ -215,6 +228,7 @@ func runCalendarAdd(input string) {
 		StartTime:          startTime,
 		EndTime:            endTime,
 		Location:           parsed.Location,
+		AllDay:             parsed.AllDay,
 		AlarmMinutesBefore: alarmMinutes,
 		Calendar:           calendarID,
 	}
@@ -229,6 +243,14 @@ func runCalendarAdd(input string) {
 	fmt.Printf("✓ Event created (ID: %s)\n", eventID[:8])
 }
 
+// eventTimes describes when a parsed event is, "All day" for one without times
+func eventTimes(allDay bool, start, end time.Time) string {
+	if allDay {
+		return "All day"
+	}
+	return fmt.Sprintf("%s - %s", start.Format("3:04 PM"), end.Format("3:04 PM"))
+}
+
 func promptForEventDescription() string {
 	input, cancelled := RunTextInput("Describe your event", "tomorrow 9am meeting with Jerry")
 	if cancelled {
@@ -268,11 +290,9 @@ func promptForAlarm() (int, bool) {
 	return minutes, false
 }
 
+// truncate shortens s to maxLen display columns, so wide characters keep tables aligned
 func truncate(s string, maxLen int) string {
-	if len(s) <= maxLen {
-		return s
-	}
-	return s[:maxLen-3] + "..."
+	return runewidth.Truncate(s, maxLen, "...")
 }
 
 func runCalendarTUI() {
@@ -285,6 +305,7 @@ func runCalendarTUI() {
 	if err := i18n.Init(cfg.Language); err != nil {
 		fmt.Printf("Warning: i18n initialization failed: %v\n", err)
 	}
+	i18n.SetWeekStart(cfg.Calendar.FirstWeekday())
 
 	// Check calendar access first
 	status := calendar.GetAuthStatus()
@@ -310,8 +331,24 @@ func runCalendarTUI() {
 		os.Exit(1)
 	}
 
+	// Invitations to attendees are sent by the server
+	account := invitationAccount()
+	if account != nil {
+		if err := startServerBackground(); err != nil {
+			fmt.Printf("Warning: failed to start server: %v\n", err)
+		}
+	}
+
+	// Open on the day last viewed
+	calendarApp := ui.NewCalendarApp(client, account, cfg.Calendar)
+	diskCache, _ := cache.New()
+	if diskCache != nil {
+		defer diskCache.Close()
+	}
+	calendarApp.RestoreDate(diskCache)
+
 	p := tea.NewProgram(
-		ui.NewCalendarApp(client),
+		calendarApp,
 		tea.WithAltScreen(),
 		tea.WithMouseCellMotion(),
 	)
@@ -320,4 +357,25 @@ func runCalendarTUI() {
 		fmt.Printf("Error running calendar: %v\n", err)
 		os.Exit(1)
 	}
+	calendarApp.SaveDate(diskCache)
+}
+
+// invitationAccount returns the account invitations to attendees are sent from:
+// --account, or the first account; nil when there are none
+func invitationAccount() *auth.Account {
+	store, err := auth.LoadAccountStore()
+	if err != nil || len(store.Accounts) == 0 {
+		if calendarAccount != "" {
+			fail("no accounts configured - run 'maily login' first")
+		}
+		return nil
+	}
+	if calendarAccount == "" {
+		return &store.Accounts[0]
+	}
+	account, err := resolveAccount(store, calendarAccount)
+	if err != nil {
+		fail("%v", err)
+	}
+	return account
 }
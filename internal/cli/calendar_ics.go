@@ -0,0 +1,197 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"maily/internal/calendar"
+)
+
+var (
+	calendarExportFrom     string
+	calendarExportTo       string
+	calendarExportCalendar string
+	calendarImportCalendar string
+)
+
+var calendarExportCmd = &cobra.Command{
+	Use:   "export [file.ics]",
+	Short: "Export events to an iCalendar file",
+	Long: `Write the events from --from to --to (both days included) to an iCalendar file that
+other calendar apps can import, or to stdout when no file is given.`,
+	Example: `  maily calendar export --from 2025-01-01 --to 2025-12-31 2025.ics
+  maily c export --calendar Work > work.ics`,
+	Args: cobra.MaximumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		path := ""
+		if len(args) == 1 {
+			path = args[0]
+		}
+		runCalendarExport(path)
+	},
+}
+
+var calendarImportCmd = &cobra.Command{
+	Use:   "import <file.ics>...",
+	Short: "Import events from iCalendar files",
+	Long: `Add the events in iCalendar files to the calendar. Events already in the calendar,
+with the same title and start, are skipped, so importing a file again adds only what's
+new. Recurring events are imported as their first occurrence.`,
+	Example: `  maily calendar import ~/Downloads/invite.ics
+  maily c import --calendar Work team.ics`,
+	Args: cobra.MinimumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		runCalendarImport(args)
+	},
+}
+
+func init() {
+	calendarExportCmd.Flags().StringVar(&calendarExportFrom, "from", "", "First day to export, YYYY-MM-DD (default: today)")
+	calendarExportCmd.Flags().StringVar(&calendarExportTo, "to", "", "Last day to export, YYYY-MM-DD (default: a year after --from)")
+	calendarExportCmd.Flags().StringVar(&calendarExportCalendar, "calendar", "", "Only export events from the calendar with this name")
+	calendarImportCmd.Flags().StringVar(&calendarImportCalendar, "calendar", "", "Calendar to add the events to (default: the default calendar)")
+	calendarCmd.AddCommand(calendarExportCmd)
+	calendarCmd.AddCommand(calendarImportCmd)
+}
+
+func runCalendarExport(path string) {
+	now := time.Now()
+	from := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.Local)
+	if calendarExportFrom != "" {
+		from = parseDayFlag("--from", calendarExportFrom)
+	}
+	to := from.AddDate(1, 0, 0)
+	if calendarExportTo != "" {
+		to = parseDayFlag("--to", calendarExportTo)
+	}
+	if to.Before(from) {
+		fail("--to is before --from")
+	}
+
+	client, err := calendar.NewClient()
+	if err != nil {
+		fail("accessing calendar: %v", err)
+	}
+	events, err := client.ListEvents(from, to.AddDate(0, 0, 1))
+	if err != nil {
+		fail("listing events: %v", err)
+	}
+	if calendarExportCalendar != "" {
+		var matched []calendar.Event
+		for _, e := range events {
+			if strings.EqualFold(e.Calendar, calendarExportCalendar) {
+				matched = append(matched, e)
+			}
+		}
+		events = matched
+	}
+
+	data := calendar.ExportICS(events)
+	if path == "" || path == "-" {
+		os.Stdout.Write(data)
+		return
+	}
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		fail("%v", err)
+	}
+	fmt.Printf("Exported %d events to %s\n", len(events), path)
+}
+
+// parseDayFlag reads a YYYY-MM-DD flag value as local midnight
+func parseDayFlag(flag, value string) time.Time {
+	day, err := time.ParseInLocation("2006-01-02", value, time.Local)
+	if err != nil {
+		fail("%s: %q is not a YYYY-MM-DD date", flag, value)
+	}
+	return day
+}
+
+func runCalendarImport(paths []string) {
+	var events []calendar.Event
+	for _, path := range paths {
+		f, err := os.Open(path)
+		if err != nil {
+			fail("%v", err)
+		}
+		parsed, err := calendar.ParseICS(f)
+		f.Close()
+		if err != nil {
+			fail("%s: %v", path, err)
+		}
+		events = append(events, parsed...)
+	}
+	if len(events) == 0 {
+		fmt.Fprintln(os.Stderr, "No events found.")
+		os.Exit(exitNoResults)
+	}
+
+	client, err := calendar.NewClient()
+	if err != nil {
+		fail("accessing calendar: %v", err)
+	}
+
+	calendarID := ""
+	if calendarImportCalendar != "" {
+		calendars, err := client.ListCalendars()
+		if err != nil {
+			fail("listing calendars: %v", err)
+		}
+		for _, cal := range calendars {
+			if strings.EqualFold(cal.Title, calendarImportCalendar) {
+				calendarID = cal.ID
+			}
+		}
+		if calendarID == "" {
+			fail("no calendar named %q; see 'maily c list'", calendarImportCalendar)
+		}
+	}
+
+	// The events already in the calendar over the days imported
+	from, to := events[0].StartTime, events[0].EndTime
+	for _, e := range events {
+		if e.StartTime.Before(from) {
+			from = e.StartTime
+		}
+		if e.EndTime.After(to) {
+			to = e.EndTime
+		}
+	}
+	existing, err := client.ListEvents(from.AddDate(0, 0, -1), to.AddDate(0, 0, 1))
+	if err != nil {
+		fail("listing events: %v", err)
+	}
+
+	added, skipped := 0, 0
+	for _, e := range events {
+		if hasEvent(existing, e) {
+			skipped++
+			continue
+		}
+		e.ID = ""
+		e.Calendar = calendarID
+		e.Attendees = nil // importing doesn't invite anyone
+		if _, err := client.CreateEvent(e); err != nil {
+			fail("adding %q: %v", e.Title, err)
+		}
+		existing = append(existing, e)
+		added++
+	}
+	fmt.Printf("Imported %d events", added)
+	if skipped > 0 {
+		fmt.Printf(" (%d already in the calendar)", skipped)
+	}
+	fmt.Println()
+}
+
+// hasEvent reports whether events has one with the same title and start as e
+func hasEvent(events []calendar.Event, e calendar.Event) bool {
+	for _, other := range events {
+		if other.Title == e.Title && other.StartTime.Equal(e.StartTime) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,89 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"maily/internal/mail"
+	"maily/internal/ui"
+)
+
+var (
+	composeAccount  string
+	composeTo       string
+	composeSubject  string
+	composeBody     string
+	composeBodyFile string
+)
+
+var composeCmd = &cobra.Command{
+	Use:   "compose [mailto:URL]",
+	Short: "Open the compose view prefilled",
+	Long: `Open maily straight into the compose view, prefilled from flags or a mailto: URL.
+
+Register "maily compose %u" (or just "maily %u") as your system mail handler to
+open mailto: links in maily. Flags override fields from the URL.`,
+	Example: `  maily compose --to a@b.com --subject "Hi"
+  git log -1 | maily compose --to team@corp.com --subject "Release" --body-file -
+  maily "mailto:a@b.com?subject=Hi&body=Hello%20there"`,
+	Args: cobra.MaximumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		var draft ui.ComposeDraft
+		if len(args) == 1 {
+			var err error
+			if draft, err = parseMailto(args[0]); err != nil {
+				fail("%v", err)
+			}
+		}
+		if composeTo != "" {
+			draft.To = composeTo
+		}
+		if composeSubject != "" {
+			draft.Subject = composeSubject
+		}
+		if composeBody != "" && composeBodyFile != "" {
+			fail("use either --body or --body-file, not both")
+		}
+		if composeBody != "" {
+			draft.Body = composeBody
+		}
+		if composeBodyFile != "" {
+			body, err := readBody(composeBodyFile)
+			if err != nil {
+				fail("reading body: %v", err)
+			}
+			draft.Body = body
+		}
+		draft.Account = composeAccount
+		runTUI(&draft)
+	},
+}
+
+func init() {
+	composeCmd.Flags().StringVarP(&composeAccount, "account", "a", "", "Account to send from (defaults to the first account)")
+	composeCmd.Flags().StringVar(&composeTo, "to", "", "Recipients, comma-separated")
+	composeCmd.Flags().StringVar(&composeSubject, "subject", "", "Subject line")
+	composeCmd.Flags().StringVar(&composeBody, "body", "", "Message body")
+	composeCmd.Flags().StringVar(&composeBodyFile, "body-file", "", "Read the message body from a file ('-' for stdin)")
+	rootCmd.AddCommand(composeCmd)
+}
+
+// parseMailto converts an RFC 6068 mailto: URL into a compose draft.
+// Cc recipients are added to To since the compose view has a single
+// recipient field; Bcc is dropped rather than exposing it to everyone.
+func parseMailto(raw string) (ui.ComposeDraft, error) {
+	m, err := mail.ParseMailto(raw)
+	if err != nil {
+		return ui.ComposeDraft{}, err
+	}
+	if len(m.Bcc) > 0 {
+		fmt.Fprintln(os.Stderr, "Warning: ignoring Bcc recipients from mailto: URL")
+	}
+	return ui.ComposeDraft{
+		To:      strings.Join(append(m.To, m.Cc...), ", "),
+		Subject: m.Subject,
+		Body:    m.Body,
+	}, nil
+}
@@ -0,0 +1,63 @@
+package cli
+
+import "testing"
+
+func TestParseMailto(t *testing.T) {
+	tests := []struct {
+		name        string
+		url         string
+		wantTo      string
+		wantSubject string
+		wantBody    string
+		wantErr     bool
+	}{
+		{
+			name:   "address_only",
+			url:    "mailto:a@b.com",
+			wantTo: "a@b.com",
+		},
+		{
+			name:        "subject_and_body",
+			url:         "mailto:a@b.com?subject=Hello%20there&body=Line%201%0D%0ALine%202",
+			wantTo:      "a@b.com",
+			wantSubject: "Hello there",
+			wantBody:    "Line 1\nLine 2",
+		},
+		{
+			name:   "multiple_recipients_and_cc",
+			url:    "MAILTO:a@b.com,c@d.com?CC=e@f.com&to=g@h.com",
+			wantTo: "a@b.com, c@d.com, g@h.com, e@f.com",
+		},
+		{
+			name:        "plus_is_literal",
+			url:         "mailto:?subject=C++%20tips",
+			wantSubject: "C++ tips",
+		},
+		{
+			name:    "not_mailto",
+			url:     "https://example.com",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			draft, err := parseMailto(tt.url)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseMailto() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if draft.To != tt.wantTo {
+				t.Errorf("To = %q, want %q", draft.To, tt.wantTo)
+			}
+			if draft.Subject != tt.wantSubject {
+				t.Errorf("Subject = %q, want %q", draft.Subject, tt.wantSubject)
+			}
+			if draft.Body != tt.wantBody {
+				t.Errorf("Body = %q, want %q", draft.Body, tt.wantBody)
+			}
+		})
+	}
+}
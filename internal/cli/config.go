@@ -5,6 +5,7 @@ import (
 	"os"
 
 	"github.com/spf13/cobra"
+	"maily/config"
 )
 
 var configCmd = &cobra.Command{
@@ -12,9 +13,60 @@ var configCmd = &cobra.Command{
 	Short: "Configure maily settings",
 	Long:  `Open interactive configuration to view and edit maily settings.`,
 	Run: func(cmd *cobra.Command, args []string) {
+		initLanguage()
 		if err := RunConfigTUI(); err != nil {
 			fmt.Printf("Error: %v\n", err)
 			os.Exit(1)
 		}
 	},
 }
+
+var configCheckCmd = &cobra.Command{
+	Use:   "check",
+	Short: "Check config.yml for invalid settings",
+	Long: `Check config.yml and list every problem at once: YAML errors, misspelt keys and
+invalid values such as an unknown theme, an AI provider without a model or a negative
+max_emails. Exits with 1 when there are problems.
+
+maily refuses to start with an invalid config; the server goes on with the defaults for
+invalid settings and logs them.`,
+	Example: `  maily config check
+  maily config check --json`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		handleConfigCheck()
+	},
+}
+
+func init() {
+	configCmd.AddCommand(configCheckCmd)
+}
+
+func handleConfigCheck() {
+	path, err := config.Path()
+	if err != nil {
+		fail("%v", err)
+	}
+	problems, err := config.Check()
+	if err != nil {
+		fail("reading %s: %v", path, err)
+	}
+
+	if jsonOutput {
+		printJSON(map[string]any{"path": path, "valid": len(problems) == 0, "problems": problems})
+	} else if len(problems) == 0 {
+		fmt.Printf("%s: no problems found\n", path)
+	} else {
+		fmt.Printf("%s: %d problem(s)\n", path, len(problems))
+		for _, problem := range problems {
+			if problem.Fatal {
+				fmt.Printf("  %s (maily won't start until this is fixed)\n", problem)
+			} else {
+				fmt.Printf("  %s\n", problem)
+			}
+		}
+	}
+	if len(problems) > 0 {
+		os.Exit(exitError)
+	}
+}
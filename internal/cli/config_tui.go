@@ -141,6 +141,22 @@ func (m *ConfigTUI) buildRows() {
 		{kind: rowAction, key: "language", label: i18n.T("config.language"), value: langDisplay, providerIdx: -1},
 	}
 
+	// Logging
+	logLevel, logFormat := "info", "text"
+	if m.cfg.Logging != nil {
+		if m.cfg.Logging.Level != "" {
+			logLevel = m.cfg.Logging.Level
+		}
+		if m.cfg.Logging.Format != "" {
+			logFormat = m.cfg.Logging.Format
+		}
+	}
+	m.rows = append(m.rows,
+		row{kind: rowSection, label: i18n.T("config.section.logging")},
+		row{kind: rowAction, key: "log_level", label: i18n.T("config.log_level"), value: logLevel, providerIdx: -1},
+		row{kind: rowAction, key: "log_format", label: i18n.T("config.log_format"), value: logFormat, providerIdx: -1},
+	)
+
 	// AI Providers
 	if len(m.cfg.AIProviders) > 0 {
 		m.rows = append(m.rows, row{kind: rowSection, label: i18n.T("config.section.ai_providers")})
@@ -356,6 +372,16 @@ func (m ConfigTUI) handleSelect() (tea.Model, tea.Cmd) {
 				}
 			}
 			return m, nil
+		case "log_level":
+			m.ensureLogging().Level = nextOption(logLevels, r.value)
+			m.dirty = true
+			m.buildRows()
+			return m, nil
+		case "log_format":
+			m.ensureLogging().Format = nextOption(logFormats, r.value)
+			m.dirty = true
+			m.buildRows()
+			return m, nil
 		case "add_cli":
 			m.openProviderDialog(config.AIProviderTypeCLI, -1)
 			return m, textinput.Blink
@@ -373,6 +399,30 @@ func (m ConfigTUI) handleSelect() (tea.Model, tea.Cmd) {
 	return m, nil
 }
 
+// Values cycled through by the logging rows
+var (
+	logLevels  = []string{"debug", "info", "warn", "error"}
+	logFormats = []string{"text", "json"}
+)
+
+// nextOption returns the option following current, wrapping around
+func nextOption(options []string, current string) string {
+	for i, opt := range options {
+		if opt == current {
+			return options[(i+1)%len(options)]
+		}
+	}
+	return options[0]
+}
+
+// ensureLogging returns the logging config, creating it if unset
+func (m *ConfigTUI) ensureLogging() *config.LoggingConfig {
+	if m.cfg.Logging == nil {
+		m.cfg.Logging = &config.LoggingConfig{}
+	}
+	return m.cfg.Logging
+}
+
 func (m *ConfigTUI) clampCursor() {
 	if m.cursor >= len(m.rows) {
 		m.cursor = len(m.rows) - 1
@@ -644,8 +694,8 @@ func (m ConfigTUI) View() string {
 		case rowAction:
 			var line string
 			switch r.key {
-			case "language":
-				// Language row: show like a field with value
+			case "language", "log_level", "log_format":
+				// Language and logging rows: show like a field with value
 				label := cfgLabelStyle.Render(r.label)
 				value := cfgValueStyle.Render(r.value)
 				line = pad + "  " + label + " " + value
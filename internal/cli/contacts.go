@@ -0,0 +1,172 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"maily/config"
+	"maily/internal/cache"
+	"maily/internal/contacts"
+)
+
+var contactsBook string
+
+var contactsCmd = &cobra.Command{
+	Use:   "contacts [query]",
+	Short: "List address book contacts used for autocomplete",
+	Args:  cobra.MaximumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		query := ""
+		if len(args) == 1 {
+			query = args[0]
+		}
+		handleContactsList(query)
+	},
+}
+
+var contactsImportCmd = &cobra.Command{
+	Use:     "import <file.vcf>...",
+	Short:   "Import contacts from vCard files",
+	Example: `  maily contacts import ~/Downloads/contacts.vcf`,
+	Args:    cobra.MinimumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		handleContactsImport(args)
+	},
+}
+
+var contactsSyncCmd = &cobra.Command{
+	Use:   "sync",
+	Short: "Sync contacts from CardDAV address books",
+	Long: `Download contacts from the CardDAV address books listed under contacts.carddav in
+config.yml and merge them into the local address book by email address.`,
+	Example: `  maily contacts sync
+  maily contacts sync --book fastmail`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		handleContactsSync()
+	},
+}
+
+func init() {
+	contactsSyncCmd.Flags().StringVar(&contactsBook, "book", "", "Only sync the address book with this name")
+	contactsCmd.AddCommand(contactsImportCmd)
+	contactsCmd.AddCommand(contactsSyncCmd)
+	rootCmd.AddCommand(contactsCmd)
+}
+
+func openContactsCache() *cache.Cache {
+	c, err := cache.New()
+	if err != nil {
+		fail("opening cache: %v", err)
+	}
+	return c
+}
+
+func handleContactsList(query string) {
+	c := openContactsCache()
+	list, err := c.LoadContacts()
+	c.Close()
+	if err != nil {
+		fail("%v", err)
+	}
+
+	query = strings.ToLower(query)
+	matched := make([]contacts.Contact, 0, len(list))
+	for _, ct := range list {
+		if query == "" || strings.Contains(strings.ToLower(ct.String()), query) {
+			matched = append(matched, ct)
+		}
+	}
+
+	if jsonOutput {
+		printJSON(matched)
+	} else {
+		for _, ct := range matched {
+			fmt.Println(ct.String())
+		}
+	}
+	if len(matched) == 0 {
+		if !jsonOutput {
+			fmt.Fprintln(os.Stderr, "No contacts found. Import some with 'maily contacts import' or 'maily contacts sync'.")
+		}
+		os.Exit(exitNoResults)
+	}
+}
+
+func handleContactsImport(paths []string) {
+	var incoming []contacts.Contact
+	for _, path := range paths {
+		f, err := os.Open(path)
+		if err != nil {
+			fail("%v", err)
+		}
+		cards, err := contacts.ParseVCards(f, "vcard:"+filepath.Base(path))
+		f.Close()
+		if err != nil {
+			fail("%s: %v", path, err)
+		}
+		incoming = append(incoming, cards...)
+	}
+
+	c := openContactsCache()
+	added, updated, err := c.ImportContacts(incoming)
+	c.Close()
+	if err != nil {
+		fail("saving contacts: %v", err)
+	}
+	printContactsResult(len(incoming), added, updated)
+}
+
+func handleContactsSync() {
+	cfg, err := config.Load()
+	if err != nil {
+		fail("loading config: %v", err)
+	}
+
+	var books []config.CardDAVConfig
+	if cfg.Contacts != nil {
+		for _, book := range cfg.Contacts.CardDAV {
+			if contactsBook == "" || strings.EqualFold(book.Name, contactsBook) {
+				books = append(books, book)
+			}
+		}
+	}
+	if len(books) == 0 {
+		if contactsBook != "" {
+			fail("no CardDAV address book named %q in config.yml", contactsBook)
+		}
+		fail("no CardDAV address books configured - add contacts.carddav to config.yml")
+	}
+
+	var incoming []contacts.Contact
+	for _, book := range books {
+		cards, err := contacts.FetchCardDAV(context.Background(), book)
+		if err != nil {
+			fail("%v", err)
+		}
+		if !jsonOutput {
+			fmt.Printf("%s: %d contacts\n", book.Name, len(cards))
+		}
+		incoming = append(incoming, cards...)
+	}
+
+	c := openContactsCache()
+	added, updated, err := c.ImportContacts(incoming)
+	c.Close()
+	if err != nil {
+		fail("saving contacts: %v", err)
+	}
+	printContactsResult(len(incoming), added, updated)
+}
+
+func printContactsResult(read, added, updated int) {
+	if jsonOutput {
+		printJSON(map[string]int{"read": read, "added": added, "updated": updated})
+		return
+	}
+	fmt.Printf("Read %d contacts: %d new, %d updated\n", read, added, updated)
+}
@@ -0,0 +1,117 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/spf13/cobra"
+	"maily/config"
+	"maily/internal/auth"
+	"maily/internal/cache"
+	"maily/internal/digest"
+)
+
+var (
+	digestAccount string
+	digestSendTo  string
+	digestOutput  string
+)
+
+var digestCmd = &cobra.Command{
+	Use:   "digest",
+	Short: "Summarize today's events, important mail and follow-ups",
+	Long: `Compose a morning summary from the local cache and calendar: today's events, unread
+mail from VIPs (today.vips in the config) or marked important by Gmail, and unread
+replies in threads you follow. Prints Markdown, writes it to a file, or mails it.
+
+To have the server mail it every morning, set digest.send_to (and optionally
+digest.at, default 07:00) in the config.`,
+	Example: `  maily digest
+  maily digest -o ~/digest.md
+  maily digest --send-to me@example.com`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		handleDigest()
+	},
+}
+
+func init() {
+	digestCmd.Flags().StringVarP(&digestAccount, "account", "a", "", "Only summarize this account, and send from it")
+	digestCmd.Flags().StringVar(&digestSendTo, "send-to", "", "Mail the digest to these recipients, comma-separated")
+	digestCmd.Flags().StringVarP(&digestOutput, "output", "o", "", "Write the Markdown to this file")
+	rootCmd.AddCommand(digestCmd)
+}
+
+func handleDigest() {
+	cfg, err := config.Load()
+	if err != nil {
+		fail("loading config: %v", err)
+	}
+	store, err := auth.LoadAccountStore()
+	if err != nil {
+		fail("%v", err)
+	}
+	if len(store.Accounts) == 0 {
+		fail("no accounts configured - run 'maily login' first")
+	}
+
+	accounts := store.Accounts
+	sender := &store.Accounts[0]
+	if digestAccount != "" {
+		if sender, err = resolveAccount(store, digestAccount); err != nil {
+			fail("%v", err)
+		}
+		accounts = []auth.Account{*sender}
+	} else if cfg.Digest != nil && cfg.Digest.Account != "" {
+		if account := store.GetAccount(cfg.Digest.Account); account != nil {
+			sender = account
+		}
+	}
+	emails := make([]string, len(accounts))
+	for i, account := range accounts {
+		emails[i] = account.Credentials.Email
+	}
+
+	c, err := cache.New()
+	if err != nil {
+		fail("opening cache: %v", err)
+	}
+	now := time.Now()
+	d, err := digest.Collect(c, emails, cfg.Today.VIP, now)
+	c.Close()
+	if err != nil {
+		fail("%v", err)
+	}
+	d.Events, d.Calendar = digest.TodayEvents(now)
+
+	if digestOutput != "" {
+		if err := os.WriteFile(digestOutput, []byte(d.Markdown()), 0600); err != nil {
+			fail("writing digest: %v", err)
+		}
+	}
+	if digestSendTo != "" {
+		if err := digest.Send(sender, digestSendTo, d); err != nil {
+			fail("sending: %v", err)
+		}
+	}
+
+	switch {
+	case jsonOutput:
+		printJSON(map[string]any{
+			"date":       now.Format(time.DateOnly),
+			"events":     len(d.Events),
+			"important":  len(d.Important),
+			"follow_ups": len(d.FollowUps),
+			"unread":     d.Unread,
+			"sent_to":    digestSendTo,
+			"output":     digestOutput,
+		})
+	case digestSendTo != "":
+		fmt.Printf("Sent to %s\n", digestSendTo)
+	case digestOutput != "":
+		fmt.Printf("Wrote %s\n", digestOutput)
+	default:
+		fmt.Print(d.Markdown())
+	}
+}
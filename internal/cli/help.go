@@ -0,0 +1,111 @@
+package cli
+
+import (
+	"os"
+	"strings"
+	"sync"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+	"maily/config"
+	"maily/internal/i18n"
+)
+
+// usageTemplate is cobra's default usage template with its headings translated
+const usageTemplate = `{{t "cli.usage.usage"}}{{if .Runnable}}
+  {{.UseLine}}{{end}}{{if .HasAvailableSubCommands}}
+  {{.CommandPath}} [command]{{end}}{{if gt (len .Aliases) 0}}
+
+{{t "cli.usage.aliases"}}
+  {{.NameAndAliases}}{{end}}{{if .HasExample}}
+
+{{t "cli.usage.examples"}}
+{{.Example}}{{end}}{{if .HasAvailableSubCommands}}
+
+{{t "cli.usage.commands"}}{{range .Commands}}{{if (or .IsAvailableCommand (eq .Name "help"))}}
+  {{rpad .Name .NamePadding }} {{.Short}}{{end}}{{end}}{{end}}{{if .HasAvailableLocalFlags}}
+
+{{t "cli.usage.flags"}}
+{{.LocalFlags.FlagUsages | trimTrailingWhitespaces}}{{end}}{{if .HasAvailableInheritedFlags}}
+
+{{t "cli.usage.global_flags"}}
+{{.InheritedFlags.FlagUsages | trimTrailingWhitespaces}}{{end}}{{if .HasAvailableSubCommands}}
+
+{{t "cli.usage.more" (dict "Command" .CommandPath)}}{{end}}
+`
+
+// localizeHelp shows help and usage in the configured language. The config and
+// translations are loaded the first time either is rendered, so commands that print
+// no help don't pay for them.
+func localizeHelp(root *cobra.Command) {
+	var once sync.Once
+	translate := func() {
+		once.Do(func() { translateHelp(root) })
+	}
+	help, usage := root.HelpFunc(), root.UsageFunc()
+	root.SetHelpFunc(func(cmd *cobra.Command, args []string) {
+		translate()
+		help(cmd, args)
+	})
+	root.SetUsageFunc(func(cmd *cobra.Command) error {
+		translate()
+		return usage(cmd)
+	})
+}
+
+// initLanguage translates the messages a command prints into the configured language
+func initLanguage() {
+	cfg, _ := config.Load()
+	i18n.Init(cfg.Language)
+}
+
+// translateHelp translates the help of every command into the configured language:
+// descriptions from cli.short.<command path> and the root's flags from cli.flag.<name>
+// when the language has them, the help flag and the usage headings
+func translateHelp(root *cobra.Command) {
+	cfg, _ := config.Load()
+	if err := i18n.Init(cfg.Language); err != nil {
+		return
+	}
+
+	// Let cobra add its own commands now so they are translated too
+	root.InitDefaultHelpCmd()
+	root.InitDefaultCompletionCmd(os.Args[1:]...)
+
+	cobra.AddTemplateFunc("t", i18n.T)
+	cobra.AddTemplateFunc("dict", func(key string, value any) map[string]any {
+		return map[string]any{key: value}
+	})
+	root.SetUsageTemplate(usageTemplate)
+
+	var walk func(cmd *cobra.Command)
+	walk = func(cmd *cobra.Command) {
+		path := strings.TrimPrefix(strings.TrimPrefix(cmd.CommandPath(), root.Name()), " ")
+		id := "cli.short." + strings.ReplaceAll(path, " ", ".")
+		if path == "" {
+			id = "cli.short.maily"
+			if long := i18n.T("cli.long.maily"); long != "cli.long.maily" {
+				cmd.Long = long
+			}
+		}
+		if short := i18n.T(id); short != id {
+			cmd.Short = short
+		}
+		cmd.InitDefaultHelpFlag()
+		cmd.Flags().Lookup("help").Usage = i18n.T("cli.usage.help_flag", map[string]any{"Command": cmd.Name()})
+		for _, sub := range cmd.Commands() {
+			walk(sub)
+		}
+	}
+	walk(root)
+
+	// The root's own flags, listed by maily help
+	for _, flags := range []*pflag.FlagSet{root.Flags(), root.PersistentFlags()} {
+		flags.VisitAll(func(flag *pflag.Flag) {
+			id := "cli.flag." + flag.Name
+			if usage := i18n.T(id); usage != id {
+				flag.Usage = usage
+			}
+		})
+	}
+}
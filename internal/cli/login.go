@@ -3,23 +3,49 @@ package cli
 import (
 	"fmt"
 	"os"
+	"path/filepath"
+	"strings"
 
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/spf13/cobra"
-	"maily/config"
+	"maily/internal/auth"
+	"maily/internal/client"
 	"maily/internal/i18n"
+	"maily/internal/mail"
+	"maily/internal/server"
 	"maily/internal/ui"
 )
 
+var (
+	loginReauth       string
+	loginMaildirPath  string
+	loginMaildirEmail string
+	loginNotmuchDB    string
+)
+
 var loginCmd = &cobra.Command{
 	Use:   "login [provider]",
 	Short: "Add an email account",
-	Long:  "Add an email account. Currently supports: gmail, yahoo, qq",
-	Args:  cobra.MaximumNArgs(1),
+	Long: `Add an email account. Currently supports: gmail, yahoo, qq, maildir
+
+A maildir account reads local mail kept in sync by mbsync or offlineimap, and sends
+with the sendmail command (e.g. msmtp).`,
+	Example: `  maily login gmail
+  maily login --reauth me@gmail.com   # replace a revoked app password
+  maily login maildir --path ~/Mail/work --email me@example.com
+  maily login maildir --path ~/Mail/work --email me@example.com --notmuch-db ~/Mail`,
+	Args: cobra.MaximumNArgs(1),
 	Run: func(cmd *cobra.Command, args []string) {
 		// Initialize i18n for login UI
-		cfg, _ := config.Load()
-		i18n.Init(cfg.Language)
+		initLanguage()
+
+		if loginReauth != "" {
+			if !reauthAccount(loginReauth) {
+				os.Exit(1)
+			}
+			fmt.Printf("%s\n", i18n.T("login.success", map[string]any{"Email": loginReauth}))
+			return
+		}
 
 		if len(args) == 0 {
 			selectAndLogin()
@@ -29,6 +55,49 @@ var loginCmd = &cobra.Command{
 	},
 }
 
+func init() {
+	loginCmd.Flags().StringVar(&loginReauth, "reauth", "", "Update the password of an existing account")
+	loginCmd.Flags().StringVar(&loginMaildirPath, "path", "", "Maildir to read (maildir only)")
+	loginCmd.Flags().StringVar(&loginMaildirEmail, "email", "", "Address to send as (maildir only)")
+	loginCmd.Flags().StringVar(&loginNotmuchDB, "notmuch-db", "", "notmuch database to search and tag with (maildir only)")
+}
+
+// reauthAccount prompts for a new password for an existing account and
+// tells a running server to pick it up. Returns true if the password was updated.
+func reauthAccount(email string) bool {
+	store, err := auth.LoadAccountStore()
+	if err != nil {
+		fmt.Printf("%s: %v\n", i18n.T("common.error"), err)
+		return false
+	}
+	account := store.GetAccount(email)
+	if account == nil {
+		fmt.Printf("%s\n", i18n.T("cli.account_not_found", map[string]any{"Email": email}))
+		return false
+	}
+
+	p := tea.NewProgram(ui.NewReauthApp(*account), tea.WithAltScreen())
+	finalModel, err := p.Run()
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		return false
+	}
+	if login, ok := finalModel.(ui.LoginApp); !ok || !login.Success() {
+		return false
+	}
+
+	// Drop the server's stale IMAP session so it logs in with the new password
+	if server.IsServerRunning() {
+		if c, err := client.Connect(); err == nil {
+			if err := c.ReloadAccounts(); err != nil {
+				fmt.Printf("Warning: failed to reload server accounts: %v\n", err)
+			}
+			c.Close()
+		}
+	}
+	return true
+}
+
 func selectAndLogin() {
 	selector := ui.NewProviderSelector()
 	p := tea.NewProgram(
@@ -55,6 +124,8 @@ func handleLogin(provider string) {
 		loginWithProvider("yahoo")
 	case "qq":
 		loginWithProvider("qq")
+	case auth.ProviderMaildir:
+		loginMaildir(loginMaildirPath, loginMaildirEmail, loginNotmuchDB)
 	default:
 		fmt.Printf("Unknown provider: %s\n", provider)
 		fmt.Println()
@@ -62,10 +133,54 @@ func handleLogin(provider string) {
 		fmt.Println("  gmail    Login with Gmail")
 		fmt.Println("  yahoo    Login with Yahoo Mail")
 		fmt.Println("  qq       Login with QQ Mail")
+		fmt.Println("  maildir  Use a local Maildir (--path, --email)")
 		os.Exit(1)
 	}
 }
 
+// loginMaildir adds a local Maildir account after checking the directory opens
+func loginMaildir(path, email, notmuchDB string) {
+	if path == "" || email == "" {
+		fail("maildir accounts need --path and --email")
+	}
+
+	// The server resolves paths from its own working directory
+	creds := auth.MaildirCredentials(email, absPath(path))
+	if notmuchDB != "" {
+		creds.NotmuchDB = absPath(notmuchDB)
+	}
+	client, err := mail.NewMaildirClient(&creds)
+	if err != nil {
+		fail("%v", err)
+	}
+	client.Close()
+
+	store, err := auth.LoadAccountStore()
+	if err != nil {
+		fail("%v", err)
+	}
+	store.AddAccount(auth.Account{
+		Name:        email,
+		Provider:    auth.ProviderMaildir,
+		Credentials: creds,
+	})
+	if err := store.Save(); err != nil {
+		fail("%v", err)
+	}
+	fmt.Printf("%s\n", i18n.T("login.success", map[string]any{"Email": email}))
+}
+
+// absPath makes a path absolute, leaving ~/ paths to be expanded where they're used
+func absPath(path string) string {
+	if strings.HasPrefix(path, "~/") {
+		return path
+	}
+	if abs, err := filepath.Abs(path); err == nil {
+		return abs
+	}
+	return path
+}
+
 func loginWithProvider(provider string) {
 	loginApp := ui.NewLoginApp(provider)
 	p := tea.NewProgram(
@@ -81,6 +196,6 @@ func loginWithProvider(provider string) {
 
 	// If login succeeded, go directly to email list
 	if login, ok := finalModel.(ui.LoginApp); ok && login.Success() {
-		runTUI()
+		runTUI(nil)
 	}
 }
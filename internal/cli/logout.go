@@ -21,6 +21,7 @@ var logoutCmd = &cobra.Command{
 	Long:  "Remove an email account. If no email specified, prompts for selection.",
 	Args:  cobra.MaximumNArgs(1),
 	Run: func(cmd *cobra.Command, args []string) {
+		initLanguage()
 		if len(args) > 0 {
 			handleLogoutAccount(args[0])
 		} else {
@@ -0,0 +1,114 @@
+package cli
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/spf13/cobra"
+	"maily/internal/logging"
+)
+
+var (
+	logsFollow bool
+	logsLines  int
+	logsTUI    bool
+)
+
+var logsCmd = &cobra.Command{
+	Use:   "logs",
+	Short: "Show maily log files",
+	Long: `Show the tail of the maily server log (or the TUI log with --tui).
+
+Examples:
+  maily logs          # last 50 lines of the server log
+  maily logs -f       # follow the server log
+  maily logs --tui -n 100`,
+	Run: func(cmd *cobra.Command, args []string) {
+		name := logging.ServerLog
+		if logsTUI {
+			name = logging.TUILog
+		}
+		if err := showLogs(logging.GetLogPath(name), logsLines, logsFollow); err != nil {
+			fmt.Printf("Error: %v\n", err)
+			os.Exit(1)
+		}
+	},
+}
+
+func init() {
+	logsCmd.Flags().BoolVarP(&logsFollow, "follow", "f", false, "Follow the log as it grows")
+	logsCmd.Flags().IntVarP(&logsLines, "lines", "n", 50, "Number of lines to show")
+	logsCmd.Flags().BoolVar(&logsTUI, "tui", false, "Show the TUI log instead of the server log")
+}
+
+// showLogs prints the last n lines of the log file and optionally follows it
+func showLogs(path string, n int, follow bool) error {
+	f, err := os.Open(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return fmt.Errorf("no log file at %s", path)
+		}
+		return err
+	}
+	defer func() { f.Close() }()
+
+	for _, line := range tailLines(f, n) {
+		fmt.Println(line)
+	}
+
+	if !follow {
+		return nil
+	}
+
+	offset, err := f.Seek(0, io.SeekEnd)
+	if err != nil {
+		return err
+	}
+
+	for {
+		time.Sleep(500 * time.Millisecond)
+
+		info, err := os.Stat(path)
+		if err != nil {
+			continue // file is being rotated
+		}
+
+		// Reopen after rotation (file replaced or truncated)
+		if cur, err := f.Stat(); err != nil || !os.SameFile(cur, info) || info.Size() < offset {
+			f.Close()
+			if f, err = os.Open(path); err != nil {
+				return err
+			}
+			offset = 0
+		}
+
+		if info.Size() == offset {
+			continue
+		}
+		written, err := io.Copy(os.Stdout, f)
+		if err != nil {
+			return err
+		}
+		offset += written
+	}
+}
+
+// tailLines returns the last n lines read from r
+func tailLines(r io.Reader, n int) []string {
+	if n <= 0 {
+		return nil
+	}
+	lines := make([]string, 0, n)
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		if len(lines) == n {
+			lines = lines[1:]
+		}
+		lines = append(lines, scanner.Text())
+	}
+	return lines
+}
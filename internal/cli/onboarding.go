@@ -0,0 +1,54 @@
+package cli
+
+import (
+	"fmt"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"maily/config"
+	"maily/internal/ui"
+)
+
+// runOnboarding walks a first run through adding an account and picking preferences.
+// It returns false if the user left before adding an account.
+func runOnboarding() bool {
+	p := tea.NewProgram(ui.NewOnboardingApp(), tea.WithAltScreen())
+	m, err := p.Run()
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		return false
+	}
+	wizard, ok := m.(ui.OnboardingApp)
+	if !ok || wizard.Account() == nil {
+		return false
+	}
+	if choices, finished := wizard.Choices(); finished {
+		applyOnboardingChoices(choices)
+	}
+	return true
+}
+
+// applyOnboardingChoices saves the preferences picked during onboarding. Failures are
+// reported but don't stop maily from opening the new account.
+func applyOnboardingChoices(choices ui.OnboardingChoices) {
+	cfg, err := config.Load()
+	if err != nil {
+		fmt.Printf("Warning: could not load config: %v\n", err)
+		return
+	}
+	cfg.Language = choices.Language
+	if choices.Notifications {
+		if cfg.Notifications == nil {
+			cfg.Notifications = &config.NotificationConfig{}
+		}
+		cfg.Notifications.Native = config.NativeNotificationConfig{Enabled: true, NewEmail: true, CalendarReminder: true}
+	}
+	if err := cfg.Save(); err != nil {
+		fmt.Printf("Warning: could not save config: %v\n", err)
+	}
+
+	if choices.Autostart {
+		if _, err := enableAutostart(); err != nil {
+			fmt.Printf("Warning: could not start the server at login: %v\n", err)
+		}
+	}
+}
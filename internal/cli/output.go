@@ -0,0 +1,37 @@
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+)
+
+// Exit codes for scripting
+const (
+	exitError     = 1
+	exitNoResults = 2 // command succeeded but matched nothing
+)
+
+// jsonOutput is set by the global --json flag
+var jsonOutput bool
+
+func init() {
+	rootCmd.PersistentFlags().BoolVar(&jsonOutput, "json", false, "Emit machine-readable JSON (exit code 2 means no results)")
+}
+
+// printJSON writes v to stdout as indented JSON
+func printJSON(v any) {
+	output, _ := json.MarshalIndent(v, "", "  ")
+	fmt.Println(string(output))
+}
+
+// fail reports an error and exits with exitError. With --json the error is
+// also written to stdout as {"error": "..."} so scripts can parse it.
+func fail(format string, args ...any) {
+	msg := fmt.Sprintf(format, args...)
+	if jsonOutput {
+		printJSON(map[string]string{"error": msg})
+	}
+	fmt.Fprintf(os.Stderr, "Error: %s\n", msg)
+	os.Exit(exitError)
+}
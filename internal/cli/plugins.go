@@ -0,0 +1,103 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+	"maily/internal/plugins"
+)
+
+var pluginsCmd = &cobra.Command{
+	Use:   "plugins",
+	Short: "List installed plugins and their actions",
+	Long: `Plugins are executables in ~/.config/maily/plugins that add actions to the mail
+list, shown in the / command palette and optionally bound to a key maily doesn't use.
+
+maily runs a plugin with "describe" and expects its actions as JSON on stdout:
+
+  {"description": "File tickets",
+   "actions": [{"name": "ticket", "title": "Send to my ticket system", "key": "T"}]}
+
+An action runs the plugin with "run <action>", the selected emails (or the current
+one) as JSON on stdin. It may print {"message": "..."} to show in the status bar, or
+{"error": "..."}; a non-zero exit reports its stderr as the error.`,
+	Example: `  maily plugins
+  maily plugins install ./tickets.sh
+  maily plugins remove tickets`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		handlePluginsList()
+	},
+}
+
+var pluginsInstallCmd = &cobra.Command{
+	Use:   "install <executable>",
+	Short: "Copy a plugin into the plugins directory",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		handlePluginsInstall(args[0])
+	},
+}
+
+var pluginsRemoveCmd = &cobra.Command{
+	Use:   "remove <name>",
+	Short: "Remove an installed plugin",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		if err := plugins.Remove(args[0]); err != nil {
+			fail("%v", err)
+		}
+		fmt.Printf("Removed %s\n", args[0])
+	},
+}
+
+func init() {
+	pluginsCmd.AddCommand(pluginsInstallCmd)
+	pluginsCmd.AddCommand(pluginsRemoveCmd)
+	rootCmd.AddCommand(pluginsCmd)
+}
+
+func handlePluginsList() {
+	list, err := plugins.Load()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+	}
+
+	if jsonOutput {
+		if list == nil {
+			list = []plugins.Plugin{}
+		}
+		printJSON(list)
+	} else {
+		if len(list) == 0 {
+			dir, _ := plugins.Dir()
+			fmt.Printf("No plugins installed in %s\n", dir)
+		}
+		for _, p := range list {
+			fmt.Printf("%s  %s\n", p.Name, p.Description)
+			for _, a := range p.Actions {
+				key := ""
+				if a.Key != "" {
+					key = " [" + a.Key + "]"
+				}
+				fmt.Printf("  %-28s %s%s\n", p.Command(a), a.Title, key)
+			}
+		}
+	}
+	if len(list) == 0 {
+		os.Exit(exitNoResults)
+	}
+}
+
+func handlePluginsInstall(path string) {
+	p, err := plugins.Install(path)
+	if err != nil {
+		fail("%v", err)
+	}
+	if jsonOutput {
+		printJSON(p)
+		return
+	}
+	fmt.Printf("Installed %s with %d actions\n", p.Name, len(p.Actions))
+}
@@ -0,0 +1,113 @@
+package cli
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+	"maily/config"
+	"maily/internal/auth"
+	"maily/internal/cache"
+	"maily/internal/export"
+)
+
+var (
+	printAccount string
+	printFolder  string
+	printFormat  string
+	printOutput  string
+)
+
+var printCmd = &cobra.Command{
+	Use:   "print <uid|message-id>",
+	Short: "Save an email to a text, HTML or PDF file",
+	Long: `Save an email's headers, body and attachment list to a file for archiving.
+
+The format, output directory and converter come from the print section of config.yml:
+
+  print:
+    format: pdf
+    command: wkhtmltopdf {input} {output}
+    dir: ~/Documents/mail
+
+Text and HTML are written directly. Any other format runs the command with {input}
+replaced by an HTML rendering of the email and {output} by the file to write.
+Files go to ~/Downloads/maily unless dir or --output says otherwise.`,
+	Example: `  maily print 4821
+  maily print -a me@gmail.com --folder "[Gmail]/Sent Mail" 1093 --format html
+  maily print "<CAF=abc@mail.gmail.com>" --format pdf -o invoice.pdf`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		handlePrint(args[0])
+	},
+}
+
+func init() {
+	printCmd.Flags().StringVarP(&printAccount, "account", "a", "", "Account the email belongs to (defaults to the first account)")
+	printCmd.Flags().StringVar(&printFolder, "folder", "INBOX", "Folder the email is in")
+	printCmd.Flags().StringVar(&printFormat, "format", "", "Output format: text, html, or one made by print.command such as pdf")
+	printCmd.Flags().StringVarP(&printOutput, "output", "o", "", "File to write instead of a name made from the date and subject")
+	rootCmd.AddCommand(printCmd)
+}
+
+func handlePrint(id string) {
+	store, err := auth.LoadAccountStore()
+	if err != nil {
+		fail("%v", err)
+	}
+	account, err := resolveAccount(store, printAccount)
+	if err != nil {
+		fail("%v", err)
+	}
+
+	cfg, err := config.Load()
+	if err != nil {
+		fail("loading config: %v", err)
+	}
+	var printCfg config.PrintConfig
+	if cfg.Print != nil {
+		printCfg = *cfg.Print
+	}
+	if printFormat != "" {
+		printCfg.Format = printFormat
+	}
+
+	r := &emailReader{account: account, mailbox: printFolder}
+	r.cache, _ = cache.New() // fall back to IMAP only if the cache is unavailable
+	defer r.Close()
+
+	uid, err := r.resolveUID(id)
+	if err != nil {
+		exitReadError(r, err)
+	}
+	email, err := r.load(uid)
+	if err != nil {
+		exitReadError(r, err)
+	}
+
+	path, err := export.Print(exportEmail(email), &printCfg, printOutput)
+	if err != nil {
+		fail("%v", err)
+	}
+
+	if jsonOutput {
+		printJSON(map[string]any{"uid": uid, "format": printCfg.OutputFormat(), "path": path})
+		return
+	}
+	fmt.Println(path)
+}
+
+func exportEmail(email *cache.CachedEmail) export.Email {
+	e := export.Email{
+		From:      email.From,
+		To:        email.To,
+		Cc:        email.Cc,
+		Subject:   email.Subject,
+		Date:      email.Date,
+		MessageID: email.MessageID,
+		BodyHTML:  email.BodyHTML,
+	}
+	for _, att := range email.Attachments {
+		e.Attachments = append(e.Attachments, export.Attachment{Filename: att.Filename, Size: att.Size})
+	}
+	return e
+}
@@ -0,0 +1,233 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/emersion/go-imap/v2"
+	"github.com/spf13/cobra"
+	"maily/internal/auth"
+	"maily/internal/cache"
+	"maily/internal/htmlbody"
+	"maily/internal/mail"
+)
+
+var (
+	readRaw  bool
+	readText bool
+	readHTML bool
+)
+
+var readCmd = &cobra.Command{
+	Use:   "read <account> <folder> <uid|message-id>",
+	Short: "Print an email to stdout",
+	Long: `Print a single email for piping into grep, less, or other scripts.
+
+The email is read from the local cache, falling back to IMAP when it isn't cached.
+By default the headers and a plain-text body are printed.`,
+	Example: `  maily read me@gmail.com INBOX 4821
+  maily read me@gmail.com INBOX "<CAF=abc@mail.gmail.com>" --raw > message.eml
+  maily read me@gmail.com INBOX 4821 --html | w3m -T text/html`,
+	Args: cobra.ExactArgs(3),
+	Run: func(cmd *cobra.Command, args []string) {
+		handleRead(args[0], args[1], args[2])
+	},
+}
+
+func init() {
+	readCmd.Flags().BoolVar(&readRaw, "raw", false, "Print the original RFC 822 source (always fetched from IMAP)")
+	readCmd.Flags().BoolVar(&readText, "text", false, "Print headers and a plain-text body (default)")
+	readCmd.Flags().BoolVar(&readHTML, "html", false, "Print only the HTML body")
+	readCmd.MarkFlagsMutuallyExclusive("raw", "text", "html")
+	rootCmd.AddCommand(readCmd)
+}
+
+// emailReader reads one email from the cache and lazily connects to IMAP when needed
+type emailReader struct {
+	account *auth.Account
+	mailbox string
+	cache   *cache.Cache
+	imap    mail.Client
+}
+
+func (r *emailReader) client() (mail.Client, error) {
+	if r.imap == nil {
+		c, err := mail.NewClient(&r.account.Credentials)
+		if err != nil {
+			return nil, err
+		}
+		r.imap = c
+	}
+	return r.imap, nil
+}
+
+func (r *emailReader) Close() {
+	if r.imap != nil {
+		r.imap.Close()
+	}
+	if r.cache != nil {
+		r.cache.Close()
+	}
+}
+
+// resolveUID parses a numeric UID or looks up a Message-ID in the cache, then on the server
+func (r *emailReader) resolveUID(id string) (imap.UID, error) {
+	if n, err := strconv.ParseUint(id, 10, 32); err == nil {
+		return imap.UID(n), nil
+	}
+
+	messageID := strings.Trim(strings.TrimSpace(id), "<>")
+	if r.cache != nil {
+		if uid, ok, err := r.cache.FindUIDByMessageID(r.account.Credentials.Email, r.mailbox, messageID); err == nil && ok {
+			return uid, nil
+		}
+	}
+	client, err := r.client()
+	if err != nil {
+		return 0, err
+	}
+	return client.FindUIDByMessageID(r.mailbox, messageID)
+}
+
+// load returns the email with its body, from cache when possible
+func (r *emailReader) load(uid imap.UID) (*cache.CachedEmail, error) {
+	var email *cache.CachedEmail
+	if r.cache != nil {
+		email, _ = r.cache.GetEmail(r.account.Credentials.Email, r.mailbox, uid)
+	}
+	if email != nil && email.BodyHTML != "" {
+		return email, nil
+	}
+
+	client, err := r.client()
+	if err != nil {
+		return nil, err
+	}
+	if email == nil {
+		emails, err := client.FetchByUIDs(r.mailbox, []imap.UID{uid})
+		if err != nil {
+			return nil, err
+		}
+		if len(emails) == 0 {
+			return nil, mail.ErrEmailNotFound
+		}
+		e := emails[0]
+		email = &cache.CachedEmail{
+			UID:        e.UID,
+			MessageID:  e.MessageID,
+			From:       e.From,
+			ReplyTo:    e.ReplyTo,
+			To:         e.To,
+			Cc:         e.Cc,
+			Subject:    e.Subject,
+			Date:       e.Date,
+			Unread:     e.Unread,
+			References: e.References,
+
+			ListID:              e.ListID,
+			ListUnsubscribe:     e.ListUnsubscribe,
+			ListUnsubscribePost: e.ListUnsubscribePost,
+		}
+		for _, att := range e.Attachments {
+			email.Attachments = append(email.Attachments, cache.Attachment{
+				PartID:      att.PartID,
+				Filename:    att.Filename,
+				ContentType: att.ContentType,
+				Size:        att.Size,
+				Encoding:    att.Encoding,
+			})
+		}
+	}
+
+	email.BodyHTML, email.Snippet, err = client.FetchEmailBody(r.mailbox, uid)
+	if err != nil {
+		return nil, err
+	}
+	return email, nil
+}
+
+func handleRead(accountEmail, mailbox, id string) {
+	store, err := auth.LoadAccountStore()
+	if err != nil {
+		fail("%v", err)
+	}
+	account, err := resolveAccount(store, accountEmail)
+	if err != nil {
+		fail("%v", err)
+	}
+
+	r := &emailReader{account: account, mailbox: mailbox}
+	r.cache, _ = cache.New() // fall back to IMAP only if the cache is unavailable
+	defer r.Close()
+
+	uid, err := r.resolveUID(id)
+	if err != nil {
+		exitReadError(r, err)
+	}
+
+	if readRaw {
+		client, err := r.client()
+		if err != nil {
+			exitReadError(r, err)
+		}
+		raw, err := client.FetchRaw(mailbox, uid)
+		if err != nil {
+			exitReadError(r, err)
+		}
+		os.Stdout.Write(raw)
+		return
+	}
+
+	email, err := r.load(uid)
+	if err != nil {
+		exitReadError(r, err)
+	}
+
+	switch {
+	case jsonOutput:
+		printJSON(email)
+	case readHTML:
+		fmt.Println(email.BodyHTML)
+	default:
+		printEmailText(email)
+	}
+}
+
+// exitReadError exits with exitNoResults when the email doesn't exist, exitError otherwise
+func exitReadError(r *emailReader, err error) {
+	if errors.Is(err, mail.ErrEmailNotFound) {
+		r.Close()
+		if jsonOutput {
+			printJSON(map[string]string{"error": err.Error()})
+		}
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(exitNoResults)
+	}
+	r.Close()
+	fail("%v", err)
+}
+
+// printEmailText prints headers followed by the body as plain text
+func printEmailText(email *cache.CachedEmail) {
+	fmt.Printf("From: %s\n", email.From)
+	fmt.Printf("To: %s\n", email.To)
+	if email.Cc != "" {
+		fmt.Printf("Cc: %s\n", email.Cc)
+	}
+	fmt.Printf("Date: %s\n", email.Date.Format(time.RFC1123Z))
+	fmt.Printf("Subject: %s\n", email.Subject)
+	if email.MessageID != "" {
+		fmt.Printf("Message-ID: <%s>\n", strings.Trim(email.MessageID, "<>"))
+	}
+	fmt.Println()
+
+	if text, ok := mail.UnwrapPlainText(email.BodyHTML); ok {
+		fmt.Println(strings.TrimRight(text, "\n"))
+		return
+	}
+	fmt.Println(strings.TrimSpace(htmlbody.ToMarkdown(email.BodyHTML)))
+}
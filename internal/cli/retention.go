@@ -0,0 +1,120 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/spf13/cobra"
+	"maily/config"
+	"maily/internal/auth"
+	"maily/internal/cache"
+	"maily/internal/retention"
+)
+
+var retentionAccount string
+
+var retentionCmd = &cobra.Command{
+	Use:   "retention",
+	Short: "Preview what retention rules would clean up (dry run)",
+	Long: `Evaluate the retention rules in config.yml against cached mail and list what each
+rule would delete or archive. Nothing is changed.
+
+Rules run during background sync only once they have "enabled: true". Example rule:
+
+  retention:
+    - name: old promos
+      action: delete        # delete (move to trash) or archive
+      older_than_days: 30
+      from: deals@shop.example.com
+      enabled: false`,
+	Example: `  maily retention
+  maily retention -a me@gmail.com --json`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		handleRetention()
+	},
+}
+
+func init() {
+	retentionCmd.Flags().StringVarP(&retentionAccount, "account", "a", "", "Only evaluate rules for this account")
+	rootCmd.AddCommand(retentionCmd)
+}
+
+func handleRetention() {
+	cfg, err := config.Load()
+	if err != nil {
+		fail("loading config: %v", err)
+	}
+	if len(cfg.Retention) == 0 {
+		fail("no retention rules configured - add retention to config.yml")
+	}
+	for _, rule := range cfg.Retention {
+		if err := retention.Validate(rule); err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: skipping %v\n", err)
+		}
+	}
+
+	store, err := auth.LoadAccountStore()
+	if err != nil {
+		fail("%v", err)
+	}
+	accounts := store.Filter(cfg.AccountActive).Accounts
+	if retentionAccount != "" {
+		account, err := resolveAccount(store, retentionAccount)
+		if err != nil {
+			fail("%v", err)
+		}
+		accounts = []auth.Account{*account}
+	}
+
+	c, err := cache.New()
+	if err != nil {
+		fail("opening cache: %v", err)
+	}
+	defer c.Close()
+
+	now := time.Now()
+	matches := []retention.Match{}
+	for _, account := range accounts {
+		found, err := retention.Evaluate(c, account.Credentials.Email, cfg.Retention, now)
+		if err != nil {
+			c.Close()
+			fail("%s: %v", account.Credentials.Email, err)
+		}
+		matches = append(matches, found...)
+	}
+
+	if jsonOutput {
+		printJSON(matches)
+	} else {
+		printRetentionReport(cfg.Retention, matches)
+	}
+	if len(matches) == 0 {
+		c.Close()
+		os.Exit(exitNoResults)
+	}
+}
+
+func printRetentionReport(rules []config.RetentionRule, matches []retention.Match) {
+	for _, rule := range rules {
+		if retention.Validate(rule) != nil {
+			continue
+		}
+		var ruleMatches []retention.Match
+		for _, m := range matches {
+			if m.Rule == rule.Name {
+				ruleMatches = append(ruleMatches, m)
+			}
+		}
+
+		status := "disabled"
+		if rule.Enabled {
+			status = "enabled"
+		}
+		fmt.Printf("%s (%s after %d days, %s): %d emails\n", rule.Name, rule.Action, rule.OlderThanDays, status, len(ruleMatches))
+		for _, m := range ruleMatches {
+			fmt.Printf("  %s  %-24s  %-30s  %s\n", m.Email.Date.Format("2006-01-02"), m.Account, truncate(m.Email.From, 30), m.Email.Subject)
+		}
+	}
+}
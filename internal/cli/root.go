@@ -6,9 +6,12 @@ import (
 
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/spf13/cobra"
+	"golang.org/x/term"
 	"maily/config"
 	"maily/internal/auth"
 	"maily/internal/i18n"
+	"maily/internal/logging"
+	"maily/internal/mail"
 	"maily/internal/ui"
 )
 
@@ -16,12 +19,35 @@ var rootCmd = &cobra.Command{
 	Use:   "maily",
 	Short: "A handy CLI email client in your terminal",
 	Long:  "maily - A handy CLI email client in your terminal",
+	Args:  rootArgs,
 	Run: func(cmd *cobra.Command, args []string) {
-		runTUI()
+		if len(args) == 1 {
+			draft, err := parseMailto(args[0])
+			if err != nil {
+				fail("%v", err)
+			}
+			runTUI(&draft)
+			return
+		}
+		runTUI(nil)
 	},
 }
 
+// rootArgs accepts a single mailto: URL so maily can be the system mail handler
+func rootArgs(cmd *cobra.Command, args []string) error {
+	if len(args) == 1 && mail.IsMailto(args[0]) {
+		return nil
+	}
+	if len(args) > 0 {
+		return fmt.Errorf("unknown command %q for %q", args[0], cmd.CommandPath())
+	}
+	return nil
+}
+
+var profileFlag []string
+
 func Execute() error {
+	localizeHelp(rootCmd)
 	return rootCmd.Execute()
 }
 
@@ -35,9 +61,13 @@ func init() {
 	rootCmd.AddCommand(versionCmd)
 	rootCmd.AddCommand(updateCmd)
 	rootCmd.AddCommand(configCmd)
+	rootCmd.AddCommand(logsCmd)
+
+	rootCmd.Flags().StringSliceVar(&profileFlag, "profile", nil, "Switch to account profiles (comma-separated, or 'all')")
 }
 
-func runTUI() {
+// runTUI starts the main TUI, or the compose view when draft is set
+func runTUI(draft *ui.ComposeDraft) {
 	store, err := auth.LoadAccountStore()
 	if err != nil {
 		fmt.Printf("%s\n", i18n.T("cli.error_loading_accounts", map[string]any{"Error": err}))
@@ -45,24 +75,55 @@ func runTUI() {
 	}
 
 	if len(store.Accounts) == 0 {
-		fmt.Println(i18n.T("cli.no_accounts"))
-		fmt.Println(i18n.T("cli.login_hint"))
-		fmt.Println()
-		os.Exit(1)
+		// First run: set up an account interactively, then carry on into the inbox
+		interactive := term.IsTerminal(int(os.Stdin.Fd())) && term.IsTerminal(int(os.Stdout.Fd()))
+		if !interactive || !runOnboarding() {
+			fmt.Println(i18n.T("cli.no_accounts"))
+			fmt.Println(i18n.T("cli.login_hint"))
+			fmt.Println()
+			os.Exit(1)
+		}
+		if store, err = auth.LoadAccountStore(); err != nil {
+			fmt.Printf("%s\n", i18n.T("cli.error_loading_accounts", map[string]any{"Error": err}))
+			os.Exit(1)
+		}
 	}
 
 	// Load config
-	cfg, err := config.Load()
+	cfg, warnings, err := config.LoadWithWarnings()
 	if err != nil {
 		fmt.Printf("%s\n", i18n.T("cli.error_loading_config", map[string]any{"Error": err}))
 		os.Exit(1)
 	}
+	for _, problem := range warnings {
+		fmt.Fprintf(os.Stderr, "Warning: config.yml: %s (using the default)\n", problem)
+	}
 
 	// Initialize i18n with configured language
 	if err := i18n.Init(cfg.Language); err != nil {
 		// Non-fatal: fall back to English if i18n fails
 		fmt.Printf("Warning: i18n initialization failed: %v\n", err)
 	}
+	i18n.SetDateFormat(cfg.Dates.Relative(), cfg.Dates.ClockHours())
+	i18n.SetWeekStart(cfg.Calendar.FirstWeekday())
+
+	// Log to file only - stdout belongs to the TUI
+	if closer, err := logging.Setup(logging.TUILog, cfg.Logging, nil); err == nil {
+		defer closer.Close()
+	}
+
+	// Persist the profile switch, the one shown next time. The server serves every
+	// account whatever the profile.
+	if len(profileFlag) > 0 {
+		if err := cfg.SetActiveProfiles(profileFlag); err != nil {
+			fmt.Printf("Error: %v\n", err)
+			os.Exit(1)
+		}
+		if err := cfg.Save(); err != nil {
+			fmt.Printf("Error saving config: %v\n", err)
+			os.Exit(1)
+		}
+	}
 
 	// Auto-start server if not running
 	if err := startServerBackground(); err != nil {
@@ -70,13 +131,29 @@ func runTUI() {
 		fmt.Printf("Warning: failed to start server: %v\n", err)
 	}
 
+	store = store.Filter(cfg.AccountActive)
+	if len(store.Accounts) == 0 {
+		fmt.Println(i18n.T("cli.no_profile_accounts", map[string]any{"Profiles": cfg.ActiveProfileLabel()}))
+		fmt.Println(i18n.T("cli.profile_hint"))
+		os.Exit(1)
+	}
+
 	// Loop to allow returning from config TUI back to main app
 	for {
-		p := tea.NewProgram(
-			ui.NewApp(store, &cfg),
-			tea.WithAltScreen(),
-			tea.WithMouseCellMotion(),
-		)
+		app := ui.NewApp(store, &cfg)
+		opts := []tea.ProgramOption{tea.WithAltScreen(), tea.WithMouseCellMotion()}
+		if draft != nil {
+			if app, err = app.StartCompose(*draft); err != nil {
+				fmt.Printf("Error: %v\n", err)
+				os.Exit(1)
+			}
+			// The body may have been piped in on stdin
+			if !term.IsTerminal(int(os.Stdin.Fd())) {
+				opts = append(opts, tea.WithInputTTY())
+			}
+			draft = nil // only the first run opens compose
+		}
+		p := tea.NewProgram(app, opts...)
 
 		m, err := p.Run()
 		if err != nil {
@@ -84,6 +161,11 @@ func runTUI() {
 			os.Exit(1)
 		}
 
+		// Reopen here next time, and after the config or re-login below
+		if app, ok := m.(ui.App); ok {
+			app.SaveSession()
+		}
+
 		// Check if we should launch config TUI (e.g., for AI setup)
 		if app, ok := m.(ui.App); ok && app.LaunchConfigUI {
 			if err := RunConfigTUI(); err != nil {
@@ -99,6 +181,17 @@ func runTUI() {
 			continue
 		}
 
+		// Re-enter a revoked password, then restart main TUI with the updated store
+		if app, ok := m.(ui.App); ok && app.ReauthEmail != "" {
+			reauthAccount(app.ReauthEmail)
+			if store, err = auth.LoadAccountStore(); err != nil {
+				fmt.Printf("%s\n", i18n.T("cli.error_loading_accounts", map[string]any{"Error": err}))
+				os.Exit(1)
+			}
+			store = store.Filter(cfg.AccountActive)
+			continue
+		}
+
 		// Normal exit
 		break
 	}
@@ -8,6 +8,7 @@ import (
 
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
+	"github.com/mattn/go-runewidth"
 	"github.com/spf13/cobra"
 	"maily/internal/auth"
 	"maily/internal/client"
@@ -26,12 +27,12 @@ var (
 )
 
 var searchCmd = &cobra.Command{
-	Use:   "search",
+	Use:   "search [query]",
 	Short: "Search emails",
 	Long: `Search emails in your mailbox.
 
-By default, launches an interactive TUI. Use --format to get non-interactive output
-suitable for scripting and piping to other commands.
+By default, launches an interactive TUI. Pass the query as an argument, or use
+--format, to get non-interactive output suitable for scripting and cron jobs.
 
 For Gmail accounts, full Gmail search syntax is supported:
   from:sender@example.com    Emails from a sender
@@ -54,6 +55,9 @@ For other providers (Yahoo, etc.), basic text search is used:
 	Example: `  # Interactive TUI search
   maily search -a me@gmail.com -q "from:temu"
 
+  # Headless: print results to stdout
+  maily search "from:boss is:unread" --account me@gmail.com
+
   # Non-interactive: get count only
   maily search -q "from:temu" --count
 
@@ -63,8 +67,16 @@ For other providers (Yahoo, etc.), basic text search is used:
 
   # Non-interactive: table output
   maily search -q "is:unread" --format=table`,
+	Args: cobra.MaximumNArgs(1),
 	Run: func(cmd *cobra.Command, args []string) {
-		handleSearch(cmd)
+		if len(args) == 1 {
+			searchQuery = args[0]
+		}
+		if searchQuery == "" {
+			fail("a search query is required (argument or --query)")
+		}
+		initLanguage()
+		handleSearch(cmd, len(args) == 1)
 	},
 }
 
@@ -75,23 +87,34 @@ func init() {
 	searchCmd.Flags().IntVar(&searchOffset, "offset", 0, "Skip first N results for pagination")
 	searchCmd.Flags().BoolVar(&searchCount, "count", false, "Only return the count, don't fetch emails")
 	searchCmd.Flags().StringVar(&searchFormat, "format", "", "Output format: json or table (non-interactive)")
-	searchCmd.MarkFlagRequired("query")
 }
 
-func handleSearch(cmd *cobra.Command) {
+func handleSearch(cmd *cobra.Command, headless bool) {
+	if jsonOutput {
+		searchFormat = "json"
+	} else if headless && searchFormat == "" {
+		searchFormat = "table"
+	}
+
 	// Auto-start server if not running
 	if err := startServerBackground(); err != nil {
 		// Non-fatal for TUI mode, but non-interactive mode requires server
-		fmt.Printf("Warning: failed to start server: %v\n", err)
+		fmt.Fprintf(os.Stderr, "Warning: failed to start server: %v\n", err)
 	}
 
 	store, err := auth.LoadAccountStore()
 	if err != nil {
+		if jsonOutput {
+			fail("%v", err)
+		}
 		fmt.Printf("%s\n", i18n.T("cli.error_loading_accounts", map[string]any{"Error": err}))
 		os.Exit(1)
 	}
 
 	if len(store.Accounts) == 0 {
+		if jsonOutput {
+			fail("no accounts configured")
+		}
 		fmt.Println(i18n.T("cli.no_accounts"))
 		fmt.Println(i18n.T("cli.login_hint"))
 		fmt.Println()
@@ -103,6 +126,9 @@ func handleSearch(cmd *cobra.Command) {
 		if len(store.Accounts) == 1 {
 			account = &store.Accounts[0]
 		} else {
+			if jsonOutput {
+				fail("--account (-a) required")
+			}
 			fmt.Printf("%s: %s\n", i18n.T("common.error"), "--account (-a) required")
 			fmt.Println()
 			fmt.Println(i18n.T("cli.available_providers"))
@@ -114,6 +140,9 @@ func handleSearch(cmd *cobra.Command) {
 	} else {
 		account = store.GetAccount(searchAccount)
 		if account == nil {
+			if jsonOutput {
+				fail("account not found: %s", searchAccount)
+			}
 			fmt.Printf("%s\n", i18n.T("cli.account_not_found", map[string]any{"Email": searchAccount}))
 			fmt.Println()
 			fmt.Println(i18n.T("cli.available_providers"))
@@ -125,7 +154,8 @@ func handleSearch(cmd *cobra.Command) {
 	}
 
 	// Non-interactive mode: any of --count, --format, --limit, --offset specified
-	isNonInteractive := searchCount ||
+	isNonInteractive := headless ||
+		searchCount ||
 		searchFormat != "" ||
 		cmd.Flags().Changed("limit") ||
 		cmd.Flags().Changed("offset")
@@ -168,24 +198,23 @@ type SearchResponse struct {
 	Results []SearchResult `json:"results"`
 }
 
+// handleNonInteractiveSearch prints results for scripting; exits with
+// exitNoResults when nothing matched so callers can tell it apart from errors
 func handleNonInteractiveSearch(account *auth.Account) {
 	// Validate format if specified
 	if searchFormat != "" && searchFormat != "json" && searchFormat != "table" {
-		fmt.Fprintf(os.Stderr, "Error: invalid format '%s'. Use 'json' or 'table'\n", searchFormat)
-		os.Exit(1)
+		fail("invalid format '%s'. Use 'json' or 'table'", searchFormat)
 	}
 
 	serverClient, err := client.Connect()
 	if err != nil {
-		fmt.Fprintf(os.Stderr, "Error connecting to server: %v\n", err)
-		os.Exit(1)
+		fail("connecting to server: %v", err)
 	}
 	defer serverClient.Close()
 
 	cached, err := serverClient.Search(account.Credentials.Email, "INBOX", searchQuery)
 	if err != nil {
-		fmt.Fprintf(os.Stderr, "Error searching: %v\n", err)
-		os.Exit(1)
+		fail("searching: %v", err)
 	}
 
 	total := len(cached)
@@ -198,6 +227,10 @@ func handleNonInteractiveSearch(account *auth.Account) {
 		} else {
 			fmt.Println(total)
 		}
+		if total == 0 {
+			serverClient.Close()
+			os.Exit(exitNoResults)
+		}
 		return
 	}
 
@@ -238,15 +271,16 @@ func handleNonInteractiveSearch(account *auth.Account) {
 
 	// Output based on format
 	switch searchFormat {
-	case "json":
-		output, _ := json.MarshalIndent(response, "", "  ")
-		fmt.Println(string(output))
 	case "table":
 		outputTable(response)
 	default:
 		// Default to JSON when format not specified but non-interactive
-		output, _ := json.MarshalIndent(response, "", "  ")
-		fmt.Println(string(output))
+		printJSON(response)
+	}
+
+	if total == 0 {
+		serverClient.Close()
+		os.Exit(exitNoResults)
 	}
 }
 
@@ -323,8 +357,5 @@ func truncateSnippet(s string, maxLen int) string {
 	s = strings.ReplaceAll(s, "\n", " ")
 	s = strings.ReplaceAll(s, "\r", "")
 	s = strings.TrimSpace(s)
-	if len(s) > maxLen {
-		return s[:maxLen-3] + "..."
-	}
-	return s
+	return runewidth.Truncate(s, maxLen, "...")
 }
@@ -0,0 +1,140 @@
+package cli
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/spf13/cobra"
+	"maily/internal/auth"
+	"maily/internal/mail"
+)
+
+var (
+	sendAccount  string
+	sendTo       string
+	sendSubject  string
+	sendBody     string
+	sendBodyFile string
+	sendAttach   []string
+)
+
+var sendCmd = &cobra.Command{
+	Use:   "send",
+	Short: "Send an email without opening the TUI",
+	Long:  "Send a plain-text email over SMTP, for scripts and cron jobs. Uses the same SMTP path as the compose view.",
+	Example: `  maily send --to boss@corp.com --subject "Weekly report" --body-file report.txt --attach report.pdf
+  echo "Backup finished" | maily send -a me@gmail.com --to me@gmail.com --subject "cron" --body-file -`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		handleSend()
+	},
+}
+
+func init() {
+	sendCmd.Flags().StringVarP(&sendAccount, "account", "a", "", "Account to send from (required if multiple accounts)")
+	sendCmd.Flags().StringVar(&sendTo, "to", "", "Recipients, comma-separated")
+	sendCmd.Flags().StringVar(&sendSubject, "subject", "", "Subject line")
+	sendCmd.Flags().StringVar(&sendBody, "body", "", "Message body")
+	sendCmd.Flags().StringVar(&sendBodyFile, "body-file", "", "Read the message body from a file ('-' for stdin)")
+	sendCmd.Flags().StringArrayVar(&sendAttach, "attach", nil, "Attach a file (repeatable)")
+	sendCmd.MarkFlagRequired("to")
+	rootCmd.AddCommand(sendCmd)
+}
+
+func handleSend() {
+	store, err := auth.LoadAccountStore()
+	if err != nil {
+		fail("%v", err)
+	}
+	account, err := resolveAccount(store, sendAccount)
+	if err != nil {
+		fail("%v", err)
+	}
+
+	body := sendBody
+	if sendBodyFile != "" {
+		if sendBody != "" {
+			fail("use either --body or --body-file, not both")
+		}
+		if body, err = readBody(sendBodyFile); err != nil {
+			fail("reading body: %v", err)
+		}
+	}
+
+	attachments, err := loadAttachments(sendAttach, account)
+	if err != nil {
+		fail("%v", err)
+	}
+
+	smtpClient := mail.NewSMTPClient(&account.Credentials)
+	if err := smtpClient.SendWithAttachments(sendTo, sendSubject, body, attachments); err != nil {
+		fail("sending: %v", err)
+	}
+
+	if jsonOutput {
+		printJSON(map[string]any{
+			"sent":        true,
+			"from":        account.Credentials.Email,
+			"to":          sendTo,
+			"subject":     sendSubject,
+			"attachments": len(attachments),
+		})
+		return
+	}
+	fmt.Printf("Sent to %s\n", sendTo)
+}
+
+// resolveAccount returns the named account, or the only account when email is empty
+func resolveAccount(store *auth.AccountStore, email string) (*auth.Account, error) {
+	if len(store.Accounts) == 0 {
+		return nil, fmt.Errorf("no accounts configured - run 'maily login' first")
+	}
+	if email == "" {
+		if len(store.Accounts) > 1 {
+			return nil, fmt.Errorf("--account (-a) required when multiple accounts are configured")
+		}
+		return &store.Accounts[0], nil
+	}
+	account := store.GetAccount(email)
+	if account == nil {
+		return nil, fmt.Errorf("account not found: %s", email)
+	}
+	return account, nil
+}
+
+func readBody(path string) (string, error) {
+	if path == "-" {
+		data, err := io.ReadAll(os.Stdin)
+		return string(data), err
+	}
+	data, err := os.ReadFile(path)
+	return string(data), err
+}
+
+// loadAttachments stats each file and enforces the account's size limit, like the compose view
+func loadAttachments(paths []string, account *auth.Account) ([]mail.AttachmentFile, error) {
+	limit := mail.AttachmentLimit(account)
+	var attachments []mail.AttachmentFile
+	var total int64
+	for _, path := range paths {
+		info, err := os.Stat(path)
+		if err != nil {
+			return nil, err
+		}
+		if info.IsDir() {
+			return nil, fmt.Errorf("%s is a directory", path)
+		}
+		total += info.Size()
+		if total > limit {
+			return nil, fmt.Errorf("total attachments exceed the %d MB limit - %s", limit/(1024*1024), mail.AttachmentAdvice(account.Provider))
+		}
+		attachments = append(attachments, mail.AttachmentFile{
+			Path: path,
+			Name: filepath.Base(path),
+			Size: info.Size(),
+		})
+	}
+	return attachments, nil
+}
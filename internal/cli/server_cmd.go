@@ -12,7 +12,9 @@ import (
 
 	"github.com/spf13/cobra"
 
+	"maily/config"
 	"maily/internal/client"
+	"maily/internal/logging"
 	"maily/internal/proc"
 	"maily/internal/server"
 	"maily/internal/version"
@@ -48,19 +50,56 @@ var serverStopCmd = &cobra.Command{
 	},
 }
 
+var serverEnableCmd = &cobra.Command{
+	Use:   "enable",
+	Short: "Start the server when you log in",
+	Run: func(cmd *cobra.Command, args []string) {
+		path, err := enableAutostart()
+		if err != nil {
+			fail("%v", err)
+		}
+		fmt.Printf("The server will start at login (%s)\n", path)
+	},
+}
+
+var serverDisableCmd = &cobra.Command{
+	Use:   "disable",
+	Short: "Stop starting the server when you log in",
+	Run: func(cmd *cobra.Command, args []string) {
+		path, err := disableAutostart()
+		if err != nil {
+			fail("%v", err)
+		}
+		fmt.Printf("The server no longer starts at login (removed %s)\n", path)
+	},
+}
+
 func init() {
 	serverCmd.AddCommand(serverStartCmd)
 	serverCmd.AddCommand(serverStatusCmd)
 	serverCmd.AddCommand(serverStopCmd)
+	serverCmd.AddCommand(serverEnableCmd)
+	serverCmd.AddCommand(serverDisableCmd)
 	rootCmd.AddCommand(serverCmd)
 }
 
 // runServer starts the server in foreground
 func runServer() {
 	if server.IsServerRunning() {
-		fmt.Println("Server is already running.")
-		fmt.Println("Use 'maily server stop' to stop it first.")
-		os.Exit(1)
+		// A server of another version is taken over (see server.New); ours is left alone
+		if running, _, ver := isServerRunning(); !running || ver == version.Version {
+			fmt.Println("Server is already running.")
+			fmt.Println("Use 'maily server stop' to stop it first.")
+			os.Exit(1)
+		}
+	}
+
+	// Log to file, and mirror to stderr when run in the foreground
+	cfg, _ := config.Load()
+	if closer, err := logging.Setup(logging.ServerLog, cfg.Logging, os.Stderr); err != nil {
+		fmt.Printf("Warning: failed to set up logging: %v\n", err)
+	} else {
+		defer closer.Close()
 	}
 
 	srv, err := server.New()
@@ -104,10 +143,31 @@ func isServerRunning() (bool, int, string) {
 	return true, pid, ver
 }
 
+// serverStatusJSON is the --json output of 'maily server status'
+type serverStatusJSON struct {
+	Running  bool                 `json:"running"`
+	PID      int                  `json:"pid,omitempty"`
+	Version  string               `json:"version,omitempty"`
+	Socket   string               `json:"socket"`
+	Accounts []server.AccountInfo `json:"accounts,omitempty"`
+}
+
 // checkServerStatus shows server status and info
 func checkServerStatus() {
 	running, pid, ver := isServerRunning()
 
+	if jsonOutput {
+		status := serverStatusJSON{Running: running, PID: pid, Version: ver, Socket: server.GetSocketPath()}
+		if running {
+			if c, err := client.Connect(); err == nil {
+				status.Accounts, _ = c.GetAccounts()
+				c.Close()
+			}
+		}
+		printJSON(status)
+		return
+	}
+
 	if running {
 		fmt.Printf("Server is running (PID: %d", pid)
 		if ver != "" {
@@ -175,24 +235,18 @@ func stopServer() {
 	fmt.Printf("Server stopped (PID: %d)\n", pid)
 }
 
-// startServerBackground starts the server in background
+// startServerBackground starts the server in background. A running server of another
+// version hands over to the new one without disconnecting clients for long.
 func startServerBackground() error {
-	// Check for version mismatch FIRST (before checking if server is running)
-	running, pid, serverVer := isServerRunning()
+	unlock, err := server.LockStartup()
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	running, _, serverVer := isServerRunning()
 	if running && serverVer != version.Version {
-		// Version mismatch - stop old server so we can start our version
-		if process, err := os.FindProcess(pid); err == nil {
-			process.Signal(syscall.SIGTERM)
-			// Wait for graceful shutdown
-			for i := 0; i < 20; i++ { // 2 seconds max
-				time.Sleep(100 * time.Millisecond)
-				if !server.IsServerRunning() {
-					break
-				}
-			}
-		}
-		os.Remove(server.GetPidPath())
-		os.Remove(server.GetSocketPath())
+		return handoffServer(serverVer)
 	}
 
 	// Now check if a compatible server is already running
@@ -200,6 +254,42 @@ func startServerBackground() error {
 		return nil // Already running with matching version
 	}
 
+	if err := spawnServer(); err != nil {
+		return err
+	}
+
+	// Wait for socket to be ready
+	sockPath := server.GetSocketPath()
+	for i := 0; i < 50; i++ { // 5 seconds max
+		time.Sleep(100 * time.Millisecond)
+		if _, err := os.Stat(sockPath); err == nil {
+			return nil
+		}
+	}
+
+	return fmt.Errorf("server failed to start within timeout")
+}
+
+// handoffServer starts the server of the executable on disk, which asks the running one
+// (of version from) to drain and takes over its socket; connected clients reconnect to it
+func handoffServer(from string) error {
+	if err := spawnServer(); err != nil {
+		return err
+	}
+
+	// The PID file carries the new version once it is listening
+	for i := 0; i < 100; i++ { // 10 seconds max
+		time.Sleep(100 * time.Millisecond)
+		if running, _, ver := isServerRunning(); running && ver != from {
+			return nil
+		}
+	}
+
+	return fmt.Errorf("new server failed to take over within timeout")
+}
+
+// spawnServer runs 'maily server start' detached from the terminal
+func spawnServer() error {
 	executable, err := os.Executable()
 	if err != nil {
 		return err
@@ -217,18 +307,5 @@ func startServerBackground() error {
 	cmd.Stdin = nil
 	cmd.SysProcAttr = &syscall.SysProcAttr{Setsid: true}
 
-	if err := cmd.Start(); err != nil {
-		return err
-	}
-
-	// Wait for socket to be ready
-	sockPath := server.GetSocketPath()
-	for i := 0; i < 50; i++ { // 5 seconds max
-		time.Sleep(100 * time.Millisecond)
-		if _, err := os.Stat(sockPath); err == nil {
-			return nil
-		}
-	}
-
-	return fmt.Errorf("server failed to start within timeout")
+	return cmd.Start()
 }
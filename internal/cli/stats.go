@@ -0,0 +1,127 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/spf13/cobra"
+	"maily/internal/cache"
+	"maily/internal/client"
+	"maily/internal/mail"
+)
+
+var (
+	statsAccount string
+	statsFormat  string
+)
+
+var statsCmd = &cobra.Command{
+	Use:   "stats",
+	Short: "Show sync statistics",
+	Long:  "Show per-account sync durations, messages fetched, queue depth, and error rates over the last 7 days, and the traffic of the server's IMAP connections.",
+	Example: `  maily stats
+  maily stats -a me@gmail.com
+  maily stats --format=json`,
+	Run: func(cmd *cobra.Command, args []string) {
+		handleStats()
+	},
+}
+
+func init() {
+	statsCmd.Flags().StringVarP(&statsAccount, "account", "a", "", "Only show stats for this account")
+	statsCmd.Flags().StringVar(&statsFormat, "format", "table", "Output format: json or table")
+	rootCmd.AddCommand(statsCmd)
+}
+
+func handleStats() {
+	if jsonOutput {
+		statsFormat = "json"
+	}
+	if statsFormat != "json" && statsFormat != "table" {
+		fail("invalid format '%s'. Use 'json' or 'table'", statsFormat)
+	}
+
+	if err := startServerBackground(); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: failed to start server: %v\n", err)
+	}
+
+	serverClient, err := client.Connect()
+	if err != nil {
+		fail("connecting to server: %v", err)
+	}
+	defer serverClient.Close()
+
+	stats, err := serverClient.GetStats(statsAccount)
+	if err != nil {
+		fail("getting stats: %v", err)
+	}
+
+	if statsFormat == "json" {
+		printJSON(stats)
+	} else {
+		outputStatsTable(stats)
+	}
+	if len(stats) == 0 {
+		os.Exit(exitNoResults)
+	}
+}
+
+func outputStatsTable(stats []cache.SyncStats) {
+	pad := "  "
+	headerStyle := lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("#D1D5DB"))
+	labelStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("#9CA3AF")).Width(16)
+	errorStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("#EF4444"))
+
+	fmt.Println()
+	for _, s := range stats {
+		fmt.Println(pad + headerStyle.Render(s.Account))
+
+		lastSync := "never"
+		if !s.LastSync.IsZero() {
+			lastSync = fmt.Sprintf("%s (%s, %d fetched)",
+				s.LastSync.Format("2006-01-02 15:04"), s.LastDuration.Round(time.Millisecond), s.LastFetched)
+		}
+		fmt.Println(pad + pad + labelStyle.Render("Last sync") + lastSync)
+		fmt.Println(pad + pad + labelStyle.Render("Syncs (7d)") +
+			fmt.Sprintf("%d, %d failed (%.0f%%)", s.TotalSyncs, s.FailedSyncs, s.ErrorRate()*100))
+		fmt.Println(pad + pad + labelStyle.Render("Avg duration") + s.AvgDuration.Round(time.Millisecond).String())
+		fmt.Println(pad + pad + labelStyle.Render("Fetched (7d)") + fmt.Sprintf("%d", s.TotalFetched))
+		fmt.Println(pad + pad + labelStyle.Render("Queue") +
+			fmt.Sprintf("%d pending, %d failed (7d)", s.PendingOps, s.FailedOps))
+		if c := s.Connection; c != nil {
+			fmt.Println(pad + pad + labelStyle.Render("Connection") + connectionSummary(*c))
+		}
+		if s.LastError != "" {
+			fmt.Println(pad + pad + labelStyle.Render("Last error") + errorStyle.Render(s.LastError))
+		}
+		fmt.Println()
+	}
+}
+
+// connectionSummary describes an IMAP connection's extensions and traffic
+func connectionSummary(c mail.ConnStats) string {
+	var exts []string
+	if c.Compressed {
+		exts = append(exts, "COMPRESS")
+	}
+	if c.LiteralPlus {
+		exts = append(exts, "LITERAL+")
+	}
+	summary := fmt.Sprintf("%s in, %s out since %s", formatBytes(c.BytesIn), formatBytes(c.BytesOut), c.Since.Format("15:04"))
+	if c.Compressed {
+		summary += fmt.Sprintf(" (%s on the wire, %.0f%% saved)", formatBytes(c.WireIn+c.WireOut), c.Saved()*100)
+	}
+	if len(exts) > 0 {
+		summary = strings.Join(exts, " ") + ", " + summary
+	}
+	if c.Throttles > 0 {
+		summary += fmt.Sprintf(", throttled %d times", c.Throttles)
+	}
+	if !c.BackoffUntil.IsZero() {
+		summary += fmt.Sprintf(", backing off until %s", c.BackoffUntil.Format("15:04"))
+	}
+	return summary
+}
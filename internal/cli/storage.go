@@ -0,0 +1,145 @@
+package cli
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/spf13/cobra"
+	"golang.org/x/term"
+	"maily/internal/auth"
+	"maily/internal/client"
+	"maily/internal/server"
+)
+
+var (
+	storageAccount    string
+	storageEmptyTrash bool
+	storageYes        bool
+)
+
+var storageCmd = &cobra.Command{
+	Use:   "storage",
+	Short: "Show mailbox storage usage per account",
+	Long: `Show how much of each account's mailbox storage is used, from the server's QUOTA
+extension, and how many emails sit in the trash. Servers without QUOTA only report the trash.
+
+Gmail's quota is shared with Drive and Photos. Emptying the trash frees the space its
+emails take right away instead of after Gmail's 30 days.`,
+	Example: `  maily storage
+  maily storage --json
+  maily storage -a me@gmail.com --empty-trash`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		handleStorage()
+	},
+}
+
+func init() {
+	storageCmd.Flags().StringVarP(&storageAccount, "account", "a", "", "Only show this account")
+	storageCmd.Flags().BoolVar(&storageEmptyTrash, "empty-trash", false, "Permanently delete the account's trash")
+	storageCmd.Flags().BoolVarP(&storageYes, "yes", "y", false, "Skip confirmation prompt")
+	rootCmd.AddCommand(storageCmd)
+}
+
+func handleStorage() {
+	if err := startServerBackground(); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: failed to start server: %v\n", err)
+	}
+
+	serverClient, err := client.Connect()
+	if err != nil {
+		fail("connecting to server: %v", err)
+	}
+	defer serverClient.Close()
+
+	if storageEmptyTrash {
+		emptyTrash(serverClient)
+		return
+	}
+
+	storage, err := serverClient.GetStorage(storageAccount)
+	if err != nil {
+		fail("getting storage: %v", err)
+	}
+
+	if jsonOutput {
+		if storage == nil {
+			storage = []server.AccountStorage{}
+		}
+		printJSON(storage)
+	} else {
+		outputStorageTable(storage)
+	}
+	if len(storage) == 0 {
+		os.Exit(exitNoResults)
+	}
+}
+
+// emptyTrash empties one account's trash after confirming
+func emptyTrash(serverClient *client.Client) {
+	store, err := auth.LoadAccountStore()
+	if err != nil {
+		fail("%v", err)
+	}
+	account, err := resolveAccount(store, storageAccount)
+	if err != nil {
+		fail("%v", err)
+	}
+	email := account.Credentials.Email
+
+	if !storageYes && term.IsTerminal(int(os.Stdin.Fd())) {
+		reader := bufio.NewReader(os.Stdin)
+		fmt.Printf("Permanently delete every email in the trash of %s? [y/N]: ", email)
+		input, _ := reader.ReadString('\n')
+		input = strings.TrimSpace(strings.ToLower(input))
+		if input != "y" && input != "yes" {
+			fmt.Println("Cancelled")
+			return
+		}
+	}
+
+	count, err := serverClient.EmptyTrash(email)
+	if err != nil {
+		fail("emptying trash: %v", err)
+	}
+	if jsonOutput {
+		printJSON(map[string]any{"account": email, "deleted": count})
+		return
+	}
+	fmt.Printf("Deleted %d emails from the trash of %s\n", count, email)
+}
+
+func outputStorageTable(storage []server.AccountStorage) {
+	pad := "  "
+	headerStyle := lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("#D1D5DB"))
+	labelStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("#9CA3AF")).Width(16)
+	warnStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("#F59E0B"))
+	errorStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("#EF4444"))
+
+	fmt.Println()
+	for _, s := range storage {
+		fmt.Println(pad + headerStyle.Render(s.Account))
+		if s.Error != "" {
+			fmt.Println(pad + pad + labelStyle.Render("Error") + errorStyle.Render(s.Error))
+			fmt.Println()
+			continue
+		}
+
+		usage := "not reported by the server"
+		if s.Quota != nil {
+			usage = fmt.Sprintf("%s of %s (%.0f%%)",
+				formatBytes(s.Quota.UsedBytes), formatBytes(s.Quota.LimitBytes), s.Quota.Percent())
+			if s.Quota.NearlyFull() {
+				usage = warnStyle.Render(usage)
+			}
+		}
+		fmt.Println(pad + pad + labelStyle.Render("Storage") + usage)
+		if s.Trash != "" {
+			fmt.Println(pad + pad + labelStyle.Render("Trash") + fmt.Sprintf("%d emails in %s", s.TrashCount, s.Trash))
+		}
+		fmt.Println()
+	}
+}
@@ -33,6 +33,8 @@ func runTodayTUI() {
 	if err := i18n.Init(cfg.Language); err != nil {
 		fmt.Printf("Warning: i18n initialization failed: %v\n", err)
 	}
+	i18n.SetDateFormat(cfg.Dates.Relative(), cfg.Dates.ClockHours())
+	i18n.SetWeekStart(cfg.Calendar.FirstWeekday())
 
 	// Auto-start server if not running
 	if err := startServerBackground(); err != nil {
@@ -80,7 +82,7 @@ func runTodayTUI() {
 	}
 
 	p := tea.NewProgram(
-		ui.NewTodayApp(store, calClient),
+		ui.NewTodayApp(store, calClient, &cfg),
 		tea.WithAltScreen(),
 		tea.WithMouseCellMotion(),
 	)
@@ -0,0 +1,129 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"maily/internal/auth"
+	"maily/internal/cache"
+)
+
+var (
+	unreadAccount string
+	unreadMailbox string
+	unreadFormat  string
+)
+
+var unreadCmd = &cobra.Command{
+	Use:   "unread",
+	Short: "Show unread email counts",
+	Long: `Show the number of unread emails per account, read from the local cache without
+contacting the server, so it is fast enough for shell prompts and status bars. Counts
+are as fresh as the last sync.
+
+Formats:
+  text   one line per account (default)
+  count  the total only, for prompts
+  tmux   a colored "✉ N" for tmux's status line, empty when nothing is unread
+  json   per-account counts`,
+	Example: `  maily unread
+  maily unread -a me@gmail.com --format count
+  # ~/.tmux.conf
+  set -g status-right '#(maily unread --format tmux) %H:%M'`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		handleUnread()
+	},
+}
+
+func init() {
+	unreadCmd.Flags().StringVarP(&unreadAccount, "account", "a", "", "Only count this account")
+	unreadCmd.Flags().StringVarP(&unreadMailbox, "mailbox", "m", "INBOX", "Mailbox to count")
+	unreadCmd.Flags().StringVar(&unreadFormat, "format", "text", "Output format: text, count, tmux or json")
+	rootCmd.AddCommand(unreadCmd)
+}
+
+// unreadCount is one account's unread count
+type unreadCount struct {
+	Account string `json:"account"`
+	Mailbox string `json:"mailbox"`
+	Unread  int    `json:"unread"`
+}
+
+func handleUnread() {
+	if jsonOutput {
+		unreadFormat = "json"
+	}
+	switch unreadFormat {
+	case "text", "count", "tmux", "json":
+	default:
+		fail("invalid format '%s'. Use 'text', 'count', 'tmux' or 'json'", unreadFormat)
+	}
+
+	store, err := auth.LoadAccountStore()
+	if err != nil {
+		fail("%v", err)
+	}
+	accounts := store.Accounts
+	if unreadAccount != "" {
+		account, err := resolveAccount(store, unreadAccount)
+		if err != nil {
+			fail("%v", err)
+		}
+		accounts = []auth.Account{*account}
+	}
+
+	c, err := cache.New()
+	if err != nil {
+		fail("opening cache: %v", err)
+	}
+	defer c.Close()
+
+	counts := make([]unreadCount, 0, len(accounts))
+	for _, account := range accounts {
+		n, err := c.CountUnread(account.Credentials.Email, unreadMailbox)
+		if err != nil {
+			c.Close()
+			fail("%s: %v", account.Credentials.Email, err)
+		}
+		counts = append(counts, unreadCount{Account: account.Credentials.Email, Mailbox: unreadMailbox, Unread: n})
+	}
+
+	if unreadFormat == "json" {
+		printJSON(counts)
+	} else {
+		fmt.Print(formatUnread(counts, unreadFormat))
+	}
+	if len(counts) == 0 {
+		c.Close()
+		os.Exit(exitNoResults)
+	}
+}
+
+// formatUnread renders unread counts in the text, count or tmux format
+func formatUnread(counts []unreadCount, format string) string {
+	total := 0
+	for _, c := range counts {
+		total += c.Unread
+	}
+	switch format {
+	case "count":
+		return fmt.Sprintf("%d\n", total)
+	case "tmux":
+		if total == 0 {
+			return ""
+		}
+		return fmt.Sprintf("#[fg=yellow,bold]✉ %d#[default]\n", total)
+	}
+
+	var b strings.Builder
+	for _, c := range counts {
+		fmt.Fprintf(&b, "%5d  %s\n", c.Unread, c.Account)
+	}
+	if len(counts) > 1 {
+		fmt.Fprintf(&b, "%5d  total\n", total)
+	}
+	return b.String()
+}
@@ -0,0 +1,31 @@
+package cli
+
+import "testing"
+
+func TestFormatUnread(t *testing.T) {
+	counts := []unreadCount{
+		{Account: "me@gmail.com", Mailbox: "INBOX", Unread: 3},
+		{Account: "work@example.com", Mailbox: "INBOX", Unread: 12},
+	}
+
+	tests := []struct {
+		name   string
+		counts []unreadCount
+		format string
+		want   string
+	}{
+		{"text", counts, "text", "    3  me@gmail.com\n   12  work@example.com\n   15  total\n"},
+		{"text_single", counts[:1], "text", "    3  me@gmail.com\n"},
+		{"count", counts, "count", "15\n"},
+		{"tmux", counts, "tmux", "#[fg=yellow,bold]✉ 15#[default]\n"},
+		{"tmux_nothing_unread", []unreadCount{{Account: "me@gmail.com"}}, "tmux", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := formatUnread(tt.counts, tt.format); got != tt.want {
+				t.Errorf("formatUnread() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -29,12 +29,8 @@ var updateCmd = &cobra.Command{
 			}
 		}
 
-		// Check if server was running and stop it
-		serverWasRunning, _, _ := isServerRunning()
-		if serverWasRunning {
-			fmt.Println("Stopping server before update...")
-			stopServer()
-		}
+		// A running server keeps serving during the update, then hands over to the new one
+		serverWasRunning, _, serverVer := isServerRunning()
 
 		// Stop any running sync
 		if err := stopRunningSyncs(); err != nil {
@@ -46,10 +42,11 @@ var updateCmd = &cobra.Command{
 			return err
 		}
 
-		// Restart server if it was running
 		if serverWasRunning {
-			fmt.Println("Restarting server...")
-			startServerBackground()
+			fmt.Println("Handing over to the new server...")
+			if err := handoffServer(serverVer); err != nil {
+				fmt.Printf("Warning: %v - run 'maily server stop' and start maily again\n", err)
+			}
 		}
 
 		return nil
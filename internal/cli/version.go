@@ -16,6 +16,16 @@ var versionCmd = &cobra.Command{
 		if len(v) > 0 && v[0] == 'v' {
 			v = v[1:]
 		}
+		if jsonOutput {
+			printJSON(map[string]string{
+				"version": v,
+				"commit":  version.Commit,
+				"date":    version.Date,
+				"os":      runtime.GOOS,
+				"arch":    runtime.GOARCH,
+			})
+			return
+		}
 		fmt.Printf("maily v%s %s/%s\n", v, runtime.GOOS, runtime.GOARCH)
 		if version.Commit != "unknown" {
 			fmt.Printf("commit: %s\n", version.Commit)
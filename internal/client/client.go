@@ -3,7 +3,9 @@ package client
 import (
 	"bufio"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"log/slog"
 	"net"
 	"sync"
 	"sync/atomic"
@@ -37,6 +39,10 @@ func (e ErrVersionMismatch) Error() string {
 	return fmt.Sprintf("version mismatch: client=%s, server=%s - please run 'maily server stop' and restart", e.ClientVersion, e.ServerVersion)
 }
 
+// reconnectTimeout bounds how long a client waits for a server to come back after the
+// connection drops, e.g. while an updated server takes over the socket
+const reconnectTimeout = 10 * time.Second
+
 // Connect creates a new client connection to the server
 func Connect() (*Client, error) {
 	sockPath := server.GetSocketPath()
@@ -45,9 +51,16 @@ func Connect() (*Client, error) {
 		return nil, fmt.Errorf("failed to connect to server: %w (is the server running?)", err)
 	}
 
+	// Perform version handshake
+	reader, err := hello(conn)
+	if err != nil {
+		conn.Close()
+		return nil, err
+	}
+
 	c := &Client{
 		conn:    conn,
-		reader:  bufio.NewReader(conn),
+		reader:  reader,
 		encoder: json.NewEncoder(conn),
 		pending: make(map[string]chan server.Response),
 		events:  make(chan server.Event, 100),
@@ -56,42 +69,56 @@ func Connect() (*Client, error) {
 	// Start reader goroutine
 	go c.readLoop()
 
-	// Perform version handshake
-	if err := c.hello(); err != nil {
-		c.Close()
-		return nil, err
-	}
-
 	return c, nil
 }
 
-// hello performs the version handshake with the server
-func (c *Client) hello() error {
-	resp, err := c.request(server.Request{
-		Type:    server.ReqHello,
-		Version: version.Version,
-	}, 5*time.Second)
+// hello performs the version handshake on a new connection, returning the reader to
+// continue with
+func hello(conn net.Conn) (*bufio.Reader, error) {
+	conn.SetDeadline(time.Now().Add(5 * time.Second))
+	defer conn.SetDeadline(time.Time{})
 
-	if err != nil {
-		// Check if it's a version mismatch error
-		if resp.Version != "" && resp.Type == server.RespError {
-			return ErrVersionMismatch{
-				ClientVersion: version.Version,
-				ServerVersion: resp.Version,
+	req := server.Request{
+		Type:     server.ReqHello,
+		ID:       "hello",
+		Version:  version.Version,
+		Protocol: server.ProtocolVersion,
+	}
+	if err := json.NewEncoder(conn).Encode(req); err != nil {
+		return nil, fmt.Errorf("failed to send request: %w", err)
+	}
+
+	reader := bufio.NewReader(conn)
+	for {
+		line, err := reader.ReadBytes('\n')
+		if err != nil {
+			return nil, fmt.Errorf("handshake failed: %w", err)
+		}
+		var resp server.Response
+		if json.Unmarshal(line, &resp) != nil || resp.ID != req.ID {
+			continue // an event pushed before the reply
+		}
+		if resp.Type == server.RespError {
+			// Check if it's a version mismatch error
+			if resp.Version != "" {
+				return nil, ErrVersionMismatch{
+					ClientVersion: version.Version,
+					ServerVersion: resp.Version,
+				}
 			}
+			return nil, fmt.Errorf("%s", resp.Error)
 		}
-		return err
+		return reader, nil
 	}
-
-	return nil
 }
 
 // Close closes the connection
 func (c *Client) Close() error {
 	c.mu.Lock()
 	c.closed = true
+	conn := c.conn
 	c.mu.Unlock()
-	return c.conn.Close()
+	return conn.Close()
 }
 
 // Events returns the channel for server push events
@@ -107,24 +134,27 @@ func (c *Client) readLoop() {
 			c.mu.Lock()
 			closed := c.closed
 			c.mu.Unlock()
-			if !closed {
-				// Unexpected disconnect
-				close(c.events)
+			if closed {
+				return
+			}
+			// Unexpected disconnect: a new server may be taking over the socket
+			slog.Warn("server connection lost", "error", err)
+			if c.reconnect() {
+				continue
 			}
+			close(c.events)
 			return
 		}
 
-		// Try to parse as response
+		// Try to parse as response (responses always carry a request ID)
 		var resp server.Response
-		if err := json.Unmarshal(line, &resp); err == nil && resp.Type != "" {
-			if resp.ID != "" {
-				c.mu.Lock()
-				if ch, ok := c.pending[resp.ID]; ok {
-					ch <- resp
-					delete(c.pending, resp.ID)
-				}
-				c.mu.Unlock()
+		if err := json.Unmarshal(line, &resp); err == nil && resp.Type != "" && resp.ID != "" {
+			c.mu.Lock()
+			if ch, ok := c.pending[resp.ID]; ok {
+				ch <- resp
+				delete(c.pending, resp.ID)
 			}
+			c.mu.Unlock()
 			continue
 		}
 
@@ -140,6 +170,56 @@ func (c *Client) readLoop() {
 	}
 }
 
+// reconnect dials the server again after the connection dropped. Requests in flight fail;
+// events resume on the new connection, preceded by EventReconnected so listeners can
+// reload what they missed. Returns false if no compatible server came back in time.
+func (c *Client) reconnect() bool {
+	c.mu.Lock()
+	for id, ch := range c.pending {
+		ch <- server.Response{Type: server.RespError, ID: id, Error: "server connection lost"}
+		delete(c.pending, id)
+	}
+	c.mu.Unlock()
+
+	sockPath := server.GetSocketPath()
+	deadline := time.Now().Add(reconnectTimeout)
+	for time.Now().Before(deadline) {
+		time.Sleep(200 * time.Millisecond)
+
+		conn, err := net.Dial("unix", sockPath)
+		if err != nil {
+			continue
+		}
+		reader, err := hello(conn)
+		if err != nil {
+			conn.Close()
+			var mismatch ErrVersionMismatch
+			if errors.As(err, &mismatch) {
+				slog.Warn("cannot reconnect", "error", err)
+				return false
+			}
+			continue
+		}
+
+		c.mu.Lock()
+		if c.closed {
+			c.mu.Unlock()
+			conn.Close()
+			return false
+		}
+		c.conn, c.reader, c.encoder = conn, reader, json.NewEncoder(conn)
+		c.mu.Unlock()
+
+		slog.Info("reconnected to server")
+		select {
+		case c.events <- server.Event{Type: server.EventReconnected}:
+		default:
+		}
+		return true
+	}
+	return false
+}
+
 // request sends a request and waits for response
 func (c *Client) request(req server.Request, timeout time.Duration) (server.Response, error) {
 	// Generate unique request ID
@@ -150,15 +230,14 @@ func (c *Client) request(req server.Request, timeout time.Duration) (server.Resp
 	respChan := make(chan server.Response, 1)
 	c.mu.Lock()
 	c.pending[id] = respChan
-	c.mu.Unlock()
 
-	// Send request
+	// Send request (under the lock, as reconnecting swaps the connection)
 	if err := c.encoder.Encode(req); err != nil {
-		c.mu.Lock()
 		delete(c.pending, id)
 		c.mu.Unlock()
 		return server.Response{}, fmt.Errorf("failed to send request: %w", err)
 	}
+	c.mu.Unlock()
 
 	// Wait for response
 	select {
@@ -190,6 +269,36 @@ func (c *Client) GetAccounts() ([]server.AccountInfo, error) {
 	return resp.Accounts, nil
 }
 
+// ReloadAccounts asks the server to re-read the account store
+func (c *Client) ReloadAccounts() error {
+	_, err := c.request(server.Request{Type: server.ReqReloadAccounts}, 10*time.Second)
+	return err
+}
+
+// GetStats returns sync statistics for an account (all accounts if empty)
+func (c *Client) GetStats(account string) ([]cache.SyncStats, error) {
+	resp, err := c.request(server.Request{
+		Type:    server.ReqGetStats,
+		Account: account,
+	}, 10*time.Second)
+	if err != nil {
+		return nil, err
+	}
+	return resp.Stats, nil
+}
+
+// GetStorage returns mailbox storage usage and trash size per account (all accounts if empty)
+func (c *Client) GetStorage(account string) ([]server.AccountStorage, error) {
+	resp, err := c.request(server.Request{
+		Type:    server.ReqGetStorage,
+		Account: account,
+	}, 120*time.Second)
+	if err != nil {
+		return nil, err
+	}
+	return resp.Storage, nil
+}
+
 // GetEmails returns emails for an account/mailbox
 func (c *Client) GetEmails(account, mailbox string, limit int) ([]cache.CachedEmail, error) {
 	resp, err := c.request(server.Request{
@@ -204,6 +313,75 @@ func (c *Client) GetEmails(account, mailbox string, limit int) ([]cache.CachedEm
 	return resp.Emails, nil
 }
 
+// GetEmailsFiltered returns the emails of an account/mailbox that match filter, newest
+// first. Servers that predate the filters ignore them and return the newest emails, so
+// callers still check what they get.
+func (c *Client) GetEmailsFiltered(account, mailbox string, filter cache.EmailFilter) ([]cache.CachedEmail, error) {
+	req := server.Request{
+		Type:        server.ReqGetEmails,
+		Account:     account,
+		Mailbox:     mailbox,
+		UnreadOnly:  filter.UnreadOnly,
+		From:        filter.From,
+		Subject:     filter.Subject,
+		Attachments: filter.HasAttachments,
+		Limit:       filter.Limit,
+	}
+	if !filter.Since.IsZero() {
+		req.Since = filter.Since.Unix()
+	}
+	if !filter.Before.IsZero() {
+		req.Before = filter.Before.Unix()
+	}
+	resp, err := c.request(req, 30*time.Second)
+	if err != nil {
+		return nil, err
+	}
+	return resp.Emails, nil
+}
+
+// CountEmailsFiltered returns how many emails of an account/mailbox match filter,
+// ignoring its Limit
+func (c *Client) CountEmailsFiltered(account, mailbox string, filter cache.EmailFilter) (int, error) {
+	req := server.Request{
+		Type:        server.ReqCountEmails,
+		Account:     account,
+		Mailbox:     mailbox,
+		UnreadOnly:  filter.UnreadOnly,
+		From:        filter.From,
+		Subject:     filter.Subject,
+		Attachments: filter.HasAttachments,
+	}
+	if !filter.Since.IsZero() {
+		req.Since = filter.Since.Unix()
+	}
+	if !filter.Before.IsZero() {
+		req.Before = filter.Before.Unix()
+	}
+	resp, err := c.request(req, 30*time.Second)
+	if err != nil {
+		return 0, err
+	}
+	return resp.Count, nil
+}
+
+// GetEmailPage returns limit emails starting at offset in the sort order (cache.SortDate
+// for newest first) and the mailbox total
+func (c *Client) GetEmailPage(account, mailbox string, offset, limit int, sort string) ([]cache.CachedEmail, int, error) {
+	resp, err := c.request(server.Request{
+		Type:    server.ReqGetEmailPage,
+		Account: account,
+		Mailbox: mailbox,
+		Offset:  offset,
+		Limit:   limit,
+		Sort:    sort,
+	}, 30*time.Second)
+	if err != nil {
+		return nil, 0, err
+	}
+	return resp.Emails, resp.Total, nil
+}
+
 // GetEmail returns a single email by UID
 func (c *Client) GetEmail(account, mailbox string, uid imap.UID) (*cache.CachedEmail, error) {
 	resp, err := c.request(server.Request{
@@ -274,6 +452,17 @@ func (c *Client) MarkUnread(account, mailbox string, uid imap.UID) error {
 	return err
 }
 
+// MarkAnswered marks an email as replied to
+func (c *Client) MarkAnswered(account, mailbox string, uid imap.UID) error {
+	_, err := c.request(server.Request{
+		Type:    server.ReqMarkAnswered,
+		Account: account,
+		Mailbox: mailbox,
+		UID:     uint32(uid),
+	}, 30*time.Second)
+	return err
+}
+
 // DeleteEmail deletes an email
 func (c *Client) DeleteEmail(account, mailbox string, uid imap.UID) error {
 	_, err := c.request(server.Request{
@@ -348,6 +537,85 @@ func (c *Client) QueueMoveToTrash(account, mailbox string, uid imap.UID) error {
 	return err
 }
 
+// QueueMoveToSpam queues a move of an email to the spam folder
+func (c *Client) QueueMoveToSpam(account, mailbox string, uid imap.UID) error {
+	_, err := c.request(server.Request{
+		Type:    server.ReqQueueMoveSpam,
+		Account: account,
+		Mailbox: mailbox,
+		UID:     uint32(uid),
+	}, 30*time.Second)
+	return err
+}
+
+// QueueNotSpam queues a move of an email from the spam folder back to INBOX
+func (c *Client) QueueNotSpam(account, mailbox string, uid imap.UID) error {
+	_, err := c.request(server.Request{
+		Type:    server.ReqQueueNotSpam,
+		Account: account,
+		Mailbox: mailbox,
+		UID:     uint32(uid),
+	}, 30*time.Second)
+	return err
+}
+
+// GetSpecialFolder returns the name of an account's sent, trash or spam folder
+// (mail.FolderSent, mail.FolderTrash or mail.FolderSpam)
+func (c *Client) GetSpecialFolder(account, kind string) (string, error) {
+	resp, err := c.request(server.Request{
+		Type:    server.ReqGetSpecialFolder,
+		Account: account,
+		Folder:  kind,
+	}, 30*time.Second)
+	if err != nil {
+		return "", err
+	}
+	return resp.Mailbox, nil
+}
+
+// RestoreFromTrash moves an email from the trash back to the folder it was deleted from
+// and returns that folder
+func (c *Client) RestoreFromTrash(account, trash string, uid imap.UID) (string, error) {
+	resp, err := c.request(server.Request{
+		Type:    server.ReqRestoreFromTrash,
+		Account: account,
+		Mailbox: trash,
+		UID:     uint32(uid),
+	}, 60*time.Second)
+	if err != nil {
+		return "", err
+	}
+	return resp.Mailbox, nil
+}
+
+// EmptyTrash permanently deletes everything in the account's trash and returns how many
+// emails were removed
+func (c *Client) EmptyTrash(account string) (int, error) {
+	resp, err := c.request(server.Request{
+		Type:    server.ReqEmptyTrash,
+		Account: account,
+	}, 120*time.Second)
+	if err != nil {
+		return 0, err
+	}
+	return resp.Count, nil
+}
+
+// LoadOlder has the server fetch the page of emails older than the oldest cached one
+// into the cache, returning how many were added
+func (c *Client) LoadOlder(account, mailbox string, limit int) (int, error) {
+	resp, err := c.request(server.Request{
+		Type:    server.ReqLoadOlder,
+		Account: account,
+		Mailbox: mailbox,
+		Limit:   limit,
+	}, 60*time.Second)
+	if err != nil {
+		return 0, err
+	}
+	return resp.Count, nil
+}
+
 // MoveMultiToTrash moves multiple emails to trash
 func (c *Client) MoveMultiToTrash(account, mailbox string, uids []imap.UID) error {
 	uint32UIDs := make([]uint32, len(uids))
@@ -378,6 +646,21 @@ func (c *Client) QueueMoveMultiToTrash(account, mailbox string, uids []imap.UID)
 	return err
 }
 
+// QueueArchiveMulti queues archive operations for multiple emails.
+func (c *Client) QueueArchiveMulti(account, mailbox string, uids []imap.UID) error {
+	uint32UIDs := make([]uint32, len(uids))
+	for i, uid := range uids {
+		uint32UIDs[i] = uint32(uid)
+	}
+	_, err := c.request(server.Request{
+		Type:    server.ReqQueueArchiveMulti,
+		Account: account,
+		Mailbox: mailbox,
+		UIDs:    uint32UIDs,
+	}, 30*time.Second)
+	return err
+}
+
 // MarkMultiRead marks multiple emails as read
 func (c *Client) MarkMultiRead(account, mailbox string, uids []imap.UID) error {
 	uint32UIDs := make([]uint32, len(uids))
@@ -439,8 +722,102 @@ func (c *Client) SaveDraft(account, to, subject, body string) error {
 	return err
 }
 
-// DownloadAttachment downloads an attachment and returns the file path
+// SendInvitation has the server email a calendar invitation from account: body for
+// mail clients that don't show invitations, and the iCalendar invitation
+func (c *Client) SendInvitation(account, to, subject, body string, invitation []byte) error {
+	_, err := c.request(server.Request{
+		Type:       server.ReqSendInvitation,
+		Account:    account,
+		To:         to,
+		Subject:    subject,
+		Body:       body,
+		Invitation: invitation,
+	}, 60*time.Second)
+	return err
+}
+
+// GetFailedOps returns the queued operations of an account that ran out of retries
+func (c *Client) GetFailedOps(account string) ([]cache.PendingOp, error) {
+	resp, err := c.request(server.Request{
+		Type:    server.ReqGetFailedOps,
+		Account: account,
+	}, 5*time.Second)
+	if err != nil {
+		return nil, err
+	}
+	return resp.Ops, nil
+}
+
+// RetryOp puts a failed operation back in the queue
+func (c *Client) RetryOp(id int64) error {
+	_, err := c.request(server.Request{
+		Type: server.ReqRetryOp,
+		OpID: id,
+	}, 5*time.Second)
+	return err
+}
+
+// DiscardOp drops a failed operation from the queue
+func (c *Client) DiscardOp(id int64) error {
+	_, err := c.request(server.Request{
+		Type: server.ReqDiscardOp,
+		OpID: id,
+	}, 5*time.Second)
+	return err
+}
+
+// UpdateLabels adds and removes Gmail labels on emails without moving them
+func (c *Client) UpdateLabels(account, mailbox string, uids []imap.UID, add, remove []string) error {
+	uint32UIDs := make([]uint32, len(uids))
+	for i, uid := range uids {
+		uint32UIDs[i] = uint32(uid)
+	}
+	_, err := c.request(server.Request{
+		Type:         server.ReqUpdateLabels,
+		Account:      account,
+		Mailbox:      mailbox,
+		UIDs:         uint32UIDs,
+		AddLabels:    add,
+		RemoveLabels: remove,
+	}, 30*time.Second)
+	return err
+}
+
+// FetchRaw returns the full RFC 822 source of a message
+func (c *Client) FetchRaw(account, mailbox string, uid imap.UID) ([]byte, error) {
+	resp, err := c.request(server.Request{
+		Type:    server.ReqFetchRaw,
+		Account: account,
+		Mailbox: mailbox,
+		UID:     uint32(uid),
+	}, 60*time.Second)
+	if err != nil {
+		return nil, err
+	}
+	return resp.Raw, nil
+}
+
+// FetchHeader returns the header block of a message
+func (c *Client) FetchHeader(account, mailbox string, uid imap.UID) ([]byte, error) {
+	resp, err := c.request(server.Request{
+		Type:    server.ReqFetchHeader,
+		Account: account,
+		Mailbox: mailbox,
+		UID:     uint32(uid),
+	}, 30*time.Second)
+	if err != nil {
+		return nil, err
+	}
+	return resp.Raw, nil
+}
+
+// DownloadAttachment downloads an attachment to ~/Downloads/maily and returns the file path
 func (c *Client) DownloadAttachment(account, mailbox string, uid imap.UID, partID, filename, encoding string) (string, error) {
+	return c.DownloadAttachmentTo(account, mailbox, uid, partID, filename, encoding, "")
+}
+
+// DownloadAttachmentTo downloads an attachment into dir and returns the file path
+func (c *Client) DownloadAttachmentTo(account, mailbox string, uid imap.UID, partID, filename, encoding, dir string) (string, error) {
 	resp, err := c.request(server.Request{
 		Type:     server.ReqDownloadAttachment,
 		Account:  account,
@@ -449,6 +826,7 @@ func (c *Client) DownloadAttachment(account, mailbox string, uid imap.UID, partI
 		PartID:   partID,
 		Filename: filename,
 		Encoding: encoding,
+		Dir:      dir,
 	}, 60*time.Second)
 	if err != nil {
 		return "", err
@@ -0,0 +1,87 @@
+package contacts
+
+import (
+	"context"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"maily/config"
+)
+
+const addressBookQuery = `<?xml version="1.0" encoding="utf-8"?>
+<C:addressbook-query xmlns:D="DAV:" xmlns:C="urn:ietf:params:xml:ns:carddav">
+  <D:prop>
+    <C:address-data/>
+  </D:prop>
+</C:addressbook-query>`
+
+// multistatus is the subset of a WebDAV 207 response maily reads
+type multistatus struct {
+	Responses []struct {
+		Href     string `xml:"href"`
+		Propstat []struct {
+			Status string `xml:"status"`
+			Prop   struct {
+				AddressData string `xml:"address-data"`
+			} `xml:"prop"`
+		} `xml:"propstat"`
+	} `xml:"response"`
+}
+
+var httpClient = &http.Client{Timeout: 60 * time.Second}
+
+// FetchCardDAV downloads every vCard in a CardDAV address book collection
+// with a single addressbook-query REPORT and parses the contacts
+func FetchCardDAV(ctx context.Context, book config.CardDAVConfig) ([]Contact, error) {
+	if book.URL == "" {
+		return nil, fmt.Errorf("address book %q has no url", book.Name)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "REPORT", book.URL, strings.NewReader(addressBookQuery))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/xml; charset=utf-8")
+	req.Header.Set("Depth", "1")
+	switch {
+	case book.Token != "":
+		req.Header.Set("Authorization", "Bearer "+book.Token)
+	case book.Username != "":
+		req.SetBasicAuth(book.Username, book.Password)
+	}
+
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusMultiStatus {
+		return nil, fmt.Errorf("address book %q: unexpected status %s", book.Name, resp.Status)
+	}
+
+	var ms multistatus
+	if err := xml.NewDecoder(io.LimitReader(resp.Body, 64<<20)).Decode(&ms); err != nil {
+		return nil, fmt.Errorf("address book %q: invalid response: %w", book.Name, err)
+	}
+
+	source := "carddav:" + book.Name
+	var result []Contact
+	for _, r := range ms.Responses {
+		for _, ps := range r.Propstat {
+			if ps.Prop.AddressData == "" || (ps.Status != "" && !strings.Contains(ps.Status, " 200 ")) {
+				continue
+			}
+			cards, err := ParseVCards(strings.NewReader(ps.Prop.AddressData), source)
+			if err != nil {
+				return nil, fmt.Errorf("address book %q: %s: %w", book.Name, r.Href, err)
+			}
+			result = append(result, cards...)
+		}
+	}
+	return result, nil
+}
@@ -0,0 +1,74 @@
+package contacts
+
+import (
+	"sort"
+	"strings"
+	"time"
+)
+
+// Contact is a single email address with an optional display name
+type Contact struct {
+	Email     string    `json:"email"`
+	Name      string    `json:"name,omitempty"`
+	Source    string    `json:"source"` // e.g. "vcard:family.vcf" or "carddav:fastmail"
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// Key returns the merge key for a contact: its lowercased email address
+func (c Contact) Key() string {
+	return strings.ToLower(strings.TrimSpace(c.Email))
+}
+
+// String formats the contact as an RFC 5322 address, e.g. "Jane Doe <jane@example.com>"
+func (c Contact) String() string {
+	if c.Name == "" {
+		return c.Email
+	}
+	return c.Name + " <" + c.Email + ">"
+}
+
+// Merge folds incoming contacts into existing ones keyed by email address and
+// returns only the contacts that were added or changed. A name fills in an
+// empty one; otherwise names are only replaced by the source that set them, so
+// a CardDAV sync never clobbers a name imported from a vCard file or vice versa.
+func Merge(existing, incoming []Contact) (changed []Contact, added int) {
+	byKey := make(map[string]Contact, len(existing))
+	for _, c := range existing {
+		byKey[c.Key()] = c
+	}
+
+	changedKeys := make(map[string]bool)
+	for _, in := range incoming {
+		key := in.Key()
+		if key == "" || !strings.Contains(key, "@") {
+			continue
+		}
+		in.Email = strings.TrimSpace(in.Email)
+		in.Name = strings.TrimSpace(in.Name)
+
+		cur, ok := byKey[key]
+		if !ok {
+			byKey[key] = in
+			changedKeys[key] = true
+			added++
+			continue
+		}
+
+		if in.Name == "" || in.Name == cur.Name {
+			continue
+		}
+		if cur.Name == "" || cur.Source == in.Source {
+			cur.Name = in.Name
+			cur.Source = in.Source
+			cur.UpdatedAt = in.UpdatedAt
+			byKey[key] = cur
+			changedKeys[key] = true
+		}
+	}
+
+	for key := range changedKeys {
+		changed = append(changed, byKey[key])
+	}
+	sort.Slice(changed, func(i, j int) bool { return changed[i].Key() < changed[j].Key() })
+	return changed, added
+}
@@ -0,0 +1,138 @@
+package contacts
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"maily/config"
+)
+
+const sampleVCF = "BEGIN:VCARD\r\n" +
+	"VERSION:3.0\r\n" +
+	"FN:Jane Doe\r\n" +
+	"N:Doe;Jane;;;\r\n" +
+	"EMAIL;TYPE=INTERNET;TYPE=WORK:jane@work.com\r\n" +
+	"item1.EMAIL;TYPE=INTERNET:jane@home.\r\n" +
+	" com\r\n" +
+	"END:VCARD\r\n" +
+	"BEGIN:VCARD\r\n" +
+	"VERSION:3.0\r\n" +
+	"N:Smith;Bob\\, Jr.;;;\r\n" +
+	"EMAIL:bob@example.com\r\n" +
+	"END:VCARD\r\n" +
+	"BEGIN:VCARD\r\n" +
+	"VERSION:2.1\r\n" +
+	"FN;ENCODING=QUOTED-PRINTABLE;CHARSET=UTF-8:Jos=C3=A9 Garc=C3=\r\n" +
+	"=ADa\r\n" +
+	"EMAIL:jose@example.com\r\n" +
+	"END:VCARD\r\n" +
+	"BEGIN:VCARD\r\n" +
+	"VERSION:3.0\r\n" +
+	"FN:No Email\r\n" +
+	"TEL:555-0100\r\n" +
+	"END:VCARD\r\n"
+
+func TestParseVCards(t *testing.T) {
+	got, err := ParseVCards(strings.NewReader(sampleVCF), "vcard:test.vcf")
+	if err != nil {
+		t.Fatalf("ParseVCards() error = %v", err)
+	}
+
+	want := []struct{ email, name string }{
+		{"jane@work.com", "Jane Doe"},
+		{"jane@home.com", "Jane Doe"},
+		{"bob@example.com", "Bob, Jr. Smith"},
+		{"jose@example.com", "José García"},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("got %d contacts, want %d: %+v", len(got), len(want), got)
+	}
+	for i, w := range want {
+		if got[i].Email != w.email || got[i].Name != w.name {
+			t.Errorf("contact %d = %q <%s>, want %q <%s>", i, got[i].Name, got[i].Email, w.name, w.email)
+		}
+		if got[i].Source != "vcard:test.vcf" {
+			t.Errorf("contact %d source = %q", i, got[i].Source)
+		}
+	}
+}
+
+func TestMerge(t *testing.T) {
+	existing := []Contact{
+		{Email: "jane@example.com", Name: "Jane", Source: "vcard:a.vcf"},
+		{Email: "bob@example.com", Name: "", Source: "carddav:work"},
+	}
+	incoming := []Contact{
+		{Email: "JANE@example.com", Name: "Jane Doe", Source: "carddav:work"}, // other source: keep name
+		{Email: "bob@example.com", Name: "Bob Smith", Source: "carddav:work"}, // fills empty name
+		{Email: "new@example.com", Name: "New", Source: "carddav:work"},
+		{Email: "new@example.com", Name: "New", Source: "carddav:work"}, // duplicate in same batch
+		{Email: "not-an-address", Source: "carddav:work"},
+	}
+
+	changed, added := Merge(existing, incoming)
+	if added != 1 {
+		t.Errorf("added = %d, want 1", added)
+	}
+	if len(changed) != 2 {
+		t.Fatalf("changed = %+v, want bob and new", changed)
+	}
+	if changed[0].Email != "bob@example.com" || changed[0].Name != "Bob Smith" {
+		t.Errorf("changed[0] = %+v", changed[0])
+	}
+	if changed[1].Email != "new@example.com" {
+		t.Errorf("changed[1] = %+v", changed[1])
+	}
+
+	// The source that set a name may update it
+	changed, _ = Merge(existing, []Contact{{Email: "jane@example.com", Name: "Jane Q. Doe", Source: "vcard:a.vcf"}})
+	if len(changed) != 1 || changed[0].Name != "Jane Q. Doe" {
+		t.Errorf("same-source rename: changed = %+v", changed)
+	}
+}
+
+func TestFetchCardDAV(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != "REPORT" {
+			t.Errorf("method = %s, want REPORT", r.Method)
+		}
+		if user, pass, ok := r.BasicAuth(); !ok || user != "me" || pass != "secret" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		w.WriteHeader(http.StatusMultiStatus)
+		w.Write([]byte(`<?xml version="1.0"?>
+<d:multistatus xmlns:d="DAV:" xmlns:card="urn:ietf:params:xml:ns:carddav">
+  <d:response>
+    <d:href>/book/jane.vcf</d:href>
+    <d:propstat>
+      <d:prop><card:address-data>BEGIN:VCARD
+VERSION:3.0
+FN:Jane Doe
+EMAIL:jane@example.com
+END:VCARD
+</card:address-data></d:prop>
+      <d:status>HTTP/1.1 200 OK</d:status>
+    </d:propstat>
+  </d:response>
+</d:multistatus>`))
+	}))
+	defer srv.Close()
+
+	book := config.CardDAVConfig{Name: "test", URL: srv.URL + "/book/", Username: "me", Password: "secret"}
+	got, err := FetchCardDAV(context.Background(), book)
+	if err != nil {
+		t.Fatalf("FetchCardDAV() error = %v", err)
+	}
+	if len(got) != 1 || got[0].Email != "jane@example.com" || got[0].Name != "Jane Doe" || got[0].Source != "carddav:test" {
+		t.Errorf("FetchCardDAV() = %+v", got)
+	}
+
+	book.Password = "wrong"
+	if _, err := FetchCardDAV(context.Background(), book); err == nil {
+		t.Error("FetchCardDAV() with bad credentials: expected error")
+	}
+}
@@ -0,0 +1,192 @@
+package contacts
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"mime/quotedprintable"
+	"strings"
+	"time"
+)
+
+// ParseVCards reads every contact from a vCard (.vcf) stream. A card with
+// several EMAIL properties yields one contact per address; cards without an
+// email address are skipped.
+func ParseVCards(r io.Reader, source string) ([]Contact, error) {
+	lines, err := unfoldLines(r)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	var result []Contact
+	var inCard bool
+	var fullName, structuredName string
+	var emails []string
+
+	for _, line := range lines {
+		name, params, value, ok := splitProperty(line)
+		if !ok {
+			continue
+		}
+
+		switch name {
+		case "BEGIN":
+			if strings.EqualFold(value, "VCARD") {
+				inCard = true
+				fullName, structuredName, emails = "", "", nil
+			}
+		case "END":
+			if !inCard || !strings.EqualFold(value, "VCARD") {
+				continue
+			}
+			inCard = false
+			displayName := fullName
+			if displayName == "" {
+				displayName = structuredName
+			}
+			for _, email := range emails {
+				result = append(result, Contact{
+					Email:     email,
+					Name:      displayName,
+					Source:    source,
+					UpdatedAt: now,
+				})
+			}
+		case "FN":
+			fullName = unescapeValue(decodeValue(params, value))
+		case "N":
+			// N:Family;Given;Additional;Prefix;Suffix
+			parts := splitUnescaped(decodeValue(params, value), ';')
+			var given, family string
+			if len(parts) > 0 {
+				family = parts[0]
+			}
+			if len(parts) > 1 {
+				given = parts[1]
+			}
+			structuredName = strings.TrimSpace(given + " " + family)
+		case "EMAIL":
+			email := strings.TrimSpace(unescapeValue(decodeValue(params, value)))
+			email = strings.TrimPrefix(email, "mailto:")
+			if strings.Contains(email, "@") {
+				emails = append(emails, email)
+			}
+		}
+	}
+
+	return result, nil
+}
+
+// unfoldLines joins RFC 6350 folded lines (continuations start with a space or tab)
+func unfoldLines(r io.Reader) ([]string, error) {
+	var lines []string
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 4*1024*1024) // embedded photos make long lines
+	for scanner.Scan() {
+		line := strings.TrimRight(scanner.Text(), "\r")
+		if len(line) > 0 && (line[0] == ' ' || line[0] == '\t') && len(lines) > 0 {
+			lines[len(lines)-1] += line[1:]
+			continue
+		}
+		// vCard 2.1 quoted-printable soft line breaks end with '='
+		if n := len(lines); n > 0 && strings.HasSuffix(lines[n-1], "=") && isQuotedPrintable(lines[n-1]) {
+			lines[n-1] = lines[n-1][:len(lines[n-1])-1] + line
+			continue
+		}
+		lines = append(lines, line)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("reading vCard: %w", err)
+	}
+	return lines, nil
+}
+
+// splitProperty splits "item1.EMAIL;TYPE=work:jane@example.com" into EMAIL, [TYPE=work], jane@example.com
+func splitProperty(line string) (name string, params []string, value string, ok bool) {
+	colon := -1
+	quoted := false
+	for i, r := range line {
+		if r == '"' {
+			quoted = !quoted
+		} else if r == ':' && !quoted {
+			colon = i
+			break
+		}
+	}
+	if colon < 0 {
+		return "", nil, "", false
+	}
+
+	parts := strings.Split(line[:colon], ";")
+	name = strings.ToUpper(parts[0])
+	if dot := strings.LastIndex(name, "."); dot >= 0 {
+		name = name[dot+1:]
+	}
+	return name, parts[1:], line[colon+1:], true
+}
+
+func isQuotedPrintable(line string) bool {
+	name, params, _, ok := splitProperty(line)
+	if !ok || name == "" {
+		return false
+	}
+	for _, p := range params {
+		if strings.EqualFold(p, "ENCODING=QUOTED-PRINTABLE") || strings.EqualFold(p, "QUOTED-PRINTABLE") {
+			return true
+		}
+	}
+	return false
+}
+
+// decodeValue undoes vCard 2.1 quoted-printable encoding when the property declares it
+func decodeValue(params []string, value string) string {
+	for _, p := range params {
+		if strings.EqualFold(p, "ENCODING=QUOTED-PRINTABLE") || strings.EqualFold(p, "QUOTED-PRINTABLE") {
+			decoded, err := io.ReadAll(quotedprintable.NewReader(strings.NewReader(value)))
+			if err == nil {
+				return string(decoded)
+			}
+		}
+	}
+	return value
+}
+
+// unescapeValue decodes \n, \, and \; escapes in a text value
+func unescapeValue(s string) string {
+	if !strings.Contains(s, `\`) {
+		return s
+	}
+	var sb strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] == '\\' && i+1 < len(s) {
+			i++
+			switch s[i] {
+			case 'n', 'N':
+				sb.WriteByte('\n')
+			default:
+				sb.WriteByte(s[i])
+			}
+			continue
+		}
+		sb.WriteByte(s[i])
+	}
+	return sb.String()
+}
+
+// splitUnescaped splits on sep except where it is escaped with a backslash
+func splitUnescaped(s string, sep byte) []string {
+	var parts []string
+	start := 0
+	for i := 0; i < len(s); i++ {
+		if s[i] == '\\' {
+			i++
+			continue
+		}
+		if s[i] == sep {
+			parts = append(parts, unescapeValue(s[start:i]))
+			start = i + 1
+		}
+	}
+	return append(parts, unescapeValue(s[start:]))
+}
@@ -0,0 +1,313 @@
+// Package dateparse reads the dates people type: 2025-01-05, jan 5, the 5th of
+// january, 1/5, fri, 3 days. Going to a date in the mail list reads them looking back,
+// quick-add of events looking ahead. Month and weekday names are read in English and
+// in the language maily is shown in.
+package dateparse
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"maily/internal/i18n"
+)
+
+// Direction is where a date written without all its parts falls: jan 5 without a year,
+// or fri
+type Direction int
+
+const (
+	// Future is the next such day, today included
+	Future Direction = iota
+	// Past is the most recent such day before today
+	Past
+)
+
+// Date reads a day at the start of w, lower-case words, returning it and how many
+// words it took: a day with the month's name (jan 5, january 5th 2026, 5 jan, 5th of
+// january), 2025-01-05, or 1/5, 1/5/25 and 1.5.2025 with the day first when dayFirst.
+// A day without a year is the next or the latest one, as dir says.
+func Date(w []string, today time.Time, dir Direction, dayFirst bool) (time.Time, int) {
+	if len(w) == 0 {
+		return time.Time{}, 0
+	}
+	if date, n := monthDay(w, today, dir); n > 0 {
+		return date, n
+	}
+	if date, ok := numericDay(w[0], today, dir, dayFirst); ok {
+		return date, 1
+	}
+	return time.Time{}, 0
+}
+
+// monthDay reads a day written with the month's name
+func monthDay(w []string, today time.Time, dir Direction) (time.Time, int) {
+	var month time.Month
+	var day, n int
+	if m, ok := Month(w[0]); ok && len(w) >= 2 {
+		if d, ok := DayNumber(w[1]); ok {
+			month, day, n = m, d, 2
+		}
+	} else if d, ok := DayNumber(w[0]); ok && len(w) >= 2 {
+		j := 1
+		if w[1] == "of" && len(w) >= 3 {
+			j = 2
+		}
+		if m, ok := Month(w[j]); ok {
+			month, day, n = m, d, j+1
+		}
+	}
+	if n == 0 {
+		return time.Time{}, 0
+	}
+	year := 0
+	if len(w) > n {
+		if y, err := strconv.Atoi(w[n]); err == nil && y >= 1900 && y < 3000 {
+			year, n = y, n+1
+		}
+	}
+	date, ok := calendarDay(year, month, day, today, dir)
+	if !ok {
+		return time.Time{}, 0
+	}
+	return date, n
+}
+
+var (
+	isoDateRe     = regexp.MustCompile(`^(\d{4})[-/.](\d{1,2})[-/.](\d{1,2})$`)
+	numericDateRe = regexp.MustCompile(`^(\d{1,2})[/.](\d{1,2})(?:[/.](\d{4}|\d{2}))?$`)
+)
+
+// numericDay reads a day written in numbers. A dash only goes with a four-digit year,
+// so 3-4 stays a span of hours.
+func numericDay(s string, today time.Time, dir Direction, dayFirst bool) (time.Time, bool) {
+	var year, month, day int
+	if m := isoDateRe.FindStringSubmatch(s); m != nil {
+		year, _ = strconv.Atoi(m[1])
+		month, _ = strconv.Atoi(m[2])
+		day, _ = strconv.Atoi(m[3])
+	} else if m := numericDateRe.FindStringSubmatch(s); m != nil {
+		month, _ = strconv.Atoi(m[1])
+		day, _ = strconv.Atoi(m[2])
+		if dayFirst {
+			month, day = day, month
+		}
+		if m[3] != "" {
+			year, _ = strconv.Atoi(m[3])
+			if year < 100 {
+				year += 2000
+			}
+		}
+	} else {
+		return time.Time{}, false
+	}
+	return calendarDay(year, time.Month(month), day, today, dir)
+}
+
+// calendarDay returns the day, checking it exists; without a year it's the next one to
+// come or the latest one gone, as dir says
+func calendarDay(year int, month time.Month, day int, today time.Time, dir Direction) (time.Time, bool) {
+	if year == 0 {
+		year = today.Year()
+		before := month < today.Month() || (month == today.Month() && day < today.Day())
+		after := month > today.Month() || (month == today.Month() && day > today.Day())
+		if dir == Future && before {
+			year++
+		} else if dir == Past && after {
+			year--
+		}
+	}
+	t := time.Date(year, month, day, 0, 0, 0, 0, today.Location())
+	if t.Month() != month || t.Day() != day {
+		return time.Time{}, false
+	}
+	return t, true
+}
+
+// WeekdayFrom returns the coming day of the week, today included unless skipToday, or
+// the most recent one before today
+func WeekdayFrom(today time.Time, day time.Weekday, dir Direction, skipToday bool) time.Time {
+	if dir == Past {
+		back := (int(today.Weekday())-int(day)+6)%7 + 1
+		return today.AddDate(0, 0, -back)
+	}
+	ahead := (int(day) - int(today.Weekday()) + 7) % 7
+	if ahead == 0 && skipToday {
+		ahead = 7
+	}
+	return today.AddDate(0, 0, ahead)
+}
+
+// DayNumber reads a day of the month: 5, 5th, 21st, or 5. as German writes it
+func DayNumber(s string) (int, bool) {
+	for _, suffix := range []string{"st", "nd", "rd", "th", "."} {
+		s = strings.TrimSuffix(s, suffix)
+	}
+	d, err := strconv.Atoi(s)
+	return d, err == nil && d >= 1 && d <= 31
+}
+
+// MonthUnit and YearUnit stand for months and years in amounts, which have no fixed
+// length
+const (
+	MonthUnit = -1
+	YearUnit  = -2
+)
+
+// units are the units Amount knows
+var units = map[string]time.Duration{
+	"m": time.Minute, "min": time.Minute, "mins": time.Minute, "minute": time.Minute, "minutes": time.Minute,
+	"h": time.Hour, "hr": time.Hour, "hrs": time.Hour, "hour": time.Hour, "hours": time.Hour,
+	"d": 24 * time.Hour, "day": 24 * time.Hour, "days": 24 * time.Hour,
+	"w": 7 * 24 * time.Hour, "wk": 7 * 24 * time.Hour, "wks": 7 * 24 * time.Hour,
+	"week": 7 * 24 * time.Hour, "weeks": 7 * 24 * time.Hour,
+	"month": MonthUnit, "months": MonthUnit,
+	"year": YearUnit, "years": YearUnit,
+}
+
+var compactAmountRe = regexp.MustCompile(`^(\d+(?:\.\d+)?)([a-z]+)$`)
+
+// Amount reads "2 hours", "an hour", "1.5 h" or "90min" at the start of w, returning the
+// number, the unit and how many words it took
+func Amount(w []string) (n float64, unit time.Duration, k int) {
+	if len(w) == 0 {
+		return 0, 0, 0
+	}
+	if m := compactAmountRe.FindStringSubmatch(w[0]); m != nil {
+		if unit, ok := units[m[2]]; ok {
+			n, _ = strconv.ParseFloat(m[1], 64)
+			return n, unit, 1
+		}
+	}
+	if len(w) < 2 {
+		return 0, 0, 0
+	}
+	unit, ok := units[w[1]]
+	if !ok {
+		return 0, 0, 0
+	}
+	if w[0] == "a" || w[0] == "an" || w[0] == "one" {
+		return 1, unit, 2
+	}
+	n, err := strconv.ParseFloat(w[0], 64)
+	if err != nil || n <= 0 {
+		return 0, 0, 0
+	}
+	return n, unit, 2
+}
+
+// Month reads a month's name or abbreviation, in English or the language maily is
+// shown in
+func Month(s string) (time.Month, bool) {
+	s = strings.TrimSuffix(s, ".")
+	if m, ok := englishMonths[s]; ok {
+		return m, true
+	}
+	m, ok := localNames().months[s]
+	return m, ok
+}
+
+// Weekday reads a day of the week, with "weekend" for Saturday, in English or the
+// language maily is shown in
+func Weekday(s string) (time.Weekday, bool) {
+	s = strings.TrimSuffix(s, ".")
+	if s == "weekend" {
+		return time.Saturday, true
+	}
+	if day, ok := englishWeekdays[s]; ok {
+		return day, true
+	}
+	day, ok := localNames().weekdays[s]
+	return day, ok
+}
+
+var englishWeekdays = map[string]time.Weekday{
+	"sunday": time.Sunday, "monday": time.Monday, "tuesday": time.Tuesday, "wednesday": time.Wednesday,
+	"thursday": time.Thursday, "friday": time.Friday, "saturday": time.Saturday,
+	"sun": time.Sunday, "mon": time.Monday, "tue": time.Tuesday, "tues": time.Tuesday, "wed": time.Wednesday,
+	"thu": time.Thursday, "thur": time.Thursday, "thurs": time.Thursday, "fri": time.Friday, "sat": time.Saturday,
+}
+
+var englishMonths = func() map[string]time.Month {
+	months := map[string]time.Month{"sept": time.September}
+	for m := time.January; m <= time.December; m++ {
+		name := strings.ToLower(m.String())
+		months[name] = m
+		months[name[:3]] = m
+	}
+	return months
+}()
+
+// names are the month and weekday names of a language
+type names struct {
+	lang     string
+	months   map[string]time.Month
+	weekdays map[string]time.Weekday
+}
+
+var (
+	namesMu sync.Mutex
+	local   names
+)
+
+// localNames returns the names of the language maily is shown in, read from its
+// translations the first time they're needed
+func localNames() names {
+	namesMu.Lock()
+	defer namesMu.Unlock()
+	lang := i18n.CurrentLanguage()
+	if local.months != nil && local.lang == lang {
+		return local
+	}
+	local = names{lang: lang, months: make(map[string]time.Month), weekdays: make(map[string]time.Weekday)}
+	for _, id := range []string{"date.months", "date.months_long"} {
+		if list := nameList(id, 12); list != nil {
+			for i, name := range list {
+				if name != "" {
+					local.months[name] = time.January + time.Month(i)
+				}
+			}
+		}
+	}
+	for _, id := range []string{"date.weekdays", "date.weekdays_long"} {
+		if list := nameList(id, 7); list != nil {
+			for i, name := range list {
+				if name != "" {
+					local.weekdays[name] = time.Sunday + time.Weekday(i)
+				}
+			}
+		}
+	}
+	return local
+}
+
+// nameList returns the n space-separated names in a message, lower case and without a
+// closing period. Two-letter names in Latin script, such as German's So and Do, are
+// left empty as they're also common words.
+func nameList(id string, n int) []string {
+	fields := strings.Fields(i18n.T(id))
+	if len(fields) != n {
+		return nil
+	}
+	list := make([]string, 0, n)
+	for _, f := range fields {
+		name := strings.TrimSuffix(strings.ToLower(f), ".")
+		if utf8.RuneCountInString(name) < 3 && isASCII(name) {
+			name = ""
+		}
+		list = append(list, name)
+	}
+	return list
+}
+
+func isASCII(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if s[i] >= utf8.RuneSelf {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,165 @@
+package dateparse
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"maily/internal/i18n"
+)
+
+// today is a Wednesday
+var today = time.Date(2025, 3, 5, 0, 0, 0, 0, time.UTC)
+
+func day(y int, m time.Month, d int) time.Time {
+	return time.Date(y, m, d, 0, 0, 0, 0, time.UTC)
+}
+
+func TestDate(t *testing.T) {
+	tests := []struct {
+		input    string
+		dir      Direction
+		dayFirst bool
+		want     time.Time
+		n        int // words taken; 0 when it isn't a date
+	}{
+		{"jan 5", Future, false, day(2026, 1, 5), 2},
+		{"jan 5", Past, false, day(2025, 1, 5), 2},
+		{"dec 24", Past, false, day(2024, 12, 24), 2},
+		{"mar 5", Future, false, day(2025, 3, 5), 2},
+		{"mar 5", Past, false, day(2025, 3, 5), 2},
+		{"march 10th", Future, false, day(2025, 3, 10), 2},
+		{"5 jan", Future, false, day(2026, 1, 5), 2},
+		{"5th of january 2024", Future, false, day(2024, 1, 5), 4},
+		{"sept 1 2030 at noon", Future, false, day(2030, 9, 1), 3},
+		{"jan. 5", Future, false, day(2026, 1, 5), 2},
+		{"2025-01-05", Past, false, day(2025, 1, 5), 1},
+		{"2024/12/01", Past, false, day(2024, 12, 1), 1},
+		{"2024.12.01", Past, false, day(2024, 12, 1), 1},
+		{"1/5", Future, false, day(2026, 1, 5), 1},
+		{"1/5", Future, true, day(2025, 5, 1), 1},
+		{"1/5", Past, true, day(2024, 5, 1), 1},
+		{"1.5.25", Future, true, day(2025, 5, 1), 1},
+		{"12/31/2024", Future, false, day(2024, 12, 31), 1},
+		{"2024-02-29", Past, false, day(2024, 2, 29), 1},
+
+		{"feb 30", Future, false, time.Time{}, 0},
+		{"2025-02-29", Past, false, time.Time{}, 0},
+		{"13/13", Future, false, time.Time{}, 0},
+		{"3-4", Future, false, time.Time{}, 0},
+		{"jan", Future, false, time.Time{}, 0},
+		{"lunch", Future, false, time.Time{}, 0},
+		{"5 apples", Future, false, time.Time{}, 0},
+	}
+	for _, tt := range tests {
+		got, n := Date(strings.Fields(tt.input), today, tt.dir, tt.dayFirst)
+		if n != tt.n || !got.Equal(tt.want) {
+			t.Errorf("Date(%q, dir %d, dayFirst %v) = %s, %d; want %s, %d", tt.input, tt.dir, tt.dayFirst, got.Format(time.DateOnly), n, tt.want.Format(time.DateOnly), tt.n)
+		}
+	}
+}
+
+func TestWeekdayFrom(t *testing.T) {
+	tests := []struct {
+		day       time.Weekday
+		dir       Direction
+		skipToday bool
+		want      time.Time
+	}{
+		{time.Friday, Future, false, day(2025, 3, 7)},
+		{time.Wednesday, Future, false, day(2025, 3, 5)},
+		{time.Wednesday, Future, true, day(2025, 3, 12)},
+		{time.Monday, Future, false, day(2025, 3, 10)},
+		{time.Monday, Past, true, day(2025, 3, 3)},
+		{time.Wednesday, Past, true, day(2025, 2, 26)},
+		{time.Thursday, Past, true, day(2025, 2, 27)},
+	}
+	for _, tt := range tests {
+		if got := WeekdayFrom(today, tt.day, tt.dir, tt.skipToday); !got.Equal(tt.want) {
+			t.Errorf("WeekdayFrom(%s, dir %d, %v) = %s, want %s", tt.day, tt.dir, tt.skipToday, got.Format(time.DateOnly), tt.want.Format(time.DateOnly))
+		}
+	}
+}
+
+func TestAmount(t *testing.T) {
+	tests := []struct {
+		input string
+		n     float64
+		unit  time.Duration
+		k     int
+	}{
+		{"2 hours", 2, time.Hour, 2},
+		{"an hour", 1, time.Hour, 2},
+		{"1.5 h", 1.5, time.Hour, 2},
+		{"90min", 90, time.Minute, 1},
+		{"3 days ago", 3, 24 * time.Hour, 2},
+		{"2 weeks", 2, 7 * 24 * time.Hour, 2},
+		{"a month", 1, MonthUnit, 2},
+		{"2 years", 2, YearUnit, 2},
+		{"two hours", 0, 0, 0},
+		{"-1 days", 0, 0, 0},
+		{"5 apples", 0, 0, 0},
+		{"90", 0, 0, 0},
+	}
+	for _, tt := range tests {
+		n, unit, k := Amount(strings.Fields(tt.input))
+		if n != tt.n || unit != tt.unit || k != tt.k {
+			t.Errorf("Amount(%q) = %v, %v, %d; want %v, %v, %d", tt.input, n, unit, k, tt.n, tt.unit, tt.k)
+		}
+	}
+}
+
+func TestDayNumber(t *testing.T) {
+	tests := map[string]int{"5": 5, "5th": 5, "5.": 5, "21st": 21, "2nd": 2, "31": 31, "32": 0, "0": 0, "fifth": 0}
+	for s, want := range tests {
+		got, ok := DayNumber(s)
+		if ok != (want != 0) || (ok && got != want) {
+			t.Errorf("DayNumber(%q) = %d, %v; want %d", s, got, ok, want)
+		}
+	}
+}
+
+func TestLocalNames(t *testing.T) {
+	t.Cleanup(func() { i18n.Init("en") })
+
+	if err := i18n.Init("de"); err != nil {
+		t.Fatal(err)
+	}
+	if m, ok := Month("märz"); !ok || m != time.March {
+		t.Errorf("Month(märz) = %v, %v in German", m, ok)
+	}
+	if d, ok := Weekday("montag"); !ok || d != time.Monday {
+		t.Errorf("Weekday(montag) = %v, %v in German", d, ok)
+	}
+	if d, ok := Weekday("friday"); !ok || d != time.Friday {
+		t.Errorf("Weekday(friday) = %v, %v: English names are read in every language", d, ok)
+	}
+	// German's two-letter abbreviations are also words
+	if _, ok := Weekday("so"); ok {
+		t.Error(`Weekday("so") is a day in German`)
+	}
+	if got, n := Date([]string{"5", "dezember"}, today, Future, true); n != 2 || !got.Equal(day(2025, 12, 5)) {
+		t.Errorf("Date(5 dezember) = %s, %d in German", got.Format(time.DateOnly), n)
+	}
+
+	// Spanish "mar" is both March and Tuesday: with a day it's the month
+	if err := i18n.Init("es"); err != nil {
+		t.Fatal(err)
+	}
+	if got, n := Date([]string{"mar", "10"}, today, Future, true); n != 2 || !got.Equal(day(2025, 3, 10)) {
+		t.Errorf("Date(mar 10) = %s, %d in Spanish", got.Format(time.DateOnly), n)
+	}
+	if d, ok := Weekday("miércoles"); !ok || d != time.Wednesday {
+		t.Errorf("Weekday(miércoles) = %v, %v in Spanish", d, ok)
+	}
+
+	if err := i18n.Init("ru"); err != nil {
+		t.Fatal(err)
+	}
+	if got, n := Date([]string{"5", "января"}, today, Future, true); n != 2 || !got.Equal(day(2026, 1, 5)) {
+		t.Errorf("Date(5 января) = %s, %d in Russian", got.Format(time.DateOnly), n)
+	}
+	if d, ok := Weekday("пн"); !ok || d != time.Monday {
+		t.Errorf("Weekday(пн) = %v, %v in Russian", d, ok)
+	}
+}
@@ -0,0 +1,185 @@
+package digest
+
+import (
+	"fmt"
+	netmail "net/mail"
+	"slices"
+	"strings"
+	"time"
+
+	"maily/internal/auth"
+	"maily/internal/cache"
+	"maily/internal/calendar"
+	"maily/internal/mail"
+)
+
+// maxEmails bounds the unread emails read per account
+const maxEmails = 500
+
+// Digest is a morning summary: the day's events, the unread mail that needs attention
+// and new replies in followed threads
+type Digest struct {
+	Date      time.Time
+	Events    []calendar.Event
+	Calendar  error // why the events are missing, nil when they were read
+	Important []Email
+	FollowUps []Email
+	Unread    int // unread inbox emails across the accounts
+}
+
+// Email is an unread email listed in the digest
+type Email struct {
+	Account string
+	From    string
+	Subject string
+	Date    time.Time
+}
+
+// Collect reads the accounts' unread inbox mail from the cache. Emails from VIPs or
+// marked important by Gmail are important; unread emails in threads followed in
+// maily are follow-ups.
+func Collect(c *cache.Cache, accounts []string, vip func(sender string) bool, now time.Time) (Digest, error) {
+	d := Digest{Date: now}
+	for _, account := range accounts {
+		emails, err := c.LoadEmailsFiltered(account, "INBOX", cache.EmailFilter{UnreadOnly: true, Limit: maxEmails})
+		if err != nil {
+			return d, fmt.Errorf("%s: %w", account, err)
+		}
+		rules, err := c.GetThreadRules(account)
+		if err != nil {
+			return d, fmt.Errorf("%s: %w", account, err)
+		}
+
+		d.Unread += len(emails)
+		for _, e := range emails {
+			email := Email{Account: account, From: e.From, Subject: e.Subject, Date: e.InternalDate}
+			switch {
+			case followed(rules, e):
+				d.FollowUps = append(d.FollowUps, email)
+			case Important(e, vip):
+				d.Important = append(d.Important, email)
+			}
+		}
+	}
+	return d, nil
+}
+
+// followed reports whether an email is in a thread followed in maily
+func followed(rules map[string]cache.ThreadRule, e cache.CachedEmail) bool {
+	for _, id := range cache.ThreadIDs(e.MessageID, e.References) {
+		if rule, ok := rules[id]; ok && rule.Rule == cache.ThreadFollow {
+			return true
+		}
+	}
+	return false
+}
+
+// Important reports whether an email is from a VIP or marked important by Gmail
+func Important(e cache.CachedEmail, vip func(sender string) bool) bool {
+	return vip(senderAddress(e.From)) || slices.Contains(e.Labels, `\Important`)
+}
+
+// senderAddress returns the bare address of a From header
+func senderAddress(from string) string {
+	if addr, err := netmail.ParseAddress(from); err == nil {
+		return addr.Address
+	}
+	return strings.TrimSpace(from)
+}
+
+// TodayEvents reads the day's events without asking for calendar access, so the
+// server never prompts for it
+func TodayEvents(now time.Time) ([]calendar.Event, error) {
+	if calendar.GetAuthStatus() != calendar.AuthAuthorized {
+		return nil, fmt.Errorf("calendar access not granted")
+	}
+	client, err := calendar.NewClient()
+	if err != nil {
+		return nil, err
+	}
+	start := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	events, err := client.ListEvents(start, start.AddDate(0, 0, 1))
+	if err != nil {
+		return nil, err
+	}
+	slices.SortStableFunc(events, func(a, b calendar.Event) int {
+		return a.StartTime.Compare(b.StartTime)
+	})
+	return events, nil
+}
+
+// Subject is the subject of the digest email
+func (d Digest) Subject() string {
+	return "Daily digest for " + d.Date.Format("Monday, January 2")
+}
+
+// Markdown renders the digest as Markdown, which reads as plain text in an email
+func (d Digest) Markdown() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "# %s\n", d.Subject())
+
+	fmt.Fprintf(&b, "\n## Today's events (%d)\n\n", len(d.Events))
+	switch {
+	case d.Calendar != nil:
+		fmt.Fprintf(&b, "Calendar unavailable: %v\n", d.Calendar)
+	case len(d.Events) == 0:
+		b.WriteString("Nothing scheduled.\n")
+	}
+	for _, e := range d.Events {
+		when := "All day"
+		if !e.AllDay {
+			when = e.StartTime.Format("15:04") + "–" + e.EndTime.Format("15:04")
+		}
+		fmt.Fprintf(&b, "- %s  %s", when, e.Title)
+		if e.Location != "" {
+			fmt.Fprintf(&b, " (%s)", e.Location)
+		}
+		b.WriteString("\n")
+	}
+
+	fmt.Fprintf(&b, "\n## Important unread mail (%d of %d unread)\n\n", len(d.Important), d.Unread)
+	writeEmails(&b, d.Important, "No unread mail from VIPs or marked important.")
+
+	fmt.Fprintf(&b, "\n## Follow-ups (%d)\n\n", len(d.FollowUps))
+	writeEmails(&b, d.FollowUps, "No new replies in followed threads.")
+	return b.String()
+}
+
+// writeEmails lists emails one per line, grouped under their account when there are several
+func writeEmails(b *strings.Builder, emails []Email, none string) {
+	if len(emails) == 0 {
+		b.WriteString(none + "\n")
+		return
+	}
+	accounts := make(map[string]bool)
+	for _, e := range emails {
+		accounts[e.Account] = true
+	}
+	for _, e := range emails {
+		subject := e.Subject
+		if subject == "" {
+			subject = "(no subject)"
+		}
+		fmt.Fprintf(b, "- **%s**: %s", senderName(e.From), subject)
+		if len(accounts) > 1 {
+			fmt.Fprintf(b, " (%s)", e.Account)
+		}
+		b.WriteString("\n")
+	}
+}
+
+// senderName returns the display name of a From header, or its address
+func senderName(from string) string {
+	if addr, err := netmail.ParseAddress(from); err == nil {
+		if addr.Name != "" {
+			return addr.Name
+		}
+		return addr.Address
+	}
+	return strings.TrimSpace(from)
+}
+
+// Send mails the digest from account to the comma-separated recipients
+func Send(account *auth.Account, to string, d Digest) error {
+	return mail.NewSMTPClient(&account.Credentials).Send(to, d.Subject(), d.Markdown())
+}
@@ -0,0 +1,94 @@
+package digest
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"maily/internal/cache"
+	"maily/internal/calendar"
+)
+
+func setTempHome(t *testing.T) {
+	t.Helper()
+	dir := t.TempDir()
+	t.Setenv("HOME", dir)
+	t.Setenv("USERPROFILE", dir)
+}
+
+func TestCollect(t *testing.T) {
+	setTempHome(t)
+
+	c, err := cache.New()
+	if err != nil {
+		t.Fatalf("New() error: %v", err)
+	}
+	defer c.Close()
+
+	account := "me@example.com"
+	now := time.Now()
+	emails := []cache.CachedEmail{
+		{UID: 1, MessageID: "<a@x>", From: "Boss <boss@corp.com>", Subject: "Budget", Unread: true, InternalDate: now},
+		{UID: 2, MessageID: "<b@x>", From: "news@list.com", Subject: "Weekly", Unread: true, InternalDate: now},
+		{UID: 3, MessageID: "<c@x>", References: "<thread@x>", From: "Bob <bob@example.com>", Subject: "Re: Plan", Unread: true, InternalDate: now},
+		{UID: 4, MessageID: "<d@x>", From: "Boss <boss@corp.com>", Subject: "Read already", InternalDate: now},
+	}
+	for _, e := range emails {
+		if err := c.SaveEmail(account, "INBOX", e); err != nil {
+			t.Fatalf("SaveEmail %d error: %v", e.UID, err)
+		}
+	}
+	if err := c.SetThreadRule(account, []string{"<thread@x>"}, cache.ThreadFollow); err != nil {
+		t.Fatalf("SetThreadRule error: %v", err)
+	}
+
+	vip := func(sender string) bool { return sender == "boss@corp.com" }
+	d, err := Collect(c, []string{account}, vip, now)
+	if err != nil {
+		t.Fatalf("Collect error: %v", err)
+	}
+	if d.Unread != 3 {
+		t.Errorf("Unread = %d, want 3", d.Unread)
+	}
+	if len(d.Important) != 1 || d.Important[0].Subject != "Budget" {
+		t.Errorf("Important = %+v, want the unread email from the VIP", d.Important)
+	}
+	if len(d.FollowUps) != 1 || d.FollowUps[0].Subject != "Re: Plan" {
+		t.Errorf("FollowUps = %+v, want the reply in the followed thread", d.FollowUps)
+	}
+}
+
+func TestMarkdown(t *testing.T) {
+	day := time.Date(2025, 3, 10, 7, 0, 0, 0, time.Local)
+	d := Digest{
+		Date: day,
+		Events: []calendar.Event{
+			{Title: "Standup", StartTime: day.Add(2 * time.Hour), EndTime: day.Add(2*time.Hour + 15*time.Minute), Location: "Room 1"},
+			{Title: "Holiday", AllDay: true},
+		},
+		Important: []Email{{Account: "me@example.com", From: "Boss <boss@corp.com>", Subject: "Budget"}},
+		Unread:    5,
+	}
+
+	got := d.Markdown()
+	for _, want := range []string{
+		"# Daily digest for Monday, March 10\n",
+		"## Today's events (2)\n",
+		"- 09:00–09:15  Standup (Room 1)\n",
+		"- All day  Holiday\n",
+		"## Important unread mail (1 of 5 unread)\n",
+		"- **Boss**: Budget\n",
+		"No new replies in followed threads.\n",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("Markdown() missing %q in:\n%s", want, got)
+		}
+	}
+
+	d.Calendar = errors.New("calendar access not granted")
+	d.Events = nil
+	if got := d.Markdown(); !strings.Contains(got, "Calendar unavailable: calendar access not granted\n") {
+		t.Errorf("Markdown() doesn't say why events are missing:\n%s", got)
+	}
+}
@@ -0,0 +1,261 @@
+package export
+
+import (
+	"context"
+	"fmt"
+	"html"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+
+	"maily/config"
+	"maily/internal/htmlbody"
+	"maily/internal/mail"
+)
+
+// commandTimeout bounds the print command, which may start a headless browser
+const commandTimeout = 2 * time.Minute
+
+var unsafeFilenameChars = regexp.MustCompile(`[^\p{L}\p{N} ._-]+`)
+
+// Email is a message to print: its headers, body and the attachments it carries.
+// Attachments are listed, not embedded.
+type Email struct {
+	From        string
+	To          string
+	Cc          string
+	Subject     string
+	Date        time.Time
+	MessageID   string
+	BodyHTML    string
+	Attachments []Attachment
+}
+
+// Attachment is an attachment listed under the printed email
+type Attachment struct {
+	Filename string
+	Size     int64
+}
+
+// Text renders the headers, the body as plain text, and the attachment list
+func Text(e Email) string {
+	var b strings.Builder
+	for _, h := range headers(e) {
+		fmt.Fprintf(&b, "%s: %s\n", h[0], h[1])
+	}
+	b.WriteString("\n")
+
+	if text, ok := mail.UnwrapPlainText(e.BodyHTML); ok {
+		b.WriteString(strings.TrimRight(text, "\n"))
+	} else {
+		b.WriteString(strings.TrimSpace(htmlbody.ToMarkdown(e.BodyHTML)))
+	}
+	b.WriteString("\n")
+
+	if len(e.Attachments) > 0 {
+		fmt.Fprintf(&b, "\nAttachments (%d):\n", len(e.Attachments))
+		for _, a := range e.Attachments {
+			fmt.Fprintf(&b, "  %s (%s)\n", a.Filename, formatSize(a.Size))
+		}
+	}
+	return b.String()
+}
+
+// HTML renders a standalone page with a header block, the body and the attachment list,
+// for printing from a browser or converting to PDF
+func HTML(e Email) string {
+	var b strings.Builder
+	b.WriteString("<!DOCTYPE html>\n<html>\n<head>\n<meta charset=\"utf-8\">\n")
+	fmt.Fprintf(&b, "<title>%s</title>\n", html.EscapeString(e.Subject))
+	b.WriteString("<style>body{font-family:sans-serif;margin:2em}table.headers td{padding:2px 8px 2px 0;vertical-align:top}" +
+		"table.headers td:first-child{color:#666}hr{border:0;border-top:1px solid #ccc;margin:1em 0}</style>\n")
+	b.WriteString("</head>\n<body>\n")
+	fmt.Fprintf(&b, "<h2>%s</h2>\n<table class=\"headers\">\n", html.EscapeString(e.Subject))
+	for _, h := range headers(e) {
+		fmt.Fprintf(&b, "<tr><td>%s</td><td>%s</td></tr>\n", h[0], html.EscapeString(h[1]))
+	}
+	b.WriteString("</table>\n<hr>\n")
+
+	// Plain-text bodies are already stored escaped inside a <pre>, which sanitizing keeps
+	b.WriteString(htmlbody.Sanitize(e.BodyHTML))
+	b.WriteString("\n")
+
+	if len(e.Attachments) > 0 {
+		fmt.Fprintf(&b, "<hr>\n<p>Attachments (%d):</p>\n<ul>\n", len(e.Attachments))
+		for _, a := range e.Attachments {
+			fmt.Fprintf(&b, "<li>%s (%s)</li>\n", html.EscapeString(a.Filename), formatSize(a.Size))
+		}
+		b.WriteString("</ul>\n")
+	}
+	b.WriteString("</body>\n</html>\n")
+	return b.String()
+}
+
+// Print writes an email to a file in the configured format and returns its path. output
+// is the file to write; when empty a name is made from the date and subject in the
+// configured directory. Formats other than text and html are made by running the
+// configured command on the HTML rendering.
+func Print(e Email, cfg *config.PrintConfig, output string) (string, error) {
+	format := cfg.OutputFormat()
+	if format != config.PrintText && format != config.PrintHTML && (cfg == nil || cfg.Command == "") {
+		return "", fmt.Errorf("printing to %s needs print.command in config.yml, e.g. \"wkhtmltopdf {input} {output}\"", format)
+	}
+
+	if output == "" {
+		dir, err := outputDir(cfg)
+		if err != nil {
+			return "", err
+		}
+		output = uniquePath(filepath.Join(dir, Filename(e, format)))
+	} else if err := os.MkdirAll(filepath.Dir(output), 0755); err != nil {
+		return "", err
+	}
+
+	switch format {
+	case config.PrintText:
+		return output, os.WriteFile(output, []byte(Text(e)), 0644)
+	case config.PrintHTML:
+		return output, os.WriteFile(output, []byte(HTML(e)), 0644)
+	}
+
+	input, err := os.CreateTemp("", "maily-print-*.html")
+	if err != nil {
+		return "", err
+	}
+	defer os.Remove(input.Name())
+	if _, err := input.WriteString(HTML(e)); err != nil {
+		input.Close()
+		return "", err
+	}
+	input.Close()
+
+	if err := runCommand(cfg.Command, input.Name(), output); err != nil {
+		return "", err
+	}
+	if _, err := os.Stat(output); err != nil {
+		return "", fmt.Errorf("print command did not write %s", output)
+	}
+	return output, nil
+}
+
+// SaveRaw writes a message's RFC 822 source to an .eml file in the print directory
+// and returns its path
+func SaveRaw(e Email, raw []byte, cfg *config.PrintConfig) (string, error) {
+	dir, err := outputDir(cfg)
+	if err != nil {
+		return "", err
+	}
+	path := uniquePath(filepath.Join(dir, Filename(e, "eml")))
+	return path, os.WriteFile(path, raw, 0644)
+}
+
+// Filename builds a file name from the email's date and subject
+func Filename(e Email, format string) string {
+	subject := strings.TrimSpace(unsafeFilenameChars.ReplaceAllString(e.Subject, ""))
+	if subject == "" {
+		subject = "email"
+	}
+	if runes := []rune(subject); len(runes) > 80 {
+		subject = strings.TrimSpace(string(runes[:80]))
+	}
+	if !e.Date.IsZero() {
+		subject = e.Date.Format("2006-01-02") + " " + subject
+	}
+	if format == config.PrintText {
+		format = "txt"
+	}
+	return subject + "." + format
+}
+
+// runCommand runs the print command with {input} and {output} replaced in its arguments.
+// It runs without a shell, so paths with spaces stay one argument.
+func runCommand(command, input, output string) error {
+	args := strings.Fields(command)
+	if len(args) == 0 {
+		return fmt.Errorf("print command is empty")
+	}
+	for i, arg := range args {
+		arg = strings.ReplaceAll(arg, "{input}", input)
+		args[i] = strings.ReplaceAll(arg, "{output}", output)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), commandTimeout)
+	defer cancel()
+	out, err := exec.CommandContext(ctx, args[0], args[1:]...).CombinedOutput()
+	if err != nil {
+		msg := strings.TrimSpace(string(out))
+		if len(msg) > 200 {
+			msg = msg[:200] + "..."
+		}
+		if msg != "" {
+			return fmt.Errorf("print command failed: %w: %s", err, msg)
+		}
+		return fmt.Errorf("print command failed: %w", err)
+	}
+	return nil
+}
+
+func headers(e Email) [][2]string {
+	h := [][2]string{{"From", e.From}, {"To", e.To}}
+	if e.Cc != "" {
+		h = append(h, [2]string{"Cc", e.Cc})
+	}
+	h = append(h, [2]string{"Date", e.Date.Format(time.RFC1123Z)}, [2]string{"Subject", e.Subject})
+	if e.MessageID != "" {
+		h = append(h, [2]string{"Message-ID", "<" + strings.Trim(e.MessageID, "<>") + ">"})
+	}
+	return h
+}
+
+// outputDir returns the configured directory, or ~/Downloads/maily, creating it if needed
+func outputDir(cfg *config.PrintConfig) (string, error) {
+	dir := ""
+	if cfg != nil {
+		dir = cfg.Dir
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	switch {
+	case dir == "":
+		dir = filepath.Join(home, "Downloads", "maily")
+	case dir == "~" || strings.HasPrefix(dir, "~/"):
+		dir = filepath.Join(home, dir[1:])
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return "", err
+	}
+	return dir, nil
+}
+
+// uniquePath adds a counter to the name when the file already exists
+func uniquePath(path string) string {
+	if _, err := os.Stat(path); os.IsNotExist(err) {
+		return path
+	}
+	ext := filepath.Ext(path)
+	base := strings.TrimSuffix(path, ext)
+	for i := 1; ; i++ {
+		candidate := fmt.Sprintf("%s (%d)%s", base, i, ext)
+		if _, err := os.Stat(candidate); os.IsNotExist(err) {
+			return candidate
+		}
+	}
+}
+
+func formatSize(size int64) string {
+	const unit = 1024
+	if size < unit {
+		return fmt.Sprintf("%d B", size)
+	}
+	div, exp := int64(unit), 0
+	for n := size / unit; n >= unit; n /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %cB", float64(size)/float64(div), "KMGTPE"[exp])
+}
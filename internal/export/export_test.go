@@ -0,0 +1,122 @@
+package export
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"maily/config"
+)
+
+func testEmail() Email {
+	return Email{
+		From:        "Alice <alice@example.com>",
+		To:          "bob@example.com",
+		Subject:     "Q3 report: <final>",
+		Date:        time.Date(2025, 7, 1, 9, 30, 0, 0, time.UTC),
+		MessageID:   "abc@example.com",
+		BodyHTML:    "<p>See attached.</p>",
+		Attachments: []Attachment{{Filename: "report.pdf", Size: 2048}},
+	}
+}
+
+func TestText(t *testing.T) {
+	text := Text(testEmail())
+	for _, want := range []string{
+		"From: Alice <alice@example.com>\n",
+		"Subject: Q3 report: <final>\n",
+		"Message-ID: <abc@example.com>\n",
+		"See attached.",
+		"Attachments (1):\n  report.pdf (2.0 KB)\n",
+	} {
+		if !strings.Contains(text, want) {
+			t.Errorf("Text() missing %q in:\n%s", want, text)
+		}
+	}
+	if strings.Contains(text, "Cc:") {
+		t.Errorf("Text() printed an empty Cc header")
+	}
+}
+
+func TestHTMLEscapesHeaders(t *testing.T) {
+	page := HTML(testEmail())
+	if strings.Contains(page, "<final>") {
+		t.Errorf("HTML() did not escape the subject")
+	}
+	if !strings.Contains(page, "<p>See attached.</p>") {
+		t.Errorf("HTML() did not include the body")
+	}
+}
+
+func TestHTMLSanitizesBody(t *testing.T) {
+	e := testEmail()
+	e.BodyHTML = `<p onclick="steal()">Hi <a href="javascript:alert(1)">there</a></p>` +
+		`<script>alert(1)</script><img src="https://tracker.example.com/p.gif"><pre>a &lt; b</pre>`
+	page := HTML(e)
+	for _, bad := range []string{"onclick", "javascript:", "<script", "tracker.example.com"} {
+		if strings.Contains(page, bad) {
+			t.Errorf("HTML() kept %q:\n%s", bad, page)
+		}
+	}
+	for _, want := range []string{"<p>Hi ", "there", "<pre>a &lt; b</pre>"} {
+		if !strings.Contains(page, want) {
+			t.Errorf("HTML() lost %q:\n%s", want, page)
+		}
+	}
+}
+
+func TestFilename(t *testing.T) {
+	if got, want := Filename(testEmail(), config.PrintText), "2025-07-01 Q3 report final.txt"; got != want {
+		t.Errorf("Filename() = %q, want %q", got, want)
+	}
+	if got := Filename(Email{Subject: "///"}, config.PrintHTML); got != "email.html" {
+		t.Errorf("Filename() = %q, want %q", got, "email.html")
+	}
+	// Long subjects are cut by characters, never inside one
+	got := Filename(Email{Subject: strings.Repeat("日本語", 30)}, config.PrintHTML)
+	if want := strings.Repeat("日本語", 26) + "日本.html"; got != want {
+		t.Errorf("Filename() = %q, want %q", got, want)
+	}
+}
+
+func TestPrint(t *testing.T) {
+	dir := t.TempDir()
+	cfg := &config.PrintConfig{Format: config.PrintHTML, Dir: dir}
+
+	first, err := Print(testEmail(), cfg, "")
+	if err != nil {
+		t.Fatalf("Print() error: %v", err)
+	}
+	second, err := Print(testEmail(), cfg, "")
+	if err != nil {
+		t.Fatalf("Print() error: %v", err)
+	}
+	if first == second || filepath.Dir(first) != dir {
+		t.Errorf("Print() paths = %q, %q, want two files in %s", first, second, dir)
+	}
+	if _, err := os.Stat(second); err != nil {
+		t.Errorf("Print() did not write %s", second)
+	}
+
+	if _, err := Print(testEmail(), &config.PrintConfig{Format: config.PrintPDF, Dir: dir}, ""); err == nil {
+		t.Errorf("Print() to pdf without a command should fail")
+	}
+}
+
+func TestSaveRaw(t *testing.T) {
+	dir := t.TempDir()
+	raw := []byte("Subject: Q3 report\r\n\r\nBody\r\n")
+
+	path, err := SaveRaw(testEmail(), raw, &config.PrintConfig{Dir: dir})
+	if err != nil {
+		t.Fatalf("SaveRaw() error: %v", err)
+	}
+	if want := filepath.Join(dir, "2025-07-01 Q3 report final.eml"); path != want {
+		t.Errorf("SaveRaw() path = %q, want %q", path, want)
+	}
+	if got, _ := os.ReadFile(path); string(got) != string(raw) {
+		t.Errorf("SaveRaw() wrote %q, want %q", got, raw)
+	}
+}
@@ -0,0 +1,115 @@
+package hooks
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"os/exec"
+	"sync"
+	"time"
+
+	"maily/config"
+)
+
+// timeout bounds each command and webhook, so a hung script can't pile up runs
+const timeout = 30 * time.Second
+
+// Payload is the JSON a hook receives. Fields not about the event are left out.
+type Payload struct {
+	Event string    `json:"event"`
+	Time  time.Time `json:"time"`
+
+	// Mail events
+	Account string `json:"account,omitempty"`
+	Mailbox string `json:"mailbox,omitempty"`
+	UID     uint32 `json:"uid,omitempty"`
+	From    string `json:"from,omitempty"`
+	Subject string `json:"subject,omitempty"`
+
+	// sync_error
+	Error string `json:"error,omitempty"`
+
+	// event_soon
+	Title    string     `json:"title,omitempty"`
+	Start    *time.Time `json:"start,omitempty"`
+	Location string     `json:"location,omitempty"`
+}
+
+// Matching returns the hooks that run for the payload's event and account
+func Matching(hooks []config.Hook, p Payload) []config.Hook {
+	var matched []config.Hook
+	for _, h := range hooks {
+		if h.Event == p.Event && h.AppliesTo(p.Account) {
+			matched = append(matched, h)
+		}
+	}
+	return matched
+}
+
+// Run runs the hooks matching the payload side by side and waits for them, returning
+// their failures
+func Run(hooks []config.Hook, p Payload) error {
+	matched := Matching(hooks, p)
+	if len(matched) == 0 {
+		return nil
+	}
+	body, err := json.Marshal(p)
+	if err != nil {
+		return err
+	}
+
+	var wg sync.WaitGroup
+	errs := make([]error, len(matched))
+	for i, h := range matched {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			errs[i] = run(h, p.Event, body)
+		}()
+	}
+	wg.Wait()
+	return errors.Join(errs...)
+}
+
+// run runs a hook's command and posts to its URL, whichever it has
+func run(h config.Hook, event string, body []byte) error {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	var errs []error
+	if h.Command != "" {
+		cmd := exec.CommandContext(ctx, "sh", "-c", h.Command)
+		cmd.Stdin = bytes.NewReader(body)
+		cmd.Env = append(os.Environ(), "MAILY_EVENT="+event)
+		if out, err := cmd.CombinedOutput(); err != nil {
+			errs = append(errs, fmt.Errorf("%s hook %q: %w: %s", event, h.Command, err, bytes.TrimSpace(out)))
+		}
+	}
+	if h.URL != "" {
+		if err := post(ctx, h.URL, body); err != nil {
+			errs = append(errs, fmt.Errorf("%s hook %s: %w", event, h.URL, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func post(ctx context.Context, url string, body []byte) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("status %s", resp.Status)
+	}
+	return nil
+}
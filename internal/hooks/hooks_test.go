@@ -0,0 +1,87 @@
+package hooks
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"maily/config"
+)
+
+func TestMatching(t *testing.T) {
+	hooks := []config.Hook{
+		{Event: config.HookNewMail, Command: "all"},
+		{Event: config.HookNewMail, Command: "work", Account: "me@work.com"},
+		{Event: config.HookSyncError, Command: "errors"},
+	}
+
+	got := Matching(hooks, Payload{Event: config.HookNewMail, Account: "me@home.com"})
+	if len(got) != 1 || got[0].Command != "all" {
+		t.Errorf("Matching(home new_mail) = %+v, want only the hook for every account", got)
+	}
+	got = Matching(hooks, Payload{Event: config.HookNewMail, Account: "ME@work.com"})
+	if len(got) != 2 {
+		t.Errorf("Matching(work new_mail) = %+v, want both new_mail hooks", got)
+	}
+	if got := Matching(hooks, Payload{Event: config.HookEventSoon}); len(got) != 0 {
+		t.Errorf("Matching(event_soon) = %+v, want none", got)
+	}
+}
+
+func TestRunCommand(t *testing.T) {
+	out := filepath.Join(t.TempDir(), "payload.json")
+	hooks := []config.Hook{{Event: config.HookNewMail, Command: `cat > "$OUT"; echo "$MAILY_EVENT" > "$OUT.event"`}}
+	t.Setenv("OUT", out)
+
+	p := Payload{Event: config.HookNewMail, Time: time.Now(), Account: "me@example.com", UID: 7, Subject: "Hello"}
+	if err := Run(hooks, p); err != nil {
+		t.Fatalf("Run error: %v", err)
+	}
+	data, err := os.ReadFile(out)
+	if err != nil {
+		t.Fatalf("hook didn't write its input: %v", err)
+	}
+	var got Payload
+	if err := json.Unmarshal(data, &got); err != nil {
+		t.Fatalf("stdin isn't the JSON payload: %v\n%s", err, data)
+	}
+	if got.UID != 7 || got.Subject != "Hello" {
+		t.Errorf("payload = %+v, want UID 7 and subject Hello", got)
+	}
+	if event, _ := os.ReadFile(out + ".event"); string(event) != "new_mail\n" {
+		t.Errorf("MAILY_EVENT = %q, want new_mail", event)
+	}
+
+	failing := []config.Hook{{Event: config.HookNewMail, Command: "exit 3"}}
+	if err := Run(failing, p); err == nil {
+		t.Error("Run of a failing command returned nil error")
+	}
+}
+
+func TestRunURL(t *testing.T) {
+	var got Payload
+	var contentType string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		contentType = r.Header.Get("Content-Type")
+		json.NewDecoder(r.Body).Decode(&got)
+		if got.Error == "reject" {
+			w.WriteHeader(http.StatusBadRequest)
+		}
+	}))
+	defer srv.Close()
+
+	hooks := []config.Hook{{Event: config.HookSyncError, URL: srv.URL}}
+	if err := Run(hooks, Payload{Event: config.HookSyncError, Error: "timeout"}); err != nil {
+		t.Fatalf("Run error: %v", err)
+	}
+	if contentType != "application/json" || got.Error != "timeout" {
+		t.Errorf("posted %q %+v, want the JSON payload", contentType, got)
+	}
+	if err := Run(hooks, Payload{Event: config.HookSyncError, Error: "reject"}); err == nil {
+		t.Error("Run returned nil error for a 400 response")
+	}
+}
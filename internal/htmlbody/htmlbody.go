@@ -0,0 +1,83 @@
+// Package htmlbody turns the HTML bodies of emails into markdown for reading in the
+// terminal, and into HTML safe to write out for a browser
+package htmlbody
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/JohannesKaufmann/html-to-markdown/v2/converter"
+	"github.com/JohannesKaufmann/html-to-markdown/v2/plugin/base"
+	"github.com/JohannesKaufmann/html-to-markdown/v2/plugin/commonmark"
+	"github.com/microcosm-cc/bluemonday"
+)
+
+var (
+	styleRegex   = regexp.MustCompile(`(?is)<style[^>]*>.*?</style>`)
+	scriptRegex  = regexp.MustCompile(`(?is)<script[^>]*>.*?</script>`)
+	headRegex    = regexp.MustCompile(`(?is)<head[^>]*>.*?</head>`)
+	imgRegex     = regexp.MustCompile(`(?is)<img[^>]*>`)
+	multiNewline = regexp.MustCompile(`\n{3,}`)
+	// Clean up markdown artifacts: image links, tracking pixels, link references
+	imgLinkRegex = regexp.MustCompile(`!\[[^\]]*\]\([^)]*\)`)
+	linkRefRegex = regexp.MustCompile(`(?m)^\[\d+\]:\s*https?://[^\s]*\.(png|jpg|jpeg|gif|webp|svg)[^\s]*$`)
+	emptyLinkRef = regexp.MustCompile(`(?m)^\[\d+\]:\s*https?://[^\s]*(imgping|tracking|pixel)[^\s]*$`)
+)
+
+// strip drops the parts of an HTML body that aren't content: styles, scripts, the head
+// and images, which would load tracking pixels
+func strip(htmlBody string) string {
+	cleaned := styleRegex.ReplaceAllString(htmlBody, "")
+	cleaned = scriptRegex.ReplaceAllString(cleaned, "")
+	cleaned = headRegex.ReplaceAllString(cleaned, "")
+	return imgRegex.ReplaceAllString(cleaned, "")
+}
+
+// ToMarkdown converts an HTML email body to plain markdown without terminal styling
+func ToMarkdown(htmlBody string) string {
+	cleaned := strip(htmlBody)
+
+	// Convert HTML to Markdown (fresh converter each time to avoid state issues)
+	conv := converter.NewConverter(
+		converter.WithPlugins(
+			base.NewBasePlugin(),
+			commonmark.NewCommonmarkPlugin(),
+		),
+	)
+	markdown, err := conv.ConvertString(cleaned)
+	if err != nil {
+		return stripTags(cleaned)
+	}
+
+	// Clean up markdown artifacts
+	markdown = imgLinkRegex.ReplaceAllString(markdown, "") // Remove image links
+	markdown = linkRefRegex.ReplaceAllString(markdown, "") // Remove image URL references
+	markdown = emptyLinkRef.ReplaceAllString(markdown, "") // Remove tracking pixel references
+	return multiNewline.ReplaceAllString(markdown, "\n\n")
+}
+
+// policy keeps the formatting of user-generated HTML and drops scripts, event handlers,
+// forms and javascript: links
+var policy = bluemonday.UGCPolicy()
+
+// Sanitize returns an HTML body safe to open in a browser: what the read view leaves
+// out is dropped, and so is anything that could run or load content
+func Sanitize(htmlBody string) string {
+	return policy.Sanitize(strip(htmlBody))
+}
+
+func stripTags(html string) string {
+	var result strings.Builder
+	inTag := false
+	for _, r := range html {
+		if r == '<' {
+			inTag = true
+		} else if r == '>' {
+			inTag = false
+			result.WriteRune(' ')
+		} else if !inTag {
+			result.WriteRune(r)
+		}
+	}
+	return strings.TrimSpace(result.String())
+}
@@ -0,0 +1,41 @@
+package htmlbody
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestToMarkdown(t *testing.T) {
+	body := `<html><head><title>x</title><style>p{color:red}</style></head><body>` +
+		`<p>Hello <b>Ana</b></p><img src="https://tracker.example.com/p.gif"><script>alert(1)</script>` +
+		`<p><a href="https://example.com">Docs</a></p></body></html>`
+	got := ToMarkdown(body)
+	for _, want := range []string{"Hello **Ana**", "[Docs](https://example.com)"} {
+		if !strings.Contains(got, want) {
+			t.Errorf("ToMarkdown() = %q, missing %q", got, want)
+		}
+	}
+	for _, bad := range []string{"color:red", "tracker", "alert", "title"} {
+		if strings.Contains(got, bad) {
+			t.Errorf("ToMarkdown() = %q, kept %q", got, bad)
+		}
+	}
+}
+
+func TestSanitize(t *testing.T) {
+	tests := []struct {
+		body, want string
+	}{
+		{`<p onclick="x()">Hi</p>`, `<p>Hi</p>`},
+		{`<a href="javascript:alert(1)">link</a>`, `link`},
+		{`<script>alert(1)</script>ok`, `ok`},
+		{`<img src="https://tracker.example.com/p.gif">ok`, `ok`},
+		{`<iframe src="https://example.com"></iframe>ok`, `ok`},
+		{`<pre>a &lt; b</pre>`, `<pre>a &lt; b</pre>`},
+	}
+	for _, tt := range tests {
+		if got := Sanitize(tt.body); got != tt.want {
+			t.Errorf("Sanitize(%q) = %q, want %q", tt.body, got, tt.want)
+		}
+	}
+}
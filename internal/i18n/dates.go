@@ -0,0 +1,129 @@
+package i18n
+
+import (
+	"strings"
+	"time"
+)
+
+var (
+	relativeDates = true
+	clockHours    = 0  // 12 or 24; 0 uses the language's clock
+	weekStart     = "" // monday or sunday; "" uses the language's first day
+)
+
+// SetDateFormat sets how dates are shown: relative ("5m ago", "yesterday") for recent
+// mail or always absolute, and a 12 or 24-hour clock, 0 for the language's own
+func SetDateFormat(relative bool, clock int) {
+	relativeDates = relative
+	clockHours = clock
+}
+
+// SetWeekStart sets the first day of the week, "monday" or "sunday"; "" follows the
+// language
+func SetWeekStart(day string) {
+	weekStart = day
+}
+
+// FirstWeekday returns the day calendars start their weeks on
+func FirstWeekday() time.Weekday {
+	day := weekStart
+	if day == "" {
+		day = T("date.week_start")
+	}
+	if day == "monday" {
+		return time.Monday
+	}
+	return time.Sunday
+}
+
+// FormatDate formats an email date for a list column: how long ago for recent mail when
+// dates are relative, otherwise the time today, the day this year, or the full date
+func FormatDate(t time.Time) string {
+	return formatDate(t.Local(), time.Now())
+}
+
+func formatDate(t, now time.Time) string {
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	if relativeDates {
+		switch ago := now.Sub(t); {
+		case ago < 0:
+			// A clock running ahead of ours; fall through to the absolute date
+		case ago < time.Minute:
+			return T("date.now")
+		case ago < time.Hour:
+			return T("date.minutes_ago", map[string]any{"Count": int(ago.Minutes())})
+		case !t.Before(today):
+			return T("date.hours_ago", map[string]any{"Count": int(ago.Hours())})
+		case !t.Before(today.AddDate(0, 0, -1)):
+			return T("date.yesterday")
+		case !t.Before(today.AddDate(0, 0, -6)):
+			return weekdayName(t.Weekday())
+		}
+	}
+	switch {
+	case !t.Before(today) && t.Before(today.AddDate(0, 0, 1)):
+		return FormatClock(t)
+	case t.Year() == now.Year():
+		return formatDay(t, false)
+	default:
+		return formatDay(t, true)
+	}
+}
+
+// FormatDateTime formats a date in full, for email headers: weekday, day and time
+func FormatDateTime(t time.Time) string {
+	return weekdayName(t.Weekday()) + ", " + formatDay(t, true) + " " + FormatClock(t)
+}
+
+// FormatClock formats the time of day on the configured or language's clock
+func FormatClock(t time.Time) string {
+	if use12HourClock() {
+		period := T("date.am")
+		if t.Hour() >= 12 {
+			period = T("date.pm")
+		}
+		return T("date.time_12", map[string]any{"Time": t.Format("3:04"), "Period": period})
+	}
+	return t.Format("15:04")
+}
+
+func use12HourClock() bool {
+	if clockHours != 0 {
+		return clockHours == 12
+	}
+	return T("date.clock") == "12"
+}
+
+// formatDay formats a day in the language's order, with or without the year
+func formatDay(t time.Time, withYear bool) string {
+	data := map[string]any{
+		"Day":         t.Day(),
+		"Month":       nameAt("date.months", int(t.Month())-1, t.Format("Jan")),
+		"MonthNumber": int(t.Month()),
+		"Year":        t.Year(),
+	}
+	if withYear {
+		return T("date.day_year", data)
+	}
+	return T("date.day", data)
+}
+
+func weekdayName(day time.Weekday) string {
+	return nameAt("date.weekdays", int(day), day.String()[:3])
+}
+
+// nameAt returns the i-th of the space separated names in a message, or fallback
+func nameAt(id string, i int, fallback string) string {
+	names := strings.Fields(T(id))
+	if i < 0 || i >= len(names) {
+		return fallback
+	}
+	return names[i]
+}
+
+// DayFirst reports whether the language writes the day before the month, as in 5/1
+// for the 5th of January
+func DayFirst() bool {
+	s := T("date.day_year", map[string]any{"Day": "\x01", "Month": "\x02", "MonthNumber": "\x02", "Year": "\x03"})
+	return strings.Index(s, "\x01") < strings.Index(s, "\x02")
+}
@@ -0,0 +1,119 @@
+package i18n
+
+import (
+	"testing"
+	"time"
+)
+
+// restoreDates puts back English and the default date settings after a test
+func restoreDates(t *testing.T) {
+	t.Cleanup(func() {
+		Init("en")
+		SetDateFormat(true, 0)
+		SetWeekStart("")
+	})
+}
+
+// useLanguage switches to a language, loading it only when it changes, and date settings
+func useLanguage(t *testing.T, code string, relative bool, clock int) {
+	t.Helper()
+	if code != CurrentLanguage() {
+		if err := Init(code); err != nil {
+			t.Fatal(err)
+		}
+	}
+	SetDateFormat(relative, clock)
+}
+
+func TestFormatDate(t *testing.T) {
+	// A Wednesday afternoon
+	restoreDates(t)
+	now := time.Date(2025, 3, 5, 15, 30, 0, 0, time.UTC)
+	tests := []struct {
+		lang     string
+		relative bool
+		t        time.Time
+		want     string
+	}{
+		{"en", true, now.Add(-20 * time.Second), "just now"},
+		{"en", true, now.Add(-5 * time.Minute), "5m ago"},
+		{"en", true, now.Add(-3 * time.Hour), "3h ago"},
+		{"en", true, now.AddDate(0, 0, -1), "yesterday"},
+		{"en", true, now.AddDate(0, 0, -3), "Sun"},
+		{"en", true, now.AddDate(0, 0, -10), "Feb 23"},
+		{"en", true, now.Add(time.Hour), "4:30 PM"}, // a clock running ahead
+		{"en", false, now.Add(-5 * time.Minute), "3:25 PM"},
+		{"en", false, now.AddDate(0, 0, -1), "Mar 4"},
+		{"en", false, time.Date(2024, 12, 24, 9, 0, 0, 0, time.UTC), "Dec 24, 2024"},
+		{"de", false, now.Add(-5 * time.Minute), "15:25"},
+		{"de", false, time.Date(2024, 12, 24, 9, 0, 0, 0, time.UTC), "24. Dez. 2024"},
+		{"de", true, now.Add(-3 * time.Hour), "vor 3 Std."},
+		{"ja", false, now.AddDate(0, 0, -10), "2月23日"},
+		{"ja", false, time.Date(2024, 12, 24, 9, 0, 0, 0, time.UTC), "2024/12/24"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.lang+" "+tt.want, func(t *testing.T) {
+			useLanguage(t, tt.lang, tt.relative, 0)
+			if got := formatDate(tt.t, now); got != tt.want {
+				t.Errorf("formatDate(%s) = %q, want %q", tt.t, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFormatClock(t *testing.T) {
+	morning := time.Date(2025, 3, 5, 9, 5, 0, 0, time.UTC)
+	evening := time.Date(2025, 3, 5, 21, 45, 0, 0, time.UTC)
+	restoreDates(t)
+	tests := []struct {
+		lang             string
+		clock            int
+		morning, evening string
+	}{
+		{"en", 0, "9:05 AM", "9:45 PM"},
+		{"en", 24, "09:05", "21:45"},
+		{"de", 0, "09:05", "21:45"},
+		{"de", 12, "9:05 vorm.", "9:45 nachm."},
+		{"ko", 12, "오전 9:05", "오후 9:45"},
+		{"ja", 12, "午前9:05", "午後9:45"},
+		{"zh-Hant", 12, "上午9:05", "下午9:45"},
+		{"es", 12, "9:05 a. m.", "9:45 p. m."},
+	}
+	for _, tt := range tests {
+		t.Run(tt.lang, func(t *testing.T) {
+			useLanguage(t, tt.lang, true, tt.clock)
+			if got := FormatClock(morning); got != tt.morning {
+				t.Errorf("FormatClock(9:05) = %q, want %q", got, tt.morning)
+			}
+			if got := FormatClock(evening); got != tt.evening {
+				t.Errorf("FormatClock(21:45) = %q, want %q", got, tt.evening)
+			}
+		})
+	}
+}
+
+func TestDayFirstAndWeekStart(t *testing.T) {
+	tests := []struct {
+		lang     string
+		dayFirst bool
+		week     time.Weekday
+	}{
+		{"en", false, time.Sunday},
+		{"de", true, time.Monday},
+		{"ja", false, time.Sunday},
+	}
+	restoreDates(t)
+	for _, tt := range tests {
+		useLanguage(t, tt.lang, true, 0)
+		if got := DayFirst(); got != tt.dayFirst {
+			t.Errorf("DayFirst() = %v in %s", got, tt.lang)
+		}
+		if got := FirstWeekday(); got != tt.week {
+			t.Errorf("FirstWeekday() = %s in %s", got, tt.lang)
+		}
+	}
+	SetWeekStart("monday")
+	if got := FirstWeekday(); got != time.Monday {
+		t.Errorf("FirstWeekday() = %s with monday set", got)
+	}
+}
@@ -0,0 +1,52 @@
+package i18n
+
+import (
+	"strings"
+	"testing"
+
+	"gopkg.in/yaml.v3"
+)
+
+func loadLocale(t *testing.T, code string) map[string]any {
+	t.Helper()
+	data, err := localesFS.ReadFile("locales/" + code + ".yml")
+	if err != nil {
+		t.Fatalf("read %s: %v", code, err)
+	}
+	var m map[string]any
+	if err := yaml.Unmarshal(data, &m); err != nil {
+		t.Fatalf("parse %s: %v", code, err)
+	}
+	return m
+}
+
+func TestLocalesHaveEveryEnglishKey(t *testing.T) {
+	en := loadLocale(t, "en")
+	for _, code := range SupportedLanguages {
+		if code == "en" {
+			continue
+		}
+		loc := loadLocale(t, code)
+		var missing []string
+		for key := range en {
+			if _, ok := loc[key]; !ok {
+				missing = append(missing, key)
+			}
+		}
+		if len(missing) > 0 {
+			t.Errorf("%s is missing %d keys, e.g. %s", code, len(missing), strings.Join(missing[:min(5, len(missing))], ", "))
+		}
+	}
+}
+
+func TestAttributionIsTranslated(t *testing.T) {
+	for _, code := range SupportedLanguages {
+		if err := Init(code); err != nil {
+			t.Fatalf("Init(%s): %v", code, err)
+		}
+		got := T("compose.attribution", map[string]any{"Date": "DATE", "From": "FROM"})
+		if !strings.Contains(got, "DATE") || !strings.Contains(got, "FROM") {
+			t.Errorf("%s: compose.attribution = %q, want both placeholders filled", code, got)
+		}
+	}
+}
@@ -0,0 +1,99 @@
+package keychain
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os/exec"
+	"runtime"
+	"strings"
+)
+
+var (
+	// ErrUnavailable means the platform has no usable credential store
+	ErrUnavailable = errors.New("no OS keychain available")
+	// ErrNotFound means the store holds no secret for the service and account
+	ErrNotFound = errors.New("secret not found in keychain")
+)
+
+// Get reads a secret from the OS credential store.
+// On macOS, uses the login keychain via security. On Linux, uses the Secret Service via secret-tool.
+func Get(service, account string) (string, error) {
+	switch runtime.GOOS {
+	case "darwin":
+		return getMacOS(service, account)
+	case "linux":
+		return getLinux(service, account)
+	default:
+		return "", ErrUnavailable
+	}
+}
+
+// Set stores a secret in the OS credential store, replacing any existing one
+func Set(service, account, secret string) error {
+	switch runtime.GOOS {
+	case "darwin":
+		return setMacOS(service, account, secret)
+	case "linux":
+		return setLinux(service, account, secret)
+	default:
+		return ErrUnavailable
+	}
+}
+
+func getMacOS(service, account string) (string, error) {
+	if _, err := exec.LookPath("security"); err != nil {
+		return "", ErrUnavailable
+	}
+	out, err := exec.Command("security", "find-generic-password", "-s", service, "-a", account, "-w").Output()
+	if err != nil {
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) && exitErr.ExitCode() == 44 { // errSecItemNotFound
+			return "", ErrNotFound
+		}
+		return "", fmt.Errorf("security: %w", err)
+	}
+	return strings.TrimSpace(string(out)), nil
+}
+
+func setMacOS(service, account, secret string) error {
+	if _, err := exec.LookPath("security"); err != nil {
+		return ErrUnavailable
+	}
+	// security only reads the password from its arguments (or an interactive prompt)
+	cmd := exec.Command("security", "add-generic-password", "-U", "-s", service, "-a", account, "-w", secret)
+	if out, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("security: %s", strings.TrimSpace(string(out)))
+	}
+	return nil
+}
+
+func getLinux(service, account string) (string, error) {
+	if _, err := exec.LookPath("secret-tool"); err != nil {
+		return "", ErrUnavailable
+	}
+	var stderr bytes.Buffer
+	cmd := exec.Command("secret-tool", "lookup", "service", service, "account", account)
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		// A missing item exits 1 silently; anything else means no Secret Service is running
+		if stderr.Len() == 0 {
+			return "", ErrNotFound
+		}
+		return "", fmt.Errorf("%w: %s", ErrUnavailable, strings.TrimSpace(stderr.String()))
+	}
+	return strings.TrimSpace(string(out)), nil
+}
+
+func setLinux(service, account, secret string) error {
+	if _, err := exec.LookPath("secret-tool"); err != nil {
+		return ErrUnavailable
+	}
+	cmd := exec.Command("secret-tool", "store", "--label", service+" "+account, "service", service, "account", account)
+	cmd.Stdin = strings.NewReader(secret)
+	if out, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("%w: %s", ErrUnavailable, strings.TrimSpace(string(out)))
+	}
+	return nil
+}
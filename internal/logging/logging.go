@@ -0,0 +1,92 @@
+package logging
+
+import (
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"maily/config"
+)
+
+const (
+	// DefaultMaxSizeMB is the log file size that triggers rotation
+	DefaultMaxSizeMB = 5
+	// DefaultMaxBackups is the number of rotated log files kept
+	DefaultMaxBackups = 3
+)
+
+// Log file names (without extension) for each process
+const (
+	ServerLog = "server"
+	TUILog    = "maily"
+)
+
+// GetLogDir returns the directory holding maily log files
+func GetLogDir() string {
+	homeDir, _ := os.UserHomeDir()
+	return filepath.Join(homeDir, ".config", "maily", "logs")
+}
+
+// GetLogPath returns the path of the named log file
+func GetLogPath(name string) string {
+	return filepath.Join(GetLogDir(), name+".log")
+}
+
+// ParseLevel converts a config level string to a slog level (default: info)
+func ParseLevel(level string) slog.Level {
+	switch strings.ToLower(level) {
+	case "debug":
+		return slog.LevelDebug
+	case "warn", "warning":
+		return slog.LevelWarn
+	case "error":
+		return slog.LevelError
+	default:
+		return slog.LevelInfo
+	}
+}
+
+// Setup installs a default slog logger writing to the named rotating log file.
+// If console is non-nil, log records are also written there.
+func Setup(name string, cfg *config.LoggingConfig, console io.Writer) (io.Closer, error) {
+	if cfg == nil {
+		cfg = &config.LoggingConfig{}
+	}
+
+	maxSize := cfg.MaxSizeMB
+	if maxSize <= 0 {
+		maxSize = DefaultMaxSizeMB
+	}
+	maxBackups := cfg.MaxBackups
+	if maxBackups <= 0 {
+		maxBackups = DefaultMaxBackups
+	}
+
+	if err := os.MkdirAll(GetLogDir(), 0700); err != nil {
+		return nil, fmt.Errorf("failed to create log directory: %w", err)
+	}
+
+	file, err := NewRotatingWriter(GetLogPath(name), int64(maxSize)*1024*1024, maxBackups)
+	if err != nil {
+		return nil, err
+	}
+
+	var out io.Writer = file
+	if console != nil {
+		out = io.MultiWriter(file, console)
+	}
+
+	opts := &slog.HandlerOptions{Level: ParseLevel(cfg.Level)}
+	var handler slog.Handler
+	if strings.ToLower(cfg.Format) == "json" {
+		handler = slog.NewJSONHandler(out, opts)
+	} else {
+		handler = slog.NewTextHandler(out, opts)
+	}
+
+	slog.SetDefault(slog.New(handler).With("pid", os.Getpid()))
+	return file, nil
+}
@@ -0,0 +1,67 @@
+package logging
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestRotatingWriterRotatesAndKeepsBackups(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "test.log")
+
+	w, err := NewRotatingWriter(path, 10, 2)
+	if err != nil {
+		t.Fatalf("NewRotatingWriter error: %v", err)
+	}
+	defer w.Close()
+
+	for _, line := range []string{"aaaaaaaa\n", "bbbbbbbb\n", "cccccccc\n", "dddddddd\n"} {
+		if _, err := w.Write([]byte(line)); err != nil {
+			t.Fatalf("Write error: %v", err)
+		}
+	}
+
+	read := func(p string) string {
+		t.Helper()
+		data, err := os.ReadFile(p)
+		if err != nil {
+			t.Fatalf("ReadFile(%s) error: %v", p, err)
+		}
+		return string(data)
+	}
+
+	if got := read(path); got != "dddddddd\n" {
+		t.Fatalf("current log = %q, want last line only", got)
+	}
+	if got := read(path + ".1"); got != "cccccccc\n" {
+		t.Fatalf("backup 1 = %q, want %q", got, "cccccccc\n")
+	}
+	if got := read(path + ".2"); got != "bbbbbbbb\n" {
+		t.Fatalf("backup 2 = %q, want %q", got, "bbbbbbbb\n")
+	}
+	if _, err := os.Stat(path + ".3"); !os.IsNotExist(err) {
+		t.Fatalf("expected no third backup, stat err = %v", err)
+	}
+}
+
+func TestRotatingWriterAppendsToExistingFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "test.log")
+	if err := os.WriteFile(path, []byte("existing\n"), 0600); err != nil {
+		t.Fatalf("WriteFile error: %v", err)
+	}
+
+	w, err := NewRotatingWriter(path, 1024, 1)
+	if err != nil {
+		t.Fatalf("NewRotatingWriter error: %v", err)
+	}
+	if _, err := w.Write([]byte("appended\n")); err != nil {
+		t.Fatalf("Write error: %v", err)
+	}
+	w.Close()
+
+	data, _ := os.ReadFile(path)
+	if !strings.HasPrefix(string(data), "existing\n") || !strings.HasSuffix(string(data), "appended\n") {
+		t.Fatalf("log content = %q, want existing content followed by appended line", data)
+	}
+}
@@ -0,0 +1,213 @@
+package mail
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/mail"
+	"strings"
+	"time"
+)
+
+// mxLookupTimeout bounds each recipient domain lookup so a slow resolver doesn't hold up sending
+const mxLookupTimeout = 3 * time.Second
+
+// DNS lookups of recipient domains, replaced in tests
+var (
+	lookupMX   = net.DefaultResolver.LookupMX
+	lookupHost = net.DefaultResolver.LookupHost
+)
+
+// Misspellings of popular mail domains, mapped to the domain that was probably meant
+var domainTypos = map[string]string{
+	"gamil.com":     "gmail.com",
+	"gmial.com":     "gmail.com",
+	"gmal.com":      "gmail.com",
+	"gmaill.com":    "gmail.com",
+	"gmai.com":      "gmail.com",
+	"gnail.com":     "gmail.com",
+	"gmail.co":      "gmail.com",
+	"gmail.cm":      "gmail.com",
+	"gmail.con":     "gmail.com",
+	"gmail.om":      "gmail.com",
+	"googlemail.co": "googlemail.com",
+	"hotmial.com":   "hotmail.com",
+	"hotmal.com":    "hotmail.com",
+	"hotmai.com":    "hotmail.com",
+	"homail.com":    "hotmail.com",
+	"hotmail.co":    "hotmail.com",
+	"hotmail.con":   "hotmail.com",
+	"outlok.com":    "outlook.com",
+	"outloo.com":    "outlook.com",
+	"outlook.co":    "outlook.com",
+	"outlook.con":   "outlook.com",
+	"yaho.com":      "yahoo.com",
+	"yahooo.com":    "yahoo.com",
+	"yhoo.com":      "yahoo.com",
+	"yahoo.co":      "yahoo.com",
+	"yahoo.con":     "yahoo.com",
+	"icloud.co":     "icloud.com",
+	"iclod.com":     "icloud.com",
+	"icoud.com":     "icloud.com",
+	"iclould.com":   "icloud.com",
+	"protonmail.co": "protonmail.com",
+	"protonmal.com": "protonmail.com",
+	"proton.me.com": "proton.me",
+	"qq.co":         "qq.com",
+	"163.co":        "163.com",
+	"126.co":        "126.com",
+}
+
+// Popular mail domains; a domain one typo away from a longer one is probably a typo
+var commonDomains = []string{
+	"gmail.com", "googlemail.com", "hotmail.com", "outlook.com", "live.com", "msn.com",
+	"yahoo.com", "icloud.com", "me.com", "mac.com", "aol.com", "protonmail.com",
+	"proton.me", "gmx.com", "gmx.de", "web.de", "mail.ru", "yandex.ru", "qq.com",
+	"163.com", "126.com", "naver.com", "daum.net",
+}
+
+// AddressWarning describes a recipient that probably can't receive mail
+type AddressWarning struct {
+	Address    string
+	Problem    string
+	Suggestion string // corrected address, empty when there's no likely fix
+}
+
+func (w AddressWarning) String() string {
+	if w.Suggestion != "" {
+		return fmt.Sprintf("%s: %s - did you mean %s?", w.Address, w.Problem, w.Suggestion)
+	}
+	return fmt.Sprintf("%s: %s", w.Address, w.Problem)
+}
+
+// CheckAddresses warns about recipients that are malformed or whose domain looks like
+// a typo of a popular mail domain. Addresses may include a display name.
+func CheckAddresses(addresses []string) []AddressWarning {
+	var warnings []AddressWarning
+	for _, raw := range addresses {
+		parsed, err := mail.ParseAddress(raw)
+		if err != nil {
+			warnings = append(warnings, AddressWarning{Address: raw, Problem: "not a valid email address"})
+			continue
+		}
+		local, domain, _ := strings.Cut(parsed.Address, "@")
+		domain = strings.ToLower(domain)
+		if !strings.Contains(domain, ".") {
+			warnings = append(warnings, AddressWarning{Address: parsed.Address, Problem: "domain has no top-level part"})
+			continue
+		}
+		if fix := SuggestDomain(domain); fix != "" {
+			warnings = append(warnings, AddressWarning{
+				Address:    parsed.Address,
+				Problem:    "possible typo in the domain",
+				Suggestion: local + "@" + fix,
+			})
+		}
+	}
+	return warnings
+}
+
+// SuggestDomain returns the popular mail domain a domain is probably a typo of, or ""
+func SuggestDomain(domain string) string {
+	domain = strings.ToLower(domain)
+	if fix, ok := domainTypos[domain]; ok {
+		return fix
+	}
+	for _, d := range commonDomains {
+		if d == domain {
+			return ""
+		}
+	}
+	for _, d := range commonDomains {
+		// Short names such as qq or me are one typo away from too many real domains
+		if strings.Index(d, ".") >= 5 && oneEditApart(domain, d) {
+			return d
+		}
+	}
+	return ""
+}
+
+// CheckDomains looks up the mail servers of the recipients' domains, warning about
+// domains that don't exist or accept no mail. Lookups that fail for other reasons,
+// such as being offline, aren't reported.
+func CheckDomains(ctx context.Context, addresses []string) []AddressWarning {
+	var warnings []AddressWarning
+	checked := map[string]bool{}
+	for _, raw := range addresses {
+		parsed, err := mail.ParseAddress(raw)
+		if err != nil {
+			continue
+		}
+		_, domain, _ := strings.Cut(parsed.Address, "@")
+		domain = strings.ToLower(domain)
+		if checked[domain] {
+			continue
+		}
+		checked[domain] = true
+		if problem := lookupDomain(ctx, domain); problem != "" {
+			warnings = append(warnings, AddressWarning{Address: parsed.Address, Problem: problem})
+		}
+	}
+	return warnings
+}
+
+// lookupDomain returns why a domain can't receive mail, or "" when it can or the
+// lookup was inconclusive
+func lookupDomain(ctx context.Context, domain string) string {
+	ctx, cancel := context.WithTimeout(ctx, mxLookupTimeout)
+	defer cancel()
+
+	mx, err := lookupMX(ctx, domain)
+	if err == nil {
+		// A single "." record is a null MX: the domain accepts no mail (RFC 7505)
+		if len(mx) == 1 && mx[0].Host == "." {
+			return "domain does not accept email"
+		}
+		if len(mx) > 0 {
+			return ""
+		}
+	} else if !isNotFound(err) {
+		return ""
+	}
+
+	// Without MX records, mail goes to the domain's own address (RFC 5321)
+	if _, err := lookupHost(ctx, domain); isNotFound(err) {
+		return "domain has no mail server"
+	}
+	return ""
+}
+
+func isNotFound(err error) bool {
+	var dnsErr *net.DNSError
+	return errors.As(err, &dnsErr) && dnsErr.IsNotFound
+}
+
+// oneEditApart reports whether a and b differ by one inserted, removed, replaced or
+// swapped neighbouring character
+func oneEditApart(a, b string) bool {
+	if a == b {
+		return false
+	}
+	if len(a) > len(b) {
+		a, b = b, a
+	}
+	switch len(b) - len(a) {
+	case 0:
+		i := 0
+		for i < len(a) && a[i] == b[i] {
+			i++
+		}
+		if a[i+1:] == b[i+1:] {
+			return true
+		}
+		return i+1 < len(a) && a[i] == b[i+1] && a[i+1] == b[i] && a[i+2:] == b[i+2:]
+	case 1:
+		i := 0
+		for i < len(a) && a[i] == b[i] {
+			i++
+		}
+		return a[i:] == b[i+1:]
+	}
+	return false
+}
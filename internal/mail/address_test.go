@@ -0,0 +1,142 @@
+package mail
+
+import (
+	"context"
+	"errors"
+	"net"
+	"testing"
+)
+
+func TestSuggestDomain(t *testing.T) {
+	tests := map[string]string{
+		// The typo table
+		"gamil.com":     "gmail.com",
+		"GMAIL.CON":     "gmail.com",
+		"hotmial.com":   "hotmail.com",
+		"proton.me.com": "proton.me",
+		"qq.co":         "qq.com",
+		// One edit from a popular domain
+		"gmaul.com":    "gmail.com", // replaced
+		"gmaile.com":   "gmail.com", // inserted
+		"outlok.com":   "outlook.com",
+		"yhaoo.com":    "yahoo.com", // swapped
+		"gmail.cmo":    "gmail.com", // swapped at the end
+		"iclou.com":    "icloud.com",
+		"protonmail.c": "", // two edits
+		// No suggestion
+		"gmail.com":    "",
+		"example.com":  "",
+		"me.co":        "", // too short a name to guess from
+		"gmx.dk":       "",
+		"web.com":      "",
+		"yahoo.com.au": "",
+	}
+	for domain, want := range tests {
+		if got := SuggestDomain(domain); got != want {
+			t.Errorf("SuggestDomain(%q) = %q, want %q", domain, got, want)
+		}
+	}
+}
+
+func TestOneEditApart(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want bool
+	}{
+		{"abc", "abc", false},
+		{"abc", "abd", true},
+		{"abc", "xbc", true},
+		{"abc", "ab", true},
+		{"ab", "abc", true},
+		{"abc", "bc", true},
+		{"abc", "acb", true}, // swap at the last character
+		{"abc", "bac", true},
+		{"abc", "cba", false},
+		{"abcd", "abdc", true},
+		{"abcd", "badc", false},
+		{"abc", "a", false},
+		{"", "a", true},
+		{"", "", false},
+	}
+	for _, tt := range tests {
+		if got := oneEditApart(tt.a, tt.b); got != tt.want {
+			t.Errorf("oneEditApart(%q, %q) = %v, want %v", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
+
+func TestCheckAddresses(t *testing.T) {
+	warnings := CheckAddresses([]string{
+		"Ana <ana@gmail.com>",
+		"bo@gamil.com",
+		"Cy <cy@Outlok.com>",
+		"not an address",
+		"dee@localhost",
+		"eve@example.com",
+	})
+	want := []AddressWarning{
+		{Address: "bo@gamil.com", Problem: "possible typo in the domain", Suggestion: "bo@gmail.com"},
+		{Address: "cy@Outlok.com", Problem: "possible typo in the domain", Suggestion: "cy@outlook.com"},
+		{Address: "not an address", Problem: "not a valid email address"},
+		{Address: "dee@localhost", Problem: "domain has no top-level part"},
+	}
+	if len(warnings) != len(want) {
+		t.Fatalf("CheckAddresses() = %v, want %v", warnings, want)
+	}
+	for i := range want {
+		if warnings[i] != want[i] {
+			t.Errorf("warning %d = %+v, want %+v", i, warnings[i], want[i])
+		}
+	}
+	if got := warnings[0].String(); got != "bo@gamil.com: possible typo in the domain - did you mean bo@gmail.com?" {
+		t.Errorf("String() = %q", got)
+	}
+}
+
+func TestCheckDomains(t *testing.T) {
+	notFound := &net.DNSError{Err: "no such host", IsNotFound: true}
+	mx := map[string][]*net.MX{
+		"example.com":    {{Host: "mx.example.com.", Pref: 10}},
+		"nomail.example": {{Host: ".", Pref: 0}}, // null MX
+	}
+	hosts := map[string]bool{"direct.example": true}
+	origMX, origHost := lookupMX, lookupHost
+	defer func() { lookupMX, lookupHost = origMX, origHost }()
+	lookupMX = func(_ context.Context, domain string) ([]*net.MX, error) {
+		if domain == "offline.example" {
+			return nil, errors.New("network is unreachable")
+		}
+		if records, ok := mx[domain]; ok {
+			return records, nil
+		}
+		return nil, notFound
+	}
+	lookupHost = func(_ context.Context, domain string) ([]string, error) {
+		if hosts[domain] {
+			return []string{"192.0.2.1"}, nil
+		}
+		return nil, notFound
+	}
+
+	warnings := CheckDomains(context.Background(), []string{
+		"a@example.com",
+		"b@nomail.example",
+		"c@direct.example", // no MX, but an address to deliver to
+		"d@missing.example",
+		"e@Missing.example", // checked once
+		"f@offline.example", // inconclusive
+		"not an address",
+	})
+	want := []AddressWarning{
+		{Address: "b@nomail.example", Problem: "domain does not accept email"},
+		{Address: "d@missing.example", Problem: "domain has no mail server"},
+	}
+	if len(warnings) != len(want) {
+		t.Fatalf("CheckDomains() = %v, want %v", warnings, want)
+	}
+	for i := range want {
+		if warnings[i] != want[i] {
+			t.Errorf("warning %d = %+v, want %+v", i, warnings[i], want[i])
+		}
+	}
+}
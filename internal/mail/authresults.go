@@ -0,0 +1,188 @@
+package mail
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"net/mail"
+	"net/textproto"
+	"strings"
+)
+
+// AuthCheck is one method's result from an Authentication-Results header, e.g.
+// dkim=pass header.d=example.com
+type AuthCheck struct {
+	Method     string // spf, dkim, dmarc, arc, ...
+	Result     string // pass, fail, softfail, neutral, none, temperror, permerror, ...
+	Properties [][2]string
+}
+
+// Property returns the value of a property such as header.d, or ""
+func (c AuthCheck) Property(name string) string {
+	for _, p := range c.Properties {
+		if strings.EqualFold(p[0], name) {
+			return p[1]
+		}
+	}
+	return ""
+}
+
+// AuthResults summarizes the sender authentication the receiving server recorded.
+// Only the topmost Authentication-Results header is used: it was added by the
+// account's own provider, while lower ones could have been written by the sender.
+type AuthResults struct {
+	ServerID    string // the provider that ran the checks, e.g. mx.google.com
+	SPF         string // result per method, empty when not checked
+	DKIM        string
+	DMARC       string
+	FromDomain  string
+	DKIMDomains []string // domains of the passing DKIM signatures
+	Checks      []AuthCheck
+	ReceivedSPF string // the Received-SPF header, used when Authentication-Results has no spf
+}
+
+// ParseAuthResults reads the authentication headers from a message header block
+func ParseAuthResults(header []byte) AuthResults {
+	var r AuthResults
+	tp := textproto.NewReader(bufio.NewReader(bytes.NewReader(header)))
+	h, _ := tp.ReadMIMEHeader() // a partial header still has what was read
+
+	if from, err := mail.ParseAddress(h.Get("From")); err == nil {
+		if _, domain, ok := strings.Cut(from.Address, "@"); ok {
+			r.FromDomain = strings.ToLower(domain)
+		}
+	}
+
+	if ar := h.Get("Authentication-Results"); ar != "" {
+		r.ServerID, r.Checks = parseAuthResultsHeader(ar)
+	}
+	for _, c := range r.Checks {
+		switch c.Method {
+		case "spf":
+			r.SPF = betterResult(r.SPF, c.Result)
+		case "dmarc":
+			r.DMARC = betterResult(r.DMARC, c.Result)
+		case "dkim":
+			r.DKIM = betterResult(r.DKIM, c.Result)
+			if c.Result == "pass" {
+				if d := dkimDomain(c); d != "" {
+					r.DKIMDomains = append(r.DKIMDomains, d)
+				}
+			}
+		}
+	}
+
+	r.ReceivedSPF = h.Get("Received-SPF")
+	if r.SPF == "" && r.ReceivedSPF != "" {
+		result, _, _ := strings.Cut(strings.TrimSpace(r.ReceivedSPF), " ")
+		r.SPF = strings.ToLower(result)
+	}
+	return r
+}
+
+// Checked reports whether the provider recorded any authentication results
+func (r AuthResults) Checked() bool {
+	return r.SPF != "" || r.DKIM != "" || r.DMARC != ""
+}
+
+// Warning explains why the sender is likely forged: DMARC failed, or the only valid
+// DKIM signatures belong to a domain unrelated to the From address. Empty when neither.
+func (r AuthResults) Warning() string {
+	if r.DMARC == "fail" {
+		return fmt.Sprintf("DMARC failed: this email may not be from %s", r.FromDomain)
+	}
+	if r.DMARC == "pass" || r.FromDomain == "" || len(r.DKIMDomains) == 0 {
+		return ""
+	}
+	for _, d := range r.DKIMDomains {
+		if domainsAligned(d, r.FromDomain) {
+			return ""
+		}
+	}
+	return fmt.Sprintf("Signed by %s, not %s: the sender may be forged", r.DKIMDomains[0], r.FromDomain)
+}
+
+// parseAuthResultsHeader splits "authserv-id; method=result prop=value; ..." into its
+// checks, ignoring (comments)
+func parseAuthResultsHeader(value string) (string, []AuthCheck) {
+	parts := strings.Split(stripComments(value), ";")
+	serverID, _, _ := strings.Cut(strings.TrimSpace(parts[0]), " ")
+
+	var checks []AuthCheck
+	for _, part := range parts[1:] {
+		fields := strings.Fields(part)
+		if len(fields) == 0 {
+			continue
+		}
+		method, result, ok := strings.Cut(fields[0], "=")
+		if !ok {
+			continue
+		}
+		c := AuthCheck{Method: strings.ToLower(method), Result: strings.ToLower(result)}
+		for _, f := range fields[1:] {
+			if k, v, ok := strings.Cut(f, "="); ok {
+				c.Properties = append(c.Properties, [2]string{strings.ToLower(k), strings.Trim(v, `"`)})
+			}
+		}
+		checks = append(checks, c)
+	}
+	return serverID, checks
+}
+
+// stripComments removes parenthesized comments, which may nest
+func stripComments(s string) string {
+	var b strings.Builder
+	depth := 0
+	for _, r := range s {
+		switch {
+		case r == '(':
+			depth++
+		case r == ')' && depth > 0:
+			depth--
+		case depth == 0:
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
+
+// dkimDomain returns the signing domain of a DKIM result, from header.d or header.i
+func dkimDomain(c AuthCheck) string {
+	if d := c.Property("header.d"); d != "" {
+		return strings.ToLower(d)
+	}
+	if i := c.Property("header.i"); i != "" {
+		_, domain, _ := strings.Cut(i, "@")
+		return strings.ToLower(domain)
+	}
+	return ""
+}
+
+// betterResult keeps pass over any other result, so one valid signature among
+// several counts
+func betterResult(current, result string) string {
+	if current == "pass" {
+		return current
+	}
+	return result
+}
+
+// domainsAligned reports whether two domains share an organizational domain, as DMARC's
+// relaxed alignment does, e.g. mail.example.com and example.com
+func domainsAligned(a, b string) bool {
+	return orgDomain(a) == orgDomain(b)
+}
+
+// orgDomain approximates the registrable part of a domain: the last two labels, or
+// three under short second-level labels such as co.uk or com.au
+func orgDomain(domain string) string {
+	labels := strings.Split(strings.TrimSuffix(strings.ToLower(domain), "."), ".")
+	n := 2
+	if len(labels) > 2 && len(labels[len(labels)-1]) == 2 && len(labels[len(labels)-2]) <= 3 {
+		n = 3
+	}
+	if len(labels) <= n {
+		return strings.Join(labels, ".")
+	}
+	return strings.Join(labels[len(labels)-n:], ".")
+}
@@ -0,0 +1,161 @@
+package mail
+
+import (
+	"slices"
+	"strings"
+	"testing"
+)
+
+// authHeader joins header lines into a header block
+func authHeader(lines ...string) []byte {
+	return []byte(strings.Join(lines, "\r\n") + "\r\n\r\n")
+}
+
+func TestParseAuthResults(t *testing.T) {
+	tests := []struct {
+		name              string
+		header            []byte
+		spf, dkim, dmarc  string
+		serverID, from    string
+		dkimDomains       []string
+		checked, warnsYou bool
+	}{
+		{
+			name: "all pass",
+			header: authHeader(
+				"From: Alice <alice@mail.example.com>",
+				"Authentication-Results: mx.google.com;",
+				"       dkim=pass header.i=@example.com header.s=s1 header.b=abc;",
+				"       spf=pass (google.com: domain of alice@example.com designates 1.2.3.4 as permitted sender) smtp.mailfrom=alice@example.com;",
+				"       dmarc=pass (p=REJECT sp=REJECT dis=NONE) header.from=example.com",
+			),
+			spf: "pass", dkim: "pass", dmarc: "pass",
+			serverID: "mx.google.com", from: "mail.example.com",
+			dkimDomains: []string{"example.com"},
+			checked:     true,
+		},
+		{
+			name: "dmarc fails",
+			header: authHeader(
+				"From: bank@example.com",
+				"Authentication-Results: mx.example.net; spf=fail smtp.mailfrom=example.com; dkim=none; dmarc=fail header.from=example.com",
+			),
+			spf: "fail", dkim: "none", dmarc: "fail",
+			serverID: "mx.example.net", from: "example.com",
+			checked: true, warnsYou: true,
+		},
+		{
+			name: "signed by an unrelated domain",
+			header: authHeader(
+				"From: PayPal <service@paypal.com>",
+				`Authentication-Results: mx.example.net; dkim=pass header.d="evil.example"; dmarc=none`,
+			),
+			dkim: "pass", dmarc: "none",
+			serverID: "mx.example.net", from: "paypal.com",
+			dkimDomains: []string{"evil.example"},
+			checked:     true, warnsYou: true,
+		},
+		{
+			name: "one passing signature among several",
+			header: authHeader(
+				"From: news@shop.co.uk",
+				"Authentication-Results: mx.example.net; dkim=fail header.d=esp.example; dkim=pass header.d=mail.shop.co.uk; dkim=neutral header.d=other.example",
+			),
+			dkim:     "pass",
+			serverID: "mx.example.net", from: "shop.co.uk",
+			dkimDomains: []string{"mail.shop.co.uk"},
+			checked:     true,
+		},
+		{
+			name: "only the topmost header counts",
+			header: authHeader(
+				"From: a@example.com",
+				"Authentication-Results: mx.example.net; dmarc=fail",
+				"Authentication-Results: forged.example; dmarc=pass",
+			),
+			dmarc:    "fail",
+			serverID: "mx.example.net", from: "example.com",
+			checked: true, warnsYou: true,
+		},
+		{
+			name: "received-spf when results have no spf",
+			header: authHeader(
+				"From: a@example.com",
+				"Received-SPF: SoftFail (example.net: domain of transitioning a@example.com) client-ip=1.2.3.4;",
+			),
+			spf:     "softfail",
+			from:    "example.com",
+			checked: true,
+		},
+		{
+			name:   "no authentication headers",
+			header: authHeader("From: a@example.com", "Subject: hi"),
+			from:   "example.com",
+		},
+		{
+			name:   "malformed header block",
+			header: []byte("From: a@example.com\r\nAuthentication-Results: mx.example.net; dkim=pass\r\nnot a header"),
+			dkim:   "pass", serverID: "mx.example.net", from: "example.com",
+			checked: true,
+		},
+		{
+			name:     "results without methods",
+			header:   authHeader("Authentication-Results: mx.example.net; none"),
+			serverID: "mx.example.net",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := ParseAuthResults(tt.header)
+			if r.SPF != tt.spf || r.DKIM != tt.dkim || r.DMARC != tt.dmarc {
+				t.Errorf("spf %q, dkim %q, dmarc %q; want %q, %q, %q", r.SPF, r.DKIM, r.DMARC, tt.spf, tt.dkim, tt.dmarc)
+			}
+			if r.ServerID != tt.serverID || r.FromDomain != tt.from {
+				t.Errorf("server %q, from %q; want %q, %q", r.ServerID, r.FromDomain, tt.serverID, tt.from)
+			}
+			if !slices.Equal(r.DKIMDomains, tt.dkimDomains) {
+				t.Errorf("DKIM domains %v, want %v", r.DKIMDomains, tt.dkimDomains)
+			}
+			if r.Checked() != tt.checked {
+				t.Errorf("Checked() = %v, want %v", r.Checked(), tt.checked)
+			}
+			if w := r.Warning(); (w != "") != tt.warnsYou {
+				t.Errorf("Warning() = %q, want a warning: %v", w, tt.warnsYou)
+			}
+		})
+	}
+}
+
+func TestAuthCheckProperty(t *testing.T) {
+	r := ParseAuthResults(authHeader(`Authentication-Results: mx.example.net; dkim=pass (good signature) Header.D="Example.com" header.s=sel`))
+	if len(r.Checks) != 1 {
+		t.Fatalf("checks = %+v, want one", r.Checks)
+	}
+	c := r.Checks[0]
+	if c.Property("header.d") != "Example.com" || c.Property("HEADER.S") != "sel" || c.Property("header.b") != "" {
+		t.Errorf("properties = %v", c.Properties)
+	}
+}
+
+func TestOrgDomain(t *testing.T) {
+	tests := map[string]string{
+		"example.com":            "example.com",
+		"mail.example.com":       "example.com",
+		"a.b.mail.example.com":   "example.com",
+		"Mail.Example.COM.":      "example.com",
+		"shop.co.uk":             "shop.co.uk",
+		"mail.shop.co.uk":        "shop.co.uk",
+		"news.store.com.au":      "store.com.au",
+		"mail.example.de":        "example.de",
+		"localhost":              "localhost",
+		"bounces.mailer.example": "mailer.example",
+	}
+	for domain, want := range tests {
+		if got := orgDomain(domain); got != want {
+			t.Errorf("orgDomain(%q) = %q, want %q", domain, got, want)
+		}
+	}
+	if !domainsAligned("mail.example.com", "example.com") || domainsAligned("example.com", "example.net") {
+		t.Error("domainsAligned() doesn't compare organizational domains")
+	}
+}
@@ -0,0 +1,67 @@
+package mail
+
+import (
+	"strings"
+
+	"github.com/emersion/go-imap/v2"
+	"github.com/emersion/go-imap/v2/imapclient"
+)
+
+// capGmailExt is advertised by Gmail for X-GM-RAW search, X-GM-LABELS and X-GM-MSGID
+const capGmailExt imap.Cap = "X-GM-EXT-1"
+
+// capCompressDeflate is advertised by servers that can deflate the connection (RFC 4978)
+const capCompressDeflate imap.Cap = "COMPRESS=DEFLATE"
+
+// Capabilities records the IMAP extensions a server supports, probed once after login.
+// Features branch on these rather than on the account's provider.
+type Capabilities struct {
+	Move        bool // MOVE, or IMAP4rev2 which includes it
+	UIDPlus     bool // UID EXPUNGE, for expunging only the messages we flagged
+	Sort        bool // server-side SORT
+	SpecialUse  bool // \Trash, \Junk, ... mailbox attributes
+	GmailExt    bool // X-GM-EXT-1: Gmail search syntax and labels
+	Quota       bool // QUOTA storage usage and limits
+	Compress    bool // COMPRESS=DEFLATE, used when the account turns on compress
+	LiteralPlus bool // LITERAL+ non-synchronizing literals, which go-imap sends unasked
+	Notmuch     bool // a local notmuch database: notmuch search syntax and tags as labels
+}
+
+// probeCapabilities reads the capabilities the server advertises after login
+func probeCapabilities(client *imapclient.Client) Capabilities {
+	caps := client.Caps()
+	rev2 := caps.Has(imap.CapIMAP4rev2)
+	return Capabilities{
+		Move:        rev2 || caps.Has(imap.CapMove),
+		UIDPlus:     rev2 || caps.Has(imap.CapUIDPlus),
+		Sort:        caps.Has(imap.CapSort),
+		SpecialUse:  caps.Has(imap.CapSpecialUse),
+		GmailExt:    caps.Has(capGmailExt),
+		Quota:       caps.Has(imap.CapQuota),
+		Compress:    caps.Has(capCompressDeflate),
+		LiteralPlus: caps.Has(imap.CapLiteralPlus),
+	}
+}
+
+// String lists the supported extensions, for logs
+func (c Capabilities) String() string {
+	var names []string
+	for _, ext := range []struct {
+		name string
+		ok   bool
+	}{
+		{"MOVE", c.Move}, {"UIDPLUS", c.UIDPlus}, {"SORT", c.Sort}, {"SPECIAL-USE", c.SpecialUse},
+		{string(capGmailExt), c.GmailExt}, {"QUOTA", c.Quota}, {string(capCompressDeflate), c.Compress},
+		{string(imap.CapLiteralPlus), c.LiteralPlus}, {"NOTMUCH", c.Notmuch},
+	} {
+		if ext.ok {
+			names = append(names, ext.name)
+		}
+	}
+	return strings.Join(names, " ")
+}
+
+// Capabilities returns the extensions the server supports
+func (c *IMAPClient) Capabilities() Capabilities {
+	return c.caps
+}
@@ -0,0 +1,81 @@
+package mail
+
+import (
+	"time"
+
+	"github.com/emersion/go-imap/v2"
+
+	"maily/internal/auth"
+)
+
+// Client is a connection to an account's mail: an IMAP server, or a local Maildir kept
+// in sync by tools like mbsync or offlineimap. Methods taking a bare UID act on the
+// mailbox last selected, as with IMAP.
+type Client interface {
+	Close() error
+	Capabilities() Capabilities
+
+	ListMailboxes() ([]string, error)
+	SelectMailbox(name string) error
+	SelectMailboxWithInfo(name string) (*MailboxInfo, error)
+	CountMessages(mailbox string) (int, error)
+	FindSpecialFolder(kind string) (string, error)
+	FindTrashFolder() (string, error)
+
+	FetchUIDsAndFlags(mailbox string, since time.Time) (map[imap.UID]Flags, error)
+	FetchAllUIDs(mailbox string) (map[imap.UID]bool, error)
+	FetchMessages(mailbox string, limit uint32) ([]Email, error)
+	FetchMessagesMetadata(mailbox string, limit uint32) ([]Email, error)
+	FetchMessagesSince(mailbox string, since time.Time, limit uint32) ([]Email, error)
+	FetchMessagesByUIDs(mailbox string, uids []imap.UID) ([]Email, error)
+	FetchMessagesByUIDsMetadata(mailbox string, uids []imap.UID) ([]Email, error)
+	FetchOlderMetadata(mailbox string, before imap.UID, limit int) ([]Email, error)
+	FetchByUIDs(mailbox string, uids []imap.UID) ([]Email, error)
+	FetchEmailBody(mailbox string, uid imap.UID) (bodyHTML string, snippet string, err error)
+	FetchRaw(mailbox string, uid imap.UID) ([]byte, error)
+	FetchHeader(mailbox string, uid imap.UID) ([]byte, error)
+	FetchAttachment(mailbox string, uid imap.UID, partID string, encoding string) ([]byte, error)
+	FindUIDByMessageID(mailbox, messageID string) (imap.UID, error)
+	SearchMessages(mailbox string, query string) ([]Email, error)
+
+	IsUnread(uid imap.UID) (bool, error)
+	MarkAsRead(uid imap.UID) error
+	MarkAsUnread(uid imap.UID) error
+	MarkAsAnswered(uid imap.UID) error
+	MarkMessagesAsRead(uids []imap.UID) error
+	DeleteMessage(uid imap.UID) error
+	DeleteMessages(uids []imap.UID) error
+	MoveMessages(uids []imap.UID, from, to string) error
+	MoveToTrashFromMailbox(uids []imap.UID, mailbox string) error
+	MoveToSpam(uids []imap.UID, mailbox string) error
+	ArchiveFromMailbox(uids []imap.UID, mailbox string) error
+	EmptyFolder(mailbox string) (int, error)
+	SaveDraft(to, subject, body string) error
+
+	FetchLabels(mailbox string, uids []imap.UID) (map[imap.UID][]string, error)
+	UpdateLabels(mailbox string, uids []imap.UID, add, remove []string) error
+	StorageQuota() (*StorageQuota, error)
+	Stats() ConnStats
+}
+
+var (
+	_ Client = (*IMAPClient)(nil)
+	_ Client = (*MaildirClient)(nil)
+)
+
+// NewClient connects to an account's mail: its Maildir for local accounts, otherwise its
+// IMAP server
+func NewClient(creds *auth.Credentials) (Client, error) {
+	if creds.Provider == auth.ProviderMaildir {
+		c, err := NewMaildirClient(creds)
+		if err != nil {
+			return nil, err
+		}
+		return c, nil
+	}
+	c, err := NewIMAPClient(creds)
+	if err != nil {
+		return nil, err
+	}
+	return c, nil
+}
@@ -0,0 +1,337 @@
+package mail
+
+import (
+	"bufio"
+	"bytes"
+	"compress/flate"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/emersion/go-imap/v2"
+	"github.com/emersion/go-imap/v2/imapclient"
+
+	"maily/internal/auth"
+)
+
+// COMPRESS=DEFLATE (RFC 4978) deflates both directions of a connection after login,
+// which shrinks large syncs over slow links severalfold. go-imap doesn't implement it, so
+// maily logs in over the raw connection, turns compression on, and hands go-imap the
+// inflated stream behind a PREAUTH greeting, as if the server had logged it in up front.
+// Non-synchronizing literals (LITERAL+) need nothing from maily: go-imap sends them
+// whenever the server advertises the extension.
+
+// preauthGreeting stands in for the server's greeting once maily has logged in itself
+const preauthGreeting = "* PREAUTH maily logged in\r\n"
+
+// ConnStats counts the traffic of an account's IMAP connection
+type ConnStats struct {
+	Since       time.Time `json:"since"`        // when the connection was opened
+	Compressed  bool      `json:"compressed"`   // COMPRESS=DEFLATE in use
+	LiteralPlus bool      `json:"literal_plus"` // non-synchronizing literals (LITERAL+) in use
+	BytesIn     int64     `json:"bytes_in"`     // IMAP data received, after inflating
+	BytesOut    int64     `json:"bytes_out"`    // IMAP data sent, before deflating
+	WireIn      int64     `json:"wire_in"`      // bytes received over the network
+	WireOut     int64     `json:"wire_out"`     // bytes sent over the network
+
+	Throttles    int       `json:"throttles"`              // times the provider throttled the account
+	BackoffUntil time.Time `json:"backoff_until,omitzero"` // no commands until then, after a throttle
+}
+
+// Saved returns the fraction of IMAP data compression kept off the network
+func (s ConnStats) Saved() float64 {
+	total := s.BytesIn + s.BytesOut
+	if total == 0 {
+		return 0
+	}
+	return 1 - float64(s.WireIn+s.WireOut)/float64(total)
+}
+
+// wireConn counts the bytes crossing the network
+type wireConn struct {
+	net.Conn
+	in, out atomic.Int64
+}
+
+func (c *wireConn) Read(p []byte) (int, error) {
+	n, err := c.Conn.Read(p)
+	c.in.Add(int64(n))
+	return n, err
+}
+
+func (c *wireConn) Write(p []byte) (int, error) {
+	n, err := c.Conn.Write(p)
+	c.out.Add(int64(n))
+	return n, err
+}
+
+// imapConn is the connection go-imap talks over: the network connection, or the
+// deflate streams on top of it, counting the IMAP data passing through
+type imapConn struct {
+	*wireConn
+	since    time.Time
+	greeting string // served before r when maily logged in itself
+
+	r       io.Reader
+	w       io.Writer
+	flush   func() error // pushes deflated data out after each write
+	writeMu sync.Mutex
+
+	compressed  bool
+	literalPlus bool
+	in, out     atomic.Int64
+
+	limiter *rateLimiter  // paces the commands written
+	watch   throttleWatch // spots throttling in what's read
+	midLine bool          // the last write didn't end a line, so the next one isn't a new command
+}
+
+func newIMAPConn(wc *wireConn, limiter *rateLimiter) *imapConn {
+	return &imapConn{wireConn: wc, since: time.Now(), r: wc, w: wc, limiter: limiter, watch: throttleWatch{limiter: limiter}}
+}
+
+func (c *imapConn) Read(p []byte) (int, error) {
+	if c.greeting != "" {
+		n := copy(p, c.greeting)
+		c.greeting = c.greeting[n:]
+		return n, nil
+	}
+	n, err := c.r.Read(p)
+	c.in.Add(int64(n))
+	c.watch.observe(p[:n])
+	return n, err
+}
+
+func (c *imapConn) Write(p []byte) (int, error) {
+	c.writeMu.Lock()
+	defer c.writeMu.Unlock()
+	if !c.midLine {
+		if err := c.limiter.wait(); err != nil {
+			return 0, err
+		}
+	}
+	c.midLine = !bytes.HasSuffix(p, []byte("\r\n"))
+	n, err := c.w.Write(p)
+	c.out.Add(int64(n))
+	if err == nil && c.flush != nil {
+		err = c.flush()
+	}
+	return n, err
+}
+
+func (c *imapConn) stats() ConnStats {
+	throttles, until := c.limiter.state()
+	if time.Now().After(until) {
+		until = time.Time{}
+	}
+	return ConnStats{
+		Since:       c.since,
+		Compressed:  c.compressed,
+		LiteralPlus: c.literalPlus,
+		BytesIn:     c.in.Load(),
+		BytesOut:    c.out.Load(),
+		WireIn:      c.wireConn.in.Load(),
+		WireOut:     c.wireConn.out.Load(),
+
+		Throttles:    throttles,
+		BackoffUntil: until,
+	}
+}
+
+// dialIMAP connects and logs in, with COMPRESS=DEFLATE when the account asks for it and
+// the server offers it
+func dialIMAP(creds *auth.Credentials) (*imapclient.Client, *imapConn, error) {
+	limiter := limiterFor(creds.Email)
+	if err := limiter.blocked(); err != nil {
+		return nil, nil, err
+	}
+	tlsConn, greeted, err := dialSecure(creds)
+	if err != nil {
+		return nil, nil, err
+	}
+	conn := newIMAPConn(&wireConn{Conn: tlsConn}, limiter)
+	if greeted {
+		conn.greeting = startTLSGreeting
+	}
+
+	if !creds.Compress {
+		client := imapclient.New(conn, nil)
+		if err := client.Login(creds.Email, creds.Password).Wait(); err != nil {
+			client.Close()
+			return nil, nil, loginError(err)
+		}
+		return client, conn, nil
+	}
+
+	if err := compressedLogin(conn, creds); err != nil {
+		if IsThrottled(err) {
+			limiter.throttle(true)
+		}
+		conn.Close()
+		return nil, nil, err
+	}
+	return imapclient.New(conn, nil), conn, nil
+}
+
+// loginError wraps a failed LOGIN. A tagged NO means the server rejected the credentials.
+func loginError(err error) error {
+	var imapErr *imap.Error
+	if errors.As(err, &imapErr) && imapErr.Type == imap.StatusResponseTypeNo {
+		return fmt.Errorf("login failed: %w: %w", ErrAuthFailed, err)
+	}
+	return fmt.Errorf("login failed: %w", err)
+}
+
+// compressedLogin logs in over the raw connection and turns on compression if the
+// server offers it, leaving conn to greet go-imap with PREAUTH
+func compressedLogin(conn *imapConn, creds *auth.Credentials) error {
+	br := bufio.NewReader(conn.wireConn)
+	if conn.greeting == "" {
+		greeting, err := br.ReadString('\n')
+		if err != nil {
+			return fmt.Errorf("failed to read greeting: %w", err)
+		}
+		if !strings.HasPrefix(greeting, "* OK") {
+			return fmt.Errorf("unexpected greeting: %s", strings.TrimSpace(greeting))
+		}
+	}
+
+	if err := rawLogin(conn.wireConn, br, "c1", creds.Email, creds.Password); err != nil {
+		return loginError(err)
+	}
+
+	untagged, err := rawCommand(conn.wireConn, br, "c2", "CAPABILITY")
+	if err != nil {
+		return err
+	}
+	offered := false
+	for _, line := range untagged {
+		if caps, ok := strings.CutPrefix(line, "* CAPABILITY "); ok {
+			for _, c := range strings.Fields(caps) {
+				offered = offered || strings.EqualFold(c, string(capCompressDeflate))
+			}
+		}
+	}
+
+	conn.r = br
+	conn.greeting = preauthGreeting
+	if !offered {
+		return nil
+	}
+	if _, err := rawCommand(conn.wireConn, br, "c3", "COMPRESS DEFLATE"); err != nil {
+		return err
+	}
+	fw, err := flate.NewWriter(conn.wireConn, flate.DefaultCompression)
+	if err != nil {
+		return err
+	}
+	conn.r = flate.NewReader(br)
+	conn.w = fw
+	conn.flush = fw.Flush
+	conn.compressed = true
+	return nil
+}
+
+// rawCommand sends a command ahead of go-imap and returns the untagged lines before
+// its tagged OK. A NO or BAD comes back as an *imap.Error.
+func rawCommand(w io.Writer, br *bufio.Reader, tag, command string) ([]string, error) {
+	if _, err := fmt.Fprintf(w, "%s %s\r\n", tag, command); err != nil {
+		return nil, err
+	}
+	return readTagged(br, tag)
+}
+
+// rawLogin sends LOGIN ahead of go-imap. The user name and password go as quoted
+// strings, or as literals when they hold 8-bit characters or line breaks, which a
+// quoted string can't carry.
+func rawLogin(w io.Writer, br *bufio.Reader, tag, user, password string) error {
+	command := tag + " LOGIN"
+	for _, arg := range []string{user, password} {
+		if !needsLiteral(arg) {
+			command += " " + quoteString(arg)
+			continue
+		}
+		if _, err := fmt.Fprintf(w, "%s {%d}\r\n", command, len(arg)); err != nil {
+			return err
+		}
+		for {
+			line, err := br.ReadString('\n')
+			if err != nil {
+				return err
+			}
+			if strings.HasPrefix(line, "* ") {
+				continue
+			}
+			if !strings.HasPrefix(line, "+") {
+				// A tagged NO or BAD instead of the go-ahead for the literal
+				return statusError(strings.TrimPrefix(strings.TrimRight(line, "\r\n"), tag+" "))
+			}
+			break
+		}
+		command = arg
+	}
+	if _, err := fmt.Fprintf(w, "%s\r\n", command); err != nil {
+		return err
+	}
+	_, err := readTagged(br, tag)
+	return err
+}
+
+// needsLiteral reports whether s can't be sent as a quoted string, which only carries
+// 7-bit characters other than NUL, CR and LF
+func needsLiteral(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if c := s[i]; c >= 0x80 || c == 0 || c == '\r' || c == '\n' {
+			return true
+		}
+	}
+	return false
+}
+
+// readTagged reads the responses to a command up to its tagged status and returns the
+// untagged lines before it
+func readTagged(br *bufio.Reader, tag string) ([]string, error) {
+	var untagged []string
+	for {
+		line, err := br.ReadString('\n')
+		if err != nil {
+			return nil, err
+		}
+		line = strings.TrimRight(line, "\r\n")
+		status, ok := strings.CutPrefix(line, tag+" ")
+		if !ok {
+			untagged = append(untagged, line)
+			continue
+		}
+		if err := statusError(status); err != nil {
+			return nil, err
+		}
+		return untagged, nil
+	}
+}
+
+// statusError returns nil for an OK status and an *imap.Error for a NO or BAD
+func statusError(status string) error {
+	kind, text, _ := strings.Cut(status, " ")
+	switch imap.StatusResponseType(strings.ToUpper(kind)) {
+	case imap.StatusResponseTypeOK:
+		return nil
+	case imap.StatusResponseTypeNo:
+		return &imap.Error{Type: imap.StatusResponseTypeNo, Text: text}
+	default:
+		return &imap.Error{Type: imap.StatusResponseTypeBad, Text: text}
+	}
+}
+
+// Stats returns the traffic counters of the connection
+func (c *IMAPClient) Stats() ConnStats {
+	if c.conn == nil {
+		return ConnStats{}
+	}
+	return c.conn.stats()
+}
@@ -0,0 +1,83 @@
+package mail
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/emersion/go-imap/v2"
+)
+
+func TestRawLogin(t *testing.T) {
+	tests := []struct {
+		name, user, password string
+		replies              string // what the server sends, in order
+		sent                 string
+	}{
+		{
+			name:     "quoted",
+			user:     "me@example.com",
+			password: `pa"ss\word`,
+			replies:  "c1 OK logged in\r\n",
+			sent:     "c1 LOGIN \"me@example.com\" \"pa\\\"ss\\\\word\"\r\n",
+		},
+		{
+			name:     "8-bit password as a literal",
+			user:     "me@example.com",
+			password: "pässwörd",
+			replies:  "+ go ahead\r\nc1 OK logged in\r\n",
+			sent:     "c1 LOGIN \"me@example.com\" {10}\r\npässwörd\r\n",
+		},
+		{
+			name:     "both as literals",
+			user:     "jürgen",
+			password: "line\r\nbreak",
+			replies:  "+ ready\r\n* OK still there\r\n+ ready\r\nc1 OK logged in\r\n",
+			sent:     "c1 LOGIN {7}\r\njürgen {11}\r\nline\r\nbreak\r\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var sent bytes.Buffer
+			br := bufio.NewReader(strings.NewReader(tt.replies))
+			if err := rawLogin(&sent, br, "c1", tt.user, tt.password); err != nil {
+				t.Fatalf("rawLogin() error = %v", err)
+			}
+			if sent.String() != tt.sent {
+				t.Errorf("sent %q, want %q", sent.String(), tt.sent)
+			}
+		})
+	}
+}
+
+func TestRawLoginRefused(t *testing.T) {
+	for _, replies := range []string{
+		"c1 NO [AUTHENTICATIONFAILED] invalid credentials\r\n",
+		"+ go ahead\r\nc1 NO [AUTHENTICATIONFAILED] invalid credentials\r\n",
+	} {
+		var sent bytes.Buffer
+		err := rawLogin(&sent, bufio.NewReader(strings.NewReader(replies)), "c1", "me@example.com", "wröng")
+		var imapErr *imap.Error
+		if !errors.As(err, &imapErr) || imapErr.Type != imap.StatusResponseTypeNo {
+			t.Errorf("rawLogin() with %q: error = %v, want a NO", replies, err)
+		}
+	}
+}
+
+func TestNeedsLiteral(t *testing.T) {
+	tests := map[string]bool{
+		"plain":            false,
+		`with "quotes"`:    false,
+		"ünïcode":          true,
+		"cr\rlf":           true,
+		"nul\x00":          true,
+		"tab\tis fine too": false,
+	}
+	for s, want := range tests {
+		if got := needsLiteral(s); got != want {
+			t.Errorf("needsLiteral(%q) = %v, want %v", s, got, want)
+		}
+	}
+}
@@ -1,12 +1,17 @@
 package mail
 
 import (
+	"bufio"
+	"bytes"
 	"encoding/base64"
 	"errors"
 	"fmt"
+	"html"
 	"io"
 	"mime"
 	"mime/quotedprintable"
+	"net/textproto"
+	"sort"
 	"strings"
 	"time"
 
@@ -46,9 +51,31 @@ func toStringLossy(b []byte) string {
 // (e.g., deleted from another device)
 var ErrEmailNotFound = errors.New("email not found on server")
 
+// ErrAuthFailed is returned when the server rejects the stored credentials
+// (e.g., the app password was revoked)
+var ErrAuthFailed = errors.New("authentication failed")
+
+// IsAuthError reports whether err is an authentication failure. It also
+// matches errors relayed as plain text from the server process.
+func IsAuthError(err error) bool {
+	if err == nil {
+		return false
+	}
+	if errors.Is(err, ErrAuthFailed) {
+		return true
+	}
+	errStr := err.Error()
+	return strings.Contains(errStr, ErrAuthFailed.Error()) ||
+		strings.Contains(errStr, "AUTHENTICATIONFAILED") ||
+		strings.Contains(errStr, "Invalid credentials")
+}
+
 type IMAPClient struct {
 	client *imapclient.Client
+	conn   *imapConn // counts the traffic for Stats
 	creds  *auth.Credentials
+	caps   Capabilities
+	labels *rawSession // raw session for Gmail labels, opened on first use
 }
 
 // Attachment represents email attachment metadata
@@ -73,30 +100,99 @@ type Email struct {
 	Snippet      string
 	BodyHTML     string       // HTML body content
 	Unread       bool
+	Answered     bool         // \Answered: replied to
+	Forwarded    bool         // $Forwarded keyword
+	Flagged      bool         // \Flagged: starred
+	Size         int64        // RFC822.SIZE: the whole message as stored on the server
 	References   string       // For threading
 	Attachments  []Attachment // Attachment metadata (content fetched on demand)
+
+	ListID              string // raw List-Id header, empty for non-list mail
+	ListUnsubscribe     string // raw List-Unsubscribe header
+	ListUnsubscribePost string // raw List-Unsubscribe-Post header
+
+	Labels []string // Gmail labels (X-GM-LABELS); nil on other servers
 }
 
-func NewIMAPClient(creds *auth.Credentials) (*IMAPClient, error) {
-	addr := fmt.Sprintf("%s:%d", creds.IMAPHost, creds.IMAPPort)
+// Flags is the part of a message's IMAP flags that maily tracks
+type Flags struct {
+	Unread    bool
+	Answered  bool
+	Forwarded bool
+	Flagged   bool
+}
 
-	client, err := imapclient.DialTLS(addr, nil)
-	if err != nil {
-		return nil, fmt.Errorf("failed to connect to IMAP server: %w", err)
+// Flags returns the email's read, answered, forwarded and flagged state
+func (e *Email) Flags() Flags {
+	return Flags{Unread: e.Unread, Answered: e.Answered, Forwarded: e.Forwarded, Flagged: e.Flagged}
+}
+
+// readFlags picks out the flags maily tracks from a message's IMAP flags
+func readFlags(flags []imap.Flag) Flags {
+	f := Flags{Unread: true}
+	for _, flag := range flags {
+		switch {
+		case flag == imap.FlagSeen:
+			f.Unread = false
+		case flag == imap.FlagAnswered:
+			f.Answered = true
+		case flag == imap.FlagFlagged:
+			f.Flagged = true
+		case strings.EqualFold(string(flag), string(imap.FlagForwarded)):
+			f.Forwarded = true
+		}
 	}
+	return f
+}
+
+// parseFlags sets an email's read, answered, forwarded and flagged state from its IMAP flags
+func parseFlags(flags []imap.Flag, email *Email) {
+	f := readFlags(flags)
+	email.Unread, email.Answered, email.Forwarded, email.Flagged = f.Unread, f.Answered, f.Forwarded, f.Flagged
+}
 
-	if err := client.Login(creds.Email, creds.Password).Wait(); err != nil {
-		client.Close()
-		return nil, fmt.Errorf("login failed: %w", err)
+// listHeaderSection fetches just the mailing list headers when the body isn't fetched
+var listHeaderSection = &imap.FetchItemBodySection{
+	Specifier:    imap.PartSpecifierHeader,
+	HeaderFields: []string{"List-Id", "List-Unsubscribe", "List-Unsubscribe-Post"},
+	Peek:         true,
+}
+
+// parseListHeaders reads List-Id and List-Unsubscribe headers from whichever body section was fetched
+func parseListHeaders(msg *imapclient.FetchMessageBuffer, email *Email) {
+	for _, section := range msg.BodySection {
+		header, err := textproto.NewReader(bufio.NewReader(bytes.NewReader(section.Bytes))).ReadMIMEHeader()
+		if err != nil && len(header) == 0 {
+			continue
+		}
+		if email.ListID == "" {
+			email.ListID = header.Get("List-Id")
+		}
+		if v := header.Get("List-Unsubscribe"); v != "" && email.ListUnsubscribe == "" {
+			email.ListUnsubscribe = v
+			email.ListUnsubscribePost = header.Get("List-Unsubscribe-Post")
+		}
 	}
+}
 
+func NewIMAPClient(creds *auth.Credentials) (*IMAPClient, error) {
+	client, conn, err := dialIMAP(creds)
+	if err != nil {
+		return nil, err
+	}
+
+	caps := probeCapabilities(client)
+	conn.literalPlus = caps.LiteralPlus
 	return &IMAPClient{
 		client: client,
+		conn:   conn,
 		creds:  creds,
+		caps:   caps,
 	}, nil
 }
 
 func (c *IMAPClient) Close() error {
+	c.closeLabelSession()
 	if c.client != nil {
 		return c.client.Close()
 	}
@@ -141,7 +237,7 @@ func (c *IMAPClient) SelectMailboxWithInfo(name string) (*MailboxInfo, error) {
 
 // FetchUIDsAndFlags fetches UIDs and flags for emails since the given date
 // Returns a map of UID -> unread status
-func (c *IMAPClient) FetchUIDsAndFlags(mailbox string, since time.Time) (map[imap.UID]bool, error) {
+func (c *IMAPClient) FetchUIDsAndFlags(mailbox string, since time.Time) (map[imap.UID]Flags, error) {
 	_, err := c.client.Select(mailbox, nil).Wait()
 	if err != nil {
 		return nil, fmt.Errorf("failed to select mailbox: %w", err)
@@ -158,7 +254,7 @@ func (c *IMAPClient) FetchUIDsAndFlags(mailbox string, since time.Time) (map[ima
 	}
 
 	if len(searchData.AllSeqNums()) == 0 {
-		return make(map[imap.UID]bool), nil
+		return make(map[imap.UID]Flags), nil
 	}
 
 	// Fetch UIDs and flags for found messages
@@ -177,21 +273,31 @@ func (c *IMAPClient) FetchUIDsAndFlags(mailbox string, since time.Time) (map[ima
 		return nil, fmt.Errorf("fetch failed: %w", err)
 	}
 
-	result := make(map[imap.UID]bool)
+	result := make(map[imap.UID]Flags)
 	for _, msg := range messages {
-		unread := true
-		for _, flag := range msg.Flags {
-			if flag == imap.FlagSeen {
-				unread = false
-				break
-			}
-		}
-		result[msg.UID] = unread
+		result[msg.UID] = readFlags(msg.Flags)
 	}
 
 	return result, nil
 }
 
+// FetchAllUIDs returns the UIDs of every message in a mailbox, to find cached emails
+// deleted on the server however old they are
+func (c *IMAPClient) FetchAllUIDs(mailbox string) (map[imap.UID]bool, error) {
+	if _, err := c.client.Select(mailbox, nil).Wait(); err != nil {
+		return nil, fmt.Errorf("failed to select mailbox: %w", err)
+	}
+	data, err := c.client.UIDSearch(&imap.SearchCriteria{}, nil).Wait()
+	if err != nil {
+		return nil, fmt.Errorf("search failed: %w", err)
+	}
+	uids := make(map[imap.UID]bool)
+	for _, uid := range data.AllUIDs() {
+		uids[uid] = true
+	}
+	return uids, nil
+}
+
 // FetchEmailBody fetches just the body content for a single email by UID
 func (c *IMAPClient) FetchEmailBody(mailbox string, uid imap.UID) (bodyHTML string, snippet string, err error) {
 	_, err = c.client.Select(mailbox, nil).Wait()
@@ -217,12 +323,78 @@ func (c *IMAPClient) FetchEmailBody(mailbox string, uid imap.UID) (bodyHTML stri
 
 	msg := messages[0]
 	if len(msg.BodySection) > 0 {
-		bodyHTML, snippet = c.parseBody(msg.BodySection[0].Bytes)
+		bodyHTML, snippet = parseBody(msg.BodySection[0].Bytes)
 	}
 
 	return bodyHTML, snippet, nil
 }
 
+// FetchRaw fetches the full RFC 822 source of a message
+func (c *IMAPClient) FetchRaw(mailbox string, uid imap.UID) ([]byte, error) {
+	if _, err := c.client.Select(mailbox, nil).Wait(); err != nil {
+		return nil, fmt.Errorf("failed to select mailbox: %w", err)
+	}
+
+	uidSet := imap.UIDSet{}
+	uidSet.AddNum(uid)
+
+	fetchOptions := &imap.FetchOptions{
+		BodySection: []*imap.FetchItemBodySection{{Peek: true}},
+	}
+
+	messages, err := c.client.Fetch(uidSet, fetchOptions).Collect()
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch message: %w", err)
+	}
+	if len(messages) == 0 || len(messages[0].BodySection) == 0 {
+		return nil, ErrEmailNotFound
+	}
+	return messages[0].BodySection[0].Bytes, nil
+}
+
+// FetchHeader fetches the header block of a message
+func (c *IMAPClient) FetchHeader(mailbox string, uid imap.UID) ([]byte, error) {
+	if _, err := c.client.Select(mailbox, nil).Wait(); err != nil {
+		return nil, fmt.Errorf("failed to select mailbox: %w", err)
+	}
+
+	uidSet := imap.UIDSet{}
+	uidSet.AddNum(uid)
+
+	fetchOptions := &imap.FetchOptions{
+		BodySection: []*imap.FetchItemBodySection{{Specifier: imap.PartSpecifierHeader, Peek: true}},
+	}
+
+	messages, err := c.client.Fetch(uidSet, fetchOptions).Collect()
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch header: %w", err)
+	}
+	if len(messages) == 0 || len(messages[0].BodySection) == 0 {
+		return nil, ErrEmailNotFound
+	}
+	return messages[0].BodySection[0].Bytes, nil
+}
+
+// FindUIDByMessageID returns the UID of the message with the given Message-ID header
+func (c *IMAPClient) FindUIDByMessageID(mailbox, messageID string) (imap.UID, error) {
+	if _, err := c.client.Select(mailbox, nil).Wait(); err != nil {
+		return 0, fmt.Errorf("failed to select mailbox: %w", err)
+	}
+
+	criteria := &imap.SearchCriteria{
+		Header: []imap.SearchCriteriaHeaderField{{Key: "Message-ID", Value: messageID}},
+	}
+	data, err := c.client.UIDSearch(criteria, nil).Wait()
+	if err != nil {
+		return 0, fmt.Errorf("search failed: %w", err)
+	}
+	uids := data.AllUIDs()
+	if len(uids) == 0 {
+		return 0, ErrEmailNotFound
+	}
+	return uids[0], nil
+}
+
 // FetchMessagesByUIDs fetches full messages by their UIDs
 func (c *IMAPClient) FetchMessagesByUIDs(mailbox string, uids []imap.UID) ([]Email, error) {
 	if len(uids) == 0 {
@@ -244,6 +416,7 @@ func (c *IMAPClient) FetchMessagesByUIDs(mailbox string, uids []imap.UID) ([]Ema
 		Flags:         true,
 		Envelope:      true,
 		InternalDate:  true,
+		RFC822Size:    true,
 		BodyStructure: &imap.FetchItemBodyStructure{Extended: true},
 		BodySection:   []*imap.FetchItemBodySection{{Peek: true}},
 	}
@@ -284,7 +457,9 @@ func (c *IMAPClient) FetchMessagesByUIDsMetadata(mailbox string, uids []imap.UID
 		Flags:         true,
 		Envelope:      true,
 		InternalDate:  true,
+		RFC822Size:    true,
 		BodyStructure: &imap.FetchItemBodyStructure{Extended: true},
+		BodySection:   []*imap.FetchItemBodySection{listHeaderSection},
 	}
 
 	messages, err := c.client.Fetch(uidSet, fetchOptions).Collect()
@@ -324,6 +499,7 @@ func (c *IMAPClient) FetchMessages(mailbox string, limit uint32) ([]Email, error
 		Flags:         true,
 		Envelope:      true,
 		InternalDate:  true,
+		RFC822Size:    true,
 		BodyStructure: &imap.FetchItemBodyStructure{Extended: true},
 		BodySection:   []*imap.FetchItemBodySection{{Peek: true}},
 	}
@@ -368,8 +544,10 @@ func (c *IMAPClient) FetchMessagesMetadata(mailbox string, limit uint32) ([]Emai
 		Flags:         true,
 		Envelope:      true,
 		InternalDate:  true,
+		RFC822Size:    true,
 		BodyStructure: &imap.FetchItemBodyStructure{Extended: true},
-		// No BodySection - body will be fetched on-demand
+		// Only the List-Unsubscribe headers - body will be fetched on-demand
+		BodySection: []*imap.FetchItemBodySection{listHeaderSection},
 	}
 
 	messages, err := c.client.Fetch(seqSet, fetchOptions).Collect()
@@ -393,6 +571,7 @@ func (c *IMAPClient) parseMessageMetadata(msg *imapclient.FetchMessageBuffer) Em
 
 	email.UID = msg.UID
 	email.InternalDate = msg.InternalDate
+	email.Size = msg.RFC822Size
 
 	// Parse attachments from BODYSTRUCTURE
 	if msg.BodyStructure != nil {
@@ -447,13 +626,9 @@ func (c *IMAPClient) parseMessageMetadata(msg *imapclient.FetchMessageBuffer) Em
 		}
 	}
 
-	email.Unread = true
-	for _, flag := range msg.Flags {
-		if flag == imap.FlagSeen {
-			email.Unread = false
-			break
-		}
-	}
+	parseFlags(msg.Flags, &email)
+
+	parseListHeaders(msg, &email)
 
 	// Body and BodyHTML are empty - will be fetched on-demand
 	return email
@@ -461,50 +636,104 @@ func (c *IMAPClient) parseMessageMetadata(msg *imapclient.FetchMessageBuffer) Em
 
 // FetchMessagesSince fetches emails since the given date, up to limit
 func (c *IMAPClient) FetchMessagesSince(mailbox string, since time.Time, limit uint32) ([]Email, error) {
-	// First get UIDs for emails since the date
-	uidMap, err := c.FetchUIDsAndFlags(mailbox, since)
+	uids, err := c.newestUIDsSince(mailbox, since, limit)
 	if err != nil {
 		return nil, err
 	}
 
-	if len(uidMap) == 0 {
+	if len(uids) == 0 {
 		return []Email{}, nil
 	}
 
+	// Fetch full messages for these UIDs
+	emails, err := c.FetchMessagesByUIDs(mailbox, uids)
+	if err != nil {
+		return nil, err
+	}
+
+	// Sort by InternalDate descending (newest first)
+	sort.SliceStable(emails, func(i, j int) bool {
+		return emails[i].InternalDate.After(emails[j].InternalDate)
+	})
+
+	return emails, nil
+}
+
+// newestUIDsSince returns up to limit UIDs of emails since the given date, newest first.
+// Servers with the SORT extension order them by arrival, so only the UIDs kept are
+// transferred; otherwise every UID is fetched and the highest (newest) are kept.
+func (c *IMAPClient) newestUIDsSince(mailbox string, since time.Time, limit uint32) ([]imap.UID, error) {
+	if c.caps.Sort {
+		if _, err := c.client.Select(mailbox, nil).Wait(); err != nil {
+			return nil, fmt.Errorf("failed to select mailbox: %w", err)
+		}
+		nums, err := c.client.UIDSort(&imapclient.SortOptions{
+			SearchCriteria: &imap.SearchCriteria{Since: since},
+			SortCriteria:   []imapclient.SortCriterion{{Key: imapclient.SortKeyArrival, Reverse: true}},
+		}).Wait()
+		if err == nil {
+			if uint32(len(nums)) > limit {
+				nums = nums[:limit]
+			}
+			uids := make([]imap.UID, len(nums))
+			for i, num := range nums {
+				uids[i] = imap.UID(num)
+			}
+			return uids, nil
+		}
+		// Fall back to sorting client-side if the server rejects SORT
+	}
+
+	uidMap, err := c.FetchUIDsAndFlags(mailbox, since)
+	if err != nil {
+		return nil, err
+	}
+
 	// Convert map keys to slice
 	uids := make([]imap.UID, 0, len(uidMap))
 	for uid := range uidMap {
 		uids = append(uids, uid)
 	}
 
-	// Apply limit if needed
+	// Sort UIDs descending (higher UID = newer) and take top N
+	sort.Slice(uids, func(i, j int) bool { return uids[i] > uids[j] })
 	if uint32(len(uids)) > limit {
-		// Sort UIDs descending (higher UID = newer) and take top N
-		for i := 0; i < len(uids)-1; i++ {
-			for j := i + 1; j < len(uids); j++ {
-				if uids[j] > uids[i] {
-					uids[i], uids[j] = uids[j], uids[i]
-				}
-			}
-		}
 		uids = uids[:limit]
 	}
+	return uids, nil
+}
 
-	// Fetch full messages for these UIDs
-	emails, err := c.FetchMessagesByUIDs(mailbox, uids)
+// FetchOlderMetadata fetches the metadata of up to limit emails with UIDs below before,
+// newest first: the page of the mailbox that precedes what is already loaded
+func (c *IMAPClient) FetchOlderMetadata(mailbox string, before imap.UID, limit int) ([]Email, error) {
+	if before <= 1 || limit <= 0 {
+		return []Email{}, nil
+	}
+	if _, err := c.client.Select(mailbox, nil).Wait(); err != nil {
+		return nil, fmt.Errorf("failed to select mailbox: %w", err)
+	}
+
+	uidSet := imap.UIDSet{}
+	uidSet.AddRange(1, before-1)
+	data, err := c.client.UIDSearch(&imap.SearchCriteria{UID: []imap.UIDSet{uidSet}}, nil).Wait()
 	if err != nil {
-		return nil, err
+		return nil, fmt.Errorf("search failed: %w", err)
 	}
 
-	// Sort by InternalDate descending (newest first)
-	for i := 0; i < len(emails)-1; i++ {
-		for j := i + 1; j < len(emails); j++ {
-			if emails[j].InternalDate.After(emails[i].InternalDate) {
-				emails[i], emails[j] = emails[j], emails[i]
-			}
-		}
+	// Higher UIDs are newer; keep the newest limit of the older ones
+	uids := data.AllUIDs()
+	sort.Slice(uids, func(i, j int) bool { return uids[i] > uids[j] })
+	if len(uids) > limit {
+		uids = uids[:limit]
 	}
 
+	emails, err := c.FetchMessagesByUIDsMetadata(mailbox, uids)
+	if err != nil {
+		return nil, err
+	}
+	sort.SliceStable(emails, func(i, j int) bool {
+		return emails[i].InternalDate.After(emails[j].InternalDate)
+	})
 	return emails, nil
 }
 
@@ -513,6 +742,7 @@ func (c *IMAPClient) parseMessage(msg *imapclient.FetchMessageBuffer) Email {
 
 	email.UID = msg.UID
 	email.InternalDate = msg.InternalDate
+	email.Size = msg.RFC822Size
 
 	// Parse attachments from BODYSTRUCTURE
 	if msg.BodyStructure != nil {
@@ -568,24 +798,20 @@ func (c *IMAPClient) parseMessage(msg *imapclient.FetchMessageBuffer) Email {
 		}
 	}
 
-	email.Unread = true
-	for _, flag := range msg.Flags {
-		if flag == imap.FlagSeen {
-			email.Unread = false
-			break
-		}
-	}
+	parseFlags(msg.Flags, &email)
 
 	if len(msg.BodySection) > 0 {
-		bodyHTML, snippet := c.parseBody(msg.BodySection[0].Bytes)
+		bodyHTML, snippet := parseBody(msg.BodySection[0].Bytes)
 		email.BodyHTML = bodyHTML
 		email.Snippet = snippet
 	}
+	parseListHeaders(msg, &email)
 
 	return email
 }
 
-func (c *IMAPClient) parseBody(body []byte) (string, string) {
+// parseBody returns the HTML to display for a whole message and a snippet of its text
+func parseBody(body []byte) (string, string) {
 	// Use lossy conversion to handle emails with invalid UTF-8 sequences
 	// (e.g., from non-UTF-8 encodings like GB2312, Latin-1 that weren't properly decoded)
 	bodyStr := toStringLossy(body)
@@ -625,7 +851,7 @@ func (c *IMAPClient) parseBody(body []byte) (string, string) {
 	}
 	if textBody != "" {
 		// Wrap plain text in pre tag for proper rendering
-		htmlBody = "<pre style=\"white-space: pre-wrap; font-family: inherit;\">" + escapeHTML(textBody) + "</pre>"
+		htmlBody = plainTextOpen + escapeHTML(textBody) + plainTextClose
 		return htmlBody, truncateSnippet(textBody)
 	}
 
@@ -633,6 +859,30 @@ func (c *IMAPClient) parseBody(body []byte) (string, string) {
 	return bodyStr, truncateSnippet(stripHTML(bodyStr))
 }
 
+// Plain-text bodies are stored wrapped in these tags
+const (
+	plainTextOpen  = "<pre style=\"white-space: pre-wrap; font-family: inherit;\">"
+	plainTextClose = "</pre>"
+)
+
+// UnwrapPlainText returns the original text of a body that was stored from a
+// text/plain part, and false for real HTML bodies
+func UnwrapPlainText(body string) (string, bool) {
+	if !strings.HasPrefix(body, plainTextOpen) || !strings.HasSuffix(body, plainTextClose) {
+		return "", false
+	}
+	text := strings.TrimSuffix(strings.TrimPrefix(body, plainTextOpen), plainTextClose)
+	return html.UnescapeString(text), true
+}
+
+// BodyText returns the text of a stored body, without the tags and styles of HTML ones
+func BodyText(body string) string {
+	if text, ok := UnwrapPlainText(body); ok {
+		return text
+	}
+	return stripHTML(body)
+}
+
 func escapeHTML(s string) string {
 	s = strings.ReplaceAll(s, "&", "&amp;")
 	s = strings.ReplaceAll(s, "<", "&lt;")
@@ -643,8 +893,8 @@ func escapeHTML(s string) string {
 
 func truncateSnippet(s string) string {
 	snippet := s
-	if len(snippet) > 200 {
-		snippet = snippet[:200] + "..."
+	if runes := []rune(snippet); len(runes) > 200 {
+		snippet = string(runes[:200]) + "..."
 	}
 	snippet = strings.ReplaceAll(snippet, "\n", " ")
 	snippet = strings.ReplaceAll(snippet, "\r", "")
@@ -917,6 +1167,27 @@ func (c *IMAPClient) MarkAsUnread(uid imap.UID) error {
 	return cmd.Close()
 }
 
+// MarkAsAnswered sets \Answered on an email once a reply to it has been sent
+func (c *IMAPClient) MarkAsAnswered(uid imap.UID) error {
+	uidSet := imap.UIDSet{}
+	uidSet.AddNum(uid)
+
+	// Verify email exists before modifying flags (STORE silently succeeds on missing UIDs)
+	if exists, err := c.uidExists(uidSet); err != nil {
+		return err
+	} else if !exists {
+		return ErrEmailNotFound
+	}
+
+	storeFlags := &imap.StoreFlags{
+		Op:    imap.StoreFlagsAdd,
+		Flags: []imap.Flag{imap.FlagAnswered},
+	}
+
+	cmd := c.client.Store(uidSet, storeFlags, nil)
+	return cmd.Close()
+}
+
 // uidExists checks if a UID exists in the currently selected mailbox
 func (c *IMAPClient) uidExists(uidSet imap.UIDSet) (bool, error) {
 	fetchOptions := &imap.FetchOptions{
@@ -929,6 +1200,26 @@ func (c *IMAPClient) uidExists(uidSet imap.UIDSet) (bool, error) {
 	return len(messages) > 0, nil
 }
 
+// IsUnread reports whether a message in the currently selected mailbox lacks the \Seen flag
+func (c *IMAPClient) IsUnread(uid imap.UID) (bool, error) {
+	uidSet := imap.UIDSet{}
+	uidSet.AddNum(uid)
+
+	messages, err := c.client.Fetch(uidSet, &imap.FetchOptions{Flags: true}).Collect()
+	if err != nil {
+		return false, err
+	}
+	if len(messages) == 0 {
+		return false, ErrEmailNotFound
+	}
+	for _, flag := range messages[0].Flags {
+		if flag == imap.FlagSeen {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
 func (c *IMAPClient) DeleteMessage(uid imap.UID) error {
 	uidSet := imap.UIDSet{}
 	uidSet.AddNum(uid)
@@ -977,7 +1268,7 @@ func (c *IMAPClient) MoveToTrashFromMailbox(uids []imap.UID, mailbox string) err
 	}
 
 	// Find trash folder (this may invalidate mailbox selection on some servers like Yahoo)
-	trashFolder, err := c.findTrashFolder()
+	trashFolder, err := c.FindTrashFolder()
 	if err != nil {
 		return fmt.Errorf("failed to find trash folder: %w", err)
 	}
@@ -993,46 +1284,100 @@ func (c *IMAPClient) MoveToTrashFromMailbox(uids []imap.UID, mailbox string) err
 	}
 
 	// Move to trash
-	if _, err := c.client.Move(uidSet, trashFolder).Wait(); err != nil {
+	if err := c.move(uidSet, trashFolder); err != nil {
 		return err
 	}
 
 	return nil
 }
 
-func (c *IMAPClient) findTrashFolder() (string, error) {
-	// Try Gmail-specific trash folder first
-	if c.mailboxExists(GmailTrash) {
-		return GmailTrash, nil
+// FindTrashFolder returns the trash folder: the \Trash special-use mailbox, [Gmail]/Trash, or a common name
+func (c *IMAPClient) FindTrashFolder() (string, error) {
+	return c.findFolder(imap.MailboxAttrTrash, GmailTrash, Trash, "Deleted", "Deleted Items", "Deleted Messages")
+}
+
+// FindSentFolder returns the sent folder: the \Sent special-use mailbox, [Gmail]/Sent Mail, or a common name
+func (c *IMAPClient) FindSentFolder() (string, error) {
+	return c.findFolder(imap.MailboxAttrSent, GmailSent, Sent, "Sent Items", "Sent Messages", "Sent Mail")
+}
+
+// FindSpecialFolder returns the sent, trash or spam folder
+func (c *IMAPClient) FindSpecialFolder(kind string) (string, error) {
+	switch kind {
+	case FolderSent:
+		return c.FindSentFolder()
+	case FolderTrash:
+		return c.FindTrashFolder()
+	case FolderSpam:
+		return c.FindSpamFolder()
+	}
+	return "", fmt.Errorf("unknown folder %q", kind)
+}
+
+// FindSpamFolder returns the spam folder: the \Junk special-use mailbox, [Gmail]/Spam, or a common name
+func (c *IMAPClient) FindSpamFolder() (string, error) {
+	return c.findFolder(imap.MailboxAttrJunk, GmailSpam, Spam, Junk, BulkMail, JunkMail)
+}
+
+// MoveToSpam moves messages from mailbox to the spam folder
+func (c *IMAPClient) MoveToSpam(uids []imap.UID, mailbox string) error {
+	spamFolder, err := c.FindSpamFolder()
+	if err != nil {
+		return fmt.Errorf("failed to find spam folder: %w", err)
 	}
+	if spamFolder == mailbox {
+		return nil
+	}
+	return c.MoveMessages(uids, mailbox, spamFolder)
+}
 
-	// Try to find folder with \Trash special-use attribute
-	listCmd := c.client.List("", "*", &imap.ListOptions{
-		ReturnStatus: &imap.StatusOptions{},
-	})
-	defer listCmd.Close()
+// MoveMessages moves messages between mailboxes
+func (c *IMAPClient) MoveMessages(uids []imap.UID, from, to string) error {
+	if len(uids) == 0 {
+		return nil
+	}
 
-	for {
-		mbox := listCmd.Next()
-		if mbox == nil {
-			break
-		}
-		for _, attr := range mbox.Attrs {
-			if attr == imap.MailboxAttrTrash {
-				return mbox.Mailbox, nil
-			}
-		}
+	// Re-select mailbox before Move (required after List on some servers)
+	if err := c.SelectMailbox(from); err != nil {
+		return fmt.Errorf("failed to select mailbox: %w", err)
 	}
 
-	// Fallback to common trash folder names
-	fallbacks := []string{"Trash", "Deleted", "Deleted Items", "Deleted Messages"}
-	for _, name := range fallbacks {
-		if c.mailboxExists(name) {
-			return name, nil
-		}
+	uidSet := imap.UIDSet{}
+	for _, uid := range uids {
+		uidSet.AddNum(uid)
 	}
 
-	return "", fmt.Errorf("trash folder not found")
+	if err := c.move(uidSet, to); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// EmptyFolder permanently deletes every message in a mailbox and returns how many were removed
+func (c *IMAPClient) EmptyFolder(mailbox string) (int, error) {
+	info, err := c.SelectMailboxWithInfo(mailbox)
+	if err != nil {
+		return 0, fmt.Errorf("failed to select mailbox: %w", err)
+	}
+	if info.NumMessages == 0 {
+		return 0, nil
+	}
+
+	all := imap.SeqSet{}
+	all.AddRange(1, 0)
+	storeFlags := &imap.StoreFlags{
+		Op:     imap.StoreFlagsAdd,
+		Silent: true,
+		Flags:  []imap.Flag{imap.FlagDeleted},
+	}
+	if err := c.client.Store(all, storeFlags, nil).Close(); err != nil {
+		return 0, err
+	}
+	if err := c.client.Expunge().Close(); err != nil {
+		return 0, err
+	}
+	return int(info.NumMessages), nil
 }
 
 func (c *IMAPClient) mailboxExists(name string) bool {
@@ -1042,47 +1387,43 @@ func (c *IMAPClient) mailboxExists(name string) bool {
 }
 
 func (c *IMAPClient) findArchiveFolder() (string, error) {
-	// Try Gmail-specific archive folder first
-	if c.mailboxExists(GmailAllMail) {
-		return GmailAllMail, nil
-	}
+	return c.findFolder(imap.MailboxAttrArchive, GmailAllMail, "Archive", "All Mail")
+}
 
-	// Try to find folder with \Archive special-use attribute
-	listCmd := c.client.List("", "*", &imap.ListOptions{
-		ReturnStatus: &imap.StatusOptions{},
-	})
-	defer listCmd.Close()
+func (c *IMAPClient) findDraftsFolder() (string, error) {
+	return c.findFolder(imap.MailboxAttrDrafts, GmailDrafts, "Drafts", "Draft")
+}
 
-	for {
-		mbox := listCmd.Next()
-		if mbox == nil {
-			break
+// findFolder finds the mailbox for a special use: by its attribute when the server
+// supports SPECIAL-USE, Gmail's fixed name when it speaks X-GM-EXT-1, then common names.
+// Servers that don't advertise SPECIAL-USE may still mark mailboxes, so the attribute is
+// checked after the Gmail name for them.
+func (c *IMAPClient) findFolder(attr imap.MailboxAttr, gmailName string, fallbacks ...string) (string, error) {
+	if c.caps.SpecialUse {
+		if name := c.specialUseMailbox(attr); name != "" {
+			return name, nil
 		}
-		for _, attr := range mbox.Attrs {
-			if attr == imap.MailboxAttrArchive {
-				return mbox.Mailbox, nil
-			}
+	}
+	if c.caps.GmailExt && c.mailboxExists(gmailName) {
+		return gmailName, nil
+	}
+	if !c.caps.SpecialUse {
+		if name := c.specialUseMailbox(attr); name != "" {
+			return name, nil
 		}
 	}
 
-	// Fallback to common archive folder names
-	fallbacks := []string{"Archive", "All Mail"}
 	for _, name := range fallbacks {
 		if c.mailboxExists(name) {
 			return name, nil
 		}
 	}
 
-	return "", fmt.Errorf("archive folder not found")
+	return "", fmt.Errorf("%s folder not found", strings.ToLower(strings.TrimPrefix(string(attr), "\\")))
 }
 
-func (c *IMAPClient) findDraftsFolder() (string, error) {
-	// Try Gmail-specific drafts folder first
-	if c.mailboxExists(GmailDrafts) {
-		return GmailDrafts, nil
-	}
-
-	// Try to find folder with \Drafts special-use attribute
+// specialUseMailbox returns the mailbox marked with a special-use attribute, or ""
+func (c *IMAPClient) specialUseMailbox(attr imap.MailboxAttr) string {
 	listCmd := c.client.List("", "*", &imap.ListOptions{
 		ReturnStatus: &imap.StatusOptions{},
 	})
@@ -1091,24 +1432,41 @@ func (c *IMAPClient) findDraftsFolder() (string, error) {
 	for {
 		mbox := listCmd.Next()
 		if mbox == nil {
-			break
+			return ""
 		}
-		for _, attr := range mbox.Attrs {
-			if attr == imap.MailboxAttrDrafts {
-				return mbox.Mailbox, nil
+		for _, a := range mbox.Attrs {
+			if a == attr {
+				return mbox.Mailbox
 			}
 		}
 	}
+}
 
-	// Fallback to common drafts folder names
-	fallbacks := []string{"Drafts", "Draft"}
-	for _, name := range fallbacks {
-		if c.mailboxExists(name) {
-			return name, nil
-		}
+// move moves messages from the selected mailbox. Without MOVE it copies, flags the
+// originals \Deleted and expunges just those with UID EXPUNGE; servers lacking UIDPLUS
+// too are left with the flagged originals, as a plain EXPUNGE would also remove other
+// messages the user flagged for deletion.
+func (c *IMAPClient) move(uidSet imap.UIDSet, to string) error {
+	if c.caps.Move {
+		_, err := c.client.Move(uidSet, to).Wait()
+		return err
 	}
 
-	return "", fmt.Errorf("drafts folder not found")
+	if _, err := c.client.Copy(uidSet, to).Wait(); err != nil {
+		return err
+	}
+	storeFlags := &imap.StoreFlags{
+		Op:     imap.StoreFlagsAdd,
+		Silent: true,
+		Flags:  []imap.Flag{imap.FlagDeleted},
+	}
+	if err := c.client.Store(uidSet, storeFlags, nil).Close(); err != nil {
+		return err
+	}
+	if c.caps.UIDPlus {
+		return c.client.UIDExpunge(uidSet).Close()
+	}
+	return nil
 }
 
 func (c *IMAPClient) ArchiveMessages(uids []imap.UID) error {
@@ -1126,13 +1484,25 @@ func (c *IMAPClient) ArchiveMessages(uids []imap.UID) error {
 		uidSet.AddNum(uid)
 	}
 
-	if _, err := c.client.Move(uidSet, archiveFolder).Wait(); err != nil {
+	if err := c.move(uidSet, archiveFolder); err != nil {
 		return err
 	}
 
 	return nil
 }
 
+// ArchiveFromMailbox moves messages from mailbox to the archive folder
+func (c *IMAPClient) ArchiveFromMailbox(uids []imap.UID, mailbox string) error {
+	archiveFolder, err := c.findArchiveFolder()
+	if err != nil {
+		return err
+	}
+	if archiveFolder == mailbox {
+		return nil
+	}
+	return c.MoveMessages(uids, mailbox, archiveFolder)
+}
+
 func (c *IMAPClient) MarkMessagesAsRead(uids []imap.UID) error {
 	if len(uids) == 0 {
 		return nil
@@ -1179,11 +1549,14 @@ func (c *IMAPClient) SaveDraft(to, subject, body string) error {
 }
 
 // SearchMessages searches for emails
-// For Gmail, uses X-GM-RAW extension with full search syntax
-// For other providers, uses standard IMAP TEXT search
+// On servers with X-GM-EXT-1 (Gmail), uses X-GM-RAW with full Gmail search syntax
+// Otherwise, uses standard IMAP TEXT search
 func (c *IMAPClient) SearchMessages(mailbox string, query string) ([]Email, error) {
-	// Use provider-appropriate search method
-	uids, err := Search(c.creds, mailbox, query)
+	stype := searchTypeText
+	if c.caps.GmailExt {
+		stype = searchTypeGmailRaw
+	}
+	uids, err := doSearch(c.creds, mailbox, query, stype)
 	if err != nil {
 		return nil, fmt.Errorf("search failed: %w", err)
 	}
@@ -1209,6 +1582,7 @@ func (c *IMAPClient) SearchMessages(mailbox string, query string) ([]Email, erro
 		Flags:         true,
 		Envelope:      true,
 		InternalDate:  true,
+		RFC822Size:    true,
 		BodyStructure: &imap.FetchItemBodyStructure{Extended: true},
 		BodySection:   []*imap.FetchItemBodySection{{Peek: true}},
 	}
@@ -1224,7 +1598,7 @@ func (c *IMAPClient) SearchMessages(mailbox string, query string) ([]Email, erro
 		email := c.parseMessageHeader(msg)
 		// Parse body if available
 		if len(msg.BodySection) > 0 {
-			bodyHTML, snippet := c.parseBody(msg.BodySection[0].Bytes)
+			bodyHTML, snippet := parseBody(msg.BodySection[0].Bytes)
 			email.BodyHTML = bodyHTML
 			email.Snippet = snippet
 		}
@@ -1257,6 +1631,7 @@ func (c *IMAPClient) FetchByUIDs(mailbox string, uids []imap.UID) ([]Email, erro
 		Flags:         true,
 		Envelope:      true,
 		InternalDate:  true,
+		RFC822Size:    true,
 		BodyStructure: &imap.FetchItemBodyStructure{Extended: true},
 		BodySection:   []*imap.FetchItemBodySection{{Peek: true}},
 	}
@@ -1272,7 +1647,7 @@ func (c *IMAPClient) FetchByUIDs(mailbox string, uids []imap.UID) ([]Email, erro
 		email := c.parseMessageHeader(msg)
 		// Parse body for snippet
 		if len(msg.BodySection) > 0 {
-			_, snippet := c.parseBody(msg.BodySection[0].Bytes)
+			_, snippet := parseBody(msg.BodySection[0].Bytes)
 			email.Snippet = snippet
 		}
 		emails = append(emails, email)
@@ -1287,6 +1662,7 @@ func (c *IMAPClient) parseMessageHeader(msg *imapclient.FetchMessageBuffer) Emai
 
 	email.UID = msg.UID
 	email.InternalDate = msg.InternalDate
+	email.Size = msg.RFC822Size
 
 	// Parse attachments from BODYSTRUCTURE
 	if msg.BodyStructure != nil {
@@ -1341,13 +1717,8 @@ func (c *IMAPClient) parseMessageHeader(msg *imapclient.FetchMessageBuffer) Emai
 		}
 	}
 
-	email.Unread = true
-	for _, flag := range msg.Flags {
-		if flag == imap.FlagSeen {
-			email.Unread = false
-			break
-		}
-	}
+	parseFlags(msg.Flags, &email)
+	parseListHeaders(msg, &email)
 
 	return email
 }
@@ -0,0 +1,276 @@
+package mail
+
+import (
+	"bufio"
+	"crypto/tls"
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/emersion/go-imap/v2"
+)
+
+// Gmail exposes labels through the X-GM-LABELS fetch item and store operation. Adding or
+// removing a label leaves the message in its folder, unlike a move. go-imap can't send
+// these, so they go over a raw session like X-GM-RAW search. The session stays open with
+// the client, so every sync doesn't log in again.
+
+var (
+	fetchUIDRegex = regexp.MustCompile(`\bUID (\d+)`)
+	literalRegex  = regexp.MustCompile(`\{(\d+)\}\r?\n$`)
+)
+
+// IsSystemLabel reports whether a Gmail label is one of the built-in ones (\Inbox,
+// \Important, \Starred, ...) rather than a label the user created
+func IsSystemLabel(label string) bool {
+	return strings.HasPrefix(label, "\\")
+}
+
+// FetchLabels returns the Gmail labels of each message in uids. Messages with no labels
+// map to an empty slice.
+func (c *IMAPClient) FetchLabels(mailbox string, uids []imap.UID) (map[imap.UID][]string, error) {
+	if !c.caps.GmailExt {
+		return nil, fmt.Errorf("server does not support labels")
+	}
+	if len(uids) == 0 {
+		return map[imap.UID][]string{}, nil
+	}
+
+	var labels map[imap.UID][]string
+	err := c.withLabelSession(mailbox, func(s *rawSession) error {
+		var err error
+		labels, err = fetchLabels(s.conn, s.reader, s.nextTag(), uids)
+		return err
+	})
+	return labels, err
+}
+
+// fetchLabels sends a UID FETCH of X-GM-LABELS and reads the labels of each message
+func fetchLabels(w io.Writer, reader *bufio.Reader, tag string, uids []imap.UID) (map[imap.UID][]string, error) {
+	cmd := fmt.Sprintf("%s UID FETCH %s (X-GM-LABELS)\r\n", tag, uidSetOf(uids).String())
+	if _, err := w.Write([]byte(cmd)); err != nil {
+		return nil, fmt.Errorf("failed to send fetch: %w", err)
+	}
+
+	labels := make(map[imap.UID][]string)
+	for {
+		line, err := readResponseLine(reader)
+		if err != nil {
+			return nil, fmt.Errorf("fetch labels failed: %w", err)
+		}
+		if strings.HasPrefix(line, tag+" OK") {
+			return labels, nil
+		}
+		if strings.HasPrefix(line, tag+" NO") || strings.HasPrefix(line, tag+" BAD") {
+			return nil, fmt.Errorf("fetch labels failed: %s", line)
+		}
+		if uid, l, ok := parseLabelsFetch(line); ok {
+			labels[uid] = l
+		}
+	}
+}
+
+// UpdateLabels adds and removes Gmail labels on messages without moving them
+func (c *IMAPClient) UpdateLabels(mailbox string, uids []imap.UID, add, remove []string) error {
+	if !c.caps.GmailExt {
+		return fmt.Errorf("server does not support labels")
+	}
+	if len(uids) == 0 || (len(add) == 0 && len(remove) == 0) {
+		return nil
+	}
+
+	set := uidSetOf(uids).String()
+	return c.withLabelSession(mailbox, func(s *rawSession) error {
+		for _, op := range []struct {
+			sign   string
+			labels []string
+		}{{"+", add}, {"-", remove}} {
+			if len(op.labels) == 0 {
+				continue
+			}
+			tag := s.nextTag()
+			cmd := fmt.Sprintf("%s UID STORE %s %sX-GM-LABELS.SILENT (%s)\r\n", tag, set, op.sign, labelList(op.labels))
+			if _, err := s.conn.Write([]byte(cmd)); err != nil {
+				return fmt.Errorf("failed to send store: %w", err)
+			}
+			if err := readUntilOK(s.reader, tag); err != nil {
+				return fmt.Errorf("failed to update labels: %w", err)
+			}
+		}
+		return nil
+	})
+}
+
+// rawSession is a logged-in raw connection kept open between label commands
+type rawSession struct {
+	conn    *tls.Conn
+	reader  *bufio.Reader
+	mailbox string // the selected mailbox
+	tag     int
+}
+
+// nextTag returns a fresh command tag, after the a1 and a2 openRawSession used
+func (s *rawSession) nextTag() string {
+	s.tag++
+	return fmt.Sprintf("a%d", s.tag+2)
+}
+
+// withLabelSession runs fn on the client's label session with mailbox selected, opening
+// the session if needed. A session the server dropped while idle is replaced once.
+func (c *IMAPClient) withLabelSession(mailbox string, fn func(*rawSession) error) error {
+	reused := c.labels != nil
+	for {
+		s, err := c.labelSession(mailbox)
+		if err == nil {
+			if err = fn(s); err == nil {
+				return nil
+			}
+		}
+		// The connection's state is unknown after a failure, so don't keep it
+		c.closeLabelSession()
+		if !reused {
+			return err
+		}
+		reused = false
+	}
+}
+
+// labelSession returns the open label session with mailbox selected
+func (c *IMAPClient) labelSession(mailbox string) (*rawSession, error) {
+	if c.labels == nil {
+		conn, reader, err := openRawSession(c.creds, mailbox)
+		if err != nil {
+			return nil, err
+		}
+		c.labels = &rawSession{conn: conn, reader: reader, mailbox: mailbox}
+		return c.labels, nil
+	}
+	s := c.labels
+	if s.mailbox != mailbox {
+		tag := s.nextTag()
+		if _, err := fmt.Fprintf(s.conn, "%s SELECT %s\r\n", tag, quoteString(mailbox)); err != nil {
+			return nil, fmt.Errorf("failed to send select: %w", err)
+		}
+		if err := readUntilOK(s.reader, tag); err != nil {
+			return nil, fmt.Errorf("select failed: %w", err)
+		}
+		s.mailbox = mailbox
+	}
+	return s, nil
+}
+
+// closeLabelSession logs out of the label session, if one is open
+func (c *IMAPClient) closeLabelSession() {
+	if c.labels == nil {
+		return
+	}
+	fmt.Fprintf(c.labels.conn, "%s LOGOUT\r\n", c.labels.nextTag())
+	c.labels.conn.Close()
+	c.labels = nil
+}
+
+func uidSetOf(uids []imap.UID) imap.UIDSet {
+	set := imap.UIDSet{}
+	for _, uid := range uids {
+		set.AddNum(uid)
+	}
+	return set
+}
+
+// labelList formats labels as a space-separated list of quoted, UTF-7 encoded names
+func labelList(labels []string) string {
+	quoted := make([]string, len(labels))
+	for i, label := range labels {
+		quoted[i] = quoteString(encodeUTF7(label))
+	}
+	return strings.Join(quoted, " ")
+}
+
+// readResponseLine reads one response line, inlining any {n} literals as quoted strings
+// so the line can be tokenized on its own
+func readResponseLine(reader *bufio.Reader) (string, error) {
+	var b strings.Builder
+	for {
+		line, err := reader.ReadString('\n')
+		if err != nil {
+			return "", err
+		}
+		m := literalRegex.FindStringSubmatchIndex(line)
+		if m == nil {
+			b.WriteString(line)
+			return strings.TrimSpace(b.String()), nil
+		}
+		size, err := strconv.Atoi(line[m[2]:m[3]])
+		if err != nil {
+			return "", err
+		}
+		literal := make([]byte, size)
+		if _, err := io.ReadFull(reader, literal); err != nil {
+			return "", err
+		}
+		b.WriteString(line[:m[0]])
+		b.WriteString(quoteString(string(literal)))
+	}
+}
+
+// parseLabelsFetch reads the UID and labels from a line like
+// `* 12 FETCH (X-GM-LABELS ("\\Important" Work) UID 345)`
+func parseLabelsFetch(line string) (imap.UID, []string, bool) {
+	if !strings.HasPrefix(line, "* ") || !strings.Contains(line, " FETCH ") {
+		return 0, nil, false
+	}
+	m := fetchUIDRegex.FindStringSubmatch(line)
+	if m == nil {
+		return 0, nil, false
+	}
+	uid, err := strconv.ParseUint(m[1], 10, 32)
+	if err != nil {
+		return 0, nil, false
+	}
+
+	i := strings.Index(line, "X-GM-LABELS (")
+	if i < 0 {
+		return 0, nil, false
+	}
+	labels := []string{}
+	rest := line[i+len("X-GM-LABELS ("):]
+	for {
+		rest = strings.TrimLeft(rest, " ")
+		if rest == "" || rest[0] == ')' {
+			break
+		}
+		var token string
+		if rest[0] == '"' {
+			token, rest = readQuoted(rest)
+		} else {
+			end := strings.IndexAny(rest, " )")
+			if end < 0 {
+				end = len(rest)
+			}
+			token, rest = rest[:end], rest[end:]
+		}
+		labels = append(labels, decodeUTF7(token))
+	}
+	return imap.UID(uid), labels, true
+}
+
+// readQuoted unescapes the quoted string at the start of s and returns it with the rest of s
+func readQuoted(s string) (string, string) {
+	var b strings.Builder
+	for i := 1; i < len(s); i++ {
+		switch s[i] {
+		case '\\':
+			if i+1 < len(s) {
+				i++
+				b.WriteByte(s[i])
+			}
+		case '"':
+			return b.String(), s[i+1:]
+		default:
+			b.WriteByte(s[i])
+		}
+	}
+	return b.String(), ""
+}
@@ -0,0 +1,82 @@
+package mail
+
+import (
+	"bufio"
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/emersion/go-imap/v2"
+)
+
+func TestParseLabelsFetch(t *testing.T) {
+	tests := []struct {
+		line   string
+		uid    imap.UID
+		labels []string
+		ok     bool
+	}{
+		{`* 12 FETCH (X-GM-LABELS ("\\Important" Work) UID 345)`, 345, []string{`\Important`, "Work"}, true},
+		{`* 12 FETCH (UID 345 X-GM-LABELS ())`, 345, []string{}, true},
+		{`* 1 FETCH (X-GM-LABELS ("Tom \"and\" Jerry" &U,BTFw-) UID 7)`, 7, []string{`Tom "and" Jerry`, "台北"}, true},
+		{`* 1 FETCH (X-GM-LABELS (\Inbox "&ZeVnLIqe-/Reise") UID 8)`, 8, []string{`\Inbox`, "日本語/Reise"}, true},
+		{`* 1 FETCH (FLAGS (\Seen) UID 9)`, 0, nil, false},
+		{`* 1 FETCH (X-GM-LABELS (Work))`, 0, nil, false},
+		{`* 3 EXISTS`, 0, nil, false},
+		{`a3 OK Success`, 0, nil, false},
+	}
+	for _, tt := range tests {
+		uid, labels, ok := parseLabelsFetch(tt.line)
+		if ok != tt.ok || uid != tt.uid || !reflect.DeepEqual(labels, tt.labels) {
+			t.Errorf("parseLabelsFetch(%q) = %d, %q, %v; want %d, %q, %v", tt.line, uid, labels, ok, tt.uid, tt.labels, tt.ok)
+		}
+	}
+}
+
+func TestReadResponseLine(t *testing.T) {
+	reader := bufio.NewReader(strings.NewReader("* 1 FETCH (X-GM-LABELS ({8}\r\nsay \"hi\" Work) UID 4)\r\na3 OK done\r\n"))
+	line, err := readResponseLine(reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := `* 1 FETCH (X-GM-LABELS ("say \"hi\"" Work) UID 4)`; line != want {
+		t.Errorf("readResponseLine() = %q, want %q", line, want)
+	}
+	if line, _ := readResponseLine(reader); line != "a3 OK done" {
+		t.Errorf("second line = %q", line)
+	}
+}
+
+func TestFetchLabels(t *testing.T) {
+	replies := strings.Join([]string{
+		`* 1 FETCH (X-GM-LABELS (\Inbox Work) UID 10)`,
+		`* 2 FETCH (X-GM-LABELS () UID 11)`,
+		`* 3 FETCH (X-GM-LABELS ({4}`,
+		`Täg) UID 12)`,
+		`a5 OK Success`,
+	}, "\r\n") + "\r\n"
+	var sent bytes.Buffer
+	labels, err := fetchLabels(&sent, bufio.NewReader(strings.NewReader(replies)), "a5", []imap.UID{10, 11, 12})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "a5 UID FETCH 10:12 (X-GM-LABELS)\r\n"; sent.String() != want {
+		t.Errorf("sent %q, want %q", sent.String(), want)
+	}
+	want := map[imap.UID][]string{10: {`\Inbox`, "Work"}, 11: {}, 12: {"Täg"}}
+	if !reflect.DeepEqual(labels, want) {
+		t.Errorf("fetchLabels() = %q, want %q", labels, want)
+	}
+
+	_, err = fetchLabels(&sent, bufio.NewReader(strings.NewReader("a6 NO [NONEXISTENT] Unknown\r\n")), "a6", []imap.UID{1})
+	if err == nil {
+		t.Error("fetchLabels() succeeded on NO")
+	}
+}
+
+func TestLabelList(t *testing.T) {
+	if got, want := labelList([]string{"Work", `a "b"`, "台北"}), `"Work" "a \"b\"" "&U,BTFw-"`; got != want {
+		t.Errorf("labelList() = %q, want %q", got, want)
+	}
+}
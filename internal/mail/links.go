@@ -0,0 +1,83 @@
+package mail
+
+import (
+	"html"
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+// Link is a link found in an email body
+type Link struct {
+	Text string // the visible text, empty for bare URLs
+	URL  string
+}
+
+var (
+	anchorRegex  = regexp.MustCompile(`(?is)<a\b[^>]*?\bhref\s*=\s*(?:"([^"]*)"|'([^']*)'|([^\s>]+))[^>]*>(.*?)</a>`)
+	tagRegex     = regexp.MustCompile(`(?s)<[^>]*>`)
+	bareURLRegex = regexp.MustCompile(`https?://[^\s<>"'()\[\]]+`)
+)
+
+// Query parameters redirectors and link scanners carry the real destination in
+var redirectParams = []string{"url", "u", "q", "target", "dest", "destination", "redirect", "redirect_url", "link"}
+
+// ExtractLinks returns the links in an email body: HTML anchors with their text, then
+// bare URLs in the text that aren't already among them
+func ExtractLinks(body string) []Link {
+	var links []Link
+	seen := map[string]bool{}
+	for _, m := range anchorRegex.FindAllStringSubmatch(body, -1) {
+		href := strings.TrimSpace(html.UnescapeString(m[1] + m[2] + m[3]))
+		if href == "" || strings.HasPrefix(href, "#") {
+			continue
+		}
+		text := strings.Join(strings.Fields(html.UnescapeString(tagRegex.ReplaceAllString(m[4], " "))), " ")
+		links = append(links, Link{Text: text, URL: href})
+		seen[href] = true
+	}
+	text := html.UnescapeString(tagRegex.ReplaceAllString(anchorRegex.ReplaceAllString(body, " "), " "))
+	for _, u := range bareURLRegex.FindAllString(text, -1) {
+		u = strings.TrimRight(u, ".,;:!?")
+		if !seen[u] {
+			links = append(links, Link{URL: u})
+			seen[u] = true
+		}
+	}
+	return links
+}
+
+// ResolveLink returns where a link really leads when it goes through a redirector or
+// link scanner such as google.com/url?q=... or Outlook Safe Links, following nested
+// redirects. Returns "" when the link isn't a redirect.
+func ResolveLink(link string) string {
+	resolved := ""
+	for range 3 {
+		u, err := url.Parse(link)
+		if err != nil || linkHost(link) == "" {
+			break
+		}
+		next := ""
+		query := u.Query()
+		for _, p := range redirectParams {
+			if v := query.Get(p); linkHost(v) != "" {
+				next = v
+				break
+			}
+		}
+		if next == "" {
+			break
+		}
+		resolved, link = next, next
+	}
+	return resolved
+}
+
+// linkHost returns the lowercase host of a web link, or "" for other links
+func linkHost(link string) string {
+	u, err := url.Parse(link)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") {
+		return ""
+	}
+	return strings.ToLower(u.Hostname())
+}
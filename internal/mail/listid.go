@@ -0,0 +1,28 @@
+package mail
+
+import "strings"
+
+// ParseListID splits an RFC 2919 List-Id header into the list identifier and its
+// display name, e.g. `"Go Weekly" <weekly.golang.example.com>`. The name falls back
+// to the identifier; id is empty for mail that isn't from a list.
+func ParseListID(header string) (id, name string) {
+	header = strings.TrimSpace(header)
+	if header == "" {
+		return "", ""
+	}
+
+	start := strings.LastIndex(header, "<")
+	end := strings.LastIndex(header, ">")
+	if start >= 0 && end > start {
+		id = strings.TrimSpace(header[start+1 : end])
+		name = strings.Trim(strings.TrimSpace(header[:start]), `"`)
+	} else {
+		id = header
+	}
+
+	id = strings.ToLower(id)
+	if name == "" {
+		name = id
+	}
+	return id, name
+}
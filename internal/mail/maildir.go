@@ -0,0 +1,1295 @@
+package mail
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/emersion/go-imap/v2"
+	"github.com/emersion/go-message"
+	"github.com/emersion/go-message/mail"
+
+	"maily/internal/auth"
+)
+
+// Maildir keeps each message in a file under a folder's new/ (not yet seen by a mail
+// client) or cur/ directory, with its flags after ":2," in the file name: S seen,
+// D draft, R replied, P passed (forwarded), F flagged, T trashed. Maildir has no UIDs, so each folder keeps
+// an index in maildirIndexFile giving every file a UID, in the order maily first saw
+// them. mbsync also records the server's UID in the file name as ",U=<uid>"; that part
+// belongs to the folder the file is in.
+
+// maildirIndexFile is the UID index kept in each folder; mbsync and offlineimap ignore
+// files they don't know
+const maildirIndexFile = ".maily-uids"
+
+// MaildirClient reads and writes a local Maildir tree, in the Maildir++ layout (the root
+// is the inbox, other folders are .Name directories) or mbsync's verbatim one (folders
+// are subdirectories), for accounts whose mail another tool fetches
+type MaildirClient struct {
+	creds    *auth.Credentials
+	root     string
+	selected string // mailbox the bare-UID methods act on, as with IMAP SELECT
+
+	mu      sync.Mutex
+	dirs    map[string]string         // folder directories by mailbox name, nil until the tree is walked
+	folders map[string]*maildirFolder // folders listed so far by directory
+}
+
+// maildirFolder is a folder's index and messages as last listed. They are listed again
+// only when new/ or cur/ changed since, as they do when another tool delivers or
+// renames a file; maily's own changes update them in place.
+type maildirFolder struct {
+	index  *maildirIndex
+	msgs   []maildirMessage // by UID
+	newMod time.Time
+	curMod time.Time
+}
+
+// maildirMessage is a message file and the UID the index gives it
+type maildirMessage struct {
+	uid  imap.UID
+	path string
+}
+
+// flags returns the letters after ":2," in the file name
+func (m maildirMessage) flags() string {
+	name := filepath.Base(m.path)
+	if i := strings.Index(name, ":2,"); i >= 0 {
+		return name[i+3:]
+	}
+	return ""
+}
+
+func (m maildirMessage) unread() bool {
+	return !strings.Contains(m.flags(), "S")
+}
+
+// marks maps the Maildir flag letters onto the flags maily tracks
+func (m maildirMessage) marks() Flags {
+	flags := m.flags()
+	return Flags{
+		Unread:    !strings.Contains(flags, "S"),
+		Answered:  strings.Contains(flags, "R"),
+		Forwarded: strings.Contains(flags, "P"),
+		Flagged:   strings.Contains(flags, "F"),
+	}
+}
+
+// maildirIndex maps the unique part of message file names to UIDs
+type maildirIndex struct {
+	validity uint32
+	next     imap.UID
+	uids     map[string]imap.UID
+}
+
+// NewMaildirClient opens the Maildir tree at the account's path
+func NewMaildirClient(creds *auth.Credentials) (*MaildirClient, error) {
+	root, err := expandHome(creds.MaildirPath)
+	if err != nil {
+		return nil, err
+	}
+	if info, err := os.Stat(root); err != nil || !info.IsDir() {
+		return nil, fmt.Errorf("maildir %s not found", root)
+	}
+	return &MaildirClient{creds: creds, root: root, selected: INBOX, folders: make(map[string]*maildirFolder)}, nil
+}
+
+// expandHome resolves a leading ~/ in a configured path
+func expandHome(path string) (string, error) {
+	rest, ok := strings.CutPrefix(path, "~/")
+	if !ok {
+		return path, nil
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(home, rest), nil
+}
+
+func (c *MaildirClient) Close() error {
+	return nil
+}
+
+// Capabilities returns no IMAP extensions, only whether a notmuch database is set
+func (c *MaildirClient) Capabilities() Capabilities {
+	return Capabilities{Notmuch: c.creds.NotmuchDB != ""}
+}
+
+// isMaildir reports whether dir holds a Maildir folder
+func isMaildir(dir string) bool {
+	for _, sub := range []string{"cur", "new", "tmp"} {
+		if info, err := os.Stat(filepath.Join(dir, sub)); err != nil || !info.IsDir() {
+			return false
+		}
+	}
+	return true
+}
+
+// maildirName turns a folder's path under the root into a mailbox name: Maildir++
+// folders (.Sent, .Lists.Go) separate levels with dots, the verbatim layout with
+// directories
+func maildirName(rel string) string {
+	rel = filepath.ToSlash(rel)
+	if strings.HasPrefix(rel, ".") && !strings.Contains(rel, "/") {
+		rel = strings.ReplaceAll(rel[1:], ".", "/")
+	}
+	if strings.EqualFold(rel, INBOX) {
+		return INBOX
+	}
+	return rel
+}
+
+// walkFolders finds the directory of every mailbox by name
+func (c *MaildirClient) walkFolders() (map[string]string, error) {
+	folders := make(map[string]string)
+	if isMaildir(c.root) {
+		folders[INBOX] = c.root
+	}
+	err := filepath.WalkDir(c.root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			if path == c.root {
+				return err
+			}
+			return nil
+		}
+		if !d.IsDir() || path == c.root {
+			return nil
+		}
+		switch d.Name() {
+		case "cur", "new", "tmp":
+			return filepath.SkipDir
+		}
+		if !isMaildir(path) {
+			// Hidden directories that aren't folders belong to other tools, like .notmuch
+			if strings.HasPrefix(d.Name(), ".") {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		rel, err := filepath.Rel(c.root, path)
+		if err != nil {
+			return err
+		}
+		folders[maildirName(rel)] = path
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	c.mu.Lock()
+	c.dirs = folders
+	c.mu.Unlock()
+	return folders, nil
+}
+
+// folderDir returns the directory of a mailbox, walking the tree again when it's one
+// maily hasn't seen yet
+func (c *MaildirClient) folderDir(mailbox string) (string, error) {
+	c.mu.Lock()
+	dir, ok := c.dirs[mailbox]
+	c.mu.Unlock()
+	if ok {
+		return dir, nil
+	}
+	folders, err := c.walkFolders()
+	if err != nil {
+		return "", err
+	}
+	dir, ok = folders[mailbox]
+	if !ok {
+		return "", fmt.Errorf("mailbox %s not found", mailbox)
+	}
+	return dir, nil
+}
+
+// createFolder makes a new folder in the tree's layout
+func (c *MaildirClient) createFolder(name string) (string, error) {
+	plusPlus := isMaildir(c.root)
+	dir := filepath.Join(c.root, filepath.FromSlash(name))
+	if plusPlus {
+		dir = filepath.Join(c.root, "."+strings.ReplaceAll(name, "/", "."))
+	}
+	for _, sub := range []string{"cur", "new", "tmp"} {
+		if err := os.MkdirAll(filepath.Join(dir, sub), 0700); err != nil {
+			return "", err
+		}
+	}
+	if plusPlus {
+		// Maildir++ marks folders with an empty maildirfolder file
+		if err := os.WriteFile(filepath.Join(dir, "maildirfolder"), nil, 0600); err != nil {
+			return "", err
+		}
+	}
+	c.mu.Lock()
+	if c.dirs != nil {
+		c.dirs[name] = dir
+	}
+	c.mu.Unlock()
+	return dir, nil
+}
+
+func (c *MaildirClient) ListMailboxes() ([]string, error) {
+	folders, err := c.walkFolders()
+	if err != nil {
+		return nil, err
+	}
+	names := make([]string, 0, len(folders))
+	for name := range folders {
+		names = append(names, name)
+	}
+	sort.Slice(names, func(i, j int) bool {
+		if (names[i] == INBOX) != (names[j] == INBOX) {
+			return names[i] == INBOX
+		}
+		return names[i] < names[j]
+	})
+	return names, nil
+}
+
+func (c *MaildirClient) SelectMailbox(name string) error {
+	if _, err := c.folderDir(name); err != nil {
+		return err
+	}
+	c.selected = name
+	return nil
+}
+
+// SelectMailboxWithInfo selects a mailbox and returns metadata
+func (c *MaildirClient) SelectMailboxWithInfo(name string) (*MailboxInfo, error) {
+	msgs, index, err := c.messages(name)
+	if err != nil {
+		return nil, err
+	}
+	c.selected = name
+	return &MailboxInfo{UIDValidity: index.validity, NumMessages: uint32(len(msgs))}, nil
+}
+
+// CountMessages returns the number of messages in a mailbox
+func (c *MaildirClient) CountMessages(mailbox string) (int, error) {
+	msgs, _, err := c.messages(mailbox)
+	return len(msgs), err
+}
+
+// loadIndex reads a folder's UID index, starting a new one when it's missing
+func loadIndex(dir string) (*maildirIndex, error) {
+	index := &maildirIndex{next: 1, uids: make(map[string]imap.UID)}
+	f, err := os.Open(filepath.Join(dir, maildirIndexFile))
+	if errors.Is(err, os.ErrNotExist) {
+		index.validity = uint32(time.Now().Unix())
+		return index, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	if scanner.Scan() {
+		var next uint32
+		if _, err := fmt.Sscanf(scanner.Text(), "%d %d", &index.validity, &next); err != nil {
+			return nil, fmt.Errorf("invalid maildir index in %s: %w", dir, err)
+		}
+		index.next = imap.UID(next)
+	}
+	for scanner.Scan() {
+		uid, key, ok := strings.Cut(scanner.Text(), " ")
+		n, err := strconv.ParseUint(uid, 10, 32)
+		if !ok || err != nil {
+			continue
+		}
+		index.uids[key] = imap.UID(n)
+	}
+	return index, scanner.Err()
+}
+
+// save writes the index, replacing the old one in one step
+func (index *maildirIndex) save(dir string) error {
+	var b bytes.Buffer
+	fmt.Fprintf(&b, "%d %d\n", index.validity, index.next)
+	keys := make([]string, 0, len(index.uids))
+	for key := range index.uids {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool { return index.uids[keys[i]] < index.uids[keys[j]] })
+	for _, key := range keys {
+		fmt.Fprintf(&b, "%d %s\n", index.uids[key], key)
+	}
+	tmp := filepath.Join(dir, maildirIndexFile+".tmp")
+	if err := os.WriteFile(tmp, b.Bytes(), 0600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, filepath.Join(dir, maildirIndexFile))
+}
+
+// maildirKey returns the unique part of a message file name, which stays the same when
+// its flags change or mbsync gives it a UID
+func maildirKey(name string) string {
+	key, _, _ := strings.Cut(name, ":")
+	return stripMbsyncUID(key)
+}
+
+// stripMbsyncUID removes the ",U=<uid>" mbsync puts in a file name. Left in after a move,
+// mbsync would take the file for the message with that UID in the new folder.
+func stripMbsyncUID(name string) string {
+	i := strings.Index(name, ",U=")
+	if i < 0 {
+		return name
+	}
+	j := i + len(",U=")
+	for j < len(name) && name[j] >= '0' && name[j] <= '9' {
+		j++
+	}
+	return name[:i] + name[j:]
+}
+
+// modTimes returns when a folder's new/ and cur/ last changed
+func modTimes(dir string) (newMod, curMod time.Time, err error) {
+	info, err := os.Stat(filepath.Join(dir, "new"))
+	if err != nil {
+		return
+	}
+	newMod = info.ModTime()
+	if info, err = os.Stat(filepath.Join(dir, "cur")); err != nil {
+		return
+	}
+	return newMod, info.ModTime(), nil
+}
+
+// messages lists a mailbox's messages by UID, giving files it hasn't seen the next UIDs
+// in order of delivery
+func (c *MaildirClient) messages(mailbox string) ([]maildirMessage, *maildirIndex, error) {
+	dir, err := c.folderDir(mailbox)
+	if err != nil {
+		return nil, nil, err
+	}
+	newMod, curMod, err := modTimes(dir)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	folder := c.folders[dir]
+	if folder != nil && folder.newMod.Equal(newMod) && folder.curMod.Equal(curMod) {
+		return slices.Clone(folder.msgs), folder.index, nil
+	}
+	var index *maildirIndex
+	if folder != nil {
+		index = folder.index
+	} else if index, err = loadIndex(dir); err != nil {
+		return nil, nil, err
+	}
+	msgs, err := scanFolder(dir, index)
+	if err != nil {
+		return nil, nil, err
+	}
+	c.folders[dir] = &maildirFolder{index: index, msgs: msgs, newMod: newMod, curMod: curMod}
+	return slices.Clone(msgs), index, nil
+}
+
+// scanFolder lists the message files of a folder, updating and saving its index when
+// files were added or removed
+func scanFolder(dir string, index *maildirIndex) ([]maildirMessage, error) {
+	type file struct {
+		path    string
+		key     string
+		modTime time.Time
+	}
+	var unseen []file
+	var msgs []maildirMessage
+	present := make(map[string]bool)
+	for _, sub := range []string{"new", "cur"} {
+		entries, err := os.ReadDir(filepath.Join(dir, sub))
+		if err != nil {
+			return nil, err
+		}
+		for _, entry := range entries {
+			if entry.IsDir() || strings.HasPrefix(entry.Name(), ".") {
+				continue
+			}
+			path := filepath.Join(dir, sub, entry.Name())
+			key := maildirKey(entry.Name())
+			present[key] = true
+			if uid, ok := index.uids[key]; ok {
+				msgs = append(msgs, maildirMessage{uid: uid, path: path})
+				continue
+			}
+			f := file{path: path, key: key}
+			if info, err := entry.Info(); err == nil {
+				f.modTime = info.ModTime()
+			}
+			unseen = append(unseen, f)
+		}
+	}
+
+	changed := len(unseen) > 0
+	for key := range index.uids {
+		if !present[key] {
+			delete(index.uids, key)
+			changed = true
+		}
+	}
+	sort.Slice(unseen, func(i, j int) bool {
+		if !unseen[i].modTime.Equal(unseen[j].modTime) {
+			return unseen[i].modTime.Before(unseen[j].modTime)
+		}
+		return unseen[i].key < unseen[j].key
+	})
+	for _, f := range unseen {
+		index.uids[f.key] = index.next
+		msgs = append(msgs, maildirMessage{uid: index.next, path: f.path})
+		index.next++
+	}
+	if changed {
+		if err := index.save(dir); err != nil {
+			return nil, err
+		}
+	}
+
+	sort.Slice(msgs, func(i, j int) bool { return msgs[i].uid < msgs[j].uid })
+	return msgs, nil
+}
+
+// update applies a change maily made to a folder's files to its listing, so the next
+// call to messages doesn't list the folder again. A folder not listed yet is left to
+// the first listing.
+func (c *MaildirClient) update(dir string, change func(folder *maildirFolder) error) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	folder := c.folders[dir]
+	if folder == nil {
+		return nil
+	}
+	if err := change(folder); err != nil {
+		delete(c.folders, dir)
+		return err
+	}
+	newMod, curMod, err := modTimes(dir)
+	if err != nil {
+		delete(c.folders, dir)
+		return nil
+	}
+	folder.newMod, folder.curMod = newMod, curMod
+	return nil
+}
+
+// renamed records a message file's new path after its flags changed
+func (c *MaildirClient) renamed(m maildirMessage, path string) error {
+	return c.update(filepath.Dir(filepath.Dir(m.path)), func(folder *maildirFolder) error {
+		for i := range folder.msgs {
+			if folder.msgs[i].uid == m.uid {
+				folder.msgs[i].path = path
+			}
+		}
+		return nil
+	})
+}
+
+// removed drops messages whose files maily deleted or moved out of dir
+func (c *MaildirClient) removed(dir string, gone []maildirMessage) error {
+	if len(gone) == 0 {
+		return nil
+	}
+	return c.update(dir, func(folder *maildirFolder) error {
+		uids := make(map[imap.UID]bool, len(gone))
+		for _, m := range gone {
+			uids[m.uid] = true
+			delete(folder.index.uids, maildirKey(filepath.Base(m.path)))
+		}
+		folder.msgs = slices.DeleteFunc(folder.msgs, func(m maildirMessage) bool { return uids[m.uid] })
+		return folder.index.save(dir)
+	})
+}
+
+// added gives files maily put in dir the next UIDs
+func (c *MaildirClient) added(dir string, paths []string) error {
+	if len(paths) == 0 {
+		return nil
+	}
+	return c.update(dir, func(folder *maildirFolder) error {
+		for _, path := range paths {
+			key := maildirKey(filepath.Base(path))
+			if _, ok := folder.index.uids[key]; ok {
+				continue
+			}
+			folder.index.uids[key] = folder.index.next
+			folder.msgs = append(folder.msgs, maildirMessage{uid: folder.index.next, path: path})
+			folder.index.next++
+		}
+		return folder.index.save(dir)
+	})
+}
+
+// lookup returns the messages of a mailbox with the given UIDs, in UID order
+func (c *MaildirClient) lookup(mailbox string, uids []imap.UID) ([]maildirMessage, error) {
+	msgs, _, err := c.messages(mailbox)
+	if err != nil {
+		return nil, err
+	}
+	wanted := make(map[imap.UID]bool, len(uids))
+	for _, uid := range uids {
+		wanted[uid] = true
+	}
+	var found []maildirMessage
+	for _, m := range msgs {
+		if wanted[m.uid] {
+			found = append(found, m)
+		}
+	}
+	return found, nil
+}
+
+// message returns one message of a mailbox, or ErrEmailNotFound
+func (c *MaildirClient) message(mailbox string, uid imap.UID) (maildirMessage, error) {
+	found, err := c.lookup(mailbox, []imap.UID{uid})
+	if err != nil {
+		return maildirMessage{}, err
+	}
+	if len(found) == 0 {
+		return maildirMessage{}, ErrEmailNotFound
+	}
+	return found[0], nil
+}
+
+// Detail read from message files: metadata only, headers with a snippet, or the whole
+// body as well
+const (
+	maildirMetadata = iota
+	maildirSnippet
+	maildirBody
+)
+
+// readEmails parses message files, skipping any removed meanwhile
+func readEmails(msgs []maildirMessage, detail int) ([]Email, error) {
+	emails := make([]Email, 0, len(msgs))
+	for _, m := range msgs {
+		email, err := readEmail(m, detail)
+		if errors.Is(err, os.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		emails = append(emails, email)
+	}
+	return emails, nil
+}
+
+// readEmail parses a message file. The delivery time is the file's modification time, which
+// mbsync and offlineimap set from the server's internal date.
+func readEmail(m maildirMessage, detail int) (Email, error) {
+	raw, err := os.ReadFile(m.path)
+	if err != nil {
+		return Email{}, err
+	}
+	flags := m.marks()
+	email := Email{
+		UID:       m.uid,
+		Unread:    flags.Unread,
+		Answered:  flags.Answered,
+		Forwarded: flags.Forwarded,
+		Flagged:   flags.Flagged,
+		Size:      int64(len(raw)),
+	}
+	if info, err := os.Stat(m.path); err == nil {
+		email.InternalDate = info.ModTime()
+	}
+
+	entity, err := message.Read(bytes.NewReader(raw))
+	if err != nil && entity == nil {
+		return email, nil
+	}
+	h := mail.Header{Header: entity.Header}
+	email.Subject, _ = h.Subject()
+	email.Date, _ = h.Date()
+	if email.InternalDate.IsZero() {
+		email.InternalDate = email.Date
+	}
+	email.MessageID, _ = h.MessageID()
+	if ids, err := h.MsgIDList("In-Reply-To"); err == nil {
+		email.References = strings.Join(ids, " ")
+	}
+	if from, err := h.AddressList("From"); err == nil && len(from) > 0 {
+		email.From = formatAddress(from[0])
+	}
+	if replyTo, err := h.AddressList("Reply-To"); err == nil && len(replyTo) > 0 {
+		email.ReplyTo = replyTo[0].Address
+	}
+	if to, err := h.AddressList("To"); err == nil && len(to) > 0 {
+		email.To = formatAddress(to[0])
+	}
+	if cc, err := h.AddressList("Cc"); err == nil && len(cc) > 0 {
+		addrs := make([]string, len(cc))
+		for i, a := range cc {
+			addrs[i] = formatAddress(a)
+		}
+		email.Cc = strings.Join(addrs, ", ")
+	}
+	email.ListID = h.Get("List-Id")
+	email.ListUnsubscribe = h.Get("List-Unsubscribe")
+	if email.ListUnsubscribe != "" {
+		email.ListUnsubscribePost = h.Get("List-Unsubscribe-Post")
+	}
+	email.Attachments = maildirAttachments(entity)
+
+	switch detail {
+	case maildirBody:
+		email.BodyHTML, email.Snippet = parseBody(raw)
+	case maildirSnippet:
+		_, email.Snippet = parseBody(raw)
+	}
+	return email, nil
+}
+
+// formatAddress formats an address as the IMAP client does: "Name <addr>", or the bare address
+func formatAddress(a *mail.Address) string {
+	if a.Name != "" {
+		return fmt.Sprintf("%s <%s>", a.Name, a.Address)
+	}
+	return a.Address
+}
+
+// walkParts calls fn for each leaf part of a message with its IMAP part number ("" for
+// a message that isn't multipart, "1", "2.1", ... otherwise) until fn returns true
+func walkParts(e *message.Entity, partID string, fn func(partID string, e *message.Entity) bool) bool {
+	mr := e.MultipartReader()
+	if mr == nil {
+		return fn(partID, e)
+	}
+	for i := 1; ; i++ {
+		part, err := mr.NextPart()
+		if err != nil {
+			return false
+		}
+		childID := strconv.Itoa(i)
+		if partID != "" {
+			childID = partID + "." + childID
+		}
+		if walkParts(part, childID, fn) {
+			return true
+		}
+	}
+}
+
+// maildirAttachments lists a message's attachments like parseAttachments does from
+// BODYSTRUCTURE, with their decoded sizes
+func maildirAttachments(e *message.Entity) []Attachment {
+	var attachments []Attachment
+	walkParts(e, "", func(partID string, part *message.Entity) bool {
+		contentType, params, _ := part.Header.ContentType()
+		disposition, dispParams, _ := part.Header.ContentDisposition()
+		filename := dispParams["filename"]
+		if filename == "" {
+			filename = params["name"]
+		}
+		filename = decodeHeader(filename)
+
+		isAttachment := strings.EqualFold(disposition, "attachment")
+		if !isAttachment && filename != "" {
+			isAttachment = contentType != "text/plain" && contentType != "text/html"
+		}
+		if isAttachment && filename != "" {
+			size, _ := io.Copy(io.Discard, part.Body)
+			attachments = append(attachments, Attachment{
+				PartID:      partID,
+				Filename:    filename,
+				ContentType: contentType,
+				Size:        size,
+				Encoding:    strings.ToLower(part.Header.Get("Content-Transfer-Encoding")),
+			})
+		}
+		return false
+	})
+	return attachments
+}
+
+// FetchUIDsAndFlags returns the UIDs of messages delivered since the given date, mapped
+// to their flags
+func (c *MaildirClient) FetchUIDsAndFlags(mailbox string, since time.Time) (map[imap.UID]Flags, error) {
+	msgs, _, err := c.messages(mailbox)
+	if err != nil {
+		return nil, err
+	}
+	result := make(map[imap.UID]Flags)
+	for _, m := range msgs {
+		info, err := os.Stat(m.path)
+		if err != nil || info.ModTime().Before(since) {
+			continue
+		}
+		result[m.uid] = m.marks()
+	}
+	return result, nil
+}
+
+// FetchAllUIDs returns the UIDs of every message in a folder
+func (c *MaildirClient) FetchAllUIDs(mailbox string) (map[imap.UID]bool, error) {
+	msgs, _, err := c.messages(mailbox)
+	if err != nil {
+		return nil, err
+	}
+	uids := make(map[imap.UID]bool, len(msgs))
+	for _, m := range msgs {
+		uids[m.uid] = true
+	}
+	return uids, nil
+}
+
+// newest returns the last limit messages of a mailbox, newest first
+func (c *MaildirClient) newest(mailbox string, limit uint32) ([]maildirMessage, error) {
+	msgs, _, err := c.messages(mailbox)
+	if err != nil {
+		return nil, err
+	}
+	if uint32(len(msgs)) > limit {
+		msgs = msgs[uint32(len(msgs))-limit:]
+	}
+	newest := slices.Clone(msgs)
+	slices.Reverse(newest)
+	return newest, nil
+}
+
+func (c *MaildirClient) FetchMessages(mailbox string, limit uint32) ([]Email, error) {
+	msgs, err := c.newest(mailbox, limit)
+	if err != nil {
+		return nil, err
+	}
+	return readEmails(msgs, maildirBody)
+}
+
+// FetchMessagesMetadata fetches email metadata without body content
+func (c *MaildirClient) FetchMessagesMetadata(mailbox string, limit uint32) ([]Email, error) {
+	msgs, err := c.newest(mailbox, limit)
+	if err != nil {
+		return nil, err
+	}
+	return readEmails(msgs, maildirMetadata)
+}
+
+// FetchMessagesSince fetches up to limit full messages delivered since the given date,
+// newest first
+func (c *MaildirClient) FetchMessagesSince(mailbox string, since time.Time, limit uint32) ([]Email, error) {
+	flags, err := c.FetchUIDsAndFlags(mailbox, since)
+	if err != nil {
+		return nil, err
+	}
+	uids := make([]imap.UID, 0, len(flags))
+	for uid := range flags {
+		uids = append(uids, uid)
+	}
+	slices.Sort(uids)
+	if uint32(len(uids)) > limit {
+		uids = uids[uint32(len(uids))-limit:]
+	}
+	emails, err := c.FetchMessagesByUIDs(mailbox, uids)
+	if err != nil {
+		return nil, err
+	}
+	sort.SliceStable(emails, func(i, j int) bool {
+		return emails[i].InternalDate.After(emails[j].InternalDate)
+	})
+	return emails, nil
+}
+
+// FetchMessagesByUIDs fetches full messages by their UIDs
+func (c *MaildirClient) FetchMessagesByUIDs(mailbox string, uids []imap.UID) ([]Email, error) {
+	msgs, err := c.lookup(mailbox, uids)
+	if err != nil {
+		return nil, err
+	}
+	return readEmails(msgs, maildirBody)
+}
+
+// FetchMessagesByUIDsMetadata fetches metadata only (no body) for given UIDs
+func (c *MaildirClient) FetchMessagesByUIDsMetadata(mailbox string, uids []imap.UID) ([]Email, error) {
+	msgs, err := c.lookup(mailbox, uids)
+	if err != nil {
+		return nil, err
+	}
+	return readEmails(msgs, maildirMetadata)
+}
+
+// FetchOlderMetadata fetches the metadata of up to limit emails with UIDs below before,
+// newest first
+func (c *MaildirClient) FetchOlderMetadata(mailbox string, before imap.UID, limit int) ([]Email, error) {
+	if before <= 1 || limit <= 0 {
+		return []Email{}, nil
+	}
+	msgs, _, err := c.messages(mailbox)
+	if err != nil {
+		return nil, err
+	}
+	var older []maildirMessage
+	for i := len(msgs) - 1; i >= 0 && len(older) < limit; i-- {
+		if msgs[i].uid < before {
+			older = append(older, msgs[i])
+		}
+	}
+	emails, err := readEmails(older, maildirMetadata)
+	if err != nil {
+		return nil, err
+	}
+	sort.SliceStable(emails, func(i, j int) bool {
+		return emails[i].InternalDate.After(emails[j].InternalDate)
+	})
+	return emails, nil
+}
+
+// FetchByUIDs fetches emails with snippets by their UIDs, newest first
+func (c *MaildirClient) FetchByUIDs(mailbox string, uids []imap.UID) ([]Email, error) {
+	msgs, err := c.lookup(mailbox, uids)
+	if err != nil {
+		return nil, err
+	}
+	emails, err := readEmails(msgs, maildirSnippet)
+	if err != nil {
+		return nil, err
+	}
+	slices.Reverse(emails)
+	return emails, nil
+}
+
+// FetchEmailBody reads the body of a single email by UID
+func (c *MaildirClient) FetchEmailBody(mailbox string, uid imap.UID) (bodyHTML string, snippet string, err error) {
+	raw, err := c.FetchRaw(mailbox, uid)
+	if err != nil {
+		return "", "", err
+	}
+	bodyHTML, snippet = parseBody(raw)
+	return bodyHTML, snippet, nil
+}
+
+// FetchRaw reads the full RFC 822 source of a message
+func (c *MaildirClient) FetchRaw(mailbox string, uid imap.UID) ([]byte, error) {
+	m, err := c.message(mailbox, uid)
+	if err != nil {
+		return nil, err
+	}
+	raw, err := os.ReadFile(m.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, ErrEmailNotFound
+	}
+	return raw, err
+}
+
+// FetchHeader reads the header block of a message
+func (c *MaildirClient) FetchHeader(mailbox string, uid imap.UID) ([]byte, error) {
+	raw, err := c.FetchRaw(mailbox, uid)
+	if err != nil {
+		return nil, err
+	}
+	for _, sep := range []string{"\r\n\r\n", "\n\n"} {
+		if i := bytes.Index(raw, []byte(sep)); i >= 0 {
+			return raw[:i+len(sep)], nil
+		}
+	}
+	return raw, nil
+}
+
+// FetchAttachment reads an attachment by its part ID, decoded; the encoding is known
+// from the message itself
+func (c *MaildirClient) FetchAttachment(mailbox string, uid imap.UID, partID string, encoding string) ([]byte, error) {
+	raw, err := c.FetchRaw(mailbox, uid)
+	if err != nil {
+		return nil, err
+	}
+	entity, err := message.Read(bytes.NewReader(raw))
+	if err != nil && entity == nil {
+		return nil, fmt.Errorf("failed to parse message: %w", err)
+	}
+
+	var data []byte
+	var readErr error
+	found := walkParts(entity, "", func(id string, part *message.Entity) bool {
+		if id != partID {
+			return false
+		}
+		data, readErr = io.ReadAll(part.Body)
+		return true
+	})
+	if !found {
+		return nil, fmt.Errorf("attachment not found")
+	}
+	return data, readErr
+}
+
+// FindUIDByMessageID returns the UID of the message with the given Message-ID header
+func (c *MaildirClient) FindUIDByMessageID(mailbox, messageID string) (imap.UID, error) {
+	msgs, _, err := c.messages(mailbox)
+	if err != nil {
+		return 0, err
+	}
+	want := strings.Trim(messageID, "<>")
+	for i := len(msgs) - 1; i >= 0; i-- {
+		email, err := readEmail(msgs[i], maildirMetadata)
+		if err == nil && email.MessageID == want {
+			return msgs[i].uid, nil
+		}
+	}
+	return 0, ErrEmailNotFound
+}
+
+// SearchMessages finds the emails containing every word of the query in their sender,
+// recipients, subject or text, newest first. With a notmuch database the query is
+// notmuch's instead.
+func (c *MaildirClient) SearchMessages(mailbox string, query string) ([]Email, error) {
+	if c.creds.NotmuchDB != "" {
+		return c.notmuchSearch(mailbox, query)
+	}
+	terms := strings.Fields(strings.ToLower(query))
+	msgs, _, err := c.messages(mailbox)
+	if err != nil {
+		return nil, err
+	}
+
+	emails := []Email{}
+	for i := len(msgs) - 1; i >= 0; i-- {
+		email, err := readEmail(msgs[i], maildirBody)
+		if err != nil {
+			continue
+		}
+		text := strings.ToLower(strings.Join([]string{
+			email.From, email.To, email.Cc, email.Subject, stripHTML(email.BodyHTML),
+		}, "\n"))
+		matched := true
+		for _, term := range terms {
+			if !strings.Contains(text, term) {
+				matched = false
+				break
+			}
+		}
+		if matched {
+			emails = append(emails, email)
+		}
+	}
+	return emails, nil
+}
+
+// setFlag adds or removes a flag letter from a message, moving it out of new/ as
+// mail clients do once they have seen it
+func (c *MaildirClient) setFlag(m maildirMessage, flag rune, on bool) error {
+	flags := strings.ReplaceAll(m.flags(), string(flag), "")
+	if on {
+		letters := []rune(flags + string(flag))
+		sort.Slice(letters, func(i, j int) bool { return letters[i] < letters[j] })
+		flags = string(letters)
+	}
+	folder := filepath.Dir(filepath.Dir(m.path))
+	name := maildirKey(filepath.Base(m.path)) + ":2," + flags
+	target := filepath.Join(folder, "cur", name)
+	if target == m.path {
+		return nil
+	}
+	err := os.Rename(m.path, target)
+	if errors.Is(err, os.ErrNotExist) {
+		return ErrEmailNotFound
+	}
+	if err != nil {
+		return err
+	}
+	return c.renamed(m, target)
+}
+
+// IsUnread reports whether a message in the selected mailbox lacks the seen flag
+func (c *MaildirClient) IsUnread(uid imap.UID) (bool, error) {
+	m, err := c.message(c.selected, uid)
+	if err != nil {
+		return false, err
+	}
+	return m.unread(), nil
+}
+
+func (c *MaildirClient) MarkAsRead(uid imap.UID) error {
+	m, err := c.message(c.selected, uid)
+	if err != nil {
+		return err
+	}
+	return c.setFlag(m, 'S', true)
+}
+
+func (c *MaildirClient) MarkAsUnread(uid imap.UID) error {
+	m, err := c.message(c.selected, uid)
+	if err != nil {
+		return err
+	}
+	return c.setFlag(m, 'S', false)
+}
+
+// MarkAsAnswered sets the R (replied) flag on an email once a reply to it has been sent
+func (c *MaildirClient) MarkAsAnswered(uid imap.UID) error {
+	m, err := c.message(c.selected, uid)
+	if err != nil {
+		return err
+	}
+	return c.setFlag(m, 'R', true)
+}
+
+func (c *MaildirClient) MarkMessagesAsRead(uids []imap.UID) error {
+	msgs, err := c.lookup(c.selected, uids)
+	if err != nil {
+		return err
+	}
+	for _, m := range msgs {
+		if err := c.setFlag(m, 'S', true); err != nil && !errors.Is(err, ErrEmailNotFound) {
+			return err
+		}
+	}
+	return nil
+}
+
+func (c *MaildirClient) DeleteMessage(uid imap.UID) error {
+	return c.DeleteMessages([]imap.UID{uid})
+}
+
+// DeleteMessages permanently removes messages from the selected mailbox
+func (c *MaildirClient) DeleteMessages(uids []imap.UID) error {
+	dir, err := c.folderDir(c.selected)
+	if err != nil {
+		return err
+	}
+	msgs, err := c.lookup(c.selected, uids)
+	if err != nil {
+		return err
+	}
+	return c.remove(dir, msgs)
+}
+
+// remove deletes message files from a folder
+func (c *MaildirClient) remove(dir string, msgs []maildirMessage) error {
+	var gone []maildirMessage
+	var err error
+	for _, m := range msgs {
+		if err = os.Remove(m.path); err != nil && !errors.Is(err, os.ErrNotExist) {
+			break
+		}
+		err = nil
+		gone = append(gone, m)
+	}
+	if updateErr := c.removed(dir, gone); err == nil {
+		err = updateErr
+	}
+	return err
+}
+
+// MoveMessages moves messages between mailboxes. They get new UIDs in the target, and
+// lose mbsync's UID from the source.
+func (c *MaildirClient) MoveMessages(uids []imap.UID, from, to string) error {
+	if len(uids) == 0 {
+		return nil
+	}
+	source, err := c.folderDir(from)
+	if err != nil {
+		return err
+	}
+	target, err := c.folderDir(to)
+	if err != nil {
+		return err
+	}
+	msgs, err := c.lookup(from, uids)
+	if err != nil {
+		return err
+	}
+	var moved []maildirMessage
+	var paths []string
+	for _, m := range msgs {
+		sub := filepath.Base(filepath.Dir(m.path))
+		path := filepath.Join(target, sub, stripMbsyncUID(filepath.Base(m.path)))
+		if err = os.Rename(m.path, path); err != nil && !errors.Is(err, os.ErrNotExist) {
+			break
+		}
+		moved = append(moved, m)
+		if err == nil {
+			paths = append(paths, path)
+		}
+		err = nil
+	}
+	if updateErr := c.removed(source, moved); err == nil {
+		err = updateErr
+	}
+	if updateErr := c.added(target, paths); err == nil {
+		err = updateErr
+	}
+	return err
+}
+
+// findFolder returns the first mailbox matching a special use, creating one named
+// create when there is none and create isn't empty
+func (c *MaildirClient) findFolder(kind string, match func(name string) bool, create string) (string, error) {
+	names, err := c.ListMailboxes()
+	if err != nil {
+		return "", err
+	}
+	for _, name := range names {
+		if match(name) {
+			return name, nil
+		}
+	}
+	if create == "" {
+		return "", fmt.Errorf("%s folder not found", kind)
+	}
+	if _, err := c.createFolder(create); err != nil {
+		return "", err
+	}
+	return create, nil
+}
+
+// FindTrashFolder returns the trash folder, creating Trash when there is none
+func (c *MaildirClient) FindTrashFolder() (string, error) {
+	return c.findFolder("trash", IsTrashFolder, Trash)
+}
+
+// FindSpecialFolder returns the sent, trash or spam folder
+func (c *MaildirClient) FindSpecialFolder(kind string) (string, error) {
+	switch kind {
+	case FolderSent:
+		return c.findFolder("sent", IsSentFolder, "")
+	case FolderTrash:
+		return c.FindTrashFolder()
+	case FolderSpam:
+		return c.findFolder("spam", IsSpamFolder, "")
+	}
+	return "", fmt.Errorf("unknown folder %q", kind)
+}
+
+func (c *MaildirClient) MoveToTrashFromMailbox(uids []imap.UID, mailbox string) error {
+	if len(uids) == 0 {
+		return nil
+	}
+	trash, err := c.FindTrashFolder()
+	if err != nil {
+		return fmt.Errorf("failed to find trash folder: %w", err)
+	}
+	if trash == mailbox {
+		return nil
+	}
+	return c.MoveMessages(uids, mailbox, trash)
+}
+
+// MoveToSpam moves messages from mailbox to the spam folder
+func (c *MaildirClient) MoveToSpam(uids []imap.UID, mailbox string) error {
+	spam, err := c.FindSpecialFolder(FolderSpam)
+	if err != nil {
+		return fmt.Errorf("failed to find spam folder: %w", err)
+	}
+	if spam == mailbox {
+		return nil
+	}
+	return c.MoveMessages(uids, mailbox, spam)
+}
+
+// ArchiveFromMailbox moves messages from mailbox to the archive folder, creating Archive
+// when there is none
+func (c *MaildirClient) ArchiveFromMailbox(uids []imap.UID, mailbox string) error {
+	archive, err := c.findFolder("archive", func(name string) bool {
+		return name == GmailAllMail || strings.EqualFold(name, Archive) || strings.EqualFold(name, "All Mail")
+	}, Archive)
+	if err != nil {
+		return err
+	}
+	if archive == mailbox {
+		return nil
+	}
+	return c.MoveMessages(uids, mailbox, archive)
+}
+
+// EmptyFolder permanently deletes every message in a mailbox and returns how many were removed
+func (c *MaildirClient) EmptyFolder(mailbox string) (int, error) {
+	dir, err := c.folderDir(mailbox)
+	if err != nil {
+		return 0, err
+	}
+	msgs, _, err := c.messages(mailbox)
+	if err != nil {
+		return 0, err
+	}
+	if err := c.remove(dir, msgs); err != nil {
+		return 0, err
+	}
+	return len(msgs), nil
+}
+
+// SaveDraft saves an email to the Drafts folder, creating it when there is none
+func (c *MaildirClient) SaveDraft(to, subject, body string) error {
+	drafts, err := c.findFolder("drafts", func(name string) bool {
+		return name == GmailDrafts || strings.EqualFold(name, Drafts) || strings.EqualFold(name, Draft)
+	}, Drafts)
+	if err != nil {
+		return err
+	}
+	dir, err := c.folderDir(drafts)
+	if err != nil {
+		return err
+	}
+
+	msg := fmt.Sprintf("From: %s\r\n"+
+		"To: %s\r\n"+
+		"Subject: %s\r\n"+
+		"Date: %s\r\n"+
+		"MIME-Version: 1.0\r\n"+
+		"Content-Type: text/plain; charset=\"utf-8\"\r\n"+
+		"\r\n"+
+		"%s", c.creds.Email, sanitizeHeader(to), sanitizeHeader(subject), time.Now().Format(time.RFC1123Z), body)
+	path, err := deliver(dir, []byte(msg), "DS")
+	if err != nil {
+		return err
+	}
+	return c.added(dir, []string{path})
+}
+
+// deliver writes a message into a folder the Maildir way: to tmp/ first, then renamed
+// into cur/ with its flags, and returns its path
+func deliver(dir string, data []byte, flags string) (string, error) {
+	host, err := os.Hostname()
+	if err != nil || host == "" {
+		host = "localhost"
+	}
+	host = strings.NewReplacer("/", "\\057", ":", "\\072").Replace(host)
+	now := time.Now()
+	name := fmt.Sprintf("%d.M%dP%d.%s", now.Unix(), now.Nanosecond()/1000, os.Getpid(), host)
+
+	tmp := filepath.Join(dir, "tmp", name)
+	if err := os.WriteFile(tmp, data, 0600); err != nil {
+		return "", err
+	}
+	path := filepath.Join(dir, "cur", name+":2,"+flags)
+	return path, os.Rename(tmp, path)
+}
+
+// FetchLabels returns the notmuch tags of each message. Without a notmuch database it
+// fails: Maildir has folders but no labels.
+func (c *MaildirClient) FetchLabels(mailbox string, uids []imap.UID) (map[imap.UID][]string, error) {
+	if c.creds.NotmuchDB == "" {
+		return nil, fmt.Errorf("maildir does not support labels")
+	}
+	return c.notmuchTags(mailbox, uids)
+}
+
+// UpdateLabels adds and removes notmuch tags. Without a notmuch database it fails.
+func (c *MaildirClient) UpdateLabels(mailbox string, uids []imap.UID, add, remove []string) error {
+	if c.creds.NotmuchDB == "" {
+		return fmt.Errorf("maildir does not support labels")
+	}
+	return c.notmuchUpdateTags(mailbox, uids, add, remove)
+}
+
+// Stats is empty: local mail has no connection
+func (c *MaildirClient) Stats() ConnStats {
+	return ConnStats{}
+}
+
+// StorageQuota fails: local mail has no quota
+func (c *MaildirClient) StorageQuota() (*StorageQuota, error) {
+	return nil, ErrQuotaUnsupported
+}
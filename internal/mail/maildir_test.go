@@ -0,0 +1,275 @@
+package mail
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/emersion/go-imap/v2"
+
+	"maily/internal/auth"
+)
+
+// newTestMaildir makes a Maildir++ tree with an inbox, Sent and Lists/Go folders
+func newTestMaildir(t *testing.T) (*MaildirClient, string) {
+	t.Helper()
+	root := t.TempDir()
+	for _, folder := range []string{"", ".Sent", ".Lists.Go"} {
+		for _, sub := range []string{"cur", "new", "tmp"} {
+			if err := os.MkdirAll(filepath.Join(root, folder, sub), 0700); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+	c, err := NewMaildirClient(&auth.Credentials{Email: "me@example.com", MaildirPath: root})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return c, root
+}
+
+// writeMessage puts a message file in a folder, delivered at the given time
+func writeMessage(t *testing.T, dir, name, subject string, delivered time.Time) string {
+	t.Helper()
+	msg := fmt.Sprintf("From: Ana <ana@example.com>\r\nTo: me@example.com\r\nSubject: %s\r\nMessage-ID: <%s@example.com>\r\n\r\nHello\r\n", subject, subject)
+	path := filepath.Join(dir, name)
+	if err := os.WriteFile(path, []byte(msg), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chtimes(path, delivered, delivered); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+// touch moves a directory's modification time forward, as a delivery by another tool would
+func touch(t *testing.T, dir string) {
+	t.Helper()
+	later := time.Now().Add(time.Minute)
+	if err := os.Chtimes(dir, later, later); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func subjects(t *testing.T, c *MaildirClient, mailbox string) []string {
+	t.Helper()
+	emails, err := c.FetchMessagesMetadata(mailbox, 100)
+	if err != nil {
+		t.Fatalf("FetchMessagesMetadata(%s): %v", mailbox, err)
+	}
+	var got []string
+	for _, e := range emails {
+		got = append(got, e.Subject)
+	}
+	return got
+}
+
+func TestMaildirListMailboxes(t *testing.T) {
+	c, root := newTestMaildir(t)
+	// Directories of other tools aren't folders
+	if err := os.MkdirAll(filepath.Join(root, ".notmuch", "xapian"), 0700); err != nil {
+		t.Fatal(err)
+	}
+	got, err := c.ListMailboxes()
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{INBOX, "Lists/Go", "Sent"}
+	if !slices.Equal(got, want) {
+		t.Errorf("ListMailboxes() = %v, want %v", got, want)
+	}
+	if _, err := c.folderDir("Missing"); err == nil {
+		t.Error("folderDir(Missing) succeeded")
+	}
+}
+
+func TestMaildirUIDsFollowDelivery(t *testing.T) {
+	c, root := newTestMaildir(t)
+	base := time.Date(2025, 3, 1, 9, 0, 0, 0, time.UTC)
+	writeMessage(t, filepath.Join(root, "cur"), "2.M1P1.host:2,S", "second", base.Add(time.Hour))
+	writeMessage(t, filepath.Join(root, "new"), "1.M1P1.host", "first", base)
+
+	if got, want := subjects(t, c, INBOX), []string{"second", "first"}; !slices.Equal(got, want) {
+		t.Fatalf("inbox = %v, want %v (newest first)", got, want)
+	}
+
+	// A message delivered by another tool is listed with the next UID
+	writeMessage(t, filepath.Join(root, "new"), "3.M1P1.host", "third", base.Add(2*time.Hour))
+	touch(t, filepath.Join(root, "new"))
+	uids, err := c.FetchAllUIDs(INBOX)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(uids) != 3 || !uids[3] {
+		t.Errorf("FetchAllUIDs() = %v, want UIDs 1-3", uids)
+	}
+
+	// The index survives a new client, as it does a restart
+	c2, err := NewMaildirClient(c.creds)
+	if err != nil {
+		t.Fatal(err)
+	}
+	emails, err := c2.FetchMessagesByUIDsMetadata(INBOX, []imap.UID{1})
+	if err != nil || len(emails) != 1 || emails[0].Subject != "first" {
+		t.Errorf("UID 1 after reopening = %v, %v; want first", emails, err)
+	}
+}
+
+func TestMaildirFlags(t *testing.T) {
+	c, root := newTestMaildir(t)
+	writeMessage(t, filepath.Join(root, "new"), "1.M1P1.host", "hello", time.Now())
+
+	flags, err := c.FetchUIDsAndFlags(INBOX, time.Time{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !flags[1].Unread {
+		t.Fatalf("new message flags = %+v, want unread", flags[1])
+	}
+
+	if err := c.SelectMailbox(INBOX); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.MarkAsRead(1); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.MarkAsAnswered(1); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(filepath.Join(root, "cur", "1.M1P1.host:2,RS")); err != nil {
+		t.Errorf("read and answered message not renamed to cur/...:2,RS: %v", err)
+	}
+	flags, err = c.FetchUIDsAndFlags(INBOX, time.Time{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := flags[1]; got.Unread || !got.Answered {
+		t.Errorf("flags = %+v, want read and answered", got)
+	}
+
+	if err := c.MarkAsUnread(1); err != nil {
+		t.Fatal(err)
+	}
+	if unread, err := c.IsUnread(1); err != nil || !unread {
+		t.Errorf("IsUnread() = %v, %v after MarkAsUnread", unread, err)
+	}
+	// The UID stays the same while the file name changes
+	if uids, _ := c.FetchAllUIDs(INBOX); len(uids) != 1 || !uids[1] {
+		t.Errorf("FetchAllUIDs() = %v, want only UID 1", uids)
+	}
+}
+
+func TestMaildirMove(t *testing.T) {
+	c, root := newTestMaildir(t)
+	base := time.Date(2025, 3, 1, 9, 0, 0, 0, time.UTC)
+	writeMessage(t, filepath.Join(root, "cur"), "1.M1P1.host,U=17:2,S", "keep", base)
+	writeMessage(t, filepath.Join(root, "cur"), "2.M1P1.host,U=18:2,FS", "move", base.Add(time.Hour))
+	writeMessage(t, filepath.Join(root, ".Sent", "cur"), "3.M1P1.host,U=4:2,S", "sent", base)
+
+	// List both folders first, so the move updates them in place
+	if got := subjects(t, c, "Sent"); !slices.Equal(got, []string{"sent"}) {
+		t.Fatalf("Sent = %v", got)
+	}
+	if err := c.MoveMessages([]imap.UID{2}, INBOX, "Sent"); err != nil {
+		t.Fatal(err)
+	}
+
+	if got := subjects(t, c, INBOX); !slices.Equal(got, []string{"keep"}) {
+		t.Errorf("inbox after move = %v, want [keep]", got)
+	}
+	if got := subjects(t, c, "Sent"); !slices.Equal(got, []string{"move", "sent"}) {
+		t.Errorf("Sent after move = %v, want [move sent]", got)
+	}
+	entries, err := os.ReadDir(filepath.Join(root, ".Sent", "cur"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var names []string
+	for _, e := range entries {
+		names = append(names, e.Name())
+	}
+	if !slices.Contains(names, "2.M1P1.host:2,FS") {
+		t.Errorf("moved file names = %v, want 2.M1P1.host:2,FS without mbsync's UID", names)
+	}
+	flags, err := c.FetchUIDsAndFlags("Sent", time.Time{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := flags[2]; !got.Flagged || got.Unread {
+		t.Errorf("moved message flags = %+v, want flagged and read", got)
+	}
+
+	// A new client lists the same UIDs from the saved indexes
+	c2, err := NewMaildirClient(c.creds)
+	if err != nil {
+		t.Fatal(err)
+	}
+	uids, err := c2.FetchAllUIDs("Sent")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(uids) != 2 || !uids[1] || !uids[2] {
+		t.Errorf("Sent UIDs after reopening = %v, want 1 and 2", uids)
+	}
+}
+
+func TestMaildirDelete(t *testing.T) {
+	c, root := newTestMaildir(t)
+	base := time.Date(2025, 3, 1, 9, 0, 0, 0, time.UTC)
+	for i, subject := range []string{"a", "b", "c"} {
+		writeMessage(t, filepath.Join(root, "cur"), fmt.Sprintf("%d.M1P1.host:2,S", i+1), subject, base.Add(time.Duration(i)*time.Hour))
+	}
+	if err := c.SelectMailbox(INBOX); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := c.FetchAllUIDs(INBOX); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.DeleteMessages([]imap.UID{1, 3}); err != nil {
+		t.Fatal(err)
+	}
+	if got := subjects(t, c, INBOX); !slices.Equal(got, []string{"b"}) {
+		t.Errorf("inbox after delete = %v, want [b]", got)
+	}
+	if _, err := c.FetchRaw(INBOX, 1); err != ErrEmailNotFound {
+		t.Errorf("FetchRaw(deleted) error = %v, want ErrEmailNotFound", err)
+	}
+	index, err := os.ReadFile(filepath.Join(root, maildirIndexFile))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(index), "1.M1P1.host") || !strings.Contains(string(index), "2 2.M1P1.host") {
+		t.Errorf("index after delete:\n%s", index)
+	}
+
+	n, err := c.EmptyFolder(INBOX)
+	if err != nil || n != 1 {
+		t.Errorf("EmptyFolder() = %d, %v; want 1", n, err)
+	}
+	if got := subjects(t, c, INBOX); len(got) != 0 {
+		t.Errorf("inbox after EmptyFolder = %v", got)
+	}
+}
+
+func TestStripMbsyncUID(t *testing.T) {
+	tests := []struct {
+		name, want string
+	}{
+		{"1.M1P1.host,U=42:2,S", "1.M1P1.host:2,S"},
+		{"1.M1P1.host,U=42", "1.M1P1.host"},
+		{"1.M1P1.host:2,S", "1.M1P1.host:2,S"},
+		{"1.M1P1.host,FMD5=abc,U=7:2,", "1.M1P1.host,FMD5=abc:2,"},
+	}
+	for _, tt := range tests {
+		if got := stripMbsyncUID(tt.name); got != tt.want {
+			t.Errorf("stripMbsyncUID(%q) = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+	if maildirKey("1.M1P1.host,U=42:2,S") != maildirKey("1.M1P1.host:2,") {
+		t.Error("maildirKey differs once mbsync adds a UID")
+	}
+}
@@ -0,0 +1,76 @@
+package mail
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// Mailto is a parsed RFC 6068 mailto: URL
+type Mailto struct {
+	To      []string // includes addresses from ?to=
+	Cc      []string
+	Bcc     []string
+	Subject string
+	Body    string
+}
+
+// IsMailto reports whether s is a mailto: URL
+func IsMailto(s string) bool {
+	return len(s) >= 7 && strings.EqualFold(s[:7], "mailto:")
+}
+
+// ParseMailto parses an RFC 6068 mailto: URL
+func ParseMailto(raw string) (Mailto, error) {
+	var m Mailto
+	if !IsMailto(raw) {
+		return m, fmt.Errorf("not a mailto: URL: %s", raw)
+	}
+	u, err := url.Parse("mailto:" + raw[7:])
+	if err != nil {
+		return m, fmt.Errorf("invalid mailto: URL: %w", err)
+	}
+
+	to, err := url.PathUnescape(u.Opaque)
+	if err != nil {
+		return m, fmt.Errorf("invalid mailto: URL: %w", err)
+	}
+	m.To = appendAddresses(m.To, to)
+
+	// '+' is a literal plus in mailto: URLs, not an encoded space
+	query, err := url.ParseQuery(strings.ReplaceAll(u.RawQuery, "+", "%2B"))
+	if err != nil {
+		return m, fmt.Errorf("invalid mailto: URL: %w", err)
+	}
+	fields := make(map[string][]string, len(query))
+	for key, values := range query {
+		key = strings.ToLower(key)
+		fields[key] = append(fields[key], values...)
+	}
+	for _, v := range fields["to"] {
+		m.To = appendAddresses(m.To, v)
+	}
+	for _, v := range fields["cc"] {
+		m.Cc = appendAddresses(m.Cc, v)
+	}
+	for _, v := range fields["bcc"] {
+		m.Bcc = appendAddresses(m.Bcc, v)
+	}
+	if v := fields["subject"]; len(v) > 0 {
+		m.Subject = v[0]
+	}
+	if v := fields["body"]; len(v) > 0 {
+		m.Body = strings.ReplaceAll(v[0], "\r\n", "\n")
+	}
+	return m, nil
+}
+
+// appendAddresses appends the non-empty entries of a comma-separated address list
+func appendAddresses(list []string, s string) []string {
+	for _, addr := range strings.Split(s, ",") {
+		if addr = strings.TrimSpace(addr); addr != "" {
+			list = append(list, addr)
+		}
+	}
+	return list
+}
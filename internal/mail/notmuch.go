@@ -0,0 +1,235 @@
+package mail
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/emersion/go-imap/v2"
+)
+
+// notmuch indexes the files of a Maildir and tags its messages. Maildir accounts with a
+// notmuch database search with notmuch's query syntax and use its tags as labels, while
+// maily keeps reading and moving the files itself.
+
+const (
+	// notmuchTimeout bounds a single notmuch command
+	notmuchTimeout = 30 * time.Second
+	// notmuchSearchLimit caps the emails a search returns, newest first
+	notmuchSearchLimit = 500
+	// notmuchBatch is how many messages one tag lookup asks about, keeping command lines short
+	notmuchBatch = 200
+)
+
+// notmuchAutomaticTags are tags notmuch derives from Maildir flags or the message itself.
+// maily shows that state its own way, so they aren't labels.
+var notmuchAutomaticTags = map[string]bool{
+	"unread":     true,
+	"replied":    true,
+	"passed":     true,
+	"flagged":    true,
+	"draft":      true,
+	"attachment": true,
+	"signed":     true,
+	"encrypted":  true,
+}
+
+// runNotmuch runs a notmuch command against a database, returning its stdout, or its
+// stderr as the error when it fails
+func runNotmuch(db string, stdin io.Reader, args ...string) ([]byte, error) {
+	path, err := expandHome(db)
+	if err != nil {
+		return nil, err
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), notmuchTimeout)
+	defer cancel()
+
+	cmd := exec.CommandContext(ctx, "notmuch", args...)
+	cmd.Env = append(os.Environ(), "NOTMUCH_DATABASE="+path)
+	cmd.Stdin = stdin
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return nil, fmt.Errorf("notmuch: %w: %s", err, msg)
+		}
+		return nil, fmt.Errorf("notmuch: %w", err)
+	}
+	return stdout.Bytes(), nil
+}
+
+// NotmuchTags lists the tags in use in a notmuch database, without the automatic ones
+func NotmuchTags(db string) ([]string, error) {
+	out, err := runNotmuch(db, nil, "search", "--output=tags", "*")
+	if err != nil {
+		return nil, err
+	}
+	var tags []string
+	for _, tag := range strings.Split(string(out), "\n") {
+		if tag = strings.TrimSpace(tag); tag != "" && !notmuchAutomaticTags[tag] {
+			tags = append(tags, tag)
+		}
+	}
+	sort.Strings(tags)
+	return tags, nil
+}
+
+// notmuchID is the query term matching a Message-ID
+func notmuchID(messageID string) string {
+	return `id:"` + strings.ReplaceAll(strings.Trim(messageID, "<>"), `"`, `""`) + `"`
+}
+
+// notmuchEncode hex-encodes a tag or query for notmuch's batch-tag format, which
+// separates fields with spaces
+func notmuchEncode(s string) string {
+	var b strings.Builder
+	for _, c := range []byte(s) {
+		if c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' || strings.IndexByte("-_@=.,:/", c) >= 0 {
+			b.WriteByte(c)
+		} else {
+			fmt.Fprintf(&b, "%%%02x", c)
+		}
+	}
+	return b.String()
+}
+
+// notmuchSearch runs a notmuch query and returns the matching emails in the mailbox,
+// newest first
+func (c *MaildirClient) notmuchSearch(mailbox, query string) ([]Email, error) {
+	msgs, _, err := c.messages(mailbox)
+	if err != nil {
+		return nil, err
+	}
+	dir, err := c.folderDir(mailbox)
+	if err != nil {
+		return nil, err
+	}
+	byKey := make(map[string]maildirMessage, len(msgs))
+	for _, m := range msgs {
+		byKey[maildirKey(filepath.Base(m.path))] = m
+	}
+
+	out, err := runNotmuch(c.creds.NotmuchDB, nil, "search", "--output=files", "--sort=newest-first", "--", query)
+	if err != nil {
+		return nil, err
+	}
+	var found []maildirMessage
+	seen := make(map[imap.UID]bool)
+	scanner := bufio.NewScanner(bytes.NewReader(out))
+	for scanner.Scan() && len(found) < notmuchSearchLimit {
+		path := scanner.Text()
+		if filepath.Dir(filepath.Dir(path)) != dir {
+			continue
+		}
+		if m, ok := byKey[maildirKey(filepath.Base(path))]; ok && !seen[m.uid] {
+			seen[m.uid] = true
+			found = append(found, m)
+		}
+	}
+	return readEmails(found, maildirSnippet)
+}
+
+// notmuchTags returns the tags of each message, looked up by Message-ID. Messages
+// notmuch hasn't indexed yet map to an empty slice.
+func (c *MaildirClient) notmuchTags(mailbox string, uids []imap.UID) (map[imap.UID][]string, error) {
+	ids, err := c.messageIDs(mailbox, uids)
+	if err != nil {
+		return nil, err
+	}
+	labels := make(map[imap.UID][]string, len(uids))
+	byID := make(map[string][]imap.UID, len(ids))
+	var terms []string
+	for uid, id := range ids {
+		labels[uid] = []string{}
+		if len(byID[id]) == 0 {
+			terms = append(terms, notmuchID(id))
+		}
+		byID[id] = append(byID[id], uid)
+	}
+
+	for start := 0; start < len(terms); start += notmuchBatch {
+		end := min(start+notmuchBatch, len(terms))
+		args := append([]string{"dump", "--format=batch-tag", "--"}, strings.Join(terms[start:end], " or "))
+		out, err := runNotmuch(c.creds.NotmuchDB, nil, args...)
+		if err != nil {
+			return nil, err
+		}
+		scanner := bufio.NewScanner(bytes.NewReader(out))
+		for scanner.Scan() {
+			// +tag1 +tag2 -- id:message-id, hex-encoded
+			tagPart, idPart, ok := strings.Cut(scanner.Text(), " -- ")
+			if !ok {
+				continue
+			}
+			id, err := url.PathUnescape(strings.TrimPrefix(idPart, "id:"))
+			if err != nil {
+				continue
+			}
+			var tags []string
+			for _, field := range strings.Fields(tagPart) {
+				tag, err := url.PathUnescape(strings.TrimPrefix(field, "+"))
+				if err == nil && !notmuchAutomaticTags[tag] {
+					tags = append(tags, tag)
+				}
+			}
+			for _, uid := range byID[id] {
+				labels[uid] = tags
+			}
+		}
+	}
+	return labels, nil
+}
+
+// notmuchUpdateTags adds and removes tags on messages, by Message-ID
+func (c *MaildirClient) notmuchUpdateTags(mailbox string, uids []imap.UID, add, remove []string) error {
+	ids, err := c.messageIDs(mailbox, uids)
+	if err != nil {
+		return err
+	}
+	var ops []string
+	for _, tag := range add {
+		ops = append(ops, "+"+notmuchEncode(tag))
+	}
+	for _, tag := range remove {
+		ops = append(ops, "-"+notmuchEncode(tag))
+	}
+	if len(ops) == 0 || len(ids) == 0 {
+		return nil
+	}
+
+	var batch strings.Builder
+	for _, id := range ids {
+		fmt.Fprintf(&batch, "%s -- %s\n", strings.Join(ops, " "), notmuchEncode(notmuchID(id)))
+	}
+	_, err = runNotmuch(c.creds.NotmuchDB, strings.NewReader(batch.String()), "tag", "--batch")
+	return err
+}
+
+// messageIDs reads the Message-IDs of messages, leaving out those without one
+func (c *MaildirClient) messageIDs(mailbox string, uids []imap.UID) (map[imap.UID]string, error) {
+	msgs, err := c.lookup(mailbox, uids)
+	if err != nil {
+		return nil, err
+	}
+	emails, err := readEmails(msgs, maildirMetadata)
+	if err != nil {
+		return nil, err
+	}
+	ids := make(map[imap.UID]string, len(emails))
+	for _, e := range emails {
+		if e.MessageID != "" {
+			ids[e.UID] = e.MessageID
+		}
+	}
+	return ids, nil
+}
@@ -0,0 +1,282 @@
+package mail
+
+import (
+	"fmt"
+	"net/mail"
+	"strings"
+	"unicode"
+
+	"golang.org/x/net/idna"
+)
+
+// brand is a frequently impersonated sender and the domains it really sends from
+type brand struct {
+	key     string // lowercase name as it appears in domains
+	name    string
+	domains []string
+}
+
+var brands = []brand{
+	{"paypal", "PayPal", []string{"paypal.com"}},
+	{"apple", "Apple", []string{"apple.com", "icloud.com"}},
+	{"microsoft", "Microsoft", []string{"microsoft.com", "outlook.com", "live.com", "office.com", "office365.com"}},
+	{"google", "Google", []string{"google.com", "gmail.com", "youtube.com"}},
+	{"amazon", "Amazon", []string{"amazon.com", "amazonaws.com", "amazonses.com"}},
+	{"netflix", "Netflix", []string{"netflix.com"}},
+	{"facebook", "Facebook", []string{"facebook.com", "facebookmail.com", "meta.com"}},
+	{"instagram", "Instagram", []string{"instagram.com", "facebookmail.com"}},
+	{"linkedin", "LinkedIn", []string{"linkedin.com"}},
+	{"dropbox", "Dropbox", []string{"dropbox.com", "dropboxmail.com"}},
+	{"docusign", "DocuSign", []string{"docusign.com", "docusign.net"}},
+	{"coinbase", "Coinbase", []string{"coinbase.com"}},
+	{"chase", "Chase", []string{"chase.com", "jpmorgan.com"}},
+	{"wellsfargo", "Wells Fargo", []string{"wellsfargo.com"}},
+	{"dhl", "DHL", []string{"dhl.com", "dhl.de"}},
+	{"fedex", "FedEx", []string{"fedex.com"}},
+	{"ups", "UPS", []string{"ups.com"}},
+	{"usps", "USPS", []string{"usps.com"}},
+}
+
+// Characters swapped in to make a domain look like a brand at a glance
+var homoglyphs = strings.NewReplacer("rn", "m", "vv", "w", "0", "o", "1", "l", "3", "e", "5", "s", "-", "")
+
+// PhishingSignals returns why an email looks like phishing: a display name that
+// claims another address or a brand the sender isn't, domains spelled with lookalike
+// letters from another script, lookalikes of
+// well-known brands, and links whose text shows a different site than they go to.
+// Empty when nothing stands out.
+func PhishingSignals(from, body string) []string {
+	var signals []string
+	seen := map[string]bool{}
+	add := func(signal string) {
+		if !seen[signal] {
+			seen[signal] = true
+			signals = append(signals, signal)
+		}
+	}
+
+	if parsed, err := mail.ParseAddress(from); err == nil {
+		_, domain, _ := strings.Cut(parsed.Address, "@")
+		domain = strings.ToLower(domain)
+		for _, s := range displayNameSignals(parsed.Name, parsed.Address, domain) {
+			add(s)
+		}
+		if hasLookalikeLetters(domain) {
+			add(fmt.Sprintf("The sender domain %s uses lookalike characters", domain))
+		}
+		if b := imitatedBrand(domain); b != nil {
+			add(fmt.Sprintf("The sender domain %s imitates %s", domain, b.name))
+		}
+	}
+
+	if body == "" {
+		return signals
+	}
+	for _, link := range ExtractLinks(body) {
+		for _, s := range LinkSignals(link) {
+			add(s)
+		}
+	}
+	return signals
+}
+
+// LinkSignals returns why a single link looks deceptive, empty when it doesn't
+func LinkSignals(link Link) []string {
+	host := linkHost(link.URL)
+	if host == "" {
+		return nil
+	}
+	var signals []string
+	if hasLookalikeLetters(host) {
+		signals = append(signals, fmt.Sprintf("A link goes to %s, which uses lookalike characters", host))
+	}
+	if b := imitatedBrand(host); b != nil {
+		signals = append(signals, fmt.Sprintf("A link goes to %s, which imitates %s", host, b.name))
+	}
+	if shown := textHost(link.Text); shown != "" && !domainsAligned(shown, host) {
+		signals = append(signals, fmt.Sprintf("A link shows %s but goes to %s", shown, host))
+	}
+	return signals
+}
+
+// displayNameSignals flags display names that show a different address than the
+// sender's, or a brand's name on mail from outside that brand
+func displayNameSignals(name, address, domain string) []string {
+	var signals []string
+	for _, field := range strings.Fields(name) {
+		shown := strings.Trim(field, `"'<>()[],;`)
+		if _, shownDomain, ok := strings.Cut(shown, "@"); ok && strings.Contains(shownDomain, ".") &&
+			!domainsAligned(strings.ToLower(shownDomain), domain) {
+			signals = append(signals, fmt.Sprintf("The name shows %s but the sender is %s", shown, address))
+		}
+	}
+
+	// Only short names count: "PayPal Service" is a claim, a sentence mentioning PayPal isn't
+	words := strings.FieldsFunc(strings.ToLower(name), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	if len(words) == 0 || len(words) > 4 {
+		return signals
+	}
+	for _, b := range brands {
+		if !containsBrand(words, b.key) || isBrandDomain(domain, b) {
+			continue
+		}
+		signals = append(signals, fmt.Sprintf("The name claims to be %s but the email was sent from %s", b.name, domain))
+	}
+	return signals
+}
+
+// containsBrand reports whether a brand key is one of the words, or two adjacent
+// words joined such as "wells fargo"
+func containsBrand(words []string, key string) bool {
+	for i, w := range words {
+		if w == key || (i+1 < len(words) && w+words[i+1] == key) {
+			return true
+		}
+	}
+	return false
+}
+
+// isBrandDomain reports whether a domain belongs to the brand
+func isBrandDomain(domain string, b brand) bool {
+	org := orgDomain(domain)
+	if label, _, _ := strings.Cut(org, "."); label == b.key {
+		return true
+	}
+	for _, d := range b.domains {
+		if domainsAligned(d, domain) {
+			return true
+		}
+	}
+	return false
+}
+
+// imitatedBrand returns the brand a domain dresses up as: a brand domain used as a
+// subdomain (paypal.com.example.net), lookalike characters (paypa1.com), one typo
+// away (arnazon.com) or the brand hyphenated with other words (paypal-secure.com).
+// Nil for the brand's own domains and everything else.
+func imitatedBrand(domain string) *brand {
+	domain = strings.ToLower(strings.TrimSuffix(domain, "."))
+	org := orgDomain(domain)
+	label, _, _ := strings.Cut(org, ".")
+	for i := range brands {
+		b := &brands[i]
+		if isBrandDomain(domain, *b) {
+			continue
+		}
+		for _, d := range b.domains {
+			if strings.HasPrefix(domain, d+".") || strings.Contains(domain, "."+d+".") {
+				return b
+			}
+		}
+		// Short names such as ups are too close to too many real words
+		if len(b.key) < 5 {
+			continue
+		}
+		if homoglyphs.Replace(label) == b.key || oneEditApart(label, b.key) {
+			return b
+		}
+		for _, part := range strings.Split(label, "-") {
+			if part == b.key {
+				return b
+			}
+		}
+	}
+	return nil
+}
+
+// scripts are the writing systems whose mixing in a domain label is checked
+var scripts = map[string]*unicode.RangeTable{
+	"Latin": unicode.Latin, "Cyrillic": unicode.Cyrillic, "Greek": unicode.Greek,
+	"Armenian": unicode.Armenian, "Cherokee": unicode.Cherokee, "Han": unicode.Han,
+	"Hiragana": unicode.Hiragana, "Katakana": unicode.Katakana, "Hangul": unicode.Hangul,
+	"Bopomofo": unicode.Bopomofo,
+}
+
+// eastAsian are the scripts written together, with Latin, in Chinese, Japanese and
+// Korean names
+var eastAsian = map[string]bool{"Han": true, "Hiragana": true, "Katakana": true, "Hangul": true, "Bopomofo": true}
+
+// latinLookalikes are the lower-case Cyrillic, Greek and Armenian letters that look
+// like Latin ones
+const latinLookalikes = "аеорсухіјѕԁԛԝһӏ" + "αοριυνκτχϲ" + "օսհոզ"
+
+// hasLookalikeLetters reports whether a domain, plain or punycode (xn--), has a label
+// that mixes scripts, such as a Cyrillic а in pаypal, or that is written in another
+// script entirely with letters that all look Latin, such as раураl. Internationalized
+// names in a single script, münchen.de or пример.рф, are fine.
+func hasLookalikeLetters(domain string) bool {
+	for _, label := range strings.Split(domain, ".") {
+		if strings.HasPrefix(label, "xn--") {
+			decoded, err := idna.Punycode.ToUnicode(label)
+			if err != nil {
+				return true // a broken encoding has no business in an address
+			}
+			label = decoded
+		}
+		if lookalikeLabel(label) {
+			return true
+		}
+	}
+	return false
+}
+
+// lookalikeLabel reports whether one domain label mixes scripts or is spelled wholly
+// with letters that pass for Latin
+func lookalikeLabel(label string) bool {
+	used := map[string]bool{}
+	confusable := true
+	for _, r := range strings.ToLower(label) {
+		if !unicode.IsLetter(r) {
+			continue
+		}
+		script := "other"
+		for name, table := range scripts {
+			if unicode.Is(table, r) {
+				script = name
+				break
+			}
+		}
+		used[script] = true
+		if !strings.ContainsRune(latinLookalikes, r) {
+			confusable = false
+		}
+	}
+	if len(used) == 0 || (len(used) == 1 && used["Latin"]) {
+		return false
+	}
+	if len(used) > 1 {
+		for name := range used {
+			if name != "Latin" && !eastAsian[name] {
+				return true
+			}
+		}
+		return false
+	}
+	return confusable
+}
+
+// textHost returns the host a link's text displays when the text is itself a URL or
+// domain, such as "https://paypal.com/login" or "www.paypal.com"; otherwise ""
+func textHost(text string) string {
+	text = strings.TrimSpace(text)
+	if text == "" || strings.ContainsAny(text, " \t") {
+		return ""
+	}
+	if !strings.Contains(text, "://") {
+		// Bare domains need a dot and a letter-only top level, so "v1.2" doesn't count
+		host, _, _ := strings.Cut(text, "/")
+		dot := strings.LastIndex(host, ".")
+		if dot <= 0 || dot == len(host)-1 || strings.Contains(host, "@") {
+			return ""
+		}
+		for _, r := range host[dot+1:] {
+			if !unicode.IsLetter(r) {
+				return ""
+			}
+		}
+		text = "http://" + text
+	}
+	return linkHost(text)
+}
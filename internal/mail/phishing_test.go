@@ -0,0 +1,120 @@
+package mail
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestPhishingSignals(t *testing.T) {
+	tests := []struct {
+		name string
+		from string
+		body string
+		want []string // a phrase each signal must contain, in order
+	}{
+		{name: "ordinary sender", from: "Alice <alice@example.com>"},
+		{name: "brand's own domain", from: "PayPal <service@mail.paypal.com>"},
+		{name: "brand on another domain", from: "PayPal Support <help@secure-mail.example>", want: []string{"claims to be PayPal"}},
+		{name: "name showing another address", from: `"ceo@company.com" <x@example.net>`, want: []string{"shows ceo@company.com"}},
+		{name: "long name mentioning a brand", from: "Your weekly digest of news about Apple and others <n@example.com>"},
+		{name: "lookalike domain", from: "Billing <billing@paypa1.com>", want: []string{"imitates PayPal"}},
+		{name: "mixed-script domain", from: "Team <team@xn--pypal-4ve.com>", want: []string{"lookalike characters"}},
+		{name: "internationalized domain", from: "Stadt <info@xn--mnchen-3ya.de>"},
+		{
+			name: "link showing another site",
+			from: "Alice <alice@example.com>",
+			body: `<a href="https://evil.example/login">https://example.com/login</a>`,
+			want: []string{"shows example.com but goes to evil.example"},
+		},
+		{
+			name: "link to a brand lookalike",
+			from: "Alice <alice@example.com>",
+			body: `<a href="https://amazon.com.evil.example/">Your order</a>`,
+			want: []string{"imitates Amazon"},
+		},
+		{
+			name: "repeated signals listed once",
+			from: "Alice <alice@example.com>",
+			body: `<a href="https://arnazon.com/a">x</a> <a href="https://arnazon.com/b">y</a>`,
+			want: []string{"imitates Amazon"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := PhishingSignals(tt.from, tt.body)
+			if len(got) != len(tt.want) {
+				t.Fatalf("PhishingSignals() = %q, want %d signals", got, len(tt.want))
+			}
+			for i, phrase := range tt.want {
+				if !strings.Contains(got[i], phrase) {
+					t.Errorf("signal %d = %q, want it to mention %q", i, got[i], phrase)
+				}
+			}
+		})
+	}
+}
+
+func TestImitatedBrand(t *testing.T) {
+	tests := map[string]string{
+		"paypal.com":             "",
+		"www.paypal.com":         "",
+		"paypal.co.uk":           "",
+		"paypal.com.example.net": "PayPal",
+		"paypa1.com":             "PayPal",
+		"paypal-secure.com":      "PayPal",
+		"arnazon.com":            "Amazon",
+		"amazom.com":             "Amazon",
+		"netfiix.com":            "Netflix",
+		"ups-tracking.com":       "", // too short a name to guess from
+		"example.com":            "",
+		"nextflix.example":       "Netflix", // one letter added
+	}
+	for domain, want := range tests {
+		got := ""
+		if b := imitatedBrand(domain); b != nil {
+			got = b.name
+		}
+		if got != want {
+			t.Errorf("imitatedBrand(%q) = %q, want %q", domain, got, want)
+		}
+	}
+}
+
+func TestHasLookalikeLetters(t *testing.T) {
+	tests := map[string]bool{
+		"example.com":           false,
+		"münchen.de":            false,
+		"xn--mnchen-3ya.de":     false,
+		"пример.рф":             false,
+		"xn--e1afmkfd.xn--p1ai": false,
+		"例え.jp":                 false,
+		"東京tokyo.jp":            false,
+		"pаypal.com":            true, // Cyrillic а
+		"xn--pypal-4ve.com":     true,
+		"раура1.com":            true, // all Cyrillic lookalikes
+		"аррӏе.com":             true,
+		"gοοgle.com":            true, // Greek ο
+	}
+	for domain, want := range tests {
+		if got := hasLookalikeLetters(domain); got != want {
+			t.Errorf("hasLookalikeLetters(%q) = %v, want %v", domain, got, want)
+		}
+	}
+}
+
+func TestTextHost(t *testing.T) {
+	tests := map[string]string{
+		"https://PayPal.com/login": "paypal.com",
+		"www.example.com":          "www.example.com",
+		"example.com/path":         "example.com",
+		"Click here":               "",
+		"v1.2":                     "",
+		"alice@example.com":        "",
+		"":                         "",
+	}
+	for text, want := range tests {
+		if got := textHost(text); got != want {
+			t.Errorf("textHost(%q) = %q, want %q", text, got, want)
+		}
+	}
+}
@@ -22,4 +22,39 @@ const (
 	BulkMail = "Bulk Mail"
 	Archive  = "Archive"
 	Junk     = "Junk"
+	JunkMail = "Junk E-mail" // Outlook / Exchange
 )
+
+// Special folders with their own views, as named by clients asking for them
+const (
+	FolderSent  = "sent"
+	FolderTrash = "trash"
+	FolderSpam  = "spam"
+)
+
+// IsSentFolder reports whether a mailbox name is a well-known sent folder
+func IsSentFolder(name string) bool {
+	switch name {
+	case GmailSent, Sent, "Sent Items", "Sent Messages", "Sent Mail":
+		return true
+	}
+	return false
+}
+
+// IsTrashFolder reports whether a mailbox name is a well-known trash folder
+func IsTrashFolder(name string) bool {
+	switch name {
+	case GmailTrash, Trash, "Deleted", "Deleted Items", "Deleted Messages":
+		return true
+	}
+	return false
+}
+
+// IsSpamFolder reports whether a mailbox name is a well-known spam folder
+func IsSpamFolder(name string) bool {
+	switch name {
+	case GmailSpam, Spam, BulkMail, Junk, JunkMail:
+		return true
+	}
+	return false
+}
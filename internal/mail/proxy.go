@@ -0,0 +1,122 @@
+package mail
+
+import (
+	"bufio"
+	"crypto/tls"
+	"encoding/base64"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+
+	"golang.org/x/net/proxy"
+
+	"maily/internal/auth"
+)
+
+// Connections to IMAP and SMTP servers go through the account's proxy, or the one in
+// ALL_PROXY (minus hosts in NO_PROXY) when the account doesn't name one. x/net/proxy
+// speaks SOCKS5; HTTP proxies are reached with CONNECT.
+
+// proxyDirect in an account's proxy setting bypasses ALL_PROXY
+const proxyDirect = "direct"
+
+func init() {
+	proxy.RegisterDialerType("http", newHTTPProxy)
+	proxy.RegisterDialerType("https", newHTTPProxy)
+}
+
+// dialerFor returns how to reach the account's servers
+func dialerFor(creds *auth.Credentials) (proxy.Dialer, error) {
+	direct := &net.Dialer{Timeout: dialTimeout}
+	switch creds.Proxy {
+	case "":
+		return proxy.FromEnvironmentUsing(direct), nil
+	case proxyDirect:
+		return direct, nil
+	}
+	u, err := url.Parse(creds.Proxy)
+	if err != nil {
+		return nil, fmt.Errorf("invalid proxy: %w", err)
+	}
+	d, err := proxy.FromURL(u, direct)
+	if err != nil {
+		return nil, fmt.Errorf("proxy %s: %w", u.Redacted(), err)
+	}
+	return d, nil
+}
+
+// ValidateProxy checks an account's proxy setting: a socks5://, socks5h://, http:// or
+// https:// URL, or "direct"
+func ValidateProxy(setting string) error {
+	_, err := dialerFor(&auth.Credentials{Proxy: setting})
+	return err
+}
+
+// httpProxy tunnels connections through an HTTP proxy with CONNECT
+type httpProxy struct {
+	url     *url.URL
+	forward proxy.Dialer
+}
+
+func newHTTPProxy(u *url.URL, forward proxy.Dialer) (proxy.Dialer, error) {
+	return &httpProxy{url: u, forward: forward}, nil
+}
+
+func (p *httpProxy) Dial(network, addr string) (net.Conn, error) {
+	host := p.url.Host
+	if p.url.Port() == "" {
+		port := "80"
+		if p.url.Scheme == "https" {
+			port = "443"
+		}
+		host = net.JoinHostPort(p.url.Hostname(), port)
+	}
+	conn, err := p.forward.Dial(network, host)
+	if err != nil {
+		return nil, fmt.Errorf("connecting to proxy %s: %w", p.url.Redacted(), err)
+	}
+	if p.url.Scheme == "https" {
+		conn = tls.Client(conn, &tls.Config{ServerName: p.url.Hostname()})
+	}
+
+	req := &http.Request{
+		Method: http.MethodConnect,
+		URL:    &url.URL{Opaque: addr},
+		Host:   addr,
+		Header: make(http.Header),
+	}
+	if user := p.url.User; user != nil {
+		password, _ := user.Password()
+		credentials := base64.StdEncoding.EncodeToString([]byte(user.Username() + ":" + password))
+		req.Header.Set("Proxy-Authorization", "Basic "+credentials)
+	}
+	if err := req.Write(conn); err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("proxy %s: %w", p.url.Redacted(), err)
+	}
+
+	br := bufio.NewReader(conn)
+	resp, err := http.ReadResponse(br, req)
+	if err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("proxy %s: %w", p.url.Redacted(), err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		conn.Close()
+		return nil, fmt.Errorf("proxy %s refused to connect to %s: %s", p.url.Redacted(), addr, resp.Status)
+	}
+	// The server may have spoken first (an IMAP greeting) and been read along with the reply
+	return &bufferedConn{Conn: conn, r: br}, nil
+}
+
+// bufferedConn reads what a bufio.Reader already took from the connection first
+type bufferedConn struct {
+	net.Conn
+	r *bufio.Reader
+}
+
+func (c *bufferedConn) Read(p []byte) (int, error) {
+	return c.r.Read(p)
+}
@@ -0,0 +1,74 @@
+package mail
+
+import (
+	"errors"
+
+	"github.com/emersion/go-imap/v2"
+)
+
+// ErrQuotaUnsupported is returned when the server doesn't report storage usage
+var ErrQuotaUnsupported = errors.New("server does not report storage usage")
+
+// StorageQuota is the mailbox storage an account uses and is allowed, from the QUOTA
+// extension. Gmail reports the quota shared with Drive and Photos.
+type StorageQuota struct {
+	UsedBytes  int64 `json:"used_bytes"`
+	LimitBytes int64 `json:"limit_bytes"`
+}
+
+// quotaWarnPercent is the usage from which storage is reported as nearly full
+const quotaWarnPercent = 90
+
+// Percent returns the share of the limit in use, 0-100
+func (q StorageQuota) Percent() float64 {
+	if q.LimitBytes <= 0 {
+		return 0
+	}
+	return float64(q.UsedBytes) * 100 / float64(q.LimitBytes)
+}
+
+// NearlyFull reports whether so little storage is left that new mail may soon bounce
+func (q StorageQuota) NearlyFull() bool {
+	return q.Percent() >= quotaWarnPercent
+}
+
+// StorageQuota returns the storage quota of the INBOX's quota root. When the INBOX falls
+// under several roots, the one closest to its limit is returned.
+func (c *IMAPClient) StorageQuota() (*StorageQuota, error) {
+	if !c.caps.Quota {
+		return nil, ErrQuotaUnsupported
+	}
+	roots, err := c.client.GetQuotaRoot(INBOX).Wait()
+	if err != nil {
+		return nil, err
+	}
+
+	var quota *StorageQuota
+	for _, root := range roots {
+		res, ok := root.Resources[imap.QuotaResourceStorage]
+		if !ok || res.Limit <= 0 {
+			continue
+		}
+		// STORAGE is counted in units of 1024 octets
+		q := &StorageQuota{UsedBytes: res.Usage * 1024, LimitBytes: res.Limit * 1024}
+		if quota == nil || q.Percent() > quota.Percent() {
+			quota = q
+		}
+	}
+	if quota == nil {
+		return nil, ErrQuotaUnsupported
+	}
+	return quota, nil
+}
+
+// CountMessages returns the number of messages in a mailbox without selecting it
+func (c *IMAPClient) CountMessages(mailbox string) (int, error) {
+	data, err := c.client.Status(mailbox, &imap.StatusOptions{NumMessages: true}).Wait()
+	if err != nil {
+		return 0, err
+	}
+	if data.NumMessages == nil {
+		return 0, nil
+	}
+	return int(*data.NumMessages), nil
+}
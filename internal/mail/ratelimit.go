@@ -0,0 +1,264 @@
+package mail
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Providers throttle clients that send commands too fast: Gmail answers with
+// [THROTTLED] or drops the connection with "Account exceeded command or bandwidth
+// limits". maily paces each account's commands with a token bucket, and when the server
+// throttles it anyway, halves the pace. A command refused for throttling also stops
+// background work for a while; the user's own commands still go out. The state lives
+// per account rather than per connection, so reconnecting doesn't reset it.
+
+const (
+	// commandRate is how many commands a second an account sends at full pace
+	commandRate = 10
+	// commandBurst is how many commands can go out back to back after a quiet spell
+	commandBurst = 30
+	// maxSlowdown caps how many times throttling halves the pace
+	maxSlowdown = 4
+	// throttleBackoff is the first pause after a throttle, doubling with each one after
+	throttleBackoff = time.Minute
+	// maxThrottleBackoff caps the pause
+	maxThrottleBackoff = 15 * time.Minute
+	// throttleRecovery is how long an account must go unthrottled to regain a step of pace
+	throttleRecovery = 10 * time.Minute
+	// softThrottleGap is how often a warning that still lets commands through, such as
+	// OK [THROTTLED], may halve the pace; servers repeat it on every slow response
+	softThrottleGap = time.Minute
+)
+
+// ErrThrottled is returned to background work while the provider is throttling an
+// account and maily is waiting before sending it more commands
+var ErrThrottled = errors.New("provider throttling, backing off")
+
+// throttleMarkers are the response texts of a throttling server
+var throttleMarkers = []string{
+	"[THROTTLED]",
+	"exceeded command or bandwidth limits",
+}
+
+// IsThrottled reports whether err comes from a throttling server or from maily backing
+// off. It also matches errors relayed as plain text from the server process.
+func IsThrottled(err error) bool {
+	if err == nil {
+		return false
+	}
+	if errors.Is(err, ErrThrottled) {
+		return true
+	}
+	errStr := err.Error()
+	if strings.Contains(errStr, ErrThrottled.Error()) {
+		return true
+	}
+	for _, marker := range throttleMarkers {
+		if strings.Contains(errStr, marker) {
+			return true
+		}
+	}
+	return false
+}
+
+// rateLimiter paces the commands of one account
+type rateLimiter struct {
+	mu          sync.Mutex
+	tokens      float64
+	last        time.Time // when tokens was last topped up
+	slowdown    int       // how many times the pace has been halved
+	throttled   time.Time // the latest throttle
+	until       time.Time // no background commands before this
+	throttles   int
+	interactive int // commands of the user in progress, which go out during a backoff
+}
+
+var (
+	limitersMu sync.Mutex
+	limiters   = make(map[string]*rateLimiter)
+)
+
+// limiterFor returns the account's limiter, shared by all its connections
+func limiterFor(email string) *rateLimiter {
+	limitersMu.Lock()
+	defer limitersMu.Unlock()
+	l, ok := limiters[email]
+	if !ok {
+		l = &rateLimiter{tokens: commandBurst, last: time.Now()}
+		limiters[email] = l
+	}
+	return l
+}
+
+// Interactive marks the account's commands as the user's until done is called: they
+// go out during a backoff and without waiting for their turn, while background work
+// stays paced
+func Interactive(email string) (done func()) {
+	l := limiterFor(email)
+	l.mu.Lock()
+	l.interactive++
+	l.mu.Unlock()
+	return func() {
+		l.mu.Lock()
+		l.interactive--
+		l.mu.Unlock()
+	}
+}
+
+// ThrottleBackoff returns an error wrapping ErrThrottled while the account is backing
+// off from a throttling provider, and nil otherwise
+func ThrottleBackoff(email string) error {
+	limitersMu.Lock()
+	l, ok := limiters[email]
+	limitersMu.Unlock()
+	if !ok {
+		return nil
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if time.Now().Before(l.until) {
+		return l.backoffError()
+	}
+	return nil
+}
+
+// backoffError reports the current backoff; l.mu must be held
+func (l *rateLimiter) backoffError() error {
+	return fmt.Errorf("%w until %s", ErrThrottled, l.until.Local().Format("15:04:05"))
+}
+
+// blocked returns an error wrapping ErrThrottled while background work must wait out a
+// backoff
+func (l *rateLimiter) blocked() error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.interactive == 0 && time.Now().Before(l.until) {
+		return l.backoffError()
+	}
+	return nil
+}
+
+// rate returns the commands a second allowed at the current slowdown, recovering a step
+// for each quiet throttleRecovery since the last throttle
+func (l *rateLimiter) rate(now time.Time) float64 {
+	if l.slowdown > 0 && now.Sub(l.throttled) >= throttleRecovery {
+		steps := int(now.Sub(l.throttled) / throttleRecovery)
+		l.slowdown = max(l.slowdown-steps, 0)
+		l.throttled = now
+	}
+	return commandRate / float64(int(1)<<l.slowdown)
+}
+
+// wait blocks until a command may be sent, or fails with ErrThrottled during a backoff
+// rather than hold the connection for minutes. The user's commands go out at once, and
+// background work pays for them by waiting longer.
+func (l *rateLimiter) wait() error {
+	l.mu.Lock()
+	now := time.Now()
+	interactive := l.interactive > 0
+	if now.Before(l.until) && !interactive {
+		err := l.backoffError()
+		l.mu.Unlock()
+		return err
+	}
+	rate := l.rate(now)
+	l.tokens = min(l.tokens+now.Sub(l.last).Seconds()*rate, commandBurst)
+	l.last = now
+	l.tokens = max(l.tokens-1, -commandBurst)
+	var delay time.Duration
+	if l.tokens < 0 && !interactive {
+		delay = time.Duration(-l.tokens / rate * float64(time.Second))
+	}
+	l.mu.Unlock()
+
+	if delay > 0 {
+		time.Sleep(delay)
+	}
+	return nil
+}
+
+// throttle halves the pace. A hard throttle, one that refused a command, also starts a
+// backoff, longer for each throttle in a row.
+func (l *rateLimiter) throttle(hard bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	now := time.Now()
+	if now.Before(l.until) {
+		return // the same throttle, reported again
+	}
+	if !hard && l.throttles > 0 && now.Sub(l.throttled) < softThrottleGap {
+		return
+	}
+	l.rate(now)
+	l.slowdown = min(l.slowdown+1, maxSlowdown)
+	l.throttled = now
+	l.throttles++
+	l.tokens = 0
+	if hard {
+		l.until = now.Add(min(throttleBackoff<<(l.slowdown-1), maxThrottleBackoff))
+	}
+}
+
+// state returns the throttles seen and the end of the current backoff
+func (l *rateLimiter) state() (throttles int, until time.Time) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.throttles, l.until
+}
+
+// maxResponsePrefix is how much of each line from the server is checked for throttling
+const maxResponsePrefix = 256
+
+// throttleWatch spots throttling responses in the data read from the server. Only
+// status responses are checked, so a message mentioning [THROTTLED] doesn't count.
+type throttleWatch struct {
+	limiter *rateLimiter
+	line    []byte // the start of the current line
+}
+
+func (w *throttleWatch) observe(p []byte) {
+	for len(p) > 0 {
+		i := bytes.IndexByte(p, '\n')
+		chunk := p
+		if i >= 0 {
+			chunk = p[:i]
+		}
+		if room := maxResponsePrefix - len(w.line); room > 0 {
+			w.line = append(w.line, chunk[:min(room, len(chunk))]...)
+		}
+		if i < 0 {
+			return
+		}
+		if throttled, hard := throttleResponse(string(w.line)); throttled {
+			w.limiter.throttle(hard)
+		}
+		w.line = w.line[:0]
+		p = p[i+1:]
+	}
+}
+
+// throttleResponse reports whether a line is a status response with a throttling text,
+// and whether it refused the command (NO, BAD or BYE) rather than warn with OK
+func throttleResponse(line string) (throttled, hard bool) {
+	fields := strings.SplitN(line, " ", 3)
+	if len(fields) < 3 {
+		return false, false
+	}
+	switch strings.ToUpper(fields[1]) {
+	case "NO", "BAD", "BYE":
+		hard = true
+	case "OK":
+	default:
+		return false, false
+	}
+	for _, marker := range throttleMarkers {
+		if strings.Contains(fields[2], marker) {
+			return true, hard
+		}
+	}
+	return false, false
+}
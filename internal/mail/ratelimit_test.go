@@ -0,0 +1,134 @@
+package mail
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRateLimiterBurst(t *testing.T) {
+	l := &rateLimiter{tokens: commandBurst, last: time.Now()}
+	start := time.Now()
+	for range commandBurst {
+		if err := l.wait(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if elapsed := time.Since(start); elapsed > 50*time.Millisecond {
+		t.Errorf("a burst of %d commands took %v, want no waiting", commandBurst, elapsed)
+	}
+	start = time.Now()
+	if err := l.wait(); err != nil {
+		t.Fatal(err)
+	}
+	if elapsed := time.Since(start); elapsed < 50*time.Millisecond {
+		t.Errorf("the command after the burst went out after %v, want about %v", elapsed, time.Second/commandRate)
+	}
+}
+
+func TestRateLimiterSoftThrottle(t *testing.T) {
+	l := &rateLimiter{tokens: commandBurst, last: time.Now()}
+	l.throttle(false)
+	l.throttle(false) // repeated on the next slow response
+	throttles, until := l.state()
+	if throttles != 1 || !until.IsZero() {
+		t.Errorf("after OK [THROTTLED]: throttles %d, backoff until %v; want 1 and no backoff", throttles, until)
+	}
+	if l.slowdown != 1 {
+		t.Errorf("slowdown = %d, want the pace halved once", l.slowdown)
+	}
+	if err := l.blocked(); err != nil {
+		t.Errorf("blocked() = %v after a soft throttle", err)
+	}
+}
+
+func TestRateLimiterHardThrottle(t *testing.T) {
+	l := &rateLimiter{tokens: commandBurst, last: time.Now()}
+	l.throttle(true)
+	_, until := l.state()
+	if d := time.Until(until); d < throttleBackoff-time.Second || d > throttleBackoff {
+		t.Errorf("backoff = %v, want %v", d, throttleBackoff)
+	}
+	if err := l.wait(); !errors.Is(err, ErrThrottled) {
+		t.Errorf("background wait() = %v, want ErrThrottled", err)
+	}
+	if err := l.blocked(); !errors.Is(err, ErrThrottled) {
+		t.Errorf("blocked() = %v, want ErrThrottled", err)
+	}
+
+	// The user's commands go out at once
+	l.interactive++
+	start := time.Now()
+	for range 3 {
+		if err := l.wait(); err != nil {
+			t.Fatalf("interactive wait() = %v during a backoff", err)
+		}
+	}
+	if elapsed := time.Since(start); elapsed > 50*time.Millisecond {
+		t.Errorf("interactive commands waited %v", elapsed)
+	}
+	if err := l.blocked(); err != nil {
+		t.Errorf("blocked() = %v for an interactive command", err)
+	}
+	l.interactive--
+
+	// Reported again during the backoff, it's the same throttle
+	l.throttle(true)
+	if throttles, _ := l.state(); throttles != 1 {
+		t.Errorf("throttles = %d, want 1", throttles)
+	}
+}
+
+func TestRateLimiterRecovers(t *testing.T) {
+	now := time.Now()
+	l := &rateLimiter{slowdown: 3, throttled: now}
+	if got, want := l.rate(now), commandRate/8.0; got != want {
+		t.Errorf("rate() = %v, want %v", got, want)
+	}
+	if got, want := l.rate(now.Add(2*throttleRecovery)), commandRate/2.0; got != want {
+		t.Errorf("rate() after two quiet spells = %v, want %v", got, want)
+	}
+}
+
+func TestThrottleResponse(t *testing.T) {
+	tests := []struct {
+		line            string
+		throttled, hard bool
+	}{
+		{"a12 OK [THROTTLED] FETCH completed", true, false},
+		{"a12 NO [THROTTLED] try again later", true, true},
+		{"* BYE Account exceeded command or bandwidth limits", true, true},
+		{"a12 BAD [THROTTLED]", true, true},
+		{"a12 OK FETCH completed", false, false},
+		{"* 3 FETCH (FLAGS (\\Seen))", false, false},
+		{"Subject: [THROTTLED] about rate limits", false, false},
+		{"a12 OK", false, false},
+	}
+	for _, tt := range tests {
+		throttled, hard := throttleResponse(tt.line)
+		if throttled != tt.throttled || hard != tt.hard {
+			t.Errorf("throttleResponse(%q) = %v, %v; want %v, %v", tt.line, throttled, hard, tt.throttled, tt.hard)
+		}
+	}
+}
+
+func TestThrottleWatch(t *testing.T) {
+	l := &rateLimiter{tokens: commandBurst, last: time.Now()}
+	w := throttleWatch{limiter: l}
+
+	// A response split across reads is put back together
+	w.observe([]byte("* 1 FETCH (FLAGS ())\r\na1 OK [THRO"))
+	w.observe([]byte("TTLED] done\r\n"))
+	if throttles, until := l.state(); throttles != 1 || !until.IsZero() {
+		t.Fatalf("after a split OK [THROTTLED]: throttles %d, until %v", throttles, until)
+	}
+
+	// Long lines, such as message data, are only checked up to maxResponsePrefix
+	w.observe([]byte("* 2 FETCH " + strings.Repeat("x", 2*maxResponsePrefix) + " [THROTTLED]\r\n"))
+	w.observe([]byte("a2 NO [THROTTLED]\r\n"))
+	throttles, until := l.state()
+	if throttles != 2 || until.IsZero() {
+		t.Errorf("after a2 NO [THROTTLED]: throttles %d, until %v; want a backoff", throttles, until)
+	}
+}
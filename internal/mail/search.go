@@ -20,50 +20,14 @@ const (
 	searchTypeGmailRaw                   // Gmail X-GM-RAW extension
 )
 
-// Search performs a search using the appropriate method for the provider.
-// For Gmail, it uses X-GM-RAW extension. For others, it uses standard IMAP SEARCH.
-func Search(creds *auth.Credentials, mailbox string, query string) ([]imap.UID, error) {
-	if creds.Provider == auth.ProviderGmail {
-		return doSearch(creds, mailbox, query, searchTypeGmailRaw)
-	}
-	return doSearch(creds, mailbox, query, searchTypeText)
-}
-
 // doSearch performs an IMAP search with the specified search type.
 func doSearch(creds *auth.Credentials, mailbox, query string, stype searchType) ([]imap.UID, error) {
-	addr := fmt.Sprintf("%s:%d", creds.IMAPHost, creds.IMAPPort)
-
-	conn, err := tls.Dial("tcp", addr, nil)
+	conn, reader, err := openRawSession(creds, mailbox)
 	if err != nil {
-		return nil, fmt.Errorf("failed to connect: %w", err)
+		return nil, err
 	}
 	defer conn.Close()
 
-	reader := bufio.NewReader(conn)
-
-	// Read greeting
-	if _, err := reader.ReadString('\n'); err != nil {
-		return nil, fmt.Errorf("failed to read greeting: %w", err)
-	}
-
-	// Login
-	loginCmd := fmt.Sprintf("a1 LOGIN %s %s\r\n", quoteString(creds.Email), quoteString(creds.Password))
-	if _, err := conn.Write([]byte(loginCmd)); err != nil {
-		return nil, fmt.Errorf("failed to send login: %w", err)
-	}
-	if err := readUntilOK(reader, "a1"); err != nil {
-		return nil, fmt.Errorf("login failed: %w", err)
-	}
-
-	// Select mailbox
-	selectCmd := fmt.Sprintf("a2 SELECT %s\r\n", quoteString(mailbox))
-	if _, err := conn.Write([]byte(selectCmd)); err != nil {
-		return nil, fmt.Errorf("failed to send select: %w", err)
-	}
-	if err := readUntilOK(reader, "a2"); err != nil {
-		return nil, fmt.Errorf("select failed: %w", err)
-	}
-
 	// Build search command based on type
 	var searchCmd string
 	switch stype {
@@ -88,6 +52,48 @@ func doSearch(creds *auth.Credentials, mailbox, query string, stype searchType)
 	return uids, nil
 }
 
+// openRawSession logs in over a plain TLS connection and selects mailbox, for commands
+// go-imap can't express (Gmail's X-GM-RAW and X-GM-LABELS). Tags a1 and a2 are used.
+func openRawSession(creds *auth.Credentials, mailbox string) (*tls.Conn, *bufio.Reader, error) {
+	conn, greeted, err := dialSecure(creds)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to connect: %w", err)
+	}
+	reader := bufio.NewReader(conn)
+
+	fail := func(err error) (*tls.Conn, *bufio.Reader, error) {
+		conn.Close()
+		return nil, nil, err
+	}
+
+	// Read greeting, unless STARTTLS already did
+	if !greeted {
+		if _, err := reader.ReadString('\n'); err != nil {
+			return fail(fmt.Errorf("failed to read greeting: %w", err))
+		}
+	}
+
+	// Login
+	loginCmd := fmt.Sprintf("a1 LOGIN %s %s\r\n", quoteString(creds.Email), quoteString(creds.Password))
+	if _, err := conn.Write([]byte(loginCmd)); err != nil {
+		return fail(fmt.Errorf("failed to send login: %w", err))
+	}
+	if err := readUntilOK(reader, "a1"); err != nil {
+		return fail(fmt.Errorf("login failed: %w", err))
+	}
+
+	// Select mailbox
+	selectCmd := fmt.Sprintf("a2 SELECT %s\r\n", quoteString(mailbox))
+	if _, err := conn.Write([]byte(selectCmd)); err != nil {
+		return fail(fmt.Errorf("failed to send select: %w", err))
+	}
+	if err := readUntilOK(reader, "a2"); err != nil {
+		return fail(fmt.Errorf("select failed: %w", err))
+	}
+
+	return conn, reader, nil
+}
+
 func quoteString(s string) string {
 	// Escape backslashes and quotes
 	s = strings.ReplaceAll(s, "\\", "\\\\")
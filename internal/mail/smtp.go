@@ -3,19 +3,70 @@ package mail
 import (
 	"bytes"
 	"crypto/rand"
+	"crypto/tls"
 	"encoding/base64"
 	"fmt"
 	"io"
 	"mime"
 	"mime/quotedprintable"
+	"net"
 	"net/smtp"
 	"os"
+	"os/exec"
 	"path/filepath"
+	"strconv"
 	"strings"
 
 	"maily/internal/auth"
 )
 
+// MaxAttachmentSize is the Gmail attachment size limit (25MB), used for unknown providers
+const MaxAttachmentSize = 25 * 1024 * 1024
+
+// AttachmentWarnRatio is the share of the attachment limit at which compose starts warning
+const AttachmentWarnRatio = 0.8
+
+// smtpsPort takes TLS from the first byte rather than upgrading with STARTTLS
+const smtpsPort = 465
+
+// providerAttachmentLimits are the total attachment sizes each provider accepts
+var providerAttachmentLimits = map[string]int64{
+	auth.ProviderGmail: 25 * 1024 * 1024,
+	auth.ProviderYahoo: 25 * 1024 * 1024,
+	auth.ProviderQQ:    50 * 1024 * 1024,
+}
+
+// AttachmentLimit returns the total attachment size an account can send: its
+// max_attachment_mb setting, else its provider's limit, else MaxAttachmentSize
+func AttachmentLimit(account *auth.Account) int64 {
+	if account == nil {
+		return MaxAttachmentSize
+	}
+	if account.MaxAttachmentMB > 0 {
+		return int64(account.MaxAttachmentMB) * 1024 * 1024
+	}
+	provider := account.Provider
+	if provider == "" {
+		provider = account.Credentials.Provider
+	}
+	if limit, ok := providerAttachmentLimits[provider]; ok {
+		return limit
+	}
+	return MaxAttachmentSize
+}
+
+// AttachmentAdvice suggests what to do with files too large to attach
+func AttachmentAdvice(provider string) string {
+	switch provider {
+	case auth.ProviderGmail:
+		return "upload large files to Google Drive and paste a share link instead"
+	case auth.ProviderQQ:
+		return "send large files as a QQ Mail file transfer link instead"
+	default:
+		return "upload large files to a file sharing service and paste a link instead"
+	}
+}
+
 // AttachmentFile represents an email attachment
 type AttachmentFile struct {
 	Path        string
@@ -115,11 +166,84 @@ func NewSMTPClient(creds *auth.Credentials) *SMTPClient {
 	return &SMTPClient{creds: creds}
 }
 
-func (c *SMTPClient) Send(to, subject, body string) error {
-	addr := fmt.Sprintf("%s:%d", c.creds.SMTPHost, c.creds.SMTPPort)
+// sendMail delivers a message over SMTP, or hands it to the sendmail command (msmtp,
+// postfix and the like) for local accounts without an SMTP server
+func (c *SMTPClient) sendMail(to string, msg []byte) error {
+	if c.creds.SMTPHost == "" && c.creds.Provider == auth.ProviderMaildir {
+		cmd := exec.Command("sendmail", append([]string{"-i", "-f", c.creds.Email, "--"}, parseRecipients(to)...)...)
+		cmd.Stdin = bytes.NewReader(msg)
+		if out, err := cmd.CombinedOutput(); err != nil {
+			return fmt.Errorf("sendmail: %w: %s", err, bytes.TrimSpace(out))
+		}
+		return nil
+	}
+	return c.submit(parseRecipients(to), msg)
+}
+
+// submit hands a message to the SMTP server, like smtp.SendMail but connecting through
+// the account's proxy, with TLS from the start on port 465, and trusting the server as
+// the account's tls settings say, as for IMAP
+func (c *SMTPClient) submit(recipients []string, msg []byte) error {
+	host := c.creds.SMTPHost
+	cfg, err := tlsConfig(c.creds, host)
+	if err != nil {
+		return err
+	}
+	dialer, err := dialerFor(c.creds)
+	if err != nil {
+		return err
+	}
+	conn, err := dialer.Dial("tcp", net.JoinHostPort(host, strconv.Itoa(c.creds.SMTPPort)))
+	if err != nil {
+		return fmt.Errorf("failed to connect to SMTP server: %w", err)
+	}
+	if c.creds.SMTPPort == smtpsPort {
+		secure := tls.Client(conn, cfg)
+		if err := secure.Handshake(); err != nil {
+			conn.Close()
+			return tlsError(host, "SMTP", err)
+		}
+		conn = secure
+	}
+	client, err := smtp.NewClient(conn, host)
+	if err != nil {
+		conn.Close()
+		return err
+	}
+	defer client.Close()
 
-	auth := smtp.PlainAuth("", c.creds.Email, c.creds.Password, c.creds.SMTPHost)
+	if ok, _ := client.Extension("STARTTLS"); ok {
+		if err := client.StartTLS(cfg); err != nil {
+			return tlsError(host, "SMTP", err)
+		}
+	}
+	if ok, _ := client.Extension("AUTH"); ok {
+		if err := client.Auth(smtp.PlainAuth("", c.creds.Email, c.creds.Password, host)); err != nil {
+			return err
+		}
+	}
+	if err := client.Mail(c.creds.Email); err != nil {
+		return err
+	}
+	for _, rcpt := range recipients {
+		if err := client.Rcpt(rcpt); err != nil {
+			return err
+		}
+	}
+	w, err := client.Data()
+	if err != nil {
+		return err
+	}
+	if _, err := w.Write(msg); err != nil {
+		return err
+	}
+	if err := w.Close(); err != nil {
+		return err
+	}
+	return client.Quit()
+}
 
+func (c *SMTPClient) Send(to, subject, body string) error {
 	// Sanitize headers to prevent CRLF injection
 	to = sanitizeHeader(to)
 	subject = sanitizeHeader(subject)
@@ -132,14 +256,10 @@ func (c *SMTPClient) Send(to, subject, body string) error {
 		"\r\n"+
 		"%s", c.creds.Email, to, subject, body)
 
-	return smtp.SendMail(addr, auth, c.creds.Email, parseRecipients(to), []byte(msg))
+	return c.sendMail(to, []byte(msg))
 }
 
 func (c *SMTPClient) Reply(to, subject, body, inReplyTo, references string) error {
-	addr := fmt.Sprintf("%s:%d", c.creds.SMTPHost, c.creds.SMTPPort)
-
-	auth := smtp.PlainAuth("", c.creds.Email, c.creds.Password, c.creds.SMTPHost)
-
 	// Sanitize headers to prevent CRLF injection
 	to = sanitizeHeader(to)
 	subject = sanitizeHeader(subject)
@@ -162,7 +282,7 @@ func (c *SMTPClient) Reply(to, subject, body, inReplyTo, references string) erro
 		"\r\n"+
 		"%s", c.creds.Email, to, subject, inReplyTo, references, body)
 
-	return smtp.SendMail(addr, auth, c.creds.Email, parseRecipients(to), []byte(msg))
+	return c.sendMail(to, []byte(msg))
 }
 
 // SendWithAttachments sends an email with attachments
@@ -171,9 +291,6 @@ func (c *SMTPClient) SendWithAttachments(to, subject, body string, attachments [
 		return c.Send(to, subject, body)
 	}
 
-	addr := fmt.Sprintf("%s:%d", c.creds.SMTPHost, c.creds.SMTPPort)
-	auth := smtp.PlainAuth("", c.creds.Email, c.creds.Password, c.creds.SMTPHost)
-
 	// Sanitize headers
 	to = sanitizeHeader(to)
 	subject = sanitizeHeader(subject)
@@ -183,7 +300,7 @@ func (c *SMTPClient) SendWithAttachments(to, subject, body string, attachments [
 		return fmt.Errorf("failed to build message: %w", err)
 	}
 
-	return smtp.SendMail(addr, auth, c.creds.Email, parseRecipients(to), msg)
+	return c.sendMail(to, msg)
 }
 
 // ReplyWithAttachments sends a reply email with attachments
@@ -192,9 +309,6 @@ func (c *SMTPClient) ReplyWithAttachments(to, subject, body, inReplyTo, referenc
 		return c.Reply(to, subject, body, inReplyTo, references)
 	}
 
-	addr := fmt.Sprintf("%s:%d", c.creds.SMTPHost, c.creds.SMTPPort)
-	auth := smtp.PlainAuth("", c.creds.Email, c.creds.Password, c.creds.SMTPHost)
-
 	// Sanitize headers
 	to = sanitizeHeader(to)
 	subject = sanitizeHeader(subject)
@@ -212,7 +326,58 @@ func (c *SMTPClient) ReplyWithAttachments(to, subject, body, inReplyTo, referenc
 		return fmt.Errorf("failed to build message: %w", err)
 	}
 
-	return smtp.SendMail(addr, auth, c.creds.Email, parseRecipients(to), msg)
+	return c.sendMail(to, msg)
+}
+
+// SendInvitation sends a calendar invitation: the body for mail clients that don't
+// understand invitations, and the iCalendar METHOD:REQUEST for the ones that do
+func (c *SMTPClient) SendInvitation(to, subject, body string, invitation []byte) error {
+	// Sanitize headers
+	to = sanitizeHeader(to)
+	subject = sanitizeHeader(subject)
+
+	msg := buildInvitationMessage(c.creds.Email, to, subject, body, invitation)
+	return c.sendMail(to, msg)
+}
+
+// buildInvitationMessage constructs a multipart/alternative message of a text body and
+// an invitation, the shape calendar-aware clients look for
+func buildInvitationMessage(from, to, subject, body string, invitation []byte) []byte {
+	var buf bytes.Buffer
+
+	boundary := fmt.Sprintf("----=_Part_%s", randomBoundary())
+
+	buf.WriteString(fmt.Sprintf("From: %s\r\n", from))
+	buf.WriteString(fmt.Sprintf("To: %s\r\n", to))
+	buf.WriteString(fmt.Sprintf("Subject: %s\r\n", subject))
+	buf.WriteString("MIME-Version: 1.0\r\n")
+	buf.WriteString(fmt.Sprintf("Content-Type: multipart/alternative; boundary=\"%s\"\r\n", boundary))
+	buf.WriteString("\r\n")
+
+	buf.WriteString(fmt.Sprintf("--%s\r\n", boundary))
+	buf.WriteString("Content-Type: text/plain; charset=\"utf-8\"\r\n")
+	buf.WriteString("Content-Transfer-Encoding: quoted-printable\r\n")
+	buf.WriteString("\r\n")
+	qpWriter := quotedprintable.NewWriter(&buf)
+	qpWriter.Write([]byte(body))
+	qpWriter.Close()
+	buf.WriteString("\r\n")
+
+	buf.WriteString(fmt.Sprintf("--%s\r\n", boundary))
+	buf.WriteString("Content-Type: text/calendar; charset=\"utf-8\"; method=REQUEST\r\n")
+	buf.WriteString("Content-Transfer-Encoding: base64\r\n")
+	buf.WriteString("\r\n")
+	lineWriter := &base64LineWriter{w: &buf, lineLen: 76}
+	encoder := base64.NewEncoder(base64.StdEncoding, lineWriter)
+	encoder.Write(invitation)
+	encoder.Close()
+	if lineWriter.col > 0 {
+		buf.WriteString("\r\n")
+	}
+
+	buf.WriteString(fmt.Sprintf("--%s--\r\n", boundary))
+
+	return buf.Bytes()
 }
 
 // buildMultipartMessage constructs a MIME multipart message with attachments
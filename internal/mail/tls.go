@@ -0,0 +1,200 @@
+package mail
+
+import (
+	"bufio"
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"maily/internal/auth"
+)
+
+// dialTimeout bounds connecting to the IMAP server and, for STARTTLS, upgrading the
+// connection
+const dialTimeout = 30 * time.Second
+
+// startTLSGreeting stands in for the greeting go-imap expects, which the server sent
+// before the connection was upgraded
+const startTLSGreeting = "* OK maily started TLS\r\n"
+
+// ErrTLSVerify is wrapped by errors for servers whose certificate couldn't be verified
+var ErrTLSVerify = errors.New("certificate verification failed")
+
+// tlsVersions maps min_version settings to TLS versions
+var tlsVersions = map[string]uint16{
+	"1.2": tls.VersionTLS12,
+	"1.3": tls.VersionTLS13,
+}
+
+// tlsConfig builds the TLS configuration for one of the account's servers, IMAP or
+// SMTP, from its tls settings
+func tlsConfig(creds *auth.Credentials, host string) (*tls.Config, error) {
+	cfg := &tls.Config{ServerName: host}
+	opts := creds.TLS
+	if opts == nil {
+		return cfg, nil
+	}
+
+	if opts.MinVersion != "" {
+		version, ok := tlsVersions[opts.MinVersion]
+		if !ok {
+			return nil, fmt.Errorf("invalid tls min_version %q: use 1.2 or 1.3", opts.MinVersion)
+		}
+		cfg.MinVersion = version
+	}
+
+	if opts.CAFile != "" {
+		path, err := expandHome(opts.CAFile)
+		if err != nil {
+			return nil, err
+		}
+		pem, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("reading tls ca_file: %w", err)
+		}
+		pool, err := x509.SystemCertPool()
+		if err != nil {
+			pool = x509.NewCertPool()
+		}
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("tls ca_file %s holds no PEM certificates", opts.CAFile)
+		}
+		cfg.RootCAs = pool
+	}
+
+	if len(opts.PinSHA256) > 0 {
+		pins := make(map[string]bool, len(opts.PinSHA256))
+		for _, pin := range opts.PinSHA256 {
+			pins[strings.TrimPrefix(pin, "sha256/")] = true
+		}
+		cfg.VerifyConnection = func(state tls.ConnectionState) error {
+			for _, cert := range state.PeerCertificates {
+				if pins[certPin(cert)] {
+					return nil
+				}
+			}
+			if len(state.PeerCertificates) == 0 {
+				return fmt.Errorf("%w: %s sent no certificate", ErrTLSVerify, host)
+			}
+			return fmt.Errorf("%w: %s's certificate doesn't match the pinned keys; it presented sha256/%s", ErrTLSVerify, host, certPin(state.PeerCertificates[0]))
+		}
+	}
+	return cfg, nil
+}
+
+// certPin returns the base64 SHA-256 of a certificate's public key, the form pins are
+// written in (as with HPKP and curl's --pinnedpubkey)
+func certPin(cert *x509.Certificate) string {
+	sum := sha256.Sum256(cert.RawSubjectPublicKeyInfo)
+	return base64.StdEncoding.EncodeToString(sum[:])
+}
+
+// dialSecure connects to the account's IMAP server over TLS, or over a plain connection
+// upgraded with STARTTLS when the account asks for it, through its proxy if any. After
+// STARTTLS the server has already greeted, so greeted is true.
+func dialSecure(creds *auth.Credentials) (conn *tls.Conn, greeted bool, err error) {
+	cfg, err := tlsConfig(creds, creds.IMAPHost)
+	if err != nil {
+		return nil, false, err
+	}
+	return dialWith(creds, cfg)
+}
+
+// dialWith connects to the account's IMAP server as dialSecure does, with the given
+// TLS configuration
+func dialWith(creds *auth.Credentials, cfg *tls.Config) (conn *tls.Conn, greeted bool, err error) {
+	dialer, err := dialerFor(creds)
+	if err != nil {
+		return nil, false, err
+	}
+	addr := net.JoinHostPort(creds.IMAPHost, strconv.Itoa(creds.IMAPPort))
+	plain, err := dialer.Dial("tcp", addr)
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to connect to IMAP server: %w", err)
+	}
+	plain.SetDeadline(time.Now().Add(dialTimeout))
+
+	startTLS := creds.TLS != nil && creds.TLS.StartTLS
+	if startTLS {
+		br := bufio.NewReader(plain)
+		greeting, err := br.ReadString('\n')
+		if err != nil {
+			plain.Close()
+			return nil, false, fmt.Errorf("failed to read greeting: %w", err)
+		}
+		if !strings.HasPrefix(greeting, "* OK") {
+			plain.Close()
+			return nil, false, fmt.Errorf("unexpected greeting: %s", strings.TrimSpace(greeting))
+		}
+		if _, err := rawCommand(plain, br, "s1", "STARTTLS"); err != nil {
+			plain.Close()
+			return nil, false, fmt.Errorf("STARTTLS refused: %w", err)
+		}
+		// Bytes that arrived ahead of the handshake weren't protected by TLS but would be
+		// read as if they were, so they could only have been injected
+		if br.Buffered() > 0 {
+			plain.Close()
+			return nil, false, fmt.Errorf("%w: %s sent data before the TLS handshake", ErrTLSVerify, creds.IMAPHost)
+		}
+	}
+
+	conn = tls.Client(plain, cfg)
+	if err := conn.Handshake(); err != nil {
+		plain.Close()
+		return nil, false, tlsError(creds.IMAPHost, "IMAP", err)
+	}
+	plain.SetDeadline(time.Time{})
+	return conn, startTLS, nil
+}
+
+// tlsError explains a failed handshake with the IMAP or SMTP server, pointing at the
+// setting that would fix it
+func tlsError(host, server string, err error) error {
+	var unknownCA x509.UnknownAuthorityError
+	var hostname x509.HostnameError
+	var invalid x509.CertificateInvalidError
+	var verify *tls.CertificateVerificationError
+	switch {
+	case errors.Is(err, ErrTLSVerify):
+		return err
+	case errors.As(err, &unknownCA):
+		return fmt.Errorf("%w: %s's certificate is signed by an unknown authority; for a corporate CA, set tls.ca_file for the account in accounts.yml", ErrTLSVerify, host)
+	case errors.As(err, &hostname):
+		return fmt.Errorf("%w: %s presented a certificate for %s", ErrTLSVerify, host, strings.Join(hostname.Certificate.DNSNames, ", "))
+	case errors.As(err, &invalid):
+		return fmt.Errorf("%w: %s: %v", ErrTLSVerify, host, invalid)
+	case errors.As(err, &verify):
+		return fmt.Errorf("%w: %s: %v", ErrTLSVerify, host, verify.Err)
+	}
+	return fmt.Errorf("failed to connect to %s server: %w", server, err)
+}
+
+// ServerPins connects to the account's IMAP server and returns the pins of the
+// certificates it presents, server first, for the account's tls pin_sha256. The
+// account's pins aren't checked, so this works when the server's key has changed and
+// the pins need updating; the certificate is still verified against the trusted CAs.
+func ServerPins(creds *auth.Credentials) ([]string, error) {
+	cfg, err := tlsConfig(creds, creds.IMAPHost)
+	if err != nil {
+		return nil, err
+	}
+	cfg.VerifyConnection = nil
+	conn, _, err := dialWith(creds, cfg)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+	var pins []string
+	for _, cert := range conn.ConnectionState().PeerCertificates {
+		pins = append(pins, "sha256/"+certPin(cert))
+	}
+	return pins, nil
+}
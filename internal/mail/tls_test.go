@@ -0,0 +1,254 @@
+package mail
+
+import (
+	"bufio"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"errors"
+	"math/big"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"maily/internal/auth"
+)
+
+// testCert makes a self-signed certificate for 127.0.0.1 and writes it as a PEM file
+// usable as tls.ca_file
+func testCert(t *testing.T) (tls.Certificate, string) {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "maily test"},
+		IPAddresses:           []net.IP{net.ParseIP("127.0.0.1")},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	caFile := filepath.Join(t.TempDir(), "ca.pem")
+	if err := os.WriteFile(caFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0600); err != nil {
+		t.Fatal(err)
+	}
+	leaf, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return tls.Certificate{Certificate: [][]byte{der}, PrivateKey: key, Leaf: leaf}, caFile
+}
+
+// fakeSMTP accepts one message over STARTTLS with the given certificate and returns
+// its port
+func fakeSMTP(t *testing.T, cert tls.Certificate) int {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { ln.Close() })
+	go func() {
+		conn, err := ln.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		var c net.Conn = conn
+		r := bufio.NewReader(c)
+		write := func(s string) { c.Write([]byte(s + "\r\n")) }
+		write("220 localhost ESMTP")
+		tlsOn := false
+		for {
+			line, err := r.ReadString('\n')
+			if err != nil {
+				return
+			}
+			verb := strings.ToUpper(strings.Fields(line + " x")[0])
+			switch verb {
+			case "EHLO":
+				if tlsOn {
+					write("250 localhost")
+				} else {
+					write("250-localhost")
+					write("250 STARTTLS")
+				}
+			case "STARTTLS":
+				write("220 ready")
+				secure := tls.Server(conn, &tls.Config{Certificates: []tls.Certificate{cert}})
+				if secure.Handshake() != nil {
+					return
+				}
+				c, r, tlsOn = secure, bufio.NewReader(secure), true
+			case "DATA":
+				write("354 go ahead")
+				for {
+					l, err := r.ReadString('\n')
+					if err != nil || l == ".\r\n" {
+						break
+					}
+				}
+				write("250 queued")
+			case "QUIT":
+				write("221 bye")
+				return
+			default:
+				write("250 ok")
+			}
+		}
+	}()
+	return ln.Addr().(*net.TCPAddr).Port
+}
+
+func TestSubmitUsesAccountTLS(t *testing.T) {
+	cert, caFile := testCert(t)
+	pin := "sha256/" + certPin(cert.Leaf)
+
+	tests := []struct {
+		name    string
+		tls     *auth.TLSOptions
+		wantErr bool
+	}{
+		{"unknown CA", nil, true},
+		{"ca_file", &auth.TLSOptions{CAFile: caFile}, false},
+		{"matching pin", &auth.TLSOptions{CAFile: caFile, PinSHA256: []string{pin}}, false},
+		{"other pin", &auth.TLSOptions{CAFile: caFile, PinSHA256: []string{"sha256/AAAA"}}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			port := fakeSMTP(t, cert)
+			client := NewSMTPClient(&auth.Credentials{
+				Email:    "me@example.com",
+				SMTPHost: "127.0.0.1",
+				SMTPPort: port,
+				Proxy:    proxyDirect,
+				TLS:      tt.tls,
+			})
+			err := client.submit([]string{"ana@example.com"}, []byte("Subject: hi\r\n\r\nhello\r\n"))
+			if tt.wantErr {
+				if !errors.Is(err, ErrTLSVerify) {
+					t.Errorf("submit() error = %v, want ErrTLSVerify", err)
+				}
+			} else if err != nil {
+				t.Errorf("submit() error = %v", err)
+			}
+		})
+	}
+}
+
+func TestTLSConfig(t *testing.T) {
+	cfg, err := tlsConfig(&auth.Credentials{TLS: &auth.TLSOptions{MinVersion: "1.3"}}, "smtp.example.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.ServerName != "smtp.example.com" || cfg.MinVersion != tls.VersionTLS13 {
+		t.Errorf("tlsConfig() = ServerName %q, MinVersion %x", cfg.ServerName, cfg.MinVersion)
+	}
+	if _, err := tlsConfig(&auth.Credentials{TLS: &auth.TLSOptions{MinVersion: "1.1"}}, "smtp.example.com"); err == nil {
+		t.Error("tlsConfig() accepted min_version 1.1")
+	}
+	if _, err := tlsConfig(&auth.Credentials{TLS: &auth.TLSOptions{CAFile: "/nonexistent.pem"}}, "smtp.example.com"); err == nil {
+		t.Error("tlsConfig() accepted a missing ca_file")
+	}
+}
+
+// fakeIMAP greets, answers STARTTLS (followed by extra, if any) and completes the TLS
+// handshake with the given certificate, then returns its port
+func fakeIMAP(t *testing.T, cert tls.Certificate, extra string) int {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { ln.Close() })
+	go func() {
+		conn, err := ln.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		r := bufio.NewReader(conn)
+		conn.Write([]byte("* OK IMAP4rev1 ready\r\n"))
+		line, err := r.ReadString('\n')
+		if err != nil {
+			return
+		}
+		tag, _, _ := strings.Cut(line, " ")
+		conn.Write([]byte(tag + " OK begin TLS\r\n" + extra))
+		secure := tls.Server(conn, &tls.Config{Certificates: []tls.Certificate{cert}})
+		if secure.Handshake() != nil {
+			return
+		}
+		secure.Read(make([]byte, 1))
+	}()
+	return ln.Addr().(*net.TCPAddr).Port
+}
+
+func TestStartTLSRefusesInjectedData(t *testing.T) {
+	cert, caFile := testCert(t)
+	for _, extra := range []string{"", "* BYE injected\r\n"} {
+		port := fakeIMAP(t, cert, extra)
+		conn, greeted, err := dialSecure(&auth.Credentials{
+			IMAPHost: "127.0.0.1",
+			IMAPPort: port,
+			Proxy:    proxyDirect,
+			TLS:      &auth.TLSOptions{CAFile: caFile, StartTLS: true},
+		})
+		if extra == "" {
+			if err != nil || !greeted {
+				t.Errorf("dialSecure() = greeted %v, %v; want a greeted connection", greeted, err)
+			}
+		} else if !errors.Is(err, ErrTLSVerify) {
+			t.Errorf("dialSecure() with data before the handshake: error = %v, want ErrTLSVerify", err)
+		}
+		if conn != nil {
+			conn.Close()
+		}
+	}
+}
+
+func TestServerPinsIgnoresStalePins(t *testing.T) {
+	cert, caFile := testCert(t)
+	creds := &auth.Credentials{
+		IMAPHost: "127.0.0.1",
+		Proxy:    proxyDirect,
+		TLS:      &auth.TLSOptions{CAFile: caFile, StartTLS: true, PinSHA256: []string{"sha256/AAAA"}},
+	}
+
+	creds.IMAPPort = fakeIMAP(t, cert, "")
+	if _, _, err := dialSecure(creds); !errors.Is(err, ErrTLSVerify) {
+		t.Fatalf("dialSecure() with a stale pin: error = %v, want ErrTLSVerify", err)
+	}
+
+	creds.IMAPPort = fakeIMAP(t, cert, "")
+	pins, err := ServerPins(creds)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "sha256/" + certPin(cert.Leaf); len(pins) != 1 || pins[0] != want {
+		t.Errorf("ServerPins() = %v, want [%s]", pins, want)
+	}
+
+	// The certificate itself is still verified
+	creds.TLS.CAFile = ""
+	creds.IMAPPort = fakeIMAP(t, cert, "")
+	if _, err := ServerPins(creds); !errors.Is(err, ErrTLSVerify) {
+		t.Errorf("ServerPins() with an untrusted certificate: error = %v, want ErrTLSVerify", err)
+	}
+}
@@ -0,0 +1,63 @@
+package mail
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// Unsubscribe holds the targets from RFC 2369 List-Unsubscribe and RFC 8058 List-Unsubscribe-Post headers
+type Unsubscribe struct {
+	URL      string // http(s) link
+	Mailto   string // mailto: link
+	OneClick bool   // URL accepts an RFC 8058 one-click POST
+}
+
+// ParseUnsubscribe parses List-Unsubscribe header values; ok is false when there's nothing usable
+func ParseUnsubscribe(listUnsubscribe, listUnsubscribePost string) (u Unsubscribe, ok bool) {
+	// <mailto:leave@example.com?subject=unsubscribe>, <https://example.com/u/123>
+	for _, part := range strings.Split(listUnsubscribe, ",") {
+		part = strings.TrimSpace(part)
+		if !strings.HasPrefix(part, "<") || !strings.HasSuffix(part, ">") {
+			continue
+		}
+		target := strings.TrimSpace(part[1 : len(part)-1])
+		lower := strings.ToLower(target)
+		switch {
+		case u.URL == "" && (strings.HasPrefix(lower, "https://") || strings.HasPrefix(lower, "http://")):
+			u.URL = target
+		case u.Mailto == "" && IsMailto(target):
+			u.Mailto = target
+		}
+	}
+
+	// One-click requires an https URI (RFC 8058 section 3.1)
+	u.OneClick = strings.HasPrefix(strings.ToLower(u.URL), "https://") &&
+		strings.EqualFold(strings.ReplaceAll(strings.TrimSpace(listUnsubscribePost), " ", ""), "List-Unsubscribe=One-Click")
+
+	return u, u.URL != "" || u.Mailto != ""
+}
+
+var unsubscribeClient = &http.Client{Timeout: 30 * time.Second}
+
+// OneClickUnsubscribe performs the RFC 8058 one-click unsubscribe POST
+func OneClickUnsubscribe(ctx context.Context, target string) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, target, strings.NewReader("List-Unsubscribe=One-Click"))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	resp, err := unsubscribeClient.Do(req)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("unsubscribe request failed: %s", resp.Status)
+	}
+	return nil
+}
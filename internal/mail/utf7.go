@@ -0,0 +1,85 @@
+package mail
+
+import (
+	"encoding/base64"
+	"strings"
+	"unicode/utf16"
+	"unicode/utf8"
+)
+
+// Mailbox names and Gmail labels travel in IMAP's modified UTF-7 (RFC 3501 5.1.3):
+// printable ASCII is literal, "&" is written "&-", and other text is UTF-16 in base64
+// with "," for "/" between "&" and "-".
+var utf7Encoding = base64.NewEncoding("ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz0123456789+,").WithPadding(base64.NoPadding)
+
+func encodeUTF7(s string) string {
+	var b strings.Builder
+	var pending []rune
+	flush := func() {
+		if len(pending) == 0 {
+			return
+		}
+		units := utf16.Encode(pending)
+		buf := make([]byte, len(units)*2)
+		for i, u := range units {
+			buf[i*2], buf[i*2+1] = byte(u>>8), byte(u)
+		}
+		b.WriteByte('&')
+		b.WriteString(utf7Encoding.EncodeToString(buf))
+		b.WriteByte('-')
+		pending = pending[:0]
+	}
+	for _, r := range s {
+		if r >= 0x20 && r <= 0x7e {
+			flush()
+			if r == '&' {
+				b.WriteString("&-")
+			} else {
+				b.WriteRune(r)
+			}
+			continue
+		}
+		pending = append(pending, r)
+	}
+	flush()
+	return b.String()
+}
+
+// decodeUTF7 decodes a modified UTF-7 name, returning it unchanged if it's malformed
+func decodeUTF7(s string) string {
+	if !strings.Contains(s, "&") {
+		return s
+	}
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] != '&' {
+			b.WriteByte(s[i])
+			continue
+		}
+		end := strings.IndexByte(s[i:], '-')
+		if end < 0 {
+			return s
+		}
+		encoded := s[i+1 : i+end]
+		i += end
+		if encoded == "" {
+			b.WriteByte('&')
+			continue
+		}
+		buf, err := utf7Encoding.DecodeString(encoded)
+		if err != nil || len(buf)%2 != 0 {
+			return s
+		}
+		units := make([]uint16, len(buf)/2)
+		for j := range units {
+			units[j] = uint16(buf[j*2])<<8 | uint16(buf[j*2+1])
+		}
+		for _, r := range utf16.Decode(units) {
+			if r == utf8.RuneError {
+				return s
+			}
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
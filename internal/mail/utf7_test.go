@@ -0,0 +1,40 @@
+package mail
+
+import "testing"
+
+func TestUTF7(t *testing.T) {
+	tests := []struct {
+		decoded, encoded string
+	}{
+		{"INBOX", "INBOX"},
+		{"Tom & Jerry", "Tom &- Jerry"},
+		{"台北", "&U,BTFw-"},
+		{"~peter/mail/台北/日本語", "~peter/mail/&U,BTFw-/&ZeVnLIqe-"},
+		{"Reçus", "Re&AOc-us"},
+		{"Входящие", "&BBIERQQ+BDQETwRJBDgENQ-"},
+		{"🎉 Party", "&2DzfiQ- Party"},
+		{"", ""},
+	}
+	for _, tt := range tests {
+		if got := encodeUTF7(tt.decoded); got != tt.encoded {
+			t.Errorf("encodeUTF7(%q) = %q, want %q", tt.decoded, got, tt.encoded)
+		}
+		if got := decodeUTF7(tt.encoded); got != tt.decoded {
+			t.Errorf("decodeUTF7(%q) = %q, want %q", tt.encoded, got, tt.decoded)
+		}
+	}
+}
+
+func TestDecodeUTF7Malformed(t *testing.T) {
+	for _, s := range []string{
+		"&U,BTFw",   // no closing dash
+		"&U,BTF-",   // odd number of bytes
+		"&!!!-",     // not base64
+		"&2Dw-",     // a lone surrogate
+		"ok &ZeVn-", // three bytes
+	} {
+		if got := decodeUTF7(s); got != s {
+			t.Errorf("decodeUTF7(%q) = %q, want it unchanged", s, got)
+		}
+	}
+}
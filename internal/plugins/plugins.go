@@ -0,0 +1,232 @@
+package plugins
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Timeouts for the two calls maily makes to a plugin
+const (
+	describeTimeout = 5 * time.Second
+	runTimeout      = 60 * time.Second
+)
+
+// Plugin is an executable in the plugins directory. Run with "describe" it prints its
+// Manifest as JSON; run with "run <action>" it reads a Request from stdin and prints
+// a Response.
+type Plugin struct {
+	Name string `json:"name"` // file name, without extension
+	Path string `json:"path"`
+	Manifest
+}
+
+// Manifest describes what a plugin adds to maily
+type Manifest struct {
+	Description string   `json:"description,omitempty"`
+	Actions     []Action `json:"actions"`
+}
+
+// Action is a list action a plugin adds to the command palette, optionally bound to a
+// key maily doesn't use
+type Action struct {
+	Name  string `json:"name"`
+	Title string `json:"title"`
+	Key   string `json:"key,omitempty"` // e.g. "ctrl+t" or "K"
+}
+
+// Request is what an action receives on stdin: the selected emails, or the current one
+type Request struct {
+	Action  string  `json:"action"`
+	Account string  `json:"account"`
+	Mailbox string  `json:"mailbox"`
+	Emails  []Email `json:"emails"`
+}
+
+// Email is an email passed to a plugin
+type Email struct {
+	UID       uint32    `json:"uid"`
+	MessageID string    `json:"message_id,omitempty"`
+	From      string    `json:"from"`
+	To        string    `json:"to,omitempty"`
+	Cc        string    `json:"cc,omitempty"`
+	Subject   string    `json:"subject"`
+	Date      time.Time `json:"date"`
+	Snippet   string    `json:"snippet,omitempty"`
+	BodyHTML  string    `json:"body_html,omitempty"` // only when the body was loaded
+	Labels    []string  `json:"labels,omitempty"`
+}
+
+// Response is what an action prints on stdout; the message is shown in the status bar.
+// Printing nothing is fine too.
+type Response struct {
+	Message string `json:"message,omitempty"`
+	Error   string `json:"error,omitempty"`
+}
+
+// Dir returns the plugins directory, ~/.config/maily/plugins
+func Dir() (string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(home, ".config", "maily", "plugins"), nil
+}
+
+// Command returns the command palette name of one of the plugin's actions
+func (p Plugin) Command(a Action) string {
+	return p.Name + ":" + a.Name
+}
+
+// Load describes every plugin in the plugins directory. Plugins that fail to describe
+// themselves are left out, with their errors returned alongside the rest.
+func Load() ([]Plugin, error) {
+	dir, err := Dir()
+	if err != nil {
+		return nil, err
+	}
+	entries, err := os.ReadDir(dir)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var plugins []Plugin
+	var errs []error
+	for _, entry := range entries {
+		if entry.IsDir() || strings.HasPrefix(entry.Name(), ".") {
+			continue
+		}
+		p, err := Describe(filepath.Join(dir, entry.Name()))
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		plugins = append(plugins, p)
+	}
+	sort.Slice(plugins, func(i, j int) bool { return plugins[i].Name < plugins[j].Name })
+	return plugins, errors.Join(errs...)
+}
+
+// Describe runs a plugin with "describe" and reads its manifest
+func Describe(path string) (Plugin, error) {
+	name := strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+	p := Plugin{Name: name, Path: path}
+
+	ctx, cancel := context.WithTimeout(context.Background(), describeTimeout)
+	defer cancel()
+	out, err := call(ctx, path, nil, "describe")
+	if err != nil {
+		return p, fmt.Errorf("plugin %s: %w", name, err)
+	}
+	if err := json.Unmarshal(out, &p.Manifest); err != nil {
+		return p, fmt.Errorf("plugin %s: invalid manifest: %w", name, err)
+	}
+	if len(p.Actions) == 0 {
+		return p, fmt.Errorf("plugin %s: no actions", name)
+	}
+	for _, a := range p.Actions {
+		if a.Name == "" || strings.ContainsAny(a.Name, " :") {
+			return p, fmt.Errorf("plugin %s: invalid action name %q", name, a.Name)
+		}
+	}
+	return p, nil
+}
+
+// Run runs one of the plugin's actions
+func (p Plugin) Run(action string, req Request) (Response, error) {
+	req.Action = action
+	body, err := json.Marshal(req)
+	if err != nil {
+		return Response{}, err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), runTimeout)
+	defer cancel()
+	out, err := call(ctx, p.Path, bytes.NewReader(body), "run", action)
+	if err != nil {
+		return Response{}, fmt.Errorf("%s: %w", p.Name, err)
+	}
+
+	var resp Response
+	if out = bytes.TrimSpace(out); len(out) > 0 {
+		if err := json.Unmarshal(out, &resp); err != nil {
+			return Response{}, fmt.Errorf("%s: invalid response: %w", p.Name, err)
+		}
+	}
+	if resp.Error != "" {
+		return resp, fmt.Errorf("%s: %s", p.Name, resp.Error)
+	}
+	return resp, nil
+}
+
+// call runs a plugin, returning its stdout, or its stderr as the error when it fails
+func call(ctx context.Context, path string, stdin io.Reader, args ...string) ([]byte, error) {
+	cmd := exec.CommandContext(ctx, path, args...)
+	cmd.Stdin = stdin
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return nil, fmt.Errorf("%w: %s", err, msg)
+		}
+		return nil, err
+	}
+	return stdout.Bytes(), nil
+}
+
+// Install copies an executable into the plugins directory after checking it describes
+// itself
+func Install(src string) (Plugin, error) {
+	p, err := Describe(src)
+	if err != nil {
+		return p, err
+	}
+	dir, err := Dir()
+	if err != nil {
+		return p, err
+	}
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return p, err
+	}
+	data, err := os.ReadFile(src)
+	if err != nil {
+		return p, err
+	}
+	dst := filepath.Join(dir, filepath.Base(src))
+	if err := os.WriteFile(dst, data, 0700); err != nil {
+		return p, err
+	}
+	p.Path = dst
+	return p, nil
+}
+
+// Remove deletes an installed plugin by name
+func Remove(name string) error {
+	dir, err := Dir()
+	if err != nil {
+		return err
+	}
+	entries, err := os.ReadDir(dir)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	for _, entry := range entries {
+		if strings.TrimSuffix(entry.Name(), filepath.Ext(entry.Name())) == name {
+			return os.Remove(filepath.Join(dir, entry.Name()))
+		}
+	}
+	return fmt.Errorf("no plugin named %s", name)
+}
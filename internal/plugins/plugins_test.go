@@ -0,0 +1,95 @@
+package plugins
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const ticketPlugin = `#!/bin/sh
+case "$1" in
+describe)
+	echo '{"description": "Tickets", "actions": [{"name": "ticket", "title": "Send to tickets", "key": "T"}]}'
+	;;
+run)
+	input=$(cat)
+	case "$input" in
+	*'"subject":"Broken"'*) echo '{"message": "Created ticket #42"}' ;;
+	*) echo '{"error": "nothing to file"}' ;;
+	esac
+	;;
+esac
+`
+
+func setTempHome(t *testing.T) string {
+	t.Helper()
+	dir := t.TempDir()
+	t.Setenv("HOME", dir)
+	t.Setenv("USERPROFILE", dir)
+	return dir
+}
+
+func writeScript(t *testing.T, path, script string) {
+	t.Helper()
+	if err := os.WriteFile(path, []byte(script), 0700); err != nil {
+		t.Fatalf("WriteFile error: %v", err)
+	}
+}
+
+func TestInstallLoadRun(t *testing.T) {
+	home := setTempHome(t)
+	src := filepath.Join(home, "tickets.sh")
+	writeScript(t, src, ticketPlugin)
+
+	if _, err := Install(src); err != nil {
+		t.Fatalf("Install error: %v", err)
+	}
+	plugins, err := Load()
+	if err != nil {
+		t.Fatalf("Load error: %v", err)
+	}
+	if len(plugins) != 1 || plugins[0].Name != "tickets" || len(plugins[0].Actions) != 1 {
+		t.Fatalf("Load() = %+v, want the tickets plugin with one action", plugins)
+	}
+	p := plugins[0]
+	if got := p.Command(p.Actions[0]); got != "tickets:ticket" {
+		t.Errorf("Command() = %q, want tickets:ticket", got)
+	}
+
+	resp, err := p.Run("ticket", Request{Account: "me@example.com", Emails: []Email{{UID: 1, Subject: "Broken"}}})
+	if err != nil {
+		t.Fatalf("Run error: %v", err)
+	}
+	if resp.Message != "Created ticket #42" {
+		t.Errorf("Message = %q, want the plugin's message", resp.Message)
+	}
+	if _, err := p.Run("ticket", Request{}); err == nil || !strings.Contains(err.Error(), "nothing to file") {
+		t.Errorf("Run error = %v, want the error the plugin reported", err)
+	}
+
+	if err := Remove("tickets"); err != nil {
+		t.Fatalf("Remove error: %v", err)
+	}
+	if plugins, _ := Load(); len(plugins) != 0 {
+		t.Errorf("Load() after Remove = %+v, want none", plugins)
+	}
+}
+
+func TestLoadSkipsBrokenPlugins(t *testing.T) {
+	setTempHome(t)
+	dir, _ := Dir()
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		t.Fatal(err)
+	}
+	writeScript(t, filepath.Join(dir, "good"), ticketPlugin)
+	writeScript(t, filepath.Join(dir, "broken"), "#!/bin/sh\necho not json\n")
+
+	plugins, err := Load()
+	if len(plugins) != 1 || plugins[0].Name != "good" {
+		t.Errorf("Load() = %+v, want only the working plugin", plugins)
+	}
+	if err == nil || !strings.Contains(err.Error(), "broken") {
+		t.Errorf("Load() error = %v, want the broken plugin named", err)
+	}
+}
@@ -0,0 +1,61 @@
+package retention
+
+import (
+	netmail "net/mail"
+	"sort"
+	"strings"
+
+	"github.com/emersion/go-imap/v2"
+
+	"maily/internal/cache"
+	"maily/internal/mail"
+)
+
+// Group is the cached mail of one mailing list or sender, for cleaning up in bulk
+type Group struct {
+	Key   string     // List-Id identifier, or the sender's address
+	Name  string     // list name, or the sender as shown on their newest email
+	List  bool       // a mailing list rather than a sender
+	Count int        // emails in the group
+	Size  int64      // bytes, as LoadEmailSizes counts them
+	UIDs  []imap.UID // newest first
+}
+
+// GroupBySender groups emails by mailing list, or by sender for mail not from a list,
+// largest groups first. Sizes are expected newest first, as LoadEmailSizes returns them.
+func GroupBySender(sizes []cache.EmailSize) []Group {
+	index := make(map[string]int)
+	var groups []Group
+	for _, s := range sizes {
+		key, name, list := groupOf(s)
+		i, ok := index[key]
+		if !ok {
+			i = len(groups)
+			index[key] = i
+			groups = append(groups, Group{Key: key, Name: name, List: list})
+		}
+		groups[i].Count++
+		groups[i].Size += s.Size
+		groups[i].UIDs = append(groups[i].UIDs, s.UID)
+	}
+
+	sort.SliceStable(groups, func(i, j int) bool {
+		if groups[i].Count != groups[j].Count {
+			return groups[i].Count > groups[j].Count
+		}
+		return groups[i].Size > groups[j].Size
+	})
+	return groups
+}
+
+// groupOf returns the key and name of the group an email belongs to
+func groupOf(s cache.EmailSize) (key, name string, list bool) {
+	if id, listName := mail.ParseListID(s.ListID); id != "" {
+		return "list:" + id, listName, true
+	}
+	addr := strings.ToLower(strings.TrimSpace(s.From))
+	if parsed, err := netmail.ParseAddress(s.From); err == nil {
+		addr = strings.ToLower(parsed.Address)
+	}
+	return "from:" + addr, s.From, false
+}
@@ -0,0 +1,43 @@
+package retention
+
+import (
+	"testing"
+
+	"maily/internal/cache"
+)
+
+func TestGroupBySender(t *testing.T) {
+	sizes := []cache.EmailSize{
+		{UID: 9, From: "GitHub <notifications@github.com>", ListID: "owner/repo <repo.owner.github.com>", Size: 300},
+		{UID: 8, From: "Alice <alice@example.com>", Size: 50},
+		{UID: 7, From: "GitHub <notifications@github.com>", ListID: "<repo.owner.github.com>", Size: 200},
+		{UID: 6, From: "alice@EXAMPLE.com", Size: 10},
+		{UID: 5, From: "GitHub <notifications@github.com>", ListID: "owner/repo <repo.owner.github.com>", Size: 100},
+		{UID: 4, From: "Bob <bob@example.com>", Size: 1000},
+	}
+
+	groups := GroupBySender(sizes)
+	if len(groups) != 3 {
+		t.Fatalf("got %d groups, want 3: %+v", len(groups), groups)
+	}
+
+	list := groups[0]
+	if !list.List || list.Name != "owner/repo" || list.Count != 3 || list.Size != 600 {
+		t.Errorf("first group = %+v, want the owner/repo list with 3 emails and 600 bytes", list)
+	}
+	if len(list.UIDs) != 3 || list.UIDs[0] != 9 || list.UIDs[2] != 5 {
+		t.Errorf("list UIDs = %v, want newest first", list.UIDs)
+	}
+
+	alice := groups[1]
+	if alice.List || alice.Key != "from:alice@example.com" || alice.Count != 2 {
+		t.Errorf("second group = %+v, want both of Alice's emails whatever the address case", alice)
+	}
+	if alice.Name != "Alice <alice@example.com>" {
+		t.Errorf("Alice's group name = %q, want the sender of her newest email", alice.Name)
+	}
+
+	if bob := groups[2]; bob.Count != 1 || bob.Size != 1000 {
+		t.Errorf("third group = %+v, want Bob's single email", bob)
+	}
+}
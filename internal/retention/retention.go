@@ -0,0 +1,118 @@
+package retention
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"maily/config"
+	"maily/internal/cache"
+	"maily/internal/mail"
+)
+
+// Match is a cached email selected by a retention rule
+type Match struct {
+	Rule    string            `json:"rule"`
+	Action  string            `json:"action"`
+	Account string            `json:"account"`
+	Mailbox string            `json:"mailbox"`
+	Email   cache.CachedEmail `json:"email"`
+}
+
+// Validate reports the first problem with a rule, so bad rules are skipped instead of deleting too much
+func Validate(rule config.RetentionRule) error {
+	switch rule.Action {
+	case config.RetentionDelete, config.RetentionArchive:
+	default:
+		return fmt.Errorf("rule %q: unknown action %q (use delete or archive)", rule.Name, rule.Action)
+	}
+	if rule.OlderThanDays < 1 {
+		return fmt.Errorf("rule %q: older_than_days must be at least 1", rule.Name)
+	}
+	if rule.From == "" && rule.Subject == "" && rule.List == "" {
+		return fmt.Errorf("rule %q: needs at least one of from, subject or list", rule.Name)
+	}
+	return nil
+}
+
+// Mailbox returns the mailbox a rule applies to
+func Mailbox(rule config.RetentionRule) string {
+	if rule.Mailbox == "" {
+		return "INBOX"
+	}
+	return rule.Mailbox
+}
+
+// AppliesTo reports whether a rule covers the given account
+func AppliesTo(rule config.RetentionRule, account string) bool {
+	return rule.Account == "" || strings.EqualFold(rule.Account, account)
+}
+
+// Matches reports whether an email is old enough and matches every condition set on the rule
+func Matches(rule config.RetentionRule, email cache.CachedEmail, now time.Time) bool {
+	received := email.InternalDate
+	if received.IsZero() {
+		received = email.Date
+	}
+	if received.IsZero() || received.After(now.AddDate(0, 0, -rule.OlderThanDays)) {
+		return false
+	}
+
+	if rule.From != "" && !containsFold(email.From, rule.From) {
+		return false
+	}
+	if rule.Subject != "" && !containsFold(email.Subject, rule.Subject) {
+		return false
+	}
+	if rule.List != "" {
+		listID, _ := mail.ParseListID(email.ListID)
+		if listID == "" || (rule.List != "*" && !containsFold(email.ListID, rule.List)) {
+			return false
+		}
+	}
+	return true
+}
+
+// Evaluate returns the cached emails of an account matched by the given rules. An email
+// matched by several rules is only reported for the first one.
+func Evaluate(c *cache.Cache, account string, rules []config.RetentionRule, now time.Time) ([]Match, error) {
+	var matches []Match
+	seen := make(map[string]bool)
+	emailsByMailbox := make(map[string][]cache.CachedEmail)
+
+	for _, rule := range rules {
+		if !AppliesTo(rule, account) || Validate(rule) != nil {
+			continue
+		}
+		mailbox := Mailbox(rule)
+		emails, ok := emailsByMailbox[mailbox]
+		if !ok {
+			var err error
+			emails, err = c.LoadEmails(account, mailbox)
+			if err != nil {
+				return nil, err
+			}
+			emailsByMailbox[mailbox] = emails
+		}
+
+		for _, email := range emails {
+			key := fmt.Sprintf("%s/%d", mailbox, email.UID)
+			if seen[key] || !Matches(rule, email, now) {
+				continue
+			}
+			seen[key] = true
+			matches = append(matches, Match{
+				Rule:    rule.Name,
+				Action:  rule.Action,
+				Account: account,
+				Mailbox: mailbox,
+				Email:   email,
+			})
+		}
+	}
+	return matches, nil
+}
+
+func containsFold(s, substr string) bool {
+	return strings.Contains(strings.ToLower(s), strings.ToLower(substr))
+}
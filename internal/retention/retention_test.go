@@ -0,0 +1,74 @@
+package retention
+
+import (
+	"testing"
+	"time"
+
+	"maily/config"
+	"maily/internal/cache"
+)
+
+func TestMatches(t *testing.T) {
+	now := time.Date(2025, 6, 30, 12, 0, 0, 0, time.UTC)
+	old := now.AddDate(0, 0, -40)
+
+	promo := cache.CachedEmail{
+		From:         "Shop Deals <deals@shop.example.com>",
+		Subject:      "50% off everything",
+		InternalDate: old,
+	}
+	newsletter := cache.CachedEmail{
+		From:         "Go Weekly <hi@golangweekly.com>",
+		Subject:      "Issue 512",
+		ListID:       "Go Weekly <golangweekly.example.com>",
+		InternalDate: old,
+	}
+	recent := promo
+	recent.InternalDate = now.AddDate(0, 0, -3)
+
+	tests := []struct {
+		name  string
+		rule  config.RetentionRule
+		email cache.CachedEmail
+		want  bool
+	}{
+		{"from matches", config.RetentionRule{OlderThanDays: 30, From: "DEALS@shop"}, promo, true},
+		{"too recent", config.RetentionRule{OlderThanDays: 30, From: "deals@shop"}, recent, false},
+		{"from differs", config.RetentionRule{OlderThanDays: 30, From: "other@"}, promo, false},
+		{"all conditions must match", config.RetentionRule{OlderThanDays: 30, From: "deals@", Subject: "invoice"}, promo, false},
+		{"any list", config.RetentionRule{OlderThanDays: 7, List: "*"}, newsletter, true},
+		{"any list skips non-list mail", config.RetentionRule{OlderThanDays: 7, List: "*"}, promo, false},
+		{"list id substring", config.RetentionRule{OlderThanDays: 7, List: "golangweekly"}, newsletter, true},
+		{"falls back to Date", config.RetentionRule{OlderThanDays: 30, Subject: "issue"}, cache.CachedEmail{Subject: "Issue 1", Date: old}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Matches(tt.rule, tt.email, now); got != tt.want {
+				t.Errorf("Matches() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		rule    config.RetentionRule
+		wantErr bool
+	}{
+		{"valid delete", config.RetentionRule{Action: config.RetentionDelete, OlderThanDays: 30, From: "a@b"}, false},
+		{"valid archive", config.RetentionRule{Action: config.RetentionArchive, OlderThanDays: 7, List: "*"}, false},
+		{"unknown action", config.RetentionRule{Action: "purge", OlderThanDays: 30, From: "a@b"}, true},
+		{"missing age", config.RetentionRule{Action: config.RetentionDelete, From: "a@b"}, true},
+		{"no conditions", config.RetentionRule{Action: config.RetentionDelete, OlderThanDays: 30}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := Validate(tt.rule); (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
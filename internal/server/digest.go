@@ -0,0 +1,77 @@
+package server
+
+import (
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"maily/config"
+	"maily/internal/auth"
+	"maily/internal/digest"
+)
+
+const (
+	// digestSentSetting records the day the digest was last mailed, so a restarted server
+	// doesn't mail it again
+	digestSentSetting = "digest_sent"
+	// digestRetryDelay is how long the server waits to retry a digest that failed to send
+	digestRetryDelay = 15 * time.Minute
+)
+
+// sendDigestIfDue mails the daily digest once a day, at or after the configured time
+func (s *Server) sendDigestIfDue(now time.Time) {
+	cfg, err := loadConfig()
+	if err != nil || !cfg.Digest.Scheduled() || now.Before(cfg.Digest.Due(now)) {
+		return
+	}
+	if now.Before(s.digestFailedAt.Add(digestRetryDelay)) {
+		return
+	}
+	sent, err := s.state.SendDigest(cfg, now)
+	if err != nil {
+		s.digestFailedAt = now
+		slog.Error("digest failed", "error", err)
+		return
+	}
+	if sent {
+		slog.Info("digest sent", "to", cfg.Digest.SendTo)
+	}
+}
+
+// SendDigest mails the digest of the active accounts unless it was already mailed today.
+// Returns whether it was sent.
+func (sm *StateManager) SendDigest(cfg config.Config, now time.Time) (bool, error) {
+	if sm.cache == nil {
+		return false, fmt.Errorf("cache unavailable")
+	}
+	day := now.Format(time.DateOnly)
+	if sent, err := sm.cache.Setting(digestSentSetting); err != nil || sent == day {
+		return false, err
+	}
+
+	sm.mu.RLock()
+	var emails []string
+	var sender *auth.Account
+	for i := range sm.store.Accounts {
+		acc := &sm.store.Accounts[i]
+		emails = append(emails, acc.Credentials.Email)
+		if sender == nil || strings.EqualFold(acc.Credentials.Email, cfg.Digest.Account) {
+			sender = acc
+		}
+	}
+	sm.mu.RUnlock()
+	if sender == nil {
+		return false, fmt.Errorf("no accounts configured")
+	}
+
+	d, err := digest.Collect(sm.cache, emails, cfg.Today.VIP, now)
+	if err != nil {
+		return false, err
+	}
+	d.Events, d.Calendar = digest.TodayEvents(now)
+	if err := digest.Send(sender, cfg.Digest.SendTo, d); err != nil {
+		return false, err
+	}
+	return true, sm.cache.SetSetting(digestSentSetting, day)
+}
@@ -0,0 +1,137 @@
+package server
+
+import (
+	"fmt"
+	"log/slog"
+	"time"
+
+	"maily/config"
+	"maily/internal/cache"
+	"maily/internal/calendar"
+	"maily/internal/digest"
+	"maily/internal/hooks"
+
+	"github.com/emersion/go-imap/v2"
+)
+
+// maxMailHooks bounds the new_mail hooks run per sync, so a burst of mail doesn't
+// start hundreds of scripts
+const maxMailHooks = 20
+
+// runHooks runs the configured hooks for an event in the background, logging failures
+func runHooks(cfg config.Config, p hooks.Payload) {
+	if len(hooks.Matching(cfg.Hooks, p)) == 0 {
+		return
+	}
+	go func() {
+		if err := hooks.Run(cfg.Hooks, p); err != nil {
+			slog.Error("hook failed", "event", p.Event, "error", err)
+		}
+	}()
+}
+
+// runMailHooks runs new_mail and important_mail hooks for unread mail that arrived in
+// the inbox. Nothing runs on an account's first sync, when all of its mail is new.
+func (sm *StateManager) runMailHooks(email, mailbox string, emails []cache.CachedEmail, known, archived map[imap.UID]bool) {
+	if mailbox != "INBOX" || len(known) == 0 {
+		return
+	}
+	cfg, err := loadConfig()
+	if err != nil || len(cfg.Hooks) == 0 {
+		return
+	}
+
+	ran := 0
+	for _, e := range emails {
+		if known[e.UID] || archived[e.UID] || !e.Unread {
+			continue
+		}
+		if ran == maxMailHooks {
+			slog.Warn("skipping hooks for the rest of the new mail", "account", email, "ran", ran)
+			return
+		}
+		ran++
+		p := hooks.Payload{
+			Event:   config.HookNewMail,
+			Time:    time.Now(),
+			Account: email,
+			Mailbox: mailbox,
+			UID:     uint32(e.UID),
+			From:    e.From,
+			Subject: e.Subject,
+		}
+		runHooks(cfg, p)
+		if digest.Important(e, cfg.Today.VIP) {
+			p.Event = config.HookImportantMail
+			runHooks(cfg, p)
+		}
+	}
+}
+
+// runSyncErrorHooks runs sync_error hooks for a failed background sync
+func runSyncErrorHooks(account string, err error) {
+	cfg, loadErr := loadConfig()
+	if loadErr != nil {
+		return
+	}
+	runHooks(cfg, hooks.Payload{Event: config.HookSyncError, Time: time.Now(), Account: account, Error: err.Error()})
+}
+
+// runEventHooks runs event_soon hooks for events starting within each hook's lead time.
+// Calendar access is never requested, so without it no event hooks run.
+func (s *Server) runEventHooks(now time.Time) {
+	cfg, err := loadConfig()
+	if err != nil {
+		return
+	}
+	var eventHooks []config.Hook
+	var lead time.Duration
+	for _, h := range cfg.Hooks {
+		if h.Event == config.HookEventSoon {
+			eventHooks = append(eventHooks, h)
+			lead = max(lead, h.Lead())
+		}
+	}
+	if len(eventHooks) == 0 || calendar.GetAuthStatus() != calendar.AuthAuthorized {
+		return
+	}
+	client, err := calendar.NewClient()
+	if err != nil {
+		return
+	}
+	events, err := client.ListEvents(now, now.Add(lead))
+	if err != nil {
+		slog.Error("listing events for hooks failed", "error", err)
+		return
+	}
+
+	// Forget events that started, so the map doesn't grow
+	for key, start := range s.firedEvents {
+		if start.Before(now) {
+			delete(s.firedEvents, key)
+		}
+	}
+	if s.firedEvents == nil {
+		s.firedEvents = make(map[string]time.Time)
+	}
+	for _, e := range events {
+		if e.AllDay || e.StartTime.Before(now) {
+			continue
+		}
+		for i, h := range eventHooks {
+			key := fmt.Sprintf("%d|%s|%d", i, e.ID, e.StartTime.Unix())
+			if _, fired := s.firedEvents[key]; fired || e.StartTime.Sub(now) > h.Lead() {
+				continue
+			}
+			s.firedEvents[key] = e.StartTime
+			start := e.StartTime
+			runHooks(config.Config{Hooks: []config.Hook{h}}, hooks.Payload{
+				Event:    config.HookEventSoon,
+				Time:     now,
+				Title:    e.Title,
+				Start:    &start,
+				Location: e.Location,
+			})
+		}
+	}
+}
@@ -0,0 +1,122 @@
+package server
+
+import (
+	"context"
+	"sync"
+)
+
+// imapLock serializes use of an account's IMAP connection. Interactive requests,
+// such as fetching the body of an email being opened, are let in ahead of background
+// work waiting for the connection, and background work holding it for long (a sync)
+// yields to them between its steps. Background work is let in after interactiveRun
+// requests in a row have gone ahead of it, so a busy user doesn't stop syncs for good.
+type imapLock struct {
+	mu         sync.Mutex
+	changed    chan struct{} // closed when the lock is released or a waiter gives up
+	held       bool
+	waiting    int // interactive requests waiting for the connection
+	background int // background work waiting for the connection
+	passed     int // interactive requests let in while background work waited
+}
+
+// interactiveRun is how many interactive requests go ahead of waiting background work
+const interactiveRun = 8
+
+// backgroundTurn reports whether background work waiting goes next, for callers
+// holding l.mu
+func (l *imapLock) backgroundTurn() bool {
+	return l.waiting == 0 || l.passed >= interactiveRun
+}
+
+// wait returns a channel closed on the next change, for callers holding l.mu
+func (l *imapLock) wait() <-chan struct{} {
+	if l.changed == nil {
+		l.changed = make(chan struct{})
+	}
+	return l.changed
+}
+
+// broadcast wakes everyone waiting, for callers holding l.mu
+func (l *imapLock) broadcast() {
+	if l.changed != nil {
+		close(l.changed)
+		l.changed = nil
+	}
+}
+
+// block releases l.mu until the next change, then takes it back
+func (l *imapLock) block() {
+	ch := l.wait()
+	l.mu.Unlock()
+	<-ch
+	l.mu.Lock()
+}
+
+// Lock waits for the connection behind any interactive requests waiting for it
+func (l *imapLock) Lock() {
+	l.mu.Lock()
+	l.lockBackground()
+	l.mu.Unlock()
+}
+
+// lockBackground waits for the connection as background work, for callers holding l.mu
+func (l *imapLock) lockBackground() {
+	l.background++
+	for l.held || !l.backgroundTurn() {
+		l.block()
+	}
+	l.background--
+	l.held = true
+	l.passed = 0
+}
+
+// LockInteractive waits for the connection ahead of background work, giving up when
+// ctx is done
+func (l *imapLock) LockInteractive(ctx context.Context) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.waiting++
+	for l.held || (l.background > 0 && l.passed >= interactiveRun) {
+		ch := l.wait()
+		l.mu.Unlock()
+		select {
+		case <-ch:
+			l.mu.Lock()
+		case <-ctx.Done():
+			l.mu.Lock()
+			// Background work held back for this request can go ahead
+			l.waiting--
+			l.broadcast()
+			return ctx.Err()
+		}
+	}
+	l.waiting--
+	l.held = true
+	if l.background > 0 {
+		l.passed++
+	}
+	return nil
+}
+
+// Unlock releases the connection
+func (l *imapLock) Unlock() {
+	l.mu.Lock()
+	l.held = false
+	l.broadcast()
+	l.mu.Unlock()
+}
+
+// Yield hands the connection to the interactive requests waiting for it and takes it
+// back once they are done, or interactiveRun of them have run. It reports whether any
+// were waiting.
+func (l *imapLock) Yield() bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.waiting == 0 {
+		return false
+	}
+	l.held = false
+	l.broadcast()
+	l.lockBackground()
+	return true
+}
@@ -0,0 +1,212 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+)
+
+// waitUntil polls until cond holds, failing the test after a second
+func waitUntil(t *testing.T, what string, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatalf("timed out waiting until %s", what)
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+// interactiveWaiting reports how many interactive requests are waiting for l
+func interactiveWaiting(l *imapLock, n int) func() bool {
+	return func() bool {
+		l.mu.Lock()
+		defer l.mu.Unlock()
+		return l.waiting == n
+	}
+}
+
+// receive returns the next value from ch, failing the test after a second
+func receive[T any](t *testing.T, ch <-chan T) T {
+	t.Helper()
+	select {
+	case v := <-ch:
+		return v
+	case <-time.After(time.Second):
+		t.Fatal("timed out")
+		panic("unreachable")
+	}
+}
+
+func TestIMAPLockInteractiveFirst(t *testing.T) {
+	var l imapLock
+	l.Lock()
+
+	order := make(chan string, 2)
+	go func() {
+		l.Lock()
+		order <- "background"
+		l.Unlock()
+	}()
+	time.Sleep(10 * time.Millisecond) // the background work queues first
+	go func() {
+		if err := l.LockInteractive(context.Background()); err != nil {
+			t.Error(err)
+			return
+		}
+		order <- "interactive"
+		l.Unlock()
+	}()
+	waitUntil(t, "the interactive request waits", interactiveWaiting(&l, 1))
+
+	l.Unlock()
+	if first := receive(t, order); first != "interactive" {
+		t.Errorf("%s took the connection first, want interactive", first)
+	}
+	if second := receive(t, order); second != "background" {
+		t.Errorf("%s took the connection second, want background", second)
+	}
+}
+
+func TestIMAPLockYield(t *testing.T) {
+	var l imapLock
+	l.Lock()
+	if l.Yield() {
+		t.Error("Yield() with nobody waiting reported interactive requests ran")
+	}
+
+	ran := make(chan struct{})
+	go func() {
+		if err := l.LockInteractive(context.Background()); err != nil {
+			t.Error(err)
+			return
+		}
+		close(ran)
+		l.Unlock()
+	}()
+	waitUntil(t, "the interactive request waits", interactiveWaiting(&l, 1))
+
+	// A long sync lets the request in between its steps, instead of starving it
+	if !l.Yield() {
+		t.Fatal("Yield() didn't report the waiting interactive request")
+	}
+	select {
+	case <-ran:
+	default:
+		t.Error("Yield() returned before the interactive request ran")
+	}
+	l.Unlock()
+}
+
+func TestIMAPLockBackgroundNotStarved(t *testing.T) {
+	var l imapLock
+	stop := make(chan struct{})
+	var wg sync.WaitGroup
+	for range 4 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				select {
+				case <-stop:
+					return
+				default:
+				}
+				if err := l.LockInteractive(context.Background()); err != nil {
+					t.Error(err)
+					return
+				}
+				time.Sleep(100 * time.Microsecond)
+				l.Unlock()
+			}
+		}()
+	}
+	defer func() {
+		close(stop)
+		wg.Wait()
+	}()
+
+	// Interactive requests keep coming, but not all the time: background work gets
+	// the connection in a gap between them
+	done := make(chan struct{})
+	go func() {
+		l.Lock()
+		l.Unlock()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("background work never got the connection")
+	}
+}
+
+func TestIMAPLockCancel(t *testing.T) {
+	var l imapLock
+	l.Lock()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	gaveUp := make(chan error, 1)
+	go func() {
+		gaveUp <- l.LockInteractive(ctx)
+	}()
+	waitUntil(t, "the interactive request waits", interactiveWaiting(&l, 1))
+
+	background := make(chan struct{})
+	go func() {
+		l.Lock()
+		close(background)
+	}()
+
+	cancel()
+	if err := receive(t, gaveUp); !errors.Is(err, context.Canceled) {
+		t.Fatalf("LockInteractive() = %v, want context.Canceled", err)
+	}
+	waitUntil(t, "the request stops waiting", interactiveWaiting(&l, 0))
+
+	// The request that gave up no longer holds background work back
+	l.Unlock()
+	receive(t, background)
+	l.Unlock()
+
+	// Nor is the lock left taken: an expired wait for a free lock still gets it
+	expired, cancel := context.WithTimeout(context.Background(), 0)
+	defer cancel()
+	if err := l.LockInteractive(expired); err != nil {
+		t.Errorf("LockInteractive() of a free lock = %v", err)
+	}
+	l.Unlock()
+}
+
+func TestIMAPLockExclusive(t *testing.T) {
+	var l imapLock
+	var wg sync.WaitGroup
+	holders := 0
+	for i := range 40 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for range 50 {
+				if i%2 == 0 {
+					l.Lock()
+				} else if err := l.LockInteractive(context.Background()); err != nil {
+					t.Error(err)
+					return
+				}
+				holders++
+				if holders != 1 {
+					t.Errorf("%d holders at once", holders)
+				}
+				if i%4 == 0 {
+					l.Yield()
+				}
+				holders--
+				l.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+}
@@ -5,18 +5,27 @@ import (
 
 	"github.com/emersion/go-imap/v2"
 	"maily/internal/cache"
+	"maily/internal/mail"
 )
 
+// ProtocolVersion is bumped when a change to requests or responses breaks older clients.
+// Clients speaking the same protocol are accepted whatever their release version, so they
+// keep working when an updated server takes over the socket.
+const ProtocolVersion = 1
+
 // Request types
 const (
 	ReqHello           = "hello"
 	ReqGetEmails       = "get_emails"
+	ReqCountEmails     = "count_emails" // takes the get_emails filters
+	ReqGetEmailPage    = "get_email_page"
 	ReqGetEmail        = "get_email"
 	ReqSync            = "sync"
 	ReqQuickRefresh    = "quick_refresh"
 	ReqMarkRead        = "mark_read"
 	ReqMarkUnread      = "mark_unread"
 	ReqMarkMultiRead   = "mark_multi_read"
+	ReqMarkAnswered    = "mark_answered"
 	ReqDeleteEmail     = "delete_email"
 	ReqDeleteMulti     = "delete_multi"
 	ReqMoveToTrash     = "move_to_trash"
@@ -25,15 +34,34 @@ const (
 	ReqQueueDeleteMulti = "queue_delete_multi"
 	ReqQueueMoveTrash   = "queue_move_trash"
 	ReqQueueMoveMultiTrash = "queue_move_multi_trash"
+	ReqQueueMoveSpam       = "queue_move_spam"
+	ReqQueueNotSpam        = "queue_not_spam"
+	ReqQueueArchiveMulti   = "queue_archive_multi"
+	ReqGetSpamFolder       = "get_spam_folder" // superseded by get_special_folder, kept for older clients
+	ReqGetSpecialFolder    = "get_special_folder"
+	ReqGetFailedOps        = "get_failed_ops"
+	ReqRetryOp             = "retry_op"
+	ReqDiscardOp           = "discard_op"
 	ReqSearch          = "search"
 	ReqGetLabels       = "get_labels"
 	ReqGetSyncStatus   = "get_sync_status"
 	ReqGetAccounts     = "get_accounts"
+	ReqGetStats        = "get_stats"
+	ReqGetStorage      = "get_storage"
+	ReqReloadAccounts  = "reload_accounts"
 	ReqPing            = "ping"
 	ReqShutdown        = "shutdown"
+	ReqDrain           = "drain" // stop accepting clients and exit once idle, for a new server to take over
 	// Synchronous operations (real-time, no queuing)
 	ReqSaveDraft           = "save_draft"
+	ReqSendInvitation      = "send_invitation"
 	ReqDownloadAttachment  = "download_attachment"
+	ReqFetchRaw            = "fetch_raw"
+	ReqFetchHeader         = "fetch_header"
+	ReqLoadOlder           = "load_older"
+	ReqUpdateLabels        = "update_labels"
+	ReqRestoreFromTrash   = "restore_from_trash"
+	ReqEmptyTrash         = "empty_trash"
 )
 
 // Request is the message sent from client to server
@@ -41,6 +69,7 @@ type Request struct {
 	Type    string   `json:"type"`
 	ID      string   `json:"id,omitempty"` // for request/response matching
 	Version string   `json:"version,omitempty"` // client version for hello handshake
+	Protocol int     `json:"protocol,omitempty"` // client ProtocolVersion for hello handshake
 	Account string   `json:"account,omitempty"`
 	Mailbox string   `json:"mailbox,omitempty"`
 	UID     uint32   `json:"uid,omitempty"`
@@ -48,14 +77,29 @@ type Request struct {
 	Query   string   `json:"query,omitempty"`  // for search
 	Target  string   `json:"target,omitempty"` // for move operations
 	Limit   int      `json:"limit,omitempty"`
-	// For save_draft
+	Offset  int      `json:"offset,omitempty"` // for get_email_page
+	Sort    string   `json:"sort,omitempty"`   // for get_email_page: date (default), size, sender, subject or unread
+	Since   int64    `json:"since,omitempty"`  // for get_emails: only emails received at or after this Unix time
+	Before  int64    `json:"before,omitempty"` // for get_emails: only emails received before this Unix time
+	UnreadOnly bool  `json:"unread_only,omitempty"` // for get_emails: only unread emails
+	From        string `json:"from,omitempty"`        // for get_emails: only emails whose From contains this
+	Attachments bool   `json:"attachments,omitempty"` // for get_emails: only emails with attachments
+	OpID    int64    `json:"op_id,omitempty"`  // for retry_op and discard_op
+	Folder  string   `json:"folder,omitempty"` // for get_special_folder: sent, trash or spam
+	// For save_draft and send_invitation
 	To      string `json:"to,omitempty"`
-	Subject string `json:"subject,omitempty"`
+	Subject string `json:"subject,omitempty"` // also for get_emails: only emails whose subject contains this
 	Body    string `json:"body,omitempty"`
+	// For send_invitation: the iCalendar METHOD:REQUEST
+	Invitation []byte `json:"invitation,omitempty"`
 	// For download_attachment
 	PartID   string `json:"part_id,omitempty"`
 	Filename string `json:"filename,omitempty"`
 	Encoding string `json:"encoding,omitempty"`
+	Dir      string `json:"dir,omitempty"` // save directory, defaults to ~/Downloads/maily
+	// For update_labels
+	AddLabels    []string `json:"add_labels,omitempty"`
+	RemoveLabels []string `json:"remove_labels,omitempty"`
 }
 
 // Response types
@@ -69,6 +113,7 @@ const (
 	RespStatus   = "status"
 	RespAccounts = "accounts"
 	RespPong     = "pong"
+	RespStats    = "stats"
 )
 
 // Response is the message sent from server to client
@@ -84,6 +129,21 @@ type Response struct {
 	Status   *SyncStatus    `json:"status,omitempty"`
 	// For download_attachment
 	FilePath string `json:"file_path,omitempty"`
+	// For get_stats
+	Stats []cache.SyncStats `json:"stats,omitempty"`
+	// For get_special_folder, and the folder restore_from_trash moved the email to
+	Mailbox string `json:"mailbox,omitempty"`
+	// For get_email_page: emails in the whole mailbox
+	Total int `json:"total,omitempty"`
+	// For get_failed_ops
+	Ops []cache.PendingOp `json:"ops,omitempty"`
+	// For empty_trash: messages deleted; for load_older: emails added to the cache; for
+	// count_emails: emails matching the filters
+	Count int `json:"count,omitempty"`
+	// For get_storage
+	Storage []AccountStorage `json:"storage,omitempty"`
+	// For fetch_raw and fetch_header: the message's RFC 822 source or header block
+	Raw []byte `json:"raw,omitempty"`
 }
 
 // AccountInfo is a summary of account state
@@ -93,6 +153,17 @@ type AccountInfo struct {
 	Syncing    bool      `json:"syncing"`
 	LastSync   time.Time `json:"last_sync"`
 	EmailCount int       `json:"email_count"`
+	FailedOps  int       `json:"failed_ops,omitempty"` // queued operations that ran out of retries
+	Labels     bool      `json:"labels,omitempty"`     // the server has Gmail labels (X-GM-EXT-1) or notmuch tags
+}
+
+// AccountStorage is an account's mailbox storage usage for the storage report
+type AccountStorage struct {
+	Account    string             `json:"account"`
+	Quota      *mail.StorageQuota `json:"quota,omitempty"` // nil when the server doesn't report usage
+	Trash      string             `json:"trash,omitempty"`
+	TrashCount int                `json:"trash_count"`
+	Error      string             `json:"error,omitempty"` // the account couldn't be reached
 }
 
 // SyncStatus represents sync state for an account
@@ -108,8 +179,13 @@ const (
 	EventSyncStarted   = "sync_started"
 	EventSyncCompleted = "sync_completed"
 	EventSyncError     = "sync_error"
+	EventSyncProgress  = "sync_progress" // a running sync moved on; see Event.Progress
 	EventNewEmails     = "new_emails"
-	EventEmailUpdated  = "email_updated"
+	EventEmailUpdated  = "email_updated" // another client read, moved or deleted emails; see Event.UIDs
+	EventOpsFailed     = "ops_failed" // queued operations ran out of retries
+	// EventReconnected is raised by the client itself after reconnecting to a server, as
+	// events pushed while it was disconnected were missed
+	EventReconnected = "reconnected"
 )
 
 // Event is pushed from server to connected clients
@@ -119,4 +195,20 @@ type Event struct {
 	Mailbox string   `json:"mailbox,omitempty"`
 	UIDs    []imap.UID `json:"uids,omitempty"`
 	Error   string   `json:"error,omitempty"`
+	Progress *SyncProgress `json:"progress,omitempty"` // for EventSyncProgress
+}
+
+// Sync phases, in the order a sync runs them
+const (
+	SyncPhaseHeaders = "headers" // the newest messages
+	SyncPhaseRecent  = "recent"  // the rest of the sync window
+	SyncPhaseBodies  = "bodies"  // prefetching the bodies of the newest messages
+	SyncPhaseLabels  = "labels"  // refreshing Gmail labels or notmuch tags
+)
+
+// SyncProgress reports how far a running sync has got
+type SyncProgress struct {
+	Phase   string `json:"phase"`
+	Fetched int    `json:"fetched"` // message headers fetched so far
+	Total   int    `json:"total"`   // message headers to fetch, 0 while still unknown
 }
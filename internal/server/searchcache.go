@@ -0,0 +1,158 @@
+package server
+
+import (
+	"strings"
+	"sync"
+	"time"
+
+	"maily/internal/cache"
+	"maily/internal/mail"
+)
+
+const (
+	// searchCacheTTL is how long search results are reused before asking the server again
+	searchCacheTTL = 5 * time.Minute
+	// searchCacheSize is how many searches are kept
+	searchCacheSize = 20
+)
+
+// searchEntry is one search's results
+type searchEntry struct {
+	account string
+	mailbox string
+	query   string // normalized with normalizeQuery
+	gmail   bool   // searched with Gmail's (or notmuch's) syntax, where terms match anywhere rather than as a phrase
+	emails  []cache.CachedEmail
+	at      time.Time
+}
+
+// searchCache keeps recent search results so repeating a search, or narrowing it by
+// adding terms, doesn't query the server again
+type searchCache struct {
+	mu      sync.Mutex
+	entries []searchEntry // oldest first
+}
+
+// normalizeQuery lowercases the query and collapses its spaces
+func normalizeQuery(query string) string {
+	return strings.Join(strings.Fields(strings.ToLower(query)), " ")
+}
+
+// get returns the results of the same search, or of an earlier one the query narrows
+// by adding terms, filtered to the ones matching it
+func (c *searchCache) get(account, mailbox, query string) ([]cache.CachedEmail, bool) {
+	query = normalizeQuery(query)
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.expire()
+	var base *searchEntry
+	for i := len(c.entries) - 1; i >= 0; i-- {
+		e := &c.entries[i]
+		if e.account != account || e.mailbox != mailbox {
+			continue
+		}
+		if e.query == query {
+			return e.emails, true
+		}
+		if refines(query, e.query) && (base == nil || len(e.query) > len(base.query)) {
+			base = e
+		}
+	}
+	if base == nil {
+		return nil, false
+	}
+
+	var matches []cache.CachedEmail
+	for _, email := range base.emails {
+		if matchesQuery(email, query, base.gmail) {
+			matches = append(matches, email)
+		}
+	}
+	if len(matches) == 0 {
+		// The server may still find some the cached text doesn't show (a match in an
+		// attachment name, or Gmail matching word forms), so ask it
+		return nil, false
+	}
+	return matches, true
+}
+
+// put remembers a search's results
+func (c *searchCache) put(account, mailbox, query string, gmail bool, emails []cache.CachedEmail) {
+	query = normalizeQuery(query)
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.drop(func(e searchEntry) bool {
+		return e.account == account && e.mailbox == mailbox && e.query == query
+	})
+	c.entries = append(c.entries, searchEntry{
+		account: account,
+		mailbox: mailbox,
+		query:   query,
+		gmail:   gmail,
+		emails:  emails,
+		at:      time.Now(),
+	})
+	if len(c.entries) > searchCacheSize {
+		c.entries = c.entries[len(c.entries)-searchCacheSize:]
+	}
+}
+
+// forget drops an account's searches, whose results a change to its mail made stale
+func (c *searchCache) forget(account string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.drop(func(e searchEntry) bool { return e.account == account })
+}
+
+func (c *searchCache) expire() {
+	cutoff := time.Now().Add(-searchCacheTTL)
+	c.drop(func(e searchEntry) bool { return e.at.Before(cutoff) })
+}
+
+func (c *searchCache) drop(stale func(searchEntry) bool) {
+	kept := c.entries[:0]
+	for _, e := range c.entries {
+		if !stale(e) {
+			kept = append(kept, e)
+		}
+	}
+	c.entries = kept
+}
+
+// refines reports whether query is base with terms added, so that its matches are
+// among base's. Queries using search operators aren't, since their terms don't just
+// narrow the search.
+func refines(query, base string) bool {
+	if !strings.HasPrefix(query, base+" ") {
+		return false
+	}
+	for _, term := range strings.Fields(query) {
+		if strings.ContainsAny(term, `:"()-{}`) || term == "or" || term == "and" {
+			return false
+		}
+	}
+	return true
+}
+
+// matchesQuery reports whether the email's sender, recipients, subject or body text
+// contain the query: each term anywhere for Gmail, or the whole phrase as IMAP's TEXT
+// search looks for it. A body's markup isn't searched, and its spaces are collapsed
+// like the query's.
+func matchesQuery(email cache.CachedEmail, query string, gmail bool) bool {
+	fields := []string{email.From, email.To, email.Cc, email.Subject, email.Snippet, mail.BodyText(email.BodyHTML)}
+	for i, f := range fields {
+		fields[i] = normalizeQuery(f)
+	}
+	text := strings.Join(fields, "\n")
+	if !gmail {
+		return strings.Contains(text, query)
+	}
+	for _, term := range strings.Fields(query) {
+		if !strings.Contains(text, term) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,80 @@
+package server
+
+import (
+	"testing"
+
+	"maily/internal/cache"
+)
+
+func TestMatchesQuery(t *testing.T) {
+	htmlBody := cache.CachedEmail{
+		From:     "Alice <alice@example.com>",
+		Subject:  "Quarterly numbers",
+		BodyHTML: `<html><head><style>div { color: red }</style></head><body><div class="x">Revenue   grew <b>12%</b> this quarter</div></body></html>`,
+	}
+	textBody := cache.CachedEmail{
+		From:     "Bob <bob@example.com>",
+		Subject:  "Lunch",
+		BodyHTML: `<pre style="white-space: pre-wrap; font-family: inherit;">Tacos at noon?
+Bring the &lt;div&gt; notes</pre>`,
+	}
+	tests := []struct {
+		name  string
+		email cache.CachedEmail
+		query string
+		gmail bool
+		want  bool
+	}{
+		{"sender", htmlBody, "alice", false, true},
+		{"subject phrase", htmlBody, "quarterly numbers", false, true},
+		{"body text", htmlBody, "revenue", false, true},
+		{"phrase across tags and spaces", htmlBody, "revenue grew 12%", false, true},
+		{"tag name", htmlBody, "div", false, false},
+		{"style rule", htmlBody, "color", false, false},
+		{"attribute", htmlBody, "class", false, false},
+		{"terms apart as a phrase", htmlBody, "revenue quarter", false, false},
+		{"terms apart with gmail", htmlBody, "revenue quarter", true, true},
+		{"one term missing with gmail", htmlBody, "revenue loss", true, false},
+		{"plain text body", textBody, "tacos at noon", false, true},
+		{"escaped text in a plain body", textBody, "<div> notes", false, true},
+		{"wrapper's style", textBody, "pre-wrap", false, false},
+		{"phrase across fields", textBody, "lunch tacos", false, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := matchesQuery(tt.email, tt.query, tt.gmail); got != tt.want {
+				t.Errorf("matchesQuery(%q, gmail %v) = %v, want %v", tt.query, tt.gmail, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSearchCacheNarrows(t *testing.T) {
+	var c searchCache
+	emails := []cache.CachedEmail{
+		{UID: 1, Subject: "Invoice for March", BodyHTML: "<p>Paid in full</p>"},
+		{UID: 2, Subject: "Invoice for April", BodyHTML: `<p style="display:none">due</p><p>Overdue</p>`},
+	}
+	c.put("a@example.com", "INBOX", "Invoice", true, emails)
+
+	if got, ok := c.get("a@example.com", "INBOX", "  invoice "); !ok || len(got) != 2 {
+		t.Errorf("same search = %d results, %v; want the 2 cached", len(got), ok)
+	}
+	if got, ok := c.get("a@example.com", "INBOX", "invoice overdue"); !ok || len(got) != 1 || got[0].UID != 2 {
+		t.Errorf("narrowed search = %+v, %v; want email 2", got, ok)
+	}
+	if _, ok := c.get("a@example.com", "INBOX", "invoice style"); ok {
+		t.Error("a narrowed search matching only markup was answered from the cache")
+	}
+	if _, ok := c.get("a@example.com", "INBOX", "invoice from:bob"); ok {
+		t.Error("a search with an operator was answered from the cache")
+	}
+	if _, ok := c.get("b@example.com", "INBOX", "invoice"); ok {
+		t.Error("another account's search was answered from the cache")
+	}
+
+	c.forget("a@example.com")
+	if _, ok := c.get("a@example.com", "INBOX", "invoice"); ok {
+		t.Error("a forgotten search was answered from the cache")
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"log/slog"
 	"net"
 	"os"
 	"os/signal"
@@ -13,9 +14,11 @@ import (
 	"syscall"
 	"time"
 
+	"maily/config"
 	"maily/internal/auth"
 	"maily/internal/cache"
 	"maily/internal/mail"
+	"maily/internal/proc"
 	"maily/internal/version"
 
 	"github.com/emersion/go-imap/v2"
@@ -23,6 +26,10 @@ import (
 
 const (
 	syncInterval = 10 * time.Minute
+	// drainTimeout bounds how long a draining server waits for running syncs to finish
+	drainTimeout = 30 * time.Second
+	// statsWindow is the period that sync statistics are aggregated over
+	statsWindow = 7 * 24 * time.Hour
 )
 
 // Server is the long-running maily server process
@@ -30,10 +37,18 @@ type Server struct {
 	sockPath string
 	listener net.Listener
 	state    *StateManager
+	searches searchCache // recent search results, reused for repeated and narrowed searches
 	clients  map[*Client]bool
 	clientMu sync.RWMutex
 	done     chan struct{}
+	drain    chan struct{} // closed when a new server takes over the socket
+	drainOne sync.Once
 	wg       sync.WaitGroup
+
+	// Last failed attempt to mail the digest; only the background poller uses it
+	digestFailedAt time.Time
+	// Start times of events whose event_soon hooks ran, by hook and event
+	firedEvents map[string]time.Time
 }
 
 // Client represents a connected TUI client
@@ -68,6 +83,28 @@ func IsServerRunning() bool {
 	return true
 }
 
+// LockStartup takes the lock held while checking for a server and starting one, so
+// several maily instances opened at once (e.g. in tmux panes) start a single server
+// rather than each taking over from the one before
+func LockStartup() (unlock func(), err error) {
+	lockPath := filepath.Join(filepath.Dir(GetPidPath()), "server.lock")
+	if err := os.MkdirAll(filepath.Dir(lockPath), 0700); err != nil {
+		return nil, err
+	}
+	f, err := os.OpenFile(lockPath, os.O_CREATE|os.O_RDWR, 0600)
+	if err != nil {
+		return nil, err
+	}
+	if err := syscall.Flock(int(f.Fd()), syscall.LOCK_EX); err != nil {
+		f.Close()
+		return nil, err
+	}
+	return func() {
+		syscall.Flock(int(f.Fd()), syscall.LOCK_UN)
+		f.Close()
+	}, nil
+}
+
 // New creates a new server instance
 func New() (*Server, error) {
 	sockPath := GetSocketPath()
@@ -77,9 +114,6 @@ func New() (*Server, error) {
 		return nil, fmt.Errorf("failed to create socket directory: %w", err)
 	}
 
-	// Remove stale socket
-	os.Remove(sockPath)
-
 	// Load accounts
 	store, err := auth.LoadAccountStore()
 	if err != nil {
@@ -90,12 +124,32 @@ func New() (*Server, error) {
 		return nil, fmt.Errorf("no accounts configured - run 'maily login' first")
 	}
 
+	// Every account is served: profiles only choose what each client shows, so
+	// commands naming any account work and clients on different profiles don't clash
+	_, warnings, err := config.LoadWithWarnings()
+	for _, problem := range warnings {
+		slog.Warn("invalid config setting, using the default", "problem", problem.String())
+	}
+	var invalid *config.ValidationError
+	if errors.As(err, &invalid) {
+		for _, problem := range invalid.Problems {
+			slog.Warn("invalid config setting, skipping it", "problem", problem.String())
+		}
+	}
+
 	// Initialize disk cache
 	diskCache, err := cache.New()
 	if err != nil {
 		return nil, fmt.Errorf("failed to create cache: %w", err)
 	}
 
+	// Hand off from a running server (e.g. the previous version after an update), then
+	// remove the stale socket
+	if err := takeOver(sockPath); err != nil {
+		return nil, fmt.Errorf("failed to take over from running server: %w", err)
+	}
+	os.Remove(sockPath)
+
 	// Create listener
 	listener, err := net.Listen("unix", sockPath)
 	if err != nil {
@@ -105,13 +159,72 @@ func New() (*Server, error) {
 	// Set socket permissions
 	os.Chmod(sockPath, 0600)
 
-	return &Server{
+	s := &Server{
 		sockPath: sockPath,
 		listener: listener,
 		state:    NewStateManager(store, diskCache),
 		clients:  make(map[*Client]bool),
 		done:     make(chan struct{}),
-	}, nil
+		drain:    make(chan struct{}),
+	}
+	s.state.onSyncProgress = func(account, mailbox string, progress SyncProgress) {
+		s.broadcastEvent(Event{Type: EventSyncProgress, Account: account, Mailbox: mailbox, Progress: &progress})
+	}
+	return s, nil
+}
+
+// takeOver asks the server listening on sockPath to drain and waits until it stops
+// accepting connections. Servers that predate ReqDrain are stopped with SIGTERM.
+func takeOver(sockPath string) error {
+	conn, err := net.DialTimeout("unix", sockPath, time.Second)
+	if err != nil {
+		return nil // nothing listening
+	}
+	defer conn.Close()
+	conn.SetDeadline(time.Now().Add(5 * time.Second))
+
+	if err := json.NewEncoder(conn).Encode(Request{Type: ReqDrain, ID: "drain"}); err == nil {
+		reader := bufio.NewReader(conn)
+		for {
+			line, err := reader.ReadBytes('\n')
+			if err != nil {
+				break
+			}
+			var resp Response
+			if json.Unmarshal(line, &resp) != nil || resp.ID != "drain" {
+				continue // an event pushed before the reply
+			}
+			if resp.Type == RespOK {
+				slog.Info("took over from running server")
+				return nil
+			}
+			break
+		}
+	}
+	return stopOldServer()
+}
+
+// stopOldServer stops the server in the PID file with SIGTERM and waits for it to exit,
+// so its cleanup doesn't remove the socket and PID file of the server replacing it
+func stopOldServer() error {
+	data, err := os.ReadFile(GetPidPath())
+	if err != nil {
+		return fmt.Errorf("server does not support handoff and its PID is unknown: %w", err)
+	}
+	var pid int
+	if _, err := fmt.Sscanf(string(data), "%d", &pid); err != nil || pid <= 0 {
+		return fmt.Errorf("invalid PID file")
+	}
+	if err := syscall.Kill(pid, syscall.SIGTERM); err != nil {
+		return nil // already gone
+	}
+	for i := 0; i < 50; i++ { // 5 seconds max
+		if !proc.Exists(pid) {
+			return nil
+		}
+		time.Sleep(100 * time.Millisecond)
+	}
+	return fmt.Errorf("server (PID %d) did not stop", pid)
 }
 
 // Run starts the server and blocks until shutdown
@@ -122,9 +235,14 @@ func (s *Server) Run() error {
 	if err := os.WriteFile(pidPath, []byte(pidContent), 0600); err != nil {
 		return fmt.Errorf("failed to write PID file: %w", err)
 	}
-	defer os.Remove(pidPath)
+	defer func() {
+		// After a handoff the file belongs to the new server
+		if data, err := os.ReadFile(pidPath); err == nil && string(data) == pidContent {
+			os.Remove(pidPath)
+		}
+	}()
 
-	fmt.Printf("Server started (PID %d, socket %s)\n", os.Getpid(), s.sockPath)
+	slog.Info("server started", "socket", s.sockPath, "version", version.Version)
 
 	// Handle shutdown signals
 	sigChan := make(chan os.Signal, 1)
@@ -141,12 +259,18 @@ func (s *Server) Run() error {
 	// Initial sync (skip if cache is fresh)
 	s.syncAllAccountsIfStale(syncInterval)
 
-	// Wait for shutdown signal
-	<-sigChan
-	fmt.Println("\nShutting down...")
-
-	// Close listener (stops accept loop)
-	s.listener.Close()
+	// Wait for shutdown signal, or a new server taking over
+	draining := false
+	select {
+	case <-sigChan:
+		slog.Info("shutting down")
+		// Close listener (stops accept loop)
+		s.listener.Close()
+	case <-s.drain:
+		draining = true
+		slog.Info("draining for new server")
+		s.waitForSyncs(drainTimeout)
+	}
 
 	// Signal all goroutines to stop
 	close(s.done)
@@ -164,10 +288,12 @@ func (s *Server) Run() error {
 	// Close any pooled IMAP clients
 	s.state.CloseIMAPClients()
 
-	// Clean up socket
-	os.Remove(s.sockPath)
+	// Clean up socket, unless it is now the new server's
+	if !draining {
+		os.Remove(s.sockPath)
+	}
 
-	fmt.Println("Server stopped")
+	slog.Info("server stopped")
 	return nil
 }
 
@@ -181,8 +307,10 @@ func (s *Server) acceptLoop() {
 			select {
 			case <-s.done:
 				return // Normal shutdown
+			case <-s.drain:
+				return // Listener handed to a new server
 			default:
-				fmt.Printf("Accept error: %v\n", err)
+				slog.Error("accept failed", "error", err)
 				continue
 			}
 		}
@@ -238,20 +366,37 @@ func (s *Server) handleClient(client *Client) {
 		}
 
 		// Handle request
+		slog.Debug("request", "type", req.Type, "account", req.Account, "mailbox", req.Mailbox)
 		resp := s.handleRequest(client, &req)
+		if resp.Type == RespError {
+			slog.Warn("request failed", "type", req.Type, "account", req.Account, "error", resp.Error)
+		}
 		resp.ID = req.ID
 		encoder.Encode(resp)
+
+		// Other TUIs showing the folder refresh their list
+		if resp.Type != RespError && changesMail(req.Type) {
+			s.broadcastEventExcept(client, emailUpdatedEvent(&req))
+		}
 	}
 }
 
 // handleRequest processes a single request
 func (s *Server) handleRequest(_ *Client, req *Request) Response {
+	if changesMail(req.Type) {
+		s.searches.forget(req.Account)
+	}
+
 	switch req.Type {
 	case ReqHello:
 		serverVersion := version.Version
 		clientVersion := req.Version
-		// Check major.minor match (ignore patch)
-		if !versionsCompatible(serverVersion, clientVersion) {
+		// Clients that send a protocol version only need to speak the same protocol
+		compatible := versionsCompatible(serverVersion, clientVersion)
+		if req.Protocol != 0 {
+			compatible = req.Protocol == ProtocolVersion
+		}
+		if !compatible {
 			return Response{
 				Type:    RespError,
 				Version: serverVersion,
@@ -267,13 +412,54 @@ func (s *Server) handleRequest(_ *Client, req *Request) Response {
 		accounts := s.state.GetAccounts()
 		return Response{Type: RespAccounts, Accounts: accounts}
 
+	case ReqGetStats:
+		stats, err := s.state.GetStats(req.Account, time.Now().Add(-statsWindow))
+		if err != nil {
+			return Response{Type: RespError, Error: err.Error()}
+		}
+		return Response{Type: RespStats, Stats: stats}
+
+	case ReqGetStorage:
+		storage, err := s.state.GetStorage(req.Account)
+		if err != nil {
+			return Response{Type: RespError, Error: err.Error()}
+		}
+		return Response{Type: RespOK, Storage: storage}
+
+	case ReqReloadAccounts:
+		if err := s.state.ReloadAccounts(); err != nil {
+			return Response{Type: RespError, Error: err.Error()}
+		}
+		slog.Info("accounts reloaded")
+		return Response{Type: RespOK}
+
 	case ReqGetEmails:
-		emails, err := s.state.GetEmails(req.Account, req.Mailbox, req.Limit)
+		var emails []cache.CachedEmail
+		var err error
+		if filter := emailFilter(req); filter != (cache.EmailFilter{Limit: req.Limit}) {
+			emails, err = s.state.GetEmailsFiltered(req.Account, req.Mailbox, filter)
+		} else {
+			emails, err = s.state.GetEmails(req.Account, req.Mailbox, req.Limit)
+		}
 		if err != nil {
 			return Response{Type: RespError, Error: err.Error()}
 		}
 		return Response{Type: RespEmails, Emails: emails}
 
+	case ReqCountEmails:
+		count, err := s.state.CountEmailsFiltered(req.Account, req.Mailbox, emailFilter(req))
+		if err != nil {
+			return Response{Type: RespError, Error: err.Error()}
+		}
+		return Response{Type: RespOK, Count: count}
+
+	case ReqGetEmailPage:
+		emails, total, err := s.state.GetEmailPage(req.Account, req.Mailbox, req.Offset, req.Limit, req.Sort)
+		if err != nil {
+			return Response{Type: RespError, Error: err.Error()}
+		}
+		return Response{Type: RespEmails, Emails: emails, Total: total}
+
 	case ReqGetEmail:
 		email, err := s.state.GetEmailWithBody(req.Account, req.Mailbox, imap.UID(req.UID))
 		if err != nil {
@@ -300,6 +486,7 @@ func (s *Server) handleRequest(_ *Client, req *Request) Response {
 			s.broadcastEvent(Event{Type: EventSyncStarted, Account: req.Account})
 			err := s.state.Sync(req.Account, req.Mailbox)
 			if err != nil {
+				slog.Error("sync failed", "account", req.Account, "mailbox", req.Mailbox, "error", err)
 				s.broadcastEvent(Event{Type: EventSyncError, Account: req.Account, Error: err.Error()})
 			} else {
 				s.broadcastEvent(Event{Type: EventSyncCompleted, Account: req.Account})
@@ -313,6 +500,9 @@ func (s *Server) handleRequest(_ *Client, req *Request) Response {
 	case ReqMarkUnread:
 		return s.markEmailRead(req.Account, req.Mailbox, imap.UID(req.UID), false)
 
+	case ReqMarkAnswered:
+		return s.markEmailAnswered(req.Account, req.Mailbox, imap.UID(req.UID))
+
 	case ReqDeleteEmail:
 		return s.deleteEmail(req.Account, req.Mailbox, imap.UID(req.UID))
 
@@ -337,6 +527,45 @@ func (s *Server) handleRequest(_ *Client, req *Request) Response {
 	case ReqQueueMoveMultiTrash:
 		return s.queueMoveMultiToTrash(req.Account, req.Mailbox, req.UIDs)
 
+	case ReqQueueMoveSpam:
+		return s.queueMoveToSpam(req.Account, req.Mailbox, imap.UID(req.UID))
+
+	case ReqQueueNotSpam:
+		return s.queueNotSpam(req.Account, req.Mailbox, imap.UID(req.UID))
+
+	case ReqQueueArchiveMulti:
+		return s.queueArchiveMulti(req.Account, req.Mailbox, req.UIDs)
+
+	case ReqGetSpamFolder, ReqGetSpecialFolder:
+		kind := req.Folder
+		if req.Type == ReqGetSpamFolder {
+			kind = mail.FolderSpam
+		}
+		folder, err := s.state.GetSpecialFolder(req.Account, kind)
+		if err != nil {
+			return Response{Type: RespError, Error: err.Error()}
+		}
+		return Response{Type: RespOK, Mailbox: folder}
+
+	case ReqGetFailedOps:
+		ops, err := s.state.GetFailedOps(req.Account)
+		if err != nil {
+			return Response{Type: RespError, Error: err.Error()}
+		}
+		return Response{Type: RespOK, Ops: ops}
+
+	case ReqRetryOp:
+		if err := s.state.RetryFailedOp(req.OpID); err != nil {
+			return Response{Type: RespError, Error: err.Error()}
+		}
+		return Response{Type: RespOK} // picked up by the next queue run
+
+	case ReqDiscardOp:
+		if err := s.state.DiscardFailedOp(req.OpID); err != nil {
+			return Response{Type: RespError, Error: err.Error()}
+		}
+		return Response{Type: RespOK}
+
 	case ReqMarkMultiRead:
 		return s.markMultiRead(req.Account, req.Mailbox, req.UIDs)
 
@@ -349,8 +578,45 @@ func (s *Server) handleRequest(_ *Client, req *Request) Response {
 	case ReqSaveDraft:
 		return s.saveDraft(req.Account, req.To, req.Subject, req.Body)
 
+	case ReqSendInvitation:
+		return s.sendInvitation(req.Account, req.To, req.Subject, req.Body, req.Invitation)
+
 	case ReqDownloadAttachment:
-		return s.downloadAttachment(req.Account, req.Mailbox, imap.UID(req.UID), req.PartID, req.Filename, req.Encoding)
+		return s.downloadAttachment(req.Account, req.Mailbox, imap.UID(req.UID), req.PartID, req.Filename, req.Encoding, req.Dir)
+
+	case ReqFetchRaw:
+		return s.fetchRaw(req.Account, req.Mailbox, imap.UID(req.UID), false)
+
+	case ReqFetchHeader:
+		return s.fetchRaw(req.Account, req.Mailbox, imap.UID(req.UID), true)
+
+	case ReqUpdateLabels:
+		return s.updateLabels(req.Account, req.Mailbox, req.UIDs, req.AddLabels, req.RemoveLabels)
+
+	case ReqRestoreFromTrash:
+		to, err := s.state.RestoreFromTrash(req.Account, req.Mailbox, imap.UID(req.UID))
+		if err != nil {
+			return Response{Type: RespError, Error: err.Error()}
+		}
+		return Response{Type: RespOK, Mailbox: to}
+
+	case ReqLoadOlder:
+		count, err := s.state.LoadOlder(req.Account, req.Mailbox, req.Limit)
+		if err != nil {
+			return Response{Type: RespError, Error: err.Error()}
+		}
+		return Response{Type: RespOK, Count: count}
+
+	case ReqEmptyTrash:
+		count, err := s.state.EmptyTrash(req.Account)
+		if err != nil {
+			return Response{Type: RespError, Error: err.Error()}
+		}
+		return Response{Type: RespOK, Count: count}
+
+	case ReqDrain:
+		s.beginDrain()
+		return Response{Type: RespOK}
 
 	case ReqShutdown:
 		go func() {
@@ -366,7 +632,7 @@ func (s *Server) handleRequest(_ *Client, req *Request) Response {
 
 // markEmailRead updates read status on IMAP and cache
 func (s *Server) markEmailRead(account, mailbox string, uid imap.UID, read bool) Response {
-	err := s.state.withIMAPClient(account, func(client *mail.IMAPClient) error {
+	err := s.state.withIMAPClient(account, func(client mail.Client) error {
 		if err := client.SelectMailbox(mailbox); err != nil {
 			return err
 		}
@@ -382,16 +648,41 @@ func (s *Server) markEmailRead(account, mailbox string, uid imap.UID, read bool)
 			s.state.DeleteEmail(account, mailbox, uid)
 			return Response{Type: RespError, Error: "email was deleted on another device"}
 		}
-		return Response{Type: RespError, Error: err.Error()}
+		if mail.IsAuthError(err) {
+			return Response{Type: RespError, Error: err.Error()}
+		}
+		// Server unreachable: apply the change locally and retry it from the queue
+		if qerr := s.state.QueueFlagOp(account, mailbox, uid, read); qerr != nil {
+			return Response{Type: RespError, Error: err.Error()}
+		}
+		slog.Info("queued flag change", "account", account, "uid", uid, "error", err)
+		return Response{Type: RespOK}
 	}
 
 	_ = s.state.UpdateEmailFlags(account, mailbox, uid, !read)
 	return Response{Type: RespOK}
 }
 
+// markEmailAnswered sets \Answered on an email that has been replied to, on the server
+// and in cache
+func (s *Server) markEmailAnswered(account, mailbox string, uid imap.UID) Response {
+	err := s.state.withIMAPClient(account, func(client mail.Client) error {
+		if err := client.SelectMailbox(mailbox); err != nil {
+			return err
+		}
+		return client.MarkAsAnswered(uid)
+	})
+	if err != nil {
+		return Response{Type: RespError, Error: err.Error()}
+	}
+
+	_ = s.state.MarkEmailAnswered(account, mailbox, uid)
+	return Response{Type: RespOK}
+}
+
 // deleteEmail deletes an email from IMAP and cache
 func (s *Server) deleteEmail(account, mailbox string, uid imap.UID) Response {
-	err := s.state.withIMAPClient(account, func(client *mail.IMAPClient) error {
+	err := s.state.withIMAPClient(account, func(client mail.Client) error {
 		if err := client.SelectMailbox(mailbox); err != nil {
 			return err
 		}
@@ -430,12 +721,39 @@ func (s *Server) queueDeleteMultiEmails(account, mailbox string, uids []uint32)
 	return Response{Type: RespOK}
 }
 
+// emailFilter reads the filters of a get_emails request
+func emailFilter(req *Request) cache.EmailFilter {
+	filter := cache.EmailFilter{
+		UnreadOnly:     req.UnreadOnly,
+		From:           req.From,
+		Subject:        req.Subject,
+		HasAttachments: req.Attachments,
+		Limit:          req.Limit,
+	}
+	if req.Since > 0 {
+		filter.Since = time.Unix(req.Since, 0)
+	}
+	if req.Before > 0 {
+		filter.Before = time.Unix(req.Before, 0)
+	}
+	return filter
+}
+
 // broadcastEvent sends an event to all connected clients
 func (s *Server) broadcastEvent(event Event) {
+	s.broadcastEventExcept(nil, event)
+}
+
+// broadcastEventExcept sends an event to every connected client but one, typically the
+// client whose request caused it
+func (s *Server) broadcastEventExcept(except *Client, event Event) {
 	s.clientMu.RLock()
 	defer s.clientMu.RUnlock()
 
 	for client := range s.clients {
+		if client == except {
+			continue
+		}
 		select {
 		case client.events <- event:
 		default:
@@ -444,6 +762,18 @@ func (s *Server) broadcastEvent(event Event) {
 	}
 }
 
+// emailUpdatedEvent describes the emails a mail-changing request touched
+func emailUpdatedEvent(req *Request) Event {
+	event := Event{Type: EventEmailUpdated, Account: req.Account, Mailbox: req.Mailbox}
+	if req.UID != 0 {
+		event.UIDs = append(event.UIDs, imap.UID(req.UID))
+	}
+	for _, uid := range req.UIDs {
+		event.UIDs = append(event.UIDs, imap.UID(uid))
+	}
+	return event
+}
+
 // backgroundPoller syncs all accounts periodically and processes pending ops
 func (s *Server) backgroundPoller() {
 	defer s.wg.Done()
@@ -455,23 +785,62 @@ func (s *Server) backgroundPoller() {
 	opsTicker := time.NewTicker(10 * time.Second)
 	defer opsTicker.Stop()
 
+	// Check once a minute whether the daily digest is due or an event is starting soon
+	minuteTicker := time.NewTicker(time.Minute)
+	defer minuteTicker.Stop()
+
 	for {
 		select {
 		case <-syncTicker.C:
 			s.syncAllAccounts()
 		case <-opsTicker.C:
 			s.processPendingOps()
+		case now := <-minuteTicker.C:
+			s.sendDigestIfDue(now)
+			s.runEventHooks(now)
 		case <-s.done:
 			return
+		case <-s.drain:
+			return // leave new syncs to the new server
+		}
+	}
+}
+
+// beginDrain stops accepting clients so a new server can listen on the socket. Connected
+// clients are served until running syncs finish, then disconnected to reconnect to it.
+func (s *Server) beginDrain() {
+	s.drainOne.Do(func() {
+		close(s.drain)
+		// Closing the listener unlinks the socket, so do it before the new server listens
+		s.listener.Close()
+	})
+}
+
+// waitForSyncs waits up to timeout for running account syncs to finish
+func (s *Server) waitForSyncs(timeout time.Duration) {
+	deadline := time.Now().Add(timeout)
+	for time.Now().Before(deadline) {
+		syncing := false
+		for _, acc := range s.state.GetAccounts() {
+			syncing = syncing || acc.Syncing
+		}
+		if !syncing {
+			return
 		}
+		time.Sleep(200 * time.Millisecond)
 	}
+	slog.Warn("drain timed out with syncs running")
 }
 
 // processPendingOps processes the pending operations queue
 func (s *Server) processPendingOps() {
-	processed, failed := s.state.ProcessPendingOps()
+	processed, failed, exhausted := s.state.ProcessPendingOps()
 	if processed > 0 || failed > 0 {
-		fmt.Printf("Pending ops: %d processed, %d failed\n", processed, failed)
+		slog.Info("pending ops processed", "processed", processed, "failed", failed)
+	}
+	for _, account := range exhausted {
+		slog.Warn("pending ops ran out of retries", "account", account)
+		s.broadcastEvent(Event{Type: EventOpsFailed, Account: account})
 	}
 }
 
@@ -482,31 +851,64 @@ func (s *Server) syncAllAccounts() {
 		s.broadcastEvent(Event{Type: EventSyncStarted, Account: acc.Email})
 		err := s.state.Sync(acc.Email, "INBOX")
 		if err != nil {
-			fmt.Printf("Sync error for %s: %v\n", acc.Email, err)
+			slog.Error("sync failed", "account", acc.Email, "error", err)
 			s.broadcastEvent(Event{Type: EventSyncError, Account: acc.Email, Error: err.Error()})
+			runSyncErrorHooks(acc.Email, err)
 		} else {
-			fmt.Printf("Synced %s\n", acc.Email)
+			slog.Info("synced", "account", acc.Email)
 			s.broadcastEvent(Event{Type: EventSyncCompleted, Account: acc.Email})
+			s.applyRetention(acc.Email)
+			s.pruneCache(acc.Email)
 		}
 	}
 }
 
+// applyRetention queues cleanup for mail matched by enabled retention rules
+func (s *Server) applyRetention(account string) {
+	queued, invalid, err := s.state.ApplyRetention(account)
+	for _, ruleErr := range invalid {
+		slog.Warn("skipping retention rule", "account", account, "error", ruleErr)
+	}
+	if err != nil {
+		slog.Error("retention failed", "account", account, "error", err)
+		return
+	}
+	if queued > 0 {
+		slog.Info("retention queued", "account", account, "emails", queued)
+	}
+}
+
+// pruneCache drops cached bodies beyond the configured cache limits
+func (s *Server) pruneCache(account string) {
+	pruned, err := s.state.PruneCache(account)
+	if err != nil {
+		slog.Error("cache prune failed", "account", account, "error", err)
+		return
+	}
+	if pruned > 0 {
+		slog.Info("cache pruned", "account", account, "bodies", pruned)
+	}
+}
+
 // syncAllAccountsIfStale syncs INBOX for accounts without a recent cache.
 func (s *Server) syncAllAccountsIfStale(maxAge time.Duration) {
 	accounts := s.state.GetAccounts()
 	for _, acc := range accounts {
 		if s.state.IsCacheFresh(acc.Email, "INBOX", maxAge) {
-			fmt.Printf("Skipping initial sync for %s (cache fresh)\n", acc.Email)
+			slog.Info("skipping initial sync, cache fresh", "account", acc.Email)
 			continue
 		}
 		s.broadcastEvent(Event{Type: EventSyncStarted, Account: acc.Email})
 		err := s.state.Sync(acc.Email, "INBOX")
 		if err != nil {
-			fmt.Printf("Sync error for %s: %v\n", acc.Email, err)
+			slog.Error("sync failed", "account", acc.Email, "error", err)
 			s.broadcastEvent(Event{Type: EventSyncError, Account: acc.Email, Error: err.Error()})
+			runSyncErrorHooks(acc.Email, err)
 		} else {
-			fmt.Printf("Synced %s\n", acc.Email)
+			slog.Info("synced", "account", acc.Email)
 			s.broadcastEvent(Event{Type: EventSyncCompleted, Account: acc.Email})
+			s.applyRetention(acc.Email)
+			s.pruneCache(acc.Email)
 		}
 	}
 }
@@ -523,7 +925,7 @@ func (s *Server) deleteMultiEmails(account, mailbox string, uids []uint32) Respo
 		imapUIDs[i] = imap.UID(uid)
 	}
 
-	err := s.state.withIMAPClient(account, func(client *mail.IMAPClient) error {
+	err := s.state.withIMAPClient(account, func(client mail.Client) error {
 		if err := client.SelectMailbox(mailbox); err != nil {
 			return err
 		}
@@ -543,7 +945,7 @@ func (s *Server) deleteMultiEmails(account, mailbox string, uids []uint32) Respo
 
 // moveToTrash moves a single email to trash
 func (s *Server) moveToTrash(account, mailbox string, uid imap.UID) Response {
-	err := s.state.withIMAPClient(account, func(client *mail.IMAPClient) error {
+	err := s.state.withIMAPClient(account, func(client mail.Client) error {
 		return client.MoveToTrashFromMailbox([]imap.UID{uid}, mailbox)
 	})
 	if err != nil {
@@ -564,6 +966,22 @@ func (s *Server) queueMoveToTrash(account, mailbox string, uid imap.UID) Respons
 	return Response{Type: RespOK}
 }
 
+// queueMoveToSpam deletes an email from cache and enqueues a move to the spam folder.
+func (s *Server) queueMoveToSpam(account, mailbox string, uid imap.UID) Response {
+	if err := s.state.QueueOp(account, mailbox, cache.OpMoveSpam, uid); err != nil {
+		return Response{Type: RespError, Error: err.Error()}
+	}
+	return Response{Type: RespOK}
+}
+
+// queueNotSpam deletes an email from cache and enqueues a move from spam back to INBOX.
+func (s *Server) queueNotSpam(account, mailbox string, uid imap.UID) Response {
+	if err := s.state.QueueOp(account, mailbox, cache.OpNotSpam, uid); err != nil {
+		return Response{Type: RespError, Error: err.Error()}
+	}
+	return Response{Type: RespOK}
+}
+
 // moveMultiToTrash moves multiple emails to trash
 func (s *Server) moveMultiToTrash(account, mailbox string, uids []uint32) Response {
 	if len(uids) == 0 {
@@ -576,7 +994,7 @@ func (s *Server) moveMultiToTrash(account, mailbox string, uids []uint32) Respon
 		imapUIDs[i] = imap.UID(uid)
 	}
 
-	err := s.state.withIMAPClient(account, func(client *mail.IMAPClient) error {
+	err := s.state.withIMAPClient(account, func(client mail.Client) error {
 		return client.MoveToTrashFromMailbox(imapUIDs, mailbox)
 	})
 	if err != nil {
@@ -606,6 +1024,21 @@ func (s *Server) queueMoveMultiToTrash(account, mailbox string, uids []uint32) R
 	return Response{Type: RespOK}
 }
 
+// queueArchiveMulti deletes multiple emails from cache and enqueues archive ops.
+func (s *Server) queueArchiveMulti(account, mailbox string, uids []uint32) Response {
+	if len(uids) == 0 {
+		return Response{Type: RespOK}
+	}
+	imapUIDs := make([]imap.UID, len(uids))
+	for i, uid := range uids {
+		imapUIDs[i] = imap.UID(uid)
+	}
+	if err := s.state.QueueOps(account, mailbox, cache.OpArchive, imapUIDs); err != nil {
+		return Response{Type: RespError, Error: err.Error()}
+	}
+	return Response{Type: RespOK}
+}
+
 // markMultiRead marks multiple emails as read
 func (s *Server) markMultiRead(account, mailbox string, uids []uint32) Response {
 	if len(uids) == 0 {
@@ -618,7 +1051,7 @@ func (s *Server) markMultiRead(account, mailbox string, uids []uint32) Response
 		imapUIDs[i] = imap.UID(uid)
 	}
 
-	err := s.state.withIMAPClient(account, func(client *mail.IMAPClient) error {
+	err := s.state.withIMAPClient(account, func(client mail.Client) error {
 		if err := client.SelectMailbox(mailbox); err != nil {
 			return err
 		}
@@ -638,9 +1071,16 @@ func (s *Server) markMultiRead(account, mailbox string, uids []uint32) Response
 
 // searchEmails searches emails via IMAP
 func (s *Server) searchEmails(account, mailbox, query string) Response {
+	if cached, ok := s.searches.get(account, mailbox, query); ok {
+		return Response{Type: RespEmails, Emails: cached}
+	}
+
 	var emails []mail.Email
-	err := s.state.withIMAPClient(account, func(client *mail.IMAPClient) error {
+	var gmail bool
+	err := s.state.withIMAPClient(account, func(client mail.Client) error {
 		var err error
+		caps := client.Capabilities()
+		gmail = caps.GmailExt || caps.Notmuch
 		emails, err = client.SearchMessages(mailbox, query)
 		return err
 	})
@@ -653,15 +1093,30 @@ func (s *Server) searchEmails(account, mailbox, query string) Response {
 	for i, e := range emails {
 		cached[i] = emailToCached(e)
 	}
+	s.searches.put(account, mailbox, query, gmail, cached)
 
 	return Response{Type: RespEmails, Emails: cached}
 }
 
+// changesMail reports whether a request changes an account's mail in ways that make
+// its cached search results stale
+func changesMail(reqType string) bool {
+	switch reqType {
+	case ReqMarkRead, ReqMarkUnread, ReqMarkMultiRead, ReqMarkAnswered,
+		ReqDeleteEmail, ReqDeleteMulti, ReqMoveToTrash, ReqMoveMultiTrash,
+		ReqQueueDelete, ReqQueueDeleteMulti, ReqQueueMoveTrash, ReqQueueMoveMultiTrash,
+		ReqQueueMoveSpam, ReqQueueNotSpam, ReqQueueArchiveMulti, ReqUpdateLabels, ReqRestoreFromTrash,
+		ReqEmptyTrash, ReqSaveDraft:
+		return true
+	}
+	return false
+}
+
 // quickRefresh performs a synchronous metadata-only refresh
 func (s *Server) quickRefresh(account, mailbox string, limit int) Response {
 	var emails []mail.Email
 
-	err := s.state.withIMAPClient(account, func(client *mail.IMAPClient) error {
+	err := s.state.withIMAPClient(account, func(client mail.Client) error {
 		var uidValidity uint32
 		if info, err := client.SelectMailboxWithInfo(mailbox); err == nil {
 			uidValidity = info.UIDValidity
@@ -706,10 +1161,23 @@ func (s *Server) quickRefresh(account, mailbox string, limit int) Response {
 
 		// Persist to disk cache
 		if s.state.cache != nil {
+			cached := make([]cache.CachedEmail, len(emails))
+			for i, e := range emails {
+				cached[i] = emailToCached(e)
+			}
+			known, _ := s.state.cache.GetCachedUIDs(account, mailbox)
+			_, _ = s.state.cache.SaveEmailsBatch(account, mailbox, cached, false)
+
+			// Leave out replies to muted threads, archived as they arrive
+			archived := s.state.applyThreadRules(account, mailbox, cached, known)
+			s.state.runMailHooks(account, mailbox, cached, known, archived)
+			kept := emails[:0]
 			for _, e := range emails {
-				cached := emailToCached(e)
-				_, _ = s.state.cache.InsertEmailMetadataIfMissing(account, mailbox, cached)
+				if !archived[e.UID] {
+					kept = append(kept, e)
+				}
 			}
+			emails = kept
 
 			if uidValidity == 0 {
 				if meta, err := s.state.cache.LoadMetadata(account, mailbox); err == nil && meta != nil {
@@ -745,7 +1213,7 @@ func (s *Server) quickRefresh(account, mailbox string, limit int) Response {
 
 // saveDraft saves an email to the Drafts folder
 func (s *Server) saveDraft(account, to, subject, body string) Response {
-	err := s.state.withIMAPClient(account, func(client *mail.IMAPClient) error {
+	err := s.state.withIMAPClient(account, func(client mail.Client) error {
 		return client.SaveDraft(to, subject, body)
 	})
 	if err != nil {
@@ -754,11 +1222,47 @@ func (s *Server) saveDraft(account, to, subject, body string) Response {
 	return Response{Type: RespOK}
 }
 
-// downloadAttachment downloads an attachment and saves it to disk
-func (s *Server) downloadAttachment(account, mailbox string, uid imap.UID, partID, filename, encoding string) Response {
+// sendInvitation emails a calendar invitation from the account over its SMTP server
+func (s *Server) sendInvitation(account, to, subject, body string, invitation []byte) Response {
+	state, err := s.state.getAccountState(account)
+	if err != nil {
+		return Response{Type: RespError, Error: err.Error()}
+	}
+	if err := mail.NewSMTPClient(&state.Account.Credentials).SendInvitation(to, subject, body, invitation); err != nil {
+		return Response{Type: RespError, Error: err.Error()}
+	}
+	return Response{Type: RespOK}
+}
+
+// updateLabels adds and removes Gmail labels, then caches the labels the server reports
+func (s *Server) updateLabels(account, mailbox string, uids []uint32, add, remove []string) Response {
+	imapUIDs := make([]imap.UID, len(uids))
+	for i, uid := range uids {
+		imapUIDs[i] = imap.UID(uid)
+	}
+
+	var labels map[imap.UID][]string
+	err := s.state.withIMAPClient(account, func(client mail.Client) error {
+		if err := client.UpdateLabels(mailbox, imapUIDs, add, remove); err != nil {
+			return err
+		}
+		var err error
+		labels, err = client.FetchLabels(mailbox, imapUIDs)
+		return err
+	})
+	if err != nil {
+		return Response{Type: RespError, Error: err.Error()}
+	}
+
+	_ = s.state.SetLabels(account, mailbox, labels)
+	return Response{Type: RespOK}
+}
+
+// downloadAttachment downloads an attachment and saves it to dir, or ~/Downloads/maily when empty
+func (s *Server) downloadAttachment(account, mailbox string, uid imap.UID, partID, filename, encoding, dir string) Response {
 	var content []byte
 
-	err := s.state.withIMAPClient(account, func(client *mail.IMAPClient) error {
+	err := s.state.withIMAPClientInteractive(account, func(client mail.Client) error {
 		var err error
 		content, err = client.FetchAttachment(mailbox, uid, partID, encoding)
 		return err
@@ -768,11 +1272,16 @@ func (s *Server) downloadAttachment(account, mailbox string, uid imap.UID, partI
 	}
 
 	// Get downloads directory
-	homeDir, err := os.UserHomeDir()
-	if err != nil {
-		return Response{Type: RespError, Error: fmt.Sprintf("cannot find home directory: %v", err)}
+	downloadsDir := dir
+	if downloadsDir == "" {
+		homeDir, err := os.UserHomeDir()
+		if err != nil {
+			return Response{Type: RespError, Error: fmt.Sprintf("cannot find home directory: %v", err)}
+		}
+		downloadsDir = filepath.Join(homeDir, "Downloads", "maily")
 	}
-	downloadsDir := filepath.Join(homeDir, "Downloads", "maily")
+	// Never let a crafted attachment name escape the directory
+	filename = filepath.Base(filename)
 
 	// Ensure downloads directory exists
 	if err := os.MkdirAll(downloadsDir, 0755); err != nil {
@@ -800,7 +1309,72 @@ func (s *Server) downloadAttachment(account, mailbox string, uid imap.UID, partI
 	return Response{Type: RespOK, FilePath: destPath}
 }
 
+// fetchRaw fetches a message's full source from IMAP, for viewing and saving as .eml,
+// or only its header block
+func (s *Server) fetchRaw(account, mailbox string, uid imap.UID, headerOnly bool) Response {
+	var raw []byte
+	err := s.state.withIMAPClientInteractive(account, func(client mail.Client) error {
+		var err error
+		if headerOnly {
+			raw, err = client.FetchHeader(mailbox, uid)
+		} else {
+			raw, err = client.FetchRaw(mailbox, uid)
+		}
+		return err
+	})
+	if err != nil {
+		return Response{Type: RespError, Error: err.Error()}
+	}
+	return Response{Type: RespOK, Raw: raw}
+}
+
 // versionsCompatible checks if client and server versions match
 func versionsCompatible(serverVer, clientVer string) bool {
 	return serverVer == clientVer
 }
+
+// configCache holds the config loadConfig last read, reused until the file changes
+var configCache struct {
+	sync.Mutex
+	loaded  bool
+	path    string
+	modTime time.Time
+	size    int64 // -1 when there was no file
+	cfg     config.Config
+	err     error
+}
+
+// loadConfig loads config.yml for background work, which goes on despite fatal problems
+// rather than stop: the retention rules they come from are checked again before running.
+// NewServer logs them. The file is only read again once it changes, so the server's
+// tickers can ask for it freely.
+func loadConfig() (config.Config, error) {
+	path, err := config.Path()
+	if err != nil {
+		return config.DefaultConfig(), err
+	}
+	var modTime time.Time
+	size := int64(-1)
+	info, err := os.Stat(path)
+	switch {
+	case err == nil:
+		modTime, size = info.ModTime(), info.Size()
+	case !os.IsNotExist(err):
+		return config.DefaultConfig(), err
+	}
+
+	configCache.Lock()
+	defer configCache.Unlock()
+	c := &configCache
+	if c.loaded && c.path == path && c.modTime.Equal(modTime) && c.size == size {
+		return c.cfg, c.err
+	}
+
+	cfg, err := config.Load()
+	var invalid *config.ValidationError
+	if errors.As(err, &invalid) {
+		err = nil
+	}
+	c.loaded, c.path, c.modTime, c.size, c.cfg, c.err = true, path, modTime, size, cfg, err
+	return cfg, err
+}
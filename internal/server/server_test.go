@@ -0,0 +1,224 @@
+package server
+
+import (
+	"bufio"
+	"encoding/json"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"maily/internal/auth"
+	"maily/internal/cache"
+)
+
+func TestLoadConfigRereadsOnlyChanges(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	path := filepath.Join(os.Getenv("HOME"), ".config", "maily", "config.yml")
+
+	if cfg, err := loadConfig(); err != nil || cfg.MaxEmails != 50 {
+		t.Fatalf("loadConfig() without a file = %d, %v; want the defaults", cfg.MaxEmails, err)
+	}
+
+	write := func(content string, modTime time.Time) {
+		t.Helper()
+		if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.Chtimes(path, modTime, modTime); err != nil {
+			t.Fatal(err)
+		}
+	}
+	at := time.Now().Add(-time.Hour).Truncate(time.Second)
+	write("max_emails: 120\n", at)
+	if cfg, _ := loadConfig(); cfg.MaxEmails != 120 {
+		t.Fatalf("loadConfig() = %d emails, want the new file's 120", cfg.MaxEmails)
+	}
+
+	// The same size and time: the file isn't read again
+	write("max_emails: 130\n", at)
+	if cfg, _ := loadConfig(); cfg.MaxEmails != 120 {
+		t.Errorf("loadConfig() of an unchanged file = %d emails, want the 120 read before", cfg.MaxEmails)
+	}
+
+	write("max_emails: 130\n", at.Add(time.Second))
+	if cfg, _ := loadConfig(); cfg.MaxEmails != 130 {
+		t.Errorf("loadConfig() after a change = %d emails, want 130", cfg.MaxEmails)
+	}
+}
+
+// testClient is a raw connection to a test server, speaking the line-delimited JSON
+// protocol
+type testClient struct {
+	t      *testing.T
+	conn   net.Conn
+	reader *bufio.Reader
+}
+
+func dialTestServer(t *testing.T, sockPath string) *testClient {
+	t.Helper()
+	conn, err := net.Dial("unix", sockPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	c := &testClient{t: t, conn: conn, reader: bufio.NewReader(conn)}
+	// The reply to a ping means the server has registered the client for events
+	c.request(Request{Type: ReqPing, ID: "ping"})
+	return c
+}
+
+// request sends req and returns its response, failing on events received before it
+func (c *testClient) request(req Request) Response {
+	c.t.Helper()
+	if err := json.NewEncoder(c.conn).Encode(req); err != nil {
+		c.t.Fatal(err)
+	}
+	var resp Response
+	if !c.read(time.Second, &resp) {
+		c.t.Fatalf("no response to %s", req.Type)
+	}
+	if resp.ID != req.ID {
+		c.t.Fatalf("got %+v before the response to %s", resp, req.Type)
+	}
+	return resp
+}
+
+// read decodes the next line into v, reporting false when none arrives within wait
+func (c *testClient) read(wait time.Duration, v any) bool {
+	c.t.Helper()
+	c.conn.SetReadDeadline(time.Now().Add(wait))
+	line, err := c.reader.ReadBytes('\n')
+	if err != nil {
+		if ne, ok := err.(net.Error); ok && ne.Timeout() {
+			return false
+		}
+		c.t.Fatal(err)
+	}
+	if err := json.Unmarshal(line, v); err != nil {
+		c.t.Fatalf("decoding %q: %v", line, err)
+	}
+	return true
+}
+
+// startTestServer serves a Maildir account holding one unread message on a socket in
+// a temporary directory
+func startTestServer(t *testing.T) (sockPath, account string) {
+	t.Helper()
+	t.Setenv("HOME", t.TempDir())
+	root := t.TempDir()
+	for _, sub := range []string{"cur", "new", "tmp"} {
+		if err := os.MkdirAll(filepath.Join(root, sub), 0700); err != nil {
+			t.Fatal(err)
+		}
+	}
+	msg := "From: Ana <ana@example.com>\r\nTo: me@example.com\r\nSubject: Hello\r\nMessage-ID: <hello@example.com>\r\n\r\nHi\r\n"
+	if err := os.WriteFile(filepath.Join(root, "new", "1.M1P1.host"), []byte(msg), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	account = "me@example.com"
+	store := &auth.AccountStore{Accounts: []auth.Account{{
+		Provider:    auth.ProviderMaildir,
+		Credentials: auth.MaildirCredentials(account, root),
+	}}}
+	diskCache, err := cache.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Socket paths are limited to about 100 bytes, which test directories can exceed
+	dir, err := os.MkdirTemp("", "maily")
+	if err != nil {
+		t.Fatal(err)
+	}
+	sockPath = filepath.Join(dir, "maily.sock")
+	listener, err := net.Listen("unix", sockPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := &Server{
+		sockPath: sockPath,
+		listener: listener,
+		state:    NewStateManager(store, diskCache),
+		clients:  make(map[*Client]bool),
+		done:     make(chan struct{}),
+		drain:    make(chan struct{}),
+	}
+	s.wg.Add(1)
+	go s.acceptLoop()
+	t.Cleanup(func() {
+		close(s.done)
+		listener.Close()
+		s.clientMu.Lock()
+		for client := range s.clients {
+			client.conn.Close()
+		}
+		s.clientMu.Unlock()
+		s.wg.Wait()
+		s.state.CloseIMAPClients()
+		diskCache.Close()
+		os.RemoveAll(dir)
+	})
+	return sockPath, account
+}
+
+func TestOtherClientsHearOfChanges(t *testing.T) {
+	sockPath, account := startTestServer(t)
+	a := dialTestServer(t, sockPath)
+	b := dialTestServer(t, sockPath)
+
+	resp := a.request(Request{Type: ReqMarkRead, ID: "1", Account: account, Mailbox: "INBOX", UID: 1})
+	if resp.Type != RespOK {
+		t.Fatalf("marking read failed: %s", resp.Error)
+	}
+
+	var event Event
+	if !b.read(time.Second, &event) {
+		t.Fatal("the other client heard nothing")
+	}
+	if event.Type != EventEmailUpdated || event.Account != account || event.Mailbox != "INBOX" ||
+		len(event.UIDs) != 1 || event.UIDs[0] != 1 {
+		t.Errorf("the other client got %+v, want email_updated for UID 1 of the inbox", event)
+	}
+
+	var echoed Event
+	if a.read(100*time.Millisecond, &echoed) {
+		t.Errorf("the client that made the change was told of it: %+v", echoed)
+	}
+}
+
+func TestLockStartup(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	unlock, err := LockStartup()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	locked := make(chan func())
+	go func() {
+		second, err := LockStartup()
+		if err != nil {
+			t.Error(err)
+			second = func() {}
+		}
+		locked <- second
+	}()
+	select {
+	case <-locked:
+		t.Fatal("a second instance took the startup lock while it was held")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	unlock()
+	select {
+	case second := <-locked:
+		second()
+	case <-time.After(time.Second):
+		t.Fatal("the startup lock wasn't handed on once released")
+	}
+}
@@ -1,16 +1,22 @@
 package server
 
 import (
+	"context"
 	"errors"
 	"fmt"
+	"log/slog"
 	"strings"
 	"sync"
 	"time"
 
 	"github.com/emersion/go-imap/v2"
+	"golang.org/x/sync/singleflight"
+	"maily/config"
 	"maily/internal/auth"
 	"maily/internal/cache"
 	"maily/internal/mail"
+	"maily/internal/notify"
+	"maily/internal/retention"
 )
 
 const (
@@ -18,6 +24,11 @@ const (
 	SyncDays = 14
 	// MinSyncEmails is the minimum number of emails to sync
 	MinSyncEmails = 100
+	// syncBatchSize is how many headers a sync fetches between progress reports
+	syncBatchSize = 100
+	// interactiveWait is how long a request the user is waiting on waits for the
+	// connection, as long as the client waits for the answer
+	interactiveWait = 30 * time.Second
 )
 
 // AccountState holds the runtime state for one account
@@ -27,8 +38,10 @@ type AccountState struct {
 	LastSync  time.Time
 	LastError error
 	mu        sync.Mutex
-	imapMu    sync.Mutex
-	imapClient *mail.IMAPClient
+	imapMu    imapLock // interactive requests go ahead of syncs
+	imapClient mail.Client
+	connStats  func() mail.ConnStats // traffic of the latest connection, guarded by mu
+	labels     bool                  // the latest connection has labels to edit, guarded by mu
 }
 
 // StateManager manages all account states and IMAP connections
@@ -37,6 +50,10 @@ type StateManager struct {
 	store    *auth.AccountStore
 	cache    *cache.Cache // SQLite disk cache - single source of truth
 	mu       sync.RWMutex
+	flights  singleflight.Group // concurrent identical syncs and body fetches share one run
+
+	// onSyncProgress is told as syncs move through their phases; set before serving
+	onSyncProgress func(email, mailbox string, progress SyncProgress)
 }
 
 // NewStateManager creates a new state manager
@@ -79,25 +96,60 @@ func isConnectionError(err error) bool {
 		strings.Contains(errStr, "EOF")
 }
 
-func (sm *StateManager) ensureIMAPClientLocked(state *AccountState) (*mail.IMAPClient, error) {
+func (sm *StateManager) ensureIMAPClientLocked(state *AccountState) (mail.Client, error) {
 	if state.imapClient != nil {
 		return state.imapClient, nil
 	}
-	client, err := mail.NewIMAPClient(&state.Account.Credentials)
+	client, err := mail.NewClient(&state.Account.Credentials)
 	if err != nil {
 		return nil, err
 	}
+	slog.Debug("imap connected", "account", state.Account.Credentials.Email, "capabilities", client.Capabilities().String())
 	state.imapClient = client
+	caps := client.Capabilities()
+	state.mu.Lock()
+	state.connStats = client.Stats
+	state.labels = caps.GmailExt || caps.Notmuch
+	state.mu.Unlock()
 	return client, nil
 }
 
-func (sm *StateManager) withIMAPClient(email string, fn func(*mail.IMAPClient) error) error {
+func (sm *StateManager) withIMAPClient(email string, fn func(mail.Client) error) error {
+	return sm.withIMAPClientPriority(email, false, fn)
+}
+
+// withIMAPClientInteractive runs fn on the account's connection ahead of background
+// work waiting for it, for requests the user is waiting on
+func (sm *StateManager) withIMAPClientInteractive(email string, fn func(mail.Client) error) error {
+	return sm.withIMAPClientPriority(email, true, fn)
+}
+
+func (sm *StateManager) withIMAPClientPriority(email string, interactive bool, fn func(mail.Client) error) error {
 	state, err := sm.getAccountState(email)
 	if err != nil {
 		return err
 	}
+	// A throttling provider gets no background commands until the backoff ends; the
+	// user's go out regardless
+	if !interactive {
+		if err := mail.ThrottleBackoff(email); err != nil {
+			return err
+		}
+	}
 
-	state.imapMu.Lock()
+	if interactive {
+		// Give up when the client would have, so a request nobody waits for any more
+		// doesn't keep holding background work back
+		ctx, cancel := context.WithTimeout(context.Background(), interactiveWait)
+		err := state.imapMu.LockInteractive(ctx)
+		cancel()
+		if err != nil {
+			return fmt.Errorf("waiting for the IMAP connection: %w", err)
+		}
+		defer mail.Interactive(email)()
+	} else {
+		state.imapMu.Lock()
+	}
 	defer state.imapMu.Unlock()
 
 	client, err := sm.ensureIMAPClientLocked(state)
@@ -110,9 +162,27 @@ func (sm *StateManager) withIMAPClient(email string, fn func(*mail.IMAPClient) e
 		state.imapClient.Close()
 		state.imapClient = nil
 	}
+	// Name the throttling behind a dropped connection or failed command
+	if backoff := mail.ThrottleBackoff(email); err != nil && backoff != nil && !errors.Is(err, mail.ErrThrottled) {
+		err = fmt.Errorf("%w: %v", backoff, err)
+	}
 	return err
 }
 
+// yieldIMAP lets interactive requests waiting for the account's connection run while
+// background work holding it is between steps. It returns the connection to carry on
+// with, since they may have replaced a broken one.
+func (sm *StateManager) yieldIMAP(email string, client mail.Client) (mail.Client, error) {
+	state, err := sm.getAccountState(email)
+	if err != nil {
+		return nil, err
+	}
+	if !state.imapMu.Yield() {
+		return client, nil
+	}
+	return sm.ensureIMAPClientLocked(state)
+}
+
 func (sm *StateManager) CloseIMAPClients() {
 	sm.mu.RLock()
 	defer sm.mu.RUnlock()
@@ -127,6 +197,57 @@ func (sm *StateManager) CloseIMAPClients() {
 	}
 }
 
+// ReloadAccounts re-reads the account store so added, removed and updated accounts
+// take effect without restarting the server
+func (sm *StateManager) ReloadAccounts() error {
+	store, err := auth.LoadAccountStore()
+	if err != nil {
+		return err
+	}
+
+	sm.mu.Lock()
+	defer sm.mu.Unlock()
+
+	sm.store = store
+
+	// Stop tracking accounts that were removed
+	for email, state := range sm.accounts {
+		if store.GetAccount(email) != nil {
+			continue
+		}
+		state.imapMu.Lock()
+		if state.imapClient != nil {
+			state.imapClient.Close()
+			state.imapClient = nil
+		}
+		state.imapMu.Unlock()
+		delete(sm.accounts, email)
+	}
+
+	for i := range store.Accounts {
+		acc := &store.Accounts[i]
+		state, ok := sm.accounts[acc.Credentials.Email]
+		if !ok {
+			sm.accounts[acc.Credentials.Email] = &AccountState{Account: acc}
+			continue
+		}
+
+		// Drop the old connection so the next request logs in again
+		state.imapMu.Lock()
+		state.Account = acc
+		if state.imapClient != nil {
+			state.imapClient.Close()
+			state.imapClient = nil
+		}
+		state.imapMu.Unlock()
+
+		state.mu.Lock()
+		state.LastError = nil
+		state.mu.Unlock()
+	}
+	return nil
+}
+
 // IsCacheFresh reports whether the disk cache was synced recently.
 func (sm *StateManager) IsCacheFresh(email, mailbox string, maxAge time.Duration) bool {
 	if sm.cache == nil {
@@ -147,12 +268,18 @@ func (sm *StateManager) GetAccounts() []AccountInfo {
 		if sm.cache != nil {
 			emailCount, _ = sm.cache.CountEmails(email, "INBOX")
 		}
+		failedOps := 0
+		if sm.cache != nil {
+			failedOps, _ = sm.cache.CountFailedOps(email)
+		}
 		info := AccountInfo{
 			Email:      email,
 			Provider:   state.Account.Credentials.Provider,
 			Syncing:    state.Syncing,
 			LastSync:   state.LastSync,
 			EmailCount: emailCount,
+			FailedOps:  failedOps,
+			Labels:     state.labels,
 		}
 		state.mu.Unlock()
 		infos = append(infos, info)
@@ -228,14 +355,78 @@ func (sm *StateManager) GetEmails(email, mailbox string, limit int) ([]cache.Cac
 	if sm.cache == nil {
 		return nil, nil
 	}
-	emails, err := sm.cache.LoadEmails(email, mailbox)
+	if limit > 0 {
+		return sm.cache.LoadEmailsLimit(email, mailbox, limit)
+	}
+	return sm.cache.LoadEmails(email, mailbox)
+}
+
+// GetEmailsFiltered returns the emails matching filter from disk cache
+func (sm *StateManager) GetEmailsFiltered(email, mailbox string, filter cache.EmailFilter) ([]cache.CachedEmail, error) {
+	if sm.cache == nil {
+		return nil, nil
+	}
+	return sm.cache.LoadEmailsFiltered(email, mailbox, filter)
+}
+
+// CountEmailsFiltered returns how many emails in disk cache match filter
+func (sm *StateManager) CountEmailsFiltered(email, mailbox string, filter cache.EmailFilter) (int, error) {
+	if sm.cache == nil {
+		return 0, nil
+	}
+	return sm.cache.CountEmailsFiltered(email, mailbox, filter)
+}
+
+// GetEmailPage returns limit emails starting at offset in the sort order from disk cache,
+// with the mailbox total
+func (sm *StateManager) GetEmailPage(email, mailbox string, offset, limit int, sort string) ([]cache.CachedEmail, int, error) {
+	if sm.cache == nil {
+		return nil, 0, nil
+	}
+	total, err := sm.cache.CountEmails(email, mailbox)
 	if err != nil {
-		return nil, err
+		return nil, 0, err
+	}
+	emails, err := sm.cache.LoadEmailPageSorted(email, mailbox, offset, limit, sort)
+	if err != nil {
+		return nil, 0, err
+	}
+	return emails, total, nil
+}
+
+// LoadOlder fetches the page of emails older than the oldest cached one from IMAP and
+// caches it, so the list can grow past the sync window without refetching what it
+// has. Returns how many emails were added; 0 means the mailbox has no older mail.
+func (sm *StateManager) LoadOlder(email, mailbox string, limit int) (int, error) {
+	if sm.cache == nil {
+		return 0, fmt.Errorf("cache unavailable")
+	}
+	oldest, ok, err := sm.cache.OldestUID(email, mailbox)
+	if err != nil {
+		return 0, err
+	}
+	if !ok {
+		return 0, nil // nothing synced yet, the next sync fills the newest page
+	}
+
+	var older []mail.Email
+	err = sm.withIMAPClient(email, func(client mail.Client) error {
+		var err error
+		older, err = client.FetchOlderMetadata(mailbox, oldest, limit)
+		return err
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	cached := make([]cache.CachedEmail, len(older))
+	for i, e := range older {
+		cached[i] = emailToCached(e)
 	}
-	if limit > 0 && len(emails) > limit {
-		return emails[:limit], nil
+	if _, err := sm.cache.SaveEmailsBatch(email, mailbox, cached, false); err != nil {
+		return 0, err
 	}
-	return emails, nil
+	return len(cached), nil
 }
 
 // GetEmail returns a single email by UID from disk cache
@@ -247,7 +438,21 @@ func (sm *StateManager) GetEmail(email, mailbox string, uid imap.UID) (*cache.Ca
 }
 
 // GetEmailWithBody loads an email from disk cache, fetching body from IMAP if missing.
+// Clients opening the same email at once share one fetch, each getting its own copy.
 func (sm *StateManager) GetEmailWithBody(email, mailbox string, uid imap.UID) (*cache.CachedEmail, error) {
+	key := fmt.Sprintf("body\x00%s\x00%s\x00%d", email, mailbox, uid)
+	v, err, _ := sm.flights.Do(key, func() (any, error) {
+		return sm.getEmailWithBody(email, mailbox, uid)
+	})
+	cached, _ := v.(*cache.CachedEmail)
+	if cached == nil {
+		return nil, err
+	}
+	shared := *cached
+	return &shared, err
+}
+
+func (sm *StateManager) getEmailWithBody(email, mailbox string, uid imap.UID) (*cache.CachedEmail, error) {
 	if sm.cache == nil {
 		return nil, nil
 	}
@@ -266,7 +471,8 @@ func (sm *StateManager) GetEmailWithBody(email, mailbox string, uid imap.UID) (*
 	}
 
 	// Fetch body from IMAP and persist
-	fetchErr := sm.withIMAPClient(email, func(client *mail.IMAPClient) error {
+	// The user is waiting on this one, so it goes ahead of any sync
+	fetchErr := sm.withIMAPClientInteractive(email, func(client mail.Client) error {
 		bodyHTML, snippet, err := client.FetchEmailBody(mailbox, uid)
 		if err != nil {
 			return err
@@ -308,6 +514,22 @@ func (sm *StateManager) UpdateEmailFlags(email, mailbox string, uid imap.UID, un
 	return sm.cache.UpdateEmailFlags(email, mailbox, uid, unread)
 }
 
+// MarkEmailAnswered records in disk cache that an email has been replied to
+func (sm *StateManager) MarkEmailAnswered(email, mailbox string, uid imap.UID) error {
+	if sm.cache == nil {
+		return nil
+	}
+	return sm.cache.MarkEmailAnswered(email, mailbox, uid)
+}
+
+// SetLabels stores Gmail labels in disk cache
+func (sm *StateManager) SetLabels(email, mailbox string, labels map[imap.UID][]string) error {
+	if sm.cache == nil {
+		return nil
+	}
+	return sm.cache.SetLabels(email, mailbox, labels)
+}
+
 // DeleteEmail removes an email from disk cache
 func (sm *StateManager) DeleteEmail(email, mailbox string, uid imap.UID) error {
 	if sm.cache == nil {
@@ -324,12 +546,54 @@ func (sm *StateManager) QueueOp(account, mailbox, operation string, uid imap.UID
 	if _, err := sm.getAccountState(account); err != nil {
 		return err
 	}
+	if operation == cache.OpMoveTrash {
+		sm.recordTrashOrigin(account, mailbox, uid)
+	}
 	if err := sm.cache.DeleteEmail(account, mailbox, uid); err != nil {
 		return err
 	}
 	return sm.cache.AddPendingOp(account, mailbox, operation, uid)
 }
 
+// recordTrashOrigin remembers where a cached email is being trashed from so it can be
+// restored there. Emails missing from the cache are restored to INBOX.
+func (sm *StateManager) recordTrashOrigin(account, mailbox string, uid imap.UID) {
+	cached, err := sm.cache.GetEmail(account, mailbox, uid)
+	if err != nil || cached == nil {
+		return
+	}
+	if err := sm.cache.RecordTrashOrigin(account, cached.MessageID, mailbox); err != nil {
+		slog.Warn("failed to record trash origin", "account", account, "mailbox", mailbox, "error", err)
+	}
+}
+
+// QueueFlagOp marks an email read or unread in the cache and queues the change for the
+// server, recording the read state it replaces so changes from other devices are kept
+func (sm *StateManager) QueueFlagOp(account, mailbox string, uid imap.UID, read bool) error {
+	if sm.cache == nil {
+		return fmt.Errorf("cache unavailable")
+	}
+	if _, err := sm.getAccountState(account); err != nil {
+		return err
+	}
+
+	expected := ""
+	if cached, err := sm.cache.GetEmail(account, mailbox, uid); err == nil && cached != nil {
+		expected = cache.FlagStateRead
+		if cached.Unread {
+			expected = cache.FlagStateUnread
+		}
+	}
+	op := cache.OpMarkUnread
+	if read {
+		op = cache.OpMarkRead
+	}
+	if err := sm.cache.UpdateEmailFlags(account, mailbox, uid, !read); err != nil {
+		return err
+	}
+	return sm.cache.AddFlagOp(account, mailbox, op, uid, expected)
+}
+
 // QueueOps deletes multiple emails from cache and enqueues pending operations.
 func (sm *StateManager) QueueOps(account, mailbox, operation string, uids []imap.UID) error {
 	if len(uids) == 0 {
@@ -342,6 +606,9 @@ func (sm *StateManager) QueueOps(account, mailbox, operation string, uids []imap
 		return err
 	}
 	for _, uid := range uids {
+		if operation == cache.OpMoveTrash {
+			sm.recordTrashOrigin(account, mailbox, uid)
+		}
 		if err := sm.cache.DeleteEmail(account, mailbox, uid); err != nil {
 			return err
 		}
@@ -352,6 +619,140 @@ func (sm *StateManager) QueueOps(account, mailbox, operation string, uids []imap
 	return nil
 }
 
+// ApplyRetention queues the actions of enabled retention rules against an account's cached mail.
+// It returns the number of emails queued and the enabled rules skipped as invalid.
+func (sm *StateManager) ApplyRetention(email string) (int, []error, error) {
+	if sm.cache == nil {
+		return 0, nil, nil
+	}
+	cfg, err := loadConfig()
+	if err != nil {
+		return 0, nil, err
+	}
+
+	var rules []config.RetentionRule
+	var invalid []error
+	for _, rule := range cfg.Retention {
+		if !rule.Enabled || !retention.AppliesTo(rule, email) {
+			continue
+		}
+		if err := retention.Validate(rule); err != nil {
+			invalid = append(invalid, err)
+			continue
+		}
+		rules = append(rules, rule)
+	}
+	if len(rules) == 0 {
+		return 0, invalid, nil
+	}
+
+	matches, err := retention.Evaluate(sm.cache, email, rules, time.Now())
+	if err != nil {
+		return 0, invalid, err
+	}
+
+	// Skip emails already waiting in the queue (sync can re-insert them before it drains)
+	pending, _ := sm.cache.GetPendingOps(email)
+	queued := make(map[string]bool, len(pending))
+	for _, op := range pending {
+		queued[fmt.Sprintf("%s/%d", op.Mailbox, op.UID)] = true
+	}
+
+	count := 0
+	for _, m := range matches {
+		if queued[fmt.Sprintf("%s/%d", m.Mailbox, m.Email.UID)] {
+			continue
+		}
+		op := cache.OpMoveTrash
+		if m.Action == config.RetentionArchive {
+			op = cache.OpArchive
+		}
+		if err := sm.QueueOp(email, m.Mailbox, op, m.Email.UID); err != nil {
+			return count, invalid, err
+		}
+		count++
+	}
+	return count, invalid, nil
+}
+
+// applyThreadRules handles newly synced mail in muted and followed threads: muted mail
+// is archived out of the inbox, followed mail notified even when nothing else would be.
+// A message replying to one in such a thread joins it, so later replies are caught too.
+// Returns the UIDs queued for archiving.
+func (sm *StateManager) applyThreadRules(email, mailbox string, emails []cache.CachedEmail, known map[imap.UID]bool) map[imap.UID]bool {
+	archived := make(map[imap.UID]bool)
+	rules, err := sm.cache.GetThreadRules(email)
+	if err != nil || len(rules) == 0 {
+		return archived
+	}
+
+	// An archive still waiting in the queue removed the email from the cache, and sync
+	// puts it back until the queue drains
+	pending, _ := sm.cache.GetPendingOps(email)
+	queued := make(map[imap.UID]bool, len(pending))
+	for _, op := range pending {
+		if op.Mailbox == mailbox {
+			queued[op.UID] = true
+		}
+	}
+
+	// Oldest first, so a reply synced along with the message it answers joins the thread
+	for i := len(emails) - 1; i >= 0; i-- {
+		e := emails[i]
+		if known[e.UID] {
+			continue
+		}
+		var rule cache.ThreadRule
+		for _, id := range cache.ThreadIDs(e.MessageID, e.References) {
+			if r, ok := rules[id]; ok {
+				rule = r
+				break
+			}
+		}
+		if rule.Rule == "" {
+			continue
+		}
+		if _, ok := rules[e.MessageID]; !ok && e.MessageID != "" {
+			rules[e.MessageID] = rule
+			_ = sm.cache.JoinThread(email, e.MessageID, rule)
+		}
+
+		switch rule.Rule {
+		case cache.ThreadMute:
+			if mailbox != "INBOX" || queued[e.UID] {
+				continue
+			}
+			if err := sm.QueueOp(email, mailbox, cache.OpArchive, e.UID); err != nil {
+				slog.Error("archiving muted thread failed", "account", email, "uid", e.UID, "error", err)
+				continue
+			}
+			archived[e.UID] = true
+			slog.Info("archived muted thread reply", "account", email, "uid", e.UID)
+		case cache.ThreadFollow:
+			if !e.Unread {
+				continue
+			}
+			_ = notify.Send("Maily", fmt.Sprintf("%s: %s", e.From, e.Subject))
+		}
+	}
+	return archived
+}
+
+// PruneCache drops cached bodies beyond the configured cache limits for an account
+func (sm *StateManager) PruneCache(email string) (int, error) {
+	if sm.cache == nil {
+		return 0, nil
+	}
+	cfg, err := loadConfig()
+	if err != nil || cfg.Cache == nil {
+		return 0, err
+	}
+	now := time.Now()
+	return sm.cache.PruneAccount(email, func(mailbox string) (time.Time, int64) {
+		return cfg.Cache.Limit(mailbox).Bounds(now)
+	})
+}
+
 // GetAccountCredentials returns credentials for an account
 func (sm *StateManager) GetAccountCredentials(email string) (*auth.Credentials, error) {
 	state, err := sm.getAccountState(email)
@@ -364,7 +765,7 @@ func (sm *StateManager) GetAccountCredentials(email string) (*auth.Credentials,
 // GetLabels fetches labels from IMAP
 func (sm *StateManager) GetLabels(email string) ([]string, error) {
 	var labels []string
-	err := sm.withIMAPClient(email, func(client *mail.IMAPClient) error {
+	err := sm.withIMAPClient(email, func(client mail.Client) error {
 		var err error
 		labels, err = client.ListMailboxes()
 		return err
@@ -375,9 +776,81 @@ func (sm *StateManager) GetLabels(email string) ([]string, error) {
 	return labels, nil
 }
 
+// GetSpecialFolder finds the sent, trash or spam folder for an account
+func (sm *StateManager) GetSpecialFolder(email, kind string) (string, error) {
+	var folder string
+	err := sm.withIMAPClient(email, func(client mail.Client) error {
+		var err error
+		folder, err = client.FindSpecialFolder(kind)
+		return err
+	})
+	return folder, err
+}
+
+// RestoreFromTrash moves an email from the trash back to the folder it was deleted from,
+// or INBOX when that wasn't recorded. Returns the folder it was moved to.
+func (sm *StateManager) RestoreFromTrash(email, trash string, uid imap.UID) (string, error) {
+	to := mail.INBOX
+	messageID := ""
+	if sm.cache != nil {
+		if cached, err := sm.cache.GetEmail(email, trash, uid); err == nil && cached != nil {
+			messageID = cached.MessageID
+			if origin, ok, _ := sm.cache.TrashOrigin(email, messageID); ok {
+				to = origin
+			}
+		}
+	}
+
+	err := sm.withIMAPClient(email, func(client mail.Client) error {
+		return client.MoveMessages([]imap.UID{uid}, trash, to)
+	})
+	if err != nil {
+		return "", err
+	}
+
+	if sm.cache != nil {
+		_ = sm.cache.DeleteEmail(email, trash, uid)
+		if messageID != "" {
+			_ = sm.cache.ForgetTrashOrigin(email, messageID)
+		}
+	}
+	return to, nil
+}
+
+// EmptyTrash permanently deletes everything in an account's trash
+func (sm *StateManager) EmptyTrash(email string) (int, error) {
+	var trash string
+	var count int
+	err := sm.withIMAPClient(email, func(client mail.Client) error {
+		var err error
+		if trash, err = client.FindTrashFolder(); err != nil {
+			return err
+		}
+		count, err = client.EmptyFolder(trash)
+		return err
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	if sm.cache != nil {
+		_ = sm.cache.InvalidateMailbox(email, trash)
+		_ = sm.cache.ClearTrashOrigins(email)
+	}
+	return count, nil
+}
+
 // Sync performs a full sync for an account using max(14 days, 100 emails)
-// This ensures we always have at least 100 emails while never missing recent ones
+// This ensures we always have at least 100 emails while never missing recent ones.
+// Syncs of the same mailbox requested while one runs share its result.
 func (sm *StateManager) Sync(email, mailbox string) error {
+	_, err, _ := sm.flights.Do("sync\x00"+email+"\x00"+mailbox, func() (any, error) {
+		return nil, sm.syncMailbox(email, mailbox)
+	})
+	return err
+}
+
+func (sm *StateManager) syncMailbox(email, mailbox string) error {
 	acquired, err := sm.TryStartSync(email)
 	if err != nil {
 		return err
@@ -386,17 +859,21 @@ func (sm *StateManager) Sync(email, mailbox string) error {
 		return fmt.Errorf("sync already in progress")
 	}
 	var syncErr error
+	var fetched int
+	started := time.Now()
 	defer func() {
 		sm.EndSync(email, syncErr)
+		sm.recordSyncRun(email, mailbox, started, fetched, syncErr)
 	}()
 
-	syncErr = sm.withIMAPClient(email, func(client *mail.IMAPClient) error {
+	syncErr = sm.withIMAPClient(email, func(client mail.Client) error {
 		var uidValidity uint32
 		if info, err := client.SelectMailboxWithInfo(mailbox); err == nil {
 			uidValidity = info.UIDValidity
 		}
 
 		// Step 1: Fetch last 100 emails by sequence number (metadata only, no body)
+		sm.reportProgress(email, mailbox, SyncPhaseHeaders, 0, 0)
 		emails, err := client.FetchMessagesMetadata(mailbox, MinSyncEmails)
 		if err != nil {
 			return err
@@ -408,6 +885,10 @@ func (sm *StateManager) Sync(email, mailbox string) error {
 			fetchedUIDs[e.UID] = true
 		}
 
+		if client, err = sm.yieldIMAP(email, client); err != nil {
+			return err
+		}
+
 		// Step 2: Get UIDs from last 14 days
 		since := time.Now().AddDate(0, 0, -SyncDays)
 		recentUIDs, err := client.FetchUIDsAndFlags(mailbox, since)
@@ -424,14 +905,29 @@ func (sm *StateManager) Sync(email, mailbox string) error {
 			}
 		}
 
-		// Step 4: Fetch any missing recent emails (metadata only)
-		if len(missingUIDs) > 0 {
-			additional, err := client.FetchMessagesByUIDsMetadata(mailbox, missingUIDs)
-			if err == nil {
-				emails = append(emails, additional...)
+		if client, err = sm.yieldIMAP(email, client); err != nil {
+			return err
+		}
+
+		// Step 4: Fetch any missing recent emails (metadata only), in batches so a large
+		// first sync reports its progress and lets interactive requests through
+		total := len(emails) + len(missingUIDs)
+		sm.reportProgress(email, mailbox, SyncPhaseRecent, len(emails), total)
+		for start := 0; start < len(missingUIDs); start += syncBatchSize {
+			batch := missingUIDs[start:min(start+syncBatchSize, len(missingUIDs))]
+			additional, err := client.FetchMessagesByUIDsMetadata(mailbox, batch)
+			if err != nil {
+				break
+			}
+			emails = append(emails, additional...)
+			sm.reportProgress(email, mailbox, SyncPhaseRecent, len(emails), total)
+			if client, err = sm.yieldIMAP(email, client); err != nil {
+				return err
 			}
 		}
 
+		fetched = len(emails)
+
 		// Convert to cached format and persist to disk
 		cached := make([]cache.CachedEmail, len(emails))
 		for i, e := range emails {
@@ -440,22 +936,42 @@ func (sm *StateManager) Sync(email, mailbox string) error {
 
 		// Persist to disk (insert metadata only if missing)
 		if sm.cache != nil {
-			for _, c := range cached {
-				_, _ = sm.cache.InsertEmailMetadataIfMissing(email, mailbox, c)
-			}
+			known, _ := sm.cache.GetCachedUIDs(email, mailbox)
+			_, _ = sm.cache.SaveEmailsBatch(email, mailbox, cached, false)
+			archived := sm.applyThreadRules(email, mailbox, cached, known)
+			sm.runMailHooks(email, mailbox, cached, known, archived)
 
 			// Step 5: Remove stale emails from disk cache
 			// Build set of all server UIDs
 			serverUIDs := make(map[imap.UID]bool)
+			windowStart := imap.UID(0)
 			for _, e := range emails {
 				serverUIDs[e.UID] = true
+				if windowStart == 0 || e.UID < windowStart {
+					windowStart = e.UID
+				}
 			}
 
-			// Delete cached UIDs that no longer exist on server
+			// Delete cached UIDs that no longer exist on server, older pages loaded with
+			// l included. The mailbox's UIDs are only listed when some are cached below
+			// the synced window; when they can't be, only the window is checked.
 			cachedUIDs, err := sm.cache.GetCachedUIDs(email, mailbox)
 			if err == nil {
+				var all map[imap.UID]bool // nil when not listed
+				for uid := range cachedUIDs {
+					if windowStart == 0 || uid < windowStart {
+						if listed, err := client.FetchAllUIDs(mailbox); err == nil {
+							all = listed
+						}
+						break
+					}
+				}
 				for uid := range cachedUIDs {
-					if !serverUIDs[uid] {
+					deleted := uid >= windowStart && !serverUIDs[uid]
+					if all != nil {
+						deleted = !all[uid]
+					}
+					if deleted {
 						_ = sm.cache.DeleteEmail(email, mailbox, uid)
 					}
 				}
@@ -470,7 +986,12 @@ func (sm *StateManager) Sync(email, mailbox string) error {
 				}
 			}
 
+			if client, err = sm.yieldIMAP(email, client); err != nil {
+				return err
+			}
+
 			if len(prefetchUIDs) > 0 {
+				sm.reportProgress(email, mailbox, SyncPhaseBodies, len(emails), len(emails))
 				fullEmails, err := client.FetchMessagesByUIDs(mailbox, prefetchUIDs)
 				if err == nil {
 					for _, fe := range fullEmails {
@@ -478,6 +999,22 @@ func (sm *StateManager) Sync(email, mailbox string) error {
 					}
 				}
 			}
+
+			if client, err = sm.yieldIMAP(email, client); err != nil {
+				return err
+			}
+
+			// Step 7: Refresh Gmail labels or notmuch tags, which change without the message moving
+			if caps := client.Capabilities(); caps.GmailExt || caps.Notmuch {
+				sm.reportProgress(email, mailbox, SyncPhaseLabels, len(emails), len(emails))
+				uids := make([]imap.UID, 0, len(emails))
+				for _, e := range emails {
+					uids = append(uids, e.UID)
+				}
+				if labels, err := client.FetchLabels(mailbox, uids); err == nil {
+					_ = sm.cache.SetLabels(email, mailbox, labels)
+				}
+			}
 		}
 
 		if sm.cache != nil {
@@ -498,6 +1035,109 @@ func (sm *StateManager) Sync(email, mailbox string) error {
 	return syncErr
 }
 
+// reportProgress passes a sync's progress on, if anyone listens
+func (sm *StateManager) reportProgress(email, mailbox, phase string, fetched, total int) {
+	if sm.onSyncProgress != nil {
+		sm.onSyncProgress(email, mailbox, SyncProgress{Phase: phase, Fetched: fetched, Total: total})
+	}
+}
+
+// recordSyncRun stores sync timing and outcome for stats
+func (sm *StateManager) recordSyncRun(email, mailbox string, started time.Time, fetched int, syncErr error) {
+	if sm.cache == nil {
+		return
+	}
+	run := cache.SyncRun{
+		Account:   email,
+		Mailbox:   mailbox,
+		StartedAt: started,
+		Duration:  time.Since(started),
+		Fetched:   fetched,
+	}
+	if syncErr != nil {
+		run.Error = syncErr.Error()
+	}
+	_ = sm.cache.RecordSyncRun(run)
+}
+
+// GetStats returns sync statistics for one account, or all accounts if email is empty
+func (sm *StateManager) GetStats(email string, since time.Time) ([]cache.SyncStats, error) {
+	if sm.cache == nil {
+		return nil, fmt.Errorf("cache unavailable")
+	}
+
+	accounts, err := sm.accountEmails(email)
+	if err != nil {
+		return nil, err
+	}
+
+	stats := make([]cache.SyncStats, 0, len(accounts))
+	for _, acc := range accounts {
+		s, err := sm.cache.GetSyncStats(acc, since)
+		if err != nil {
+			return nil, err
+		}
+		if state, err := sm.getAccountState(acc); err == nil {
+			state.mu.Lock()
+			if state.connStats != nil {
+				conn := state.connStats()
+				s.Connection = &conn
+			}
+			state.mu.Unlock()
+		}
+		stats = append(stats, *s)
+	}
+	return stats, nil
+}
+
+// GetStorage reports mailbox storage usage and the size of the trash for an account, or
+// for all accounts when email is empty. Accounts that can't be reached carry the error.
+func (sm *StateManager) GetStorage(email string) ([]AccountStorage, error) {
+	accounts, err := sm.accountEmails(email)
+	if err != nil {
+		return nil, err
+	}
+
+	storage := make([]AccountStorage, 0, len(accounts))
+	for _, acc := range accounts {
+		st := AccountStorage{Account: acc}
+		err := sm.withIMAPClient(acc, func(client mail.Client) error {
+			quota, err := client.StorageQuota()
+			if err != nil && !errors.Is(err, mail.ErrQuotaUnsupported) {
+				return err
+			}
+			st.Quota = quota
+			if trash, err := client.FindTrashFolder(); err == nil {
+				st.Trash = trash
+				st.TrashCount, _ = client.CountMessages(trash)
+			}
+			return nil
+		})
+		if err != nil {
+			st.Error = err.Error()
+		}
+		storage = append(storage, st)
+	}
+	return storage, nil
+}
+
+// accountEmails returns email after checking it's a known account, or every account when empty
+func (sm *StateManager) accountEmails(email string) ([]string, error) {
+	if email != "" {
+		if _, err := sm.getAccountState(email); err != nil {
+			return nil, err
+		}
+		return []string{email}, nil
+	}
+	sm.mu.RLock()
+	defer sm.mu.RUnlock()
+	var accounts []string
+	for _, acc := range sm.store.Accounts {
+		accounts = append(accounts, acc.Credentials.Email)
+	}
+	return accounts, nil
+}
+
 // emailToCached converts mail.Email to cache.CachedEmail
 func emailToCached(e mail.Email) cache.CachedEmail {
 	attachments := make([]cache.Attachment, len(e.Attachments))
@@ -523,21 +1163,44 @@ func emailToCached(e mail.Email) cache.CachedEmail {
 		Snippet:      e.Snippet,
 		BodyHTML:     e.BodyHTML,
 		Unread:       e.Unread,
+		Answered:     e.Answered,
+		Forwarded:    e.Forwarded,
+		Flagged:      e.Flagged,
+		Size:         e.Size,
 		References:   e.References,
 		Attachments:  attachments,
+
+		ListID:              e.ListID,
+		ListUnsubscribe:     e.ListUnsubscribe,
+		ListUnsubscribePost: e.ListUnsubscribePost,
 	}
 }
 
-// ProcessPendingOps processes all pending operations from the queue
-// Returns the number of successfully processed operations
-func (sm *StateManager) ProcessPendingOps() (processed int, failed int) {
+// ProcessPendingOps processes the queued operations that are due. A failed operation is
+// retried with exponential backoff until it runs out of retries, then set aside for review.
+// Returns the number of operations that succeeded and failed, and the accounts with
+// operations that ran out of retries.
+func (sm *StateManager) ProcessPendingOps() (processed int, failed int, exhausted []string) {
 	if sm.cache == nil {
-		return 0, 0
+		return 0, 0, nil
 	}
 
-	ops, err := sm.cache.GetPendingOps("")
+	ops, err := sm.cache.GetDuePendingOps(time.Now())
 	if err != nil || len(ops) == 0 {
-		return 0, 0
+		return 0, 0, nil
+	}
+
+	cfg, _ := loadConfig()
+	maxRetries, backoff := cfg.PendingOps.RetryPolicy()
+	exhaustedSet := make(map[string]bool)
+	fail := func(op cache.PendingOp, errMsg string) {
+		failed++
+		if op.Retries+1 >= maxRetries {
+			sm.cache.FailPendingOp(op.ID, errMsg)
+			exhaustedSet[op.Account] = true
+			return
+		}
+		sm.cache.UpdatePendingOpError(op.ID, errMsg, time.Now().Add(backoff(op.Retries+1)))
 	}
 
 	// Group ops by account to reuse IMAP connections
@@ -547,12 +1210,15 @@ func (sm *StateManager) ProcessPendingOps() (processed int, failed int) {
 	}
 
 	for account, accountOps := range byAccount {
+		// Leave the ops due without spending a retry until the provider stops throttling
+		if mail.ThrottleBackoff(account) != nil {
+			continue
+		}
 		state, err := sm.getAccountState(account)
 		if err != nil {
 			// Mark all ops for this account as failed
 			for _, op := range accountOps {
-				sm.cache.UpdatePendingOpError(op.ID, err.Error())
-				failed++
+				fail(op, err.Error())
 			}
 			continue
 		}
@@ -562,29 +1228,34 @@ func (sm *StateManager) ProcessPendingOps() (processed int, failed int) {
 		if err != nil {
 			state.imapMu.Unlock()
 			for _, op := range accountOps {
-				sm.cache.UpdatePendingOpError(op.ID, err.Error())
-				failed++
+				fail(op, err.Error())
 			}
 			continue
 		}
 
 		for i, op := range accountOps {
 			var opErr error
-			switch op.Operation {
-			case cache.OpDelete:
-				opErr = client.DeleteMessage(op.UID)
-			case cache.OpMoveTrash:
-				opErr = client.MoveToTrashFromMailbox([]imap.UID{op.UID}, op.Mailbox)
-			case cache.OpMarkRead:
-				opErr = client.MarkAsRead(op.UID)
-			default:
-				opErr = fmt.Errorf("unknown operation: %s", op.Operation)
+			if isFlagOp(op.Operation) {
+				var conflict, serverUnread bool
+				conflict, serverUnread, opErr = applyFlagOp(client, op)
+				if opErr == nil && conflict {
+					// Keep the state set on the other device rather than overwrite it
+					slog.Info("skipped flag change made stale on another device",
+						"account", op.Account, "mailbox", op.Mailbox, "uid", op.UID, "op", op.Operation)
+					sm.cache.RemovePendingOp(op.ID)
+					sm.cache.LogOp(op, cache.StatusConflict, "changed on another device")
+					sm.cache.UpdateEmailFlags(op.Account, op.Mailbox, op.UID, serverUnread)
+					continue
+				}
+			} else {
+				opErr = runPendingOp(client, op)
 			}
-
 			if opErr != nil {
-				sm.cache.UpdatePendingOpError(op.ID, opErr.Error())
+				fail(op, opErr.Error())
 				sm.cache.LogOp(op, cache.StatusFailed, opErr.Error())
-				failed++
+				if mail.ThrottleBackoff(account) != nil {
+					break // the rest waits for the backoff to end
+				}
 
 				if isConnectionError(opErr) {
 					client.Close()
@@ -592,8 +1263,7 @@ func (sm *StateManager) ProcessPendingOps() (processed int, failed int) {
 					client, err = sm.ensureIMAPClientLocked(state)
 					if err != nil {
 						for _, remaining := range accountOps[i+1:] {
-							sm.cache.UpdatePendingOpError(remaining.ID, err.Error())
-							failed++
+							fail(remaining, err.Error())
 						}
 						break
 					}
@@ -604,7 +1274,7 @@ func (sm *StateManager) ProcessPendingOps() (processed int, failed int) {
 			sm.cache.RemovePendingOp(op.ID)
 			sm.cache.LogOp(op, cache.StatusSuccess, "")
 			// Delete from cache again in case sync pulled email back
-			if op.Operation == cache.OpDelete || op.Operation == cache.OpMoveTrash {
+			if !isFlagOp(op.Operation) {
 				sm.cache.DeleteEmail(op.Account, op.Mailbox, op.UID)
 			}
 			processed++
@@ -613,7 +1283,83 @@ func (sm *StateManager) ProcessPendingOps() (processed int, failed int) {
 		state.imapMu.Unlock()
 	}
 
-	return processed, failed
+	for account := range exhaustedSet {
+		exhausted = append(exhausted, account)
+	}
+	return processed, failed, exhausted
+}
+
+// runPendingOp performs a queued operation on the server
+func runPendingOp(client mail.Client, op cache.PendingOp) error {
+	switch op.Operation {
+	case cache.OpDelete:
+		return client.DeleteMessage(op.UID)
+	case cache.OpMoveTrash:
+		return client.MoveToTrashFromMailbox([]imap.UID{op.UID}, op.Mailbox)
+	case cache.OpMoveSpam:
+		return client.MoveToSpam([]imap.UID{op.UID}, op.Mailbox)
+	case cache.OpNotSpam:
+		return client.MoveMessages([]imap.UID{op.UID}, op.Mailbox, mail.INBOX)
+	case cache.OpArchive:
+		return client.ArchiveFromMailbox([]imap.UID{op.UID}, op.Mailbox)
+	default:
+		return fmt.Errorf("unknown operation: %s", op.Operation)
+	}
+}
+
+func isFlagOp(operation string) bool {
+	return operation == cache.OpMarkRead || operation == cache.OpMarkUnread
+}
+
+// applyFlagOp marks an email read or unread. When the op recorded the read state it
+// replaces and the server now has a different one, the email was changed on another
+// device since: the op is skipped and conflict is returned with the server's state.
+func applyFlagOp(client mail.Client, op cache.PendingOp) (conflict, serverUnread bool, err error) {
+	if err := client.SelectMailbox(op.Mailbox); err != nil {
+		return false, false, err
+	}
+	if op.ExpectedState != "" {
+		unread, err := client.IsUnread(op.UID)
+		if err != nil {
+			return false, false, err
+		}
+		state := cache.FlagStateRead
+		if unread {
+			state = cache.FlagStateUnread
+		}
+		if state != op.ExpectedState {
+			return true, unread, nil
+		}
+	}
+	if op.Operation == cache.OpMarkRead {
+		return false, false, client.MarkAsRead(op.UID)
+	}
+	return false, true, client.MarkAsUnread(op.UID)
+}
+
+// GetFailedOps returns the operations of an account that ran out of retries
+func (sm *StateManager) GetFailedOps(email string) ([]cache.PendingOp, error) {
+	if sm.cache == nil {
+		return nil, nil
+	}
+	return sm.cache.GetFailedOps(email)
+}
+
+// RetryFailedOp queues a failed operation again
+func (sm *StateManager) RetryFailedOp(id int64) error {
+	if sm.cache == nil {
+		return nil
+	}
+	return sm.cache.RetryFailedOp(id)
+}
+
+// DiscardFailedOp drops a failed operation. The email was already removed from the
+// cache when the operation was queued, so it reappears on the next sync.
+func (sm *StateManager) DiscardFailedOp(id int64) error {
+	if sm.cache == nil {
+		return nil
+	}
+	return sm.cache.RemovePendingOp(id)
 }
 
 // GetPendingOpsCount returns the number of pending operations
@@ -0,0 +1,249 @@
+package spell
+
+import (
+	"bufio"
+	"bytes"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"golang.org/x/text/encoding"
+	"golang.org/x/text/encoding/charmap"
+)
+
+// affix is one PFX or SFX rule: strip removes text from the word's start or end, add
+// puts text in its place, and the rule only applies when the word matches cond
+type affix struct {
+	strip string
+	add   string
+	cond  *regexp.Regexp // nil matches every word
+	cross bool           // combines with affixes of the other kind
+}
+
+// affixRules holds the parts of a hunspell .aff file used to expand dictionary words.
+// Compounding and morphology aren't supported, so compound-heavy languages such as
+// German flag some valid compounds.
+type affixRules struct {
+	encoding  string
+	flagMode  string // "" for one character per flag, or long, num or UTF-8
+	try       []rune
+	prefixes  map[string][]affix
+	suffixes  map[string][]affix
+	needAffix string // words with this flag are only valid with an affix
+	forbidden string // words with this flag are never valid
+}
+
+// Legacy encodings used by hunspell dictionaries; UTF-8 needs no decoding
+var encodings = map[string]encoding.Encoding{
+	"ISO8859-1":        charmap.ISO8859_1,
+	"ISO8859-2":        charmap.ISO8859_2,
+	"ISO8859-5":        charmap.ISO8859_5,
+	"ISO8859-7":        charmap.ISO8859_7,
+	"ISO8859-13":       charmap.ISO8859_13,
+	"ISO8859-15":       charmap.ISO8859_15,
+	"KOI8-R":           charmap.KOI8R,
+	"KOI8-U":           charmap.KOI8U,
+	"MICROSOFT-CP1251": charmap.Windows1251,
+	"CP1251":           charmap.Windows1251,
+}
+
+func decode(data []byte, name string) string {
+	if enc, ok := encodings[strings.ToUpper(name)]; ok {
+		if decoded, err := enc.NewDecoder().Bytes(data); err == nil {
+			return string(decoded)
+		}
+	}
+	return string(bytes.TrimPrefix(data, []byte("\xef\xbb\xbf")))
+}
+
+func parseAffix(data []byte) (*affixRules, error) {
+	rules := &affixRules{
+		prefixes: map[string][]affix{},
+		suffixes: map[string][]affix{},
+	}
+
+	// The encoding applies to the whole file, so find it before decoding the rest
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		if fields := strings.Fields(scanner.Text()); len(fields) >= 2 && fields[0] == "SET" {
+			rules.encoding = fields[1]
+			break
+		}
+	}
+
+	scanner = bufio.NewScanner(strings.NewReader(decode(data, rules.encoding)))
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	cross := map[string]bool{}
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 2 || strings.HasPrefix(fields[0], "#") {
+			continue
+		}
+		switch fields[0] {
+		case "FLAG":
+			rules.flagMode = fields[1]
+		case "TRY":
+			rules.try = []rune(strings.ToLower(fields[1]))
+		case "NEEDAFFIX":
+			rules.needAffix = fields[1]
+		case "FORBIDDENWORD":
+			rules.forbidden = fields[1]
+		case "PFX", "SFX":
+			if len(fields) == 4 && (fields[2] == "Y" || fields[2] == "N") && isNumber(fields[3]) {
+				cross[fields[0]+fields[1]] = fields[2] == "Y"
+				continue
+			}
+			if len(fields) < 4 {
+				continue
+			}
+			a, ok := parseRule(fields, cross[fields[0]+fields[1]])
+			if !ok {
+				continue
+			}
+			if fields[0] == "PFX" {
+				rules.prefixes[fields[1]] = append(rules.prefixes[fields[1]], a)
+			} else {
+				rules.suffixes[fields[1]] = append(rules.suffixes[fields[1]], a)
+			}
+		}
+	}
+	return rules, scanner.Err()
+}
+
+// parseRule reads "SFX flag strip add condition", where 0 means an empty strip or add
+func parseRule(fields []string, cross bool) (affix, bool) {
+	a := affix{cross: cross}
+	if fields[2] != "0" {
+		a.strip = fields[2]
+	}
+	add, _, _ := strings.Cut(fields[3], "/") // continuation flags aren't supported
+	if add != "0" {
+		a.add = add
+	}
+
+	cond := "."
+	if len(fields) > 4 {
+		cond = fields[4]
+	}
+	if cond != "." {
+		pattern := "(?:" + cond + ")$"
+		if fields[0] == "PFX" {
+			pattern = "^(?:" + cond + ")"
+		}
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return a, false
+		}
+		a.cond = re
+	}
+	return a, true
+}
+
+func (a affix) applySuffix(word string) (string, bool) {
+	if (a.cond != nil && !a.cond.MatchString(word)) || !strings.HasSuffix(word, a.strip) {
+		return "", false
+	}
+	return strings.TrimSuffix(word, a.strip) + a.add, true
+}
+
+func (a affix) applyPrefix(word string) (string, bool) {
+	if (a.cond != nil && !a.cond.MatchString(word)) || !strings.HasPrefix(word, a.strip) {
+		return "", false
+	}
+	return a.add + strings.TrimPrefix(word, a.strip), true
+}
+
+// splitFlags splits a word's flag string according to the FLAG mode
+func (r *affixRules) splitFlags(s string) []string {
+	var flags []string
+	switch r.flagMode {
+	case "long":
+		runes := []rune(s)
+		for i := 0; i+1 < len(runes); i += 2 {
+			flags = append(flags, string(runes[i:i+2]))
+		}
+	case "num":
+		for _, f := range strings.Split(s, ",") {
+			if f = strings.TrimSpace(f); f != "" {
+				flags = append(flags, f)
+			}
+		}
+	default:
+		for _, r := range s {
+			flags = append(flags, string(r))
+		}
+	}
+	return flags
+}
+
+// readDic adds every word in a .dic file along with the forms its affix flags produce
+func (c *Checker) readDic(data string, rules *affixRules) error {
+	scanner := bufio.NewScanner(strings.NewReader(data))
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	first := true
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		// The first line is the word count
+		if first {
+			first = false
+			if isNumber(line) {
+				continue
+			}
+		}
+		line, _, _ = strings.Cut(line, "\t") // morphological fields follow a tab
+		fields := strings.Fields(line)
+		if len(fields) == 0 || strings.HasPrefix(fields[0], "#") {
+			continue
+		}
+		word, flagStr, _ := strings.Cut(fields[0], "/")
+		c.expand(word, rules.splitFlags(flagStr), rules)
+	}
+	return scanner.Err()
+}
+
+func (c *Checker) expand(word string, flags []string, rules *affixRules) {
+	needAffix := false
+	for _, f := range flags {
+		switch f {
+		case rules.forbidden:
+			return
+		case rules.needAffix:
+			needAffix = true
+		}
+	}
+	if !needAffix {
+		c.words[word] = struct{}{}
+	}
+
+	var crossSuffixed []string
+	for _, f := range flags {
+		for _, a := range rules.suffixes[f] {
+			if w, ok := a.applySuffix(word); ok {
+				c.words[w] = struct{}{}
+				if a.cross {
+					crossSuffixed = append(crossSuffixed, w)
+				}
+			}
+		}
+	}
+	for _, f := range flags {
+		for _, a := range rules.prefixes[f] {
+			if w, ok := a.applyPrefix(word); ok {
+				c.words[w] = struct{}{}
+			}
+			if !a.cross {
+				continue
+			}
+			for _, s := range crossSuffixed {
+				if w, ok := a.applyPrefix(s); ok {
+					c.words[w] = struct{}{}
+				}
+			}
+		}
+	}
+}
+
+func isNumber(s string) bool {
+	_, err := strconv.Atoi(s)
+	return err == nil
+}
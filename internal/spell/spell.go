@@ -0,0 +1,438 @@
+package spell
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// ErrNoDictionary is returned when no hunspell dictionary is installed for a language
+var ErrNoDictionary = errors.New("no dictionary found")
+
+// Preferred regional dictionary for each UI language; other regions are used when it's missing
+var preferredDictionaries = map[string]string{
+	"en":    "en_US",
+	"es":    "es_ES",
+	"de":    "de_DE",
+	"fr":    "fr_FR",
+	"pt-BR": "pt_BR",
+	"pl":    "pl_PL",
+	"nl":    "nl_NL",
+	"it":    "it_IT",
+	"ru":    "ru_RU",
+}
+
+// Checker checks words against a hunspell dictionary and a personal word list
+type Checker struct {
+	name     string
+	words    map[string]struct{}
+	try      []rune // letters tried when building suggestions, most common first
+	personal string // personal word list file, empty to keep added words in memory only
+}
+
+// Dirs returns the directories searched for hunspell dictionaries, in order
+func Dirs() []string {
+	var dirs []string
+	if env := os.Getenv("DICPATH"); env != "" {
+		dirs = append(dirs, filepath.SplitList(env)...)
+	}
+	if home, err := os.UserHomeDir(); err == nil {
+		dirs = append(dirs,
+			filepath.Join(home, ".config", "maily", "dictionaries"),
+			filepath.Join(home, ".local", "share", "hunspell"),
+			filepath.Join(home, "Library", "Spelling"),
+		)
+	}
+	return append(dirs,
+		"/usr/share/hunspell",
+		"/usr/local/share/hunspell",
+		"/opt/homebrew/share/hunspell",
+		"/usr/share/myspell",
+		"/usr/share/myspell/dicts",
+		"/Library/Spelling",
+	)
+}
+
+// Find returns the .dic file for a dictionary name such as en_GB, or for a UI language
+// code such as en or pt-BR, preferring the language's main region
+func Find(name string) (string, error) {
+	dirs := Dirs()
+	candidates := []string{name}
+	if preferred, ok := preferredDictionaries[name]; ok {
+		candidates = []string{preferred, name}
+	}
+	candidates = append(candidates, strings.ReplaceAll(name, "-", "_"))
+
+	for _, candidate := range candidates {
+		for _, dir := range dirs {
+			path := filepath.Join(dir, candidate+".dic")
+			if fileExists(path) && fileExists(strings.TrimSuffix(path, ".dic")+".aff") {
+				return path, nil
+			}
+		}
+	}
+
+	// Any region of the language, e.g. en_GB when en_US isn't installed
+	lang := strings.SplitN(strings.ReplaceAll(name, "-", "_"), "_", 2)[0]
+	for _, dir := range dirs {
+		matches, _ := filepath.Glob(filepath.Join(dir, lang+"_*.dic"))
+		sort.Strings(matches)
+		for _, path := range matches {
+			if fileExists(strings.TrimSuffix(path, ".dic") + ".aff") {
+				return path, nil
+			}
+		}
+	}
+	return "", fmt.Errorf("%w for %s", ErrNoDictionary, name)
+}
+
+// Load finds and reads the dictionary for name (see Find), adding the words in the
+// personal word list file, which may not exist yet
+func Load(name, personal string) (*Checker, error) {
+	path, err := Find(name)
+	if err != nil {
+		return nil, err
+	}
+	c, err := LoadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	if err := c.loadPersonal(personal); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// LoadFile reads a hunspell dictionary from its .dic file and the .aff file next to it
+func LoadFile(dicPath string) (*Checker, error) {
+	affPath := strings.TrimSuffix(dicPath, ".dic") + ".aff"
+	affData, err := os.ReadFile(affPath)
+	if err != nil {
+		return nil, err
+	}
+	dicData, err := os.ReadFile(dicPath)
+	if err != nil {
+		return nil, err
+	}
+
+	rules, err := parseAffix(affData)
+	if err != nil {
+		return nil, fmt.Errorf("reading %s: %w", affPath, err)
+	}
+	c := &Checker{
+		name:  strings.TrimSuffix(filepath.Base(dicPath), ".dic"),
+		words: make(map[string]struct{}),
+		try:   rules.try,
+	}
+	if err := c.readDic(decode(dicData, rules.encoding), rules); err != nil {
+		return nil, fmt.Errorf("reading %s: %w", dicPath, err)
+	}
+	if len(c.try) == 0 {
+		c.try = c.alphabet()
+	}
+	return c, nil
+}
+
+// Name returns the dictionary name, e.g. en_US
+func (c *Checker) Name() string {
+	return c.name
+}
+
+// Check reports whether a word is spelled correctly. Words with digits or symbols,
+// single letters and all-caps acronyms are not checked.
+func (c *Checker) Check(word string) bool {
+	word = strings.ReplaceAll(word, "’", "'")
+	if utf8.RuneCountInString(word) < 2 || !isWord(word) || isUpper(word) {
+		return true
+	}
+	if c.known(word) {
+		return true
+	}
+	if stem, ok := strings.CutSuffix(word, "'s"); ok && c.known(stem) {
+		return true
+	}
+	return false
+}
+
+// known looks a word up as written, and lowercased when it's capitalized
+func (c *Checker) known(word string) bool {
+	if _, ok := c.words[word]; ok {
+		return true
+	}
+	if lower := strings.ToLower(word); lower != word && isCapitalized(word) {
+		_, ok := c.words[lower]
+		return ok
+	}
+	return false
+}
+
+// Suggest returns up to limit corrections for a misspelled word, closest first,
+// in the word's capitalization
+func (c *Checker) Suggest(word string, limit int) []string {
+	word = strings.ReplaceAll(word, "’", "'")
+	lower := []rune(strings.ToLower(word))
+	seen := map[string]bool{string(lower): true}
+	var suggestions []string
+
+	consider := func(candidate string) bool {
+		if seen[candidate] {
+			return len(suggestions) < limit
+		}
+		seen[candidate] = true
+		if c.known(candidate) || c.known(capitalize(candidate)) {
+			suggestions = append(suggestions, candidate)
+		}
+		return len(suggestions) < limit
+	}
+
+	edits := c.edits(lower)
+	for _, e := range edits {
+		if !consider(e) {
+			return matchCase(word, suggestions)
+		}
+	}
+
+	// Two words run together, e.g. "thecat"
+	for i := 2; i < len(lower)-1; i++ {
+		left, right := string(lower[:i]), string(lower[i:])
+		if c.known(left) && c.known(right) {
+			suggestions = append(suggestions, left+" "+right)
+			if len(suggestions) >= limit {
+				return matchCase(word, suggestions)
+			}
+		}
+	}
+
+	// Two edits away, only for words short enough to keep this fast
+	if len(suggestions) == 0 && len(lower) <= 8 {
+		for _, e := range edits {
+			for _, e2 := range c.edits([]rune(e)) {
+				if !consider(e2) {
+					return matchCase(word, suggestions)
+				}
+			}
+		}
+	}
+	return matchCase(word, suggestions)
+}
+
+// Add accepts a word from now on, appending it to the personal word list
+func (c *Checker) Add(word string) error {
+	word = strings.TrimSpace(strings.ReplaceAll(word, "’", "'"))
+	if word == "" {
+		return nil
+	}
+	c.words[word] = struct{}{}
+	if c.personal == "" {
+		return nil
+	}
+	if err := os.MkdirAll(filepath.Dir(c.personal), 0755); err != nil {
+		return err
+	}
+	f, err := os.OpenFile(c.personal, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	_, err = fmt.Fprintln(f, word)
+	return err
+}
+
+func (c *Checker) loadPersonal(path string) error {
+	c.personal = path
+	if path == "" {
+		return nil
+	}
+	f, err := os.Open(path)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		if word := strings.TrimSpace(scanner.Text()); word != "" && !strings.HasPrefix(word, "#") {
+			c.words[word] = struct{}{}
+		}
+	}
+	return scanner.Err()
+}
+
+// edits returns the words one edit away: swapped neighbours, replaced, removed and
+// inserted letters, in that order since it roughly follows how common each typo is
+func (c *Checker) edits(word []rune) []string {
+	var out []string
+	for i := 0; i < len(word)-1; i++ {
+		w := append([]rune{}, word...)
+		w[i], w[i+1] = w[i+1], w[i]
+		out = append(out, string(w))
+	}
+	for i := range word {
+		for _, r := range c.try {
+			if r != word[i] {
+				w := append([]rune{}, word...)
+				w[i] = r
+				out = append(out, string(w))
+			}
+		}
+	}
+	for i := range word {
+		out = append(out, string(word[:i])+string(word[i+1:]))
+	}
+	for i := 0; i <= len(word); i++ {
+		for _, r := range c.try {
+			out = append(out, string(word[:i])+string(r)+string(word[i:]))
+		}
+	}
+	return out
+}
+
+// alphabet collects the lowercase letters used by the dictionary, most common first
+func (c *Checker) alphabet() []rune {
+	counts := map[rune]int{}
+	for w := range c.words {
+		for _, r := range w {
+			if unicode.IsLetter(r) {
+				counts[unicode.ToLower(r)]++
+			}
+		}
+	}
+	letters := make([]rune, 0, len(counts))
+	for r := range counts {
+		letters = append(letters, r)
+	}
+	sort.Slice(letters, func(i, j int) bool {
+		if counts[letters[i]] != counts[letters[j]] {
+			return counts[letters[i]] > counts[letters[j]]
+		}
+		return letters[i] < letters[j]
+	})
+	return letters
+}
+
+// WordAt returns the [start, end) rune bounds of the word at or just before col in line
+func WordAt(line []rune, col int) (int, int, bool) {
+	col = min(max(col, 0), len(line))
+	start, end := col, col
+	for start > 0 && isWordRune(line, start-1) {
+		start--
+	}
+	for end < len(line) && isWordRune(line, end) {
+		end++
+	}
+	if start == end {
+		return 0, 0, false
+	}
+	// Apostrophes only count inside a word
+	for start < end && isApostrophe(line[start]) {
+		start++
+	}
+	for end > start && isApostrophe(line[end-1]) {
+		end--
+	}
+	return start, end, start < end
+}
+
+// Words returns the [start, end) rune bounds of the words in text that should be spell
+// checked. Tokens with digits or symbols, such as URLs and addresses, are skipped, and
+// hyphenated words are checked part by part.
+func Words(text []rune) [][2]int {
+	var ranges [][2]int
+	for i := 0; i < len(text); {
+		if unicode.IsSpace(text[i]) {
+			i++
+			continue
+		}
+		start := i
+		for i < len(text) && !unicode.IsSpace(text[i]) {
+			i++
+		}
+		end := i
+
+		// Trim surrounding punctuation such as quotes, brackets and full stops
+		for start < end && !unicode.IsLetter(text[start]) {
+			start++
+		}
+		for end > start && !unicode.IsLetter(text[end-1]) {
+			end--
+		}
+		if start == end || !isWord(string(text[start:end])) {
+			continue
+		}
+		part := start
+		for j := start; j <= end; j++ {
+			if j == end || text[j] == '-' {
+				if j > part {
+					ranges = append(ranges, [2]int{part, j})
+				}
+				part = j + 1
+			}
+		}
+	}
+	return ranges
+}
+
+func isWordRune(line []rune, i int) bool {
+	return unicode.IsLetter(line[i]) || unicode.Is(unicode.Mn, line[i]) || isApostrophe(line[i])
+}
+
+func isApostrophe(r rune) bool {
+	return r == '\'' || r == '’'
+}
+
+// isWord reports whether s holds only letters, combining marks, apostrophes and hyphens
+func isWord(s string) bool {
+	for _, r := range s {
+		if !unicode.IsLetter(r) && !unicode.Is(unicode.Mn, r) && !isApostrophe(r) && r != '-' {
+			return false
+		}
+	}
+	return true
+}
+
+func isUpper(s string) bool {
+	for _, r := range s {
+		if unicode.IsLetter(r) && !unicode.IsUpper(r) {
+			return false
+		}
+	}
+	return true
+}
+
+func isCapitalized(s string) bool {
+	r, size := utf8.DecodeRuneInString(s)
+	return unicode.IsUpper(r) && !strings.ContainsFunc(s[size:], unicode.IsUpper)
+}
+
+func capitalize(s string) string {
+	r, size := utf8.DecodeRuneInString(s)
+	return string(unicode.ToUpper(r)) + s[size:]
+}
+
+// matchCase capitalizes suggestions like the misspelled word
+func matchCase(word string, suggestions []string) []string {
+	switch {
+	case utf8.RuneCountInString(word) > 1 && isUpper(word):
+		for i, s := range suggestions {
+			suggestions[i] = strings.ToUpper(s)
+		}
+	case isCapitalized(word):
+		for i, s := range suggestions {
+			suggestions[i] = capitalize(s)
+		}
+	}
+	return suggestions
+}
+
+func fileExists(path string) bool {
+	info, err := os.Stat(path)
+	return err == nil && !info.IsDir()
+}
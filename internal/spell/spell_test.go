@@ -0,0 +1,138 @@
+package spell
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+const testAff = `SET UTF-8
+TRY esianrtolcdugmphbyfvkwzESIANRTOLCDUGMPHBYFVKWZ'
+
+PFX U Y 1
+PFX U   0     un         .
+
+SFX S Y 2
+SFX S   y     ies        [^aeiou]y
+SFX S   0     s          [aeiou]y
+
+SFX D Y 2
+SFX D   0     d          e
+SFX D   0     ed         [^e]
+`
+
+const testDic = `6
+reply/SD
+happy/U
+day/S
+hello
+Paris
+like/DU
+`
+
+func loadTestChecker(t *testing.T) *Checker {
+	t.Helper()
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "xx_TEST.aff"), []byte(testAff), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "xx_TEST.dic"), []byte(testDic), 0644); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("DICPATH", dir)
+	c, err := Load("xx", filepath.Join(dir, "personal.txt"))
+	if err != nil {
+		t.Fatalf("Load() error: %v", err)
+	}
+	return c
+}
+
+func TestCheck(t *testing.T) {
+	c := loadTestChecker(t)
+	if c.Name() != "xx_TEST" {
+		t.Errorf("Name() = %q, want xx_TEST", c.Name())
+	}
+
+	tests := []struct {
+		word string
+		want bool
+	}{
+		{"reply", true},
+		{"replies", true},  // SFX S strips y
+		{"replied", false}, // SFX D needs a condition that doesn't match
+		{"days", true},
+		{"unhappy", true}, // PFX U
+		{"unliked", true}, // PFX and SFX cross product
+		{"Hello", true},   // capitalized at the start of a sentence
+		{"paris", false},  // proper nouns keep their capital
+		{"Paris's", true},
+		{"helo", false},
+		{"NASA", true}, // acronyms aren't checked
+		{"a", true},
+	}
+	for _, tt := range tests {
+		if got := c.Check(tt.word); got != tt.want {
+			t.Errorf("Check(%q) = %v, want %v", tt.word, got, tt.want)
+		}
+	}
+}
+
+func TestSuggest(t *testing.T) {
+	c := loadTestChecker(t)
+
+	if got := c.Suggest("helo", 3); !reflect.DeepEqual(got, []string{"hello"}) {
+		t.Errorf("Suggest(helo) = %v, want [hello]", got)
+	}
+	if got := c.Suggest("Rpely", 3); len(got) == 0 || got[0] != "Reply" {
+		t.Errorf("Suggest(Rpely) = %v, want Reply first", got)
+	}
+	if got := c.Suggest("helloday", 3); !reflect.DeepEqual(got, []string{"hello day"}) {
+		t.Errorf("Suggest(helloday) = %v, want [hello day]", got)
+	}
+}
+
+func TestAdd(t *testing.T) {
+	c := loadTestChecker(t)
+	if err := c.Add("maily"); err != nil {
+		t.Fatalf("Add() error: %v", err)
+	}
+	if !c.Check("maily") {
+		t.Errorf("Check(maily) = false after Add")
+	}
+
+	// The word is kept in the personal list for the next load
+	again, err := Load("xx", c.personal)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !again.Check("maily") {
+		t.Errorf("personal word not loaded")
+	}
+}
+
+func TestWords(t *testing.T) {
+	text := []rune(`"Hello," see https://x.io or me@x.io - well-known 42 don't`)
+	var got []string
+	for _, r := range Words(text) {
+		got = append(got, string(text[r[0]:r[1]]))
+	}
+	want := []string{"Hello", "see", "or", "well", "known", "don't"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Words() = %q, want %q", got, want)
+	}
+}
+
+func TestWordAt(t *testing.T) {
+	line := []rune("say 'helo' now")
+	start, end, ok := WordAt(line, 9)
+	if !ok || string(line[start:end]) != "helo" {
+		t.Errorf("WordAt() = %q, %v, want helo", string(line[start:end]), ok)
+	}
+	if _, _, ok := WordAt(line, 3); !ok {
+		t.Errorf("WordAt() right after a word should find it")
+	}
+	if _, _, ok := WordAt([]rune("a  b"), 2); ok {
+		t.Errorf("WordAt() between spaces should find nothing")
+	}
+}
@@ -26,7 +26,8 @@ type Syncer struct {
 
 type imapClient interface {
 	SelectMailboxWithInfo(string) (*mail.MailboxInfo, error)
-	FetchUIDsAndFlags(string, time.Time) (map[imap.UID]bool, error)
+	FetchUIDsAndFlags(string, time.Time) (map[imap.UID]mail.Flags, error)
+	FetchAllUIDs(string) (map[imap.UID]bool, error)
 	FetchMessagesByUIDs(string, []imap.UID) ([]mail.Email, error)
 	FetchMessages(string, uint32) ([]mail.Email, error)
 	Close() error
@@ -35,7 +36,7 @@ type imapClient interface {
 type imapClientFactory func(*auth.Credentials) (imapClient, error)
 
 var newIMAPClient imapClientFactory = func(creds *auth.Credentials) (imapClient, error) {
-	return mail.NewIMAPClient(creds)
+	return mail.NewClient(creds)
 }
 
 // NewSyncer creates a new syncer for an account
@@ -108,13 +109,11 @@ func (s *Syncer) FullSync(mailbox string) error {
 		}
 	}
 
-	// Find deleted UIDs (in cache but not on server)
-	for uid := range cachedUIDs {
-		if _, ok := serverUIDs[uid]; !ok {
-			if err := s.cache.DeleteEmail(email, mailbox, uid); err != nil {
-				// Log but don't fail
-				continue
-			}
+	// Find deleted UIDs (in cache but not on server), however old they are
+	for uid := range deletedOnServer(client, mailbox, cachedUIDs, serverUIDs) {
+		if err := s.cache.DeleteEmail(email, mailbox, uid); err != nil {
+			// Log but don't fail
+			continue
 		}
 	}
 
@@ -125,30 +124,32 @@ func (s *Syncer) FullSync(mailbox string) error {
 			return fmt.Errorf("failed to fetch new emails: %w", err)
 		}
 
-		for _, e := range emails {
-			cached := emailToCached(e)
-			if err := s.cache.SaveEmail(email, mailbox, cached); err != nil {
-				// Log but don't fail
-				continue
-			}
+		cached := make([]cache.CachedEmail, len(emails))
+		for i, e := range emails {
+			cached[i] = emailToCached(e)
+		}
+		if _, err := s.cache.SaveEmailsBatch(email, mailbox, cached, true); err != nil {
+			return fmt.Errorf("failed to save new emails: %w", err)
 		}
 	}
 
 	// Update flags for existing emails
-	for uid, unread := range serverUIDs {
+	for uid, flags := range serverUIDs {
 		if cachedUIDs[uid] {
 			// Check if flag changed
-			if err := s.cache.UpdateEmailFlags(email, mailbox, uid, unread); err != nil {
+			if err := s.cache.UpdateEmailFlags(email, mailbox, uid, flags.Unread); err != nil {
 				// Log but don't fail
 				continue
 			}
+			s.cache.UpdateEmailMarks(email, mailbox, cache.CachedEmail{
+				UID:       uid,
+				Answered:  flags.Answered,
+				Forwarded: flags.Forwarded,
+				Flagged:   flags.Flagged,
+			})
 		}
 	}
 
-	// Cleanup old emails
-	olderThan := time.Now().AddDate(0, 0, -SyncDays)
-	s.cache.Cleanup(email, mailbox, olderThan)
-
 	// Update metadata
 	newMeta := &cache.Metadata{
 		UIDValidity: info.UIDValidity,
@@ -161,6 +162,50 @@ func (s *Syncer) FullSync(mailbox string) error {
 	return nil
 }
 
+// deletedOnServer returns the cached UIDs the server no longer has. Cached UIDs below
+// the synced window (the keys of windowUIDs), such as older pages loaded with l, are
+// checked against every UID in the mailbox; that list is only fetched when there are
+// some. When it can't be fetched, only the window is checked, as UIDs below it may
+// just be older.
+func deletedOnServer(client imapClient, mailbox string, cachedUIDs map[imap.UID]bool, windowUIDs map[imap.UID]mail.Flags) map[imap.UID]bool {
+	deleted := make(map[imap.UID]bool)
+	windowStart := imap.UID(0)
+	for uid := range windowUIDs {
+		if windowStart == 0 || uid < windowStart {
+			windowStart = uid
+		}
+	}
+
+	if belowWindow(cachedUIDs, windowStart) {
+		if all, err := client.FetchAllUIDs(mailbox); err == nil {
+			for uid := range cachedUIDs {
+				if !all[uid] {
+					deleted[uid] = true
+				}
+			}
+			return deleted
+		}
+	}
+
+	for uid := range cachedUIDs {
+		if _, ok := windowUIDs[uid]; !ok && windowStart != 0 && uid >= windowStart {
+			deleted[uid] = true
+		}
+	}
+	return deleted
+}
+
+// belowWindow reports whether any cached UID is older than the synced window starting
+// at windowStart, 0 for an empty window
+func belowWindow(cachedUIDs map[imap.UID]bool, windowStart imap.UID) bool {
+	for uid := range cachedUIDs {
+		if windowStart == 0 || uid < windowStart {
+			return true
+		}
+	}
+	return false
+}
+
 // QuickRefresh fetches the latest 50 emails only
 func (s *Syncer) QuickRefresh(mailbox string) error {
 	email := s.account.Credentials.Email
@@ -189,12 +234,12 @@ func (s *Syncer) QuickRefresh(mailbox string) error {
 	}
 
 	// Save to cache
-	for _, e := range emails {
-		cached := emailToCached(e)
-		if err := s.cache.SaveEmail(email, mailbox, cached); err != nil {
-			// Log but don't fail
-			continue
-		}
+	cached := make([]cache.CachedEmail, len(emails))
+	for i, e := range emails {
+		cached[i] = emailToCached(e)
+	}
+	if _, err := s.cache.SaveEmailsBatch(email, mailbox, cached, true); err != nil {
+		return fmt.Errorf("failed to save emails: %w", err)
 	}
 
 	// Update metadata
@@ -236,7 +281,15 @@ func emailToCached(e mail.Email) cache.CachedEmail {
 		Snippet:      e.Snippet,
 		BodyHTML:     e.BodyHTML,
 		Unread:       e.Unread,
+		Answered:     e.Answered,
+		Forwarded:    e.Forwarded,
+		Flagged:      e.Flagged,
+		Size:         e.Size,
 		References:   e.References,
 		Attachments:  attachments,
+
+		ListID:              e.ListID,
+		ListUnsubscribe:     e.ListUnsubscribe,
+		ListUnsubscribePost: e.ListUnsubscribePost,
 	}
 }
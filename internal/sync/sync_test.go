@@ -14,7 +14,9 @@ import (
 
 type fakeIMAPClient struct {
 	mailboxInfo    *mail.MailboxInfo
-	uidFlags       map[imap.UID]bool
+	uidFlags       map[imap.UID]mail.Flags
+	allUIDs        map[imap.UID]bool // nil fails FetchAllUIDs
+	allUIDsCalls   int
 	messagesByUID  map[imap.UID]mail.Email
 	latestMessages []mail.Email
 }
@@ -23,10 +25,18 @@ func (f *fakeIMAPClient) SelectMailboxWithInfo(string) (*mail.MailboxInfo, error
 	return f.mailboxInfo, nil
 }
 
-func (f *fakeIMAPClient) FetchUIDsAndFlags(string, time.Time) (map[imap.UID]bool, error) {
+func (f *fakeIMAPClient) FetchUIDsAndFlags(string, time.Time) (map[imap.UID]mail.Flags, error) {
 	return f.uidFlags, nil
 }
 
+func (f *fakeIMAPClient) FetchAllUIDs(string) (map[imap.UID]bool, error) {
+	f.allUIDsCalls++
+	if f.allUIDs == nil {
+		return nil, fmt.Errorf("search failed")
+	}
+	return f.allUIDs, nil
+}
+
 func (f *fakeIMAPClient) FetchMessagesByUIDs(_ string, uids []imap.UID) ([]mail.Email, error) {
 	emails := make([]mail.Email, 0, len(uids))
 	for _, uid := range uids {
@@ -90,9 +100,9 @@ func TestFullSyncUpdatesCache(t *testing.T) {
 
 	fake := &fakeIMAPClient{
 		mailboxInfo: &mail.MailboxInfo{UIDValidity: 123},
-		uidFlags: map[imap.UID]bool{
-			imap.UID(1): false,
-			imap.UID(3): true,
+		uidFlags: map[imap.UID]mail.Flags{
+			imap.UID(1): {Answered: true, Flagged: true},
+			imap.UID(3): {Unread: true},
 		},
 		messagesByUID: map[imap.UID]mail.Email{
 			imap.UID(3): {
@@ -128,6 +138,9 @@ func TestFullSyncUpdatesCache(t *testing.T) {
 	if email1 == nil || email1.Unread {
 		t.Fatalf("expected UID 1 to be marked read, got %#v", email1)
 	}
+	if !email1.Answered || !email1.Flagged || email1.Forwarded {
+		t.Fatalf("expected UID 1 to be answered and flagged, got %#v", email1)
+	}
 
 	email2, err := c.GetEmail(accountEmail, mailbox, imap.UID(2))
 	if err != nil {
@@ -153,3 +166,155 @@ func TestFullSyncUpdatesCache(t *testing.T) {
 		t.Fatalf("expected UIDValidity 123, got %#v", meta)
 	}
 }
+
+func TestFullSyncReconcilesOlderEmails(t *testing.T) {
+	setTempHome(t)
+
+	c, err := cache.New()
+	if err != nil {
+		t.Fatalf("New() error: %v", err)
+	}
+
+	account := &auth.Account{Credentials: auth.Credentials{Email: "user@example.com"}}
+	accountEmail := account.Credentials.Email
+	mailbox := "INBOX"
+	now := time.Now()
+
+	// UID 6 is below the synced window and was deleted on the server; UID 5 is still
+	// there but older than the window kept in the cache
+	for _, e := range []cache.CachedEmail{
+		{UID: 5, InternalDate: now.AddDate(-1, 0, 0), Subject: "older page"},
+		{UID: 6, InternalDate: now.AddDate(0, 0, -2), Subject: "deleted below the window"},
+		{UID: 20, InternalDate: now.Add(-time.Hour), Subject: "recent"},
+	} {
+		if err := c.SaveEmail(accountEmail, mailbox, e); err != nil {
+			t.Fatalf("SaveEmail UID %d error: %v", e.UID, err)
+		}
+	}
+
+	fake := &fakeIMAPClient{
+		mailboxInfo: &mail.MailboxInfo{UIDValidity: 1},
+		uidFlags:    map[imap.UID]mail.Flags{imap.UID(20): {}},
+		allUIDs:     map[imap.UID]bool{imap.UID(5): true, imap.UID(20): true},
+	}
+	originalFactory := newIMAPClient
+	newIMAPClient = func(*auth.Credentials) (imapClient, error) {
+		return fake, nil
+	}
+	defer func() {
+		newIMAPClient = originalFactory
+	}()
+
+	if err := NewSyncer(c, account).FullSync(mailbox); err != nil {
+		t.Fatalf("FullSync error: %v", err)
+	}
+
+	uids, err := c.GetCachedUIDs(accountEmail, mailbox)
+	if err != nil {
+		t.Fatalf("GetCachedUIDs error: %v", err)
+	}
+	if len(uids) != 2 || !uids[5] || !uids[20] {
+		t.Fatalf("expected UIDs 5 and 20 to stay cached and only UID 6 to be removed, got %v", uids)
+	}
+}
+
+func TestFullSyncListsUIDsOnlyWithOlderEmails(t *testing.T) {
+	setTempHome(t)
+
+	c, err := cache.New()
+	if err != nil {
+		t.Fatalf("New() error: %v", err)
+	}
+
+	account := &auth.Account{Credentials: auth.Credentials{Email: "user@example.com"}}
+	accountEmail := account.Credentials.Email
+	mailbox := "INBOX"
+	now := time.Now()
+
+	// Everything cached is in the synced window, so the window alone shows what's gone
+	for _, e := range []cache.CachedEmail{
+		{UID: 20, InternalDate: now.Add(-time.Hour), Subject: "recent"},
+		{UID: 21, InternalDate: now.Add(-time.Hour), Subject: "deleted in the window"},
+	} {
+		if err := c.SaveEmail(accountEmail, mailbox, e); err != nil {
+			t.Fatalf("SaveEmail UID %d error: %v", e.UID, err)
+		}
+	}
+
+	fake := &fakeIMAPClient{
+		mailboxInfo: &mail.MailboxInfo{UIDValidity: 1},
+		uidFlags:    map[imap.UID]mail.Flags{imap.UID(20): {}},
+		allUIDs:     map[imap.UID]bool{imap.UID(20): true},
+	}
+	originalFactory := newIMAPClient
+	newIMAPClient = func(*auth.Credentials) (imapClient, error) {
+		return fake, nil
+	}
+	defer func() {
+		newIMAPClient = originalFactory
+	}()
+
+	if err := NewSyncer(c, account).FullSync(mailbox); err != nil {
+		t.Fatalf("FullSync error: %v", err)
+	}
+	if fake.allUIDsCalls != 0 {
+		t.Errorf("FetchAllUIDs called %d times, want none without older cached emails", fake.allUIDsCalls)
+	}
+
+	uids, err := c.GetCachedUIDs(accountEmail, mailbox)
+	if err != nil {
+		t.Fatalf("GetCachedUIDs error: %v", err)
+	}
+	if len(uids) != 1 || !uids[20] {
+		t.Fatalf("expected only UID 20 to stay cached, got %v", uids)
+	}
+}
+
+func TestFullSyncFallsBackToWindow(t *testing.T) {
+	setTempHome(t)
+
+	c, err := cache.New()
+	if err != nil {
+		t.Fatalf("New() error: %v", err)
+	}
+
+	account := &auth.Account{Credentials: auth.Credentials{Email: "user@example.com"}}
+	accountEmail := account.Credentials.Email
+	mailbox := "INBOX"
+	now := time.Now()
+
+	// Without the mailbox's full UID list, UID 6 below the window may just be older
+	for _, e := range []cache.CachedEmail{
+		{UID: 6, InternalDate: now.AddDate(0, 0, -2), Subject: "below the window"},
+		{UID: 20, InternalDate: now.Add(-time.Hour), Subject: "recent"},
+		{UID: 21, InternalDate: now.Add(-time.Hour), Subject: "deleted in the window"},
+	} {
+		if err := c.SaveEmail(accountEmail, mailbox, e); err != nil {
+			t.Fatalf("SaveEmail UID %d error: %v", e.UID, err)
+		}
+	}
+
+	fake := &fakeIMAPClient{
+		mailboxInfo: &mail.MailboxInfo{UIDValidity: 1},
+		uidFlags:    map[imap.UID]mail.Flags{imap.UID(20): {}},
+	}
+	originalFactory := newIMAPClient
+	newIMAPClient = func(*auth.Credentials) (imapClient, error) {
+		return fake, nil
+	}
+	defer func() {
+		newIMAPClient = originalFactory
+	}()
+
+	if err := NewSyncer(c, account).FullSync(mailbox); err != nil {
+		t.Fatalf("FullSync error: %v", err)
+	}
+
+	uids, err := c.GetCachedUIDs(accountEmail, mailbox)
+	if err != nil {
+		t.Fatalf("GetCachedUIDs error: %v", err)
+	}
+	if len(uids) != 2 || !uids[6] || !uids[20] {
+		t.Fatalf("expected UIDs 6 and 20 to stay cached, got %v", uids)
+	}
+}
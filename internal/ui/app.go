@@ -1,7 +1,10 @@
 package ui
 
 import (
+	"errors"
 	"fmt"
+	"os"
+	"path/filepath"
 	"strings"
 	"time"
 
@@ -18,8 +21,12 @@ import (
 	"maily/internal/cache"
 	"maily/internal/calendar"
 	"maily/internal/client"
+	"maily/internal/contacts"
+	"maily/internal/htmlbody"
 	"maily/internal/i18n"
 	"maily/internal/mail"
+	"maily/internal/server"
+	"maily/internal/spell"
 	"maily/internal/ui/components"
 )
 
@@ -56,13 +63,23 @@ type App struct {
 	errAccountEmail string // which account the error belongs to
 	statusMsg       string
 	confirmDelete   bool
+	confirmEmpty    bool
 	deleteOption    components.DeleteOption // selected option in delete dialog
-	emailLimit      uint32
+	emailLimit      uint32 // size of the loaded window of emails
+	pageOffset      int    // position of the first loaded email in the mailbox
+	mailboxTotal    int    // emails in the mailbox, for paging
+	paging          bool   // a page fetch is in flight
 
 	// Labels
 	labelPicker     components.LabelPicker
 	currentLabel    string // current mailbox/label being viewed
 	showLabelPicker bool   // showing label picker view
+	labelEditor     components.LabelEditor
+	showLabelEditor bool // editing the Gmail labels of the open email
+	// Sent, trash and spam folders of the current account, by kind, once opened
+	specialFolders  map[string]string
+	newsletters     bool   // showing the newsletters virtual folder (mailing list mail only)
+	smartFolder     *config.SmartFolder // showing a smart folder, a saved filter of currentLabel
 
 	// Search
 	searchInput    textinput.Model
@@ -74,11 +91,66 @@ type App struct {
 	// Multi-select (search mode only)
 	selected map[imap.UID]bool
 
+	// Find within the opened email
+	finder bodyFinder
+
+	// Go to date bar (list view)
+	jumper dateJumper
+
+	// Quick reply bar (list and read views)
+	replier quickReplier
+
+	// Quoted history in the opened email is expanded (z)
+	showQuoted bool
+
+	// Raw source of the opened email shown instead of its body (V)
+	showRaw   bool
+	rawSource []byte
+
+	// SPF/DKIM/DMARC results of the opened email, with the details popup (H)
+	authResults     *mail.AuthResults
+	showAuthDetails bool
+	// Results already fetched, so reopening an email doesn't fetch its headers again
+	authCache map[authKey]*mail.AuthResults
+
+	// Links in the opened email, listed and confirmed before opening (o)
+	links linkOpener
+
+	// Waiting for the field to copy after y
+	copyPending bool
+
+	// Server operations run in the background, shown in the status bar
+	jobs jobTracker
+
+	// Preview of the highlighted email beside or below the list (v)
+	showPreview bool
+	preview     *previewCache
+
+	// Unread inbox emails per account, from the disk cache, for the header tabs
+	unread map[string]int
+
+	// Where each account was left, reopened at startup and on switching accounts
+	session session
+	restore *accountPlace // the cursor to restore once the folder's emails are loaded
+
+	// Emails that arrived after newSince are marked new in viewedFolder (account and mailbox)
+	newSince     time.Time
+	viewedFolder string
+
 	// Scroll throttling (count-based)
 	scrollCount int
+	clicks      clickTracker // double clicks on list rows
 
 	// Reply/Compose
-	compose ComposeModel
+	compose     ComposeModel
+	drafts      []ComposeModel     // emails set aside with esc while browsing, resumed with C
+	showDrafts  bool               // picking which set aside email to resume
+	draftIdx    int
+	dropPending bool               // waiting for y to discard the highlighted set aside email
+	composeOnly bool               // launched from `maily compose`; quit once the email is sent or cancelled
+	contacts    []contacts.Contact // address book for recipient autocomplete
+	speller     *spell.Checker     // compose body spell checker, nil when off, not loaded yet or no dictionary
+	spellAsked  bool               // the spell checker is loaded, or loading, since compose first opened
 
 	// Command palette
 	commandPalette     components.CommandPalette
@@ -92,6 +164,7 @@ type App struct {
 	summaryViewport viewport.Model
 	showAISetup     bool // show AI setup confirmation dialog
 	LaunchConfigUI  bool // signal to launch config TUI after exit
+	ReauthEmail     string // signal to re-enter the password for this account after exit
 
 	// Extract
 	showExtract       bool
@@ -99,11 +172,12 @@ type App struct {
 	extractedStart    time.Time
 	extractedEnd      time.Time
 	extractedProvider string // which AI provider was used
+	extractSlot       slotCheck // events already at the extracted time
 
 	// Extract edit form
 	showExtractEdit      bool
 	extractEditTitle     textinput.Model
-	extractEditDate      textinput.Model
+	extractEditDate      components.DatePicker
 	extractEditStart     textinput.Model
 	extractEditEnd       textinput.Model
 	extractEditLocation  textinput.Model
@@ -122,15 +196,56 @@ type App struct {
 	showAttachmentPicker bool
 	attachmentIdx        int
 
+	// Emoji picker (for the compose subject and body)
+	showEmojiPicker bool
+	emojiPicker     components.EmojiPicker
+
 	// File picker (for compose attachments)
 	showFilePicker bool
 	filePicker     components.FilePicker
+	attachDir      string // where the compose file picker opens: the last attachment's directory
+
+	// Sync stats panel
+	showStats bool
+	stats     []components.StatsInfo
+
+	// Storage report
+	showStorage bool
+	storage     []components.StorageInfo
+
+	// Server sync events (keyed by account email)
+	syncStatus    map[string]accountSyncStatus
+	showSyncError bool
+
+	// Review of queued operations that ran out of retries
+	opsReview     components.OpsReview
+	showOpsReview bool
+
+	// Cleanup wizard grouping the folder's mail by sender and mailing list
+	cleanup     components.CleanupWizard
+	showCleanup bool
+
+	// Actions of installed plugins, by command palette name
+	pluginActions map[string]pluginAction
+}
+
+// accountSyncStatus tracks sync state reported by the server for one account
+type accountSyncStatus struct {
+	syncing   bool
+	lastSync  time.Time
+	err       string
+	failedOps int                  // queued operations that ran out of retries
+	labels    bool                 // the server has Gmail labels or notmuch tags
+	progress  *server.SyncProgress // how far the running sync has got, nil before it reports
 }
 
 type emailsLoadedMsg struct {
 	emails       []mail.Email
+	offset       int    // position of the first email in the mailbox
+	total        int    // emails in the whole mailbox
 	accountEmail string // which account this belongs to
 	uidValidity  uint32 // for cache consistency with daemon
+	keepCursor   bool   // a refresh of the shown folder: the cursor stays on its email
 }
 
 type errorMsg struct {
@@ -149,7 +264,9 @@ type labelsLoadedMsg struct {
 	accountEmail string // which account this belongs to
 }
 
-type replySentMsg struct{}
+type replySentMsg struct {
+	original imap.UID // the email replied to, 0 for a new message
+}
 
 type replySendErrorMsg struct {
 	err error
@@ -186,6 +303,8 @@ type calendarEventErrorMsg struct {
 
 type cachedEmailsLoadedMsg struct {
 	emails       []mail.Email
+	offset       int    // position of the first email in the mailbox
+	total        int    // emails in the whole mailbox
 	accountEmail string // which account this belongs to
 }
 
@@ -197,17 +316,112 @@ type markUnreadCompleteMsg struct {
 	uid imap.UID
 }
 
+type spamReportedMsg struct {
+	uid     imap.UID
+	notSpam bool
+}
+
+type labelsUpdatedMsg struct {
+	uid    imap.UID
+	labels []string // the email's labels after the change
+	err    error
+}
+
+// notmuchTagsMsg carries a notmuch database's tags, to open the label editor with
+type notmuchTagsMsg struct {
+	tags    []string
+	current []string // the email's tags
+	err     error
+}
+
+type failedOpsLoadedMsg struct {
+	ops []cache.PendingOp
+	err error
+}
+
+type opResolvedMsg struct {
+	id      int64
+	retried bool // false when discarded
+	err     error
+}
+
+type specialFolderMsg struct {
+	kind         string // mail.FolderSent, mail.FolderTrash or mail.FolderSpam
+	folder       string
+	accountEmail string
+	err          error
+}
+
+type restoredMsg struct {
+	uid     imap.UID
+	mailbox string // folder the email was moved back to
+	err     error
+}
+
+type trashEmptiedMsg struct {
+	count int
+	err   error
+}
+
+type unsubscribeDoneMsg struct {
+	err error
+}
+
+type configSavedMsg struct {
+	err error
+}
+
+// threadRuleSetMsg reports a thread muted, followed, or the rule undone
+type threadRuleSetMsg struct {
+	rule string // cache.ThreadMute or cache.ThreadFollow
+	set  bool   // false when the thread already had the rule and it was undone
+	err  error
+}
+
 type autoRefreshTickMsg struct{}
 
 type attachmentDownloadedMsg struct {
-	filename string
-	path     string
+	filename  string
+	path      string
+	chosenDir bool // saved to a directory picked by the user rather than ~/Downloads/maily
 }
 
 type attachmentDownloadErrorMsg struct {
 	err error
 }
 
+type printedMsg struct {
+	path string
+	err  error
+}
+
+type rawSourceLoadedMsg struct {
+	uid imap.UID
+	raw []byte
+	err error
+}
+
+// authKey names an email in the auth results cache
+type authKey struct {
+	account, mailbox string
+	uid              imap.UID
+}
+
+type authResultsLoadedMsg struct {
+	key     authKey
+	results *mail.AuthResults
+	err     error
+}
+
+type unreadCountsLoadedMsg struct {
+	counts map[string]int
+}
+
+type rawSavedMsg struct {
+	path string
+	err  error
+}
+
 type emailBodyLoadedMsg struct {
 	uid          imap.UID
 	bodyHTML     string
@@ -223,6 +437,30 @@ type emailBodyErrorMsg struct {
 	mailbox      string
 }
 
+type serverEventMsg struct {
+	event server.Event
+}
+
+type statsLoadedMsg struct {
+	stats []cache.SyncStats
+	err   error
+}
+
+type storageLoadedMsg struct {
+	storage []server.AccountStorage
+	err     error
+}
+
+type profileSwitchedMsg struct {
+	cfg   config.Config
+	store *auth.AccountStore
+	err   error
+}
+
+type syncStatusLoadedMsg struct {
+	accounts []server.AccountInfo
+}
+
 type serverRefreshCompleteMsg struct {
 	emails       []mail.Email
 	accountEmail string
@@ -263,26 +501,92 @@ func NewApp(store *auth.AccountStore, cfg *config.Config) App {
 	// Initialize calendar client (ignore error, will just skip calendar features)
 	calClient, _ := calendar.NewClient()
 
-	return App{
+	// Load the address book for recipient autocomplete (ignore error)
+	var addressBook []contacts.Contact
+	if diskCache != nil {
+		addressBook, _ = diskCache.LoadContacts()
+	}
+
+	// Fold mailing list mail into sections, hiding muted lists
+	mailList := components.NewMailList()
+	mailList.SetGrouping(components.GroupingLists)
+	mailList.SetMutedLists(cfg.MutedLists)
+	mailList.SetLayout(listLayout(cfg.List))
+	if len(store.Accounts) > 0 {
+		mailList.SetAccount(store.Accounts[0].DisplayName())
+	}
+
+	attachDir, err := os.UserHomeDir()
+	if err != nil {
+		attachDir = "/"
+	}
+
+	app := App{
 		store:          store,
 		cfg:            cfg,
 		accountIdx:   0,
 		serverClient: serverClient,
 		diskCache:    diskCache,
-		mailList:       components.NewMailList(),
+		mailList:       mailList,
 		viewport:       vp,
 		spinner:        s,
 		state:          stateLoading,
 		view:           listView,
 		emailLimit:     uint32(cfg.MaxEmails),
 		labelPicker:    components.NewLabelPicker(),
+		labelEditor:    components.NewLabelEditor(),
+		opsReview:      components.NewOpsReview(),
+		cleanup:        components.NewCleanupWizard(),
 		currentLabel:   "INBOX",
 		searchInput:    si,
 		selected:       make(map[imap.UID]bool),
 		commandPalette: components.NewCommandPalette(),
+		finder:         newBodyFinder(),
+		jumper:         newDateJumper(),
+		replier:        newQuickReplier(),
 		aiClient:       ai.NewClient(),
 		calClient:      calClient,
+		syncStatus:     make(map[string]accountSyncStatus),
+		authCache:      make(map[authKey]*mail.AuthResults),
+		contacts:       addressBook,
+		attachDir:      attachDir,
+		showPreview:    cfg.Preview.Shown(),
+		preview:        &previewCache{},
+		session:        loadSession(diskCache),
+		drafts:         loadDrafts(diskCache),
 	}
+
+	// Reopen the account and folder the TUI was left on
+	for i, acc := range store.Accounts {
+		if acc.Credentials.Email == app.session.Account {
+			app.accountIdx = i
+			app.mailList.SetAccount(acc.DisplayName())
+		}
+	}
+	app.enterPlace()
+	return app
+}
+
+// loadSpellChecker loads the configured dictionary, or one for the UI language, along
+// with the personal word list. Returns nil when spell checking is off or no dictionary
+// is installed.
+func loadSpellChecker(cfg *config.Config) *spell.Checker {
+	if !cfg.SpellCheck.Enabled() {
+		return nil
+	}
+	name := i18n.CurrentLanguage()
+	if cfg.SpellCheck != nil && cfg.SpellCheck.Dictionary != "" {
+		name = cfg.SpellCheck.Dictionary
+	}
+	var personal string
+	if dir, err := config.GetConfigDir(); err == nil {
+		personal = filepath.Join(dir, "spelling.txt")
+	}
+	checker, err := spell.Load(name, personal)
+	if err != nil {
+		return nil
+	}
+	return checker
 }
 
 func (a App) currentAccount() *auth.Account {
@@ -292,12 +596,51 @@ func (a App) currentAccount() *auth.Account {
 	return nil
 }
 
+// StartCompose opens the app straight into a prefilled compose view
+func (a App) StartCompose(draft ComposeDraft) (App, error) {
+	if draft.Account != "" {
+		idx := -1
+		for i, acc := range a.store.Accounts {
+			if strings.EqualFold(acc.Credentials.Email, draft.Account) {
+				idx = i
+				break
+			}
+		}
+		if idx < 0 {
+			return a, fmt.Errorf("account not found: %s", draft.Account)
+		}
+		a.accountIdx = idx
+		a.mailList.SetAccount(a.store.Accounts[idx].DisplayName())
+	}
+	account := a.currentAccount()
+	if account == nil {
+		return a, fmt.Errorf("no account configured")
+	}
+	a.compose = NewDraftModel(account.Credentials.Email, draft)
+	a.compose.SetSignature(a.cfg.Signature)
+	a.prepareCompose(account)
+	a.view = composeView
+	a.state = stateReady
+	a.composeOnly = true
+	return a, nil
+}
+
 func (a App) Init() tea.Cmd {
-	return tea.Batch(
+	if a.composeOnly {
+		return tea.Batch(a.compose.Init(), a.loadSpeller())
+	}
+	cmds := []tea.Cmd{
 		a.spinner.Tick,
 		a.loadCachedEmails(),
+		a.loadUnreadCounts(),
 		scheduleAutoRefresh(),
-	)
+		scheduleDraftAutosave(),
+		loadPlugins(),
+	}
+	if a.serverClient != nil {
+		cmds = append(cmds, a.loadSyncStatus(), waitForServerEvent(a.serverClient.Events()))
+	}
+	return tea.Batch(cmds...)
 }
 
 func (a App) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
@@ -318,6 +661,30 @@ func (a App) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			}
 		}
 
+		// Handle go to date bar input (list view)
+		if a.jumper.typing && a.view == listView {
+			cmd := a.handleGotoKey(msg)
+			return a, cmd
+		}
+
+		// Handle quick reply bar input (list and read views)
+		if a.replier.typing && (a.view == listView || a.view == readView) {
+			cmd := a.handleQuickReplyKey(msg)
+			return a, cmd
+		}
+
+		// Handle find bar input (read view)
+		if a.finder.typing && a.view == readView {
+			return a, a.finder.HandleKey(msg, &a.viewport)
+		}
+
+		// Handle emoji picker input (for compose)
+		if a.showEmojiPicker {
+			var cmd tea.Cmd
+			a.emojiPicker, cmd = a.emojiPicker.Update(msg)
+			return a, cmd
+		}
+
 		// Handle file picker input (for compose attachments)
 		if a.showFilePicker {
 			var cmd tea.Cmd
@@ -325,6 +692,12 @@ func (a App) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			return a, cmd
 		}
 
+		// Handle drafts switcher input
+		if a.showDrafts {
+			cmd := a.handleDraftsKey(msg)
+			return a, cmd
+		}
+
 		// Handle compose view input
 		if a.view == composeView {
 			var cmd tea.Cmd
@@ -394,10 +767,15 @@ func (a App) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				a.labelPicker, cmd = a.labelPicker.Update(msg)
 				return a, cmd
 			case "enter":
+				a.showLabelPicker = false
+				if name, ok := a.labelPicker.CursorSmartFolder(); ok {
+					cmd := a.openSmartFolder(name)
+					return a, cmd
+				}
 				// Select label and load emails
 				newLabel := a.labelPicker.CursorLabel()
-				a.showLabelPicker = false
-				if newLabel != a.currentLabel {
+				if newLabel != a.currentLabel || a.newsletters || a.smartFolder != nil {
+					a.setNewsletters(false)
 					a.currentLabel = newLabel
 					a.labelPicker.SetSelected(newLabel)
 					a.state = stateLoading
@@ -414,6 +792,79 @@ func (a App) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			return a, nil
 		}
 
+		// Handle label editor
+		if a.showLabelEditor {
+			switch msg.String() {
+			case "up", "down", "k", "j", " ", "x":
+				var cmd tea.Cmd
+				a.labelEditor, cmd = a.labelEditor.Update(msg)
+				return a, cmd
+			case "enter":
+				a.showLabelEditor = false
+				add, remove := a.labelEditor.Changes()
+				email := a.mailList.SelectedEmail()
+				if email == nil || (len(add) == 0 && len(remove) == 0) {
+					return a, nil
+				}
+				a.state = stateLoading
+				a.statusMsg = i18n.T("label.updating")
+				return a, tea.Batch(a.spinner.Tick, a.updateLabels(*email, add, remove))
+			case "esc":
+				a.showLabelEditor = false
+				return a, nil
+			}
+			return a, nil
+		}
+
+		// Handle failed operations review
+		if a.showOpsReview {
+			switch msg.String() {
+			case "up", "k":
+				a.opsReview.Up()
+			case "down", "j":
+				a.opsReview.Down()
+			case "r":
+				if op, ok := a.opsReview.Selected(); ok {
+					return a, a.resolveFailedOp(op.ID, true)
+				}
+			case "x", "d":
+				if op, ok := a.opsReview.Selected(); ok {
+					return a, a.resolveFailedOp(op.ID, false)
+				}
+			case "esc", "o":
+				a.showOpsReview = false
+			}
+			return a, nil
+		}
+
+		// Handle cleanup wizard
+		if a.showCleanup {
+			if a.cleanup.Pending() != "" {
+				switch msg.String() {
+				case "y", "Y", "enter":
+					return a, a.runCleanup()
+				case "n", "N", "esc":
+					a.cleanup.Cancel()
+				}
+				return a, nil
+			}
+			switch msg.String() {
+			case "up", "k":
+				a.cleanup.Up()
+			case "down", "j":
+				a.cleanup.Down()
+			case " ":
+				a.cleanup.Toggle()
+			case "a":
+				a.cleanup.Confirm(cache.OpArchive)
+			case "d":
+				a.cleanup.Confirm(cache.OpMoveTrash)
+			case "esc", "q":
+				a.showCleanup = false
+			}
+			return a, nil
+		}
+
 		// Handle attachment picker navigation
 		if a.showAttachmentPicker {
 			email := a.mailList.SelectedEmail()
@@ -453,12 +904,22 @@ func (a App) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				a.updateExtractEditFocus()
 				return a, nil
 			case "up", "k":
+				// The date picker moves up a week
+				if a.extractEditFocus == 1 {
+					a.extractEditDate, _ = a.extractEditDate.Update(msg)
+					return a, nil
+				}
 				// For reminder field, cycle through options
 				if a.extractEditFocus == 6 {
 					a.extractEditReminder = (a.extractEditReminder - 1 + 6) % 6
 					return a, nil
 				}
 			case "down", "j":
+				// The date picker moves down a week
+				if a.extractEditFocus == 1 {
+					a.extractEditDate, _ = a.extractEditDate.Update(msg)
+					return a, nil
+				}
 				// For reminder field, cycle through options
 				if a.extractEditFocus == 6 {
 					a.extractEditReminder = (a.extractEditReminder + 1) % 6
@@ -473,7 +934,7 @@ func (a App) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 					}
 					a.showExtractEdit = false
 					a.statusMsg = i18n.T("status.changes_saved")
-					return a, nil
+					return a, checkSlot(a.calClient, a.extractedStart, a.extractedEnd)
 				case 8: // Cancel button
 					a.showExtractEdit = false
 					return a, nil
@@ -530,7 +991,92 @@ func (a App) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				a.extractedEvent = nil
 				a.extractedProvider = ""
 				return a, nil
+			default:
+				// Move a conflicting event to one of the free slots offered
+				if start, end, ok := a.extractSlot.pick(msg.String()); ok {
+					a.extractedStart, a.extractedEnd = start, end
+					return a, checkSlot(a.calClient, start, end)
+				}
+			}
+		}
+
+		// Handle empty trash confirmation
+		if a.confirmEmpty {
+			switch msg.String() {
+			case "enter", "y":
+				a.confirmEmpty = false
+				a.state = stateLoading
+				a.statusMsg = i18n.T("trash.emptying")
+				return a, tea.Batch(a.spinner.Tick, a.emptyTrash())
+			case "esc", "n":
+				a.confirmEmpty = false
+			}
+			return a, nil
+		}
+
+		// Handle sync error details dialog
+		if a.showSyncError {
+			switch msg.String() {
+			case "esc", "enter", "!":
+				a.showSyncError = false
+			case "r":
+				if account := a.currentAccount(); account != nil && mail.IsAuthError(errors.New(a.currentSyncStatus().err)) {
+					a.ReauthEmail = account.Credentials.Email
+					return a, tea.Quit
+				}
+			case "q":
+				return a, tea.Quit
+			}
+			return a, nil
+		}
+
+		// Handle the field key after y
+		if a.copyPending {
+			a.copyField(msg.String())
+			return a, nil
+		}
+
+		// Handle the link list and open confirmation
+		if a.links.Active() {
+			cmd := a.handleLinkKey(msg)
+			return a, cmd
+		}
+
+		// Handle sender authentication details
+		if a.showAuthDetails {
+			switch msg.String() {
+			case "esc", "enter", "H":
+				a.showAuthDetails = false
+			case "q":
+				return a, tea.Quit
+			}
+			return a, nil
+		}
+
+		// Handle storage report
+		if a.showStorage {
+			switch msg.String() {
+			case "esc", "enter":
+				a.showStorage = false
+			case "X":
+				// Empty the current account's trash, after confirming
+				a.showStorage = false
+				a.confirmEmpty = true
+			case "q":
+				return a, tea.Quit
 			}
+			return a, nil
+		}
+
+		// Handle sync stats panel
+		if a.showStats {
+			switch msg.String() {
+			case "esc", "enter", "S":
+				a.showStats = false
+			case "q":
+				return a, tea.Quit
+			}
+			return a, nil
 		}
 
 		// Handle summary dialog scrolling
@@ -558,11 +1104,21 @@ func (a App) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				a.serverClient.Close()
 			}
 			return a, tea.Quit
+		case "ctrl+r":
+			// Retry the background job that failed
+			if cmd := a.jobs.retry(); cmd != nil {
+				return a, cmd
+			}
 		case "f":
 			// Show label picker (when not in search/confirm mode)
 			if a.state == stateReady && !a.confirmDelete && !a.searchMode && !a.isSearchResult && a.view == listView {
-				a.labelPicker.SetSelected(a.currentLabel)
-				a.showLabelPicker = true
+				cmd := a.openLabelPicker()
+				return a, cmd
+			}
+		case "*":
+			// Show only flagged (starred) emails, or all again
+			if a.canFilter() {
+				a.toggleFilter(components.FilterFlagged)
 				return a, nil
 			}
 		case "esc":
@@ -578,10 +1134,21 @@ func (a App) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			} else if a.confirmDelete {
 				a.confirmDelete = false
 				a.statusMsg = ""
+			} else if a.view == readView && a.finder.Clear(&a.viewport) {
+				// Clear find highlights before leaving the email
+				return a, nil
+			} else if a.view == readView && a.showRaw {
+				// Back from the raw source to the email
+				a.showRaw = false
+				if email := a.mailList.SelectedEmail(); email != nil {
+					a.finder.SetContent(&a.viewport, a.renderEmailContent(*email))
+				}
+				a.statusMsg = ""
+				return a, nil
 			} else if a.view == readView {
 				// Go back to list view (preserves search mode if active)
 				a.view = listView
-				return a, tea.ClearScreen
+				return a, tea.Batch(tea.ClearScreen, a.loadUnreadCounts())
 			} else if a.isSearchResult {
 				// Exit search results, refresh inbox to reflect any deletions
 				a.isSearchResult = false
@@ -592,8 +1159,23 @@ func (a App) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				a.state = stateLoading
 				a.statusMsg = i18n.T("email.refreshing")
 				return a, tea.Batch(a.spinner.Tick, a.loadEmails())
+			} else if a.newsletters && a.view == listView {
+				// Leave the newsletters view
+				a.setNewsletters(false)
+				a.statusMsg = ""
+			} else if a.smartFolder != nil && a.view == listView && a.state == stateReady {
+				// Leave the smart folder for the whole mailbox it filters
+				a.setNewsletters(false)
+				a.labelPicker.SetSelected(a.currentLabel)
+				a.state = stateLoading
+				a.statusMsg = i18n.T("common.loading")
+				return a, tea.Batch(a.spinner.Tick, a.loadEmails())
 			}
 		case "/":
+			// Find within the opened email
+			if a.state == stateReady && a.view == readView && !a.confirmDelete && !a.showAttachmentPicker {
+				return a, a.finder.Start()
+			}
 			// Open command palette
 			if a.state == stateReady && !a.confirmDelete && !a.searchMode {
 				viewName := "list"
@@ -615,7 +1197,7 @@ func (a App) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 					if a.attachmentIdx == 0 {
 						// Download All
 						a.statusMsg = i18n.T("help.download") + "..."
-						return a, tea.Batch(a.spinner.Tick, a.downloadAllAttachments(email))
+						return a, tea.Batch(a.spinner.Tick, a.downloadAllAttachments(email, ""))
 					} else {
 						// Individual attachment (index shifted by 1)
 						attIdx := a.attachmentIdx - 1
@@ -670,77 +1252,47 @@ func (a App) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				}
 				return a, nil
 			}
+			// Expand or collapse a mailing list section
+			if a.view == listView && a.state == stateReady && a.mailList.ToggleSection() {
+				return a, nil
+			}
 			// Normal enter - open email
 			if a.view == listView && a.state == stateReady {
-				if email := a.mailList.SelectedEmail(); email != nil {
-					a.view = readView
-					// Create fresh viewport for each email to avoid state issues
-					emailHeaderHeight := 6
-					if len(email.Attachments) > 0 {
-						emailHeaderHeight = 7
-					}
-					vpHeight := max(5, a.height-10-emailHeaderHeight)
-					a.viewport = viewport.New(a.width-8, vpHeight)
-					a.viewport.Style = lipgloss.NewStyle().Padding(1, 4, 3, 4)
-
-					// Check if body needs to be fetched
-					if email.BodyHTML == "" && email.Snippet == "" {
-						a.viewport.SetContent(i18n.T("common.loading"))
-						// Trigger async body fetch
-						cmd := a.fetchEmailBody(email.UID)
-						if email.Unread {
-							uid := email.UID
-							account := a.currentAccount()
-							label := a.currentLabel
-							serverClient := a.serverClient
-							a.mailList.MarkAsRead(uid)
-							go func() {
-								if serverClient != nil && account != nil {
-									_ = serverClient.MarkRead(account.Credentials.Email, label, uid)
-								}
-							}()
-						}
-						return a, cmd
-					}
-
-					a.viewport.SetContent(a.renderEmailContent(*email))
-
-					if email.Unread {
-						uid := email.UID
-						account := a.currentAccount()
-						label := a.currentLabel
-						serverClient := a.serverClient
-						// Update in-memory state immediately for responsive UI
-						a.mailList.MarkAsRead(uid)
-						go func() {
-							if serverClient != nil && account != nil {
-								_ = serverClient.MarkRead(account.Credentials.Email, label, uid)
-							}
-						}()
-					}
+				if cmd := a.openSelectedEmail(); cmd != nil {
+					return a, cmd
 				}
 			}
+		case "N":
+			// Previous find match (read view)
+			if a.state == stateReady && a.view == readView && a.finder.Active() {
+				a.finder.Next(&a.viewport, -1)
+				return a, nil
+			}
 		case "n":
+			// Next find match (read view)
+			if a.state == stateReady && a.view == readView && a.finder.Active() {
+				a.finder.Next(&a.viewport, 1)
+				return a, nil
+			}
 			// new email
 			if a.state == stateReady && !a.confirmDelete && a.view == listView {
 				account := a.currentAccount()
 				if account != nil {
-					a.compose = NewComposeModel(account.Credentials.Email)
-					a.compose.setSize(a.width, a.height)
-					a.view = composeView
-					return a, a.compose.Init()
+					return a.openCompose(NewComposeModel(account.Credentials.Email), account)
 				}
 			}
 		case "r":
+			// Re-enter password after an authentication failure
+			if a.state == stateError && a.errAccountEmail != "" && mail.IsAuthError(a.err) {
+				a.ReauthEmail = a.errAccountEmail
+				return a, tea.Quit
+			}
 			// Reply to email (in list or read view)
 			if a.state == stateReady && !a.confirmDelete && (a.view == listView || a.view == readView) {
 				if email := a.mailList.SelectedEmail(); email != nil {
 					account := a.currentAccount()
 					if account != nil {
-						a.compose = NewReplyModel(account.Credentials.Email, email)
-						a.compose.setSize(a.width, a.height)
-						a.view = composeView
-						return a, a.compose.Init()
+						return a.openCompose(NewReplyModel(account.Credentials.Email, email, a.cfg.Reply), account)
 					}
 				}
 			}
@@ -750,13 +1302,23 @@ func (a App) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				if email := a.mailList.SelectedEmail(); email != nil {
 					account := a.currentAccount()
 					if account != nil {
-						a.compose = NewReplyAllModel(account.Credentials.Email, email)
-						a.compose.setSize(a.width, a.height)
-						a.view = composeView
-						return a, a.compose.Init()
+						return a.openCompose(NewReplyAllModel(account.Credentials.Email, email, a.cfg.Reply), account)
 					}
 				}
 			}
+		case "Q":
+			// Quick reply to the email under the cursor (in list or read view)
+			if a.state == stateReady && !a.confirmDelete && (a.view == listView || a.view == readView) {
+				if email := a.mailList.SelectedEmail(); email != nil {
+					return a, a.replier.Start(*email)
+				}
+			}
+		case "C":
+			// Resume a set aside email
+			if a.state == stateReady && !a.confirmDelete && len(a.drafts) > 0 && (a.view == listView || a.view == readView) {
+				cmd := a.openDrafts()
+				return a, cmd
+			}
 		case "R":
 			// Shift+R for refresh - direct IMAP metadata-only refresh
 			if a.state == stateReady && !a.isSearchResult && a.view == listView {
@@ -832,8 +1394,23 @@ func (a App) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				}
 				a.mailList.SetSelections(a.selected)
 			}
+		case "1", "2", "3", "4", "5", "6", "7", "8", "9":
+			// Download the numbered attachment from the strip (read view only)
+			if a.state == stateReady && a.view == readView && !a.confirmDelete && !a.showAttachmentPicker {
+				email := a.mailList.SelectedEmail()
+				idx := int(msg.String()[0] - '1')
+				if email != nil && idx < len(email.Attachments) {
+					a.state = stateLoading
+					a.statusMsg = i18n.T("help.download") + " " + email.Attachments[idx].Filename + "..."
+					return a, tea.Batch(a.spinner.Tick, a.downloadAttachment(email, idx))
+				}
+			}
 		case "u":
-			// Mark as unread (read view only)
+			// Show only unread emails in the list; mark as unread in the read view
+			if a.canFilter() {
+				a.toggleFilter(components.FilterUnread)
+				return a, nil
+			}
 			if a.state == stateReady && a.view == readView && !a.confirmDelete {
 				email := a.mailList.SelectedEmail()
 				if email != nil {
@@ -866,12 +1443,34 @@ func (a App) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 					a.deleteOption++
 				}
 			}
-		case "l":
+		case "G":
+			// Go to a date in the list
 			if a.view == listView && a.state == stateReady && !a.confirmDelete && !a.isSearchResult {
-				a.emailLimit += uint32(a.cfg.MaxEmails)
-				a.state = stateLoading
-				a.statusMsg = i18n.T("email.loading", map[string]any{"Count": a.emailLimit})
-				return a, tea.Batch(a.spinner.Tick, a.reloadFromCache())
+				if a.sortOrder() != cache.SortDate {
+					a.statusMsg = i18n.T("goto.sorted")
+					return a, nil
+				}
+				return a, a.jumper.Start()
+			}
+		case "O":
+			// Sort the folder by the next order: date, size, sender, subject, unread first
+			if a.view == listView && a.state == stateReady && !a.confirmDelete && !a.isSearchResult && a.smartFolder == nil && !a.newsletters {
+				cmd := a.cycleSort()
+				return a, cmd
+			}
+		case "l":
+			if a.view == listView && a.state == stateReady && !a.confirmDelete && !a.isSearchResult && !a.paging && a.smartFolder == nil {
+				// Page in what the cache already has, then ask the server for older mail
+				loaded := len(a.mailList.Emails())
+				a.paging = true
+				if next := a.pageOffset + loaded; next < a.mailboxTotal {
+					return a, a.loadPage(next, a.cfg.MaxEmails, false)
+				}
+				if cmd := a.loadOlder(a.pageOffset + loaded); cmd != nil {
+					a.statusMsg = i18n.T("email.loading_older")
+					return a, cmd
+				}
+				a.paging = false
 			}
 		case " ": // Space to toggle selection (search mode only)
 			if a.isSearchResult && a.view == listView && a.state == stateReady {
@@ -890,28 +1489,242 @@ func (a App) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				a.statusMsg = i18n.T("help.mark_read") + "..."
 				return a, tea.Batch(a.spinner.Tick, a.markSelectedAsRead())
 			}
+		case "S":
+			// Save all attachments into a chosen directory (read view)
+			if a.state == stateReady && a.view == readView && !a.confirmDelete && !a.showAttachmentPicker {
+				if email := a.mailList.SelectedEmail(); email != nil && len(email.Attachments) > 0 {
+					a.filePicker = components.NewDirPickerAt(defaultSaveDir())
+					a.filePicker.SetSize(a.width, a.height)
+					a.showFilePicker = true
+				} else {
+					a.statusMsg = i18n.T("attachment.no_attachments")
+				}
+				return a, nil
+			}
+			// Show sync statistics panel
+			if a.state == stateReady && a.view == listView && !a.confirmDelete && !a.isSearchResult {
+				a.statusMsg = i18n.T("stats.loading")
+				return a, a.loadStats()
+			}
+		case "J":
+			// Report spam, or "not spam" when viewing the spam folder
+			if a.state == stateReady && !a.confirmDelete && !a.isSearchResult && (a.view == listView || a.view == readView) {
+				if email := a.mailList.SelectedEmail(); email != nil {
+					notSpam := a.inSpecialFolder(mail.FolderSpam)
+					a.state = stateLoading
+					a.statusMsg = i18n.T("spam.moving")
+					return a, tea.Batch(a.spinner.Tick, a.reportSpam(email.UID, notSpam))
+				}
+			}
+		case "T":
+			// Restore the email from the trash to the folder it was deleted from
+			if a.state == stateReady && !a.confirmDelete && !a.isSearchResult && (a.view == listView || a.view == readView) &&
+				a.inSpecialFolder(mail.FolderTrash) {
+				if email := a.mailList.SelectedEmail(); email != nil {
+					a.state = stateLoading
+					a.statusMsg = i18n.T("trash.restoring")
+					return a, tea.Batch(a.spinner.Tick, a.restoreFromTrash(email.UID))
+				}
+			}
+		case "X":
+			// Empty the trash, after confirming
+			if a.state == stateReady && !a.confirmDelete && !a.isSearchResult && a.view == listView &&
+				a.inSpecialFolder(mail.FolderTrash) {
+				a.confirmEmpty = true
+				return a, nil
+			}
+		case "E":
+			// Send a sent email again, opened in compose to review first
+			if a.state == stateReady && !a.confirmDelete && a.view == readView && a.inSpecialFolder(mail.FolderSent) {
+				if email := a.mailList.SelectedEmail(); email != nil {
+					if account := a.currentAccount(); account != nil {
+						return a.openCompose(NewResendModel(account.Credentials.Email, email), account)
+					}
+				}
+			}
+		case "F":
+			// Forward the open email
+			if a.state == stateReady && !a.confirmDelete && a.view == readView {
+				if email := a.mailList.SelectedEmail(); email != nil {
+					if account := a.currentAccount(); account != nil {
+						return a.openCompose(NewForwardModel(account.Credentials.Email, email), account)
+					}
+				}
+			}
+		case "V":
+			// Show the raw source of the open email, or go back to its body
+			if a.state == stateReady && !a.confirmDelete && a.view == readView {
+				if email := a.mailList.SelectedEmail(); email != nil {
+					if a.showRaw {
+						a.showRaw = false
+						a.finder.SetContent(&a.viewport, a.renderEmailContent(*email))
+						a.statusMsg = ""
+						return a, nil
+					}
+					a.state = stateLoading
+					a.statusMsg = i18n.T("source.loading")
+					return a, tea.Batch(a.spinner.Tick, a.fetchRawSource(email.UID))
+				}
+			}
+		case "w":
+			// Save the raw source shown in the read view as an .eml file
+			if a.state == stateReady && a.view == readView && a.showRaw {
+				if email := a.mailList.SelectedEmail(); email != nil {
+					return a, a.saveRawSource(*email, a.rawSource)
+				}
+			}
+		case "H":
+			// Show the sender authentication details of the open email
+			if a.state == stateReady && !a.confirmDelete && a.view == readView {
+				a.showAuthDetails = true
+				return a, nil
+			}
+		case "v":
+			// Show or hide the preview pane beside or below the list
+			if a.state == stateReady && !a.confirmDelete && a.view == listView {
+				a.showPreview = !a.showPreview
+				a.resizeList()
+				return a, nil
+			}
+		case "z":
+			// Show or hide the quoted history of the open email
+			if a.state == stateReady && !a.confirmDelete && a.view == readView && !a.showRaw {
+				if email := a.mailList.SelectedEmail(); email != nil {
+					a.showQuoted = !a.showQuoted
+					a.finder.SetContent(&a.viewport, a.renderEmailContent(*email))
+				}
+			}
+		case "p":
+			// Show only emails with attachments in the list; print the open email to a file
+			// in the configured format in the read view
+			if a.canFilter() {
+				a.toggleFilter(components.FilterAttachments)
+				return a, nil
+			}
+			if a.state == stateReady && !a.confirmDelete && a.view == readView {
+				if email := a.mailList.SelectedEmail(); email != nil {
+					if email.BodyHTML == "" {
+						a.statusMsg = i18n.T("print.not_loaded")
+						return a, nil
+					}
+					a.state = stateLoading
+					a.statusMsg = i18n.T("print.printing")
+					return a, tea.Batch(a.spinner.Tick, a.printEmail(*email))
+				}
+			}
+		case "L":
+			// Edit the Gmail labels (or notmuch tags) of the open email
+			if a.state == stateReady && a.view == readView && !a.confirmDelete {
+				if email := a.mailList.SelectedEmail(); email != nil {
+					if !a.canEditLabels() {
+						a.statusMsg = i18n.T("label.unsupported")
+						return a, nil
+					}
+					if account := a.currentAccount(); account.Credentials.NotmuchDB != "" {
+						return a, loadNotmuchTags(account.Credentials.NotmuchDB, customLabels(email.Labels))
+					}
+					a.labelEditor.Open(a.labelPicker.CustomLabels(), customLabels(email.Labels))
+					a.labelEditor.SetSize(a.width, a.height)
+					a.showLabelEditor = true
+					return a, nil
+				}
+			}
+		case "U":
+			// Unsubscribe from the mailing list (read view only)
+			if a.state == stateReady && a.view == readView && !a.confirmDelete {
+				if email := a.mailList.SelectedEmail(); email != nil {
+					return a.unsubscribe(email)
+				}
+			}
+		case "M":
+			// Mute or unmute the mailing list under the cursor
+			if a.state == stateReady && a.view == listView && !a.confirmDelete && !a.isSearchResult {
+				if id, name := a.mailList.SelectedListID(); id != "" {
+					cmd := a.toggleMuteList(id, name)
+					return a, cmd
+				}
+			}
+		case "P":
+			// Cycle through account profiles
+			if len(a.cfg.Profiles) > 0 && a.view == listView && !a.confirmDelete && !a.isSearchResult &&
+				(a.state == stateReady || a.state == stateError) {
+				a.statusMsg = i18n.T("common.loading")
+				return a, a.switchProfile(a.nextProfile())
+			}
+		case "y":
+			// Copy a field of the selected email to the clipboard
+			if a.state == stateReady && (a.view == listView || a.view == readView) && !a.confirmDelete {
+				a.startCopy()
+				return a, nil
+			}
+		case "W":
+			// Open the selected email in the provider's web mail
+			if a.state == stateReady && (a.view == listView || a.view == readView) && !a.confirmDelete {
+				if email := a.mailList.SelectedEmail(); email != nil {
+					a.openInWeb(email)
+				}
+				return a, nil
+			}
+		case "o":
+			// List the links in the open email
+			if a.state == stateReady && a.view == readView && !a.confirmDelete && !a.showRaw {
+				if email := a.mailList.SelectedEmail(); email != nil {
+					a.showLinks(email)
+				}
+				return a, nil
+			}
+			// Review queued operations that ran out of retries
+			if a.state == stateReady && a.view == listView && !a.confirmDelete {
+				a.statusMsg = i18n.T("ops.loading")
+				return a, a.loadFailedOps()
+			}
+		case "!":
+			// Show details of the last sync error for the current account
+			if a.currentSyncStatus().err != "" && !a.confirmDelete && !a.showSummary && !a.showExtract {
+				a.showSyncError = true
+				return a, nil
+			}
 		case "tab":
 			// Block account switching when any dialog is open
 			if len(a.store.Accounts) > 1 && !a.confirmDelete && !a.isSearchResult && !a.showLabelPicker &&
 				!a.showExtractEdit && !a.showExtract && !a.showExtractInput && !a.showSummary && !a.showAISetup {
 				// Switch to next account
+				a.rememberPlace()
 				a.accountIdx = (a.accountIdx + 1) % len(a.store.Accounts)
+				a.mailList.SetAccount(a.store.Accounts[a.accountIdx].DisplayName())
 				a.view = listView
 				a.currentLabel = "INBOX" // Reset to inbox on account switch
+				a.specialFolders = nil
+				a.setNewsletters(false)
 				a.showLabelPicker = false
 				// Clear error state from previous account
 				a.err = nil
 				a.state = stateLoading
 				a.emailLimit = uint32(a.cfg.MaxEmails)
+				a.pageOffset = 0
+				a.restore = nil
+				a.enterPlace()
 				a.mailList.SetEmails(nil)
 				a.statusMsg = i18n.T("common.loading")
 
 				// Load from disk cache
 				return a, tea.Batch(a.spinner.Tick, a.loadCachedEmails())
 			}
+		default:
+			// Keys bound by plugins, among those maily doesn't use
+			if a.state == stateReady && (a.view == listView || a.view == readView) && !a.overlayOpen() {
+				if pa, ok := a.pluginForKey(msg.String()); ok {
+					cmd := a.runPlugin(pa)
+					return a, cmd
+				}
+			}
 		}
 
 	case tea.MouseMsg:
+		if msg.Action == tea.MouseActionPress && msg.Button == tea.MouseButtonLeft {
+			return a.handleClick(msg)
+		}
+
 		// Handle summary dialog mouse scroll
 		if a.showSummary {
 			switch msg.Button {
@@ -935,7 +1748,8 @@ func (a App) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 						a.mailList.ScrollUp()
 						a.scrollCount = 0
 					}
-					return a, nil
+					cmd := a.pageIfNeeded()
+					return a, cmd
 				case readView:
 					a.viewport.ScrollUp(3)
 					return a, nil
@@ -949,7 +1763,8 @@ func (a App) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 						a.mailList.ScrollDown()
 						a.scrollCount = 0
 					}
-					return a, nil
+					cmd := a.pageIfNeeded()
+					return a, cmd
 				case readView:
 					a.viewport.ScrollDown(3)
 					return a, nil
@@ -960,8 +1775,11 @@ func (a App) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	case tea.WindowSizeMsg:
 		a.width = msg.Width
 		a.height = msg.Height
-		a.mailList.SetSize(msg.Width, msg.Height-7) // account for 2-row status bar
+		a.resizeList()
 		a.labelPicker.SetSize(msg.Width, msg.Height)
+		a.labelEditor.SetSize(msg.Width, msg.Height)
+		a.opsReview.SetSize(msg.Width, msg.Height)
+		a.cleanup.SetSize(msg.Width, msg.Height)
 		a.viewport.Width = msg.Width - 8
 		// Viewport height depends on view (readView has email header)
 		if a.view == readView {
@@ -977,12 +1795,26 @@ func (a App) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		if a.showFilePicker {
 			a.filePicker.SetSize(msg.Width, msg.Height)
 		}
+		if a.showEmojiPicker {
+			a.emojiPicker.SetSize(msg.Width, msg.Height)
+		}
+		// The folder loaded before the size was known
+		if a.restore != nil && a.state == stateReady && a.view == listView {
+			cmds = append(cmds, a.restorePlace())
+		}
 
 	case spinner.TickMsg:
 		var cmd tea.Cmd
 		a.spinner, cmd = a.spinner.Update(msg)
 		cmds = append(cmds, cmd)
 
+	case jobDoneMsg:
+		return a, a.jobs.finish(msg)
+
+	case jobClearMsg:
+		a.jobs.clear(msg)
+		return a, nil
+
 	case labelsLoadedMsg:
 		// Ignore messages from other accounts (stale messages after switching)
 		currentAccount := a.currentAccount()
@@ -1008,10 +1840,18 @@ func (a App) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		// If we have cached emails, UI is already usable, fetch silently
 		if len(a.mailList.Emails()) > 0 {
 			// Don't show "Loading..." - UI is already usable
-			return a, a.loadEmails()
+			cmd := a.loadEmails()
+			return a, cmd
 		}
 		a.statusMsg = i18n.T("common.loading")
-		return a, a.loadEmails()
+		cmd := a.loadEmails()
+		return a, cmd
+
+	case smartFoldersCountedMsg:
+		if account := a.currentAccount(); account != nil && account.Credentials.Email == msg.accountEmail {
+			a.labelPicker.SetSmartFolders(a.smartFolderItems(msg.counts))
+		}
+		return a, nil
 
 	case cachedEmailsLoadedMsg:
 		// Ignore messages from other accounts (stale messages after switching)
@@ -1025,10 +1865,12 @@ func (a App) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		}
 		// Set emails from cache
 		a.mailList.SetEmails(msg.emails)
+		a.pageOffset = msg.offset
+		a.mailboxTotal = msg.total
 		a.state = stateReady
-		labelName := components.GetLabelDisplayName(a.currentLabel)
-		a.statusMsg = i18n.T("email.folder_count", map[string]any{"Label": labelName, "Count": len(msg.emails)})
-		return a, nil
+		a.statusMsg = a.folderCountStatus(components.GetLabelDisplayName(a.currentLabel), len(msg.emails))
+		newMail := a.markNewMail()
+		return a, tea.Batch(newMail, a.restorePlace())
 
 	case emailsLoadedMsg:
 		// Ignore messages from other accounts (stale messages after switching)
@@ -1040,10 +1882,16 @@ func (a App) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		if msg.accountEmail != "" && msg.accountEmail != currentEmail {
 			return a, nil
 		}
-		a.mailList.SetEmails(msg.emails)
+		if msg.keepCursor {
+			a.mailList.ReplaceEmails(msg.emails)
+		} else {
+			a.mailList.SetEmails(msg.emails)
+		}
+		a.pageOffset = msg.offset
+		a.mailboxTotal = msg.total
 		a.state = stateReady
-		labelName := components.GetLabelDisplayName(a.currentLabel)
-		a.statusMsg = i18n.T("email.folder_count", map[string]any{"Label": labelName, "Count": len(msg.emails)})
+		a.statusMsg = a.folderCountStatus(a.folderName(), len(msg.emails))
+		cmds = append(cmds, a.markNewMail())
 		// Update cache metadata so future runs know cache is fresh
 		if a.diskCache != nil && currentEmail != "" {
 			uidValidity := msg.uidValidity
@@ -1072,25 +1920,215 @@ func (a App) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		if msg.err != nil {
 			a.state = stateError
 			a.err = msg.err
+			a.errAccountEmail = msg.accountEmail
 			a.statusMsg = i18n.T("error.connection", map[string]any{"Error": msg.err})
 			return a, nil
 		}
+		if a.smartFolder != nil || a.sortOrder() != cache.SortDate {
+			// The refresh returns the newest mail; filter or sort it again from the cache
+			return a, a.reloadFromCache()
+		}
+		// Quick refresh returns the newest mail
 		a.mailList.SetEmails(msg.emails)
+		a.pageOffset = 0
 		a.state = stateReady
 		labelName := components.GetLabelDisplayName(a.currentLabel)
 		a.statusMsg = i18n.T("email.folder_count", map[string]any{"Label": labelName, "Count": len(msg.emails)})
 
+	case emailPageLoadedMsg:
+		// Drop pages for an account or mailbox the user has since left
+		if account := a.currentAccount(); account == nil || msg.accountEmail != account.Credentials.Email || msg.mailbox != a.currentLabel || a.isSearchResult {
+			a.paging = false
+			return a, nil
+		}
+		a.applyPage(msg)
+		return a, nil
+
+	case dateParsedMsg:
+		if msg.err != nil {
+			a.state = stateReady
+			a.statusMsg = i18n.T("goto.unknown_date", map[string]any{"Input": a.jumper.input.Value()})
+			return a, nil
+		}
+		cmd := a.startJump(msg.date)
+		return a, cmd
+
+	case dateJumpedMsg:
+		// Drop jumps for an account or mailbox the user has since left
+		if account := a.currentAccount(); account == nil || msg.accountEmail != account.Credentials.Email || msg.mailbox != a.currentLabel || a.isSearchResult {
+			a.paging = false
+			a.state = stateReady
+			return a, nil
+		}
+		a.applyJump(msg)
+		return a, nil
+
+	case draftAutosaveTickMsg:
+		return a, tea.Batch(a.autosaveDrafts(), scheduleDraftAutosave())
+
 	case autoRefreshTickMsg:
 		// Schedule next tick
 		cmds = append(cmds, scheduleAutoRefresh())
-		// Only refresh if in list view, ready state, and not in any dialog
-		if a.view == listView && a.state == stateReady && !a.confirmDelete && !a.searchMode && !a.showLabelPicker && !a.showCommandPalette && !a.isSearchResult {
+		// Only refresh if in list view, ready state, and not in any dialog or paged away from the newest mail
+		if a.view == listView && a.state == stateReady && !a.confirmDelete && !a.searchMode && !a.showLabelPicker && !a.showCommandPalette && !a.isSearchResult && a.pageOffset == 0 {
 			a.state = stateLoading
 			a.statusMsg = i18n.T("email.auto_refreshing")
 			cmds = append(cmds, a.spinner.Tick, a.loadEmails())
 		}
 		return a, tea.Batch(cmds...)
 
+	case profileSwitchedMsg:
+		if msg.err != nil {
+			a.statusMsg = i18n.T("profile.failed", map[string]any{"Error": msg.err})
+			return a, nil
+		}
+		label := msg.cfg.ActiveProfileLabel()
+		if label == "" {
+			label = config.ProfileAll
+		}
+		if len(msg.store.Accounts) == 0 {
+			a.statusMsg = i18n.T("profile.empty", map[string]any{"Profile": label})
+			return a, nil
+		}
+		a.rememberPlace()
+		*a.cfg = msg.cfg
+		a.store = msg.store
+		a.accountIdx = 0
+		a.view = listView
+		a.currentLabel = "INBOX"
+		a.specialFolders = nil
+		a.setNewsletters(false)
+		a.mailList.SetMutedLists(a.cfg.MutedLists)
+		a.mailList.SetLayout(listLayout(a.cfg.List))
+		a.mailList.SetAccount(a.store.Accounts[0].DisplayName())
+		a.err = nil
+		a.state = stateLoading
+		a.emailLimit = uint32(a.cfg.MaxEmails)
+		a.pageOffset = 0
+		a.restore = nil
+		a.enterPlace()
+		a.mailList.SetEmails(nil)
+		a.statusMsg = i18n.T("profile.switched", map[string]any{"Profile": label})
+		return a, tea.Batch(a.spinner.Tick, a.loadCachedEmails(), a.loadSyncStatus())
+
+	case statsLoadedMsg:
+		if msg.err != nil {
+			a.statusMsg = i18n.T("stats.failed", map[string]any{"Error": msg.err})
+			return a, nil
+		}
+		a.stats = make([]components.StatsInfo, len(msg.stats))
+		for i, s := range msg.stats {
+			a.stats[i] = components.StatsInfo{
+				Account:      s.Account,
+				LastSync:     s.LastSync,
+				LastDuration: s.LastDuration,
+				LastFetched:  s.LastFetched,
+				TotalSyncs:   s.TotalSyncs,
+				FailedSyncs:  s.FailedSyncs,
+				PendingOps:   s.PendingOps,
+				LastError:    s.LastError,
+			}
+		}
+		a.showStats = true
+		a.statusMsg = ""
+		return a, nil
+
+	case storageLoadedMsg:
+		if msg.err != nil {
+			a.statusMsg = i18n.T("storage.failed", map[string]any{"Error": msg.err})
+			return a, nil
+		}
+		a.storage = make([]components.StorageInfo, len(msg.storage))
+		for i, s := range msg.storage {
+			a.storage[i] = components.StorageInfo{
+				Account:    s.Account,
+				Quota:      s.Quota,
+				Trash:      s.Trash,
+				TrashCount: s.TrashCount,
+				Error:      s.Error,
+			}
+		}
+		a.showStorage = true
+		a.statusMsg = ""
+		return a, nil
+
+	case syncStatusLoadedMsg:
+		anySyncing := false
+		for _, acc := range msg.accounts {
+			status := a.syncStatus[acc.Email]
+			status.syncing = acc.Syncing
+			status.failedOps = acc.FailedOps
+			status.labels = acc.Labels
+			if acc.LastSync.After(status.lastSync) {
+				status.lastSync = acc.LastSync
+			}
+			a.syncStatus[acc.Email] = status
+			anySyncing = anySyncing || acc.Syncing
+		}
+		if anySyncing {
+			return a, a.spinner.Tick
+		}
+		return a, nil
+
+	case serverEventMsg:
+		// Keep listening for the next event
+		cmds = append(cmds, waitForServerEvent(a.serverClient.Events()))
+		if msg.event.Type == server.EventReconnected {
+			// A new server took over; catch up on events missed while disconnected
+			cmds = append(cmds, a.loadSyncStatus())
+			if a.view == listView && a.state == stateReady && !a.isSearchResult {
+				cmds = append(cmds, a.refreshFromCache())
+			}
+			return a, tea.Batch(cmds...)
+		}
+		if msg.event.Type == server.EventOpsFailed {
+			cmds = append(cmds, a.loadSyncStatus())
+			return a, tea.Batch(cmds...)
+		}
+		if msg.event.Type == server.EventEmailUpdated {
+			// Another maily, e.g. in a second terminal, changed emails; the selection and
+			// cursor here are this instance's own
+			cmds = append(cmds, a.loadUnreadCounts())
+			account := a.currentAccount()
+			if account != nil && account.Credentials.Email == msg.event.Account &&
+				(msg.event.Mailbox == "" || msg.event.Mailbox == a.currentLabel) &&
+				a.view == listView && a.state == stateReady && !a.isSearchResult {
+				cmds = append(cmds, a.refreshFromCache())
+			}
+			return a, tea.Batch(cmds...)
+		}
+		status := a.syncStatus[msg.event.Account]
+		switch msg.event.Type {
+		case server.EventSyncStarted:
+			status.syncing = true
+			status.progress = nil
+			cmds = append(cmds, a.spinner.Tick)
+		case server.EventSyncProgress:
+			if !status.syncing {
+				cmds = append(cmds, a.spinner.Tick)
+			}
+			status.syncing = true
+			status.progress = msg.event.Progress
+		case server.EventSyncCompleted:
+			status.syncing = false
+			status.progress = nil
+			status.lastSync = time.Now()
+			status.err = ""
+			cmds = append(cmds, a.loadUnreadCounts())
+			// Pick up newly synced emails for the account being viewed
+			account := a.currentAccount()
+			if account != nil && account.Credentials.Email == msg.event.Account &&
+				a.view == listView && a.state == stateReady && !a.isSearchResult {
+				cmds = append(cmds, a.refreshFromCache())
+			}
+		case server.EventSyncError:
+			status.syncing = false
+			status.progress = nil
+			status.err = msg.event.Error
+		}
+		a.syncStatus[msg.event.Account] = status
+		return a, tea.Batch(cmds...)
+
 	case errorMsg:
 		// Ignore errors from other accounts (stale errors after switching)
 		currentAccount := a.currentAccount()
@@ -1131,6 +2169,7 @@ func (a App) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		a.state = stateReady
 		a.isSearchResult = true
 		a.searchQuery = msg.query
+		cmds = append(cmds, a.markNewMail())
 		if len(msg.emails) == 0 {
 			a.statusMsg = i18n.T("email.no_results", map[string]any{"Query": msg.query})
 		} else {
@@ -1142,7 +2181,7 @@ func (a App) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		// Clear selections after action
 		a.selected = make(map[imap.UID]bool)
 		a.mailList.SetSelections(a.selected)
-		a.statusMsg = i18n.TPlural("email.deleted", msg.count, map[string]any{"Count": msg.count})
+		a.statusMsg = i18n.TPlural(msg.action, msg.count, nil)
 		// Re-run search to refresh the list
 		if a.isSearchResult && a.searchQuery != "" {
 			a.state = stateLoading
@@ -1154,6 +2193,176 @@ func (a App) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		a.mailList.RemoveByUID(msg.uid)
 		a.statusMsg = i18n.TPlural("email.deleted", 1, map[string]any{"Count": 1})
 
+	case spamReportedMsg:
+		a.state = stateReady
+		a.view = listView
+		a.mailList.RemoveByUID(msg.uid)
+		if msg.notSpam {
+			a.statusMsg = i18n.T("spam.not_spam_done")
+		} else {
+			a.statusMsg = i18n.T("spam.reported")
+		}
+		return a, tea.ClearScreen
+
+	case restoredMsg:
+		a.state = stateReady
+		if msg.err != nil {
+			a.statusMsg = i18n.T("trash.restore_failed", map[string]any{"Error": msg.err})
+			return a, nil
+		}
+		a.view = listView
+		a.mailList.RemoveByUID(msg.uid)
+		a.statusMsg = i18n.T("trash.restored", map[string]any{"Folder": components.GetLabelDisplayName(msg.mailbox)})
+		return a, tea.ClearScreen
+
+	case trashEmptiedMsg:
+		a.state = stateReady
+		if msg.err != nil {
+			a.statusMsg = i18n.T("trash.empty_failed", map[string]any{"Error": msg.err})
+			return a, nil
+		}
+		a.view = listView
+		if a.inSpecialFolder(mail.FolderTrash) {
+			a.mailList.SetEmails(nil)
+			a.mailboxTotal = 0
+		}
+		a.statusMsg = i18n.TPlural("trash.emptied", msg.count, map[string]any{"Count": msg.count})
+		return a, tea.ClearScreen
+
+	case failedOpsLoadedMsg:
+		if msg.err != nil {
+			a.statusMsg = i18n.T("ops.action_failed", map[string]any{"Error": msg.err})
+			return a, nil
+		}
+		a.setFailedOpsCount(len(msg.ops))
+		if len(msg.ops) == 0 {
+			a.statusMsg = i18n.T("ops.none")
+			return a, nil
+		}
+		a.statusMsg = ""
+		a.opsReview.SetOps(msg.ops)
+		a.opsReview.SetSize(a.width, a.height)
+		a.showOpsReview = true
+		return a, nil
+
+	case cleanupLoadedMsg:
+		if msg.err != nil {
+			a.statusMsg = i18n.T("cleanup.failed", map[string]any{"Error": msg.err})
+			return a, nil
+		}
+		a.statusMsg = ""
+		a.cleanup.SetGroups(msg.mailbox, msg.groups)
+		a.cleanup.SetSize(a.width, a.height)
+		a.showCleanup = true
+		return a, nil
+
+	case pluginsLoadedMsg:
+		a.setPlugins(msg.plugins)
+		return a, nil
+
+	case pluginDoneMsg:
+		a.statusMsg = pluginStatus(msg)
+		return a, nil
+
+	case cleanupDoneMsg:
+		if msg.err != nil {
+			a.cleanup.Cancel()
+			a.statusMsg = i18n.T("cleanup.error", map[string]any{"Error": msg.err})
+			return a, nil
+		}
+		a.cleanup.Remove(msg.keys)
+		key := "cleanup.trashed"
+		if msg.operation == cache.OpArchive {
+			key = "cleanup.archived"
+		}
+		a.statusMsg = i18n.TPlural(key, msg.count, map[string]any{"Count": msg.count})
+		cmd := a.loadEmails()
+		return a, cmd
+
+	case opResolvedMsg:
+		if msg.err != nil {
+			a.statusMsg = i18n.T("ops.action_failed", map[string]any{"Error": msg.err})
+			return a, nil
+		}
+		a.opsReview.Remove(msg.id)
+		a.setFailedOpsCount(a.opsReview.Len())
+		if a.opsReview.Len() == 0 {
+			a.showOpsReview = false
+		}
+		if msg.retried {
+			a.statusMsg = i18n.T("ops.retried")
+		} else {
+			a.statusMsg = i18n.T("ops.discarded")
+		}
+		return a, nil
+
+	case labelsUpdatedMsg:
+		a.state = stateReady
+		if msg.err != nil {
+			a.statusMsg = i18n.T("label.update_failed", map[string]any{"Error": msg.err})
+			return a, nil
+		}
+		a.mailList.SetEmailLabels(msg.uid, msg.labels)
+		a.statusMsg = i18n.T("label.updated")
+
+	case notmuchTagsMsg:
+		if msg.err != nil {
+			a.statusMsg = i18n.T("label.tags_failed", map[string]any{"Error": msg.err})
+			return a, nil
+		}
+		a.labelEditor.Open(msg.tags, msg.current)
+		a.labelEditor.SetSize(a.width, a.height)
+		a.showLabelEditor = true
+
+	case configSavedMsg:
+		if msg.err != nil {
+			a.statusMsg = i18n.T("list.save_failed", map[string]any{"Error": msg.err})
+		}
+
+	case threadRuleSetMsg:
+		switch {
+		case msg.err != nil:
+			a.statusMsg = i18n.T("thread.failed", map[string]any{"Error": msg.err})
+		case msg.rule == cache.ThreadMute && msg.set:
+			a.statusMsg = i18n.T("thread.muted")
+		case msg.rule == cache.ThreadMute:
+			a.statusMsg = i18n.T("thread.unmuted")
+		case msg.set:
+			a.statusMsg = i18n.T("thread.followed")
+		default:
+			a.statusMsg = i18n.T("thread.unfollowed")
+		}
+
+	case unsubscribeDoneMsg:
+		a.state = stateReady
+		if msg.err != nil {
+			a.statusMsg = i18n.T("unsubscribe.failed", map[string]any{"Error": msg.err})
+		} else {
+			a.statusMsg = i18n.T("unsubscribe.done")
+		}
+
+	case specialFolderMsg:
+		if account := a.currentAccount(); account == nil || account.Credentials.Email != msg.accountEmail {
+			return a, nil
+		}
+		if msg.err != nil {
+			a.state = stateReady
+			a.statusMsg = i18n.T("folder.not_found", map[string]any{"Folder": i18n.T("folder." + msg.kind), "Error": msg.err})
+			return a, nil
+		}
+		if a.specialFolders == nil {
+			a.specialFolders = make(map[string]string)
+		}
+		a.specialFolders[msg.kind] = msg.folder
+		a.setNewsletters(false)
+		a.currentLabel = msg.folder
+		a.labelPicker.SetSelected(msg.folder)
+		a.view = listView
+		a.emailLimit = uint32(a.cfg.MaxEmails)
+		a.pageOffset = 0
+		a.statusMsg = i18n.T("common.loading")
+		return a, tea.Batch(a.spinner.Tick, a.loadEmails())
+
 	case markUnreadCompleteMsg:
 		a.state = stateReady
 		a.view = listView
@@ -1162,10 +2371,24 @@ func (a App) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		return a, tea.ClearScreen
 
 	case replySentMsg:
+		if a.composeOnly {
+			return a, tea.Quit
+		}
 		a.state = stateReady
 		a.view = listView
 		a.statusMsg = i18n.T("email.reply_success")
-		return a, tea.ClearScreen
+		return a, tea.Batch(a.dropDraft(a.compose), a.markAnswered(msg.original), tea.ClearScreen)
+
+	case quickReplySentMsg:
+		a.state = stateReady
+		if msg.err != nil {
+			a.statusMsg = i18n.T("email.send_failed", map[string]any{"Error": msg.err})
+		} else {
+			a.statusMsg = i18n.T("quick_reply.sent", map[string]any{"To": msg.to})
+			cmd := a.markAnswered(msg.uid)
+			return a, cmd
+		}
+		return a, nil
 
 	case replySendErrorMsg:
 		a.state = stateReady
@@ -1189,32 +2412,54 @@ func (a App) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		return a, tea.Batch(a.spinner.Tick, a.saveDraft())
 
 	case draftSavedMsg:
+		if a.composeOnly {
+			return a, tea.Quit
+		}
 		a.state = stateReady
 		a.statusMsg = i18n.T("email.draft_saved")
+		cmds = append(cmds, a.dropDraft(a.compose))
 		if a.compose.isReply {
 			a.view = readView
 		} else {
 			a.view = listView
-			return a, tea.ClearScreen
+			return a, tea.Batch(append(cmds, tea.ClearScreen)...)
 		}
 
 	case draftSaveErrorMsg:
 		a.state = stateReady
 		a.statusMsg = i18n.T("email.draft_failed", map[string]any{"Error": msg.err})
 
+	case spellerLoadedMsg:
+		a.speller = msg.checker
+		a.spellAsked = true
+		a.compose.SetSpellChecker(msg.checker)
+		return a, nil
+
+	case MinimizeMsg:
+		// Set the email aside to browse; it comes back as it was with C
+		if a.composeOnly {
+			return a, nil
+		}
+		a.setComposeAside()
+		return a, tea.ClearScreen
+
 	case CancelMsg:
 		// Cancel button pressed in compose view
+		if a.composeOnly {
+			return a, tea.Quit
+		}
 		a.statusMsg = i18n.T("common.cancel")
+		cmds = append(cmds, a.dropDraft(a.compose))
 		if a.compose.isReply {
 			a.view = readView
 		} else {
 			a.view = listView
-			return a, tea.ClearScreen
+			return a, tea.Batch(append(cmds, tea.ClearScreen)...)
 		}
 
 	case OpenFilePickerMsg:
-		// Open file picker from compose view
-		a.filePicker = components.NewFilePicker()
+		// Open file picker from compose view, where the last attachment came from
+		a.filePicker = components.NewMultiFilePickerAt(a.attachDir)
 		a.filePicker.SetSize(a.width, a.height)
 		a.showFilePicker = true
 		return a, nil
@@ -1228,6 +2473,43 @@ func (a App) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		} else {
 			a.statusMsg = i18n.T("compose.attach") + ": " + msg.Name
 		}
+		a.attachDir = filepath.Dir(msg.Path)
+		return a, nil
+
+	case PastedAttachmentsMsg:
+		switch {
+		case len(msg.Errors) > 0:
+			a.statusMsg = i18n.T("compose.paste_failed", map[string]any{"Count": len(msg.Added), "Error": strings.Join(msg.Errors, "; ")})
+		case len(msg.Added) > 0:
+			a.statusMsg = i18n.T("compose.attached_count", map[string]any{"Count": len(msg.Added)})
+		}
+		return a, nil
+
+	case components.FilesSelectedMsg:
+		// Several files marked in the file picker
+		a.showFilePicker = false
+		attached := 0
+		for _, f := range msg.Files {
+			if err := a.compose.AddAttachment(f.Path, f.Name, "", f.Size); err != nil {
+				a.statusMsg = i18n.T("error.invalid_input", map[string]any{"Error": err})
+				break
+			}
+			attached++
+			a.attachDir = filepath.Dir(f.Path)
+		}
+		if attached == len(msg.Files) {
+			a.statusMsg = i18n.T("compose.attached_count", map[string]any{"Count": attached})
+		}
+		return a, nil
+
+	case components.DirSelectedMsg:
+		// Save all attachments of the open email into the chosen directory
+		a.showFilePicker = false
+		if email := a.mailList.SelectedEmail(); email != nil && a.view == readView {
+			a.state = stateLoading
+			a.statusMsg = i18n.T("help.download") + "..."
+			return a, tea.Batch(a.spinner.Tick, a.downloadAllAttachments(email, msg.Path))
+		}
 		return a, nil
 
 	case components.FilePickerCancelledMsg:
@@ -1235,6 +2517,21 @@ func (a App) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		a.showFilePicker = false
 		return a, nil
 
+	case OpenEmojiPickerMsg:
+		a.emojiPicker = components.NewEmojiPicker()
+		a.emojiPicker.SetSize(a.width, a.height)
+		a.showEmojiPicker = true
+		return a, nil
+
+	case components.EmojiSelectedMsg:
+		a.showEmojiPicker = false
+		a.compose.InsertSymbol(msg.Char)
+		return a, nil
+
+	case components.EmojiPickerCancelledMsg:
+		a.showEmojiPicker = false
+		return a, nil
+
 	case summaryResultMsg:
 		a.state = stateReady
 		a.showSummary = true
@@ -1259,7 +2556,7 @@ func (a App) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		if !msg.found {
 			// No event found - prompt user to type event details
 			a.extractInput = textinput.New()
-			a.extractInput.Placeholder = "e.g., tomorrow 2pm meeting with John"
+			a.extractInput.Placeholder = i18n.T("extract.placeholder.input")
 			a.extractInput.Focus()
 			a.extractInput.CharLimit = 200
 			a.extractInput.Width = 50
@@ -1274,8 +2571,12 @@ func (a App) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			a.extractedEnd = msg.endTime
 			a.extractedProvider = msg.provider
 			a.statusMsg = ""
+			return a, checkSlot(a.calClient, msg.startTime, msg.endTime)
 		}
 
+	case slotCheckedMsg:
+		a.extractSlot = msg.check
+
 	case extractErrorMsg:
 		a.state = stateReady
 		a.statusMsg = i18n.T("extract.failed", map[string]any{"Error": msg.err})
@@ -1297,12 +2598,69 @@ func (a App) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 
 	case attachmentDownloadedMsg:
 		a.state = stateReady
-		a.statusMsg = i18n.T("attachment.downloaded", map[string]any{"Filename": msg.filename})
+		if msg.chosenDir {
+			a.statusMsg = i18n.T("attachment.saved_to", map[string]any{"Filename": msg.filename, "Path": msg.path})
+		} else {
+			a.statusMsg = i18n.T("attachment.downloaded", map[string]any{"Filename": msg.filename})
+		}
 
 	case attachmentDownloadErrorMsg:
 		a.state = stateReady
 		a.statusMsg = i18n.T("attachment.download_failed", map[string]any{"Error": msg.err})
 
+	case printedMsg:
+		a.state = stateReady
+		if msg.err != nil {
+			a.statusMsg = i18n.T("print.failed", map[string]any{"Error": msg.err})
+		} else {
+			a.statusMsg = i18n.T("print.done", map[string]any{"Path": msg.path})
+		}
+
+	case rawSourceLoadedMsg:
+		a.state = stateReady
+		email := a.mailList.SelectedEmail()
+		if a.view != readView || email == nil || email.UID != msg.uid {
+			a.statusMsg = ""
+			return a, nil
+		}
+		if msg.err != nil {
+			a.statusMsg = i18n.T("source.failed", map[string]any{"Error": msg.err})
+			return a, nil
+		}
+		a.showRaw = true
+		a.rawSource = msg.raw
+		a.finder.SetContent(&a.viewport, components.RenderRawSource(msg.raw))
+		a.viewport.GotoTop()
+		a.statusMsg = i18n.T("source.hint")
+
+	case authResultsLoadedMsg:
+		// Badges are optional: a failed header fetch just leaves them out
+		if msg.err != nil {
+			break
+		}
+		a.authCache[msg.key] = msg.results
+		if email := a.mailList.SelectedEmail(); email != nil && a.authKey(email.UID) == msg.key {
+			a.authResults = msg.results
+		}
+
+	case lastViewedMsg:
+		if msg.err != nil {
+			a.statusMsg = i18n.T("list.last_viewed_failed", map[string]any{"Error": msg.err})
+		} else if msg.read && msg.folder == a.viewedFolder {
+			a.newSince = msg.since
+			a.showNewSince()
+		}
+
+	case unreadCountsLoadedMsg:
+		a.unread = msg.counts
+
+	case rawSavedMsg:
+		if msg.err != nil {
+			a.statusMsg = i18n.T("source.save_failed", map[string]any{"Error": msg.err})
+		} else {
+			a.statusMsg = i18n.T("print.done", map[string]any{"Path": msg.path})
+		}
+
 	case emailBodyLoadedMsg:
 		// Skip UI update if account/mailbox changed since fetch started
 		currentAccount := a.currentAccount()
@@ -1312,9 +2670,9 @@ func (a App) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		// Update the email body in the mail list
 		a.mailList.UpdateEmailBody(msg.uid, msg.bodyHTML, msg.snippet)
 		// Re-render if we're still viewing this email
-		if a.view == readView {
+		if a.view == readView && !a.showRaw {
 			if email := a.mailList.SelectedEmail(); email != nil && email.UID == msg.uid {
-				a.viewport.SetContent(a.renderEmailContent(*email))
+				a.finder.SetContent(&a.viewport, a.renderEmailContent(*email))
 			}
 		}
 
@@ -1334,7 +2692,7 @@ func (a App) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		// Only show error if still viewing the same email
 		if a.view == readView {
 			if email := a.mailList.SelectedEmail(); email != nil && email.UID == msg.uid {
-				a.viewport.SetContent(fmt.Sprintf("Failed to load email content: %v", msg.err))
+				a.viewport.SetContent(i18n.T("email.content_failed", map[string]any{"Error": msg.err}))
 			}
 		}
 	}
@@ -1342,7 +2700,7 @@ func (a App) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	if a.view == listView && a.state == stateReady {
 		var cmd tea.Cmd
 		a.mailList, cmd = a.mailList.Update(msg)
-		cmds = append(cmds, cmd)
+		cmds = append(cmds, cmd, a.pageIfNeeded())
 	}
 
 	if a.view == readView {
@@ -1376,7 +2734,11 @@ func (a App) View() string {
 	case stateReady:
 		switch a.view {
 		case listView:
-			content = components.RenderListView(a.width, a.height, a.mailList.View())
+			if a.showPreview {
+				content = a.renderListWithPreview()
+			} else {
+				content = components.RenderListView(a.width, a.height, a.mailList.View())
+			}
 		case readView:
 			if email := a.mailList.SelectedEmail(); email != nil {
 				var attachments []components.AttachmentInfo
@@ -1393,6 +2755,8 @@ func (a App) View() string {
 					Subject:     email.Subject,
 					Date:        email.Date,
 					Attachments: attachments,
+					Auth:        a.authResults,
+					Avatar:      a.cfg.List.Avatars(),
 				}
 				content = components.RenderReadView(emailData, a.width, a.viewport.View())
 			}
@@ -1423,6 +2787,11 @@ func (a App) View() string {
 		content = components.RenderCentered(a.width, a.height, components.RenderConfirmDialog(deleteCount, a.deleteOption))
 	}
 
+	// Show empty trash confirmation overlay
+	if a.confirmEmpty {
+		content = components.RenderCentered(a.width, a.height, components.RenderEmptyTrashDialog())
+	}
+
 	// Show search input overlay
 	if a.searchMode {
 		content = components.RenderCentered(a.width, a.height, components.RenderSearchInput(a.searchInput.View()))
@@ -1433,6 +2802,21 @@ func (a App) View() string {
 		content = a.labelPicker.View()
 	}
 
+	// Show label editor overlay
+	if a.showLabelEditor {
+		content = a.labelEditor.View()
+	}
+
+	// Show failed operations review overlay
+	if a.showOpsReview {
+		content = a.opsReview.View()
+	}
+
+	// Show cleanup wizard overlay
+	if a.showCleanup {
+		content = a.cleanup.View()
+	}
+
 	// Show command palette overlay
 	if a.showCommandPalette {
 		content = components.RenderCentered(a.width, a.height, a.commandPalette.View())
@@ -1452,29 +2836,33 @@ func (a App) View() string {
 	if a.showExtract && a.extractedEvent != nil {
 		reminderStr := ""
 		if a.extractedEvent.AlarmMinutesBefore > 0 {
-			reminderStr = ReminderLabels[minutesToReminderIndex(a.extractedEvent.AlarmMinutesBefore)]
+			reminderStr = reminderLabel(minutesToReminderIndex(a.extractedEvent.AlarmMinutesBefore))
 		}
-		content = components.RenderExtractDialog(a.width, a.height, components.ExtractData{
+		extractData := components.ExtractData{
 			Title:     a.extractedEvent.Title,
 			StartTime: a.extractedStart,
 			EndTime:   a.extractedEnd,
 			Location:  a.extractedEvent.Location,
 			Reminder:  reminderStr,
 			Provider:  a.extractedProvider,
-		})
+		}
+		if a.extractSlot.conflicting(a.extractedStart, a.extractedEnd) {
+			extractData.Conflicts, extractData.FreeSlots = a.extractSlot.labels()
+		}
+		content = components.RenderExtractDialog(a.width, a.height, extractData)
 	}
 
 	// Show extract edit dialog overlay (takes precedence over extract dialog)
 	if a.showExtractEdit {
 		content = components.RenderExtractEditDialog(a.width, a.height, components.ExtractEditData{
 			TitleInput:    a.extractEditTitle.View(),
-			DateInput:     a.extractEditDate.View(),
+			DateInput:     datePickerView(a.extractEditDate),
 			StartInput:    a.extractEditStart.View(),
 			EndInput:      a.extractEditEnd.View(),
 			LocationInput: a.extractEditLocation.View(),
 			NotesInput:    a.extractEditNotes.View(),
 			ReminderIdx:   a.extractEditReminder,
-			ReminderLabel: ReminderLabels[a.extractEditReminder],
+			ReminderLabel: reminderLabel(a.extractEditReminder),
 			FocusIdx:      a.extractEditFocus,
 			Provider:      a.extractedProvider,
 		})
@@ -1495,23 +2883,58 @@ func (a App) View() string {
 		}
 	}
 
+	// Show drafts switcher overlay
+	if a.showDrafts {
+		content = components.RenderDraftSwitcher(a.width, a.height, a.draftInfos(), a.draftIdx, a.dropPending)
+	}
+
 	// Show file picker overlay (for compose attachments)
 	if a.showFilePicker {
 		content = a.filePicker.View()
 	}
 
-	// Build header data
-	var accounts []string
-	for _, acc := range a.store.Accounts {
-		accounts = append(accounts, acc.Credentials.Email)
+	// Show emoji picker overlay (for compose)
+	if a.showEmojiPicker {
+		content = a.emojiPicker.View()
 	}
-	headerData := components.HeaderData{
-		Width:          a.width,
-		Accounts:       accounts,
-		ActiveIdx:      a.accountIdx,
-		IsSearchResult: a.isSearchResult,
-		SearchQuery:    a.searchQuery,
-		CurrentLabel:   a.currentLabel,
+
+	// Show sync stats overlay
+	if a.showStats {
+		content = components.RenderStatsDialog(a.width, a.height, a.stats)
+	}
+
+	// Show link list or open confirmation overlay
+	if a.links.Active() {
+		content = a.renderLinks()
+	}
+
+	// Show sender authentication details overlay
+	if a.showAuthDetails {
+		content = components.RenderAuthDialog(a.width, a.height, a.authResults)
+	}
+
+	// Show storage report overlay
+	if a.showStorage {
+		content = components.RenderStorageDialog(a.width, a.height, a.storage)
+	}
+
+	// Show sync error details overlay
+	syncStatus := a.currentSyncStatus()
+	if a.showSyncError && syncStatus.err != "" {
+		accountEmail := ""
+		if account := a.currentAccount(); account != nil {
+			accountEmail = account.Credentials.Email
+		}
+		content = components.RenderCentered(a.width, a.height, components.RenderSyncErrorDialog(accountEmail, syncStatus.err))
+	}
+
+	findBar := ""
+	if a.replier.typing && (a.view == listView || a.view == readView) {
+		findBar = a.replier.View()
+	} else if a.view == readView {
+		findBar = a.finder.View()
+	} else if a.view == listView {
+		findBar = a.jumper.View()
 	}
 
 	// Build status bar data
@@ -1522,11 +2945,32 @@ func (a App) View() string {
 		IsSearchResult: a.isSearchResult,
 		IsListView:     a.view == listView,
 		IsComposeView:  a.view == composeView,
+		CanMinimize:    a.view == composeView && !a.composeOnly,
 		AccountCount:   len(a.store.Accounts),
+		HasProfiles:    len(a.cfg.Profiles) > 0,
+		Folder:         a.specialFolderKind(),
+		CanUnsubscribe: a.view == readView && a.canUnsubscribe(),
+		CanEditLabels:  a.view == readView && a.canEditLabels(),
+		OnMailingList:  a.view == listView && !a.isSearchResult && a.onMailingList(),
+		FindBar:        findBar,
 		SelectionCount: a.selectedCount(),
+		Syncing:        syncStatus.syncing,
+		SyncSpinner:    a.spinner.View(),
+		LastSync:       syncStatus.lastSync,
+		SyncError:      syncStatus.err,
+		FailedOps:      syncStatus.failedOps,
+		Jobs:           a.jobs.status(),
+	}
+	if a.view != composeView {
+		statusData.SetAside = a.draftTitles()
+	}
+	if p := syncStatus.progress; p != nil {
+		statusData.SyncPhase = p.Phase
+		statusData.SyncFetched = p.Fetched
+		statusData.SyncTotal = p.Total
 	}
 
-	header := components.RenderHeader(headerData)
+	header := components.RenderHeader(a.headerData())
 	status := components.RenderStatusBar(statusData)
 
 	return lipgloss.JoinVertical(
@@ -1537,6 +2981,31 @@ func (a App) View() string {
 	)
 }
 
+// headerData describes the header: account tabs, profile and folder or search
+func (a App) headerData() components.HeaderData {
+	var accounts []components.AccountTab
+	for i, acc := range a.store.Accounts {
+		accounts = append(accounts, components.AccountTab{
+			Name:   acc.DisplayName(),
+			Color:  components.AccountColor(acc.Color, i),
+			Unread: a.unread[acc.Credentials.Email],
+		})
+	}
+	currentLabel := a.currentLabel
+	if a.newsletters || a.smartFolder != nil {
+		currentLabel = a.folderName()
+	}
+	return components.HeaderData{
+		Width:          a.width,
+		Profile:        a.cfg.ActiveProfileLabel(),
+		Accounts:       accounts,
+		ActiveIdx:      a.accountIdx,
+		IsSearchResult: a.isSearchResult,
+		SearchQuery:    a.searchQuery,
+		CurrentLabel:   currentLabel,
+	}
+}
+
 func (a App) renderEmailContent(email mail.Email) string {
 	body := email.BodyHTML
 	if body == "" {
@@ -1549,8 +3018,17 @@ func (a App) renderEmailContent(email mail.Email) string {
 		wrapWidth = 40
 	}
 
-	// Render HTML body with glamour
-	rendered := components.RenderHTMLBody(body, wrapWidth)
+	// Render HTML body with glamour, folding the quoted history
+	var rendered string
+	if body != "" {
+		markdown, _ := components.FoldQuoted(htmlbody.ToMarkdown(body), a.showQuoted)
+		rendered = components.RenderMarkdown(markdown, wrapWidth)
+	}
+
+	// Warn about phishing signals before the reader gets to any links
+	if signals := mail.PhishingSignals(email.From, email.BodyHTML); len(signals) > 0 {
+		rendered = components.RenderPhishingBanner(signals, wrapWidth) + "\n\n" + rendered
+	}
 
 	contentStyle := lipgloss.NewStyle().
 		PaddingLeft(4).
@@ -1559,6 +3037,33 @@ func (a App) renderEmailContent(email mail.Email) string {
 	return contentStyle.Render(rendered)
 }
 
+// nextProfile returns the profile after the active one, cycling through "all"
+func (a App) nextProfile() string {
+	names := []string{config.ProfileAll}
+	for _, p := range a.cfg.Profiles {
+		names = append(names, p.Name)
+	}
+	current := config.ProfileAll
+	if len(a.cfg.ActiveProfiles) == 1 {
+		current = a.cfg.ActiveProfiles[0]
+	}
+	for i, name := range names {
+		if name == current {
+			return names[(i+1)%len(names)]
+		}
+	}
+	return config.ProfileAll
+}
+
+// currentSyncStatus returns the server-reported sync status for the active account
+func (a App) currentSyncStatus() accountSyncStatus {
+	account := a.currentAccount()
+	if account == nil {
+		return accountSyncStatus{}
+	}
+	return a.syncStatus[account.Credentials.Email]
+}
+
 func (a App) selectedCount() int {
 	count := 0
 	for _, selected := range a.selected {
@@ -1606,7 +3111,8 @@ func (a App) downloadAttachment(email *mail.Email, attachmentIdx int) tea.Cmd {
 	}
 }
 
-func (a App) downloadAllAttachments(email *mail.Email) tea.Cmd {
+// downloadAllAttachments saves every attachment into dir, or ~/Downloads/maily when empty
+func (a App) downloadAllAttachments(email *mail.Email, dir string) tea.Cmd {
 	account := a.currentAccount()
 	serverClient := a.serverClient
 	mailbox := a.currentLabel
@@ -1627,13 +3133,14 @@ func (a App) downloadAllAttachments(email *mail.Email) tea.Cmd {
 		var downloaded []string
 		var lastPath string
 		for _, att := range email.Attachments {
-			filePath, err := serverClient.DownloadAttachment(
+			filePath, err := serverClient.DownloadAttachmentTo(
 				account.Credentials.Email,
 				mailbox,
 				email.UID,
 				att.PartID,
 				att.Filename,
 				att.Encoding,
+				dir,
 			)
 			if err != nil {
 				return attachmentDownloadErrorMsg{err: fmt.Errorf("failed to download %s: %w", att.Filename, err)}
@@ -1655,8 +3162,9 @@ func (a App) downloadAllAttachments(email *mail.Email) tea.Cmd {
 		}
 
 		return attachmentDownloadedMsg{
-			filename: fmt.Sprintf("%d files", len(downloaded)),
-			path:     downloadsDir,
+			filename:  fmt.Sprintf("%d files", len(downloaded)),
+			path:      downloadsDir,
+			chosenDir: dir != "",
 		}
 	}
 }
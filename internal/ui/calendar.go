@@ -1,14 +1,21 @@
 package ui
 
 import (
+	"errors"
 	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
 	"time"
 
 	"github.com/charmbracelet/bubbles/textarea"
 	"github.com/charmbracelet/bubbles/textinput"
 	tea "github.com/charmbracelet/bubbletea"
+	"maily/config"
 	"maily/internal/ai"
+	"maily/internal/auth"
 	"maily/internal/calendar"
+	"maily/internal/i18n"
 	"maily/internal/ui/components"
 )
 
@@ -32,12 +39,14 @@ const (
 	viewFormCalendar   // Interactive form: select calendar
 	viewFormReminder   // Interactive form: select reminder
 	viewFormConfirm    // Interactive form: confirm
+	viewSearch         // Search events by title, location or notes
 )
 
 
 // CalendarApp is the main calendar TUI model
 type CalendarApp struct {
 	client       calendar.Client
+	account      *auth.Account // sends invitations to attendees; nil when none is set up
 	width        int
 	height       int
 	selectedDate time.Time
@@ -54,48 +63,80 @@ type CalendarApp struct {
 
 	// NLP quick-add fields
 	nlpInput       textarea.Model
+	nlpUseAI       bool // an AI CLI reads the input, otherwise the built-in parser
 	nlpParsed      *ai.ParsedEvent
 	nlpCalendarIdx int
 	nlpReminderIdx int
 	nlpStartTime   time.Time
 	nlpEndTime     time.Time
+	nlpAllDay      bool
+	nlpSlot        slotCheck // events already at the parsed time
+	nlpAttendees   []calendar.Attendee
 
 	// NLP edit fields (for editing parsed event)
-	nlpEditTitle    textinput.Model
-	nlpEditDate     components.DatePicker
-	nlpEditStart    components.TimePicker
-	nlpEditEnd      components.TimePicker
-	nlpEditLocation textinput.Model
-	nlpEditNotes    textarea.Model
-	nlpEditFocus    int // 0=title, 1=date, 2=start, 3=end, 4=location, 5=notes
+	nlpEditTitle     textinput.Model
+	nlpEditDate      components.DatePicker
+	nlpEditEndDate   components.DatePicker
+	nlpEditAllDay    bool
+	nlpEditStart     components.TimePicker
+	nlpEditEnd       components.TimePicker
+	nlpEditZone      components.ZonePicker
+	nlpEditLocation  textinput.Model
+	nlpEditAttendees textinput.Model
+	nlpEditNotes     textarea.Model
+	nlpEditFocus     int    // 0=title, 1=date, 2=end date, 3=all day, 4=start, 5=end, 6=zone, 7=location, 8=attendees, 9=notes
+	nlpZone          string // the zone the parsed times are on the clock of, "" for local
 
 	// Interactive form fields (fallback when no AI CLI)
-	formTitleInput    textinput.Model
-	formDateInput     components.DatePicker
-	formStartInput    components.TimePicker
-	formEndInput      components.TimePicker
-	formLocationInput textinput.Model
-	formNotesInput    textarea.Model
-	formCalendarIdx   int
-	formReminderIdx   int
-	formFocusField    int // 0=date, 1=start, 2=end, 3=location, 4=notes in datetime view
+	formTitleInput     textinput.Model
+	formDateInput      components.DatePicker
+	formEndDateInput   components.DatePicker
+	formAllDay         bool
+	formStartInput     components.TimePicker
+	formEndInput       components.TimePicker
+	formZoneInput      components.ZonePicker
+	formLocationInput  textinput.Model
+	formAttendeesInput textinput.Model
+	formNotesInput     textarea.Model
+	formCalendarIdx    int
+	formReminderIdx    int
+	formFocusField     int // 0=date, 1=end date, 2=all day, 3=start, 4=end, 5=zone, 6=location, 7=attendees, 8=notes in datetime view
+	formAttendees      []calendar.Attendee
 
 	// Delete confirmation
 	deleteButtonIdx int // 0=Delete, 1=Cancel
 
 	// Event detail view
 	detailButtonIdx int // 0=Edit, 1=Delete, 2=Close
+
+	// Event search
+	searchInput   textinput.Model
+	searchDays    int              // days before and after today searched
+	searchEvents  []calendar.Event // the events searched, loaded when search opens
+	searchResults []calendar.Event
+	searchIdx     int
+	searchLoading bool
+	jumpEventID   string // the event search jumped to, selected once its month is loaded
+
+	notice string // shown below the day's events until the next key
+
+	settings *config.CalendarConfig // calendar colors and travel times from the config
 }
 
 type eventForm struct {
-	title    textinput.Model
-	date     components.DatePicker
-	start    components.TimePicker
-	end      components.TimePicker
-	location textinput.Model
-	notes    textarea.Model
-	calendar int // index into calendars slice
-	editID   string
+	title     textinput.Model
+	date      components.DatePicker
+	endDate   components.DatePicker
+	allDay    bool
+	start     components.TimePicker
+	end       components.TimePicker
+	zone      components.ZonePicker
+	location  textinput.Model
+	attendees textinput.Model
+	notes     textarea.Model
+	calendar  int // index into calendars slice
+	editID    string
+	invited   []calendar.Attendee // the edited event's attendees, who aren't invited again
 }
 
 // Messages
@@ -108,11 +149,18 @@ type calendarsLoadedMsg struct {
 }
 
 type eventCreatedMsg struct {
-	id string
+	id        string
+	inviteErr error // the event was created, but its invitations weren't sent
 }
 
 type eventDeletedMsg struct{}
 
+// eventExportedMsg reports where an event was saved as an .ics file
+type eventExportedMsg struct {
+	path string
+	err  error
+}
+
 type errMsg struct {
 	err error
 }
@@ -123,12 +171,16 @@ type nlpParsedMsg struct {
 	endTime   time.Time
 }
 
-// NewCalendarApp creates a new calendar TUI
-func NewCalendarApp(client calendar.Client) *CalendarApp {
+// NewCalendarApp creates a new calendar TUI; invitations to attendees are sent from
+// account, and cfg sets how far search looks, the calendars' colors and travel times
+func NewCalendarApp(client calendar.Client, account *auth.Account, cfg *config.CalendarConfig) *CalendarApp {
 	return &CalendarApp{
 		client:       client,
+		account:      account,
 		selectedDate: time.Now(),
 		view:         viewCalendar,
+		searchDays:   cfg.SearchRange(),
+		settings:     cfg,
 	}
 }
 
@@ -177,6 +229,16 @@ func (m *CalendarApp) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 
 	case eventsLoadedMsg:
 		m.events = msg.events
+		if m.jumpEventID != "" {
+			m.selectJumpedEvent()
+		}
+		return m, nil
+
+	case searchLoadedMsg:
+		m.searchLoading = false
+		m.searchEvents = msg.events
+		m.err = msg.err
+		m.searchResults = calendar.Search(m.searchEvents, m.searchInput.Value())
 		return m, nil
 
 	case calendarsLoadedMsg:
@@ -185,8 +247,19 @@ func (m *CalendarApp) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 
 	case eventCreatedMsg:
 		m.view = viewCalendar
+		if msg.inviteErr != nil {
+			m.err = msg.inviteErr
+		}
 		return m, m.loadEvents()
 
+	case eventExportedMsg:
+		if msg.err != nil {
+			m.err = msg.err
+			return m, nil
+		}
+		m.notice = i18n.T("calendar.exported", map[string]any{"Path": msg.path})
+		return m, nil
+
 	case eventDeletedMsg:
 		m.view = viewCalendar
 		if m.selectedIdx >= len(m.events) {
@@ -202,16 +275,29 @@ func (m *CalendarApp) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		m.view = viewCalendar
 		return m, nil
 
+	case slotCheckedMsg:
+		m.nlpSlot = msg.check
+		return m, nil
+
 	case nlpParsedMsg:
 		m.nlpParsed = msg.parsed
 		m.nlpStartTime = msg.startTime
 		m.nlpEndTime = msg.endTime
+		m.nlpAllDay = msg.parsed.AllDay
 		m.nlpCalendarIdx = 0
 		m.nlpReminderIdx = 0
+		m.nlpAttendees = nil
+		m.nlpZone = calendar.LocalZone()
 		m.initNLPEdit()
 		m.view = viewNLPEdit
 		return m, nil
 
+	case tea.MouseMsg:
+		if msg.Action == tea.MouseActionPress && msg.Button == tea.MouseButtonLeft {
+			return m.handleClick(msg)
+		}
+		return m, nil
+
 	case tea.KeyMsg:
 		// Handle form input first if in form view
 		if m.view == viewAddEvent || m.view == viewEditEvent {
@@ -237,6 +323,9 @@ func (m *CalendarApp) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		if m.view == viewFormCalendar || m.view == viewFormReminder || m.view == viewFormConfirm {
 			return m.handleFormSelectKeys(msg)
 		}
+		if m.view == viewSearch {
+			return m.handleSearchKeys(msg)
+		}
 		return m.handleKeyPress(msg)
 	}
 
@@ -257,6 +346,7 @@ func (m *CalendarApp) handleKeyPress(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 
 func (m *CalendarApp) handleCalendarKeys(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 	k := msg.String()
+	m.notice = ""
 
 	// Month/Year mode: m/y sets mode, up/down navigates, esc exits
 	if m.pendingKey != "" {
@@ -319,19 +409,17 @@ func (m *CalendarApp) handleCalendarKeys(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		m.selectedDate = time.Now()
 		m.selectedIdx = 0
 		return m, m.loadEvents()
+	case "/":
+		return m, m.startSearch()
 	case "n":
-		// Check if AI CLI is available
-		aiClient := ai.NewClient()
-		if aiClient.Available() {
-			// NLP quick-add (AI-powered)
-			m.initNLPInput()
-			m.view = viewNLPInput
-			return m, textarea.Blink
-		} else {
-			// Fallback to interactive form
-			m.initInteractiveForm()
-			m.view = viewFormTitle
-		}
+		// NLP quick-add, read by the AI or the built-in parser
+		m.initNLPInput()
+		m.view = viewNLPInput
+		return m, textarea.Blink
+	case "N":
+		// Step-by-step form
+		m.initInteractiveForm()
+		m.view = viewFormTitle
 	case "enter":
 		dayEvents := m.eventsForDate(m.selectedDate)
 		if len(dayEvents) > 0 && m.selectedIdx < len(dayEvents) {
@@ -350,6 +438,11 @@ func (m *CalendarApp) handleCalendarKeys(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 			m.deleteButtonIdx = 0 // Default to "Delete" button
 			m.view = viewDeleteConfirm
 		}
+	case "E":
+		dayEvents := m.eventsForDate(m.selectedDate)
+		if len(dayEvents) > 0 && m.selectedIdx < len(dayEvents) {
+			return m, exportEvent(dayEvents[m.selectedIdx])
+		}
 	}
 	return m, nil
 }
@@ -357,16 +450,19 @@ func (m *CalendarApp) handleCalendarKeys(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 func (m *CalendarApp) handleFormInput(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 	key := msg.String()
 
-	// Handle date/time picker navigation (up/down/left/right when focused on date or time fields)
-	if m.formFocusIdx >= 1 && m.formFocusIdx <= 3 {
+	// Handle date/time picker navigation: arrows, and pgup/pgdown/t on the date pickers
+	if m.formFocusIdx >= 1 && m.formFocusIdx <= 5 && m.formFocusIdx != 3 {
 		switch key {
-		case "up", "down", "left", "right":
+		case "up", "down", "left", "right", "pgup", "pgdown", "t":
 			switch m.formFocusIdx {
 			case 1:
 				m.form.date, _ = m.form.date.Update(msg)
+				followDate(m.form.date, &m.form.endDate)
 			case 2:
+				m.form.endDate, _ = m.form.endDate.Update(msg)
+			case 4:
 				m.form.start, _ = m.form.start.Update(msg)
-			case 3:
+			case 5:
 				m.form.end, _ = m.form.end.Update(msg)
 			}
 			return m, nil
@@ -379,26 +475,32 @@ func (m *CalendarApp) handleFormInput(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		return m, nil
 
 	case "tab":
-		m.formFocusIdx = (m.formFocusIdx + 1) % 9
+		m.formFocusIdx = (m.formFocusIdx + 1) % 13
 		m.updateFormFocus()
 		return m, nil
 
 	case "shift+tab":
-		m.formFocusIdx = (m.formFocusIdx + 8) % 9
+		m.formFocusIdx = (m.formFocusIdx + 12) % 13
 		m.updateFormFocus()
 		return m, nil
 
+	case " ":
+		if m.formFocusIdx == 3 { // All-day toggle
+			m.form.allDay = !m.form.allDay
+			return m, nil
+		}
+
 	case "enter":
-		if m.formFocusIdx == 6 { // Calendar selector
+		if m.formFocusIdx == 10 { // Calendar selector
 			if len(m.calendars) > 0 {
 				m.form.calendar = (m.form.calendar + 1) % len(m.calendars)
 			}
 			return m, nil
 		}
-		if m.formFocusIdx == 7 { // Save button
+		if m.formFocusIdx == 11 { // Save button
 			return m, m.saveEvent()
 		}
-		if m.formFocusIdx == 8 { // Cancel button
+		if m.formFocusIdx == 12 { // Cancel button
 			m.view = viewCalendar
 			return m, nil
 		}
@@ -411,13 +513,13 @@ func (m *CalendarApp) handleFormInput(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		return m, m.saveEvent()
 
 	case "left":
-		if m.formFocusIdx == 6 && len(m.calendars) > 0 {
+		if m.formFocusIdx == 10 && len(m.calendars) > 0 {
 			m.form.calendar = (m.form.calendar + len(m.calendars) - 1) % len(m.calendars)
 			return m, nil
 		}
 
 	case "right":
-		if m.formFocusIdx == 6 && len(m.calendars) > 0 {
+		if m.formFocusIdx == 10 && len(m.calendars) > 0 {
 			m.form.calendar = (m.form.calendar + 1) % len(m.calendars)
 			return m, nil
 		}
@@ -428,11 +530,13 @@ func (m *CalendarApp) handleFormInput(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 	switch m.formFocusIdx {
 	case 0:
 		m.form.title, cmd = m.form.title.Update(msg)
-	case 1:
-		m.form.date, cmd = m.form.date.Update(msg)
-	case 4:
+	case 6:
+		m.form.zone, cmd = m.form.zone.Update(msg)
+	case 7:
 		m.form.location, cmd = m.form.location.Update(msg)
-	case 5:
+	case 8:
+		m.form.attendees, cmd = m.form.attendees.Update(msg)
+	case 9:
 		m.form.notes, cmd = m.form.notes.Update(msg)
 	}
 
@@ -500,6 +604,14 @@ func (m *CalendarApp) handleEventDetailKeys(msg tea.KeyMsg) (tea.Model, tea.Cmd)
 			m.view = viewCalendar
 		}
 		return m, nil
+	case "E":
+		// Save as an .ics file, back in the calendar where the notice shows
+		dayEvents := m.eventsForDate(m.selectedDate)
+		if len(dayEvents) > 0 && m.selectedIdx < len(dayEvents) {
+			m.view = viewCalendar
+			return m, exportEvent(dayEvents[m.selectedIdx])
+		}
+		return m, nil
 	case "e":
 		// Direct shortcut to edit
 		dayEvents := m.eventsForDate(m.selectedDate)
@@ -529,22 +641,33 @@ func (m *CalendarApp) initEditForm(event calendar.Event) {
 	notes.ShowLineNumbers = false
 
 	m.form = eventForm{
-		title:    textinput.New(),
-		date:     components.NewDatePicker(),
-		start:    components.NewTimePicker(),
-		end:      components.NewTimePicker(),
-		location: textinput.New(),
-		notes:    notes,
-		editID:   event.ID,
+		title:     textinput.New(),
+		date:      components.NewDatePicker(),
+		endDate:   components.NewDatePicker(),
+		allDay:    event.AllDay,
+		start:     components.NewTimePicker(),
+		end:       components.NewTimePicker(),
+		zone:      components.NewZonePicker(calendar.TimeZones()),
+		location:  textinput.New(),
+		attendees: newAttendeesInput(),
+		notes:     notes,
+		editID:    event.ID,
+		invited:   event.Attendees,
 	}
 
 	m.form.title.SetValue(event.Title)
 	m.form.title.Focus()
 
-	m.form.date.SetDate(event.StartTime)
-	m.form.start.SetTime24(event.StartTime.Format("15:04"))
-	m.form.end.SetTime24(event.EndTime.Format("15:04"))
+	// Show the times on the clock of the zone the event was created in
+	zone, err := calendar.Location(event.TimeZone)
+	m.err = err
+	m.form.zone.SetValue(event.TimeZone)
+	m.form.date.SetDate(event.StartTime.In(zone))
+	m.form.endDate.SetDate(event.EndTime.In(zone))
+	m.form.start.SetTime24(event.StartTime.In(zone).Format("15:04"))
+	m.form.end.SetTime24(event.EndTime.In(zone).Format("15:04"))
 	m.form.location.SetValue(event.Location)
+	m.form.attendees.SetValue(calendar.FormatAttendees(event.Attendees))
 	m.form.notes.SetValue(event.Notes)
 
 	// Find calendar index
@@ -561,9 +684,12 @@ func (m *CalendarApp) initEditForm(event calendar.Event) {
 func (m *CalendarApp) updateFormFocus() {
 	m.form.title.Blur()
 	m.form.date.Blur()
+	m.form.endDate.Blur()
 	m.form.start.Blur()
 	m.form.end.Blur()
+	m.form.zone.Blur()
 	m.form.location.Blur()
+	m.form.attendees.Blur()
 	m.form.notes.Blur()
 
 	switch m.formFocusIdx {
@@ -572,12 +698,18 @@ func (m *CalendarApp) updateFormFocus() {
 	case 1:
 		m.form.date.Focus()
 	case 2:
+		m.form.endDate.Focus()
+	case 4:
 		m.form.start.Focus()
-	case 3:
+	case 5:
 		m.form.end.Focus()
-	case 4:
+	case 6:
+		m.form.zone.Focus()
+	case 7:
 		m.form.location.Focus()
-	case 5:
+	case 8:
+		m.form.attendees.Focus()
+	case 9:
 		m.form.notes.Focus()
 	}
 }
@@ -590,6 +722,7 @@ func (m *CalendarApp) initNLPInput() {
 	m.nlpInput.ShowLineNumbers = false
 	m.resizeNLPInput()
 	m.nlpInput.Focus()
+	m.nlpUseAI = ai.NewClient().Available()
 	m.nlpParsed = nil
 	m.err = nil
 }
@@ -609,11 +742,19 @@ func (m *CalendarApp) handleNLPInputKeys(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		m.view = viewCalendar
 		return m, nil
 	case "ctrl+enter":
-		if m.nlpInput.Value() != "" {
-			m.view = viewNLPParsing
-			return m, m.parseNLPInput()
+		if strings.TrimSpace(m.nlpInput.Value()) == "" {
+			return m, nil
 		}
-		return m, nil
+		if !m.nlpUseAI {
+			parsed, ok := parseNLPLocally(m.nlpInput.Value(), time.Now())
+			if !ok {
+				m.err = errors.New(i18n.T("calendar.quick_add_no_date"))
+				return m, nil
+			}
+			return m.Update(parsed)
+		}
+		m.view = viewNLPParsing
+		return m, m.parseNLPInput()
 	}
 
 	var cmd tea.Cmd
@@ -654,28 +795,56 @@ func (m *CalendarApp) handleNLPSelectKeys(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 			m.view = viewNLPReminder
 		case viewNLPReminder:
 			m.view = viewNLPConfirm
+			if m.nlpAllDay {
+				return m, nil // all-day events don't take up the day's time
+			}
+			return m, checkSlot(m.client, m.nlpStartTime, m.nlpEndTime)
 		case viewNLPConfirm:
 			return m, m.createNLPEvent()
 		}
+	default:
+		// Move a conflicting event to one of the free slots offered
+		if m.view == viewNLPConfirm {
+			if start, end, ok := m.nlpSlot.pick(msg.String()); ok {
+				m.nlpStartTime, m.nlpEndTime = start, end
+				return m, checkSlot(m.client, start, end)
+			}
+		}
 	}
 	return m, nil
 }
 
+// parseNLPLocally reads quick-add input with the built-in parser
+func parseNLPLocally(input string, now time.Time) (nlpParsedMsg, bool) {
+	parsed, ok := ai.ParseEventLocally(input, now, i18n.DayFirst())
+	if !ok {
+		return nlpParsedMsg{}, false
+	}
+	startTime, _ := parsed.GetStartTime()
+	endTime, _ := parsed.GetEndTime()
+	return nlpParsedMsg{parsed: parsed, startTime: startTime, endTime: endTime}, true
+}
+
+// parseNLPInput has the AI read the quick-add input, falling back to the built-in
+// parser when the AI fails
 func (m *CalendarApp) parseNLPInput() tea.Cmd {
+	input := m.nlpInput.Value()
 	return func() tea.Msg {
 		aiClient := ai.NewClient()
-		if !aiClient.Available() {
-			return errMsg{fmt.Errorf("no AI CLI found (install claude, codex, gemini, or ollama)")}
-		}
-
-		prompt := ai.ParseCalendarEventPrompt(m.nlpInput.Value(), time.Now())
+		prompt := ai.ParseCalendarEventPrompt(input, time.Now())
 		response, err := aiClient.Call(prompt)
 		if err != nil {
+			if parsed, ok := parseNLPLocally(input, time.Now()); ok {
+				return parsed
+			}
 			return errMsg{err}
 		}
 
 		parsed, err := ai.ParseEventResponse(response)
 		if err != nil {
+			if parsed, ok := parseNLPLocally(input, time.Now()); ok {
+				return parsed
+			}
 			return errMsg{err}
 		}
 
@@ -697,6 +866,38 @@ func (m *CalendarApp) parseNLPInput() tea.Cmd {
 	}
 }
 
+// travelTime returns how long it takes to get to an event at location, none for
+// online meetings
+func travelTime(cfg *config.CalendarConfig, location string) time.Duration {
+	if calendar.Online(location) {
+		return 0
+	}
+	return cfg.TravelTime(location)
+}
+
+// alarmMinutes returns how long before an event its reminder fires: the reminder
+// chosen plus the time to get to the event, so it's a reminder to leave
+func alarmMinutes(cfg *config.CalendarConfig, reminder int, location string, allDay bool) int {
+	travel := travelTime(cfg, location)
+	if travel == 0 || allDay {
+		return reminder
+	}
+	return reminder + int(travel/time.Minute)
+}
+
+// leaveByRow describes when to leave for an event at location, "" when there's no
+// travel to it
+func leaveByRow(cfg *config.CalendarConfig, start time.Time, location string, allDay bool) string {
+	travel := travelTime(cfg, location)
+	if travel == 0 || allDay {
+		return ""
+	}
+	return i18n.T("calendar.leave_by_value", map[string]any{
+		"Time":    start.Add(-travel).In(time.Local).Format("3:04 PM"),
+		"Minutes": int(travel / time.Minute),
+	})
+}
+
 func (m *CalendarApp) getNLPReminderMinutes() int {
 	reminderOptions := []int{0, 5, 10, 15, 30, 60}
 	if m.nlpReminderIdx < len(reminderOptions) {
@@ -716,18 +917,29 @@ func (m *CalendarApp) initNLPEdit() {
 	m.nlpEditDate = components.NewDatePicker()
 	m.nlpEditDate.SetDate(m.nlpStartTime)
 
+	m.nlpEditEndDate = components.NewDatePicker()
+	m.nlpEditEndDate.SetDate(m.nlpEndTime)
+	followDate(m.nlpEditDate, &m.nlpEditEndDate)
+	m.nlpEditAllDay = m.nlpAllDay
+
 	m.nlpEditStart = components.NewTimePicker()
 	m.nlpEditStart.SetTime24(m.nlpStartTime.Format("15:04"))
 
 	m.nlpEditEnd = components.NewTimePicker()
 	m.nlpEditEnd.SetTime24(m.nlpEndTime.Format("15:04"))
 
+	m.nlpEditZone = components.NewZonePicker(calendar.TimeZones())
+	m.nlpEditZone.SetValue(m.nlpZone)
+
 	m.nlpEditLocation = textinput.New()
 	m.nlpEditLocation.SetValue(m.nlpParsed.Location)
 	m.nlpEditLocation.Placeholder = "Location (optional)"
 	m.nlpEditLocation.CharLimit = 100
 	m.nlpEditLocation.Width = 40
 
+	m.nlpEditAttendees = newAttendeesInput()
+	m.nlpEditAttendees.SetValue(calendar.FormatAttendees(m.nlpAttendees))
+
 	m.nlpEditNotes = textarea.New()
 	m.nlpEditNotes.SetValue(m.nlpParsed.Notes)
 	m.nlpEditNotes.Placeholder = "Notes: meeting URL, agenda, details..."
@@ -742,16 +954,19 @@ func (m *CalendarApp) initNLPEdit() {
 func (m *CalendarApp) handleNLPEditKeys(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 	key := msg.String()
 
-	// Handle date/time picker navigation
-	if m.nlpEditFocus >= 1 && m.nlpEditFocus <= 3 {
+	// Handle date/time picker navigation: arrows, and pgup/pgdown/t on the date pickers
+	if m.nlpEditFocus >= 1 && m.nlpEditFocus <= 5 && m.nlpEditFocus != 3 {
 		switch key {
-		case "up", "down", "left", "right":
+		case "up", "down", "left", "right", "pgup", "pgdown", "t":
 			switch m.nlpEditFocus {
 			case 1:
 				m.nlpEditDate, _ = m.nlpEditDate.Update(msg)
+				followDate(m.nlpEditDate, &m.nlpEditEndDate)
 			case 2:
+				m.nlpEditEndDate, _ = m.nlpEditEndDate.Update(msg)
+			case 4:
 				m.nlpEditStart, _ = m.nlpEditStart.Update(msg)
-			case 3:
+			case 5:
 				m.nlpEditEnd, _ = m.nlpEditEnd.Update(msg)
 			}
 			return m, nil
@@ -763,13 +978,18 @@ func (m *CalendarApp) handleNLPEditKeys(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		m.view = viewCalendar
 		return m, nil
 	case "tab":
-		m.nlpEditFocus = (m.nlpEditFocus + 1) % 6
+		m.nlpEditFocus = (m.nlpEditFocus + 1) % 10
 		m.updateNLPEditFocus()
 		return m, nil
 	case "shift+tab":
-		m.nlpEditFocus = (m.nlpEditFocus + 5) % 6
+		m.nlpEditFocus = (m.nlpEditFocus + 9) % 10
 		m.updateNLPEditFocus()
 		return m, nil
+	case " ":
+		if m.nlpEditFocus == 3 { // All-day toggle
+			m.nlpEditAllDay = !m.nlpEditAllDay
+			return m, nil
+		}
 	case "enter":
 		// Validate and apply edits
 		if m.applyNLPEdits() {
@@ -783,9 +1003,13 @@ func (m *CalendarApp) handleNLPEditKeys(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 	switch m.nlpEditFocus {
 	case 0:
 		m.nlpEditTitle, cmd = m.nlpEditTitle.Update(msg)
-	case 4:
+	case 6:
+		m.nlpEditZone, cmd = m.nlpEditZone.Update(msg)
+	case 7:
 		m.nlpEditLocation, cmd = m.nlpEditLocation.Update(msg)
-	case 5:
+	case 8:
+		m.nlpEditAttendees, cmd = m.nlpEditAttendees.Update(msg)
+	case 9:
 		m.nlpEditNotes, cmd = m.nlpEditNotes.Update(msg)
 	}
 	return m, cmd
@@ -794,9 +1018,12 @@ func (m *CalendarApp) handleNLPEditKeys(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 func (m *CalendarApp) updateNLPEditFocus() {
 	m.nlpEditTitle.Blur()
 	m.nlpEditDate.Blur()
+	m.nlpEditEndDate.Blur()
 	m.nlpEditStart.Blur()
 	m.nlpEditEnd.Blur()
+	m.nlpEditZone.Blur()
 	m.nlpEditLocation.Blur()
+	m.nlpEditAttendees.Blur()
 	m.nlpEditNotes.Blur()
 
 	switch m.nlpEditFocus {
@@ -805,12 +1032,18 @@ func (m *CalendarApp) updateNLPEditFocus() {
 	case 1:
 		m.nlpEditDate.Focus()
 	case 2:
+		m.nlpEditEndDate.Focus()
+	case 4:
 		m.nlpEditStart.Focus()
-	case 3:
+	case 5:
 		m.nlpEditEnd.Focus()
-	case 4:
+	case 6:
+		m.nlpEditZone.Focus()
+	case 7:
 		m.nlpEditLocation.Focus()
-	case 5:
+	case 8:
+		m.nlpEditAttendees.Focus()
+	case 9:
 		m.nlpEditNotes.Focus()
 	}
 }
@@ -822,20 +1055,28 @@ func (m *CalendarApp) applyNLPEdits() bool {
 	m.nlpParsed.Notes = m.nlpEditNotes.Value()
 
 	// Update times
-	date := m.nlpEditDate.Value()
-	startTime, _ := time.Parse("15:04", m.nlpEditStart.Value24())
-	endTime, _ := time.Parse("15:04", m.nlpEditEnd.Value24())
+	zone := m.nlpEditZone.Value()
+	if m.nlpEditAllDay {
+		zone = ""
+	}
+	start, end, err := eventSpan(m.nlpEditDate.Value(), m.nlpEditEndDate.Value(),
+		m.nlpEditStart.Value24(), m.nlpEditEnd.Value24(), m.nlpEditAllDay, zone)
+	if err != nil {
+		m.err = err
+		return false
+	}
 
-	// Validate end time is after start time
-	if !endTime.After(startTime) {
-		m.err = fmt.Errorf("end time must be after start time")
+	attendees, err := calendar.ParseAttendees(m.nlpEditAttendees.Value())
+	if err != nil {
+		m.err = err
 		return false
 	}
+	m.nlpAttendees = attendees
 
-	m.nlpStartTime = time.Date(date.Year(), date.Month(), date.Day(),
-		startTime.Hour(), startTime.Minute(), 0, 0, time.Local)
-	m.nlpEndTime = time.Date(date.Year(), date.Month(), date.Day(),
-		endTime.Hour(), endTime.Minute(), 0, 0, time.Local)
+	m.nlpZone = zone
+	m.nlpAllDay = m.nlpEditAllDay
+	m.nlpStartTime = start
+	m.nlpEndTime = end
 
 	m.err = nil
 	return true
@@ -855,42 +1096,33 @@ func (m *CalendarApp) createNLPEvent() tea.Cmd {
 			Location:           m.nlpParsed.Location,
 			Notes:              m.nlpParsed.Notes,
 			Calendar:           calendarID,
-			AlarmMinutesBefore: m.getNLPReminderMinutes(),
+			AlarmMinutesBefore: alarmMinutes(m.settings, m.getNLPReminderMinutes(), m.nlpParsed.Location, m.nlpAllDay),
+			AllDay:             m.nlpAllDay,
+			TimeZone:           m.nlpZone,
+			Attendees:          m.nlpAttendees,
 		}
 
-		id, err := m.client.CreateEvent(event)
-		if err != nil {
-			return errMsg{err}
-		}
-
-		return eventCreatedMsg{id}
+		return m.createEvent(event, m.nlpAttendees)
 	}
 }
 
 func (m *CalendarApp) saveEvent() tea.Cmd {
 	return func() tea.Msg {
-		date := m.form.date.Value()
-
-		startTime, err := time.Parse("15:04", m.form.start.Value24())
-		if err != nil {
-			return errMsg{fmt.Errorf("invalid start time: %v", err)}
+		zone := m.form.zone.Value()
+		if m.form.allDay {
+			zone = ""
 		}
-
-		endTime, err := time.Parse("15:04", m.form.end.Value24())
+		start, end, err := eventSpan(m.form.date.Value(), m.form.endDate.Value(),
+			m.form.start.Value24(), m.form.end.Value24(), m.form.allDay, zone)
 		if err != nil {
-			return errMsg{fmt.Errorf("invalid end time: %v", err)}
+			return errMsg{err}
 		}
 
-		// Validate end time is after start time
-		if !endTime.After(startTime) {
-			return errMsg{fmt.Errorf("end time must be after start time")}
+		attendees, err := calendar.ParseAttendees(m.form.attendees.Value())
+		if err != nil {
+			return errMsg{err}
 		}
 
-		start := time.Date(date.Year(), date.Month(), date.Day(),
-			startTime.Hour(), startTime.Minute(), 0, 0, time.Local)
-		end := time.Date(date.Year(), date.Month(), date.Day(),
-			endTime.Hour(), endTime.Minute(), 0, 0, time.Local)
-
 		var calendarID string
 		if len(m.calendars) > 0 && m.form.calendar < len(m.calendars) {
 			calendarID = m.calendars[m.form.calendar].ID
@@ -903,6 +1135,9 @@ func (m *CalendarApp) saveEvent() tea.Cmd {
 			Location:  m.form.location.Value(),
 			Notes:     m.form.notes.Value(),
 			Calendar:  calendarID,
+			AllDay:    m.form.allDay,
+			TimeZone:  zone,
+			Attendees: attendees,
 		}
 
 		// If editing, delete old event first (EventKit doesn't have update)
@@ -910,12 +1145,61 @@ func (m *CalendarApp) saveEvent() tea.Cmd {
 			_ = m.client.DeleteEvent(m.form.editID)
 		}
 
-		id, err := m.client.CreateEvent(event)
+		return m.createEvent(event, newAttendees(attendees, m.form.invited))
+	}
+}
+
+// createEvent creates the event and emails an invitation to the attendees in invite
+func (m *CalendarApp) createEvent(event calendar.Event, invite []calendar.Attendee) tea.Msg {
+	id, err := m.client.CreateEvent(event)
+	if err != nil {
+		return errMsg{err}
+	}
+
+	event.ID = id
+	if err := sendInvitations(m.account, event, invite); err != nil {
+		return eventCreatedMsg{id: id, inviteErr: fmt.Errorf("event created, but the invitations weren't sent: %w", err)}
+	}
+	return eventCreatedMsg{id: id}
+}
+
+// exportEvent saves the event as an iCalendar file in the downloads folder, numbering
+// the name rather than replacing a file already there
+func exportEvent(event calendar.Event) tea.Cmd {
+	return func() tea.Msg {
+		name := strings.Map(func(r rune) rune {
+			if strings.ContainsRune(`/\:*?"<>|`, r) || r < ' ' {
+				return -1
+			}
+			return r
+		}, event.Title)
+		name = event.StartTime.In(time.Local).Format("2006-01-02") + " " + strings.TrimSpace(name) + ".ics"
+		f, path, err := createUnique(filepath.Join(defaultSaveDir(), name))
 		if err != nil {
-			return errMsg{err}
+			return eventExportedMsg{err: err}
 		}
+		_, err = f.Write(calendar.ExportICS([]calendar.Event{event}))
+		if cerr := f.Close(); err == nil {
+			err = cerr
+		}
+		if err != nil {
+			return eventExportedMsg{err: err}
+		}
+		return eventExportedMsg{path: path}
+	}
+}
 
-		return eventCreatedMsg{id}
+// createUnique creates a new file at path, or at "name (1).ext", "name (2).ext" and so
+// on when one is already there
+func createUnique(path string) (*os.File, string, error) {
+	ext := filepath.Ext(path)
+	base := strings.TrimSuffix(path, ext)
+	for i := 1; ; i++ {
+		f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0644)
+		if !os.IsExist(err) {
+			return f, path, err
+		}
+		path = fmt.Sprintf("%s (%d)%s", base, i, ext)
 	}
 }
 
@@ -929,12 +1213,54 @@ func (m *CalendarApp) deleteEvent(id string) tea.Cmd {
 	}
 }
 
+// eventSpan returns when an event starts and ends from a form's fields: an all-day
+// event runs from midnight on date to the end of endDate, local time; others from
+// start on date to end on endDate, on zone's clock
+func eventSpan(date, endDate time.Time, start, end string, allDay bool, zone string) (time.Time, time.Time, error) {
+	if allDay {
+		if endDate.Before(date) {
+			return time.Time{}, time.Time{}, fmt.Errorf("end date must not be before the start date")
+		}
+		return time.Date(date.Year(), date.Month(), date.Day(), 0, 0, 0, 0, time.Local),
+			time.Date(endDate.Year(), endDate.Month(), endDate.Day(), 23, 59, 59, 0, time.Local), nil
+	}
+
+	startTime, err := time.Parse("15:04", start)
+	if err != nil {
+		return time.Time{}, time.Time{}, fmt.Errorf("invalid start time: %v", err)
+	}
+	endTime, err := time.Parse("15:04", end)
+	if err != nil {
+		return time.Time{}, time.Time{}, fmt.Errorf("invalid end time: %v", err)
+	}
+
+	loc, err := calendar.Location(zone)
+	if err != nil {
+		return time.Time{}, time.Time{}, err
+	}
+	from := time.Date(date.Year(), date.Month(), date.Day(),
+		startTime.Hour(), startTime.Minute(), 0, 0, loc)
+	to := time.Date(endDate.Year(), endDate.Month(), endDate.Day(),
+		endTime.Hour(), endTime.Minute(), 0, 0, loc)
+	if !to.After(from) {
+		return time.Time{}, time.Time{}, fmt.Errorf("end time must be after start time")
+	}
+	return from, to, nil
+}
+
+// followDate moves an end date picker along when the start date is moved past it
+func followDate(date components.DatePicker, endDate *components.DatePicker) {
+	if endDate.Value().Before(date.Value()) {
+		endDate.SetDate(date.Value())
+	}
+}
+
+// eventsForDate returns the events on date, including multi-day events that started
+// on an earlier day
 func (m *CalendarApp) eventsForDate(date time.Time) []calendar.Event {
 	var result []calendar.Event
-	dateStr := date.Format("2006-01-02")
-
 	for _, e := range m.events {
-		if e.StartTime.Format("2006-01-02") == dateStr {
+		if e.On(date) {
 			result = append(result, e)
 		}
 	}
@@ -973,6 +1299,8 @@ func (m *CalendarApp) View() string {
 		return m.renderFormReminder()
 	case viewFormConfirm:
 		return m.renderFormConfirm()
+	case viewSearch:
+		return m.renderSearch()
 	default:
 		return m.renderCalendar()
 	}
@@ -994,17 +1322,27 @@ func (m *CalendarApp) initInteractiveForm() {
 	m.formDateInput = components.NewDatePicker()
 	m.formDateInput.SetDate(m.selectedDate)
 
+	m.formEndDateInput = components.NewDatePicker()
+	m.formEndDateInput.SetDate(m.selectedDate)
+	m.formAllDay = false
+
 	m.formStartInput = components.NewTimePicker()
 	m.formStartInput.SetTime24("09:00")
 
 	m.formEndInput = components.NewTimePicker()
 	m.formEndInput.SetTime24("10:00")
 
+	m.formZoneInput = components.NewZonePicker(calendar.TimeZones())
+	m.formZoneInput.SetValue(calendar.LocalZone())
+
 	m.formLocationInput = textinput.New()
 	m.formLocationInput.Placeholder = "Location (optional)"
 	m.formLocationInput.CharLimit = 100
 	m.formLocationInput.Width = 40
 
+	m.formAttendeesInput = newAttendeesInput()
+	m.formAttendees = nil
+
 	m.formNotesInput = textarea.New()
 	m.formNotesInput.Placeholder = "Notes: meeting URL, agenda, details..."
 	m.formNotesInput.CharLimit = 1000
@@ -1028,9 +1366,12 @@ func (m *CalendarApp) handleFormTitleKeys(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 			m.view = viewFormDateTime
 			m.formFocusField = 0
 			m.formDateInput.Focus()
+			m.formEndDateInput.Blur()
 			m.formStartInput.Blur()
 			m.formEndInput.Blur()
+			m.formZoneInput.Blur()
 			m.formLocationInput.Blur()
+			m.formAttendeesInput.Blur()
 			m.formNotesInput.Blur()
 		}
 		return m, nil
@@ -1044,16 +1385,19 @@ func (m *CalendarApp) handleFormTitleKeys(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 func (m *CalendarApp) handleFormDateTimeKeys(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 	key := msg.String()
 
-	// Handle date/time picker navigation (up/down/left/right when focused on date or time fields)
-	if m.formFocusField >= 0 && m.formFocusField <= 2 {
+	// Handle date/time picker navigation: arrows, and pgup/pgdown/t on the date pickers
+	if m.formFocusField >= 0 && m.formFocusField <= 4 && m.formFocusField != 2 {
 		switch key {
-		case "up", "down", "left", "right":
+		case "up", "down", "left", "right", "pgup", "pgdown", "t":
 			switch m.formFocusField {
 			case 0:
 				m.formDateInput, _ = m.formDateInput.Update(msg)
+				followDate(m.formDateInput, &m.formEndDateInput)
 			case 1:
+				m.formEndDateInput, _ = m.formEndDateInput.Update(msg)
+			case 3:
 				m.formStartInput, _ = m.formStartInput.Update(msg)
-			case 2:
+			case 4:
 				m.formEndInput, _ = m.formEndInput.Update(msg)
 			}
 			return m, nil
@@ -1065,13 +1409,18 @@ func (m *CalendarApp) handleFormDateTimeKeys(msg tea.KeyMsg) (tea.Model, tea.Cmd
 		m.view = viewCalendar
 		return m, nil
 	case "tab":
-		m.formFocusField = (m.formFocusField + 1) % 5
+		m.formFocusField = (m.formFocusField + 1) % 9
 		m.updateFormDateTimeFocus()
 		return m, nil
 	case "shift+tab":
-		m.formFocusField = (m.formFocusField + 4) % 5
+		m.formFocusField = (m.formFocusField + 8) % 9
 		m.updateFormDateTimeFocus()
 		return m, nil
+	case " ":
+		if m.formFocusField == 2 { // All-day toggle
+			m.formAllDay = !m.formAllDay
+			return m, nil
+		}
 	case "enter":
 		// Validate and move to calendar selection
 		if m.validateFormDateTime() {
@@ -1083,9 +1432,13 @@ func (m *CalendarApp) handleFormDateTimeKeys(msg tea.KeyMsg) (tea.Model, tea.Cmd
 	// Pass keystrokes to text inputs
 	var cmd tea.Cmd
 	switch m.formFocusField {
-	case 3:
+	case 5:
+		m.formZoneInput, cmd = m.formZoneInput.Update(msg)
+	case 6:
 		m.formLocationInput, cmd = m.formLocationInput.Update(msg)
-	case 4:
+	case 7:
+		m.formAttendeesInput, cmd = m.formAttendeesInput.Update(msg)
+	case 8:
 		m.formNotesInput, cmd = m.formNotesInput.Update(msg)
 	}
 	return m, cmd
@@ -1093,21 +1446,30 @@ func (m *CalendarApp) handleFormDateTimeKeys(msg tea.KeyMsg) (tea.Model, tea.Cmd
 
 func (m *CalendarApp) updateFormDateTimeFocus() {
 	m.formDateInput.Blur()
+	m.formEndDateInput.Blur()
 	m.formStartInput.Blur()
 	m.formEndInput.Blur()
+	m.formZoneInput.Blur()
 	m.formLocationInput.Blur()
+	m.formAttendeesInput.Blur()
 	m.formNotesInput.Blur()
 
 	switch m.formFocusField {
 	case 0:
 		m.formDateInput.Focus()
 	case 1:
+		m.formEndDateInput.Focus()
+	case 3:
 		m.formStartInput.Focus()
-	case 2:
+	case 4:
 		m.formEndInput.Focus()
-	case 3:
+	case 5:
+		m.formZoneInput.Focus()
+	case 6:
 		m.formLocationInput.Focus()
-	case 4:
+	case 7:
+		m.formAttendeesInput.Focus()
+	case 8:
 		m.formNotesInput.Focus()
 	}
 }
@@ -1115,13 +1477,18 @@ func (m *CalendarApp) updateFormDateTimeFocus() {
 func (m *CalendarApp) validateFormDateTime() bool {
 	// components.DatePicker always has valid date values, no need to validate
 
-	// Validate end time is after start time
-	startTime, _ := time.Parse("15:04", m.formStartInput.Value24())
-	endTime, _ := time.Parse("15:04", m.formEndInput.Value24())
-	if !endTime.After(startTime) {
-		m.err = fmt.Errorf("end time must be after start time")
+	// Validate the event ends after it starts
+	if _, _, err := m.getFormSpan(); err != nil {
+		m.err = err
+		return false
+	}
+
+	attendees, err := calendar.ParseAttendees(m.formAttendeesInput.Value())
+	if err != nil {
+		m.err = err
 		return false
 	}
+	m.formAttendees = attendees
 
 	m.err = nil
 	return true
@@ -1175,18 +1542,27 @@ func (m *CalendarApp) getFormReminderMinutes() int {
 	return 0
 }
 
+// getFormZone returns the zone of the form's times, "" for all-day events
+func (m *CalendarApp) getFormZone() string {
+	if m.formAllDay {
+		return ""
+	}
+	return m.formZoneInput.Value()
+}
+
+func (m *CalendarApp) getFormSpan() (time.Time, time.Time, error) {
+	return eventSpan(m.formDateInput.Value(), m.formEndDateInput.Value(),
+		m.formStartInput.Value24(), m.formEndInput.Value24(), m.formAllDay, m.getFormZone())
+}
+
 func (m *CalendarApp) getFormStartTime() time.Time {
-	date := m.formDateInput.Value()
-	startTime, _ := time.Parse("15:04", m.formStartInput.Value24())
-	return time.Date(date.Year(), date.Month(), date.Day(),
-		startTime.Hour(), startTime.Minute(), 0, 0, time.Local)
+	start, _, _ := m.getFormSpan()
+	return start
 }
 
 func (m *CalendarApp) getFormEndTime() time.Time {
-	date := m.formDateInput.Value()
-	endTime, _ := time.Parse("15:04", m.formEndInput.Value24())
-	return time.Date(date.Year(), date.Month(), date.Day(),
-		endTime.Hour(), endTime.Minute(), 0, 0, time.Local)
+	_, end, _ := m.getFormSpan()
+	return end
 }
 
 func (m *CalendarApp) createFormEvent() tea.Cmd {
@@ -1203,15 +1579,13 @@ func (m *CalendarApp) createFormEvent() tea.Cmd {
 			Location:           m.formLocationInput.Value(),
 			Notes:              m.formNotesInput.Value(),
 			Calendar:           calendarID,
-			AlarmMinutesBefore: m.getFormReminderMinutes(),
-		}
-
-		id, err := m.client.CreateEvent(event)
-		if err != nil {
-			return errMsg{err}
+			AlarmMinutesBefore: alarmMinutes(m.settings, m.getFormReminderMinutes(), m.formLocationInput.Value(), m.formAllDay),
+			AllDay:             m.formAllDay,
+			TimeZone:           m.getFormZone(),
+			Attendees:          m.formAttendees,
 		}
 
-		return eventCreatedMsg{id}
+		return m.createEvent(event, m.formAttendees)
 	}
 }
 
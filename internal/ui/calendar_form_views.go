@@ -3,8 +3,10 @@ package ui
 import (
 	"fmt"
 	"strings"
+	"time"
 
 	"github.com/charmbracelet/lipgloss"
+	"maily/internal/calendar"
 	"maily/internal/i18n"
 	"maily/internal/ui/components"
 )
@@ -36,44 +38,68 @@ func (m *CalendarApp) renderForm(title string) string {
 	content.WriteString(m.form.title.View())
 	content.WriteString("\n")
 
-	// Date field
+	// Date field, a month grid while focused
+	dateLabel := labelStyle.Render(i18n.T("calendar.field.date"))
 	if m.formFocusIdx == 1 {
-		content.WriteString(focusedLabelStyle.Render(i18n.T("calendar.field.date")))
+		dateLabel = focusedLabelStyle.Render(i18n.T("calendar.field.date"))
+	}
+	content.WriteString(lipgloss.JoinHorizontal(lipgloss.Top, dateLabel, datePickerView(m.form.date)))
+	content.WriteString("\n")
+
+	// End date, for events spanning several days
+	endDateLabel := labelStyle.Render(i18n.T("calendar.field.end_date"))
+	if m.formFocusIdx == 2 {
+		endDateLabel = focusedLabelStyle.Render(i18n.T("calendar.field.end_date"))
+	}
+	content.WriteString(lipgloss.JoinHorizontal(lipgloss.Top, endDateLabel, datePickerView(m.form.endDate)))
+	content.WriteString("\n")
+
+	// All-day toggle
+	if m.formFocusIdx == 3 {
+		content.WriteString(focusedLabelStyle.Render(i18n.T("calendar.field.all_day")))
 	} else {
-		content.WriteString(labelStyle.Render(i18n.T("calendar.field.date")))
+		content.WriteString(labelStyle.Render(i18n.T("calendar.field.all_day")))
 	}
-	content.WriteString(m.form.date.View())
-	if m.formFocusIdx == 1 {
-		content.WriteString(hintStyle.Render("  ↑↓←→"))
+	content.WriteString(renderToggle(m.form.allDay, m.formFocusIdx == 3))
+	if m.formFocusIdx == 3 {
+		content.WriteString(hintStyle.Render("  space"))
 	}
 	content.WriteString("\n")
 
-	// Start time
-	if m.formFocusIdx == 2 {
+	// Start and end times, unused by all-day events
+	if m.formFocusIdx == 4 {
 		content.WriteString(focusedLabelStyle.Render(i18n.T("calendar.field.start")))
 	} else {
 		content.WriteString(labelStyle.Render(i18n.T("calendar.field.start")))
 	}
-	content.WriteString(m.form.start.View())
-	if m.formFocusIdx == 2 {
+	content.WriteString(timeView(m.form.start, m.form.allDay))
+	if m.formFocusIdx == 4 && !m.form.allDay {
 		content.WriteString(hintStyle.Render("  ↑↓←→"))
 	}
 	content.WriteString("\n")
 
-	// End time
-	if m.formFocusIdx == 3 {
+	if m.formFocusIdx == 5 {
 		content.WriteString(focusedLabelStyle.Render(i18n.T("calendar.field.end")))
 	} else {
 		content.WriteString(labelStyle.Render(i18n.T("calendar.field.end")))
 	}
-	content.WriteString(m.form.end.View())
-	if m.formFocusIdx == 3 {
+	content.WriteString(timeView(m.form.end, m.form.allDay))
+	if m.formFocusIdx == 5 && !m.form.allDay {
 		content.WriteString(hintStyle.Render("  ↑↓←→"))
 	}
 	content.WriteString("\n")
 
+	// Time zone
+	if m.formFocusIdx == 6 {
+		content.WriteString(focusedLabelStyle.Render(i18n.T("calendar.field.zone")))
+	} else {
+		content.WriteString(labelStyle.Render(i18n.T("calendar.field.zone")))
+	}
+	content.WriteString(m.form.zone.View())
+	content.WriteString("\n")
+
 	// Location
-	if m.formFocusIdx == 4 {
+	if m.formFocusIdx == 7 {
 		content.WriteString(focusedLabelStyle.Render(i18n.T("calendar.field.location")))
 	} else {
 		content.WriteString(labelStyle.Render(i18n.T("calendar.field.location")))
@@ -81,8 +107,17 @@ func (m *CalendarApp) renderForm(title string) string {
 	content.WriteString(m.form.location.View())
 	content.WriteString("\n")
 
+	// Attendees
+	if m.formFocusIdx == 8 {
+		content.WriteString(focusedLabelStyle.Render(i18n.T("calendar.field.attendees")))
+	} else {
+		content.WriteString(labelStyle.Render(i18n.T("calendar.field.attendees")))
+	}
+	content.WriteString(m.form.attendees.View())
+	content.WriteString("\n")
+
 	// Notes
-	if m.formFocusIdx == 5 {
+	if m.formFocusIdx == 9 {
 		content.WriteString(focusedLabelStyle.Render(i18n.T("calendar.field.notes")))
 	} else {
 		content.WriteString(labelStyle.Render(i18n.T("calendar.field.notes")))
@@ -91,7 +126,7 @@ func (m *CalendarApp) renderForm(title string) string {
 	content.WriteString("\n")
 
 	// Calendar selector
-	if m.formFocusIdx == 6 {
+	if m.formFocusIdx == 10 {
 		content.WriteString(focusedLabelStyle.Render(i18n.T("calendar.field.calendar")))
 	} else {
 		content.WriteString(labelStyle.Render(i18n.T("calendar.field.calendar")))
@@ -102,7 +137,7 @@ func (m *CalendarApp) renderForm(title string) string {
 		calName = m.calendars[m.form.calendar].Title
 	}
 	calStyle := lipgloss.NewStyle()
-	if m.formFocusIdx == 6 {
+	if m.formFocusIdx == 10 {
 		calStyle = calStyle.Background(components.Primary).Foreground(components.Text)
 	}
 	content.WriteString(calStyle.Render(fmt.Sprintf("◀ %s ▶", calName)))
@@ -136,12 +171,12 @@ func (m *CalendarApp) renderForm(title string) string {
 		Foreground(components.Muted)
 
 	var saveBtn, cancelBtn string
-	if m.formFocusIdx == 7 {
+	if m.formFocusIdx == 11 {
 		saveBtn = selectedBtn.BorderForeground(components.Primary).Background(components.Primary).Foreground(lipgloss.Color("#FFFFFF")).Render(i18n.T("common.save"))
 	} else {
 		saveBtn = unselectedBtn.Render(i18n.T("common.save"))
 	}
-	if m.formFocusIdx == 8 {
+	if m.formFocusIdx == 12 {
 		cancelBtn = selectedBtn.BorderForeground(components.Muted).Background(components.Muted).Foreground(lipgloss.Color("#FFFFFF")).Render(i18n.T("common.cancel"))
 	} else {
 		cancelBtn = unselectedBtn.Render(i18n.T("common.cancel"))
@@ -230,22 +265,29 @@ func (m *CalendarApp) renderEventDetail() string {
 	content.WriteString(valueStyle.Bold(true).Render(event.Title))
 	content.WriteString("\n")
 
-	// Date
+	// Date, or the days it spans
+	date, timeStr := eventWhen(event.StartTime.In(time.Local), event.EndTime.In(time.Local), event.AllDay)
 	content.WriteString(labelStyle.Render(i18n.T("calendar.field.date")))
-	content.WriteString(valueStyle.Render(event.StartTime.Format("Monday, January 2, 2006")))
+	content.WriteString(valueStyle.Render(date))
 	content.WriteString("\n")
 
 	// Time
-	var timeStr string
-	if event.AllDay {
-		timeStr = i18n.T("calendar.all_day")
-	} else {
-		timeStr = fmt.Sprintf("%s - %s", event.StartTime.Format("3:04 PM"), event.EndTime.Format("3:04 PM"))
-	}
 	content.WriteString(labelStyle.Render(i18n.T("calendar.field.time")))
 	content.WriteString(valueStyle.Render(timeStr))
 	content.WriteString("\n")
 
+	// Time zone, when the event was created on another clock
+	if event.Elsewhere() {
+		content.WriteString(labelStyle.Render(i18n.T("calendar.field.zone")))
+		if zone, err := calendar.Location(event.TimeZone); err != nil {
+			content.WriteString(valueStyle.Render(i18n.T("calendar.zone_unknown", map[string]any{"Zone": event.TimeZone})))
+		} else {
+			there := fmt.Sprintf("%s - %s", event.StartTime.In(zone).Format("3:04 PM"), event.EndTime.In(zone).Format("3:04 PM"))
+			content.WriteString(valueStyle.Render(i18n.T("calendar.zone_there", map[string]any{"Time": there, "Zone": event.TimeZone})))
+		}
+		content.WriteString("\n")
+	}
+
 	// Location (if present)
 	if event.Location != "" {
 		content.WriteString(labelStyle.Render(i18n.T("calendar.field.location")))
@@ -253,10 +295,28 @@ func (m *CalendarApp) renderEventDetail() string {
 		content.WriteString("\n")
 	}
 
+	// Attendees and their responses
+	for i, a := range event.Attendees {
+		label := ""
+		if i == 0 {
+			label = i18n.T("calendar.field.attendees")
+		}
+		name := a.Name
+		if name == "" {
+			name = a.Email
+		}
+		content.WriteString(labelStyle.Render(label))
+		content.WriteString(valueStyle.Render(name))
+		if status := attendeeStatus(a.Status); status != "" {
+			content.WriteString(lipgloss.NewStyle().Foreground(components.Muted).Render("  " + status))
+		}
+		content.WriteString("\n")
+	}
+
 	// Calendar
 	if event.Calendar != "" {
 		content.WriteString(labelStyle.Render(i18n.T("calendar.field.calendar")))
-		content.WriteString(valueStyle.Foreground(components.Secondary).Render(event.Calendar))
+		content.WriteString(valueStyle.Foreground(m.calendarColor(event.Calendar)).Render("● " + event.Calendar))
 		content.WriteString("\n")
 	}
 
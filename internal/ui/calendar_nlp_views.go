@@ -22,6 +22,14 @@ func (m *CalendarApp) renderNLPInput() string {
 	b.WriteString("\n\n")
 	b.WriteString(m.nlpInput.View())
 	b.WriteString("\n\n")
+	if m.err != nil {
+		b.WriteString(lipgloss.NewStyle().Foreground(components.Danger).Render(m.err.Error()))
+		b.WriteString("\n\n")
+	}
+	if !m.nlpUseAI {
+		b.WriteString(hintStyle.Render(i18n.T("calendar.quick_add_builtin")))
+		b.WriteString("\n")
+	}
 	b.WriteString(hintStyle.Render(fmt.Sprintf("ctrl+enter %s • esc %s", i18n.T("help.confirm"), i18n.T("help.cancel"))))
 
 	return lipgloss.NewStyle().Padding(1, 2).Render(b.String())
@@ -56,31 +64,39 @@ func (m *CalendarApp) renderNLPEdit() string {
 		if focused {
 			style = focusedLabel
 		}
-		row := fmt.Sprintf("    %s%s", style.Render(label), value)
+		hint := ""
 		if focused && showHint {
-			row += hintStyle.Render(fmt.Sprintf("  %s", i18n.T("calendar.picker_hint")))
+			hint = hintStyle.Render(fmt.Sprintf("  %s", i18n.T("calendar.picker_hint")))
 		}
-		return row + "\n"
+		return lipgloss.JoinHorizontal(lipgloss.Top, "    ", style.Render(label), value, hint) + "\n"
 	}
 
 	// Title
 	b.WriteString(field(m.nlpEditFocus == 0, i18n.T("calendar.field.title"), m.nlpEditTitle.View(), false))
 
-	// Date
-	b.WriteString(field(m.nlpEditFocus == 1, i18n.T("calendar.field.date"), m.nlpEditDate.View(), true))
+	// Date, and the last day of events spanning several
+	b.WriteString(field(m.nlpEditFocus == 1, i18n.T("calendar.field.date"), datePickerView(m.nlpEditDate), false))
+	b.WriteString(field(m.nlpEditFocus == 2, i18n.T("calendar.field.end_date"), datePickerView(m.nlpEditEndDate), false))
 
-	// Start time
-	b.WriteString(field(m.nlpEditFocus == 2, i18n.T("calendar.field.start"), m.nlpEditStart.View(), true))
+	// All-day toggle
+	b.WriteString(field(m.nlpEditFocus == 3, i18n.T("calendar.field.all_day"), renderToggle(m.nlpEditAllDay, m.nlpEditFocus == 3), false))
 
-	// End time
-	b.WriteString(field(m.nlpEditFocus == 3, i18n.T("calendar.field.end"), m.nlpEditEnd.View(), true))
+	// Start and end times, unused by all-day events
+	b.WriteString(field(m.nlpEditFocus == 4, i18n.T("calendar.field.start"), timeView(m.nlpEditStart, m.nlpEditAllDay), !m.nlpEditAllDay))
+	b.WriteString(field(m.nlpEditFocus == 5, i18n.T("calendar.field.end"), timeView(m.nlpEditEnd, m.nlpEditAllDay), !m.nlpEditAllDay))
+
+	// Time zone
+	b.WriteString(field(m.nlpEditFocus == 6, i18n.T("calendar.field.zone"), m.nlpEditZone.View(), false))
 
 	// Location
-	b.WriteString(field(m.nlpEditFocus == 4, i18n.T("calendar.field.location"), m.nlpEditLocation.View(), false))
+	b.WriteString(field(m.nlpEditFocus == 7, i18n.T("calendar.field.location"), m.nlpEditLocation.View(), false))
+
+	// Attendees
+	b.WriteString(field(m.nlpEditFocus == 8, i18n.T("calendar.field.attendees"), m.nlpEditAttendees.View(), false))
 
 	// Notes
 	b.WriteString("\n")
-	b.WriteString(field(m.nlpEditFocus == 5, i18n.T("calendar.field.notes"), "", false))
+	b.WriteString(field(m.nlpEditFocus == 9, i18n.T("calendar.field.notes"), "", false))
 	b.WriteString(fmt.Sprintf("    %s\n", m.nlpEditNotes.View()))
 
 	// Error
@@ -181,11 +197,18 @@ func (m *CalendarApp) renderNLPConfirm() string {
 	// Event details box
 	b.WriteString("  ┌────────────────────────────────────────────────┐\n")
 	b.WriteString(boxRow(i18n.T("calendar.field.title"), utils.TruncateStr(m.nlpParsed.Title, 35), 35))
-	b.WriteString(boxRow(i18n.T("calendar.field.date")+" ", m.nlpStartTime.Format("Monday, Jan 2, 2006"), 35))
-	b.WriteString(boxRow(i18n.T("calendar.field.time")+" ", fmt.Sprintf("%s - %s", m.nlpStartTime.Format("3:04 PM"), m.nlpEndTime.Format("3:04 PM")), 35))
+	date, clock := eventWhen(m.nlpStartTime, m.nlpEndTime, m.nlpAllDay)
+	b.WriteString(boxRow(i18n.T("calendar.field.date")+" ", date, 35))
+	b.WriteString(boxRow(i18n.T("calendar.field.time")+" ", clock, 35))
+	if here := zoneHere(m.nlpZone, m.nlpStartTime); here != "" {
+		b.WriteString(boxRow(i18n.T("calendar.field.zone"), utils.TruncateStr(here, 35), 35))
+	}
 	if m.nlpParsed.Location != "" {
 		b.WriteString(boxRow(i18n.T("calendar.field.location"), utils.TruncateStr(m.nlpParsed.Location, 35), 35))
 	}
+	if len(m.nlpAttendees) > 0 {
+		b.WriteString(boxRow(i18n.T("calendar.field.attendees"), utils.TruncateStr(attendeeNames(m.nlpAttendees), 35), 35))
+	}
 	calName := i18n.T("calendar.default")
 	if len(m.calendars) > 0 && m.nlpCalendarIdx < len(m.calendars) {
 		calName = m.calendars[m.nlpCalendarIdx].Title
@@ -200,8 +223,17 @@ func (m *CalendarApp) renderNLPConfirm() string {
 		}
 	}
 	b.WriteString(boxRow(i18n.T("calendar.field.reminder"), reminderStr, 35))
+	if leave := leaveByRow(m.settings, m.nlpStartTime, m.nlpParsed.Location, m.nlpAllDay); leave != "" {
+		b.WriteString(boxRow(i18n.T("calendar.field.leave_by"), leave, 35))
+	}
 	b.WriteString("  └────────────────────────────────────────────────┘\n")
 
+	if warning := m.nlpSlot.view(m.nlpStartTime, m.nlpEndTime); warning != "" {
+		b.WriteString("\n")
+		b.WriteString(lipgloss.NewStyle().PaddingLeft(2).Render(warning))
+		b.WriteString("\n")
+	}
+
 	b.WriteString("\n")
 	b.WriteString(hintStyle.Render(fmt.Sprintf("enter %s • esc %s", i18n.T("calendar.create"), i18n.T("help.cancel"))))
 
@@ -218,8 +250,9 @@ func (m *CalendarApp) renderNLPEventBox() string {
 
 	fmt.Fprintf(&b, "  ┌─ %s ─────────────────────────────────┐\n", i18n.T("calendar.parsed_event"))
 	b.WriteString(boxRow(i18n.T("calendar.field.title"), utils.TruncateStr(m.nlpParsed.Title, 37)))
-	b.WriteString(boxRow(i18n.T("calendar.field.date")+" ", m.nlpStartTime.Format("Monday, Jan 2, 2006")))
-	b.WriteString(boxRow(i18n.T("calendar.field.time")+" ", fmt.Sprintf("%s - %s", m.nlpStartTime.Format("3:04 PM"), m.nlpEndTime.Format("3:04 PM"))))
+	date, clock := eventWhen(m.nlpStartTime, m.nlpEndTime, m.nlpAllDay)
+	b.WriteString(boxRow(i18n.T("calendar.field.date")+" ", date))
+	b.WriteString(boxRow(i18n.T("calendar.field.time")+" ", clock))
 	if m.nlpParsed.Location != "" {
 		b.WriteString(boxRow(i18n.T("calendar.field.location"), utils.TruncateStr(m.nlpParsed.Location, 37)))
 	}
@@ -260,11 +293,11 @@ func (m *CalendarApp) renderFormDateTime() string {
 		if focused {
 			style = focusedLabel
 		}
-		row := fmt.Sprintf("    %s%s", style.Render(label), value)
+		hint := ""
 		if focused && showHint {
-			row += hintStyle.Render(fmt.Sprintf("  %s", i18n.T("calendar.picker_hint")))
+			hint = hintStyle.Render(fmt.Sprintf("  %s", i18n.T("calendar.picker_hint")))
 		}
-		return row + "\n"
+		return lipgloss.JoinHorizontal(lipgloss.Top, "    ", style.Render(label), value, hint) + "\n"
 	}
 
 	fmt.Fprintf(&b, "%s  %s\n\n", titleStyle.Render(i18n.T("calendar.new_event")), stepStyle.Render(i18n.T("calendar.step", map[string]any{"Current": 2, "Total": 4})))
@@ -276,15 +309,19 @@ func (m *CalendarApp) renderFormDateTime() string {
 
 	fmt.Fprintf(&b, "  %s\n\n", i18n.T("calendar.when_event"))
 
-	// Date, Start, End, Location fields
-	b.WriteString(field(m.formFocusField == 0, i18n.T("calendar.field.date"), m.formDateInput.View(), true))
-	b.WriteString(field(m.formFocusField == 1, i18n.T("calendar.field.start"), m.formStartInput.View(), true))
-	b.WriteString(field(m.formFocusField == 2, i18n.T("calendar.field.end"), m.formEndInput.View(), true))
-	b.WriteString(field(m.formFocusField == 3, i18n.T("calendar.field.location"), m.formLocationInput.View(), false))
+	// Date, End date, All day, Start, End, Time zone, Location, Attendees fields
+	b.WriteString(field(m.formFocusField == 0, i18n.T("calendar.field.date"), datePickerView(m.formDateInput), false))
+	b.WriteString(field(m.formFocusField == 1, i18n.T("calendar.field.end_date"), datePickerView(m.formEndDateInput), false))
+	b.WriteString(field(m.formFocusField == 2, i18n.T("calendar.field.all_day"), renderToggle(m.formAllDay, m.formFocusField == 2), false))
+	b.WriteString(field(m.formFocusField == 3, i18n.T("calendar.field.start"), timeView(m.formStartInput, m.formAllDay), !m.formAllDay))
+	b.WriteString(field(m.formFocusField == 4, i18n.T("calendar.field.end"), timeView(m.formEndInput, m.formAllDay), !m.formAllDay))
+	b.WriteString(field(m.formFocusField == 5, i18n.T("calendar.field.zone"), m.formZoneInput.View(), false))
+	b.WriteString(field(m.formFocusField == 6, i18n.T("calendar.field.location"), m.formLocationInput.View(), false))
+	b.WriteString(field(m.formFocusField == 7, i18n.T("calendar.field.attendees"), m.formAttendeesInput.View(), false))
 
 	// Notes
 	b.WriteString("\n")
-	b.WriteString(field(m.formFocusField == 4, i18n.T("calendar.field.notes"), "", false))
+	b.WriteString(field(m.formFocusField == 8, i18n.T("calendar.field.notes"), "", false))
 	fmt.Fprintf(&b, "    %s\n", m.formNotesInput.View())
 
 	// Error
@@ -399,11 +436,18 @@ func (m *CalendarApp) renderFormConfirm() string {
 	// Event details box
 	b.WriteString("  ┌────────────────────────────────────────────────┐\n")
 	b.WriteString(boxRow(i18n.T("calendar.field.title"), utils.TruncateStr(m.formTitleInput.Value(), 35)))
-	b.WriteString(boxRow(i18n.T("calendar.field.date")+" ", startTime.Format("Monday, Jan 2, 2006")))
-	b.WriteString(boxRow(i18n.T("calendar.field.time")+" ", fmt.Sprintf("%s - %s", startTime.Format("3:04 PM"), endTime.Format("3:04 PM"))))
+	date, clock := eventWhen(startTime, endTime, m.formAllDay)
+	b.WriteString(boxRow(i18n.T("calendar.field.date")+" ", date))
+	b.WriteString(boxRow(i18n.T("calendar.field.time")+" ", clock))
+	if here := zoneHere(m.getFormZone(), startTime); here != "" {
+		b.WriteString(boxRow(i18n.T("calendar.field.zone"), utils.TruncateStr(here, 35)))
+	}
 	if m.formLocationInput.Value() != "" {
 		b.WriteString(boxRow(i18n.T("calendar.field.location"), utils.TruncateStr(m.formLocationInput.Value(), 35)))
 	}
+	if len(m.formAttendees) > 0 {
+		b.WriteString(boxRow(i18n.T("calendar.field.attendees"), utils.TruncateStr(attendeeNames(m.formAttendees), 35)))
+	}
 	calName := i18n.T("calendar.default")
 	if len(m.calendars) > 0 && m.formCalendarIdx < len(m.calendars) {
 		calName = m.calendars[m.formCalendarIdx].Title
@@ -418,6 +462,9 @@ func (m *CalendarApp) renderFormConfirm() string {
 		}
 	}
 	b.WriteString(boxRow(i18n.T("calendar.field.reminder"), reminderStr))
+	if leave := leaveByRow(m.settings, startTime, m.formLocationInput.Value(), m.formAllDay); leave != "" {
+		b.WriteString(boxRow(i18n.T("calendar.field.leave_by"), leave))
+	}
 	b.WriteString("  └────────────────────────────────────────────────┘\n")
 
 	b.WriteString("\n")
@@ -439,8 +486,9 @@ func (m *CalendarApp) renderFormEventBox() string {
 
 	fmt.Fprintf(&b, "  ┌─ %s ──────────────────────────────────────────┐\n", i18n.T("calendar.event"))
 	b.WriteString(boxRow(i18n.T("calendar.field.title"), utils.TruncateStr(m.formTitleInput.Value(), 39)))
-	b.WriteString(boxRow(i18n.T("calendar.field.date")+" ", startTime.Format("Monday, Jan 2, 2006")))
-	b.WriteString(boxRow(i18n.T("calendar.field.time")+" ", fmt.Sprintf("%s - %s", startTime.Format("3:04 PM"), endTime.Format("3:04 PM"))))
+	date, clock := eventWhen(startTime, endTime, m.formAllDay)
+	b.WriteString(boxRow(i18n.T("calendar.field.date")+" ", date))
+	b.WriteString(boxRow(i18n.T("calendar.field.time")+" ", clock))
 	if m.formLocationInput.Value() != "" {
 		b.WriteString(boxRow(i18n.T("calendar.field.location"), utils.TruncateStr(m.formLocationInput.Value(), 39)))
 	}
@@ -0,0 +1,168 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+
+	"maily/internal/calendar"
+	"maily/internal/i18n"
+	"maily/internal/ui/components"
+	"maily/internal/ui/utils"
+)
+
+// searchLoadedMsg carries the events calendar search looks through
+type searchLoadedMsg struct {
+	events []calendar.Event
+	err    error
+}
+
+// searchSpan returns the days calendar search covers, searchDays either side of today
+func (m *CalendarApp) searchSpan() (from, to time.Time) {
+	now := time.Now()
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.Local)
+	return today.AddDate(0, 0, -m.searchDays), today.AddDate(0, 0, m.searchDays+1)
+}
+
+// startSearch opens calendar search and loads the events it looks through, so the
+// matches can follow the query as it's typed
+func (m *CalendarApp) startSearch() tea.Cmd {
+	m.searchInput = textinput.New()
+	m.searchInput.Placeholder = i18n.T("calendar.search.placeholder")
+	m.searchInput.CharLimit = 100
+	m.searchInput.Width = 40
+	m.searchInput.Focus()
+	m.searchEvents = nil
+	m.searchResults = nil
+	m.searchIdx = 0
+	m.searchLoading = true
+	m.err = nil
+	m.view = viewSearch
+
+	client := m.client
+	from, to := m.searchSpan()
+	return tea.Batch(textinput.Blink, func() tea.Msg {
+		events, err := client.ListEvents(from, to)
+		return searchLoadedMsg{events: events, err: err}
+	})
+}
+
+func (m *CalendarApp) handleSearchKeys(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "esc":
+		m.view = viewCalendar
+		return m, nil
+	case "up", "ctrl+p":
+		if m.searchIdx > 0 {
+			m.searchIdx--
+		}
+		return m, nil
+	case "down", "ctrl+n":
+		if m.searchIdx < len(m.searchResults)-1 {
+			m.searchIdx++
+		}
+		return m, nil
+	case "enter":
+		if m.searchIdx < len(m.searchResults) {
+			return m, m.jumpToEvent(m.searchResults[m.searchIdx])
+		}
+		return m, nil
+	}
+
+	var cmd tea.Cmd
+	m.searchInput, cmd = m.searchInput.Update(msg)
+	m.searchResults = calendar.Search(m.searchEvents, m.searchInput.Value())
+	m.searchIdx = 0
+	return m, cmd
+}
+
+// jumpToEvent moves the calendar to the event's day and selects it once the month's
+// events are loaded
+func (m *CalendarApp) jumpToEvent(event calendar.Event) tea.Cmd {
+	m.selectedDate = event.StartTime.In(time.Local)
+	m.selectedIdx = 0
+	m.jumpEventID = event.ID
+	m.view = viewCalendar
+	return m.loadEvents()
+}
+
+// selectJumpedEvent selects the event search jumped to among the selected day's events
+func (m *CalendarApp) selectJumpedEvent() {
+	for i, e := range m.eventsForDate(m.selectedDate) {
+		if e.ID == m.jumpEventID {
+			m.selectedIdx = i
+			break
+		}
+	}
+	m.jumpEventID = ""
+}
+
+func (m *CalendarApp) renderSearch() string {
+	var b strings.Builder
+
+	titleStyle := lipgloss.NewStyle().Bold(true).Foreground(components.Primary)
+	hintStyle := lipgloss.NewStyle().Foreground(components.Muted)
+	dateStyle := lipgloss.NewStyle().Foreground(components.Muted).Width(24)
+	itemStyle := lipgloss.NewStyle().Foreground(components.Text)
+	cursorStyle := lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("#FFFFFF")).Background(components.Primary)
+
+	from, to := m.searchSpan()
+	b.WriteString(titleStyle.Render(i18n.T("calendar.search.title")))
+	b.WriteString("  ")
+	b.WriteString(hintStyle.Render(i18n.T("calendar.search.range", map[string]any{
+		"From": from.Format("Jan 2, 2006"),
+		"To":   to.AddDate(0, 0, -1).Format("Jan 2, 2006"),
+	})))
+	b.WriteString("\n\n")
+	b.WriteString("/ " + m.searchInput.View())
+	b.WriteString("\n\n")
+
+	switch {
+	case m.err != nil:
+		b.WriteString(lipgloss.NewStyle().Foreground(components.Danger).Render(fmt.Sprintf("%s: %v", i18n.T("common.error"), m.err)))
+		b.WriteString("\n")
+	case m.searchLoading:
+		b.WriteString(hintStyle.Render(i18n.T("calendar.search.loading")))
+		b.WriteString("\n")
+	case strings.TrimSpace(m.searchInput.Value()) == "":
+	case len(m.searchResults) == 0:
+		b.WriteString(hintStyle.Render(i18n.T("calendar.search.none")))
+		b.WriteString("\n")
+	default:
+		b.WriteString(hintStyle.Render(i18n.T("calendar.search.matches", map[string]any{"Count": len(m.searchResults)})))
+		b.WriteString("\n\n")
+
+		// Keep the selected match in view
+		rows := max(m.height-12, 5)
+		first := max(0, min(m.searchIdx-rows/2, len(m.searchResults)-rows))
+		last := min(first+rows, len(m.searchResults))
+		for i := first; i < last; i++ {
+			e := m.searchResults[i]
+			when := e.StartTime.In(time.Local).Format("Mon, Jan 2 2006 3:04 PM")
+			if e.AllDay {
+				when = e.StartTime.In(time.Local).Format("Mon, Jan 2 2006")
+			}
+			title := utils.TruncateStr(e.Title, max(m.width-50, 20))
+			row := dateStyle.Render(when)
+			if i == m.searchIdx {
+				row += cursorStyle.Render(" " + title + " ")
+			} else {
+				row += itemStyle.Render(" " + title + " ")
+			}
+			if e.Location != "" {
+				row += hintStyle.Render(" " + utils.TruncateStr(e.Location, 30))
+			}
+			b.WriteString(row)
+			b.WriteString("\n")
+		}
+	}
+
+	b.WriteString("\n")
+	b.WriteString(hintStyle.Render(fmt.Sprintf("↑↓ %s • enter %s • esc %s", i18n.T("help.navigate"), i18n.T("calendar.search.jump"), i18n.T("help.cancel"))))
+
+	return lipgloss.NewStyle().Padding(1, 2).Render(b.String())
+}
@@ -0,0 +1,46 @@
+package ui
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"maily/internal/calendar"
+)
+
+func TestExportEventKeepsExistingFiles(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	event := calendar.Event{
+		Title:     "Plan: Q3/Q4",
+		StartTime: time.Date(2025, 6, 2, 9, 0, 0, 0, time.Local),
+		EndTime:   time.Date(2025, 6, 2, 10, 0, 0, 0, time.Local),
+	}
+	existing := filepath.Join(home, "2025-06-02 Plan Q3Q4.ics")
+	if err := os.WriteFile(existing, []byte("keep me"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	var paths []string
+	for range 2 {
+		msg := exportEvent(event)().(eventExportedMsg)
+		if msg.err != nil {
+			t.Fatal(msg.err)
+		}
+		paths = append(paths, msg.path)
+	}
+
+	want := []string{
+		filepath.Join(home, "2025-06-02 Plan Q3Q4 (1).ics"),
+		filepath.Join(home, "2025-06-02 Plan Q3Q4 (2).ics"),
+	}
+	for i := range want {
+		if paths[i] != want[i] {
+			t.Errorf("export %d saved to %q, want %q", i+1, paths[i], want[i])
+		}
+	}
+	if data, _ := os.ReadFile(existing); string(data) != "keep me" {
+		t.Errorf("existing file was overwritten with %q", data)
+	}
+}
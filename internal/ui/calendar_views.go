@@ -39,8 +39,12 @@ func (m *CalendarApp) renderCalendar() string {
 		Width(7).
 		Align(lipgloss.Center)
 
-	for _, day := range weekdays {
-		b.WriteString(headerStyle.Render(day))
+	if m.settings.ShowWeekNumbers() {
+		b.WriteString(strings.Repeat(" ", weekGutter))
+	}
+	first := int(i18n.FirstWeekday())
+	for i := range weekdays {
+		b.WriteString(headerStyle.Render(weekdays[(first+i)%7]))
 	}
 	b.WriteString("\n")
 
@@ -85,6 +89,9 @@ func (m *CalendarApp) renderCalendar() string {
 		errStyle := lipgloss.NewStyle().Foreground(components.Danger)
 		b.WriteString(errStyle.Render(fmt.Sprintf("Error: %v", m.err)))
 		b.WriteString("\n")
+	} else if m.notice != "" {
+		b.WriteString(lipgloss.NewStyle().Foreground(components.Success).Render(m.notice))
+		b.WriteString("\n")
 	}
 
 	// Help bar
@@ -106,35 +113,34 @@ func (m *CalendarApp) renderMonthGrid() string {
 	todayStr := today.Format("2006-01-02")
 	selectedStr := m.selectedDate.Format("2006-01-02")
 
-	// Start from the Sunday of the first week
-	startDay := firstDay.AddDate(0, 0, -int(firstDay.Weekday()))
+	startDay, weeks := m.monthGrid()
 
 	dayStyle := lipgloss.NewStyle().Width(7).Align(lipgloss.Center)
 	selectedStyle := dayStyle.Background(components.Primary).Foreground(components.Text)
 	todayStyle := dayStyle.Bold(true).Foreground(components.Secondary)
 	otherMonthStyle := dayStyle.Foreground(components.Muted)
-	hasEventStyle := lipgloss.NewStyle().Foreground(components.Success)
+	weekStyle := lipgloss.NewStyle().Foreground(components.Muted).Width(weekGutter)
 
-	for week := 0; week < 6; week++ {
+	for week := 0; week < weeks; week++ {
+		if m.settings.ShowWeekNumbers() {
+			b.WriteString(weekStyle.Render(fmt.Sprintf("%3d", isoWeek(startDay.AddDate(0, 0, week*7)))))
+		}
 		for dow := 0; dow < 7; dow++ {
 			day := startDay.AddDate(0, 0, week*7+dow)
 			dayStr := day.Format("2006-01-02")
 
-			// Check if this day has events
-			hasEvents := false
+			// A dot in its calendar's color for each calendar with events on the day,
+			// counting every day of multi-day ones
+			var dots []string
+			marked := make(map[string]bool)
 			for _, e := range m.events {
-				if e.StartTime.Format("2006-01-02") == dayStr {
-					hasEvents = true
-					break
+				if len(dots) < maxDayDots && e.On(day) && !marked[e.Calendar] {
+					marked[e.Calendar] = true
+					dots = append(dots, lipgloss.NewStyle().Foreground(m.calendarColor(e.Calendar)).Render("•"))
 				}
 			}
 
-			content := fmt.Sprintf("%2d", day.Day())
-			if hasEvents {
-				content += hasEventStyle.Render("•")
-			} else {
-				content += " "
-			}
+			content := fmt.Sprintf("  %2d", day.Day()) + strings.Join(dots, "") + strings.Repeat(" ", maxDayDots-len(dots))
 
 			var style lipgloss.Style
 			switch {
@@ -153,21 +159,86 @@ func (m *CalendarApp) renderMonthGrid() string {
 			b.WriteString(style.Render(content))
 		}
 		b.WriteString("\n")
+	}
+
+	return b.String()
+}
+
+// maxDayDots is how many calendars' dots fit beside a day in the month grid
+const maxDayDots = 3
+
+// weekGutter is the width of the week numbers left of the month grid
+const weekGutter = 4
+
+// isoWeek returns the ISO week number of the grid row starting on day: the week of
+// the row's Monday, which holds most of its days whichever day weeks start on
+func isoWeek(day time.Time) int {
+	offset := (int(time.Monday) - int(day.Weekday()) + 7) % 7
+	_, week := day.AddDate(0, 0, offset).ISOWeek()
+	return week
+}
 
-		// Stop if we've passed the last day of the month and completed the week
-		if startDay.AddDate(0, 0, (week+1)*7).After(lastDay) && week >= 3 {
-			break
+// calendarColor returns the color of the named calendar: the one set in the config,
+// else the calendar's own, else the theme's
+func (m *CalendarApp) calendarColor(name string) lipgloss.TerminalColor {
+	if color := m.settings.Color(name); color != "" {
+		return lipgloss.Color(color)
+	}
+	for _, cal := range m.calendars {
+		if cal.Title == name && cal.Color != "" {
+			return lipgloss.Color(cal.Color)
 		}
 	}
+	return components.Secondary
+}
 
-	return b.String()
+// monthGrid returns the first day shown in the month grid, the first day of the
+// month's first week, and how many weeks it shows
+func (m *CalendarApp) monthGrid() (time.Time, int) {
+	year, month, _ := m.selectedDate.Date()
+	firstDay := time.Date(year, month, 1, 0, 0, 0, 0, m.selectedDate.Location())
+	lastDay := firstDay.AddDate(0, 1, -1)
+	startDay := firstDay.AddDate(0, 0, -(int(firstDay.Weekday()-i18n.FirstWeekday())+7)%7)
+
+	// Stop once the last day of the month is shown, but always show at least four weeks
+	weeks := 4
+	for weeks < 6 && !startDay.AddDate(0, 0, weeks*7).After(lastDay) {
+		weeks++
+	}
+	return startDay, weeks
+}
+
+// dayAt returns the day whose cell in the month grid is drawn at x, y
+func (m *CalendarApp) dayAt(x, y int) (time.Time, bool) {
+	// renderCalendar's padding, then the month and weekday headers above the grid
+	const gridTop = 4
+	gridLeft := 2
+	if m.settings.ShowWeekNumbers() {
+		gridLeft += weekGutter
+	}
+	startDay, weeks := m.monthGrid()
+	week, dow := y-gridTop, (x-gridLeft)/7
+	if week < 0 || week >= weeks || x < gridLeft || dow > 6 {
+		return time.Time{}, false
+	}
+	return startDay.AddDate(0, 0, week*7+dow), true
 }
 
 func (m *CalendarApp) renderEvent(event calendar.Event, selected bool) string {
 	var timeStr string
-	if event.AllDay {
+	day, days := event.DayOf(m.selectedDate)
+	switch {
+	case days > 1 && event.AllDay:
+		timeStr = i18n.T("calendar.day_of", map[string]any{"Day": day, "Days": days})
+	case days > 1 && day == 1:
+		timeStr = event.StartTime.Format("3:04 PM") + " →"
+	case days > 1 && day == days:
+		timeStr = "→ " + event.EndTime.Format("3:04 PM")
+	case days > 1:
+		timeStr = i18n.T("calendar.day_of", map[string]any{"Day": day, "Days": days})
+	case event.AllDay:
 		timeStr = i18n.T("calendar.all_day")
-	} else {
+	default:
 		timeStr = fmt.Sprintf("%s - %s", event.StartTime.Format("3:04 PM"), event.EndTime.Format("3:04 PM"))
 	}
 
@@ -186,14 +257,75 @@ func (m *CalendarApp) renderEvent(event calendar.Event, selected bool) string {
 		prefix = "  "
 	}
 
-	line := prefix + timeStyle.Render(timeStr) + titleStyle.Render(event.Title)
+	dot := lipgloss.NewStyle().Foreground(m.calendarColor(event.Calendar)).Render("● ")
+	line := prefix + dot + timeStyle.Render(timeStr) + titleStyle.Render(event.Title)
 	if event.Calendar != "" {
 		line += calStyle.Render(fmt.Sprintf(" [%s]", event.Calendar))
 	}
+	if event.Elsewhere() {
+		note := i18n.T("calendar.zone_unknown", map[string]any{"Zone": event.TimeZone})
+		if zone, err := calendar.Location(event.TimeZone); err == nil {
+			there := event.StartTime.In(zone).Format("3:04 PM")
+			note = i18n.T("calendar.zone_there", map[string]any{"Time": there, "Zone": event.TimeZone})
+		}
+		line += lipgloss.NewStyle().Foreground(components.Muted).Render("  ◷ " + note)
+	}
 
 	return line
 }
 
+// eventWhen describes when an event is for the confirmation and detail views: its day,
+// or the first and last of the days it spans, and its times
+func eventWhen(start, end time.Time, allDay bool) (date, clock string) {
+	date = start.Format("Monday, Jan 2, 2006")
+	if _, days := (calendar.Event{StartTime: start, EndTime: end}).DayOf(start); days > 1 {
+		date = start.Format("Mon, Jan 2") + " - " + end.Add(-time.Nanosecond).Format("Mon, Jan 2, 2006")
+	}
+	if allDay {
+		return date, i18n.T("calendar.all_day")
+	}
+	return date, fmt.Sprintf("%s - %s", start.Format("3:04 PM"), end.Format("3:04 PM"))
+}
+
+// datePickerView renders a form's date picker, with the keys that aren't arrows below
+// the month grid while it's focused
+func datePickerView(picker components.DatePicker) string {
+	if !picker.Focused() {
+		return picker.View()
+	}
+	hint := lipgloss.NewStyle().Foreground(components.Muted).Render(i18n.T("calendar.date_picker_hint"))
+	return picker.View() + "\n" + hint
+}
+
+// renderToggle renders an on/off form field such as "All day"
+func renderToggle(on, focused bool) string {
+	box := "[ ]"
+	if on {
+		box = "[✓]"
+	}
+	if focused {
+		return lipgloss.NewStyle().Foreground(components.Primary).Bold(true).Render(box)
+	}
+	return box
+}
+
+// timeView renders a form's time picker, dimmed to a dash for all-day events
+func timeView(picker components.TimePicker, allDay bool) string {
+	if allDay {
+		return lipgloss.NewStyle().Foreground(components.Muted).Render("—")
+	}
+	return picker.View()
+}
+
+// zoneHere describes an event time on zone's clock for the confirmation, with the
+// local time it is, e.g. "Europe/Berlin (9:00 AM here)"; empty when the clocks agree
+func zoneHere(zone string, start time.Time) string {
+	if !(calendar.Event{TimeZone: zone, StartTime: start}).Elsewhere() {
+		return ""
+	}
+	return i18n.T("calendar.zone_here", map[string]any{"Zone": zone, "Time": start.In(time.Local).Format("3:04 PM")})
+}
+
 func (m *CalendarApp) renderHelpBar() string {
 	helpStyle := lipgloss.NewStyle().Foreground(components.Muted)
 	keyStyle := lipgloss.NewStyle().Bold(true).Foreground(components.Secondary)
@@ -219,6 +351,7 @@ func (m *CalendarApp) renderHelpBar() string {
 		key("m", i18n.T("calendar.nav.month")),
 		key("y", i18n.T("calendar.nav.year")),
 		key("t", i18n.T("calendar.today")),
+		key("/", i18n.T("help.search")),
 	}
 
 	// Row 2: Actions
@@ -227,6 +360,7 @@ func (m *CalendarApp) renderHelpBar() string {
 		key("n", i18n.T("calendar.action.new")),
 		key("e", i18n.T("help.edit")),
 		key("d", i18n.T("help.delete")),
+		key("E", i18n.T("calendar.action.export")),
 		key("q", i18n.T("help.quit")),
 	}
 
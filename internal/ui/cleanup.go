@@ -0,0 +1,83 @@
+package ui
+
+import (
+	"fmt"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/emersion/go-imap/v2"
+
+	"maily/internal/cache"
+	"maily/internal/retention"
+)
+
+// cleanupLoadedMsg carries the current folder's cached mail grouped for the cleanup wizard
+type cleanupLoadedMsg struct {
+	mailbox string
+	groups  []retention.Group
+	err     error
+}
+
+// cleanupDoneMsg reports that the mail of some cleanup groups was queued for archiving
+// or moving to trash
+type cleanupDoneMsg struct {
+	operation string
+	keys      []string
+	count     int
+	err       error
+}
+
+// loadCleanup groups the current folder's cached mail by mailing list and sender
+func (a App) loadCleanup() tea.Cmd {
+	accountEmail := ""
+	if account := a.currentAccount(); account != nil {
+		accountEmail = account.Credentials.Email
+	}
+	mailbox := a.currentLabel
+	diskCache := a.diskCache
+
+	return func() tea.Msg {
+		if diskCache == nil {
+			return cleanupLoadedMsg{mailbox: mailbox, err: fmt.Errorf("cache unavailable")}
+		}
+		sizes, err := diskCache.LoadEmailSizes(accountEmail, mailbox)
+		if err != nil {
+			return cleanupLoadedMsg{mailbox: mailbox, err: err}
+		}
+		return cleanupLoadedMsg{mailbox: mailbox, groups: retention.GroupBySender(sizes)}
+	}
+}
+
+// runCleanup queues the confirmed operation for every email of the selected groups
+func (a App) runCleanup() tea.Cmd {
+	operation := a.cleanup.Pending()
+	groups := a.cleanup.Selected()
+	mailbox := a.cleanup.Mailbox()
+	accountEmail := ""
+	if account := a.currentAccount(); account != nil {
+		accountEmail = account.Credentials.Email
+	}
+	serverClient := a.serverClient
+
+	var uids []imap.UID
+	keys := make([]string, len(groups))
+	for i, g := range groups {
+		keys[i] = g.Key
+		uids = append(uids, g.UIDs...)
+	}
+
+	return func() tea.Msg {
+		if serverClient == nil {
+			return cleanupDoneMsg{err: fmt.Errorf("server unavailable")}
+		}
+		var err error
+		if operation == cache.OpArchive {
+			err = serverClient.QueueArchiveMulti(accountEmail, mailbox, uids)
+		} else {
+			err = serverClient.QueueMoveMultiToTrash(accountEmail, mailbox, uids)
+		}
+		if err != nil {
+			return cleanupDoneMsg{err: err}
+		}
+		return cleanupDoneMsg{operation: operation, keys: keys, count: len(uids)}
+	}
+}
@@ -0,0 +1,80 @@
+package ui
+
+import (
+	"strings"
+
+	"maily/internal/i18n"
+	"maily/internal/mail"
+	"maily/internal/ui/utils"
+)
+
+// startCopy waits for the key naming the field of the selected email to copy (y)
+func (a *App) startCopy() {
+	if a.mailList.SelectedEmail() == nil {
+		return
+	}
+	a.copyPending = true
+	a.statusMsg = i18n.T("copy.prompt")
+}
+
+// copyField copies a field of the selected email to the clipboard: the sender's
+// address (f), subject (s), Message-ID (m) or a permalink to the email (l)
+func (a *App) copyField(key string) {
+	a.copyPending = false
+	email := a.mailList.SelectedEmail()
+	if email == nil {
+		a.statusMsg = ""
+		return
+	}
+
+	var value, field string
+	switch key {
+	case "f":
+		value, field = extractEmail(email.From), i18n.T("copy.sender")
+	case "s":
+		value, field = email.Subject, i18n.T("copy.subject")
+	case "m":
+		value, field = messageIDHeader(email.MessageID), "Message-ID"
+	case "l":
+		value, field = a.permalink(email), i18n.T("copy.link")
+		if value == "" {
+			a.statusMsg = i18n.T("copy.no_link")
+			return
+		}
+	default:
+		a.statusMsg = ""
+		return
+	}
+	if value == "" {
+		a.statusMsg = i18n.T("copy.empty", map[string]any{"Field": field})
+		return
+	}
+	if err := utils.CopyToClipboard(value); err != nil {
+		a.statusMsg = i18n.T("copy.failed", map[string]any{"Error": err})
+		return
+	}
+	a.statusMsg = i18n.T("copy.done", map[string]any{"Field": field, "Value": value})
+}
+
+// permalink returns a link that opens the email in the provider's web UI, or "" when
+// the provider has none that finds a single email
+func (a App) permalink(email *mail.Email) string {
+	account := a.currentAccount()
+	if account == nil {
+		return ""
+	}
+	link, specific := account.WebMailURL(email.MessageID, email.Subject)
+	if !specific {
+		return ""
+	}
+	return link
+}
+
+// messageIDHeader formats a Message-ID the way it appears in headers, in angle brackets
+func messageIDHeader(id string) string {
+	id = strings.Trim(id, "<> ")
+	if id == "" {
+		return ""
+	}
+	return "<" + id + ">"
+}
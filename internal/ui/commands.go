@@ -1,21 +1,35 @@
 package ui
 
 import (
+	"context"
 	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
 	"strings"
 	"time"
 
 	"github.com/charmbracelet/bubbles/textinput"
+	"github.com/charmbracelet/bubbles/viewport"
 	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
 	"github.com/emersion/go-imap/v2"
+	"maily/config"
 	"maily/internal/ai"
+	"maily/internal/auth"
 	"maily/internal/cache"
 	"maily/internal/calendar"
+	"maily/internal/client"
+	"maily/internal/export"
+	"maily/internal/i18n"
 	"maily/internal/mail"
+	"maily/internal/server"
+	"maily/internal/ui/components"
+	"maily/internal/ui/utils"
 )
 
 type bulkActionCompleteMsg struct {
-	action string
+	action string // message reporting the action, pluralized by count
 	count  int
 }
 
@@ -110,7 +124,7 @@ func (a *App) markSelectedAsRead() tea.Cmd {
 
 	return func() tea.Msg {
 		if len(uids) == 0 {
-			return bulkActionCompleteMsg{action: "marked as read", count: 0}
+			return bulkActionCompleteMsg{action: "email.marked_read", count: 0}
 		}
 
 		if serverClient == nil {
@@ -119,7 +133,7 @@ func (a *App) markSelectedAsRead() tea.Cmd {
 		if err := serverClient.MarkMultiRead(accountEmail, mailbox, uids); err != nil {
 			return errorMsg{err: err, accountEmail: accountEmail}
 		}
-		return bulkActionCompleteMsg{action: "marked as read", count: len(uids)}
+		return bulkActionCompleteMsg{action: "email.marked_read", count: len(uids)}
 	}
 }
 
@@ -142,7 +156,7 @@ func (a *App) deleteSelectedEmails() tea.Cmd {
 
 	return func() tea.Msg {
 		if len(uids) == 0 {
-			return bulkActionCompleteMsg{action: "deleted", count: 0}
+			return bulkActionCompleteMsg{action: "email.deleted", count: 0}
 		}
 		if serverClient == nil {
 			return errorMsg{err: fmt.Errorf("server unavailable"), accountEmail: accountEmail}
@@ -150,7 +164,7 @@ func (a *App) deleteSelectedEmails() tea.Cmd {
 		if err := serverClient.QueueDeleteMulti(accountEmail, mailbox, uids); err != nil {
 			return errorMsg{err: err, accountEmail: accountEmail}
 		}
-		return bulkActionCompleteMsg{action: "deleted", count: len(uids)}
+		return bulkActionCompleteMsg{action: "email.deleted", count: len(uids)}
 	}
 }
 
@@ -193,7 +207,7 @@ func (a *App) moveSelectedToTrash() tea.Cmd {
 
 	return func() tea.Msg {
 		if len(uids) == 0 {
-			return bulkActionCompleteMsg{action: "moved to trash", count: 0}
+			return bulkActionCompleteMsg{action: "email.trashed", count: 0}
 		}
 		if serverClient == nil {
 			return errorMsg{err: fmt.Errorf("server unavailable"), accountEmail: accountEmail}
@@ -201,7 +215,7 @@ func (a *App) moveSelectedToTrash() tea.Cmd {
 		if err := serverClient.QueueMoveMultiToTrash(accountEmail, mailbox, uids); err != nil {
 			return errorMsg{err: err, accountEmail: accountEmail}
 		}
-		return bulkActionCompleteMsg{action: "moved to trash", count: len(uids)}
+		return bulkActionCompleteMsg{action: "email.trashed", count: len(uids)}
 	}
 }
 
@@ -225,6 +239,358 @@ func (a *App) moveSingleToTrash(uid imap.UID) tea.Cmd {
 	}
 }
 
+// inSpecialFolder reports whether the current folder is the account's sent, trash or spam
+// folder. Until that folder was opened with its command, well-known names are recognized.
+func (a App) inSpecialFolder(kind string) bool {
+	if folder, ok := a.specialFolders[kind]; ok {
+		return a.currentLabel == folder
+	}
+	switch kind {
+	case mail.FolderSent:
+		return mail.IsSentFolder(a.currentLabel)
+	case mail.FolderTrash:
+		return mail.IsTrashFolder(a.currentLabel)
+	case mail.FolderSpam:
+		return mail.IsSpamFolder(a.currentLabel)
+	}
+	return false
+}
+
+// specialFolderKind returns which special folder is being viewed, or "" for other folders
+func (a App) specialFolderKind() string {
+	for _, kind := range []string{mail.FolderSent, mail.FolderTrash, mail.FolderSpam} {
+		if a.inSpecialFolder(kind) {
+			return kind
+		}
+	}
+	return ""
+}
+
+// reportSpam queues a move to the spam folder, or back to INBOX when notSpam is set
+func (a *App) reportSpam(uid imap.UID, notSpam bool) tea.Cmd {
+	account := a.currentAccount()
+	accountEmail := ""
+	if account != nil {
+		accountEmail = account.Credentials.Email
+	}
+	mailbox := a.currentLabel
+	serverClient := a.serverClient
+
+	return func() tea.Msg {
+		if serverClient == nil {
+			return errorMsg{err: fmt.Errorf("server unavailable"), accountEmail: accountEmail}
+		}
+		var err error
+		if notSpam {
+			err = serverClient.QueueNotSpam(accountEmail, mailbox, uid)
+		} else {
+			err = serverClient.QueueMoveToSpam(accountEmail, mailbox, uid)
+		}
+		if err != nil {
+			return errorMsg{err: err, accountEmail: accountEmail}
+		}
+		return spamReportedMsg{uid: uid, notSpam: notSpam}
+	}
+}
+
+// canEditLabels reports whether the current account has Gmail labels or notmuch tags:
+// whether its server advertised X-GM-EXT-1, whatever provider it was set up with
+func (a App) canEditLabels() bool {
+	account := a.currentAccount()
+	if account == nil {
+		return false
+	}
+	return account.Credentials.NotmuchDB != "" || a.syncStatus[account.Credentials.Email].labels
+}
+
+// loadNotmuchTags lists the tags of a notmuch database for the label editor
+func loadNotmuchTags(db string, current []string) tea.Cmd {
+	return func() tea.Msg {
+		tags, err := mail.NotmuchTags(db)
+		return notmuchTagsMsg{tags: tags, current: current, err: err}
+	}
+}
+
+// customLabels drops Gmail's system labels (\Inbox, \Important, ...)
+func customLabels(labels []string) []string {
+	var custom []string
+	for _, l := range labels {
+		if !mail.IsSystemLabel(l) {
+			custom = append(custom, l)
+		}
+	}
+	return custom
+}
+
+// updateLabels adds and removes Gmail labels on an email, leaving it in its folder
+func (a *App) updateLabels(email mail.Email, add, remove []string) tea.Cmd {
+	account := a.currentAccount()
+	accountEmail := ""
+	if account != nil {
+		accountEmail = account.Credentials.Email
+	}
+	mailbox := a.currentLabel
+	serverClient := a.serverClient
+
+	return func() tea.Msg {
+		if serverClient == nil {
+			return labelsUpdatedMsg{uid: email.UID, err: fmt.Errorf("server unavailable")}
+		}
+		if err := serverClient.UpdateLabels(accountEmail, mailbox, []imap.UID{email.UID}, add, remove); err != nil {
+			return labelsUpdatedMsg{uid: email.UID, err: err}
+		}
+
+		removed := make(map[string]bool, len(remove))
+		for _, l := range remove {
+			removed[l] = true
+		}
+		var labels []string
+		for _, l := range email.Labels {
+			if !removed[l] {
+				labels = append(labels, l)
+			}
+		}
+		labels = append(labels, add...)
+		return labelsUpdatedMsg{uid: email.UID, labels: labels}
+	}
+}
+
+// openSpecialFolder asks the server for the account's sent, trash or spam folder
+func (a *App) openSpecialFolder(kind string) tea.Cmd {
+	account := a.currentAccount()
+	accountEmail := ""
+	if account != nil {
+		accountEmail = account.Credentials.Email
+	}
+	serverClient := a.serverClient
+
+	return func() tea.Msg {
+		if serverClient == nil {
+			return specialFolderMsg{kind: kind, accountEmail: accountEmail, err: fmt.Errorf("server unavailable")}
+		}
+		folder, err := serverClient.GetSpecialFolder(accountEmail, kind)
+		return specialFolderMsg{kind: kind, folder: folder, accountEmail: accountEmail, err: err}
+	}
+}
+
+// restoreFromTrash moves an email from the trash back to the folder it was deleted from
+func (a *App) restoreFromTrash(uid imap.UID) tea.Cmd {
+	account := a.currentAccount()
+	accountEmail := ""
+	if account != nil {
+		accountEmail = account.Credentials.Email
+	}
+	trash := a.currentLabel
+	serverClient := a.serverClient
+
+	return func() tea.Msg {
+		if serverClient == nil {
+			return restoredMsg{uid: uid, err: fmt.Errorf("server unavailable")}
+		}
+		mailbox, err := serverClient.RestoreFromTrash(accountEmail, trash, uid)
+		return restoredMsg{uid: uid, mailbox: mailbox, err: err}
+	}
+}
+
+// emptyTrash permanently deletes everything in the account's trash
+func (a *App) emptyTrash() tea.Cmd {
+	account := a.currentAccount()
+	accountEmail := ""
+	if account != nil {
+		accountEmail = account.Credentials.Email
+	}
+	serverClient := a.serverClient
+
+	return func() tea.Msg {
+		if serverClient == nil {
+			return trashEmptiedMsg{err: fmt.Errorf("server unavailable")}
+		}
+		count, err := serverClient.EmptyTrash(accountEmail)
+		return trashEmptiedMsg{count: count, err: err}
+	}
+}
+
+// openCompose switches to the compose view with a prefilled model
+func (a App) openCompose(compose ComposeModel, account *auth.Account) (tea.Model, tea.Cmd) {
+	a.compose = compose
+	a.compose.SetSignature(a.cfg.Signature)
+	a.prepareCompose(account)
+	a.view = composeView
+	return a, tea.Batch(a.compose.Init(), a.loadSpeller())
+}
+
+// prepareCompose gives the email being written the address book, spell checker and
+// recipient check, the attachment limit of the account it is sent from, and its size
+func (a *App) prepareCompose(account *auth.Account) {
+	a.compose.SetContacts(a.contacts)
+	a.compose.SetSpellChecker(a.speller)
+	a.compose.SetDomainCheck(a.cfg.CheckRecipientDomains)
+	a.compose.SetAttachmentLimit(account)
+	a.compose.setSize(a.width, a.height)
+}
+
+// setNewsletters switches between the newsletters virtual folder and the regular grouped
+// list, leaving any smart folder either way
+func (a *App) setNewsletters(on bool) {
+	a.newsletters = on
+	a.smartFolder = nil
+	if on {
+		a.mailList.SetGrouping(components.GroupingListsOnly)
+	} else {
+		a.mailList.SetGrouping(components.GroupingLists)
+	}
+}
+
+// listLayout converts the configured list columns, density and avatars into the mail
+// list's layout
+func listLayout(c *config.ListConfig) components.ListLayout {
+	layout := components.ListLayout{Relaxed: c.Relaxed(), Avatars: c.Avatars()}
+	for _, col := range c.ListColumns() {
+		layout.Columns = append(layout.Columns, components.ListColumn{Name: col.Name, Width: col.Width})
+	}
+	return layout
+}
+
+// onMailingList reports whether the cursor is on a mailing list section or list email
+func (a App) onMailingList() bool {
+	id, _ := a.mailList.SelectedListID()
+	return id != ""
+}
+
+// toggleMuteList mutes or unmutes a mailing list and saves the choice to the config
+func (a *App) toggleMuteList(id, name string) tea.Cmd {
+	muted := make([]string, 0, len(a.cfg.MutedLists)+1)
+	wasMuted := false
+	for _, listID := range a.cfg.MutedLists {
+		if listID == id {
+			wasMuted = true
+			continue
+		}
+		muted = append(muted, listID)
+	}
+	if wasMuted {
+		a.statusMsg = i18n.T("list.unmuted", map[string]any{"List": name})
+	} else {
+		muted = append(muted, id)
+		a.statusMsg = i18n.T("list.muted", map[string]any{"List": name})
+	}
+	a.cfg.MutedLists = muted
+	a.mailList.SetMutedLists(muted)
+
+	return func() tea.Msg {
+		_, err := config.Update(func(cfg *config.Config) {
+			cfg.MutedLists = slices.DeleteFunc(cfg.MutedLists, func(listID string) bool { return listID == id })
+			if !wasMuted {
+				cfg.MutedLists = append(cfg.MutedLists, id)
+			}
+		})
+		return configSavedMsg{err: err}
+	}
+}
+
+// toggleThreadRule mutes or follows an email's thread in the disk cache, where sync
+// enforces it, or undoes the rule when the thread already has it
+func (a *App) toggleThreadRule(email mail.Email, rule string) tea.Cmd {
+	account := a.currentAccount()
+	if account == nil || a.diskCache == nil {
+		return nil
+	}
+	accountEmail := account.Credentials.Email
+	diskCache := a.diskCache
+	ids := cache.ThreadIDs(email.MessageID, email.References)
+
+	return func() tea.Msg {
+		current, err := diskCache.GetThreadRule(accountEmail, ids)
+		if err != nil {
+			return threadRuleSetMsg{rule: rule, err: err}
+		}
+		if current != nil && current.Rule == rule {
+			return threadRuleSetMsg{rule: rule, err: diskCache.SetThreadRule(accountEmail, ids, "")}
+		}
+		return threadRuleSetMsg{rule: rule, set: true, err: diskCache.SetThreadRule(accountEmail, ids, rule)}
+	}
+}
+
+// searchTerms returns the free-text terms of the active search, highlighted in opened emails
+func (a App) searchTerms() []string {
+	if !a.isSearchResult {
+		return nil
+	}
+	return components.QueryTerms(a.searchQuery)
+}
+
+// defaultSaveDir is where the save-attachments picker starts: ~/Downloads if it exists, else home
+func defaultSaveDir() string {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "/"
+	}
+	if info, err := os.Stat(filepath.Join(home, "Downloads")); err == nil && info.IsDir() {
+		return filepath.Join(home, "Downloads")
+	}
+	return home
+}
+
+// canUnsubscribe reports whether the selected email has a usable List-Unsubscribe header
+func (a App) canUnsubscribe() bool {
+	email := a.mailList.SelectedEmail()
+	if email == nil {
+		return false
+	}
+	_, ok := mail.ParseUnsubscribe(email.ListUnsubscribe, email.ListUnsubscribePost)
+	return ok
+}
+
+// unsubscribe performs a one-click unsubscribe when supported, otherwise opens the
+// unsubscribe link in the browser or a prefilled compose for mailto: targets
+func (a App) unsubscribe(email *mail.Email) (tea.Model, tea.Cmd) {
+	target, ok := mail.ParseUnsubscribe(email.ListUnsubscribe, email.ListUnsubscribePost)
+	if !ok {
+		a.statusMsg = i18n.T("unsubscribe.unavailable")
+		return a, nil
+	}
+
+	if target.OneClick {
+		a.state = stateLoading
+		a.statusMsg = i18n.T("unsubscribe.sending")
+		return a, tea.Batch(a.spinner.Tick, oneClickUnsubscribe(target.URL))
+	}
+
+	if target.URL != "" {
+		if err := utils.OpenURL(target.URL); err != nil {
+			a.statusMsg = i18n.T("unsubscribe.failed", map[string]any{"Error": err})
+		} else {
+			a.statusMsg = i18n.T("unsubscribe.opened")
+		}
+		return a, nil
+	}
+
+	account := a.currentAccount()
+	if account == nil {
+		return a, nil
+	}
+	m, err := mail.ParseMailto(target.Mailto)
+	if err != nil {
+		a.statusMsg = i18n.T("unsubscribe.failed", map[string]any{"Error": err})
+		return a, nil
+	}
+	draft := ComposeDraft{
+		To:      strings.Join(m.To, ", "),
+		Subject: m.Subject,
+		Body:    m.Body,
+	}
+	if draft.Subject == "" {
+		draft.Subject = "unsubscribe"
+	}
+	return a.openCompose(NewDraftModel(account.Credentials.Email, draft), account)
+}
+
+func oneClickUnsubscribe(target string) tea.Cmd {
+	return func() tea.Msg {
+		return unsubscribeDoneMsg{err: mail.OneClickUnsubscribe(context.Background(), target)}
+	}
+}
+
 func (a *App) markSingleAsUnread(uid imap.UID) tea.Cmd {
 	account := a.currentAccount()
 	accountEmail := ""
@@ -245,8 +611,23 @@ func (a *App) markSingleAsUnread(uid imap.UID) tea.Cmd {
 	}
 }
 
-func (a *App) sendReply() tea.Cmd {
+// markReadInBackground marks an opened email read on the server as a background job
+func (a *App) markReadInBackground(uid imap.UID) tea.Cmd {
 	account := a.currentAccount()
+	if account == nil || a.serverClient == nil {
+		return nil
+	}
+	accountEmail := account.Credentials.Email
+	mailbox := a.currentLabel
+	serverClient := a.serverClient
+
+	return a.jobs.start("job.mark_read", func() error {
+		return serverClient.MarkRead(accountEmail, mailbox, uid)
+	})
+}
+
+func (a *App) sendReply() tea.Cmd {
+	account := a.composeAccount()
 	if account == nil {
 		return func() tea.Msg {
 			return replySendErrorMsg{err: fmt.Errorf("no account configured")}
@@ -293,15 +674,38 @@ func (a *App) sendReply() tea.Cmd {
 		if err != nil {
 			return replySendErrorMsg{err: err}
 		}
+		if original != nil {
+			return replySentMsg{original: original.UID}
+		}
 		return replySentMsg{}
 	}
 }
 
+// markAnswered shows the email a reply was sent to as answered and sets \Answered on
+// the server as a background job
+func (a *App) markAnswered(uid imap.UID) tea.Cmd {
+	if uid == 0 {
+		return nil
+	}
+	a.mailList.MarkAsAnswered(uid)
+	account := a.currentAccount()
+	if account == nil || a.serverClient == nil {
+		return nil
+	}
+	accountEmail := account.Credentials.Email
+	mailbox := a.currentLabel
+	serverClient := a.serverClient
+
+	return a.jobs.start("job.mark_answered", func() error {
+		return serverClient.MarkAnswered(accountEmail, mailbox, uid)
+	})
+}
+
 func (a *App) saveDraft() tea.Cmd {
 	to := a.compose.GetTo()
 	subject := a.compose.GetSubject()
 	body := a.compose.GetBody()
-	account := a.currentAccount()
+	account := a.composeAccount()
 	serverClient := a.serverClient
 
 	return func() tea.Msg {
@@ -439,6 +843,7 @@ func (a *App) addEventToCalendar() tea.Cmd {
 	startTime := a.extractedStart
 	endTime := a.extractedEnd
 	client := a.calClient
+	settings := a.cfg.Calendar
 
 	return func() tea.Msg {
 		if event == nil || client == nil {
@@ -450,7 +855,7 @@ func (a *App) addEventToCalendar() tea.Cmd {
 			StartTime:          startTime,
 			EndTime:            endTime,
 			Location:           event.Location,
-			AlarmMinutesBefore: event.AlarmMinutesBefore,
+			AlarmMinutesBefore: alarmMinutes(settings, event.AlarmMinutesBefore, event.Location, false),
 		}
 
 		eventID, err := client.CreateEvent(calEvent)
@@ -465,8 +870,15 @@ func (a *App) addEventToCalendar() tea.Cmd {
 // ReminderOptions defines available reminder choices (in minutes, 0 = none)
 var ReminderOptions = []int{0, 5, 10, 15, 30, 60}
 
-// ReminderLabels are the display labels for reminder options
-var ReminderLabels = []string{"No reminder", "5 minutes", "10 minutes", "15 minutes", "30 minutes", "1 hour"}
+// reminderLabel returns the display label of a reminder option
+func reminderLabel(i int) string {
+	ids := []string{"calendar.reminder.none", "calendar.reminder.5min", "calendar.reminder.10min",
+		"calendar.reminder.15min", "calendar.reminder.30min", "calendar.reminder.1hour"}
+	if i < 0 || i >= len(ids) {
+		i = 0
+	}
+	return i18n.T(ids[i])
+}
 
 // minutesToReminderIndex converts minutes to reminder option index
 func minutesToReminderIndex(minutes int) int {
@@ -488,18 +900,15 @@ func (a *App) initExtractEditForm() {
 
 	// Title
 	a.extractEditTitle = textinput.New()
-	a.extractEditTitle.Placeholder = "Event title"
+	a.extractEditTitle.Placeholder = i18n.T("extract.placeholder.title")
 	a.extractEditTitle.SetValue(event.Title)
 	a.extractEditTitle.CharLimit = 100
 	a.extractEditTitle.Width = 40
 	a.extractEditTitle.Focus()
 
-	// Date (YYYY-MM-DD)
-	a.extractEditDate = textinput.New()
-	a.extractEditDate.Placeholder = "YYYY-MM-DD"
-	a.extractEditDate.SetValue(a.extractedStart.Format("2006-01-02"))
-	a.extractEditDate.CharLimit = 10
-	a.extractEditDate.Width = 12
+	// Date
+	a.extractEditDate = components.NewDatePicker()
+	a.extractEditDate.SetDate(a.extractedStart)
 
 	// Start time (HH:MM)
 	a.extractEditStart = textinput.New()
@@ -517,14 +926,14 @@ func (a *App) initExtractEditForm() {
 
 	// Location
 	a.extractEditLocation = textinput.New()
-	a.extractEditLocation.Placeholder = "Location (optional)"
+	a.extractEditLocation.Placeholder = i18n.T("extract.placeholder.location")
 	a.extractEditLocation.SetValue(event.Location)
 	a.extractEditLocation.CharLimit = 100
 	a.extractEditLocation.Width = 40
 
 	// Notes
 	a.extractEditNotes = textinput.New()
-	a.extractEditNotes.Placeholder = "Notes (optional)"
+	a.extractEditNotes.Placeholder = i18n.T("extract.placeholder.notes")
 	a.extractEditNotes.SetValue(event.Notes)
 	a.extractEditNotes.CharLimit = 500
 	a.extractEditNotes.Width = 40
@@ -562,12 +971,7 @@ func (a *App) updateExtractEditFocus() {
 
 // applyExtractEdits validates and applies edits to the extracted event
 func (a *App) applyExtractEdits() error {
-	// Parse date
-	dateStr := a.extractEditDate.Value()
-	date, err := time.ParseInLocation("2006-01-02", dateStr, time.Local)
-	if err != nil {
-		return fmt.Errorf("invalid date format (use YYYY-MM-DD)")
-	}
+	date := a.extractEditDate.Value()
 
 	// Parse start time
 	startStr := a.extractEditStart.Value()
@@ -617,36 +1021,23 @@ func (a App) loadCachedEmails() tea.Cmd {
 	}
 
 	mailbox := a.currentLabel
+	offset := a.pageOffset
 	limit := a.cfg.MaxEmails
+	sort := a.sortOrder()
 	serverClient := a.serverClient
 	diskCache := a.diskCache
 
 	return func() tea.Msg {
-		// Try server first
-		if serverClient != nil {
-			cached, err := serverClient.GetEmails(accountEmail, mailbox, limit)
-			if err == nil && len(cached) > 0 {
-				emails := make([]mail.Email, len(cached))
-				for i, c := range cached {
-					emails[i] = cachedToGmail(c)
-				}
-				return cachedEmailsLoadedMsg{emails: emails, accountEmail: accountEmail}
-			}
+		emails, total, err := loadEmailPage(serverClient, diskCache, accountEmail, mailbox, offset, limit, sort)
+		if err == nil && len(emails) == 0 && offset > 0 {
+			// The folder shrank since the page was left; start from the newest mail
+			offset = 0
+			emails, total, err = loadEmailPage(serverClient, diskCache, accountEmail, mailbox, offset, limit, sort)
 		}
-
-		// Fall back to disk cache
-		if diskCache != nil {
-			cached, err := diskCache.LoadEmailsLimit(accountEmail, mailbox, limit)
-			if err == nil && len(cached) > 0 {
-				emails := make([]mail.Email, len(cached))
-				for i, c := range cached {
-					emails[i] = cachedToGmail(c)
-				}
-				return cachedEmailsLoadedMsg{emails: emails, accountEmail: accountEmail}
-			}
+		if err != nil {
+			return cachedEmailsLoadedMsg{emails: nil, accountEmail: accountEmail}
 		}
-
-		return cachedEmailsLoadedMsg{emails: nil, accountEmail: accountEmail}
+		return cachedEmailsLoadedMsg{emails: emails, offset: offset, total: total, accountEmail: accountEmail}
 	}
 }
 
@@ -661,39 +1052,120 @@ func (a App) reloadFromCache() tea.Cmd {
 
 	accountEmail := account.Credentials.Email
 	mailbox := a.currentLabel
+	offset := a.pageOffset
 	limit := int(a.emailLimit)
+	sort := a.sortOrder()
 	serverClient := a.serverClient
 	diskCache := a.diskCache
 
-	return func() tea.Msg {
-		// Try server first
-		if serverClient != nil {
-			cached, err := serverClient.GetEmails(accountEmail, mailbox, limit)
-			if err == nil {
-				emails := make([]mail.Email, len(cached))
-				for i, c := range cached {
-					emails[i] = cachedToGmail(c)
-				}
-				return emailsLoadedMsg{emails: emails, accountEmail: accountEmail}
+	if a.smartFolder != nil {
+		folder := *a.smartFolder
+		return func() tea.Msg {
+			emails, err := loadSmartFolder(serverClient, diskCache, accountEmail, folder)
+			if err != nil {
+				return emailsLoadedMsg{emails: nil, accountEmail: accountEmail}
 			}
+			return emailsLoadedMsg{emails: emails, total: len(emails), accountEmail: accountEmail}
 		}
+	}
 
-		// Fall back to disk cache
-		if diskCache != nil {
-			cached, err := diskCache.LoadEmailsLimit(accountEmail, mailbox, limit)
-			if err == nil {
-				emails := make([]mail.Email, len(cached))
-				for i, c := range cached {
-					emails[i] = cachedToGmail(c)
-				}
-				return emailsLoadedMsg{emails: emails, accountEmail: accountEmail}
-			}
+	return func() tea.Msg {
+		// Reload the window the list is showing, which is not the newest mail after paging down
+		emails, total, err := loadEmailPage(serverClient, diskCache, accountEmail, mailbox, offset, limit, sort)
+		if err != nil {
+			return emailsLoadedMsg{emails: nil, accountEmail: accountEmail}
 		}
+		return emailsLoadedMsg{emails: emails, offset: offset, total: total, accountEmail: accountEmail}
+	}
+}
 
-		return emailsLoadedMsg{emails: nil, accountEmail: accountEmail}
+// openSelectedEmail shows the email under the cursor in the read view, fetching its body
+// when it isn't cached and marking it read
+func (a *App) openSelectedEmail() tea.Cmd {
+	email := a.mailList.SelectedEmail()
+	if email == nil {
+		return nil
+	}
+	a.view = readView
+	// Create fresh viewport for each email to avoid state issues
+	emailHeaderHeight := 6
+	if len(email.Attachments) > 0 {
+		emailHeaderHeight = 7
+	}
+	vpHeight := max(5, a.height-10-emailHeaderHeight)
+	a.viewport = viewport.New(a.width-8, vpHeight)
+	a.viewport.Style = lipgloss.NewStyle().Padding(1, 4, 3, 4)
+	a.finder.Reset(a.searchTerms())
+	a.showQuoted = false
+	a.showRaw = false
+	a.rawSource = nil
+	var cmds []tea.Cmd
+	if results, ok := a.authCache[a.authKey(email.UID)]; ok {
+		a.authResults = results
+	} else {
+		a.authResults = nil
+		cmds = append(cmds, a.fetchAuthResults(email.UID))
+	}
+
+	// Check if body needs to be fetched
+	if email.BodyHTML == "" && email.Snippet == "" {
+		a.viewport.SetContent(i18n.T("common.loading"))
+		cmds = append(cmds, a.fetchEmailBody(email.UID))
+	} else {
+		a.finder.SetContent(&a.viewport, a.renderEmailContent(*email))
+	}
+
+	if email.Unread {
+		// Update in-memory state immediately for responsive UI
+		a.mailList.MarkAsRead(email.UID)
+		cmds = append(cmds, a.markReadInBackground(email.UID))
+	}
+	return tea.Batch(cmds...)
+}
+
+// refreshFromCache reloads the shown folder after a sync or another client changed it,
+// keeping the cursor on the email it was on
+func (a App) refreshFromCache() tea.Cmd {
+	reload := a.reloadFromCache()
+	return func() tea.Msg {
+		msg := reload()
+		if loaded, ok := msg.(emailsLoadedMsg); ok {
+			loaded.keepCursor = true
+			return loaded
+		}
+		return msg
 	}
 }
 
+// loadEmailPage reads limit emails starting at offset in the sort order, and the mailbox
+// total, from the server or, when it is unavailable, the disk cache
+func loadEmailPage(serverClient *client.Client, diskCache *cache.Cache, account, mailbox string, offset, limit int, sort string) ([]mail.Email, int, error) {
+	var cached []cache.CachedEmail
+	var total int
+	err := fmt.Errorf("no server or cache available")
+
+	// Try server first
+	if serverClient != nil {
+		cached, total, err = serverClient.GetEmailPage(account, mailbox, offset, limit, sort)
+	}
+
+	// Fall back to disk cache
+	if err != nil && diskCache != nil {
+		if total, err = diskCache.CountEmails(account, mailbox); err == nil {
+			cached, err = diskCache.LoadEmailPageSorted(account, mailbox, offset, limit, sort)
+		}
+	}
+	if err != nil {
+		return nil, 0, err
+	}
+
+	emails := make([]mail.Email, len(cached))
+	for i, c := range cached {
+		emails[i] = cachedToGmail(c)
+	}
+	return emails, total, nil
+}
+
 // refreshFromIMAP performs a manual metadata-only refresh via the server.
 func (a *App) refreshFromIMAP() tea.Cmd {
 	account := a.currentAccount()
@@ -757,8 +1229,18 @@ func cachedToGmail(c cache.CachedEmail) mail.Email {
 		Snippet:      c.Snippet,
 		BodyHTML:     c.BodyHTML,
 		Unread:       c.Unread,
+		Answered:     c.Answered,
+		Forwarded:    c.Forwarded,
+		Flagged:      c.Flagged,
+		Size:         c.Size,
 		References:   c.References,
 		Attachments:  attachments,
+
+		ListID:              c.ListID,
+		ListUnsubscribe:     c.ListUnsubscribe,
+		ListUnsubscribePost: c.ListUnsubscribePost,
+
+		Labels: c.Labels,
 	}
 }
 
@@ -769,10 +1251,7 @@ func (a App) executeCommand(command string) (tea.Model, tea.Cmd) {
 		// New email
 		account := a.currentAccount()
 		if account != nil {
-			a.compose = NewComposeModel(account.Credentials.Email)
-			a.compose.setSize(a.width, a.height)
-			a.view = composeView
-			return a, a.compose.Init()
+			return a.openCompose(NewComposeModel(account.Credentials.Email), account)
 		}
 
 	case "reply":
@@ -780,10 +1259,7 @@ func (a App) executeCommand(command string) (tea.Model, tea.Cmd) {
 		if email := a.mailList.SelectedEmail(); email != nil {
 			account := a.currentAccount()
 			if account != nil {
-				a.compose = NewReplyModel(account.Credentials.Email, email)
-				a.compose.setSize(a.width, a.height)
-				a.view = composeView
-				return a, a.compose.Init()
+				return a.openCompose(NewReplyModel(account.Credentials.Email, email, a.cfg.Reply), account)
 			}
 		}
 
@@ -792,10 +1268,7 @@ func (a App) executeCommand(command string) (tea.Model, tea.Cmd) {
 		if email := a.mailList.SelectedEmail(); email != nil {
 			account := a.currentAccount()
 			if account != nil {
-				a.compose = NewReplyAllModel(account.Credentials.Email, email)
-				a.compose.setSize(a.width, a.height)
-				a.view = composeView
-				return a, a.compose.Init()
+				return a.openCompose(NewReplyAllModel(account.Credentials.Email, email, a.cfg.Reply), account)
 			}
 		}
 
@@ -815,27 +1288,101 @@ func (a App) executeCommand(command string) (tea.Model, tea.Cmd) {
 		// Refresh from IMAP server
 		if !a.isSearchResult && a.view == listView {
 			a.state = stateLoading
-			a.statusMsg = "Refreshing..."
+			a.statusMsg = i18n.T("email.refreshing")
 			return a, tea.Batch(a.spinner.Tick, a.loadEmails())
 		}
 
+	case "sent", "trash", "spam":
+		// Open the sent, trash or spam folder; the command names are the folder kinds
+		if !a.isSearchResult && a.view == listView {
+			a.state = stateLoading
+			a.statusMsg = i18n.T("common.loading")
+			return a, tea.Batch(a.spinner.Tick, a.openSpecialFolder(command))
+		}
+
+	case "sort":
+		// Sort the folder by the next order, as with O
+		if !a.isSearchResult && a.view == listView && a.smartFolder == nil && !a.newsletters {
+			cmd := a.cycleSort()
+			return a, cmd
+		}
+
+	case "storage":
+		// Show storage usage per account
+		if a.view == listView {
+			a.statusMsg = i18n.T("storage.loading")
+			return a, a.loadStorage()
+		}
+
+	case "cleanup":
+		// Group the folder's mail by sender and mailing list to archive or delete in bulk
+		if !a.isSearchResult && a.view == listView {
+			a.statusMsg = i18n.T("cleanup.loading")
+			return a, a.loadCleanup()
+		}
+
+	case "empty-trash":
+		// Empty the account's trash from any folder, after confirming
+		if !a.isSearchResult && a.view == listView {
+			a.confirmEmpty = true
+		}
+
+	case "newsletters":
+		// Show only mailing list mail from the inbox, muted lists included
+		if !a.isSearchResult && a.view == listView {
+			a.setNewsletters(true)
+			if a.currentLabel != "INBOX" {
+				a.currentLabel = "INBOX"
+				a.labelPicker.SetSelected("INBOX")
+				a.state = stateLoading
+				a.statusMsg = i18n.T("common.loading")
+				return a, tea.Batch(a.spinner.Tick, a.loadEmails())
+			}
+			a.statusMsg = ""
+		}
+
+	case "report-spam":
+		if email := a.mailList.SelectedEmail(); email != nil && !a.isSearchResult {
+			notSpam := a.inSpecialFolder(mail.FolderSpam)
+			a.state = stateLoading
+			a.statusMsg = i18n.T("spam.moving")
+			return a, tea.Batch(a.spinner.Tick, a.reportSpam(email.UID, notSpam))
+		}
+
+	case "mute-thread", "follow-thread":
+		// Mute or follow the selected email's thread, or undo it
+		if email := a.mailList.SelectedEmail(); email != nil {
+			rule := cache.ThreadMute
+			if command == "follow-thread" {
+				rule = cache.ThreadFollow
+			}
+			cmd := a.toggleThreadRule(*email, rule)
+			return a, cmd
+		}
+
+	case "goto":
+		// Go to a date in the list
+		if !a.isSearchResult && a.view == listView {
+			return a, a.jumper.Start()
+		}
+
 	case "labels":
 		// Show label picker
 		if !a.isSearchResult && a.view == listView {
-			a.labelPicker.SetSelected(a.currentLabel)
-			a.showLabelPicker = true
+			cmd := a.openLabelPicker()
+			return a, cmd
 		}
 
 	case "summarize":
 		// AI summarize
 		if a.view == readView {
 			if !a.aiClient.Available() {
-				a.statusMsg = "No AI CLI found (install claude, codex, gemini, vibe, or ollama)"
+				a.statusMsg = i18n.T("summary.no_ai")
 				return a, nil
 			}
 			if email := a.mailList.SelectedEmail(); email != nil {
 				a.state = stateLoading
-				a.statusMsg = "Summarizing with " + a.aiClient.Provider() + "..."
+				a.statusMsg = i18n.T("summary.generating_with", map[string]any{"Provider": a.aiClient.Provider()})
 				return a, tea.Batch(a.spinner.Tick, a.summarizeEmail(email))
 			}
 		}
@@ -845,17 +1392,247 @@ func (a App) executeCommand(command string) (tea.Model, tea.Cmd) {
 		if a.aiClient.Available() {
 			if email := a.mailList.SelectedEmail(); email != nil {
 				a.state = stateLoading
-				a.statusMsg = "Extracting event with " + a.aiClient.Provider() + "..."
+				a.statusMsg = i18n.T("extract.extracting_with", map[string]any{"Provider": a.aiClient.Provider()})
 				return a, tea.Batch(a.spinner.Tick, a.doExtractEvent(email))
 			}
 		} else {
-			a.statusMsg = "No AI CLI found (install claude, codex, gemini, vibe, or ollama)"
+			a.statusMsg = i18n.T("summary.no_ai")
 		}
 
 	case "add":
 		// Add calendar event (placeholder)
-		a.statusMsg = "Add event: Not implemented yet"
+		a.statusMsg = i18n.T("extract.add_unavailable")
+
+	default:
+		// An action added by a plugin
+		if pa, ok := a.pluginActions[command]; ok {
+			cmd := a.runPlugin(pa)
+			return a, cmd
+		}
 	}
 
 	return a, nil
 }
+
+// loadSyncStatus fetches the current sync state of all accounts from the server
+func (a App) loadSyncStatus() tea.Cmd {
+	serverClient := a.serverClient
+
+	return func() tea.Msg {
+		accounts, err := serverClient.GetAccounts()
+		if err != nil {
+			return nil
+		}
+		return syncStatusLoadedMsg{accounts: accounts}
+	}
+}
+
+// loadFailedOps fetches the current account's queued operations that ran out of retries
+func (a App) loadFailedOps() tea.Cmd {
+	serverClient := a.serverClient
+	accountEmail := ""
+	if account := a.currentAccount(); account != nil {
+		accountEmail = account.Credentials.Email
+	}
+
+	return func() tea.Msg {
+		if serverClient == nil {
+			return failedOpsLoadedMsg{err: fmt.Errorf("server unavailable")}
+		}
+		ops, err := serverClient.GetFailedOps(accountEmail)
+		return failedOpsLoadedMsg{ops: ops, err: err}
+	}
+}
+
+// resolveFailedOp queues a failed operation again, or discards it
+func (a App) resolveFailedOp(id int64, retry bool) tea.Cmd {
+	serverClient := a.serverClient
+
+	return func() tea.Msg {
+		if serverClient == nil {
+			return opResolvedMsg{id: id, retried: retry, err: fmt.Errorf("server unavailable")}
+		}
+		var err error
+		if retry {
+			err = serverClient.RetryOp(id)
+		} else {
+			err = serverClient.DiscardOp(id)
+		}
+		return opResolvedMsg{id: id, retried: retry, err: err}
+	}
+}
+
+// setFailedOpsCount updates the failed operations notice of the current account
+func (a *App) setFailedOpsCount(count int) {
+	account := a.currentAccount()
+	if account == nil {
+		return
+	}
+	status := a.syncStatus[account.Credentials.Email]
+	status.failedOps = count
+	a.syncStatus[account.Credentials.Email] = status
+}
+
+// loadStats fetches sync statistics for all accounts from the server
+func (a App) loadStats() tea.Cmd {
+	serverClient := a.serverClient
+
+	return func() tea.Msg {
+		if serverClient == nil {
+			return statsLoadedMsg{err: fmt.Errorf("server unavailable")}
+		}
+		stats, err := serverClient.GetStats("")
+		return statsLoadedMsg{stats: stats, err: err}
+	}
+}
+
+// loadStorage asks the server for every account's storage usage
+func (a App) loadStorage() tea.Cmd {
+	serverClient := a.serverClient
+
+	return func() tea.Msg {
+		if serverClient == nil {
+			return storageLoadedMsg{err: fmt.Errorf("server unavailable")}
+		}
+		storage, err := serverClient.GetStorage("")
+		return storageLoadedMsg{storage: storage, err: err}
+	}
+}
+
+// printEmail writes the email to a file using the print settings from config.yml
+func (a App) printEmail(email mail.Email) tea.Cmd {
+	cfg := a.cfg.Print
+
+	return func() tea.Msg {
+		e := export.Email{
+			From:      email.From,
+			To:        email.To,
+			Cc:        email.Cc,
+			Subject:   email.Subject,
+			Date:      email.Date,
+			MessageID: email.MessageID,
+			BodyHTML:  email.BodyHTML,
+		}
+		for _, att := range email.Attachments {
+			e.Attachments = append(e.Attachments, export.Attachment{Filename: att.Filename, Size: att.Size})
+		}
+		path, err := export.Print(e, cfg, "")
+		return printedMsg{path: path, err: err}
+	}
+}
+
+// fetchRawSource asks the server for the RFC 822 source of an email in the current folder
+func (a App) fetchRawSource(uid imap.UID) tea.Cmd {
+	account := a.currentAccount()
+	serverClient := a.serverClient
+	mailbox := a.currentLabel
+
+	return func() tea.Msg {
+		if serverClient == nil {
+			return rawSourceLoadedMsg{uid: uid, err: fmt.Errorf("server unavailable")}
+		}
+		if account == nil {
+			return rawSourceLoadedMsg{uid: uid, err: fmt.Errorf("no account selected")}
+		}
+		raw, err := serverClient.FetchRaw(account.Credentials.Email, mailbox, uid)
+		return rawSourceLoadedMsg{uid: uid, raw: raw, err: err}
+	}
+}
+
+// loadUnreadCounts counts each account's unread inbox emails in the disk cache
+func (a App) loadUnreadCounts() tea.Cmd {
+	diskCache := a.diskCache
+	accounts := a.store.Accounts
+	if diskCache == nil {
+		return nil
+	}
+
+	return func() tea.Msg {
+		counts := make(map[string]int, len(accounts))
+		for _, account := range accounts {
+			if n, err := diskCache.CountUnread(account.Credentials.Email, "INBOX"); err == nil {
+				counts[account.Credentials.Email] = n
+			}
+		}
+		return unreadCountsLoadedMsg{counts: counts}
+	}
+}
+
+// authKey names an email of the current account and folder in the auth results cache
+func (a App) authKey(uid imap.UID) authKey {
+	key := authKey{mailbox: a.currentLabel, uid: uid}
+	if account := a.currentAccount(); account != nil {
+		key.account = account.Credentials.Email
+	}
+	return key
+}
+
+// fetchAuthResults fetches the headers of an email in the current folder and parses
+// its SPF, DKIM and DMARC results
+func (a App) fetchAuthResults(uid imap.UID) tea.Cmd {
+	account := a.currentAccount()
+	serverClient := a.serverClient
+	key := a.authKey(uid)
+
+	return func() tea.Msg {
+		if serverClient == nil || account == nil {
+			return authResultsLoadedMsg{key: key, err: fmt.Errorf("server unavailable")}
+		}
+		header, err := serverClient.FetchHeader(key.account, key.mailbox, uid)
+		if err != nil {
+			return authResultsLoadedMsg{key: key, err: err}
+		}
+		results := mail.ParseAuthResults(header)
+		return authResultsLoadedMsg{key: key, results: &results}
+	}
+}
+
+// saveRawSource writes an email's source to an .eml file in the print directory
+func (a App) saveRawSource(email mail.Email, raw []byte) tea.Cmd {
+	cfg := a.cfg.Print
+
+	return func() tea.Msg {
+		e := export.Email{Subject: email.Subject, Date: email.Date}
+		path, err := export.SaveRaw(e, raw, cfg)
+		return rawSavedMsg{path: path, err: err}
+	}
+}
+
+// switchProfile activates a profile and persists it. The server serves every account,
+// so only this app's accounts change.
+func (a App) switchProfile(name string) tea.Cmd {
+	cfg := *a.cfg
+
+	return func() tea.Msg {
+		if err := cfg.SetActiveProfiles([]string{name}); err != nil {
+			return profileSwitchedMsg{err: err}
+		}
+		store, err := auth.LoadAccountStore()
+		if err != nil {
+			return profileSwitchedMsg{err: err}
+		}
+		store = store.Filter(cfg.AccountActive)
+		if len(store.Accounts) == 0 {
+			return profileSwitchedMsg{cfg: cfg, store: store}
+		}
+		// Only the profile is saved, keeping what other instances saved meanwhile
+		if _, err := config.Update(func(c *config.Config) {
+			c.ActiveProfiles = cfg.ActiveProfiles
+		}); err != nil {
+			return profileSwitchedMsg{err: err}
+		}
+		return profileSwitchedMsg{cfg: cfg, store: store}
+	}
+}
+
+// waitForServerEvent blocks until the server pushes the next event
+func waitForServerEvent(events <-chan server.Event) tea.Cmd {
+	return func() tea.Msg {
+		event, ok := <-events
+		if !ok {
+			// Connection closed, stop listening
+			return nil
+		}
+		return serverEventMsg{event: event}
+	}
+}
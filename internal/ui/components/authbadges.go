@@ -0,0 +1,117 @@
+package components
+
+import (
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+
+	"maily/internal/i18n"
+	"maily/internal/mail"
+)
+
+// RenderAuthBadges renders SPF, DKIM and DMARC result badges for the read view header,
+// followed by a warning when the sender looks forged
+func RenderAuthBadges(r *mail.AuthResults) string {
+	if r == nil || !r.Checked() {
+		return ""
+	}
+	var badges []string
+	for _, check := range [][2]string{{"SPF", r.SPF}, {"DKIM", r.DKIM}, {"DMARC", r.DMARC}} {
+		if check[1] != "" {
+			badges = append(badges, authBadge(check[0], check[1]))
+		}
+	}
+	line := strings.Join(badges, " ")
+	if warning := r.Warning(); warning != "" {
+		line += " " + lipgloss.NewStyle().
+			Foreground(lipgloss.Color("#FFFFFF")).
+			Background(Danger).
+			Bold(true).
+			Padding(0, 1).
+			Render("⚠ "+warning)
+	}
+	return line + " " + HelpKeyStyle.Render("H") + HelpDescStyle.Render(" "+i18n.T("help.auth_details"))
+}
+
+// authBadge renders one result: green for pass, red for fail, grey otherwise
+func authBadge(method, result string) string {
+	color, mark := Muted, "?"
+	switch result {
+	case "pass":
+		color, mark = Success, "✓"
+	case "fail", "permerror":
+		color, mark = Danger, "✗"
+	case "softfail":
+		color, mark = Warning, "~"
+	}
+	return lipgloss.NewStyle().Foreground(color).Bold(true).Render(method + " " + mark)
+}
+
+// RenderAuthDialog lists every check from the Authentication-Results header with its
+// properties, plus Received-SPF
+func RenderAuthDialog(width, height int, r *mail.AuthResults) string {
+	dialogWidth := min(width-20, 90)
+
+	titleStyle := lipgloss.NewStyle().
+		Bold(true).
+		Foreground(Primary).
+		MarginBottom(1)
+
+	labelStyle := lipgloss.NewStyle().
+		Foreground(Muted).
+		Width(14)
+
+	valueStyle := lipgloss.NewStyle().
+		Foreground(TextDim)
+
+	hintStyle := lipgloss.NewStyle().
+		Foreground(Muted).
+		MarginTop(1)
+
+	lines := []string{titleStyle.Render(i18n.T("auth.title"))}
+	switch {
+	case r == nil:
+		lines = append(lines, valueStyle.Render(i18n.T("common.loading")))
+	case !r.Checked():
+		lines = append(lines, valueStyle.Render(i18n.T("auth.none")))
+	default:
+		if r.ServerID != "" {
+			lines = append(lines, labelStyle.Render(i18n.T("auth.checked_by"))+valueStyle.Render(r.ServerID))
+		}
+		if r.FromDomain != "" {
+			lines = append(lines, labelStyle.Render(i18n.T("auth.from_domain"))+valueStyle.Render(r.FromDomain))
+		}
+		if warning := r.Warning(); warning != "" {
+			lines = append(lines, "", lipgloss.NewStyle().Foreground(Danger).Bold(true).Render("⚠ "+warning))
+		}
+		lines = append(lines, "")
+		for _, c := range r.Checks {
+			var props []string
+			for _, p := range c.Properties {
+				props = append(props, p[0]+"="+p[1])
+			}
+			detail := truncate(strings.Join(props, " "), max(10, dialogWidth-30))
+			lines = append(lines, labelStyle.Render(strings.ToUpper(c.Method))+authBadge("", c.Result)+" "+
+				valueStyle.Render(c.Result+"  "+detail))
+		}
+		if r.ReceivedSPF != "" {
+			lines = append(lines, "", labelStyle.Render("Received-SPF")+
+				valueStyle.Render(truncate(r.ReceivedSPF, max(10, dialogWidth-24))))
+		}
+	}
+	lines = append(lines, hintStyle.Render("esc "+i18n.T("help.close")))
+
+	dialogStyle := lipgloss.NewStyle().
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(Primary).
+		Padding(1, 3).
+		Width(dialogWidth)
+
+	return lipgloss.Place(
+		width,
+		height-4,
+		lipgloss.Center,
+		lipgloss.Center,
+		dialogStyle.Render(lipgloss.JoinVertical(lipgloss.Left, lines...)),
+	)
+}
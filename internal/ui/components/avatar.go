@@ -0,0 +1,90 @@
+package components
+
+import (
+	"hash/fnv"
+	netmail "net/mail"
+	"strings"
+	"unicode"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/mattn/go-runewidth"
+)
+
+// avatarPalette holds the backgrounds of sender initials; light text reads on each
+var avatarPalette = []lipgloss.Color{
+	lipgloss.Color("#7C3AED"),
+	lipgloss.Color("#2563EB"),
+	lipgloss.Color("#0891B2"),
+	lipgloss.Color("#059669"),
+	lipgloss.Color("#65A30D"),
+	lipgloss.Color("#CA8A04"),
+	lipgloss.Color("#EA580C"),
+	lipgloss.Color("#DC2626"),
+	lipgloss.Color("#DB2777"),
+	lipgloss.Color("#9333EA"),
+	lipgloss.Color("#4F46E5"),
+	lipgloss.Color("#0D9488"),
+}
+
+// avatarWidth is the width of a rendered avatar, with the space after it
+const avatarWidth = 5
+
+// RenderAvatar renders a sender's initials as a coloured block. The colour comes from a
+// hash of the address, so a sender's emails are drawn alike wherever they appear.
+func RenderAvatar(from string) string {
+	name, address := parseSender(from)
+	h := fnv.New32a()
+	h.Write([]byte(strings.ToLower(address)))
+	return lipgloss.NewStyle().
+		Bold(true).
+		Foreground(Text).
+		Background(avatarPalette[h.Sum32()%uint32(len(avatarPalette))]).
+		Render(" " + Initials(name, address) + " ")
+}
+
+// Initials returns two letters for a sender: the first letters of the first and last
+// words of the name, or the first two letters of the address when there is no name,
+// padded to two columns
+func Initials(name, address string) string {
+	var letters []rune
+	words := strings.FieldsFunc(name, func(r rune) bool { return !unicode.IsLetter(r) && !unicode.IsDigit(r) })
+	switch {
+	case len(words) >= 2:
+		letters = []rune{[]rune(words[0])[0], []rune(words[len(words)-1])[0]}
+	case len(words) == 1:
+		letters = []rune(words[0])
+	default:
+		local, _, _ := strings.Cut(address, "@")
+		for _, r := range local {
+			if unicode.IsLetter(r) || unicode.IsDigit(r) {
+				letters = append(letters, r)
+			}
+		}
+	}
+	// Two columns: a wide letter, as in CJK names, fills them alone
+	initials := ""
+	for _, r := range strings.ToUpper(string(letters)) {
+		if runewidth.StringWidth(initials+string(r)) > 2 {
+			break
+		}
+		initials += string(r)
+	}
+	if initials == "" {
+		return "??"
+	}
+	return initials + strings.Repeat(" ", 2-runewidth.StringWidth(initials))
+}
+
+// parseSender splits a From header into the display name and the address
+func parseSender(from string) (name, address string) {
+	if addr, err := netmail.ParseAddress(from); err == nil {
+		return addr.Name, addr.Address
+	}
+	name = extractName(from)
+	if i := strings.Index(from, "<"); i >= 0 {
+		address = strings.Trim(from[i:], "<> ")
+	} else {
+		address, name = strings.TrimSpace(from), ""
+	}
+	return name, address
+}
@@ -0,0 +1,216 @@
+package components
+
+import (
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+	"maily/internal/cache"
+	"maily/internal/i18n"
+	"maily/internal/retention"
+)
+
+// CleanupWizard lists a folder's cached mail grouped by mailing list and sender, largest
+// first, so whole groups can be archived or deleted at once
+type CleanupWizard struct {
+	mailbox string
+	groups  []retention.Group
+	marked  map[string]bool // group keys marked with space
+	cursor  int
+	pending string // operation waiting for confirmation: cache.OpArchive or cache.OpMoveTrash
+	width   int
+	height  int
+}
+
+func NewCleanupWizard() CleanupWizard {
+	return CleanupWizard{width: 80, height: 24}
+}
+
+// SetGroups shows the groups of a folder's mail, with nothing marked
+func (w *CleanupWizard) SetGroups(mailbox string, groups []retention.Group) {
+	w.mailbox = mailbox
+	w.groups = groups
+	w.marked = make(map[string]bool)
+	w.cursor = 0
+	w.pending = ""
+}
+
+func (w *CleanupWizard) SetSize(width, height int) {
+	w.width = width
+	w.height = height
+}
+
+func (w *CleanupWizard) Up() {
+	if w.cursor > 0 {
+		w.cursor--
+	}
+}
+
+func (w *CleanupWizard) Down() {
+	if w.cursor < len(w.groups)-1 {
+		w.cursor++
+	}
+}
+
+// Toggle marks or unmarks the group under the cursor and moves to the next one
+func (w *CleanupWizard) Toggle() {
+	if w.cursor >= len(w.groups) {
+		return
+	}
+	key := w.groups[w.cursor].Key
+	w.marked[key] = !w.marked[key]
+	w.Down()
+}
+
+// Selected returns the marked groups, or the one under the cursor when none are marked
+func (w CleanupWizard) Selected() []retention.Group {
+	var selected []retention.Group
+	for _, g := range w.groups {
+		if w.marked[g.Key] {
+			selected = append(selected, g)
+		}
+	}
+	if len(selected) == 0 && w.cursor < len(w.groups) {
+		selected = append(selected, w.groups[w.cursor])
+	}
+	return selected
+}
+
+// Confirm asks to confirm an operation on the selected groups
+func (w *CleanupWizard) Confirm(operation string) {
+	if len(w.groups) > 0 {
+		w.pending = operation
+	}
+}
+
+// Pending returns the operation waiting for confirmation, if any
+func (w CleanupWizard) Pending() string {
+	return w.pending
+}
+
+// Cancel drops the operation waiting for confirmation
+func (w *CleanupWizard) Cancel() {
+	w.pending = ""
+}
+
+// Remove drops groups once their mail was queued for archiving or deletion
+func (w *CleanupWizard) Remove(keys []string) {
+	gone := make(map[string]bool, len(keys))
+	for _, k := range keys {
+		gone[k] = true
+		delete(w.marked, k)
+	}
+	kept := w.groups[:0]
+	for _, g := range w.groups {
+		if !gone[g.Key] {
+			kept = append(kept, g)
+		}
+	}
+	w.groups = kept
+	w.pending = ""
+	w.cursor = min(w.cursor, max(0, len(w.groups)-1))
+}
+
+// Mailbox returns the folder the groups are from
+func (w CleanupWizard) Mailbox() string {
+	return w.mailbox
+}
+
+func (w CleanupWizard) View() string {
+	var b strings.Builder
+
+	listWidth := max(40, min(90, w.width-16))
+	countWidth := 14
+	sizeWidth := 10
+	nameWidth := listWidth - countWidth - sizeWidth - 4
+
+	listHeight := max(3, w.height-14)
+	start := 0
+	if w.cursor >= listHeight {
+		start = w.cursor - listHeight + 1
+	}
+	end := min(start+listHeight, len(w.groups))
+
+	if len(w.groups) == 0 {
+		b.WriteString(lipgloss.NewStyle().Foreground(Muted).Render(i18n.T("cleanup.empty")))
+	}
+	for i := start; i < end; i++ {
+		g := w.groups[i]
+		mark := "  "
+		if w.marked[g.Key] {
+			mark = "✓ "
+		}
+		name := g.Name
+		if g.List {
+			name = "≡ " + name
+		}
+		count := i18n.TPlural("cleanup.count", g.Count, map[string]any{"Count": g.Count})
+		line := mark + lipgloss.NewStyle().Width(nameWidth).Render(truncate(name, nameWidth-1)) +
+			lipgloss.NewStyle().Width(countWidth).Align(lipgloss.Right).Render(count) +
+			lipgloss.NewStyle().Width(sizeWidth).Align(lipgloss.Right).Render(formatFileSize(g.Size))
+
+		style := lipgloss.NewStyle().Padding(0, 1).Foreground(Text)
+		if i == w.cursor {
+			style = style.Bold(true).Background(Primary)
+		} else if w.marked[g.Key] {
+			style = style.Foreground(Primary)
+		}
+		b.WriteString(style.Render(line))
+		if i < end-1 {
+			b.WriteString("\n")
+		}
+	}
+
+	titleStyle := lipgloss.NewStyle().
+		Bold(true).
+		Foreground(Primary).
+		MarginBottom(1)
+
+	hintStyle := lipgloss.NewStyle().
+		Foreground(Muted).
+		MarginTop(1)
+
+	hint := "↑/↓ " + i18n.T("help.navigate") + " • space " + i18n.T("help.select") +
+		" • a " + i18n.T("help.archive") + " • d " + i18n.T("help.delete") + " • esc " + i18n.T("help.close")
+	if w.pending != "" {
+		hint = w.confirmText()
+		hintStyle = hintStyle.Foreground(Warning).Bold(true)
+	}
+
+	content := lipgloss.JoinVertical(
+		lipgloss.Left,
+		titleStyle.Render(i18n.T("cleanup.title", map[string]any{"Folder": GetLabelDisplayName(w.mailbox)})),
+		b.String(),
+		"",
+		hintStyle.Render(hint),
+	)
+
+	return lipgloss.Place(
+		w.width,
+		w.height-4,
+		lipgloss.Center,
+		lipgloss.Center,
+		lipgloss.NewStyle().
+			Border(lipgloss.RoundedBorder()).
+			BorderForeground(Primary).
+			Padding(1, 3).
+			Render(content),
+	)
+}
+
+// confirmText asks whether to go ahead with the pending operation on the selected groups
+func (w CleanupWizard) confirmText() string {
+	selected := w.Selected()
+	count := 0
+	for _, g := range selected {
+		count += g.Count
+	}
+	what := selected[0].Name
+	if len(selected) > 1 {
+		what = i18n.T("cleanup.groups", map[string]any{"Count": len(selected)})
+	}
+	key := "cleanup.confirm_delete"
+	if w.pending == cache.OpArchive {
+		key = "cleanup.confirm_archive"
+	}
+	return i18n.TPlural(key, count, map[string]any{"Count": count, "What": what}) + " (y/n)"
+}
@@ -13,12 +13,16 @@ import (
 type Command struct {
 	Name        string
 	DescKey     string   // i18n key for description
+	Desc        string   // literal description, for commands added by plugins
 	Shortcut    string   // keyboard shortcut hint
 	Views       []string // views where this command is available: "list", "read", "today"
 }
 
 // Description returns the translated description
 func (c Command) Description() string {
+	if c.Desc != "" {
+		return c.Desc
+	}
 	return i18n.T(c.DescKey)
 }
 
@@ -35,7 +39,19 @@ var AllCommands = []Command{
 	{Name: "delete", DescKey: "command.delete", Shortcut: "d", Views: []string{"list", "today"}},
 	{Name: "search", DescKey: "command.search", Shortcut: "s", Views: []string{"list"}},
 	{Name: "refresh", DescKey: "command.refresh", Shortcut: "R", Views: []string{"list"}},
+	{Name: "goto", DescKey: "command.goto", Shortcut: "G", Views: []string{"list"}},
 	{Name: "labels", DescKey: "command.labels", Shortcut: "f", Views: []string{"list"}},
+	{Name: "sent", DescKey: "command.sent", Views: []string{"list"}},
+	{Name: "trash", DescKey: "command.trash", Views: []string{"list"}},
+	{Name: "spam", DescKey: "command.spam", Views: []string{"list"}},
+	{Name: "empty-trash", DescKey: "command.empty_trash", Shortcut: "X", Views: []string{"list"}},
+	{Name: "sort", DescKey: "command.sort", Shortcut: "O", Views: []string{"list"}},
+	{Name: "storage", DescKey: "command.storage", Views: []string{"list"}},
+	{Name: "cleanup", DescKey: "command.cleanup", Views: []string{"list"}},
+	{Name: "newsletters", DescKey: "command.newsletters", Views: []string{"list"}},
+	{Name: "report-spam", DescKey: "command.report_spam", Shortcut: "J", Views: []string{"list"}},
+	{Name: "mute-thread", DescKey: "command.mute_thread", Views: []string{"list"}},
+	{Name: "follow-thread", DescKey: "command.follow_thread", Views: []string{"list"}},
 	{Name: "summarize", DescKey: "command.summarize", Shortcut: "s", Views: []string{"today"}},
 	{Name: "event", DescKey: "command.event", Shortcut: "e", Views: []string{"today"}},
 	{Name: "add", DescKey: "command.add", Shortcut: "a", Views: []string{"today"}},
@@ -46,6 +62,7 @@ type CommandPalette struct {
 	input      textinput.Model
 	commands   []Command // filtered commands for current view
 	allForView []Command // all commands for current view
+	extra      []Command // commands added by plugins
 	cursor     int
 	width      int
 	height     int
@@ -70,12 +87,17 @@ func NewCommandPalette() CommandPalette {
 // SetView sets the current view and filters commands accordingly
 func (c *CommandPalette) SetView(view string) {
 	c.currentView = view
-	c.allForView = filterCommandsByView(AllCommands, view)
+	c.allForView = filterCommandsByView(append(AllCommands[:len(AllCommands):len(AllCommands)], c.extra...), view)
 	c.commands = c.allForView
 	c.cursor = 0
 	c.input.SetValue("")
 }
 
+// SetExtraCommands sets the commands plugins add after the built-in ones
+func (c *CommandPalette) SetExtraCommands(commands []Command) {
+	c.extra = commands
+}
+
 // SetSize sets the palette dimensions
 func (c *CommandPalette) SetSize(width, height int) {
 	c.width = width
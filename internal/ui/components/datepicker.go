@@ -2,41 +2,27 @@ package components
 
 import (
 	"fmt"
+	"strings"
 	"time"
 
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
+	"github.com/mattn/go-runewidth"
 	"maily/internal/i18n"
 )
 
-// DatePickerField represents which field is currently focused
-type DatePickerField int
-
-const (
-	FieldYear DatePickerField = iota
-	FieldMonth
-	FieldDay
-)
-
-// DatePicker is a scrollable date picker component
+// DatePicker is a mini-calendar date picker: a one-line date when blurred, and the
+// month's grid while focused, where the arrows move by day and week
 type DatePicker struct {
-	year       int
-	month      int // 1-12
-	day        int // 1-31
-	focused    bool
-	focusField DatePickerField
+	date    time.Time // local midnight of the chosen day
+	focused bool
 }
 
 // NewDatePicker creates a new date picker with today's date
 func NewDatePicker() DatePicker {
-	now := time.Now()
-	return DatePicker{
-		year:       now.Year(),
-		month:      int(now.Month()),
-		day:        now.Day(),
-		focused:    false,
-		focusField: FieldMonth, // Start on month as it's most commonly changed
-	}
+	var d DatePicker
+	d.SetDate(time.Now())
+	return d
 }
 
 // Focus sets the picker as focused
@@ -56,9 +42,7 @@ func (d DatePicker) Focused() bool {
 
 // SetDate sets the date from a time.Time
 func (d *DatePicker) SetDate(t time.Time) {
-	d.year = t.Year()
-	d.month = int(t.Month())
-	d.day = t.Day()
+	d.date = time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.Local)
 }
 
 // SetDateString sets the date from a string in "2006-01-02" format
@@ -73,170 +57,119 @@ func (d *DatePicker) SetDateString(dateStr string) error {
 
 // Value returns the date as a time.Time
 func (d DatePicker) Value() time.Time {
-	return time.Date(d.year, time.Month(d.month), d.day, 0, 0, 0, 0, time.Local)
+	return d.date
 }
 
 // ValueString returns the date in "2006-01-02" format
 func (d DatePicker) ValueString() string {
-	return fmt.Sprintf("%04d-%02d-%02d", d.year, d.month, d.day)
-}
-
-// daysInMonth returns the number of days in the current month
-func (d DatePicker) daysInMonth() int {
-	// Create a date for the first day of the next month, then subtract one day
-	t := time.Date(d.year, time.Month(d.month)+1, 0, 0, 0, 0, 0, time.Local)
-	return t.Day()
+	return d.date.Format("2006-01-02")
 }
 
-// clampDay ensures the day is valid for the current month
-func (d *DatePicker) clampDay() {
-	maxDay := d.daysInMonth()
-	if d.day > maxDay {
-		d.day = maxDay
-	}
-	if d.day < 1 {
-		d.day = 1
-	}
-}
-
-// Update handles key messages for the date picker
+// Update handles key messages for the date picker: ←→ move a day, ↑↓ a week,
+// pgup/pgdown a month, and t goes to today
 func (d DatePicker) Update(msg tea.Msg) (DatePicker, tea.Cmd) {
 	if !d.focused {
 		return d, nil
 	}
 
-	switch msg := msg.(type) {
-	case tea.KeyMsg:
+	if msg, ok := msg.(tea.KeyMsg); ok {
 		switch msg.String() {
-		case "up", "k":
-			d.increment()
-		case "down", "j":
-			d.decrement()
 		case "left", "h":
-			d.prevField()
+			d.date = d.date.AddDate(0, 0, -1)
 		case "right", "l":
-			d.nextField()
+			d.date = d.date.AddDate(0, 0, 1)
+		case "up", "k":
+			d.date = d.date.AddDate(0, 0, -7)
+		case "down", "j":
+			d.date = d.date.AddDate(0, 0, 7)
+		case "pgup", "[":
+			d.date = addMonths(d.date, -1)
+		case "pgdown", "]":
+			d.date = addMonths(d.date, 1)
+		case "t":
+			d.SetDate(time.Now())
 		}
 	}
 
 	return d, nil
 }
 
-func (d *DatePicker) increment() {
-	switch d.focusField {
-	case FieldYear:
-		d.year++
-		d.clampDay()
-	case FieldMonth:
-		d.month++
-		if d.month > 12 {
-			d.month = 1
-			d.year++
-		}
-		d.clampDay()
-	case FieldDay:
-		d.day++
-		if d.day > d.daysInMonth() {
-			d.day = 1
-		}
-	}
+// addMonths moves t by n months, keeping its day unless the month is shorter
+func addMonths(t time.Time, n int) time.Time {
+	first := time.Date(t.Year(), t.Month()+time.Month(n), 1, 0, 0, 0, 0, time.Local)
+	last := first.AddDate(0, 1, -1).Day()
+	return time.Date(first.Year(), first.Month(), min(t.Day(), last), 0, 0, 0, 0, time.Local)
 }
 
-func (d *DatePicker) decrement() {
-	switch d.focusField {
-	case FieldYear:
-		d.year--
-		if d.year < 1900 {
-			d.year = 1900
-		}
-		d.clampDay()
-	case FieldMonth:
-		d.month--
-		if d.month < 1 {
-			d.month = 12
-			d.year--
-		}
-		d.clampDay()
-	case FieldDay:
-		d.day--
-		if d.day < 1 {
-			d.day = d.daysInMonth()
-		}
-	}
-}
-
-func (d *DatePicker) nextField() {
-	d.focusField = (d.focusField + 1) % 3
-}
-
-func (d *DatePicker) prevField() {
-	if d.focusField == 0 {
-		d.focusField = FieldDay
-	} else {
-		d.focusField--
+// View renders the date on one line, or the month around it while focused
+func (d DatePicker) View() string {
+	if !d.focused {
+		return lipgloss.NewStyle().Foreground(Muted).Render(d.ViewCompact())
 	}
-}
 
-// View renders the date picker
-func (d DatePicker) View() string {
-	// Styles
 	normalStyle := lipgloss.NewStyle().Foreground(Text)
-	focusedStyle := lipgloss.NewStyle().Foreground(Text).Background(Primary).Bold(true)
 	dimStyle := lipgloss.NewStyle().Foreground(Muted)
-
-	// Format parts
-	monthNames := []string{
-		"",
-		i18n.T("calendar.month.jan"),
-		i18n.T("calendar.month.feb"),
-		i18n.T("calendar.month.mar"),
-		i18n.T("calendar.month.apr"),
-		i18n.T("calendar.month.may"),
-		i18n.T("calendar.month.jun"),
-		i18n.T("calendar.month.jul"),
-		i18n.T("calendar.month.aug"),
-		i18n.T("calendar.month.sep"),
-		i18n.T("calendar.month.oct"),
-		i18n.T("calendar.month.nov"),
-		i18n.T("calendar.month.dec"),
+	selectedStyle := lipgloss.NewStyle().Foreground(Text).Background(Primary).Bold(true)
+	todayStyle := lipgloss.NewStyle().Foreground(Secondary).Bold(true)
+
+	const width = 20 // seven two-column days with a space between
+	var b strings.Builder
+
+	header := fmt.Sprintf("◀ %s %d ▶", monthName(d.date.Month()), d.date.Year())
+	b.WriteString(normalStyle.Bold(true).Width(width).Align(lipgloss.Center).Render(header))
+	b.WriteString("\n")
+
+	weekdays := []string{
+		i18n.T("calendar.weekday.sun"),
+		i18n.T("calendar.weekday.mon"),
+		i18n.T("calendar.weekday.tue"),
+		i18n.T("calendar.weekday.wed"),
+		i18n.T("calendar.weekday.thu"),
+		i18n.T("calendar.weekday.fri"),
+		i18n.T("calendar.weekday.sat"),
 	}
-	monthStr := monthNames[d.month]
-	dayStr := fmt.Sprintf("%2d", d.day)
-	yearStr := fmt.Sprintf("%d", d.year)
-
-	var monthView, dayView, yearView string
-
-	if d.focused {
-		if d.focusField == FieldMonth {
-			monthView = focusedStyle.Render(monthStr)
-		} else {
-			monthView = normalStyle.Render(monthStr)
-		}
-
-		if d.focusField == FieldDay {
-			dayView = focusedStyle.Render(dayStr)
-		} else {
-			dayView = normalStyle.Render(dayStr)
-		}
+	weekStart := i18n.FirstWeekday()
+	names := make([]string, 7)
+	for i := range names {
+		name := weekdays[(int(weekStart)+i)%7]
+		names[i] = runewidth.FillRight(runewidth.Truncate(name, 2, ""), 2)
+	}
+	b.WriteString(dimStyle.Render(strings.Join(names, " ")))
 
-		if d.focusField == FieldYear {
-			yearView = focusedStyle.Render(yearStr)
-		} else {
-			yearView = normalStyle.Render(yearStr)
+	now := time.Now()
+	first := time.Date(d.date.Year(), d.date.Month(), 1, 0, 0, 0, 0, time.Local)
+	start := first.AddDate(0, 0, -(int(first.Weekday()-weekStart)+7)%7)
+	for day := start; day.Month() == d.date.Month() || day.Before(first); day = day.AddDate(0, 0, 7) {
+		b.WriteString("\n")
+		cells := make([]string, 7)
+		for i := range cells {
+			date := day.AddDate(0, 0, i)
+			label := fmt.Sprintf("%2d", date.Day())
+			switch {
+			case date.Month() != d.date.Month():
+				cells[i] = "  "
+			case date.Equal(d.date):
+				cells[i] = selectedStyle.Render(label)
+			case date.Year() == now.Year() && date.YearDay() == now.YearDay():
+				cells[i] = todayStyle.Render(label)
+			default:
+				cells[i] = normalStyle.Render(label)
+			}
 		}
-	} else {
-		monthView = dimStyle.Render(monthStr)
-		dayView = dimStyle.Render(dayStr)
-		yearView = dimStyle.Render(yearStr)
+		b.WriteString(strings.Join(cells, " "))
 	}
 
-	// Format as "Jan 15, 2025"
-	return monthView + " " + dayView + normalStyle.Render(", ") + yearView
+	return b.String()
 }
 
-// ViewCompact renders a compact view
+// ViewCompact renders the date on one line, e.g. "Jan 15, 2025"
 func (d DatePicker) ViewCompact() string {
-	monthNames := []string{
+	return fmt.Sprintf("%s %d, %d", monthName(d.date.Month()), d.date.Day(), d.date.Year())
+}
+
+// monthName returns the short name of month in the UI language
+func monthName(month time.Month) string {
+	names := []string{
 		"",
 		i18n.T("calendar.month.jan"),
 		i18n.T("calendar.month.feb"),
@@ -251,5 +184,5 @@ func (d DatePicker) ViewCompact() string {
 		i18n.T("calendar.month.nov"),
 		i18n.T("calendar.month.dec"),
 	}
-	return fmt.Sprintf("%s %d, %d", monthNames[d.month], d.day, d.year)
+	return names[month]
 }
@@ -0,0 +1,115 @@
+package components
+
+// popularEmoji are offered before any search and matched by their common short names
+// too, which Unicode names ("thumbs up sign") don't always use. Several are more than
+// one code point: a heart with its emoji variation selector, ZWJ sequences and flags.
+var popularEmoji = []Symbol{
+	{"👍", "thumbs up +1 yes"},
+	{"👎", "thumbs down -1 no"},
+	{"🙂", "slightly smiling face smile"},
+	{"😀", "grinning face"},
+	{"😄", "smiling face with open mouth and smiling eyes smile happy"},
+	{"😂", "face with tears of joy laugh lol"},
+	{"🤣", "rolling on the floor laughing rofl"},
+	{"😅", "smiling face with open mouth and cold sweat sweat smile"},
+	{"😉", "winking face wink"},
+	{"😊", "smiling face with smiling eyes blush"},
+	{"😍", "smiling face with heart-shaped eyes love"},
+	{"😘", "face throwing a kiss"},
+	{"😎", "smiling face with sunglasses cool"},
+	{"🤔", "thinking face hmm"},
+	{"🙄", "face with rolling eyes"},
+	{"😐", "neutral face"},
+	{"😬", "grimacing face"},
+	{"😢", "crying face sad"},
+	{"😭", "loudly crying face sob"},
+	{"😮", "face with open mouth wow surprised"},
+	{"😱", "face screaming in fear"},
+	{"😴", "sleeping face"},
+	{"🤯", "exploding head mind blown"},
+	{"🥳", "partying face"},
+	{"🤝", "handshake deal"},
+	{"🙏", "person with folded hands please thanks pray"},
+	{"👏", "clapping hands sign clap"},
+	{"🙌", "person raising both hands in celebration hooray"},
+	{"👋", "waving hand sign wave hello bye"},
+	{"👀", "eyes look"},
+	{"💪", "flexed biceps strong"},
+	{"✌️", "victory hand peace"},
+	{"👌", "ok hand sign okay"},
+	{"🤞", "hand with index and middle fingers crossed fingers crossed luck"},
+	{"🫡", "saluting face salute"},
+	{"❤️", "red heart love"},
+	{"💔", "broken heart"},
+	{"💯", "hundred points symbol 100"},
+	{"🔥", "fire lit"},
+	{"✨", "sparkles"},
+	{"⭐", "white medium star"},
+	{"🎉", "party popper tada celebrate"},
+	{"🎂", "birthday cake"},
+	{"🎁", "wrapped present gift"},
+	{"✅", "white heavy check mark done yes"},
+	{"❌", "cross mark no"},
+	{"⚠️", "warning sign"},
+	{"❓", "black question mark ornament question"},
+	{"❗", "heavy exclamation mark symbol exclamation"},
+	{"💡", "electric light bulb idea"},
+	{"📌", "pushpin pin"},
+	{"📎", "paperclip attachment"},
+	{"📅", "calendar date"},
+	{"⏰", "alarm clock"},
+	{"⏳", "hourglass with flowing sand wait"},
+	{"📧", "e-mail symbol email"},
+	{"📞", "telephone receiver phone call"},
+	{"💻", "personal computer laptop"},
+	{"🚀", "rocket ship launch"},
+	{"🐛", "bug"},
+	{"🔧", "wrench fix"},
+	{"🔒", "lock"},
+	{"📝", "memo note"},
+	{"📈", "chart with upwards trend"},
+	{"💰", "money bag"},
+	{"☕", "hot beverage coffee"},
+	{"🍕", "slice of pizza"},
+	{"🍺", "beer mug"},
+	{"🏖️", "beach with umbrella vacation"},
+	{"✈️", "airplane travel flight"},
+	{"🏠", "house building home"},
+	{"🌞", "sun with face"},
+	{"🌧️", "cloud with rain"},
+	{"🎄", "christmas tree"},
+	{"🐶", "dog face"},
+	{"🐱", "cat face"},
+	{"🤷", "shrug"},
+	{"🤦", "face palm facepalm"},
+	{"🤷‍♀️", "woman shrugging shrug"},
+	{"🤷‍♂️", "man shrugging shrug"},
+	{"👨‍💻", "man technologist developer"},
+	{"👩‍💻", "woman technologist developer"},
+	{"🏳️‍🌈", "rainbow flag pride"},
+	{"🇺🇸", "flag united states usa"},
+	{"🇬🇧", "flag united kingdom uk"},
+	{"🇪🇺", "flag european union eu"},
+	{"🇩🇪", "flag germany"},
+	{"🇫🇷", "flag france"},
+	{"🇯🇵", "flag japan"},
+	{"🇨🇳", "flag china"},
+}
+
+// symbolRanges are the blocks searched by name besides the popular emoji: accented
+// letters, Greek, punctuation, currency, arrows, maths, technical and other symbols, and
+// the emoji blocks
+var symbolRanges = [][2]rune{
+	{0x00A1, 0x017F},
+	{0x0391, 0x03C9},
+	{0x2010, 0x205E},
+	{0x20A0, 0x20C0},
+	{0x2100, 0x218B},
+	{0x2190, 0x23FF},
+	{0x2460, 0x24FF},
+	{0x25A0, 0x27BF},
+	{0x2B00, 0x2BFF},
+	{0x1F300, 0x1F64F},
+	{0x1F680, 0x1F6FF},
+	{0x1F900, 0x1FAFF},
+}
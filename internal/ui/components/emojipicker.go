@@ -0,0 +1,241 @@
+package components
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"unicode"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"golang.org/x/text/unicode/runenames"
+
+	"maily/internal/i18n"
+)
+
+// emojiMatchLimit bounds the matches listed for a search
+const emojiMatchLimit = 200
+
+// Symbol is an emoji or other character the picker inserts. Char is a whole grapheme
+// cluster, which may be several code points.
+type Symbol struct {
+	Char string
+	Name string // lower case, searched
+}
+
+// EmojiSelectedMsg is sent when a symbol is picked
+type EmojiSelectedMsg struct {
+	Char string
+}
+
+// EmojiPickerCancelledMsg is sent when the picker is closed without picking
+type EmojiPickerCancelledMsg struct{}
+
+var (
+	symbolsOnce sync.Once
+	symbols     []Symbol
+)
+
+// allSymbols returns the popular emoji followed by every named character of
+// symbolRanges, built on first use
+func allSymbols() []Symbol {
+	symbolsOnce.Do(func() {
+		seen := make(map[string]bool)
+		for _, s := range popularEmoji {
+			symbols = append(symbols, s)
+			seen[s.Char] = true
+		}
+		for _, span := range symbolRanges {
+			for r := span[0]; r <= span[1]; r++ {
+				if !unicode.IsGraphic(r) || unicode.Is(unicode.Mn, r) || seen[string(r)] {
+					continue
+				}
+				if name := runenames.Name(r); name != "" && !strings.HasPrefix(name, "<") {
+					symbols = append(symbols, Symbol{Char: string(r), Name: strings.ToLower(name)})
+				}
+			}
+		}
+	})
+	return symbols
+}
+
+// EmojiPicker picks an emoji or other character by fuzzy search over names: "tada" or
+// "party" finds 🎉, "em dash" finds —, and ↑↓ step through the matches
+type EmojiPicker struct {
+	input   textinput.Model
+	matches []Symbol
+	idx     int
+	width   int
+	height  int
+}
+
+// NewEmojiPicker creates a picker listing the popular emoji
+func NewEmojiPicker() EmojiPicker {
+	input := textinput.New()
+	input.Placeholder = i18n.T("emoji.placeholder")
+	input.CharLimit = 50
+	input.Width = 30
+	input.Focus()
+	return EmojiPicker{input: input, matches: popularEmoji}
+}
+
+// SetSize sets the screen size the picker is centered in
+func (e *EmojiPicker) SetSize(width, height int) {
+	e.width = width
+	e.height = height
+}
+
+// Update handles key messages for the emoji picker
+func (e EmojiPicker) Update(msg tea.Msg) (EmojiPicker, tea.Cmd) {
+	if msg, ok := msg.(tea.KeyMsg); ok {
+		switch msg.String() {
+		case "esc", "ctrl+e":
+			return e, func() tea.Msg { return EmojiPickerCancelledMsg{} }
+		case "enter":
+			if e.idx < len(e.matches) {
+				char := e.matches[e.idx].Char
+				return e, func() tea.Msg { return EmojiSelectedMsg{Char: char} }
+			}
+			return e, nil
+		case "up", "ctrl+p":
+			if e.idx > 0 {
+				e.idx--
+			}
+			return e, nil
+		case "down", "ctrl+n", "tab":
+			if e.idx < len(e.matches)-1 {
+				e.idx++
+			}
+			return e, nil
+		}
+	}
+
+	var cmd tea.Cmd
+	e.input, cmd = e.input.Update(msg)
+	e.matches = matchSymbols(e.input.Value())
+	e.idx = 0
+	return e, cmd
+}
+
+// View renders the picker as a centered dialog
+func (e EmojiPicker) View() string {
+	dialogWidth := min(e.width-20, 60)
+	listHeight := max(5, min(12, e.height-16))
+
+	titleStyle := lipgloss.NewStyle().Bold(true).Foreground(Primary)
+	selectedStyle := lipgloss.NewStyle().Bold(true).Foreground(Bg).Background(Primary).Padding(0, 1)
+	normalStyle := lipgloss.NewStyle().Foreground(Text).Padding(0, 1)
+	dimStyle := lipgloss.NewStyle().Foreground(Muted)
+
+	start := 0
+	if e.idx >= listHeight {
+		start = e.idx - listHeight + 1
+	}
+	end := min(start+listHeight, len(e.matches))
+
+	var items []string
+	for i := start; i < end; i++ {
+		s := e.matches[i]
+		// Wide and narrow characters line up in a cell two columns wide
+		char := s.Char + strings.Repeat(" ", max(0, 2-lipgloss.Width(s.Char)))
+		line := char + "  " + truncate(s.Name, dialogWidth-14)
+		if i == e.idx {
+			items = append(items, selectedStyle.Render("→ "+line))
+		} else {
+			items = append(items, normalStyle.Render("  "+line))
+		}
+	}
+	list := strings.Join(items, "\n")
+	if len(e.matches) == 0 {
+		list = dimStyle.Render(i18n.T("emoji.no_match"))
+	}
+
+	count := ""
+	if len(e.matches) > 0 {
+		count = dimStyle.Render(fmt.Sprintf("  (%d/%d)", e.idx+1, len(e.matches)))
+	}
+
+	content := lipgloss.JoinVertical(
+		lipgloss.Left,
+		titleStyle.Render(i18n.T("emoji.title")),
+		"",
+		e.input.View()+count,
+		"",
+		list,
+		"",
+		dimStyle.Render(i18n.T("emoji.hint")),
+	)
+
+	dialogStyle := lipgloss.NewStyle().
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(Primary).
+		Padding(1, 2).
+		Width(dialogWidth)
+
+	return lipgloss.Place(
+		e.width,
+		e.height-4,
+		lipgloss.Center,
+		lipgloss.Center,
+		dialogStyle.Render(content),
+	)
+}
+
+// matchSymbols returns the symbols whose names match query, best first and popular
+// emoji ahead of others; the popular emoji for an empty query. A code point such as "U+2014" or "2014" matches its character.
+func matchSymbols(query string) []Symbol {
+	query = strings.TrimSpace(query)
+	if query == "" {
+		return popularEmoji
+	}
+
+	type match struct {
+		symbol Symbol
+		score  int
+	}
+	var matches []match
+	if r, ok := parseCodePoint(query); ok {
+		matches = append(matches, match{Symbol{Char: string(r), Name: strings.ToLower(runenames.Name(r))}, 1 << 20})
+	}
+	for i, s := range allSymbols() {
+		if score, ok := fuzzyScore(query, s.Name); ok {
+			if i < len(popularEmoji) {
+				score += 100
+			}
+			matches = append(matches, match{s, score})
+		}
+	}
+	// Among equal matches the shortest name is the closest: "euro sign" before
+	// "euro-currency sign"
+	sort.SliceStable(matches, func(i, j int) bool {
+		if matches[i].score != matches[j].score {
+			return matches[i].score > matches[j].score
+		}
+		return len(matches[i].symbol.Name) < len(matches[j].symbol.Name)
+	})
+
+	symbols := make([]Symbol, 0, min(len(matches), emojiMatchLimit))
+	for _, m := range matches[:min(len(matches), emojiMatchLimit)] {
+		symbols = append(symbols, m.symbol)
+	}
+	return symbols
+}
+
+// parseCodePoint reads "U+1F600", "u+2014" or a bare hex number of four to six digits
+// as a printable code point. A bare number needs a decimal digit, so words such as
+// "face" are searched as names.
+func parseCodePoint(query string) (rune, bool) {
+	upper := strings.ToUpper(query)
+	hex := strings.TrimPrefix(upper, "U+")
+	if len(hex) < 4 || len(hex) > 6 || (hex == upper && !strings.ContainsAny(hex, "0123456789")) {
+		return 0, false
+	}
+	n, err := strconv.ParseUint(hex, 16, 32)
+	if err != nil || !unicode.IsGraphic(rune(n)) {
+		return 0, false
+	}
+	return rune(n), true
+}
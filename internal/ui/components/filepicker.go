@@ -6,9 +6,11 @@ import (
 	"path/filepath"
 	"sort"
 	"strings"
+	"time"
 
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
+	"github.com/mattn/go-runewidth"
 )
 
 // FileSelectedMsg is sent when a file is selected
@@ -18,15 +20,26 @@ type FileSelectedMsg struct {
 	Size int64
 }
 
+// FilesSelectedMsg is sent when several files are confirmed in a multi-select picker
+type FilesSelectedMsg struct {
+	Files []FileSelectedMsg
+}
+
+// DirSelectedMsg is sent when a directory is chosen in a directory picker
+type DirSelectedMsg struct {
+	Path string
+}
+
 // FilePickerCancelledMsg is sent when the picker is cancelled
 type FilePickerCancelledMsg struct{}
 
 // FileEntry represents a file or directory in the picker
 type FileEntry struct {
-	Name  string
-	Path  string
-	Size  int64
-	IsDir bool
+	Name    string
+	Path    string
+	Size    int64
+	ModTime time.Time
+	IsDir   bool
 }
 
 // FilePicker is a file browser component
@@ -38,6 +51,10 @@ type FilePicker struct {
 	height     int
 	err        error
 	showHidden bool
+	dirOnly    bool           // pick a directory instead of a file
+	multi      bool           // space marks files, enter confirms all marked
+	selected   []FileEntry    // marked files, in the order they were marked
+	itemCounts map[string]int // items in the directories the cursor has been on, -1 when unreadable
 }
 
 // NewFilePicker creates a new file picker starting at the home directory
@@ -69,6 +86,25 @@ func NewFilePickerAt(dir string) FilePicker {
 	return fp
 }
 
+// NewMultiFilePickerAt creates a file picker starting at dir in which several files can be marked
+func NewMultiFilePickerAt(dir string) FilePicker {
+	fp := NewFilePickerAt(dir)
+	fp.multi = true
+	return fp
+}
+
+// NewDirPickerAt creates a picker for choosing a directory, starting at dir
+func NewDirPickerAt(dir string) FilePicker {
+	fp := FilePicker{
+		currentDir: dir,
+		width:      80,
+		height:     24,
+		dirOnly:    true,
+	}
+	fp.loadDirectory()
+	return fp
+}
+
 func (fp *FilePicker) loadDirectory() {
 	fp.entries = make([]FileEntry, 0)
 	fp.err = nil
@@ -105,15 +141,16 @@ func (fp *FilePicker) loadDirectory() {
 		}
 
 		fe := FileEntry{
-			Name:  name,
-			Path:  filepath.Join(fp.currentDir, name),
-			Size:  info.Size(),
-			IsDir: entry.IsDir(),
+			Name:    name,
+			Path:    filepath.Join(fp.currentDir, name),
+			Size:    info.Size(),
+			ModTime: info.ModTime(),
+			IsDir:   entry.IsDir(),
 		}
 
 		if entry.IsDir() {
 			dirs = append(dirs, fe)
-		} else {
+		} else if !fp.dirOnly {
 			files = append(files, fe)
 		}
 	}
@@ -134,6 +171,29 @@ func (fp *FilePicker) loadDirectory() {
 	if fp.cursor >= len(fp.entries) {
 		fp.cursor = 0
 	}
+	fp.itemCounts = make(map[string]int)
+	fp.countItems()
+}
+
+// countItems reads the directory under the cursor for the preview, once per visit to
+// the directory it's in
+func (fp *FilePicker) countItems() {
+	if fp.cursor < 0 || fp.cursor >= len(fp.entries) {
+		return
+	}
+	entry := fp.entries[fp.cursor]
+	if !entry.IsDir || entry.Name == ".." {
+		return
+	}
+	if _, ok := fp.itemCounts[entry.Path]; ok {
+		return
+	}
+	items, err := os.ReadDir(entry.Path)
+	if err != nil {
+		fp.itemCounts[entry.Path] = -1
+		return
+	}
+	fp.itemCounts[entry.Path] = len(items)
 }
 
 // SetSize sets the picker dimensions
@@ -168,6 +228,15 @@ func (fp FilePicker) Update(msg tea.Msg) (FilePicker, tea.Cmd) {
 					fp.currentDir = entry.Path
 					fp.cursor = 0
 					fp.loadDirectory()
+				} else if len(fp.selected) > 0 {
+					// Confirm the marked files
+					files := make([]FileSelectedMsg, len(fp.selected))
+					for i, f := range fp.selected {
+						files[i] = FileSelectedMsg{Path: f.Path, Name: f.Name, Size: f.Size}
+					}
+					return fp, func() tea.Msg {
+						return FilesSelectedMsg{Files: files}
+					}
 				} else {
 					// Select file
 					return fp, func() tea.Msg {
@@ -179,6 +248,22 @@ func (fp FilePicker) Update(msg tea.Msg) (FilePicker, tea.Cmd) {
 					}
 				}
 			}
+		case " ":
+			// Mark or unmark the file under the cursor
+			if fp.multi && fp.cursor >= 0 && fp.cursor < len(fp.entries) && !fp.entries[fp.cursor].IsDir {
+				fp.toggleSelected(fp.entries[fp.cursor])
+				if fp.cursor < len(fp.entries)-1 {
+					fp.cursor++
+				}
+			}
+		case "s":
+			// Choose the current directory
+			if fp.dirOnly {
+				dir := fp.currentDir
+				return fp, func() tea.Msg {
+					return DirSelectedMsg{Path: dir}
+				}
+			}
 		case "backspace":
 			// Go to parent directory
 			if fp.currentDir != "/" {
@@ -219,10 +304,52 @@ func (fp FilePicker) Update(msg tea.Msg) (FilePicker, tea.Cmd) {
 		case "end", "G":
 			fp.cursor = len(fp.entries) - 1
 		}
+		fp.countItems()
 	}
 	return fp, nil
 }
 
+// toggleSelected marks a file, or unmarks it if it was already marked
+func (fp *FilePicker) toggleSelected(entry FileEntry) {
+	if i := fp.selectedIndex(entry.Path); i >= 0 {
+		fp.selected = append(fp.selected[:i], fp.selected[i+1:]...)
+		return
+	}
+	fp.selected = append(fp.selected, entry)
+}
+
+func (fp FilePicker) selectedIndex(path string) int {
+	for i, f := range fp.selected {
+		if f.Path == path {
+			return i
+		}
+	}
+	return -1
+}
+
+// preview describes the entry under the cursor and, in multi-select mode, the marked files
+func (fp FilePicker) preview() string {
+	var parts []string
+	if fp.cursor >= 0 && fp.cursor < len(fp.entries) {
+		entry := fp.entries[fp.cursor]
+		if entry.IsDir {
+			if n, ok := fp.itemCounts[entry.Path]; ok && n >= 0 {
+				parts = append(parts, fmt.Sprintf("%s/ · %d items", entry.Name, n))
+			}
+		} else {
+			parts = append(parts, fmt.Sprintf("%s · %s · modified %s", entry.Name, formatFileSize(entry.Size), entry.ModTime.Format("2006-01-02 15:04")))
+		}
+	}
+	if len(fp.selected) > 0 {
+		var total int64
+		for _, f := range fp.selected {
+			total += f.Size
+		}
+		parts = append(parts, fmt.Sprintf("%d selected (%s)", len(fp.selected), formatFileSize(total)))
+	}
+	return strings.Join(parts, "  ·  ")
+}
+
 // View renders the file picker
 func (fp FilePicker) View() string {
 	var b strings.Builder
@@ -266,7 +393,14 @@ func (fp FilePicker) View() string {
 		if entry.IsDir {
 			line = icon + entry.Name + "/"
 		} else {
-			line = fmt.Sprintf("%s%-30s %s", icon, truncateName(entry.Name, 30), formatFileSize(entry.Size))
+			line = fmt.Sprintf("%s%s %s", icon, runewidth.FillRight(truncateName(entry.Name, 30), 30), formatFileSize(entry.Size))
+		}
+		if fp.multi {
+			mark := "  "
+			if fp.selectedIndex(entry.Path) >= 0 {
+				mark = "✓ "
+			}
+			line = mark + line
 		}
 
 		style := lipgloss.NewStyle().Padding(0, 1)
@@ -324,14 +458,26 @@ func (fp FilePicker) View() string {
 		hiddenStatus = " (showing hidden)"
 	}
 
+	title := "Select File"
+	hint := "↑/↓ navigate • enter select • backspace parent • ~ home • . toggle hidden • esc cancel"
+	if fp.multi {
+		title = "Select Files"
+		hint = "↑/↓ navigate • space mark • enter attach • backspace parent • ~ home • . toggle hidden • esc cancel"
+	}
+	if fp.dirOnly {
+		title = "Save to Folder"
+		hint = "↑/↓ navigate • enter open • s save here • backspace parent • ~ home • . toggle hidden • esc cancel"
+	}
+
 	content := lipgloss.JoinVertical(
 		lipgloss.Left,
-		titleStyle.Render("Select File"),
+		titleStyle.Render(title),
 		pathStyle.Render(displayPath+hiddenStatus),
 		"",
 		b.String(),
 		"",
-		hintStyle.Render("↑/↓ navigate • enter select • backspace parent • ~ home • . toggle hidden • esc cancel"),
+		pathStyle.UnsetMarginBottom().Render(fp.preview()),
+		hintStyle.Render(hint),
 	)
 
 	// Calculate container width
@@ -360,19 +506,20 @@ func (fp FilePicker) CurrentDir() string {
 	return fp.currentDir
 }
 
-// truncateName truncates a filename to maxLen characters
+// truncateName truncates a filename to maxLen display columns
 func truncateName(name string, maxLen int) string {
-	if len(name) <= maxLen {
+	if runewidth.StringWidth(name) <= maxLen {
 		return name
 	}
 	// Keep extension visible
 	ext := filepath.Ext(name)
-	if len(ext) > 0 && len(ext) < maxLen-3 {
+	extWidth := runewidth.StringWidth(ext)
+	if extWidth > 0 && extWidth < maxLen-3 {
 		base := name[:len(name)-len(ext)]
-		availLen := maxLen - len(ext) - 3
+		availLen := maxLen - extWidth - 3
 		if availLen > 0 {
-			return base[:availLen] + "..." + ext
+			return runewidth.Truncate(base, availLen, "") + "..." + ext
 		}
 	}
-	return name[:maxLen-3] + "..."
+	return truncate(name, maxLen)
 }
@@ -0,0 +1,56 @@
+package components
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func TestFilePickerPreviewCountsOnce(t *testing.T) {
+	dir := t.TempDir()
+	for _, name := range []string{"docs/a.txt", "docs/b.txt", "photos/c.jpg", "notes.txt"} {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte("x"), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	fp := NewFilePickerAt(dir) // .., docs, photos, notes.txt
+	down := tea.KeyMsg{Type: tea.KeyDown}
+	fp, _ = fp.Update(down)
+	if got := fp.preview(); !strings.Contains(got, "docs/ · 2 items") {
+		t.Errorf("preview() = %q, want docs' 2 items", got)
+	}
+
+	// Rendering doesn't read the directory again
+	if err := os.WriteFile(filepath.Join(dir, "docs", "new.txt"), []byte("x"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	fp.View()
+	if got := fp.preview(); !strings.Contains(got, "2 items") {
+		t.Errorf("preview() after a render = %q, want the count read when the cursor moved", got)
+	}
+
+	fp, _ = fp.Update(down)
+	if got := fp.preview(); !strings.Contains(got, "photos/ · 1 items") {
+		t.Errorf("preview() = %q, want photos' 1 item", got)
+	}
+	fp, _ = fp.Update(down)
+	if got := fp.preview(); !strings.HasPrefix(got, "notes.txt · 1 B") {
+		t.Errorf("preview() = %q, want the file's details", got)
+	}
+
+	// Reloading the directory counts afresh
+	fp, _ = fp.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'.'}})
+	fp, _ = fp.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'g'}})
+	fp, _ = fp.Update(down)
+	if got := fp.preview(); !strings.Contains(got, "docs/ · 3 items") {
+		t.Errorf("preview() after reloading = %q, want docs' 3 items", got)
+	}
+}
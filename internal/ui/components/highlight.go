@@ -0,0 +1,240 @@
+package components
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// ANSI styles for search matches; the current match stands out from the rest
+const (
+	matchStyle        = "\x1b[30;43m"       // black on yellow
+	currentMatchStyle = "\x1b[30;48;5;208m" // black on orange
+	misspelledStyle   = "\x1b[4;31m"        // red, underlined
+	resetStyle        = "\x1b[0m"
+)
+
+// HighlightMatches highlights case-insensitive occurrences of any of terms in rendered
+// (ANSI-styled) content. It returns the highlighted content and the line number of each
+// match in order; the match at index current gets a distinct color.
+func HighlightMatches(content string, terms []string, current int) (string, []int) {
+	var needles [][]rune
+	for _, term := range terms {
+		if term = strings.TrimSpace(term); term != "" {
+			needle := []rune(term)
+			for i, r := range needle {
+				needle[i] = unicode.ToLower(r)
+			}
+			needles = append(needles, needle)
+		}
+	}
+	if len(needles) == 0 {
+		return content, nil
+	}
+
+	lines := strings.Split(content, "\n")
+	var matchLines []int
+	for i, line := range lines {
+		ranges := findRanges([]rune(stripANSI(line)), needles)
+		if len(ranges) == 0 {
+			continue
+		}
+		first := len(matchLines)
+		for range ranges {
+			matchLines = append(matchLines, i)
+		}
+		lines[i] = highlightLine(line, ranges, current-first)
+	}
+	return strings.Join(lines, "\n"), matchLines
+}
+
+// UnderlineMisspelled underlines the words in rendered (ANSI-styled) content that
+// misspelled reports as wrong. words finds the checkable words in a line of visible text.
+func UnderlineMisspelled(content string, words func(text []rune) [][2]int, misspelled func(word string) bool) string {
+	lines := strings.Split(content, "\n")
+	for i, line := range lines {
+		text := []rune(stripANSI(line))
+		var ranges [][2]int
+		for _, r := range words(text) {
+			if misspelled(string(text[r[0]:r[1]])) {
+				ranges = append(ranges, r)
+			}
+		}
+		if len(ranges) > 0 {
+			lines[i] = styleRanges(line, ranges, func(int) string { return misspelledStyle })
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// QueryTerms extracts the free-text words of a search query, skipping operators such
+// as from:, is:unread, OR and -excluded words. Quoted phrases are kept whole.
+func QueryTerms(query string) []string {
+	var terms []string
+	var b strings.Builder
+	inQuotes := false
+
+	flush := func() {
+		word := b.String()
+		b.Reset()
+		if word == "" || word == "OR" || word == "AND" || strings.HasPrefix(word, "-") || strings.Contains(word, ":") {
+			return
+		}
+		terms = append(terms, word)
+	}
+
+	for _, r := range query {
+		switch {
+		case r == '"':
+			if inQuotes {
+				if phrase := strings.TrimSpace(b.String()); phrase != "" {
+					terms = append(terms, phrase)
+				}
+				b.Reset()
+			} else {
+				flush()
+			}
+			inQuotes = !inQuotes
+		case unicode.IsSpace(r) && !inQuotes:
+			flush()
+		default:
+			b.WriteRune(r)
+		}
+	}
+	flush()
+	return terms
+}
+
+// findRanges returns non-overlapping [start, end) rune ranges of lowercased needles in text, longest match first
+func findRanges(text []rune, needles [][]rune) [][2]int {
+	var ranges [][2]int
+	for i := 0; i < len(text); {
+		matched := 0
+		for _, needle := range needles {
+			if len(needle) > matched && hasPrefixAt(text, needle, i) {
+				matched = len(needle)
+			}
+		}
+		if matched == 0 {
+			i++
+			continue
+		}
+		ranges = append(ranges, [2]int{i, i + matched})
+		i += matched
+	}
+	return ranges
+}
+
+func hasPrefixAt(text, needle []rune, at int) bool {
+	if at+len(needle) > len(text) {
+		return false
+	}
+	for j, r := range needle {
+		if unicode.ToLower(text[at+j]) != r {
+			return false
+		}
+	}
+	return true
+}
+
+// highlightLine wraps the visible rune ranges of an ANSI-styled line in match styles
+func highlightLine(line string, ranges [][2]int, current int) string {
+	return styleRanges(line, ranges, func(i int) string {
+		if i == current {
+			return currentMatchStyle
+		}
+		return matchStyle
+	})
+}
+
+// styleRanges wraps the visible rune ranges of an ANSI-styled line in the style returned
+// for each range's index, restoring the line's own styling after each range
+func styleRanges(line string, ranges [][2]int, style func(i int) string) string {
+	var b strings.Builder
+	var active strings.Builder // escape sequences in effect since the last reset
+	visible := 0
+	next := 0 // index into ranges
+	inMatch := false
+
+	startStyle := func() string {
+		return style(next)
+	}
+
+	for i := 0; i < len(line); {
+		if line[i] == '\x1b' {
+			seq := escapeSequence(line[i:])
+			b.WriteString(seq)
+			if seq == resetStyle || seq == "\x1b[m" {
+				active.Reset()
+			} else {
+				active.WriteString(seq)
+			}
+			if inMatch {
+				b.WriteString(startStyle())
+			}
+			i += len(seq)
+			continue
+		}
+
+		if !inMatch && next < len(ranges) && visible == ranges[next][0] {
+			b.WriteString(startStyle())
+			inMatch = true
+		}
+
+		_, size := utf8.DecodeRuneInString(line[i:])
+		b.WriteString(line[i : i+size])
+		i += size
+		visible++
+
+		if inMatch && visible == ranges[next][1] {
+			b.WriteString(resetStyle + active.String())
+			inMatch = false
+			next++
+		}
+	}
+	if inMatch {
+		b.WriteString(resetStyle)
+	}
+	return b.String()
+}
+
+// escapeSequence returns the CSI or OSC escape sequence at the start of s
+func escapeSequence(s string) string {
+	if len(s) < 2 {
+		return s
+	}
+	switch s[1] {
+	case '[':
+		for j := 2; j < len(s); j++ {
+			if s[j] >= 0x40 && s[j] <= 0x7e {
+				return s[:j+1]
+			}
+		}
+	case ']':
+		for j := 2; j < len(s); j++ {
+			if s[j] == '\a' {
+				return s[:j+1]
+			}
+			if s[j] == '\x1b' && j+1 < len(s) && s[j+1] == '\\' {
+				return s[:j+2]
+			}
+		}
+	default:
+		return s[:2]
+	}
+	return s
+}
+
+// stripANSI removes escape sequences, leaving the visible text
+func stripANSI(s string) string {
+	var b strings.Builder
+	for i := 0; i < len(s); {
+		if s[i] == '\x1b' {
+			i += len(escapeSequence(s[i:]))
+			continue
+		}
+		b.WriteByte(s[i])
+		i++
+	}
+	return b.String()
+}
@@ -0,0 +1,147 @@
+package components
+
+import (
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"maily/internal/i18n"
+)
+
+// LabelEditor is a dialog for choosing which Gmail labels an email carries. Unlike the
+// label picker it selects several labels at once and doesn't change folders.
+type LabelEditor struct {
+	labels  []string        // custom labels to choose from
+	initial map[string]bool // labels the email had when the editor opened
+	checked map[string]bool
+	cursor  int
+	width   int
+	height  int
+}
+
+func NewLabelEditor() LabelEditor {
+	return LabelEditor{
+		initial: map[string]bool{},
+		checked: map[string]bool{},
+		width:   80,
+		height:  24,
+	}
+}
+
+// Open resets the editor to the account's labels, checking the ones the email has
+func (e *LabelEditor) Open(labels, current []string) {
+	e.labels = labels
+	e.initial = make(map[string]bool, len(current))
+	e.checked = make(map[string]bool, len(current))
+	for _, l := range current {
+		e.initial[l] = true
+		e.checked[l] = true
+	}
+	e.cursor = 0
+}
+
+func (e *LabelEditor) SetSize(width, height int) {
+	e.width = width
+	e.height = height
+}
+
+func (e LabelEditor) Update(msg tea.Msg) (LabelEditor, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.KeyMsg:
+		switch msg.String() {
+		case "up", "k":
+			if e.cursor > 0 {
+				e.cursor--
+			}
+		case "down", "j":
+			if e.cursor < len(e.labels)-1 {
+				e.cursor++
+			}
+		case " ", "x":
+			if e.cursor < len(e.labels) {
+				label := e.labels[e.cursor]
+				e.checked[label] = !e.checked[label]
+			}
+		}
+	}
+	return e, nil
+}
+
+// Changes returns the labels to add and remove to apply the selection
+func (e LabelEditor) Changes() (add, remove []string) {
+	for _, l := range e.labels {
+		switch {
+		case e.checked[l] && !e.initial[l]:
+			add = append(add, l)
+		case !e.checked[l] && e.initial[l]:
+			remove = append(remove, l)
+		}
+	}
+	return add, remove
+}
+
+func (e LabelEditor) View() string {
+	var b strings.Builder
+
+	if len(e.labels) == 0 {
+		b.WriteString(lipgloss.NewStyle().Foreground(Muted).Padding(0, 2).Render(i18n.T("label.none")))
+	}
+
+	listHeight := max(5, e.height-10)
+	start := 0
+	if e.cursor >= listHeight {
+		start = e.cursor - listHeight + 1
+	}
+	end := min(start+listHeight, len(e.labels))
+
+	for i := start; i < end; i++ {
+		label := e.labels[i]
+		box := "[ ] "
+		if e.checked[label] {
+			box = "[✓] "
+		}
+
+		style := lipgloss.NewStyle().Padding(0, 2)
+		if i == e.cursor {
+			style = style.Bold(true).Foreground(Text).Background(Primary)
+		} else if e.checked[label] {
+			style = style.Foreground(Primary)
+		} else {
+			style = style.Foreground(Text)
+		}
+		b.WriteString(style.Render(box + label))
+		if i < end-1 {
+			b.WriteString("\n")
+		}
+	}
+
+	titleStyle := lipgloss.NewStyle().
+		Bold(true).
+		Foreground(Primary).
+		MarginBottom(1)
+
+	hintStyle := lipgloss.NewStyle().
+		Foreground(Muted).
+		MarginTop(1)
+
+	content := lipgloss.JoinVertical(
+		lipgloss.Left,
+		titleStyle.Render(i18n.T("label.edit_title")),
+		"",
+		b.String(),
+		"",
+		hintStyle.Render("↑/↓ "+i18n.T("help.navigate")+" • space "+i18n.T("help.toggle")+" • enter "+i18n.T("help.confirm")+" • esc "+i18n.T("help.cancel")),
+	)
+
+	return lipgloss.Place(
+		e.width,
+		e.height-4,
+		lipgloss.Center,
+		lipgloss.Center,
+		lipgloss.NewStyle().
+			Border(lipgloss.RoundedBorder()).
+			BorderForeground(Primary).
+			Padding(1, 3).
+			Render(content),
+	)
+}
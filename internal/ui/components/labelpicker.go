@@ -1,6 +1,7 @@
 package components
 
 import (
+	"fmt"
 	"sort"
 	"strings"
 
@@ -54,15 +55,23 @@ var folderSortOrder = map[string]int{
 	mail.Trash:    6,
 }
 
+// SmartFolder is a saved filter listed in the picker with its count of matching emails
+type SmartFolder struct {
+	Name  string
+	Count int // -1 until counted
+}
+
 // LabelPicker is a full-screen view for selecting a label/folder
 type LabelPicker struct {
-	folders  []string // System folders (INBOX, [Gmail]/*)
-	labels   []string // Custom labels
-	items    []pickerItem
-	cursor   int
-	selected string // Currently selected label (raw name)
-	width    int
-	height   int
+	smart         []SmartFolder // Saved filters, listed first
+	folders       []string      // System folders (INBOX, [Gmail]/*)
+	labels        []string      // Custom labels
+	items         []pickerItem
+	cursor        int
+	selected      string // Currently selected label (raw name)
+	selectedSmart string // Currently selected smart folder, instead of a label
+	width         int
+	height        int
 }
 
 type pickerItem struct {
@@ -70,6 +79,7 @@ type pickerItem struct {
 	display   string // Display text
 	isHeader  bool   // True if this is a section header
 	isFolder  bool   // True if this is a system folder
+	smart     string // Smart folder name, for smart folders
 }
 
 func NewLabelPicker() LabelPicker {
@@ -138,9 +148,45 @@ func (p *LabelPicker) SetLabels(labels []string) {
 	p.moveCursorToSelected()
 }
 
+// SetSmartFolders sets the smart folders listed above the folders, keeping the cursor
+// on the item it was on
+func (p *LabelPicker) SetSmartFolders(folders []SmartFolder) {
+	var current pickerItem
+	if p.cursor >= 0 && p.cursor < len(p.items) {
+		current = p.items[p.cursor]
+	}
+	p.smart = folders
+	p.buildItems()
+	for i, item := range p.items {
+		if !item.isHeader && item.label == current.label && item.smart == current.smart {
+			p.cursor = i
+			return
+		}
+	}
+	p.moveCursorToSelected()
+}
+
 func (p *LabelPicker) buildItems() {
 	p.items = make([]pickerItem, 0)
 
+	// Smart folders section
+	if len(p.smart) > 0 {
+		p.items = append(p.items, pickerItem{
+			display:  i18n.T("label.smart_folders"),
+			isHeader: true,
+		})
+		for _, f := range p.smart {
+			display := f.Name
+			if f.Count >= 0 {
+				display = fmt.Sprintf("%s (%d)", f.Name, f.Count)
+			}
+			p.items = append(p.items, pickerItem{
+				display: display,
+				smart:   f.Name,
+			})
+		}
+	}
+
 	// Folders section
 	if len(p.folders) > 0 {
 		p.items = append(p.items, pickerItem{
@@ -180,7 +226,7 @@ func (p *LabelPicker) buildItems() {
 
 func (p *LabelPicker) moveCursorToSelected() {
 	for i, item := range p.items {
-		if item.label == p.selected {
+		if !item.isHeader && p.isSelected(item) {
 			p.cursor = i
 			return
 		}
@@ -211,9 +257,25 @@ func (p *LabelPicker) skipHeaders(direction int) {
 
 func (p *LabelPicker) SetSelected(label string) {
 	p.selected = label
+	p.selectedSmart = ""
+	p.moveCursorToSelected()
+}
+
+// SetSelectedSmartFolder marks the named smart folder as the one being viewed
+func (p *LabelPicker) SetSelectedSmartFolder(name string) {
+	p.selected = ""
+	p.selectedSmart = name
 	p.moveCursorToSelected()
 }
 
+// isSelected reports whether item is the folder, label or smart folder being viewed
+func (p LabelPicker) isSelected(item pickerItem) bool {
+	if item.smart != "" {
+		return item.smart == p.selectedSmart
+	}
+	return p.selectedSmart == "" && item.label == p.selected
+}
+
 func (p *LabelPicker) SetSize(width, height int) {
 	p.width = width
 	p.height = height
@@ -275,7 +337,7 @@ func (p LabelPicker) View() string {
 		} else {
 			// Selectable item
 			prefix := "  "
-			if item.label == p.selected {
+			if p.isSelected(item) {
 				prefix = "● "
 			}
 
@@ -286,7 +348,7 @@ func (p LabelPicker) View() string {
 					Bold(true).
 					Foreground(Text).
 					Background(Primary)
-			} else if item.label == p.selected {
+			} else if p.isSelected(item) {
 				style = style.
 					Bold(true).
 					Foreground(Primary)
@@ -335,6 +397,28 @@ func (p LabelPicker) View() string {
 	)
 }
 
+// SelectLine moves the cursor onto the item drawn on a line of View, given as plain
+// text; false when the line holds no selectable item
+func (p *LabelPicker) SelectLine(line string) bool {
+	text := strings.TrimSpace(strings.Trim(strings.TrimSpace(line), "│"))
+	text = strings.TrimSpace(strings.TrimPrefix(text, "●"))
+	for i, item := range p.items {
+		if !item.isHeader && item.display == text {
+			p.cursor = i
+			return true
+		}
+	}
+	return false
+}
+
+// CursorSmartFolder returns the name of the smart folder at cursor position, if it is on one
+func (p LabelPicker) CursorSmartFolder() (string, bool) {
+	if p.cursor >= 0 && p.cursor < len(p.items) && p.items[p.cursor].smart != "" {
+		return p.items[p.cursor].smart, true
+	}
+	return "", false
+}
+
 // CursorLabel returns the label at cursor position
 func (p LabelPicker) CursorLabel() string {
 	if p.cursor >= 0 && p.cursor < len(p.items) && !p.items[p.cursor].isHeader {
@@ -343,6 +427,11 @@ func (p LabelPicker) CursorLabel() string {
 	return mail.INBOX
 }
 
+// CustomLabels returns the user's labels, excluding system folders
+func (p LabelPicker) CustomLabels() []string {
+	return p.labels
+}
+
 // SelectedLabel returns the currently selected label
 func (p LabelPicker) SelectedLabel() string {
 	return p.selected
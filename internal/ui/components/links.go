@@ -0,0 +1,154 @@
+package components
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/mattn/go-runewidth"
+
+	"maily/internal/i18n"
+	"maily/internal/mail"
+)
+
+// RenderLinkPicker lists the links in an email with the host each one goes to
+func RenderLinkPicker(width, height int, links []mail.Link, selectedIdx int) string {
+	dialogWidth := min(width-20, 90)
+
+	titleStyle := lipgloss.NewStyle().
+		Bold(true).
+		Foreground(Primary).
+		MarginBottom(1)
+
+	selectedStyle := lipgloss.NewStyle().
+		Bold(true).
+		Foreground(Bg).
+		Background(Primary).
+		Padding(0, 1)
+
+	normalStyle := lipgloss.NewStyle().
+		Foreground(Text).
+		Padding(0, 1)
+
+	hostStyle := lipgloss.NewStyle().
+		Foreground(Muted)
+
+	hintStyle := lipgloss.NewStyle().
+		Foreground(Muted).
+		MarginTop(1)
+
+	// Keep the selected link in view when there are more than fit
+	visible := max(3, height-16)
+	start := max(0, min(selectedIdx-visible/2, len(links)-visible))
+	end := min(len(links), start+visible)
+
+	var items []string
+	for i := start; i < end; i++ {
+		link := links[i]
+		text := link.Text
+		if text == "" {
+			text = link.URL
+		}
+		host := ""
+		if u, err := url.Parse(link.URL); err == nil && u.Host != "" {
+			host = u.Hostname()
+		}
+		line := fmt.Sprintf("%2d. %s", i+1, truncate(text, max(10, dialogWidth-runewidth.StringWidth(host)-16)))
+		if i == selectedIdx {
+			items = append(items, selectedStyle.Render("→ "+line+"  "+host))
+		} else {
+			items = append(items, normalStyle.Render("  "+line)+"  "+hostStyle.Render(host))
+		}
+	}
+
+	content := lipgloss.JoinVertical(
+		lipgloss.Left,
+		titleStyle.Render("🔗 "+i18n.T("links.title", map[string]any{"Count": len(links)})),
+		strings.Join(items, "\n"),
+		hintStyle.Render(i18n.T("links.hint")),
+	)
+
+	dialogStyle := lipgloss.NewStyle().
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(Primary).
+		Padding(1, 2).
+		Width(dialogWidth)
+
+	return lipgloss.Place(
+		width,
+		height-4,
+		lipgloss.Center,
+		lipgloss.Center,
+		dialogStyle.Render(content),
+	)
+}
+
+// RenderLinkConfirm asks before opening a link, showing the full URL with its host
+// highlighted, where a redirect really leads, and any reasons the link looks deceptive
+func RenderLinkConfirm(width, height int, link mail.Link, resolved string, signals []string) string {
+	dialogWidth := min(width-20, 90)
+	wrapWidth := dialogWidth - 8
+
+	titleStyle := lipgloss.NewStyle().
+		Bold(true).
+		Foreground(Primary).
+		MarginBottom(1)
+
+	labelStyle := lipgloss.NewStyle().
+		Foreground(Muted)
+
+	urlStyle := lipgloss.NewStyle().
+		Foreground(TextDim).
+		Width(wrapWidth)
+
+	hintStyle := lipgloss.NewStyle().
+		Foreground(Muted).
+		MarginTop(1)
+
+	lines := []string{titleStyle.Render(i18n.T("links.confirm_title"))}
+	if link.Text != "" && link.Text != link.URL {
+		lines = append(lines, labelStyle.Render(i18n.T("links.text")), urlStyle.Render(link.Text), "")
+	}
+	lines = append(lines, labelStyle.Render(i18n.T("links.url")), urlStyle.Render(highlightHost(link.URL)))
+	if resolved != "" {
+		lines = append(lines, "", labelStyle.Render(i18n.T("links.leads_to")), urlStyle.Render(highlightHost(resolved)))
+	}
+	if len(signals) > 0 {
+		warnStyle := lipgloss.NewStyle().Foreground(Danger).Bold(true).Width(wrapWidth)
+		lines = append(lines, "")
+		for _, s := range signals {
+			lines = append(lines, warnStyle.Render("⚠ "+s))
+		}
+	}
+	lines = append(lines, hintStyle.Render(i18n.T("links.confirm_hint")))
+
+	dialogStyle := lipgloss.NewStyle().
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(Primary).
+		Padding(1, 3).
+		Width(dialogWidth)
+
+	return lipgloss.Place(
+		width,
+		height-4,
+		lipgloss.Center,
+		lipgloss.Center,
+		dialogStyle.Render(lipgloss.JoinVertical(lipgloss.Left, lines...)),
+	)
+}
+
+// highlightHost shows a URL with its host in bold, since the host is what decides
+// where the link really goes
+func highlightHost(link string) string {
+	u, err := url.Parse(link)
+	if err != nil || u.Host == "" {
+		return link
+	}
+	i := strings.Index(link, u.Host)
+	if i < 0 {
+		return link
+	}
+	hostStyle := lipgloss.NewStyle().Foreground(Text).Bold(true).Underline(true)
+	return link[:i] + hostStyle.Render(u.Host) + link[i+len(u.Host):]
+}
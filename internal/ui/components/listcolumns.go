@@ -0,0 +1,288 @@
+package components
+
+import (
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/charmbracelet/lipgloss"
+
+	"maily/internal/i18n"
+	"maily/internal/mail"
+)
+
+// Mail list columns
+const (
+	ColumnCheckbox = "checkbox" // selection checkbox, only shown while selecting
+	ColumnFlags    = "flags"    // suspicious, unread, answered, forwarded, flagged and attachment marks
+	ColumnAccount  = "account"
+	ColumnFrom     = "from"
+	ColumnSubject  = "subject" // label chips and subject
+	ColumnSnippet  = "snippet"
+	ColumnSize     = "size" // the message size from the server, or attachments plus the cached body
+	ColumnDate     = "date"
+)
+
+// columnWidths are the default widths; 0 marks the columns sharing the free space
+var columnWidths = map[string]int{
+	ColumnCheckbox: 5,
+	ColumnFlags:    10,
+	ColumnAccount:  16,
+	ColumnFrom:     20,
+	ColumnSubject:  0,
+	ColumnSnippet:  0,
+	ColumnSize:     8,
+	ColumnDate:     13,
+}
+
+// ListColumn is a mail list column and its width in characters; 0 uses the default
+type ListColumn struct {
+	Name  string
+	Width int
+}
+
+// ListLayout is the mail list's columns, in display order, its row density and whether
+// rows start with the sender's initials
+type ListLayout struct {
+	Columns []ListColumn // nil for DefaultListColumns
+	Relaxed bool         // a blank line between emails
+	Avatars bool         // a coloured block of the sender's initials ahead of the columns
+}
+
+// DefaultListColumns is the layout used unless one is configured
+var DefaultListColumns = []ListColumn{
+	{Name: ColumnCheckbox},
+	{Name: ColumnFlags},
+	{Name: ColumnFrom},
+	{Name: ColumnSubject},
+	{Name: ColumnDate},
+}
+
+// SetLayout sets the columns and density, dropping unknown and repeated columns
+func (m *MailList) SetLayout(layout ListLayout) {
+	columns := layout.Columns
+	if len(columns) == 0 {
+		columns = DefaultListColumns
+	}
+	seen := map[string]bool{}
+	m.columns = nil
+	for _, c := range columns {
+		name := strings.ToLower(strings.TrimSpace(c.Name))
+		if _, ok := columnWidths[name]; !ok || seen[name] {
+			continue
+		}
+		seen[name] = true
+		m.columns = append(m.columns, ListColumn{Name: name, Width: max(0, c.Width)})
+	}
+	if len(m.columns) == 0 {
+		m.columns = DefaultListColumns
+	}
+	m.relaxed = layout.Relaxed
+	m.avatars = layout.Avatars
+}
+
+// SetAccount sets the name shown in the account column
+func (m *MailList) SetAccount(name string) {
+	m.account = name
+}
+
+// visibleColumns returns the columns to draw with their widths: the checkbox only while
+// selecting (first, when the layout leaves it out), and subject and snippet sharing
+// what the fixed columns leave of width
+func (m MailList) visibleColumns(width int) []ListColumn {
+	columns := m.columns
+	if len(columns) == 0 {
+		columns = DefaultListColumns
+	}
+
+	var visible []ListColumn
+	hasCheckbox := false
+	for _, c := range columns {
+		if c.Name == ColumnCheckbox {
+			hasCheckbox = true
+			if !m.selectionMode {
+				continue
+			}
+		}
+		if c.Width == 0 {
+			c.Width = columnWidths[c.Name]
+			if c.Name == ColumnDate {
+				c.Width = max(c.Width, dateWidth(time.Now()))
+			}
+		}
+		visible = append(visible, c)
+	}
+	if m.selectionMode && !hasCheckbox {
+		visible = append([]ListColumn{{Name: ColumnCheckbox, Width: columnWidths[ColumnCheckbox]}}, visible...)
+	}
+
+	// Text columns are separated by two spaces
+	free, flexible, text := width, 0, 0
+	for _, c := range visible {
+		free -= c.Width
+		if c.Width == 0 {
+			flexible++
+		}
+		if !isMarkColumn(c.Name) {
+			text++
+		}
+	}
+	free -= 2 * max(0, text-1)
+	if flexible == 0 {
+		return visible
+	}
+
+	// The subject gets the larger share when the snippet is also shown
+	share := max(20, free/flexible)
+	for i, c := range visible {
+		if c.Width != 0 {
+			continue
+		}
+		visible[i].Width = share
+		if flexible == 2 {
+			if c.Name == ColumnSubject {
+				visible[i].Width = max(20, free*3/5)
+			} else {
+				visible[i].Width = max(10, free-free*3/5)
+			}
+		}
+	}
+	return visible
+}
+
+// dateWidthCache keeps the width dateWidth measured, for the language and date settings
+// in key
+var dateWidthCache struct {
+	sync.Mutex
+	key   string
+	width int
+}
+
+// dateWidth returns how wide the longest dates shown in the date column run in the
+// current language and date settings: relative times, weekdays, the clock, and days of
+// every month with and without the year
+func dateWidth(now time.Time) int {
+	evening := time.Date(2000, 1, 1, 22, 59, 0, 0, time.Local)
+	key := i18n.CurrentLanguage() + "\x00" + i18n.FormatClock(evening) + "\x00" + i18n.FormatDate(now.Add(-time.Minute))
+	dateWidthCache.Lock()
+	defer dateWidthCache.Unlock()
+	if key == dateWidthCache.key {
+		return dateWidthCache.width
+	}
+
+	samples := []time.Time{now.Add(-59 * time.Minute), now.Add(-23 * time.Hour)}
+	for days := 1; days < 7; days++ {
+		samples = append(samples, now.AddDate(0, 0, -days))
+	}
+	for m := time.January; m <= time.December; m++ {
+		samples = append(samples,
+			time.Date(now.Year(), m, 28, 12, 0, 0, 0, time.Local),
+			time.Date(now.Year()-1, m, 28, 12, 0, 0, 0, time.Local))
+	}
+	width := max(lipgloss.Width(i18n.FormatClock(evening)), lipgloss.Width(i18n.FormatClock(evening.Add(-12*time.Hour))))
+	for _, t := range samples {
+		width = max(width, lipgloss.Width(i18n.FormatDate(t)))
+	}
+	dateWidthCache.key, dateWidthCache.width = key, width
+	return width
+}
+
+// isMarkColumn reports whether a column is drawn in its own colours outside the row
+// highlight, as the checkbox and flags are
+func isMarkColumn(name string) bool {
+	return name == ColumnCheckbox || name == ColumnFlags
+}
+
+// renderCell renders the text of one column for an email, padded to width
+func (m MailList) renderCell(email mail.Email, c ListColumn) string {
+	var text string
+	align := lipgloss.Left
+	switch c.Name {
+	case ColumnAccount:
+		text = truncate(m.account, c.Width)
+	case ColumnFrom:
+		text = truncate(extractName(email.From), c.Width)
+	case ColumnSubject:
+		text = truncate(labelChips(email.Labels, c.Width/2)+email.Subject, c.Width)
+	case ColumnSnippet:
+		text = truncate(strings.Join(strings.Fields(email.Snippet), " "), c.Width)
+	case ColumnSize:
+		if size := emailSize(email); size > 0 {
+			text = formatFileSize(size)
+		}
+		align = lipgloss.Right
+	case ColumnDate:
+		text = i18n.FormatDate(email.Date)
+		align = lipgloss.Right
+	}
+	// One line per email even when a translated date runs long
+	return lipgloss.NewStyle().Width(c.Width).MaxHeight(1).Align(align).Render(text)
+}
+
+// renderFlags renders the suspicious, unread, answered or forwarded, flagged and
+// attachment marks
+func (m MailList) renderFlags(email mail.Email, width int) string {
+	var status string
+	if email.Unread {
+		status = lipgloss.NewStyle().Foreground(lipgloss.Color("#3B82F6")).Render("● ")
+	} else {
+		status = lipgloss.NewStyle().Foreground(lipgloss.Color("#6B7280")).Render("○ ")
+	}
+	if m.suspicious[email.UID] {
+		status = lipgloss.NewStyle().Foreground(lipgloss.Color("#EF4444")).Render(" ⚠") + status
+	} else {
+		status = "  " + status
+	}
+
+	// Replied ↩, forwarded ↪, both ⇄
+	replied := " "
+	switch {
+	case email.Answered && email.Forwarded:
+		replied = "⇄"
+	case email.Answered:
+		replied = "↩"
+	case email.Forwarded:
+		replied = "↪"
+	}
+	status += lipgloss.NewStyle().Foreground(lipgloss.Color("#9CA3AF")).Render(replied)
+	if email.Flagged {
+		status += lipgloss.NewStyle().Foreground(lipgloss.Color("#F97316")).Render("⚑ ")
+	} else {
+		status += "  "
+	}
+
+	attachIcon := "   "
+	if len(email.Attachments) > 0 {
+		attachIcon = lipgloss.NewStyle().Foreground(lipgloss.Color("#F59E0B")).Render("📎 ")
+	}
+	flags := status + attachIcon
+	if pad := width - lipgloss.Width(flags); pad > 0 {
+		flags += strings.Repeat(" ", pad)
+	}
+	return flags
+}
+
+// renderCheckbox renders the selection checkbox
+func (m MailList) renderCheckbox(email mail.Email, width int) string {
+	checkbox := lipgloss.NewStyle().Foreground(lipgloss.Color("#6B7280")).Render(" [ ] ")
+	if m.selections[email.UID] {
+		checkbox = lipgloss.NewStyle().Foreground(lipgloss.Color("#10B981")).Render(" [✓] ")
+	}
+	if pad := width - lipgloss.Width(checkbox); pad > 0 {
+		checkbox += strings.Repeat(" ", pad)
+	}
+	return checkbox
+}
+
+// emailSize returns an email's size as the server reported it, or for emails cached
+// before sizes were fetched, approximates it from its attachments and the cached body
+func emailSize(email mail.Email) int64 {
+	if email.Size > 0 {
+		return email.Size
+	}
+	size := int64(len(email.BodyHTML))
+	for _, a := range email.Attachments {
+		size += a.Size
+	}
+	return size
+}
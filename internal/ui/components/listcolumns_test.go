@@ -0,0 +1,43 @@
+package components
+
+import (
+	"testing"
+	"time"
+
+	"github.com/charmbracelet/lipgloss"
+
+	"maily/internal/i18n"
+)
+
+func TestDateColumnFitsEveryDate(t *testing.T) {
+	t.Cleanup(func() {
+		i18n.Init("en")
+		i18n.SetDateFormat(true, 0)
+	})
+	now := time.Now()
+	var samples []time.Time
+	for days := 0; days < 400; days += 3 {
+		samples = append(samples, now.AddDate(0, 0, -days).Add(-time.Duration(days)*time.Hour))
+	}
+
+	list := NewMailList()
+	for _, lang := range i18n.SupportedLanguages {
+		if err := i18n.Init(lang); err != nil {
+			t.Fatal(err)
+		}
+		for _, clock := range []int{12, 24} {
+			i18n.SetDateFormat(true, clock)
+			width := 0
+			for _, c := range list.visibleColumns(120) {
+				if c.Name == ColumnDate {
+					width = c.Width
+				}
+			}
+			for _, s := range samples {
+				if date := i18n.FormatDate(s); lipgloss.Width(date) > width {
+					t.Errorf("%s, %d-hour clock: %q is wider than the %d-column date column", lang, clock, date, width)
+				}
+			}
+		}
+	}
+}
@@ -1,6 +1,7 @@
 package components
 
 import (
+	"fmt"
 	"strings"
 	"time"
 
@@ -8,7 +9,9 @@ import (
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
 	"github.com/emersion/go-imap/v2"
+	"github.com/mattn/go-runewidth"
 
+	"maily/internal/i18n"
 	"maily/internal/mail"
 )
 
@@ -48,28 +51,330 @@ var DefaultMailListKeyMap = MailListKeyMap{
 	),
 }
 
+// ListGrouping controls how mailing list mail (emails with a List-Id) is arranged
+type ListGrouping int
+
+const (
+	GroupingOff       ListGrouping = iota // flat, date-ordered list
+	GroupingLists                         // list mail folded into collapsible sections, muted lists hidden
+	GroupingListsOnly                     // only list mail, muted lists included (newsletters view)
+)
+
+// ListFilter narrows the list to emails with a mark; filters combine
+type ListFilter int
+
+const (
+	FilterUnread      ListFilter = 1 << iota // unread emails
+	FilterFlagged                            // flagged (starred) emails
+	FilterAttachments                        // emails with attachments
+)
+
+// listRow is one visible line: a mailing list section header, or an email
+type listRow struct {
+	email  int    // index into emails, -1 for a section header
+	listID string // list the row belongs to, empty for ungrouped mail
+}
+
+// listSection summarizes the emails of one mailing list
+type listSection struct {
+	name   string
+	emails []int
+	unread int
+}
+
 type MailList struct {
 	emails        []mail.Email
-	cursor        int
+	rows          []listRow
+	sections      map[string]*listSection
+	cursor        int // index into rows
 	width         int
 	height        int
 	keyMap        MailListKeyMap
 	selectionMode bool
 	selections    map[imap.UID]bool
+	grouping      ListGrouping
+	expanded      map[string]bool // sections are collapsed unless expanded
+	muted         map[string]bool
+	suspicious    map[imap.UID]bool // phishing check results, filled in as emails are loaded
+	columns       []ListColumn
+	relaxed       bool      // blank line between emails
+	avatars       bool      // rows start with the sender's initials
+	account       string    // shown in the account column
+	newSince      time.Time // emails that arrived after it are marked new, zero for none
+	separator     int       // row the new mail separator is drawn above, -1 for none
+	filter        ListFilter
+	keptUnread    map[imap.UID]bool // read while the unread filter is on, listed until the filter changes
 }
 
 func NewMailList() MailList {
 	return MailList{
-		emails: []mail.Email{},
-		cursor: 0,
-		keyMap: DefaultMailListKeyMap,
+		emails:     []mail.Email{},
+		cursor:     0,
+		keyMap:     DefaultMailListKeyMap,
+		expanded:   make(map[string]bool),
+		muted:      make(map[string]bool),
+		suspicious: make(map[imap.UID]bool),
+		columns:    DefaultListColumns,
+		separator:  -1,
 	}
 }
 
 func (m *MailList) SetEmails(emails []mail.Email) {
 	m.emails = emails
-	if m.cursor >= len(emails) {
-		m.cursor = max(0, len(emails)-1)
+	m.suspicious = make(map[imap.UID]bool)
+	m.rebuild()
+}
+
+// ReplaceEmails swaps in a fresh copy of the loaded emails, keeping the cursor on the
+// email it was on
+func (m *MailList) ReplaceEmails(emails []mail.Email) {
+	m.keepCursor(func() {
+		m.emails = emails
+	})
+}
+
+// SetNewSince marks the emails that arrived after t, drawing a separator between them
+// and the older ones. The list must be sorted newest first; zero removes the separator.
+func (m *MailList) SetNewSince(t time.Time) {
+	m.newSince = t
+	m.rebuild()
+}
+
+// SetGrouping changes how mailing list mail is arranged
+func (m *MailList) SetGrouping(grouping ListGrouping) {
+	if m.grouping != grouping {
+		m.grouping = grouping
+		m.cursor = 0
+		m.rebuild()
+	}
+}
+
+func (m MailList) Grouping() ListGrouping {
+	return m.grouping
+}
+
+// SetMutedLists sets the List-Ids hidden from grouped views other than the newsletters view
+func (m *MailList) SetMutedLists(listIDs []string) {
+	m.muted = make(map[string]bool, len(listIDs))
+	for _, id := range listIDs {
+		m.muted[id] = true
+	}
+	m.rebuild()
+}
+
+// SelectedListID returns the List-Id of the section header or email under the cursor
+func (m MailList) SelectedListID() (id, name string) {
+	if m.cursor < 0 || m.cursor >= len(m.rows) {
+		return "", ""
+	}
+	row := m.rows[m.cursor]
+	if row.email < 0 {
+		return row.listID, m.sections[row.listID].name
+	}
+	return mail.ParseListID(m.emails[row.email].ListID)
+}
+
+// ToggleSection expands or collapses the section under the cursor; false if the cursor isn't on a header
+func (m *MailList) ToggleSection() bool {
+	if m.cursor < 0 || m.cursor >= len(m.rows) || m.rows[m.cursor].email >= 0 {
+		return false
+	}
+	id := m.rows[m.cursor].listID
+	m.expanded[id] = !m.expanded[id]
+	m.rebuild()
+	return true
+}
+
+// rebuild recomputes the visible rows from emails and the grouping settings
+func (m *MailList) rebuild() {
+	m.checkPhishing()
+	m.rows = nil
+	m.sections = make(map[string]*listSection)
+
+	if m.grouping == GroupingOff || m.selectionMode {
+		for i, email := range m.emails {
+			if m.matches(email) {
+				m.rows = append(m.rows, listRow{email: i})
+			}
+		}
+	} else {
+		listIDs := make([]string, len(m.emails))
+		for i, email := range m.emails {
+			id, name := mail.ParseListID(email.ListID)
+			if id == "" || (m.grouping == GroupingLists && m.muted[id]) || !m.matches(email) {
+				continue
+			}
+			listIDs[i] = id
+			section := m.sections[id]
+			if section == nil {
+				section = &listSection{name: name}
+				m.sections[id] = section
+			}
+			section.emails = append(section.emails, i)
+			if email.Unread {
+				section.unread++
+			}
+		}
+
+		// Each section appears where its newest email would have been
+		for i, email := range m.emails {
+			id := listIDs[i]
+			if id == "" {
+				listID, _ := mail.ParseListID(email.ListID)
+				if m.grouping == GroupingLists && listID == "" && m.matches(email) {
+					m.rows = append(m.rows, listRow{email: i})
+				}
+				continue
+			}
+			section := m.sections[id]
+			if section.emails[0] != i {
+				continue
+			}
+			m.rows = append(m.rows, listRow{email: -1, listID: id})
+			if m.expanded[id] {
+				for _, idx := range section.emails {
+					m.rows = append(m.rows, listRow{email: idx, listID: id})
+				}
+			}
+		}
+	}
+
+	if m.cursor >= len(m.rows) {
+		m.cursor = max(0, len(m.rows)-1)
+	}
+	m.separator = m.newMailBoundary()
+}
+
+// ToggleFilter turns a quick filter on or off, keeping the cursor on its email when the
+// email is still listed
+func (m *MailList) ToggleFilter(filter ListFilter) {
+	m.keepCursor(func() {
+		m.filter ^= filter
+		m.keptUnread = nil
+	})
+}
+
+// Filter returns the quick filters narrowing the list
+func (m MailList) Filter() ListFilter {
+	return m.filter
+}
+
+// Matching returns how many loaded emails the quick filters leave listed
+func (m MailList) Matching() int {
+	count := 0
+	for _, email := range m.emails {
+		if m.matches(email) {
+			count++
+		}
+	}
+	return count
+}
+
+// matches reports whether an email passes the quick filters. Emails read while the
+// unread filter is on stay, so reading one doesn't move the cursor off it.
+func (m MailList) matches(email mail.Email) bool {
+	switch {
+	case m.filter&FilterUnread != 0 && !email.Unread && !m.keptUnread[email.UID]:
+		return false
+	case m.filter&FilterFlagged != 0 && !email.Flagged:
+		return false
+	case m.filter&FilterAttachments != 0 && len(email.Attachments) == 0:
+		return false
+	}
+	return true
+}
+
+// newMailBoundary returns the first row that isn't new mail, or -1 when there is no new
+// mail above it. Emails inside sections are skipped; a section is as new as its newest
+// email.
+func (m MailList) newMailBoundary() int {
+	if m.newSince.IsZero() {
+		return -1
+	}
+	for i, row := range m.rows {
+		email := row.email
+		if email < 0 {
+			email = m.sections[row.listID].emails[0]
+		} else if row.listID != "" {
+			continue
+		}
+		if !arrived(m.emails[email]).After(m.newSince) {
+			if i == 0 {
+				return -1
+			}
+			return i
+		}
+	}
+	return -1
+}
+
+// arrived returns when an email reached the server, its Date header when that is unknown
+func arrived(email mail.Email) time.Time {
+	if email.InternalDate.IsZero() {
+		return email.Date
+	}
+	return email.InternalDate
+}
+
+// AppendEmails adds a page of older emails after the loaded ones, keeping the cursor in place
+func (m *MailList) AppendEmails(emails []mail.Email) {
+	m.keepCursor(func() {
+		m.emails = append(m.emails, emails...)
+	})
+}
+
+// PrependEmails adds a page of newer emails before the loaded ones, keeping the cursor in place
+func (m *MailList) PrependEmails(emails []mail.Email) {
+	m.keepCursor(func() {
+		m.emails = append(append([]mail.Email{}, emails...), m.emails...)
+	})
+}
+
+// TrimFront drops the first n loaded emails, bounding memory while paging down
+func (m *MailList) TrimFront(n int) {
+	m.keepCursor(func() {
+		m.emails = append([]mail.Email{}, m.emails[min(n, len(m.emails)):]...)
+	})
+}
+
+// TrimBack drops the last n loaded emails, bounding memory while paging up
+func (m *MailList) TrimBack(n int) {
+	m.keepCursor(func() {
+		m.emails = m.emails[:max(0, len(m.emails)-n)]
+	})
+}
+
+// NearStart reports whether the cursor is within n rows of the first row
+func (m MailList) NearStart(n int) bool {
+	return m.cursor < n
+}
+
+// NearEnd reports whether the cursor is within n rows of the last row
+func (m MailList) NearEnd(n int) bool {
+	return m.cursor >= len(m.rows)-n
+}
+
+// keepCursor applies a change to emails and moves the cursor back onto the row it was on
+func (m *MailList) keepCursor(change func()) {
+	var uid imap.UID
+	var listID string
+	header := false
+	if m.cursor >= 0 && m.cursor < len(m.rows) {
+		if row := m.rows[m.cursor]; row.email < 0 {
+			header, listID = true, row.listID
+		} else {
+			uid = m.emails[row.email].UID
+		}
+	}
+
+	change()
+	m.rebuild()
+
+	for i, row := range m.rows {
+		if (header && row.email < 0 && row.listID == listID) || (!header && row.email >= 0 && m.emails[row.email].UID == uid) {
+			m.cursor = i
+			return
+		}
 	}
 }
 
@@ -83,22 +388,19 @@ func (m *MailList) SetSize(width, height int) {
 }
 
 func (m *MailList) RemoveCurrent() {
-	if len(m.emails) == 0 || m.cursor < 0 || m.cursor >= len(m.emails) {
+	if m.cursor < 0 || m.cursor >= len(m.rows) || m.rows[m.cursor].email < 0 {
 		return
 	}
-	m.emails = append(m.emails[:m.cursor], m.emails[m.cursor+1:]...)
-	if m.cursor >= len(m.emails) && m.cursor > 0 {
-		m.cursor--
-	}
+	i := m.rows[m.cursor].email
+	m.emails = append(m.emails[:i], m.emails[i+1:]...)
+	m.rebuild()
 }
 
 func (m *MailList) RemoveByUID(uid imap.UID) {
 	for i, email := range m.emails {
 		if email.UID == uid {
 			m.emails = append(m.emails[:i], m.emails[i+1:]...)
-			if m.cursor >= len(m.emails) && m.cursor > 0 {
-				m.cursor--
-			}
+			m.rebuild()
 			return
 		}
 	}
@@ -108,6 +410,13 @@ func (m *MailList) MarkAsRead(uid imap.UID) {
 	for i := range m.emails {
 		if m.emails[i].UID == uid {
 			m.emails[i].Unread = false
+			if m.filter&FilterUnread != 0 {
+				if m.keptUnread == nil {
+					m.keptUnread = make(map[imap.UID]bool)
+				}
+				m.keptUnread[uid] = true
+			}
+			m.rebuild()
 			return
 		}
 	}
@@ -117,6 +426,29 @@ func (m *MailList) MarkAsUnread(uid imap.UID) {
 	for i := range m.emails {
 		if m.emails[i].UID == uid {
 			m.emails[i].Unread = true
+			m.rebuild()
+			return
+		}
+	}
+}
+
+// MarkAsAnswered shows an email as replied to
+func (m *MailList) MarkAsAnswered(uid imap.UID) {
+	for i := range m.emails {
+		if m.emails[i].UID == uid {
+			m.emails[i].Answered = true
+			m.rebuild()
+			return
+		}
+	}
+}
+
+// SetEmailLabels replaces the Gmail labels of an email
+func (m *MailList) SetEmailLabels(uid imap.UID, labels []string) {
+	for i := range m.emails {
+		if m.emails[i].UID == uid {
+			m.emails[i].Labels = labels
+			m.rebuild()
 			return
 		}
 	}
@@ -128,6 +460,8 @@ func (m *MailList) UpdateEmailBody(uid imap.UID, bodyHTML, snippet string) {
 		if m.emails[i].UID == uid {
 			m.emails[i].BodyHTML = bodyHTML
 			m.emails[i].Snippet = snippet
+			// The links may change the verdict
+			m.suspicious[uid] = len(mail.PhishingSignals(m.emails[i].From, bodyHTML)) > 0
 			return
 		}
 	}
@@ -140,27 +474,54 @@ func (m *MailList) ScrollUp() {
 }
 
 func (m *MailList) ScrollDown() {
-	if m.cursor < len(m.emails)-1 {
+	if m.cursor < len(m.rows)-1 {
 		m.cursor++
 	}
 }
 
+// SelectedEmail returns the email under the cursor, nil on a section header
 func (m MailList) SelectedEmail() *mail.Email {
-	if len(m.emails) == 0 || m.cursor < 0 || m.cursor >= len(m.emails) {
+	if m.cursor < 0 || m.cursor >= len(m.rows) || m.rows[m.cursor].email < 0 {
 		return nil
 	}
-	return &m.emails[m.cursor]
+	return &m.emails[m.rows[m.cursor].email]
+}
+
+// SelectUID moves the cursor onto an email, or onto the header of the collapsed section
+// holding it; false when the email isn't loaded
+func (m *MailList) SelectUID(uid imap.UID) bool {
+	header := -1
+	for i, row := range m.rows {
+		switch {
+		case row.email >= 0 && m.emails[row.email].UID == uid:
+			m.cursor = i
+			return true
+		case row.email < 0 && header < 0:
+			for _, idx := range m.sections[row.listID].emails {
+				if m.emails[idx].UID == uid {
+					header = i
+				}
+			}
+		}
+	}
+	if header < 0 {
+		return false
+	}
+	m.cursor = header
+	return true
 }
 
 func (m MailList) Cursor() int {
 	return m.cursor
 }
 
+// SetSelectionMode toggles checkboxes for bulk actions; grouping is off while it's enabled
 func (m *MailList) SetSelectionMode(enabled bool) {
 	m.selectionMode = enabled
 	if !enabled {
 		m.selections = nil
 	}
+	m.rebuild()
 }
 
 func (m *MailList) SetSelections(selections map[imap.UID]bool) {
@@ -176,7 +537,7 @@ func (m MailList) Update(msg tea.Msg) (MailList, tea.Cmd) {
 				m.cursor--
 			}
 		case key.Matches(msg, m.keyMap.Down):
-			if m.cursor < len(m.emails)-1 {
+			if m.cursor < len(m.rows)-1 {
 				m.cursor++
 			}
 		}
@@ -184,98 +545,151 @@ func (m MailList) Update(msg tea.Msg) (MailList, tea.Cmd) {
 	return m, nil
 }
 
-func (m MailList) View() string {
-	if len(m.emails) == 0 {
-		return lipgloss.NewStyle().
-			Foreground(lipgloss.Color("#6B7280")).
-			Padding(2).
-			Render("No emails to display")
-	}
-
-	var b strings.Builder
-
+// window returns the rows shown, from start up to end, keeping the cursor in view
+func (m MailList) window() (start, end int) {
 	visibleHeight := m.height - 1 // use height directly, SetSize already accounts for chrome
 	if visibleHeight < 1 {
 		visibleHeight = 10
 	}
+	// Relaxed rows take two lines each
+	if m.relaxed {
+		visibleHeight = max(1, visibleHeight/2)
+	}
 
-	start := 0
 	if m.cursor >= visibleHeight {
 		start = m.cursor - visibleHeight + 1
 	}
+	end = min(start+visibleHeight, len(m.rows))
 
-	end := start + visibleHeight
-	if end > len(m.emails) {
-		end = len(m.emails)
+	// Compact rows leave no blank line for the new mail separator; it takes a row's place
+	if !m.relaxed && start < m.separator && m.separator < end && end-start == visibleHeight {
+		if m.cursor == end-1 {
+			start++
+		} else {
+			end--
+		}
+	}
+	return start, end
+}
+
+// RowAt returns the row drawn on line y of View, or -1 for none
+func (m MailList) RowAt(y int) int {
+	start, end := m.window()
+	if !m.relaxed && start < m.separator && m.separator < end {
+		// Rows below the new mail separator are a line further down
+		switch line := m.separator - start; {
+		case y == line:
+			return -1
+		case y > line:
+			y--
+		}
+	}
+
+	lineHeight := 1
+	if m.relaxed {
+		lineHeight = 2
+	}
+	if y < 0 || y%lineHeight != 0 {
+		return -1
+	}
+	if row := start + y/lineHeight; row < end {
+		return row
+	}
+	return -1
+}
+
+// SelectRow moves the cursor onto a row
+func (m *MailList) SelectRow(row int) {
+	if row >= 0 && row < len(m.rows) {
+		m.cursor = row
+	}
+}
+
+func (m MailList) View() string {
+	if len(m.rows) == 0 {
+		empty := "No emails to display"
+		if m.filter != 0 && len(m.emails) > 0 {
+			empty = i18n.T("list.filter_empty")
+		}
+		return lipgloss.NewStyle().
+			Foreground(lipgloss.Color("#6B7280")).
+			Padding(2).
+			Render(empty)
 	}
 
+	var b strings.Builder
+
+	start, end := m.window()
 	for i := start; i < end; i++ {
-		email := m.emails[i]
-		line := m.renderEmailLine(email, i == m.cursor)
+		var line string
+		if row := m.rows[i]; row.email < 0 {
+			line = m.renderSectionLine(row.listID, i == m.cursor)
+		} else {
+			line = m.renderEmailLine(m.emails[row.email], row.listID != "", i == m.cursor)
+		}
 		b.WriteString(line)
 		if i < end-1 {
 			b.WriteString("\n")
+			if i+1 == m.separator {
+				b.WriteString(m.renderSeparator() + "\n")
+			} else if m.relaxed {
+				b.WriteString("\n")
+			}
 		}
 	}
 
 	return b.String()
 }
 
-func (m MailList) renderEmailLine(email mail.Email, isCursor bool) string {
-	dateWidth := 12
-	fromWidth := 20
-	statusWidth := 5
-	attachWidth := 3 // for 📎 icon + space
-	checkboxWidth := 0
-	rightPadding := 4
-	spacing := 4 // spaces between columns
+// renderSeparator renders the line between new mail and the mail already seen
+func (m MailList) renderSeparator() string {
+	label := " " + i18n.T("list.new_since", map[string]any{"Time": i18n.FormatDate(m.newSince)}) + " "
+	rule := max(2, m.width-lipgloss.Width(label)-6)
+	return lipgloss.NewStyle().
+		Foreground(lipgloss.Color("#F59E0B")).
+		Render("  ──" + label + strings.Repeat("─", rule))
+}
 
-	// Add checkbox width if in selection mode
-	if m.selectionMode {
-		checkboxWidth = 5
-	}
+// renderSectionLine renders a collapsible mailing list header with its email and unread counts
+func (m MailList) renderSectionLine(listID string, isCursor bool) string {
+	section := m.sections[listID]
 
-	availableWidth := m.width - statusWidth - attachWidth - checkboxWidth - fromWidth - dateWidth - spacing - rightPadding
-	if availableWidth < 20 {
-		availableWidth = 20
+	arrow := "▸"
+	if m.expanded[listID] {
+		arrow = "▾"
 	}
 
-	from := truncate(extractName(email.From), fromWidth)
-	subject := truncate(email.Subject, availableWidth)
-	date := formatDate(email.Date)
-
-	// Checkbox for selection mode
-	isSelected := m.selections[email.UID]
-	var checkbox string
-	if m.selectionMode {
-		if isSelected {
-			checkbox = lipgloss.NewStyle().Foreground(lipgloss.Color("#10B981")).Render(" [✓] ")
-		} else {
-			checkbox = lipgloss.NewStyle().Foreground(lipgloss.Color("#6B7280")).Render(" [ ] ")
-		}
+	counts := fmt.Sprintf("(%d)", len(section.emails))
+	if section.unread > 0 {
+		counts = fmt.Sprintf("(%d, %s)", len(section.emails), i18n.T("list.section_unread", map[string]any{"Count": section.unread}))
 	}
-
-	// Status indicator - show read/unread
-	var status string
-	if email.Unread {
-		status = lipgloss.NewStyle().Foreground(lipgloss.Color("#3B82F6")).Render("  ●  ")
-	} else {
-		status = lipgloss.NewStyle().Foreground(lipgloss.Color("#6B7280")).Render("  ○  ")
+	if m.muted[listID] {
+		counts += " " + i18n.T("list.section_muted")
 	}
 
-	// Attachment indicator
-	var attachIcon string
-	if len(email.Attachments) > 0 {
-		attachIcon = lipgloss.NewStyle().Foreground(lipgloss.Color("#F59E0B")).Render("📎 ")
-	} else {
-		attachIcon = "   "
+	nameWidth := max(10, m.width-lipgloss.Width(counts)-16)
+	line := "  " + arrow + "  " + truncate(section.name, nameWidth) + "  " + counts
+
+	lineStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("#A78BFA"))
+	if isCursor {
+		lineStyle = lineStyle.
+			Bold(true).
+			Foreground(lipgloss.Color("#F9FAFB")).
+			Background(lipgloss.Color("#7C3AED"))
+	} else if section.unread > 0 {
+		lineStyle = lineStyle.Bold(true)
 	}
+	return lineStyle.Render(line)
+}
 
-	fromStyle := lipgloss.NewStyle().Width(fromWidth)
-	subjectStyle := lipgloss.NewStyle().Width(availableWidth)
-	dateStyle := lipgloss.NewStyle().Width(dateWidth).Align(lipgloss.Right)
+func (m MailList) renderEmailLine(email mail.Email, inSection bool, isCursor bool) string {
+	rightPadding := 4
 
-	line := fromStyle.Render(from) + "  " + subjectStyle.Render(subject) + "  " + dateStyle.Render(date)
+	// Indent emails inside an expanded mailing list section
+	indent := ""
+	if inSection {
+		indent = "   "
+	}
 
 	lineStyle := lipgloss.NewStyle().
 		Foreground(lipgloss.Color("#F9FAFB"))
@@ -284,14 +698,71 @@ func (m MailList) renderEmailLine(email mail.Email, isCursor bool) string {
 		lineStyle = lineStyle.
 			Bold(true).
 			Background(lipgloss.Color("#7C3AED"))
-	} else if m.selectionMode && isSelected {
+	} else if m.selectionMode && m.selections[email.UID] {
 		lineStyle = lineStyle.
 			Foreground(lipgloss.Color("#10B981"))
 	} else if email.Unread {
 		lineStyle = lineStyle.Bold(true)
 	}
 
-	return checkbox + status + attachIcon + lineStyle.Render(line)
+	// Text columns are highlighted together; the avatar, checkbox and flags keep their colours
+	line := indent
+	width := m.width - len(indent) - rightPadding
+	if m.avatars {
+		line += RenderAvatar(email.From) + " "
+		width -= avatarWidth
+	}
+	var text []string
+	flush := func() {
+		if len(text) > 0 {
+			line += lineStyle.Render(strings.Join(text, "  "))
+			text = nil
+		}
+	}
+	for _, c := range m.visibleColumns(width) {
+		switch c.Name {
+		case ColumnCheckbox:
+			flush()
+			line += m.renderCheckbox(email, c.Width)
+		case ColumnFlags:
+			flush()
+			line += m.renderFlags(email, c.Width)
+		default:
+			text = append(text, m.renderCell(email, c))
+		}
+	}
+	flush()
+	return line
+}
+
+// checkPhishing looks for phishing signals in the emails not checked yet, so drawing
+// the list only reads the results
+func (m *MailList) checkPhishing() {
+	if m.suspicious == nil {
+		m.suspicious = make(map[imap.UID]bool)
+	}
+	for _, email := range m.emails {
+		if _, ok := m.suspicious[email.UID]; !ok {
+			m.suspicious[email.UID] = len(mail.PhishingSignals(email.From, email.BodyHTML)) > 0
+		}
+	}
+}
+
+// labelChips renders an email's custom Gmail labels as "[Work] [Travel] " ahead of the
+// subject, dropping labels that don't fit in maxWidth
+func labelChips(labels []string, maxWidth int) string {
+	var b strings.Builder
+	for _, l := range labels {
+		if mail.IsSystemLabel(l) {
+			continue
+		}
+		chip := "[" + l + "] "
+		if lipgloss.Width(b.String()+chip) > maxWidth {
+			break
+		}
+		b.WriteString(chip)
+	}
+	return b.String()
 }
 
 func extractName(from string) string {
@@ -301,22 +772,16 @@ func extractName(from string) string {
 	return from
 }
 
+// truncate shortens s to maxLen display columns, counting wide characters such as CJK
+// and emoji as two
 func truncate(s string, maxLen int) string {
-	if len(s) <= maxLen {
+	if runewidth.StringWidth(s) <= maxLen {
 		return s
 	}
 	if maxLen <= 3 {
-		return s[:maxLen]
-	}
-	return s[:maxLen-3] + "..."
-}
-
-func formatDate(t time.Time) string {
-	now := time.Now()
-	if t.Year() == now.Year() && t.YearDay() == now.YearDay() {
-		return "Today " + t.Format("15:04")
+		return runewidth.Truncate(s, max(0, maxLen), "")
 	}
-	return t.Format("Jan 02, 2006")
+	return runewidth.Truncate(s, maxLen, "...")
 }
 
 func max(a, b int) int {
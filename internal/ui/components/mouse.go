@@ -0,0 +1,55 @@
+package components
+
+import (
+	"strings"
+
+	"github.com/charmbracelet/x/ansi"
+	"github.com/mattn/go-runewidth"
+)
+
+// buttonPadding is how far a click may land beside a label and still hit its button,
+// the horizontal padding dialog buttons are drawn with
+const buttonPadding = 2
+
+// ScreenLine returns line y of a rendered view as plain text, without styling
+func ScreenLine(view string, y int) string {
+	lines := strings.Split(view, "\n")
+	if y < 0 || y >= len(lines) {
+		return ""
+	}
+	return ansi.Strip(lines[y])
+}
+
+// LabelAt returns the index of the label drawn across column x of a plain text line,
+// or -1 when the click missed all of them
+func LabelAt(line string, x int, labels ...string) int {
+	for i, label := range labels {
+		if label == "" {
+			continue
+		}
+		for from := 0; ; {
+			idx := strings.Index(line[from:], label)
+			if idx < 0 {
+				break
+			}
+			start := runewidth.StringWidth(line[:from+idx])
+			end := start + runewidth.StringWidth(label)
+			if x >= start-buttonPadding && x < end+buttonPadding {
+				return i
+			}
+			from += idx + len(label)
+		}
+	}
+	return -1
+}
+
+// ButtonAt is LabelAt for a row of buttons: only the line holding every one of them
+// matches, so the same words elsewhere in a dialog aren't taken for a button
+func ButtonAt(line string, x int, labels ...string) int {
+	for _, label := range labels {
+		if !strings.Contains(line, label) {
+			return -1
+		}
+	}
+	return LabelAt(line, x, labels...)
+}
@@ -0,0 +1,141 @@
+package components
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+	"maily/internal/cache"
+	"maily/internal/i18n"
+)
+
+// Operation i18n keys for the failed operations review
+var opI18nKeys = map[string]string{
+	cache.OpDelete:     "ops.delete",
+	cache.OpMoveTrash:  "ops.move_trash",
+	cache.OpMarkRead:   "ops.mark_read",
+	cache.OpMarkUnread: "ops.mark_unread",
+	cache.OpMoveSpam:   "ops.move_spam",
+	cache.OpNotSpam:    "ops.not_spam",
+	cache.OpArchive:    "ops.archive",
+}
+
+// OpsReview lists queued operations that ran out of retries so each can be retried or discarded
+type OpsReview struct {
+	ops    []cache.PendingOp
+	cursor int
+	width  int
+	height int
+}
+
+func NewOpsReview() OpsReview {
+	return OpsReview{width: 80, height: 24}
+}
+
+func (r *OpsReview) SetOps(ops []cache.PendingOp) {
+	r.ops = ops
+	r.cursor = min(r.cursor, max(0, len(ops)-1))
+}
+
+func (r *OpsReview) SetSize(width, height int) {
+	r.width = width
+	r.height = height
+}
+
+// Remove drops an operation once it was retried or discarded
+func (r *OpsReview) Remove(id int64) {
+	for i, op := range r.ops {
+		if op.ID == id {
+			r.ops = append(r.ops[:i], r.ops[i+1:]...)
+			break
+		}
+	}
+	r.cursor = min(r.cursor, max(0, len(r.ops)-1))
+}
+
+func (r *OpsReview) Up() {
+	if r.cursor > 0 {
+		r.cursor--
+	}
+}
+
+func (r *OpsReview) Down() {
+	if r.cursor < len(r.ops)-1 {
+		r.cursor++
+	}
+}
+
+// Selected returns the operation under the cursor
+func (r OpsReview) Selected() (cache.PendingOp, bool) {
+	if r.cursor < len(r.ops) {
+		return r.ops[r.cursor], true
+	}
+	return cache.PendingOp{}, false
+}
+
+func (r OpsReview) Len() int {
+	return len(r.ops)
+}
+
+func (r OpsReview) View() string {
+	var b strings.Builder
+
+	errWidth := max(30, min(70, r.width-20))
+	listHeight := max(3, (r.height-10)/2)
+	start := 0
+	if r.cursor >= listHeight {
+		start = r.cursor - listHeight + 1
+	}
+	end := min(start+listHeight, len(r.ops))
+
+	for i := start; i < end; i++ {
+		op := r.ops[i]
+		name := op.Operation
+		if key, ok := opI18nKeys[op.Operation]; ok {
+			name = i18n.T(key)
+		}
+		line := fmt.Sprintf("%s  %s #%d  %s", name, GetLabelDisplayName(op.Mailbox), op.UID,
+			i18n.T("ops.attempts", map[string]any{"Count": op.Retries}))
+
+		style := lipgloss.NewStyle().Padding(0, 2).Foreground(Text)
+		if i == r.cursor {
+			style = style.Bold(true).Background(Primary)
+		}
+		b.WriteString(style.Render(line))
+		b.WriteString("\n")
+		b.WriteString(lipgloss.NewStyle().Padding(0, 4).Foreground(Danger).Render(truncate(op.LastError, errWidth)))
+		if i < end-1 {
+			b.WriteString("\n")
+		}
+	}
+
+	titleStyle := lipgloss.NewStyle().
+		Bold(true).
+		Foreground(Danger).
+		MarginBottom(1)
+
+	hintStyle := lipgloss.NewStyle().
+		Foreground(Muted).
+		MarginTop(1)
+
+	content := lipgloss.JoinVertical(
+		lipgloss.Left,
+		titleStyle.Render(i18n.T("ops.title")),
+		"",
+		b.String(),
+		"",
+		hintStyle.Render("↑/↓ "+i18n.T("help.navigate")+" • r "+i18n.T("help.retry")+" • x "+i18n.T("help.discard")+" • esc "+i18n.T("help.close")),
+	)
+
+	return lipgloss.Place(
+		r.width,
+		r.height-4,
+		lipgloss.Center,
+		lipgloss.Center,
+		lipgloss.NewStyle().
+			Border(lipgloss.RoundedBorder()).
+			BorderForeground(Danger).
+			Padding(1, 3).
+			Render(content),
+	)
+}
@@ -0,0 +1,25 @@
+package components
+
+import (
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+
+	"maily/internal/i18n"
+)
+
+// RenderPhishingBanner renders the warning shown above the body of a suspicious email,
+// listing what looked wrong
+func RenderPhishingBanner(signals []string, width int) string {
+	lines := []string{lipgloss.NewStyle().Bold(true).Render("⚠ " + i18n.T("phishing.banner"))}
+	for _, s := range signals {
+		lines = append(lines, "  • "+s)
+	}
+	return lipgloss.NewStyle().
+		Foreground(Danger).
+		Border(lipgloss.NormalBorder(), false, false, false, true).
+		BorderForeground(Danger).
+		PaddingLeft(1).
+		Width(width).
+		Render(strings.Join(lines, "\n"))
+}
@@ -0,0 +1,51 @@
+package components
+
+import (
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/x/ansi"
+
+	"maily/internal/i18n"
+)
+
+// PreviewData is the highlighted email shown in the preview pane
+type PreviewData struct {
+	From    string
+	Subject string
+	Date    time.Time
+	Body    string // rendered body, already cut to the lines to show
+}
+
+// RenderPreview renders the preview pane at exactly width by height, border included;
+// a nil email leaves it empty
+func RenderPreview(email *PreviewData, width, height int) string {
+	style := PreviewStyle.Margin(0).Padding(0, 1)
+	inner := max(1, width-style.GetHorizontalFrameSize())
+	rows := max(1, height-style.GetVerticalFrameSize())
+
+	var lines []string
+	if email == nil {
+		lines = []string{SnippetStyle.Render(i18n.T("preview.empty"))}
+	} else {
+		lines = []string{
+			FromStyle.Render(i18n.T("today.from")) + email.From,
+			SubjectStyle.Render(i18n.T("today.subject")) + email.Subject,
+			DateStyle.Render(i18n.FormatDateTime(email.Date.Local())),
+			DateStyle.Render(strings.Repeat("─", inner)),
+		}
+		lines = append(lines, strings.Split(email.Body, "\n")...)
+	}
+
+	if len(lines) > rows {
+		lines = lines[:rows]
+	}
+	for i, line := range lines {
+		lines[i] = ansi.Truncate(line, inner, "")
+	}
+	return style.
+		Width(inner + style.GetHorizontalPadding()).
+		Height(rows).
+		MaxHeight(height).
+		Render(strings.Join(lines, "\n"))
+}
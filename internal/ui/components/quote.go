@@ -0,0 +1,133 @@
+package components
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// minQuotedLines is the shortest quoted block worth folding
+const minQuotedLines = 3
+
+var (
+	// Reply attributions such as "On Mon, Jan 2, 2006 at 3:04 PM Bob <bob@example.com> wrote:",
+	// in the languages maily writes them in
+	attributionEnd   = regexp.MustCompile(`(?i)(wrote|writes|schrieb|a écrit|escribió|ha scritto|schreef|napisał(\(a\))?|escreveu|написал(а|\(а\))?|書きました|작성|写道|寫道)\s*[:：]\s*$`)
+	attributionStart = regexp.MustCompile(`^(On|Am|Le|El|Il|Op|W dniu|Em) `)
+	// German and Dutch put the verb before the sender: "Am ... schrieb Bob <bob@example.com>:"
+	attributionVerbFirst = regexp.MustCompile(`^(Am|Op) .+ (schrieb|schreef) .+:\s*$`)
+	// Outlook puts the whole previous message below this line, unquoted
+	originalMessage = regexp.MustCompile(`(?i)^-{2,}\s*original message\s*-{2,}$`)
+)
+
+// FoldQuoted collapses the quoted history in a markdown email body: runs of "> " lines
+// with the attribution line before them, and everything after an Outlook
+// "Original Message" separator. When expand is true the quotes are kept and marked as
+// foldable instead. Returns the body and the number of quoted lines found.
+func FoldQuoted(markdown string, expand bool) (string, int) {
+	lines := strings.Split(markdown, "\n")
+	var out []string
+	total := 0
+	inFence := false
+
+	emit := func(block []string) {
+		n := 0
+		for _, l := range block {
+			if strings.TrimSpace(strings.TrimLeft(strings.TrimSpace(l), "> ")) != "" {
+				n++
+			}
+		}
+		if n < minQuotedLines {
+			out = append(out, block...)
+			return
+		}
+		total += n
+		if expand {
+			out = append(out, foldMarker(fmt.Sprintf("[-] hide quoted text (%d lines, z)", n), inFence), "")
+			out = append(out, block...)
+			return
+		}
+		out = append(out, foldMarker(fmt.Sprintf("[+] show quoted text (%d lines, z)", n), inFence))
+	}
+
+	for i := 0; i < len(lines); i++ {
+		line := lines[i]
+		trimmed := strings.TrimSpace(line)
+		if strings.HasPrefix(trimmed, "```") {
+			inFence = !inFence
+			out = append(out, line)
+			continue
+		}
+
+		if originalMessage.MatchString(trimmed) {
+			end := i
+			for end < len(lines) && !(inFence && strings.HasPrefix(strings.TrimSpace(lines[end]), "```")) {
+				end++
+			}
+			emit(lines[i:end])
+			i = end - 1
+			continue
+		}
+
+		if !isQuoted(line) && !isAttribution(lines, i) {
+			out = append(out, line)
+			continue
+		}
+
+		// The attribution, possibly wrapped over two lines, then the quoted lines with
+		// any blank lines between them
+		start := i
+		for nonBlank := 0; i < len(lines) && !isQuoted(lines[i]); i++ {
+			if strings.TrimSpace(lines[i]) != "" {
+				if nonBlank++; nonBlank > 2 {
+					break
+				}
+			}
+		}
+		if i == len(lines) || !isQuoted(lines[i]) {
+			out = append(out, lines[start:i]...)
+			i--
+			continue
+		}
+		end := i
+		for j := i; j < len(lines); j++ {
+			if isQuoted(lines[j]) {
+				end = j + 1
+			} else if strings.TrimSpace(lines[j]) != "" {
+				break
+			}
+		}
+		emit(lines[start:end])
+		i = end - 1
+	}
+	return strings.Join(out, "\n"), total
+}
+
+// isAttribution reports whether line i introduces a quote, either on its own or as the
+// first half of a wrapped attribution
+func isAttribution(lines []string, i int) bool {
+	line := strings.TrimSpace(lines[i])
+	if attributionEnd.MatchString(line) || attributionVerbFirst.MatchString(line) {
+		return true
+	}
+	return attributionStart.MatchString(line) && i+1 < len(lines) &&
+		attributionEnd.MatchString(strings.TrimSpace(lines[i+1]))
+}
+
+// IsAttribution reports whether a line introduces a quote, like "On ... Bob wrote:"
+func IsAttribution(line string) bool {
+	line = strings.TrimSpace(line)
+	return attributionEnd.MatchString(line) || attributionVerbFirst.MatchString(line)
+}
+
+func isQuoted(line string) bool {
+	return strings.HasPrefix(strings.TrimSpace(line), ">")
+}
+
+// foldMarker renders the fold toggle line, emphasized unless inside a code block
+func foldMarker(text string, inFence bool) string {
+	if inFence {
+		return text
+	}
+	return "*" + text + "*"
+}
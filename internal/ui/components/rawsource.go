@@ -0,0 +1,81 @@
+package components
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"unicode"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+// Runs of encoded attachment data longer than this are collapsed in the source view
+const maxEncodedLines = 4
+
+var (
+	headerLineRegex = regexp.MustCompile(`^[!-9;-~]+:`)
+	boundaryRegex   = regexp.MustCompile(`(?i)boundary\s*=\s*"?([^";\s]+)"?`)
+	encodedRegex    = regexp.MustCompile(`^[A-Za-z0-9+/=]{60,}$`)
+)
+
+// RenderRawSource renders a message's RFC 822 source with its headers, the headers of
+// each MIME part and the part boundaries highlighted. Long runs of base64 data are
+// collapsed; the source itself is unchanged.
+func RenderRawSource(raw []byte) string {
+	nameStyle := lipgloss.NewStyle().Foreground(Secondary).Bold(true)
+	valueStyle := lipgloss.NewStyle().Foreground(Text)
+	boundaryStyle := lipgloss.NewStyle().Foreground(Warning).Bold(true)
+	mutedStyle := lipgloss.NewStyle().Foreground(Muted).Italic(true)
+
+	source := strings.ReplaceAll(string(raw), "\r\n", "\n")
+	boundaries := map[string]bool{}
+	for _, m := range boundaryRegex.FindAllStringSubmatch(source, -1) {
+		boundaries["--"+m[1]] = true
+		boundaries["--"+m[1]+"--"] = true
+	}
+
+	lines := strings.Split(strings.TrimRight(source, "\n"), "\n")
+	var out []string
+	inHeaders := true
+	for i := 0; i < len(lines); i++ {
+		line := stripControlChars(lines[i])
+		switch {
+		case boundaries[strings.TrimRight(line, " \t")]:
+			out = append(out, boundaryStyle.Render(line))
+			inHeaders = !strings.HasSuffix(strings.TrimRight(line, " \t"), "--")
+		case inHeaders && line == "":
+			out = append(out, "")
+			inHeaders = false
+		case inHeaders && headerLineRegex.MatchString(line):
+			name, value, _ := strings.Cut(line, ":")
+			out = append(out, nameStyle.Render(name+":")+valueStyle.Render(value))
+		case inHeaders:
+			out = append(out, valueStyle.Render(line)) // folded header continuation
+		case encodedRegex.MatchString(line):
+			end := i
+			for end < len(lines) && encodedRegex.MatchString(lines[end]) {
+				end++
+			}
+			if end-i > maxEncodedLines {
+				out = append(out, lines[i:i+maxEncodedLines-1]...)
+				out = append(out, mutedStyle.Render(fmt.Sprintf("[... %d more lines of encoded data ...]", end-i-maxEncodedLines+1)))
+			} else {
+				out = append(out, lines[i:end]...)
+			}
+			i = end - 1
+		default:
+			out = append(out, line)
+		}
+	}
+	return strings.Join(out, "\n")
+}
+
+// stripControlChars removes control characters such as escape sequences, keeping tabs
+func stripControlChars(s string) string {
+	return strings.Map(func(r rune) rune {
+		if r != '\t' && unicode.IsControl(r) {
+			return -1
+		}
+		return r
+	}, s)
+}
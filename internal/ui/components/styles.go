@@ -18,6 +18,24 @@ var (
 	BgDark    = lipgloss.Color("#111827")
 )
 
+// accountPalette assigns accent colors to accounts without a configured color
+var accountPalette = []lipgloss.Color{
+	lipgloss.Color("#7C3AED"),
+	lipgloss.Color("#0EA5E9"),
+	lipgloss.Color("#10B981"),
+	lipgloss.Color("#F59E0B"),
+	lipgloss.Color("#EC4899"),
+	lipgloss.Color("#14B8A6"),
+}
+
+// AccountColor returns the configured accent color, or a palette color by account index
+func AccountColor(color string, idx int) lipgloss.Color {
+	if color != "" {
+		return lipgloss.Color(color)
+	}
+	return accountPalette[idx%len(accountPalette)]
+}
+
 // Base styles
 var (
 	BaseStyle = lipgloss.NewStyle().
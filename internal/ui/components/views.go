@@ -1,12 +1,17 @@
 package components
 
 import (
+	"errors"
 	"fmt"
+	"strconv"
 	"strings"
 	"time"
+	"unicode/utf8"
 
 	"github.com/charmbracelet/lipgloss"
+	"github.com/mattn/go-runewidth"
 	"maily/internal/i18n"
+	"maily/internal/mail"
 )
 
 
@@ -14,11 +19,19 @@ import (
 
 type HeaderData struct {
 	Width          int
-	Accounts       []string
+	Accounts       []AccountTab
 	ActiveIdx      int
 	IsSearchResult bool
 	SearchQuery    string
 	CurrentLabel   string
+	Profile        string // active profile names, empty when all accounts are shown
+}
+
+// AccountTab is an account shown in the header switcher
+type AccountTab struct {
+	Name   string
+	Color  lipgloss.Color
+	Unread int // unread emails in the inbox
 }
 
 type StatusBarData struct {
@@ -28,8 +41,34 @@ type StatusBarData struct {
 	IsSearchResult bool
 	IsListView     bool
 	IsComposeView    bool
+	CanMinimize    bool      // esc sets the email being composed aside
+	SetAside       []string  // titles of the emails set aside, C resumes them
 	AccountCount   int
 	SelectionCount int
+	HasProfiles    bool      // account profiles are configured
+	Folder         string    // special folder being viewed (mail.FolderSent, FolderTrash, FolderSpam), "" for others
+	CanUnsubscribe bool      // open email has a List-Unsubscribe header
+	CanEditLabels  bool      // Gmail account, L edits the open email's labels
+	OnMailingList  bool      // cursor is on mailing list mail, M mutes the list
+	FindBar        string    // rendered find-in-email or go to date bar, replaces the help line
+	Syncing        bool      // server is syncing the active account
+	SyncSpinner    string    // rendered spinner frame shown while syncing
+	SyncPhase      string    // phase of the running sync (server.SyncPhase*), empty until it reports one
+	SyncFetched    int       // message headers the running sync has fetched
+	SyncTotal      int       // message headers it expects to fetch, 0 while unknown
+	LastSync       time.Time // last successful sync reported by the server
+	SyncError      string    // last sync error, empty if none
+	FailedOps      int       // queued operations that ran out of retries, reviewed with o
+	Jobs           JobStatus // server operations running in the background
+}
+
+// JobStatus is what the status bar shows about server operations run in the background
+type JobStatus struct {
+	Running int    // jobs in flight
+	Label   string // message id of what the last started one does
+	Done    bool   // the last jobs all succeeded
+	Failed  string // message id of the job that failed, empty if none did
+	Error   string
 }
 
 type AttachmentInfo struct {
@@ -38,12 +77,35 @@ type AttachmentInfo struct {
 	Size        int64
 }
 
+// StatsInfo is the per-account data shown in the sync stats panel
+type StatsInfo struct {
+	Account      string
+	LastSync     time.Time
+	LastDuration time.Duration
+	LastFetched  int
+	TotalSyncs   int
+	FailedSyncs  int
+	PendingOps   int
+	LastError    string
+}
+
+// StorageInfo is the per-account data shown in the storage report
+type StorageInfo struct {
+	Account    string
+	Quota      *mail.StorageQuota // nil when the server doesn't report usage
+	Trash      string
+	TrashCount int
+	Error      string
+}
+
 type EmailViewData struct {
 	From        string
 	To          string
 	Subject     string
 	Date        time.Time
 	Attachments []AttachmentInfo
+	Auth        *mail.AuthResults // nil until the headers are fetched
+	Avatar      bool              // the sender's initials ahead of the From line
 }
 
 // Render functions
@@ -57,24 +119,23 @@ func RenderHeader(data HeaderData) string {
 			Foreground(Text).
 			Background(Warning).
 			Padding(0, 1).
-			Render(fmt.Sprintf(" Search: %s ", data.SearchQuery))
+			Render(" " + i18n.T("search.badge", map[string]any{"Query": data.SearchQuery}) + " ")
 		return HeaderStyle.Width(data.Width).Render(title + " " + searchBadge)
 	}
 
 	var tabs []string
-	activeTabStyle := lipgloss.NewStyle().
-		Foreground(Text).
-		Background(Primary).
-		Padding(0, 1)
-	inactiveTabStyle := lipgloss.NewStyle().
-		Foreground(TextDim).
-		Padding(0, 1)
-
-	for i, email := range data.Accounts {
+	for i, acc := range data.Accounts {
+		name := acc.Name
+		if acc.Unread > 0 {
+			name += fmt.Sprintf(" (%d)", acc.Unread)
+		}
 		if i == data.ActiveIdx {
-			tabs = append(tabs, activeTabStyle.Render(email))
+			tabs = append(tabs, RenderAccountBadge(name, acc.Color))
 		} else {
-			tabs = append(tabs, inactiveTabStyle.Render(email))
+			tabs = append(tabs, lipgloss.NewStyle().
+				Foreground(acc.Color).
+				Padding(0, 1).
+				Render(name))
 		}
 	}
 
@@ -91,7 +152,15 @@ func RenderHeader(data HeaderData) string {
 			Render(labelName)
 	}
 
-	return HeaderStyle.Width(data.Width).Render(title + " " + tabsStr + labelBadge)
+	profileBadge := ""
+	if data.Profile != "" {
+		profileBadge = " " + lipgloss.NewStyle().
+			Foreground(TextDim).
+			Italic(true).
+			Render("["+data.Profile+"]")
+	}
+
+	return HeaderStyle.Width(data.Width).Render(title + profileBadge + " " + tabsStr + labelBadge)
 }
 
 func RenderStatusBar(data StatusBarData) string {
@@ -106,6 +175,11 @@ func RenderStatusBar(data StatusBarData) string {
 			HelpKeyStyle.Render("esc") + HelpDescStyle.Render(" "+i18n.T("help.cancel"))
 	} else if data.IsComposeView {
 		help = HelpKeyStyle.Render("Tab") + HelpDescStyle.Render(" "+i18n.T("help.next_field"))
+		if data.CanMinimize {
+			help += "  " + HelpKeyStyle.Render("esc") + HelpDescStyle.Render(" "+i18n.T("help.minimize"))
+		}
+	} else if data.FindBar != "" {
+		help = data.FindBar
 	} else if data.IsSearchResult {
 		help = HelpKeyStyle.Render("space") + HelpDescStyle.Render(" "+i18n.T("help.select")+"  ") +
 			HelpKeyStyle.Render("a") + HelpDescStyle.Render(" "+i18n.T("help.select_all")+"  ") +
@@ -124,15 +198,39 @@ func RenderStatusBar(data StatusBarData) string {
 		row2 := HelpKeyStyle.Render("d") + HelpDescStyle.Render(" "+i18n.T("help.delete")+"  ") +
 			HelpKeyStyle.Render("l") + HelpDescStyle.Render(" "+i18n.T("help.load_more")+"  ") +
 			HelpKeyStyle.Render("f") + HelpDescStyle.Render(" "+i18n.T("help.folders")+"  ") +
+			HelpKeyStyle.Render("u/*/p") + HelpDescStyle.Render(" "+i18n.T("help.filter")+"  ") +
+			HelpKeyStyle.Render("S") + HelpDescStyle.Render(" "+i18n.T("help.stats")+"  ") +
+			HelpKeyStyle.Render("v") + HelpDescStyle.Render(" "+i18n.T("help.preview")+"  ") +
 			HelpKeyStyle.Render("/") + HelpDescStyle.Render(" "+i18n.T("help.commands"))
+		row2 += "  " + folderHint(data.Folder, true)
+		if data.OnMailingList {
+			row2 += "  " + HelpKeyStyle.Render("M") + HelpDescStyle.Render(" "+i18n.T("help.mute_list"))
+		}
+		if data.HasProfiles {
+			row2 += "  " + HelpKeyStyle.Render("P") + HelpDescStyle.Render(" "+i18n.T("help.profile"))
+		}
 		help = row1 + "\n" + row2
 	} else {
 		// Read view
+		unsubscribeHint := ""
+		if data.CanUnsubscribe {
+			unsubscribeHint = HelpKeyStyle.Render("U") + HelpDescStyle.Render(" "+i18n.T("help.unsubscribe")+"  ")
+		}
+		labelsHint := ""
+		if data.CanEditLabels {
+			labelsHint = HelpKeyStyle.Render("L") + HelpDescStyle.Render(" "+i18n.T("help.labels")+"  ")
+		}
 		help = tabHint +
 			HelpKeyStyle.Render("r") + HelpDescStyle.Render(" "+i18n.T("help.reply")+"  ") +
+			HelpKeyStyle.Render("Q") + HelpDescStyle.Render(" "+i18n.T("help.quick_reply")+"  ") +
 			HelpKeyStyle.Render("u") + HelpDescStyle.Render(" "+i18n.T("help.mark_read")+"  ") +
 			HelpKeyStyle.Render("d") + HelpDescStyle.Render(" "+i18n.T("help.delete")+"  ") +
+			folderHint(data.Folder, false) + "  " +
+			unsubscribeHint +
+			labelsHint +
+			HelpKeyStyle.Render("/") + HelpDescStyle.Render(" "+i18n.T("help.find")+"  ") +
 			HelpKeyStyle.Render("a") + HelpDescStyle.Render(" "+i18n.T("help.attachments")+"  ") +
+			HelpKeyStyle.Render("p") + HelpDescStyle.Render(" "+i18n.T("help.print")+"  ") +
 			HelpKeyStyle.Render("s") + HelpDescStyle.Render(" "+i18n.T("help.summarize")+"  ") +
 			HelpKeyStyle.Render("e") + HelpDescStyle.Render(" "+i18n.T("help.extract")+"  ") +
 			HelpKeyStyle.Render("esc") + HelpDescStyle.Render(" "+i18n.T("help.back")+"  ") +
@@ -150,13 +248,134 @@ func RenderStatusBar(data StatusBarData) string {
 			Render(" " + i18n.TPlural("email.selected", data.SelectionCount, map[string]any{"Count": data.SelectionCount}) + " ")
 	}
 
-	gap := max(0, data.Width-lipgloss.Width(help)-lipgloss.Width(status)-lipgloss.Width(selectionInfo)-12)
+	syncInfo := renderDraftChip(data.SetAside) + RenderJobStatus(data.Jobs, data.SyncSpinner) + renderFailedOpsNotice(data.FailedOps) + renderSyncIndicator(data)
+
+	gap := max(0, data.Width-lipgloss.Width(help)-lipgloss.Width(status)-lipgloss.Width(selectionInfo)-lipgloss.Width(syncInfo)-12)
 
 	return StatusBarStyle.Width(data.Width).PaddingLeft(4).PaddingRight(4).MarginTop(1).Render(
-		help + strings.Repeat(" ", gap) + selectionInfo + status,
+		help + strings.Repeat(" ", gap) + selectionInfo + syncInfo + status,
 	)
 }
 
+// renderDraftChip shows the emails set aside and the key that resumes them
+func renderDraftChip(titles []string) string {
+	var chip string
+	switch len(titles) {
+	case 0:
+		return ""
+	case 1:
+		chip = i18n.T("compose.draft_chip", map[string]any{"Subject": truncate(titles[0], 30)})
+	default:
+		chip = i18n.T("compose.drafts_chip", map[string]any{"Count": len(titles)})
+	}
+	return lipgloss.NewStyle().
+		Foreground(Text).
+		Background(Primary).
+		Padding(0, 1).
+		Render(chip) + "  "
+}
+
+// folderHint renders the keys for the current folder's actions: restoring and emptying
+// the trash, resending and forwarding sent mail, or reporting spam and moving it back
+func folderHint(folder string, listView bool) string {
+	switch folder {
+	case mail.FolderTrash:
+		hint := HelpKeyStyle.Render("T") + HelpDescStyle.Render(" "+i18n.T("help.restore"))
+		if listView {
+			hint += "  " + HelpKeyStyle.Render("X") + HelpDescStyle.Render(" "+i18n.T("help.empty_trash"))
+		}
+		return hint
+	case mail.FolderSent:
+		if listView {
+			return ""
+		}
+		return HelpKeyStyle.Render("E") + HelpDescStyle.Render(" "+i18n.T("help.resend")+"  ") +
+			HelpKeyStyle.Render("F") + HelpDescStyle.Render(" "+i18n.T("help.forward"))
+	case mail.FolderSpam:
+		return HelpKeyStyle.Render("J") + HelpDescStyle.Render(" "+i18n.T("help.not_spam"))
+	}
+	return HelpKeyStyle.Render("J") + HelpDescStyle.Render(" "+i18n.T("help.spam"))
+}
+
+// renderSyncIndicator renders the server sync state: spinner, error badge, or last sync time
+func renderSyncIndicator(data StatusBarData) string {
+	switch {
+	case data.Syncing && data.SyncTotal > 0 && data.SyncFetched < data.SyncTotal:
+		// A bar rather than the bare spinner while a large sync fetches headers
+		const barWidth = 12
+		percent := data.SyncFetched * 100 / data.SyncTotal
+		filled := percent * barWidth / 100
+		bar := lipgloss.NewStyle().Foreground(Primary).Render(strings.Repeat("█", filled)) +
+			lipgloss.NewStyle().Foreground(Muted).Render(strings.Repeat("░", barWidth-filled))
+		counts := i18n.T("status.syncing_count", map[string]any{"Fetched": data.SyncFetched, "Total": data.SyncTotal})
+		return data.SyncSpinner + HelpDescStyle.Render(" "+counts+" ") + bar + HelpDescStyle.Render(fmt.Sprintf(" %d%%  ", percent))
+	case data.Syncing && data.SyncPhase != "":
+		return data.SyncSpinner + HelpDescStyle.Render(" "+i18n.T("status.sync_phase."+data.SyncPhase)+"  ")
+	case data.Syncing:
+		return data.SyncSpinner + HelpDescStyle.Render(" "+i18n.T("status.syncing")+"  ")
+	case data.SyncError != "" && mail.IsThrottled(errors.New(data.SyncError)):
+		badge := lipgloss.NewStyle().
+			Foreground(Text).
+			Background(Warning).
+			Padding(0, 1).
+			Render(i18n.T("status.throttled"))
+		return badge + " " + HelpKeyStyle.Render("!") + HelpDescStyle.Render(" "+i18n.T("help.details")+"  ")
+	case data.SyncError != "":
+		badge := lipgloss.NewStyle().
+			Foreground(Text).
+			Background(Danger).
+			Padding(0, 1).
+			Render(i18n.T("status.sync_failed"))
+		return badge + " " + HelpKeyStyle.Render("!") + HelpDescStyle.Render(" "+i18n.T("help.details")+"  ")
+	case !data.LastSync.IsZero():
+		return HelpDescStyle.Render(i18n.T("status.last_sync", map[string]any{"Time": data.LastSync.Local().Format("15:04")}) + "  ")
+	}
+	return ""
+}
+
+// RenderJobStatus renders the background jobs: a spinner with what runs, a check mark
+// once they succeeded, or the failure with the key to retry it
+func RenderJobStatus(jobs JobStatus, spinner string) string {
+	switch {
+	case jobs.Failed != "":
+		badge := lipgloss.NewStyle().
+			Foreground(Text).
+			Background(Danger).
+			Padding(0, 1).
+			Render(i18n.T("job.failed", map[string]any{"Action": i18n.T(jobs.Failed), "Error": truncate(jobs.Error, 40)}))
+		return badge + " " + HelpKeyStyle.Render("ctrl+r") + HelpDescStyle.Render(" "+i18n.T("help.retry")+"  ")
+	case jobs.Running > 1:
+		return spinner + HelpDescStyle.Render(" "+i18n.TPlural("job.running", jobs.Running, nil)+"  ")
+	case jobs.Running == 1:
+		return spinner + HelpDescStyle.Render(" "+i18n.T(jobs.Label)+"...  ")
+	case jobs.Done:
+		return lipgloss.NewStyle().Foreground(Success).Render("✓") + "  "
+	}
+	return ""
+}
+
+// renderFailedOpsNotice points to the review screen when queued operations ran out of retries
+func renderFailedOpsNotice(count int) string {
+	if count == 0 {
+		return ""
+	}
+	badge := lipgloss.NewStyle().
+		Foreground(Text).
+		Background(Danger).
+		Padding(0, 1).
+		Render(i18n.TPlural("status.ops_failed", count, map[string]any{"Count": count}))
+	return badge + " " + HelpKeyStyle.Render("o") + HelpDescStyle.Render(" "+i18n.T("help.review")+"  ")
+}
+
+// RenderAccountBadge renders an account name on its accent color
+func RenderAccountBadge(name string, color lipgloss.Color) string {
+	return lipgloss.NewStyle().
+		Foreground(Text).
+		Background(color).
+		Padding(0, 1).
+		Render(name)
+}
+
 func RenderListView(width, height int, listContent string) string {
 	// Don't set fixed Height - let content determine height
 	// The mailList already limits visible rows based on its height
@@ -179,28 +398,42 @@ func formatFileSize(size int64) string {
 }
 
 func RenderReadView(email EmailViewData, width int, viewportContent string) string {
+	from := FromStyle.Render(i18n.T("today.from")) + email.From
+	if email.Avatar {
+		from = RenderAvatar(email.From) + " " + from
+	}
 	headerLines := []string{
-		FromStyle.Render("From: ") + email.From,
-		"To: " + email.To,
-		SubjectStyle.Render("Subject: ") + email.Subject,
-		DateStyle.Render(email.Date.Format("Mon, 02 Jan 2006 15:04:05")),
+		from,
+		i18n.T("today.to") + email.To,
+		SubjectStyle.Render(i18n.T("today.subject")) + email.Subject,
+		DateStyle.Render(i18n.FormatDateTime(email.Date)),
 	}
 
-	// Add attachments line if there are any
+	// Sender authentication badges share the date line
+	if badges := RenderAuthBadges(email.Auth); badges != "" {
+		headerLines[3] = lipgloss.NewStyle().MaxWidth(width-8).Render(headerLines[3] + "  " + badges)
+	}
+
+	// Add attachments strip if there are any; 1-9 download one, S saves all
 	if len(email.Attachments) > 0 {
 		attachStyle := lipgloss.NewStyle().Foreground(Secondary).Bold(true)
 		fileStyle := lipgloss.NewStyle().Foreground(Text)
 		sizeStyle := lipgloss.NewStyle().Foreground(Muted)
 
 		var attachParts []string
-		for _, att := range email.Attachments {
-			attachParts = append(attachParts, fmt.Sprintf("%s %s",
+		for i, att := range email.Attachments {
+			part := fmt.Sprintf("%s %s",
 				fileStyle.Render(att.Filename),
-				sizeStyle.Render("("+formatFileSize(att.Size)+")")))
+				sizeStyle.Render("("+formatFileSize(att.Size)+")"))
+			if i < 9 {
+				part = HelpKeyStyle.Render(fmt.Sprintf("[%d]", i+1)) + " " + part
+			}
+			attachParts = append(attachParts, part)
 		}
 
-		attachLine := attachStyle.Render("📎 Attachments: ") + strings.Join(attachParts, ", ")
-		headerLines = append(headerLines, attachLine)
+		attachLine := attachStyle.Render("📎 ") + strings.Join(attachParts, "  ") +
+			"  " + HelpKeyStyle.Render("S") + HelpDescStyle.Render(" "+i18n.T("attachment.save_all"))
+		headerLines = append(headerLines, lipgloss.NewStyle().MaxWidth(width-8).Render(attachLine))
 	}
 
 	headerLines = append(headerLines, strings.Repeat("─", width-12))
@@ -291,6 +524,71 @@ func RenderConfirmDialog(count int, selected DeleteOption) string {
 	)
 }
 
+// RenderSyncErrorDialog renders the details of the last sync error for an account
+func RenderSyncErrorDialog(account, errText string) string {
+	dialogStyle := DialogStyle.BorderForeground(Danger)
+
+	title := DialogTitleStyle.
+		Foreground(Danger).
+		Render(i18n.T("dialog.sync_error.title"))
+
+	accountLine := lipgloss.NewStyle().
+		Foreground(Text).
+		Bold(true).
+		Render(account)
+
+	message := lipgloss.NewStyle().
+		Foreground(TextDim).
+		Width(50).
+		Render(errText)
+
+	hintText := i18n.T("dialog.sync_error.hint")
+	if mail.IsAuthError(errors.New(errText)) {
+		hintText = "r " + i18n.T("help.reauth") + " • " + hintText
+	}
+	hint := DialogHintStyle.Render(hintText)
+
+	return dialogStyle.Render(
+		lipgloss.JoinVertical(
+			lipgloss.Center,
+			title,
+			accountLine,
+			"",
+			message,
+			"",
+			hint,
+		),
+	)
+}
+
+// RenderEmptyTrashDialog asks to confirm permanently deleting everything in the trash
+func RenderEmptyTrashDialog() string {
+	dialogStyle := DialogStyle.BorderForeground(Danger)
+
+	title := DialogTitleStyle.
+		Foreground(Danger).
+		Render(i18n.T("dialog.empty_trash.title"))
+
+	message := lipgloss.NewStyle().
+		Foreground(TextDim).
+		Width(40).
+		Align(lipgloss.Center).
+		Render(i18n.T("dialog.empty_trash.message"))
+
+	hint := DialogHintStyle.Render(i18n.T("dialog.empty_trash.hint"))
+
+	return dialogStyle.Render(
+		lipgloss.JoinVertical(
+			lipgloss.Center,
+			title,
+			"",
+			message,
+			"",
+			hint,
+		),
+	)
+}
+
 // RenderAISetupDialog renders a dialog asking user if they want to configure AI
 func RenderAISetupDialog() string {
 	dialogStyle := DialogStyle.BorderForeground(Primary)
@@ -357,26 +655,29 @@ func RenderError(width, height int, err error, accountEmail string, canSwitch bo
 	}
 
 	// Check if this is a login/authentication error
-	errStr := err.Error()
-	isAuthError := strings.Contains(errStr, "login failed") ||
-		strings.Contains(errStr, "AUTHENTICATIONFAILED") ||
-		strings.Contains(errStr, "Invalid credentials")
+	isAuthError := mail.IsAuthError(err) || strings.Contains(err.Error(), "login failed")
+	canReauth := mail.IsAuthError(err) && accountEmail != ""
 
 	fixHint := ""
 	if isAuthError {
 		fixHintStyle := lipgloss.NewStyle().
 			Foreground(Muted).
 			Italic(true)
-		fixHint = "\n\n" + fixHintStyle.Render(i18n.T("error.auth_hint"))
+		if canReauth {
+			fixHint = "\n\n" + fixHintStyle.Render(i18n.T("error.reauth_hint", map[string]any{"Email": accountEmail}))
+		} else {
+			fixHint = "\n\n" + fixHintStyle.Render(i18n.T("error.auth_hint"))
+		}
 	}
 
-	hint := ""
+	hint := "\n\n"
+	if canReauth {
+		hint += HelpKeyStyle.Render("r") + HelpDescStyle.Render(" "+i18n.T("help.reauth")+"  ")
+	}
 	if canSwitch {
-		hint = "\n\n" + HelpKeyStyle.Render("tab") + HelpDescStyle.Render(" "+i18n.T("help.switch_account")+"  ") +
-			HelpKeyStyle.Render("q") + HelpDescStyle.Render(" "+i18n.T("help.quit"))
-	} else {
-		hint = "\n\n" + HelpKeyStyle.Render("q") + HelpDescStyle.Render(" "+i18n.T("help.quit"))
+		hint += HelpKeyStyle.Render("tab") + HelpDescStyle.Render(" "+i18n.T("help.switch_account")+"  ")
 	}
+	hint += HelpKeyStyle.Render("q") + HelpDescStyle.Render(" "+i18n.T("help.quit"))
 
 	return lipgloss.Place(
 		width,
@@ -444,6 +745,159 @@ func RenderSummaryDialog(width, height int, viewportContent string, provider str
 	)
 }
 
+// RenderStatsDialog renders the sync statistics panel (last sync times and queue depth)
+func RenderStatsDialog(width, height int, stats []StatsInfo) string {
+	dialogWidth := min(width-20, 80)
+
+	titleStyle := lipgloss.NewStyle().
+		Bold(true).
+		Foreground(Primary).
+		MarginBottom(1)
+
+	accountStyle := lipgloss.NewStyle().
+		Bold(true).
+		Foreground(Text)
+
+	labelStyle := lipgloss.NewStyle().
+		Foreground(Muted).
+		Width(14)
+
+	valueStyle := lipgloss.NewStyle().
+		Foreground(TextDim)
+
+	errorStyle := lipgloss.NewStyle().
+		Foreground(Danger)
+
+	hintStyle := lipgloss.NewStyle().
+		Foreground(Muted).
+		MarginTop(1)
+
+	lines := []string{titleStyle.Render(i18n.T("stats.title"))}
+	if len(stats) == 0 {
+		lines = append(lines, valueStyle.Render(i18n.T("stats.none")))
+	}
+	for _, s := range stats {
+		lastSync := i18n.T("stats.never")
+		if !s.LastSync.IsZero() {
+			lastSync = i18n.T("stats.last_sync_value", map[string]any{
+				"Time":     s.LastSync.Local().Format("Jan 2 15:04"),
+				"Duration": s.LastDuration.Round(time.Millisecond),
+				"Count":    s.LastFetched,
+			})
+		}
+		lines = append(lines,
+			accountStyle.Render(s.Account),
+			labelStyle.Render(i18n.T("stats.last_sync"))+valueStyle.Render(lastSync),
+			labelStyle.Render(i18n.T("stats.syncs"))+valueStyle.Render(i18n.T("stats.syncs_value", map[string]any{
+				"Total":  s.TotalSyncs,
+				"Failed": s.FailedSyncs,
+			})),
+			labelStyle.Render(i18n.T("stats.queue"))+valueStyle.Render(i18n.T("stats.queue_value", map[string]any{"Count": s.PendingOps})),
+		)
+		if s.LastError != "" {
+			lines = append(lines, labelStyle.Render(i18n.T("stats.last_error"))+errorStyle.Render(truncate(s.LastError, max(10, dialogWidth-24))))
+		}
+		lines = append(lines, "")
+	}
+	lines = append(lines, hintStyle.Render(i18n.T("stats.close_hint")))
+
+	dialogStyle := lipgloss.NewStyle().
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(Primary).
+		Padding(1, 3).
+		Width(dialogWidth)
+
+	return lipgloss.Place(
+		width,
+		height-4,
+		lipgloss.Center,
+		lipgloss.Center,
+		dialogStyle.Render(lipgloss.JoinVertical(lipgloss.Left, lines...)),
+	)
+}
+
+// RenderStorageDialog renders the storage report: mailbox usage against the quota and the
+// size of the trash for each account
+func RenderStorageDialog(width, height int, storage []StorageInfo) string {
+	dialogWidth := min(width-20, 80)
+	barWidth := max(10, min(40, dialogWidth-30))
+
+	titleStyle := lipgloss.NewStyle().
+		Bold(true).
+		Foreground(Primary).
+		MarginBottom(1)
+
+	accountStyle := lipgloss.NewStyle().
+		Bold(true).
+		Foreground(Text)
+
+	labelStyle := lipgloss.NewStyle().
+		Foreground(Muted).
+		Width(14)
+
+	valueStyle := lipgloss.NewStyle().
+		Foreground(TextDim)
+
+	errorStyle := lipgloss.NewStyle().
+		Foreground(Danger)
+
+	hintStyle := lipgloss.NewStyle().
+		Foreground(Muted).
+		MarginTop(1)
+
+	lines := []string{titleStyle.Render(i18n.T("storage.title"))}
+	for _, s := range storage {
+		lines = append(lines, accountStyle.Render(s.Account))
+		if s.Error != "" {
+			lines = append(lines, labelStyle.Render(i18n.T("storage.error"))+errorStyle.Render(truncate(s.Error, max(10, dialogWidth-24))), "")
+			continue
+		}
+
+		if s.Quota == nil {
+			lines = append(lines, labelStyle.Render(i18n.T("storage.usage"))+valueStyle.Render(i18n.T("storage.unsupported")))
+		} else {
+			filled := min(barWidth, int(s.Quota.Percent()*float64(barWidth)/100))
+			barColor := Primary
+			if s.Quota.NearlyFull() {
+				barColor = Danger
+			}
+			bar := lipgloss.NewStyle().Foreground(barColor).Render(strings.Repeat("█", filled)) +
+				lipgloss.NewStyle().Foreground(Muted).Render(strings.Repeat("░", barWidth-filled))
+			lines = append(lines,
+				labelStyle.Render(i18n.T("storage.usage"))+valueStyle.Render(i18n.T("storage.usage_value", map[string]any{
+					"Used":    formatFileSize(s.Quota.UsedBytes),
+					"Limit":   formatFileSize(s.Quota.LimitBytes),
+					"Percent": fmt.Sprintf("%.0f", s.Quota.Percent()),
+				})),
+				labelStyle.Render("")+bar,
+			)
+			if s.Quota.NearlyFull() {
+				lines = append(lines, labelStyle.Render("")+errorStyle.Render(i18n.T("storage.nearly_full")))
+			}
+		}
+		if s.Trash != "" {
+			lines = append(lines, labelStyle.Render(i18n.T("storage.trash"))+
+				valueStyle.Render(i18n.TPlural("storage.trash_value", s.TrashCount, map[string]any{"Count": s.TrashCount})))
+		}
+		lines = append(lines, "")
+	}
+	lines = append(lines, hintStyle.Render("X "+i18n.T("help.empty_trash")+" • esc "+i18n.T("help.close")))
+
+	dialogStyle := lipgloss.NewStyle().
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(Primary).
+		Padding(1, 3).
+		Width(dialogWidth)
+
+	return lipgloss.Place(
+		width,
+		height-4,
+		lipgloss.Center,
+		lipgloss.Center,
+		dialogStyle.Render(lipgloss.JoinVertical(lipgloss.Left, lines...)),
+	)
+}
+
 func RenderExtractInputDialog(width, height int, inputView string) string {
 	dialogWidth := min(width-20, 60)
 
@@ -493,6 +947,8 @@ type ExtractData struct {
 	Location  string
 	Reminder  string // e.g., "15 minutes before" or empty
 	Provider  string
+	Conflicts []string // events already in the calendar at that time
+	FreeSlots []string // nearest free times, picked with 1, 2, 3...
 }
 
 func RenderExtractDialog(width, height int, data ExtractData) string {
@@ -549,9 +1005,11 @@ func RenderExtractDialog(width, height int, data ExtractData) string {
 		strings.Join(lines, "\n"),
 		"",
 		providerStyle.Render(i18n.T("summary.via", map[string]any{"Provider": data.Provider})),
-		"",
-		hintStyle.Render(i18n.T("extract.hint")),
 	)
+	if len(data.Conflicts) > 0 {
+		content = lipgloss.JoinVertical(lipgloss.Left, content, "", RenderSlotConflicts(data.Conflicts, data.FreeSlots))
+	}
+	content = lipgloss.JoinVertical(lipgloss.Left, content, "", hintStyle.Render(i18n.T("extract.hint")))
 
 	dialogStyle := lipgloss.NewStyle().
 		Border(lipgloss.RoundedBorder()).
@@ -568,6 +1026,27 @@ func RenderExtractDialog(width, height int, data ExtractData) string {
 	)
 }
 
+// RenderSlotConflicts warns that a new event overlaps events already in the calendar,
+// offering the free slots to move it to by number
+func RenderSlotConflicts(conflicts, free []string) string {
+	warnStyle := lipgloss.NewStyle().Foreground(Warning).Bold(true)
+	mutedStyle := lipgloss.NewStyle().Foreground(Muted)
+
+	lines := []string{warnStyle.Render("⚠ " + i18n.TPlural("calendar.conflicts", len(conflicts), nil))}
+	for _, c := range conflicts {
+		lines = append(lines, "  "+c)
+	}
+	if len(free) == 0 {
+		return strings.Join(append(lines, mutedStyle.Render(i18n.T("calendar.no_free_slots"))), "\n")
+	}
+	slots := make([]string, len(free))
+	for i, slot := range free {
+		slots[i] = HelpKeyStyle.Render(strconv.Itoa(i+1)) + " " + slot
+	}
+	lines = append(lines, mutedStyle.Render(i18n.T("calendar.free_slots"))+"  "+strings.Join(slots, "   "))
+	return strings.Join(lines, "\n")
+}
+
 // ExtractEditData contains form data for editing extracted events
 type ExtractEditData struct {
 	TitleInput    string
@@ -617,10 +1096,10 @@ func RenderExtractEditDialog(width, height int, data ExtractEditData) string {
 	}{
 		{i18n.T("extract.field.title"), data.TitleInput},
 		{i18n.T("extract.field.date"), data.DateInput},
-		{"Start:", data.StartInput},
-		{"End:", data.EndInput},
+		{i18n.T("calendar.field.start"), data.StartInput},
+		{i18n.T("calendar.field.end"), data.EndInput},
 		{i18n.T("extract.field.location"), data.LocationInput},
-		{"Notes:", data.NotesInput},
+		{i18n.T("calendar.field.notes"), data.NotesInput},
 	}
 
 	var lines []string
@@ -629,7 +1108,7 @@ func RenderExtractEditDialog(width, height int, data ExtractEditData) string {
 		if i == data.FocusIdx {
 			ls = focusedLabelStyle
 		}
-		lines = append(lines, ls.Render(f.label)+inputStyle.Render(f.value))
+		lines = append(lines, lipgloss.JoinHorizontal(lipgloss.Top, ls.Render(f.label), inputStyle.Render(f.value)))
 	}
 
 	// Add reminder field (uses ↑↓ to change)
@@ -766,6 +1245,89 @@ func RenderAttachmentPicker(width, height int, attachments []AttachmentInfo, sel
 	)
 }
 
+// DraftInfo describes an email set aside in the drafts switcher
+type DraftInfo struct {
+	Title string
+	To    string
+	From  string    // account it is sent from
+	Saved time.Time // last autosave, zero if not saved yet
+}
+
+// RenderDraftSwitcher renders the emails set aside, to pick the one to resume, highlighting
+// selectedIdx; confirmDrop asks to confirm discarding the selected one
+func RenderDraftSwitcher(width, height int, drafts []DraftInfo, selectedIdx int, confirmDrop bool) string {
+	dialogWidth := min(width-20, 70)
+
+	titleStyle := lipgloss.NewStyle().
+		Bold(true).
+		Foreground(Primary).
+		MarginBottom(1)
+
+	selectedStyle := lipgloss.NewStyle().
+		Bold(true).
+		Foreground(Bg).
+		Background(Primary).
+		Padding(0, 1)
+
+	normalStyle := lipgloss.NewStyle().
+		Foreground(Text).
+		Padding(0, 1)
+
+	detailStyle := lipgloss.NewStyle().
+		Foreground(Muted).
+		PaddingLeft(3)
+
+	hintStyle := lipgloss.NewStyle().
+		Foreground(Muted).
+		MarginTop(1)
+
+	var items []string
+	for i, draft := range drafts {
+		title := truncate(draft.Title, dialogWidth-10)
+		if i == selectedIdx {
+			items = append(items, selectedStyle.Render("→ "+title))
+		} else {
+			items = append(items, normalStyle.Render("  "+title))
+		}
+		detail := i18n.T("drafts.from", map[string]any{"From": draft.From})
+		if draft.To != "" {
+			detail = i18n.T("drafts.to", map[string]any{"To": draft.To}) + " · " + detail
+		}
+		if !draft.Saved.IsZero() {
+			detail += " · " + i18n.T("drafts.saved", map[string]any{"Time": draft.Saved.Format("15:04")})
+		}
+		items = append(items, detailStyle.Render(truncate(detail, dialogWidth-8)))
+	}
+
+	hint := i18n.T("drafts.hint")
+	if confirmDrop && selectedIdx < len(drafts) {
+		hint = i18n.T("drafts.confirm_discard", map[string]any{"Subject": drafts[selectedIdx].Title}) + " (y/n)"
+	}
+
+	content := lipgloss.JoinVertical(
+		lipgloss.Left,
+		titleStyle.Render(i18n.T("drafts.title", map[string]any{"Count": len(drafts)})),
+		"",
+		strings.Join(items, "\n"),
+		"",
+		hintStyle.Render(hint),
+	)
+
+	dialogStyle := lipgloss.NewStyle().
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(Primary).
+		Padding(1, 2).
+		Width(dialogWidth)
+
+	return lipgloss.Place(
+		width,
+		height-4,
+		lipgloss.Center,
+		lipgloss.Center,
+		dialogStyle.Render(content),
+	)
+}
+
 // WrapWithHangingIndent wraps text with proper hanging indent for list items.
 // Lines starting with list markers (-, *, •, 1.) will have continuation lines
 // indented to align with the text after the marker.
@@ -851,7 +1413,7 @@ func detectListIndent(line string) int {
 
 // wrapLineWithIndent wraps a single line with hanging indent
 func wrapLineWithIndent(line string, width, indent int) []string {
-	if len(line) <= width {
+	if runewidth.StringWidth(line) <= width {
 		return []string{line}
 	}
 
@@ -871,32 +1433,9 @@ func wrapLineWithIndent(line string, width, indent int) []string {
 			}
 		}
 
-		if len(remaining) <= maxWidth {
-			result = append(result, prefix+remaining)
-			break
-		}
-
-		// Find break point (last space within width)
-		breakPoint := maxWidth
-		for i := maxWidth; i > 0; i-- {
-			if remaining[i] == ' ' {
-				breakPoint = i
-				break
-			}
-		}
-
-		// If no space found, force break at width
-		if breakPoint == maxWidth && remaining[breakPoint] != ' ' {
-			for i := maxWidth; i > maxWidth/2; i-- {
-				if remaining[i] == ' ' {
-					breakPoint = i
-					break
-				}
-			}
-		}
-
-		result = append(result, prefix+remaining[:breakPoint])
-		remaining = strings.TrimLeft(remaining[breakPoint:], " ")
+		var head string
+		head, remaining = splitAtWidth(remaining, maxWidth, 1)
+		result = append(result, prefix+head)
 		isFirst = false
 	}
 
@@ -905,7 +1444,7 @@ func wrapLineWithIndent(line string, width, indent int) []string {
 
 // wrapLine wraps a single line without special indent
 func wrapLine(line string, width int) []string {
-	if len(line) <= width {
+	if runewidth.StringWidth(line) <= width {
 		return []string{line}
 	}
 
@@ -913,23 +1452,43 @@ func wrapLine(line string, width int) []string {
 	remaining := line
 
 	for len(remaining) > 0 {
-		if len(remaining) <= width {
-			result = append(result, remaining)
-			break
-		}
+		var head string
+		head, remaining = splitAtWidth(remaining, width, width/2)
+		result = append(result, head)
+	}
+
+	return result
+}
 
-		// Find break point
-		breakPoint := width
-		for i := width; i > width/2; i-- {
-			if remaining[i] == ' ' {
-				breakPoint = i
-				break
+// splitAtWidth splits s after at most width display columns, at the last space past
+// minBreak columns when there is one. Wide characters count as two columns and are
+// never cut in half.
+func splitAtWidth(s string, width, minBreak int) (string, string) {
+	cols, cut, space := 0, len(s), -1
+	for i, r := range s {
+		w := runewidth.RuneWidth(r)
+		if cols+w > width {
+			cut = i
+			if r == ' ' {
+				space = i
 			}
+			break
 		}
-
-		result = append(result, remaining[:breakPoint])
-		remaining = strings.TrimLeft(remaining[breakPoint:], " ")
+		if r == ' ' && cols >= minBreak && cols > 0 {
+			space = i
+		}
+		cols += w
 	}
-
-	return result
+	if cut == len(s) {
+		return s, ""
+	}
+	if space > 0 {
+		cut = space
+	}
+	if cut == 0 {
+		// A character wider than the whole line still has to go somewhere
+		_, size := utf8.DecodeRuneInString(s)
+		cut = size
+	}
+	return s[:cut], strings.TrimLeft(s[cut:], " ")
 }
@@ -0,0 +1,185 @@
+package components
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"maily/internal/i18n"
+)
+
+// ZonePicker picks a time zone by fuzzy search over zone names: typing "berl" or
+// "ny" finds Europe/Berlin or America/New_York, and ↑↓ step through the matches
+type ZonePicker struct {
+	input   textinput.Model
+	zones   []string
+	matches []string
+	idx     int
+	value   string // the chosen zone, "" for local time
+}
+
+// NewZonePicker creates a zone picker over zones, set to local time
+func NewZonePicker(zones []string) ZonePicker {
+	input := textinput.New()
+	input.Prompt = ""
+	input.CharLimit = 40
+	input.Width = 20
+	return ZonePicker{input: input, zones: zones}
+}
+
+// Focus starts a new search
+func (z *ZonePicker) Focus() {
+	z.input.SetValue("")
+	z.matches = nil
+	z.input.Focus()
+}
+
+// Blur keeps the zone chosen and ends the search
+func (z *ZonePicker) Blur() {
+	z.input.Blur()
+	z.input.SetValue("")
+	z.matches = nil
+}
+
+// SetValue sets the zone, "" for local time
+func (z *ZonePicker) SetValue(name string) {
+	z.value = name
+}
+
+// Value returns the chosen zone, "" for local time
+func (z ZonePicker) Value() string {
+	return z.value
+}
+
+// Update handles key messages for the zone picker
+func (z ZonePicker) Update(msg tea.Msg) (ZonePicker, tea.Cmd) {
+	if !z.input.Focused() {
+		return z, nil
+	}
+
+	if msg, ok := msg.(tea.KeyMsg); ok && len(z.matches) > 0 {
+		switch msg.String() {
+		case "up":
+			z.idx = (z.idx + len(z.matches) - 1) % len(z.matches)
+			z.value = z.matches[z.idx]
+			return z, nil
+		case "down":
+			z.idx = (z.idx + 1) % len(z.matches)
+			z.value = z.matches[z.idx]
+			return z, nil
+		}
+	}
+
+	var cmd tea.Cmd
+	z.input, cmd = z.input.Update(msg)
+	z.matches = matchZones(z.zones, z.input.Value())
+	z.idx = 0
+	if len(z.matches) > 0 {
+		z.value = z.matches[0]
+	}
+	return z, cmd
+}
+
+// View renders the zone picker: the search and its match while focused, the zone
+// and its UTC offset otherwise
+func (z ZonePicker) View() string {
+	normalStyle := lipgloss.NewStyle().Foreground(Text)
+	dimStyle := lipgloss.NewStyle().Foreground(Muted)
+
+	chosen := i18n.T("calendar.local_time")
+	if z.value != "" {
+		chosen = z.value + " " + zoneOffset(z.value)
+	}
+	if !z.input.Focused() {
+		return dimStyle.Render(chosen)
+	}
+	if z.input.Value() == "" {
+		return z.input.View() + dimStyle.Render(chosen)
+	}
+	if len(z.matches) == 0 {
+		return z.input.View() + dimStyle.Render("  "+i18n.T("calendar.no_zone"))
+	}
+	count := fmt.Sprintf("  (%d/%d)", z.idx+1, len(z.matches))
+	return z.input.View() + normalStyle.Render(" → "+chosen) + dimStyle.Render(count)
+}
+
+// zoneOffset returns a zone's current offset from UTC, e.g. "UTC+01:00"
+func zoneOffset(name string) string {
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		return ""
+	}
+	return "UTC" + time.Now().In(loc).Format("-07:00")
+}
+
+// matchZones returns the zones matching query, best first
+func matchZones(zones []string, query string) []string {
+	if strings.TrimSpace(query) == "" {
+		return nil
+	}
+	type match struct {
+		zone  string
+		score int
+	}
+	var matches []match
+	for _, zone := range zones {
+		if score, ok := fuzzyScore(query, zone); ok {
+			matches = append(matches, match{zone, score})
+		}
+	}
+	sort.SliceStable(matches, func(i, j int) bool {
+		return matches[i].score > matches[j].score
+	})
+
+	names := make([]string, len(matches))
+	for i, m := range matches {
+		names[i] = m.zone
+	}
+	return names
+}
+
+// fuzzyScore scores how well query matches a zone name: the query as one piece beats
+// its letters spread out in order, and matching the start of a word beats the middle,
+// though initials ("ny" for New York) count as the start of one; ok is false when the
+// letters aren't all there
+func fuzzyScore(query, zone string) (score int, ok bool) {
+	normalize := strings.NewReplacer("_", " ", "/", " ").Replace
+	q := strings.ToLower(normalize(strings.TrimSpace(query)))
+	name := strings.ToLower(normalize(zone))
+
+	var initials strings.Builder
+	for _, word := range strings.Fields(name) {
+		initials.WriteByte(word[0])
+	}
+	if len(q) > 1 && strings.Contains(initials.String(), q) {
+		return 2500, true
+	}
+
+	if i := strings.Index(name, q); i >= 0 {
+		score = 1000 - i
+		if i == 0 || name[i-1] == ' ' {
+			score += 1000
+		}
+		return score, true
+	}
+
+	// Letters in order, preferring ones at word starts
+	pos := 0
+	for _, r := range q {
+		i := strings.IndexRune(name[pos:], r)
+		if i < 0 {
+			return 0, false
+		}
+		at := pos + i
+		if at == 0 || name[at-1] == ' ' {
+			score += 10
+		}
+		score -= i
+		pos = at + len(string(r))
+	}
+	return score, true
+}
@@ -1,9 +1,14 @@
 package ui
 
 import (
+	"context"
 	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
 	"regexp"
 	"strings"
+	"time"
 	"unicode"
 	"unicode/utf8"
 
@@ -12,7 +17,13 @@ import (
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
 
+	"maily/config"
+	"maily/internal/auth"
+	"maily/internal/contacts"
+	"maily/internal/htmlbody"
+	"maily/internal/i18n"
 	"maily/internal/mail"
+	"maily/internal/spell"
 	"maily/internal/ui/components"
 )
 
@@ -22,9 +33,6 @@ var emailExtractRegex = regexp.MustCompile(`<([^>]+)>`)
 // maxQuotedBodyLen limits quoted body length to prevent performance issues
 const maxQuotedBodyLen = 10000
 
-// maxAttachmentSize is the Gmail attachment size limit (25MB)
-const maxAttachmentSize = 25 * 1024 * 1024
-
 // Focus fields
 const (
 	focusTo = iota
@@ -46,6 +54,7 @@ const (
 	confirmSend
 	confirmSaveDraft
 	confirmCancel
+	confirmAttachment // the body mentions an attachment but nothing is attached
 )
 
 // ComposeAttachment represents an attachment to be sent
@@ -71,29 +80,49 @@ type ComposeModel struct {
 	confirming      int         // confirmNone, confirmSend, or confirmCancel
 	confirmFocused  int         // 0 = Confirm button, 1 = Cancel button
 	quotedBody      string      // stored quoted body for deferred initialization
+	bottomPost      bool        // reply below the quoted body instead of above it
+	signature       string      // added below where the text is typed, after a "-- " line
+	bodyPending     bool        // the quote and signature are placed in the body on first resize
 	attachments     []ComposeAttachment
-	totalAttachSize int64 // cumulative size of all attachments
-	attachmentIdx   int   // currently selected attachment index
+	totalAttachSize int64              // cumulative size of all attachments
+	attachmentIdx   int                // currently selected attachment index
+	contacts        []contacts.Contact // address book for To autocomplete
+	attachLimit     int64              // total attachment size the account's provider accepts
+	attachAdvice    string             // what to do instead when files are too large
+	speller         *spell.Checker     // nil when spell checking is off
+	spelling        *spellPopup        // corrections for the word at the cursor, nil when closed
+	checkDomains    bool               // look up recipient domains' mail servers before sending
+	checkingDomains bool               // a domain lookup is in flight
+	addressWarnings []mail.AddressWarning
+	draftID         string    // key of the autosaved copy, empty until first saved
+	autosaved       string    // the email as last autosaved
+	autosavedAt     time.Time // when it was last autosaved
 }
 
 // OpenFilePickerMsg is sent when user wants to open the file picker
 type OpenFilePickerMsg struct{}
 
+// PastedAttachmentsMsg reports file paths pasted into the attachments area
+type PastedAttachmentsMsg struct {
+	Added  []string // names of the attached files
+	Errors []string // one message per path that could not be attached
+}
+
 // NewComposeModel creates a new compose model for a fresh email
 func NewComposeModel(from string) ComposeModel {
 	ti := textinput.New()
-	ti.Placeholder = "recipient@example.com"
+	ti.Placeholder = i18n.T("compose.placeholder.to")
 	ti.Focus()
 	ti.CharLimit = 200
 	ti.Width = 50
 
 	si := textinput.New()
-	si.Placeholder = "Subject"
+	si.Placeholder = i18n.T("compose.placeholder.subject")
 	si.CharLimit = 200
 	si.Width = 50
 
 	ta := textarea.New()
-	ta.Placeholder = "Type your message..."
+	ta.Placeholder = i18n.T("compose.placeholder.body")
 	ta.CharLimit = 0
 	ta.SetWidth(80)
 	ta.SetHeight(10)
@@ -104,11 +133,65 @@ func NewComposeModel(from string) ComposeModel {
 		subjectInput: si,
 		body:         ta,
 		focused:      focusTo, // Start at To field for new compose
+		attachLimit:  mail.MaxAttachmentSize,
+		attachAdvice: mail.AttachmentAdvice(""),
+	}
+}
+
+// ComposeDraft prefills a new email opened from the command line or a mailto: URL
+type ComposeDraft struct {
+	Account string // sender; empty uses the first account
+	To      string
+	Subject string
+	Body    string
+}
+
+// NewDraftModel creates a compose model prefilled from a draft, focused on the first empty field
+func NewDraftModel(from string, draft ComposeDraft) ComposeModel {
+	m := NewComposeModel(from)
+	m.toInput.SetValue(sanitizeHeaderValue(draft.To))
+	m.subjectInput.SetValue(sanitizeHeaderValue(draft.Subject))
+	m.body.SetValue(draft.Body)
+	switch {
+	case draft.To == "":
+	case draft.Subject == "":
+		m.focusField(focusSubject)
+	default:
+		m.focusField(focusBody)
+		m.moveBodyCursorToLine(0)
 	}
+	return m
+}
+
+// NewForwardModel creates a compose model forwarding an email to new recipients. The
+// original's attachments aren't carried over.
+func NewForwardModel(from string, original *mail.Email) ComposeModel {
+	subject := original.Subject
+	if lower := strings.ToLower(subject); !strings.HasPrefix(lower, "fwd:") && !strings.HasPrefix(lower, "fw:") {
+		subject = "Fwd: " + subject
+	}
+	return NewDraftModel(from, ComposeDraft{
+		Subject: subject,
+		Body:    "\n\n" + buildForwardBody(original),
+	})
+}
+
+// NewResendModel creates a compose model for sending a sent email again, prefilled with its
+// recipients (Cc folded into To), subject and body
+func NewResendModel(from string, original *mail.Email) ComposeModel {
+	var recipients []string
+	for _, r := range append(parseEmailList(original.To), parseEmailList(original.Cc)...) {
+		recipients = append(recipients, extractEmail(r))
+	}
+	return NewDraftModel(from, ComposeDraft{
+		To:      strings.Join(recipients, ", "),
+		Subject: original.Subject,
+		Body:    editableBody(original),
+	})
 }
 
 // NewReplyModel creates a compose model for replying to an email
-func NewReplyModel(from string, original *mail.Email) ComposeModel {
+func NewReplyModel(from string, original *mail.Email, quoting *config.ReplyConfig) ComposeModel {
 	// Determine who to reply to
 	replyTo := original.From
 	if original.ReplyTo != "" {
@@ -132,16 +215,13 @@ func NewReplyModel(from string, original *mail.Email) ComposeModel {
 	si.Width = 50
 
 	ta := textarea.New()
-	ta.Placeholder = "Type your reply..."
+	ta.Placeholder = i18n.T("compose.placeholder.reply_body")
 	ta.CharLimit = 0
 	ta.SetWidth(80)
 	ta.SetHeight(10)
 	ta.Focus()
 
-	// Build quoted body - will be set after first resize
-	quotedBody := buildQuotedBody(original)
-
-	return ComposeModel{
+	m := ComposeModel{
 		from:         from,
 		toInput:      ti,
 		subjectInput: si,
@@ -149,12 +229,14 @@ func NewReplyModel(from string, original *mail.Email) ComposeModel {
 		focused:      focusBody, // Start at body for reply
 		isReply:      true,
 		replyEmail:   original,
-		quotedBody:   "\n\n" + quotedBody,
 	}
+	// Quoted body - will be set after first resize
+	m.setReplyQuote(original, quoting)
+	return m
 }
 
 // NewReplyAllModel creates a compose model for replying to all recipients
-func NewReplyAllModel(from string, original *mail.Email) ComposeModel {
+func NewReplyAllModel(from string, original *mail.Email, quoting *config.ReplyConfig) ComposeModel {
 	// Determine who to reply to
 	replyTo := original.From
 	if original.ReplyTo != "" {
@@ -206,15 +288,13 @@ func NewReplyAllModel(from string, original *mail.Email) ComposeModel {
 	si.Width = 50
 
 	ta := textarea.New()
-	ta.Placeholder = "Type your reply..."
+	ta.Placeholder = i18n.T("compose.placeholder.reply_body")
 	ta.CharLimit = 0
 	ta.SetWidth(80)
 	ta.SetHeight(10)
 	ta.Focus()
 
-	quotedBody := buildQuotedBody(original)
-
-	return ComposeModel{
+	m := ComposeModel{
 		from:         from,
 		toInput:      ti,
 		subjectInput: si,
@@ -223,8 +303,9 @@ func NewReplyAllModel(from string, original *mail.Email) ComposeModel {
 		isReply:      true,
 		isReplyAll:   true,
 		replyEmail:   original,
-		quotedBody:   "\n\n" + quotedBody,
 	}
+	m.setReplyQuote(original, quoting)
+	return m
 }
 
 // parseEmailList splits a comma-separated email list into individual entries
@@ -290,40 +371,185 @@ func sanitizeControlChars(s string) string {
 }
 
 // buildQuotedBody creates the quoted original email content
-func buildQuotedBody(email *mail.Email) string {
+func buildQuotedBody(email *mail.Email, quoting *config.ReplyConfig) string {
 	var sb strings.Builder
 
 	// Sanitize From field to prevent escape injection
 	sanitizedFrom := sanitizeControlChars(email.From)
 
 	// Quote header
-	dateStr := email.Date.Format("Mon, Jan 2, 2006 at 3:04 PM")
-	sb.WriteString(fmt.Sprintf("On %s, %s wrote:\n", dateStr, sanitizedFrom))
+	sb.WriteString(i18n.T("compose.attribution", map[string]any{
+		"Date": i18n.FormatDateTime(email.Date),
+		"From": sanitizedFrom,
+	}) + "\n")
+
+	// Quote the body as text, sanitized and truncated, with > prefix
+	lines := strings.Split(editableBody(email), "\n")
+	if quoting != nil && quoting.StripSignature {
+		lines = stripSignature(lines)
+	}
+	if quoting != nil && quoting.MaxQuoteDepth > 0 {
+		lines = trimQuoteDepth(lines, quoting.MaxQuoteDepth-1)
+	}
+	for _, line := range lines {
+		sb.WriteString("> ")
+		sb.WriteString(line)
+		sb.WriteString("\n")
+	}
+
+	return sb.String()
+}
 
-	// Quote body with > prefix
-	body := email.BodyHTML
-	if body == "" {
-		body = email.Snippet
+// setReplyQuote prepares the quoted email in the configured style, applied to the
+// body after the first resize
+func (m *ComposeModel) setReplyQuote(original *mail.Email, quoting *config.ReplyConfig) {
+	switch quoting.Style() {
+	case config.QuoteNone:
+		m.quotedBody = ""
+	case config.QuoteBottom:
+		m.quotedBody = buildQuotedBody(original, quoting)
+		m.bottomPost = true
+	default:
+		m.quotedBody = buildQuotedBody(original, quoting)
 	}
+	m.bodyPending = true
+}
 
-	// Sanitize body to prevent escape injection
-	body = sanitizeControlChars(body)
+// SetSignature sets the signature added to replies and to new emails whose body starts
+// empty; emails prefilled with a body, such as forwards, are left as they are
+func (m *ComposeModel) SetSignature(signature string) {
+	m.signature = strings.TrimRight(signature, "\n")
+	if m.signature != "" && !m.isReply && m.body.Value() == "" {
+		m.bodyPending = true
+	}
+}
 
-	// Limit quoted body length to prevent performance issues
-	if len(body) > maxQuotedBodyLen {
-		body = body[:maxQuotedBodyLen] + "\n[... content truncated ...]"
+// replyBody lays out the body of an email: text and the signature below it, above the
+// quote or below it when bottom posting. It returns the body and the line text starts
+// on, where the cursor goes.
+func replyBody(text, quote, signature string, bottomPost bool) (body string, line int) {
+	if signature != "" {
+		text += "\n\n-- \n" + signature
+	}
+	switch {
+	case quote == "":
+		return text, 0
+	case bottomPost:
+		return quote + "\n" + text, strings.Count(quote, "\n") + 1
 	}
+	return text + "\n\n" + quote, 0
+}
 
-	lines := strings.Split(body, "\n")
+// stripSignature drops signatures from an email's lines: each "-- " delimiter and
+// the lines after it, up to the next quoted part
+func stripSignature(lines []string) []string {
+	var out []string
+	inSignature := false
 	for _, line := range lines {
-		sb.WriteString("> ")
-		sb.WriteString(line)
-		sb.WriteString("\n")
+		switch {
+		case strings.TrimRight(line, " ") == "--":
+			inSignature = true
+		case inSignature && (quoteDepth(line) > 0 || components.IsAttribution(line)):
+			inSignature = false
+		}
+		if !inSignature {
+			out = append(out, line)
+		}
+	}
+	// Leave no blank lines where a trailing signature was
+	for len(out) > 0 && strings.TrimSpace(out[len(out)-1]) == "" {
+		out = out[:len(out)-1]
+	}
+	return out
+}
+
+// trimQuoteDepth drops quotes nested deeper than depth levels, along with the
+// attribution line introducing them, leaving one "[...]" line in their place
+func trimQuoteDepth(lines []string, depth int) []string {
+	var out []string
+	trimmed := false
+	for i, line := range lines {
+		if quoteDepth(line) > depth {
+			if !trimmed {
+				out = append(out, strings.Repeat("> ", depth)+"[...]")
+			}
+			trimmed = true
+			continue
+		}
+		trimmed = false
+		// An attribution whose quote is about to be dropped goes too
+		if components.IsAttribution(line) && nextQuoteDepth(lines[i+1:]) > depth {
+			continue
+		}
+		out = append(out, line)
+	}
+	return out
+}
+
+// quoteDepth counts the > markers at the start of a line
+func quoteDepth(line string) int {
+	depth := 0
+	for _, r := range line {
+		switch r {
+		case '>':
+			depth++
+		case ' ':
+		default:
+			return depth
+		}
 	}
+	return depth
+}
+
+// nextQuoteDepth returns the quote depth of the first non-blank line
+func nextQuoteDepth(lines []string) int {
+	for _, line := range lines {
+		if strings.Trim(line, "> ") != "" {
+			return quoteDepth(line)
+		}
+	}
+	return 0
+}
+
+// forwardedMarker starts the original message in a forwarded email's body
+const forwardedMarker = "---------- Forwarded message ----------"
+
+// buildForwardBody builds the forwarded message block: the original headers, then its body
+func buildForwardBody(email *mail.Email) string {
+	var sb strings.Builder
+
+	sb.WriteString(forwardedMarker + "\n")
+	sb.WriteString(fmt.Sprintf("From: %s\n", sanitizeControlChars(email.From)))
+	sb.WriteString(fmt.Sprintf("Date: %s\n", email.Date.Format("Mon, Jan 2, 2006 at 3:04 PM")))
+	sb.WriteString(fmt.Sprintf("Subject: %s\n", sanitizeControlChars(email.Subject)))
+	sb.WriteString(fmt.Sprintf("To: %s\n", sanitizeControlChars(email.To)))
+	if email.Cc != "" {
+		sb.WriteString(fmt.Sprintf("Cc: %s\n", sanitizeControlChars(email.Cc)))
+	}
+	sb.WriteString("\n")
+	sb.WriteString(editableBody(email))
+	sb.WriteString("\n")
 
 	return sb.String()
 }
 
+// editableBody returns an email's body as text to edit in compose: plain-text bodies as
+// they were sent, HTML converted to markdown
+func editableBody(email *mail.Email) string {
+	body := email.Snippet
+	if text, ok := mail.UnwrapPlainText(email.BodyHTML); ok {
+		body = strings.TrimRight(text, "\n")
+	} else if email.BodyHTML != "" {
+		body = strings.TrimSpace(htmlbody.ToMarkdown(email.BodyHTML))
+	}
+
+	body = sanitizeControlChars(body)
+	if len(body) > maxQuotedBodyLen {
+		body = body[:maxQuotedBodyLen] + "\n[... content truncated ...]"
+	}
+	return body
+}
+
 func (m *ComposeModel) setSize(width, height int) {
 	m.width = width
 	m.height = height
@@ -351,22 +577,31 @@ func (m *ComposeModel) setSize(width, height int) {
 	}
 	m.body.SetHeight(bodyHeight)
 
-	m.applyDeferredReplyQuote()
+	m.applyDeferredBody()
 }
 
-func (m *ComposeModel) applyDeferredReplyQuote() {
-	if !m.isReply || m.quotedBody == "" {
+// applyDeferredBody places the reply quote and the signature in the body, with the
+// cursor where the text goes: above the quote, or below it when bottom posting, and
+// above the signature
+func (m *ComposeModel) applyDeferredBody() {
+	if !m.bodyPending || m.body.Value() != "" {
 		return
 	}
-	m.body.SetValue(m.quotedBody)
+	body, line := replyBody("", m.quotedBody, m.signature, m.bottomPost)
+	m.body.SetValue(body)
 	m.quotedBody = ""
-	m.moveBodyCursorToTop()
+	m.bodyPending = false
+	m.moveBodyCursorToLine(line)
 }
 
-func (m *ComposeModel) moveBodyCursorToTop() {
+// moveBodyCursorToLine puts the body cursor at the start of a line
+func (m *ComposeModel) moveBodyCursorToLine(line int) {
 	for m.body.Line() > 0 || m.body.LineInfo().RowOffset > 0 {
 		m.body.CursorUp()
 	}
+	for m.body.Line() < min(line, m.body.LineCount()-1) {
+		m.body.CursorDown()
+	}
 	m.body.CursorStart()
 }
 
@@ -428,6 +663,9 @@ type SaveDraftMsg struct{}
 // CancelMsg is sent when user presses Enter on Cancel button
 type CancelMsg struct{}
 
+// MinimizeMsg is sent when user presses Esc to set the email aside and browse
+type MinimizeMsg struct{}
+
 func (m ComposeModel) Update(msg tea.Msg) (ComposeModel, tea.Cmd) {
 	var cmd tea.Cmd
 	var cmds []tea.Cmd
@@ -437,6 +675,34 @@ func (m ComposeModel) Update(msg tea.Msg) (ComposeModel, tea.Cmd) {
 		if isMouseEscapeKey(msg) {
 			return m, nil
 		}
+		// Handle the missing attachment reminder: attach now or send anyway
+		if m.confirming == confirmAttachment {
+			attach := false
+			switch msg.String() {
+			case "left", "right", "tab", "h", "l":
+				m.confirmFocused = 1 - m.confirmFocused
+				return m, nil
+			case "a", "A":
+				attach = true
+			case "s", "S":
+			case "enter":
+				attach = m.confirmFocused == 0
+			case "esc":
+				m.confirming = confirmNone
+				m.confirmFocused = 0
+				return m, nil
+			default:
+				return m, nil
+			}
+			m.confirmFocused = 0
+			if attach {
+				m.confirming = confirmNone
+				return m, func() tea.Msg { return OpenFilePickerMsg{} }
+			}
+			m.confirming = confirmSend
+			return m, m.checkRecipients()
+		}
+
 		// Handle confirmation dialogs
 		if m.confirming != confirmNone {
 			switch msg.String() {
@@ -477,6 +743,11 @@ func (m ComposeModel) Update(msg tea.Msg) (ComposeModel, tea.Cmd) {
 					m.confirmFocused = 0
 					return m, nil
 				}
+			case "f", "F":
+				// Apply the suggested fixes for mistyped recipient domains
+				if m.confirming == confirmSend && m.fixRecipients() {
+					return m, m.checkRecipients()
+				}
 			case "n", "N", "esc":
 				m.confirming = confirmNone
 				m.confirmFocused = 0
@@ -485,14 +756,40 @@ func (m ComposeModel) Update(msg tea.Msg) (ComposeModel, tea.Cmd) {
 			return m, nil
 		}
 
+		if m.spelling != nil {
+			m.updateSpellPopup(msg)
+			return m, nil
+		}
+
+		// Pasting file paths on the attach row or attachment list attaches them directly
+		if msg.Paste && (m.focused == focusAttach || m.focused == focusAttachments) {
+			result := m.attachPastedPaths(string(msg.Runes))
+			return m, func() tea.Msg { return result }
+		}
+
 		switch msg.String() {
+		case "esc":
+			return m, func() tea.Msg { return MinimizeMsg{} }
+		case "ctrl+e":
+			if m.focused == focusSubject || m.focused == focusBody {
+				return m, func() tea.Msg { return OpenEmojiPickerMsg{} }
+			}
+		case "ctrl+l":
+			if m.focused == focusBody && m.speller != nil {
+				m.openSpellPopup()
+				return m, nil
+			}
 		case "enter":
 			if m.focused == focusAttach {
 				return m, func() tea.Msg { return OpenFilePickerMsg{} }
 			}
 			if m.focused == focusSend {
+				if len(m.attachments) == 0 && mentionsAttachment(m.body.Value()) {
+					m.confirming = confirmAttachment
+					return m, nil
+				}
 				m.confirming = confirmSend
-				return m, nil
+				return m, m.checkRecipients()
 			}
 			if m.focused == focusSaveDraft {
 				m.confirming = confirmSaveDraft
@@ -503,6 +800,15 @@ func (m ComposeModel) Update(msg tea.Msg) (ComposeModel, tea.Cmd) {
 				return m, nil
 			}
 		case "tab":
+			// Accept the autocompleted recipient before moving on
+			if m.focused == focusTo {
+				if s := m.toInput.CurrentSuggestion(); s != "" && s != m.toInput.Value() {
+					m.toInput.SetValue(s)
+					m.toInput.CursorEnd()
+					m.updateRecipientSuggestions()
+					return m, nil
+				}
+			}
 			// Cycle focus: To → Subject → Body → Attachments → Send → Save Draft → Cancel → To
 			nextFocus := (m.focused + 1) % numFocusFields
 			// Skip attachments if there are none
@@ -558,6 +864,13 @@ func (m ComposeModel) Update(msg tea.Msg) (ComposeModel, tea.Cmd) {
 				return m, nil
 			}
 		}
+	case recipientDomainsCheckedMsg:
+		// Drop results for recipients that have since been edited
+		if msg.to == m.toInput.Value() && m.checkingDomains {
+			m.checkingDomains = false
+			m.addressWarnings = append(m.addressWarnings, msg.warnings...)
+		}
+		return m, nil
 	case tea.MouseMsg:
 		// Ignore mouse events to prevent gibberish in textarea
 		return m, nil
@@ -566,8 +879,8 @@ func (m ComposeModel) Update(msg tea.Msg) (ComposeModel, tea.Cmd) {
 		return m, nil
 	}
 
-	if m.isReply && m.quotedBody != "" && m.body.Value() == "" {
-		m.applyDeferredReplyQuote()
+	if m.bodyPending && m.body.Value() == "" {
+		m.applyDeferredBody()
 		cmds = append(cmds, textarea.Blink)
 	}
 
@@ -576,6 +889,9 @@ func (m ComposeModel) Update(msg tea.Msg) (ComposeModel, tea.Cmd) {
 	case focusTo:
 		m.toInput, cmd = m.toInput.Update(msg)
 		cmds = append(cmds, cmd)
+		if _, ok := msg.(tea.KeyMsg); ok {
+			m.updateRecipientSuggestions()
+		}
 	case focusSubject:
 		m.subjectInput, cmd = m.subjectInput.Update(msg)
 		cmds = append(cmds, cmd)
@@ -598,43 +914,50 @@ func (m ComposeModel) View() string {
 	labelStyle := lipgloss.NewStyle().Width(10)
 
 	// From line (not editable)
-	fromLine := labelStyle.Render("From:") + " " + m.from
+	fromLine := labelStyle.Render(i18n.T("compose.label.from")) + " " + m.from
 
 	// To line
-	toLabel := labelStyle.Render("To:")
+	toLabel := labelStyle.Render(i18n.T("compose.label.to"))
 	if m.focused == focusTo {
-		toLabel = focusedStyle.Render(labelStyle.Render("To:"))
+		toLabel = focusedStyle.Render(labelStyle.Render(i18n.T("compose.label.to")))
 	}
 	toLine := toLabel + " " + m.toInput.View()
 
 	// Subject line
-	subjectLabel := labelStyle.Render("Subject:")
+	subjectLabel := labelStyle.Render(i18n.T("compose.label.subject"))
 	if m.focused == focusSubject {
-		subjectLabel = focusedStyle.Render(labelStyle.Render("Subject:"))
+		subjectLabel = focusedStyle.Render(labelStyle.Render(i18n.T("compose.label.subject")))
 	}
 	subjectLine := subjectLabel + " " + m.subjectInput.View()
 
 	// Attach row
-	attachLabel := labelStyle.Render("Attach:")
-	attachBtn := "[+ Add File]"
+	attachLabel := labelStyle.Render(i18n.T("compose.label.attach"))
+	attachBtn := "[+ " + i18n.T("compose.add_file") + "]"
 	if m.focused == focusAttach {
-		attachLabel = focusedStyle.Render(labelStyle.Render("Attach:"))
+		attachLabel = focusedStyle.Render(labelStyle.Render(i18n.T("compose.label.attach")))
 		attachBtn = lipgloss.NewStyle().
 			Background(components.Primary).
 			Foreground(lipgloss.Color("#FFFFFF")).
 			Bold(true).
 			Padding(0, 1).
-			Render("+ Add File")
+			Render("+ " + i18n.T("compose.add_file"))
 	} else {
 		attachBtn = lipgloss.NewStyle().
 			Foreground(components.TextDim).
 			Padding(0, 1).
-			Render("+ Add File")
+			Render("+ " + i18n.T("compose.add_file"))
 	}
 	// Show attachment count if any
+	countStyle := lipgloss.NewStyle().Foreground(components.Muted)
 	if len(m.attachments) > 0 {
-		countStyle := lipgloss.NewStyle().Foreground(components.Muted)
-		attachBtn += countStyle.Render(fmt.Sprintf(" (%d attached)", len(m.attachments)))
+		attachBtn += countStyle.Render(" (" + i18n.TPlural("email.attachment_count", len(m.attachments), nil) + ")")
+	}
+	if m.nearAttachmentLimit() {
+		attachBtn += lipgloss.NewStyle().Foreground(components.Warning).Render(
+			"  " + i18n.T("compose.attach_limit", map[string]any{"Size": formatSize(m.totalAttachSize), "Limit": formatSize(m.attachLimit), "Advice": m.attachAdvice}))
+	}
+	if m.focused == focusAttach {
+		attachBtn += countStyle.Render("  " + i18n.T("compose.paste_hint"))
 	}
 	attachLine := attachLabel + " " + attachBtn
 
@@ -655,6 +978,12 @@ func (m ComposeModel) View() string {
 
 	// Body textarea
 	bodySection := m.body.View()
+	if m.speller != nil {
+		bodySection = m.underlineMisspelled(bodySection)
+	}
+	if m.spelling != nil {
+		bodySection = lipgloss.JoinVertical(lipgloss.Left, bodySection, m.renderSpellPopup())
+	}
 
 	// Attachments section
 	var attachSection string
@@ -677,25 +1006,25 @@ func (m ComposeModel) View() string {
 	// Send button
 	var sendBtn string
 	if m.focused == focusSend {
-		sendBtn = btnFocusedStyle.Render("Send")
+		sendBtn = btnFocusedStyle.Render(i18n.T("compose.send"))
 	} else {
-		sendBtn = btnUnfocusedStyle.Render("Send")
+		sendBtn = btnUnfocusedStyle.Render(i18n.T("compose.send"))
 	}
 
 	// Save Draft button
 	var saveDraftBtn string
 	if m.focused == focusSaveDraft {
-		saveDraftBtn = btnFocusedStyle.Render("Save Draft")
+		saveDraftBtn = btnFocusedStyle.Render(i18n.T("compose.save_draft"))
 	} else {
-		saveDraftBtn = btnUnfocusedStyle.Render("Save Draft")
+		saveDraftBtn = btnUnfocusedStyle.Render(i18n.T("compose.save_draft"))
 	}
 
 	// Cancel button
 	var cancelBtn string
 	if m.focused == focusCancel {
-		cancelBtn = btnFocusedStyle.Render("Cancel")
+		cancelBtn = btnFocusedStyle.Render(i18n.T("common.cancel"))
 	} else {
-		cancelBtn = btnUnfocusedStyle.Render("Cancel")
+		cancelBtn = btnUnfocusedStyle.Render(i18n.T("common.cancel"))
 	}
 
 	// Buttons row
@@ -703,7 +1032,14 @@ func (m ComposeModel) View() string {
 
 	// Help hint (always show)
 	hintStyle := lipgloss.NewStyle().Foreground(components.Muted).Italic(true)
-	helpHint := hintStyle.Render("tab: navigate • enter: select")
+	help := i18n.T("compose.nav_hint")
+	if m.focused == focusSubject || m.focused == focusBody {
+		help += " • " + i18n.T("compose.emoji_hint")
+	}
+	if m.focused == focusBody && m.speller != nil {
+		help += " • " + i18n.T("compose.spelling_hint")
+	}
+	helpHint := hintStyle.Render(help)
 
 	// Compose everything
 	var contentParts []string
@@ -728,11 +1064,11 @@ func (m ComposeModel) View() string {
 		Width(containerWidth)
 
 	// Title based on compose type
-	titleText := "Compose"
+	titleText := i18n.T("compose.title")
 	if m.isReplyAll {
-		titleText = "Reply All"
+		titleText = i18n.T("compose.reply_all")
 	} else if m.isReply {
-		titleText = "Reply"
+		titleText = i18n.T("compose.reply")
 	}
 	title := lipgloss.NewStyle().
 		Bold(true).
@@ -764,6 +1100,124 @@ func sanitizeHeaderValue(s string) string {
 	return sb.String()
 }
 
+// SetContacts enables To autocomplete from the address book
+func (m *ComposeModel) SetContacts(list []contacts.Contact) {
+	m.contacts = list
+	m.toInput.ShowSuggestions = len(list) > 0
+	m.updateRecipientSuggestions()
+}
+
+// maxRecipientSuggestions caps how many contacts are offered for one prefix
+const maxRecipientSuggestions = 10
+
+// updateRecipientSuggestions offers contacts matching the recipient being typed.
+// Suggestions repeat the recipients already entered since textinput completes the whole value.
+func (m *ComposeModel) updateRecipientSuggestions() {
+	if len(m.contacts) == 0 {
+		return
+	}
+	value := m.toInput.Value()
+	head := value[:strings.LastIndex(value, ",")+1]
+	token := strings.TrimLeft(value[len(head):], " ")
+	head = value[:len(value)-len(token)]
+	if token == "" {
+		m.toInput.SetSuggestions(nil)
+		return
+	}
+
+	lower := strings.ToLower(token)
+	var suggestions []string
+	for _, ct := range m.contacts {
+		switch {
+		case strings.HasPrefix(strings.ToLower(ct.Email), lower):
+			suggestions = append(suggestions, head+ct.Email)
+		case ct.Name != "" && !strings.ContainsAny(ct.Name, ",<>") && strings.HasPrefix(strings.ToLower(ct.Name), lower):
+			suggestions = append(suggestions, head+ct.String())
+		default:
+			continue
+		}
+		if len(suggestions) == maxRecipientSuggestions {
+			break
+		}
+	}
+	m.toInput.SetSuggestions(suggestions)
+}
+
+// mentionsAttachment reports whether the body refers to an attachment, using the
+// phrases of the UI language. Quoted lines and forwarded messages are skipped.
+func mentionsAttachment(body string) bool {
+	var phrases []string
+	for _, p := range strings.Split(i18n.T("compose.attachment_phrases"), ",") {
+		if p = strings.ToLower(strings.TrimSpace(p)); p != "" {
+			phrases = append(phrases, p)
+		}
+	}
+	for _, line := range strings.Split(body, "\n") {
+		if line == forwardedMarker {
+			break
+		}
+		if strings.HasPrefix(strings.TrimSpace(line), ">") {
+			continue
+		}
+		line = strings.ToLower(line)
+		for _, p := range phrases {
+			if strings.Contains(line, p) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// recipientDomainsCheckedMsg carries the mail server lookups for the recipients in to
+type recipientDomainsCheckedMsg struct {
+	to       string
+	warnings []mail.AddressWarning
+}
+
+// SetDomainCheck turns on mail server lookups for recipient domains before sending
+func (m *ComposeModel) SetDomainCheck(on bool) {
+	m.checkDomains = on
+}
+
+// checkRecipients warns about malformed or mistyped recipients when confirming the
+// send, then looks up their domains in the background when enabled
+func (m *ComposeModel) checkRecipients() tea.Cmd {
+	to := m.toInput.Value()
+	recipients := parseEmailList(to)
+	m.addressWarnings = mail.CheckAddresses(recipients)
+	m.checkingDomains = m.checkDomains && len(recipients) > 0
+	if !m.checkingDomains {
+		return nil
+	}
+	return func() tea.Msg {
+		return recipientDomainsCheckedMsg{to: to, warnings: mail.CheckDomains(context.Background(), recipients)}
+	}
+}
+
+// fixRecipients swaps mistyped recipients for their suggested corrections, reporting
+// whether any changed
+func (m *ComposeModel) fixRecipients() bool {
+	recipients := parseEmailList(m.toInput.Value())
+	fixed := false
+	for _, w := range m.addressWarnings {
+		if w.Suggestion == "" {
+			continue
+		}
+		for i, r := range recipients {
+			if extractEmail(r) == w.Address {
+				recipients[i] = strings.Replace(r, w.Address, w.Suggestion, 1)
+				fixed = true
+			}
+		}
+	}
+	if fixed {
+		m.toInput.SetValue(strings.Join(recipients, ", "))
+		m.updateRecipientSuggestions()
+	}
+	return fixed
+}
+
 // GetTo returns the recipient email (sanitized to prevent header injection)
 func (m ComposeModel) GetTo() string {
 	return sanitizeHeaderValue(m.toInput.Value())
@@ -774,6 +1228,14 @@ func (m ComposeModel) GetSubject() string {
 	return sanitizeHeaderValue(m.subjectInput.Value())
 }
 
+// Title names the email when it is set aside: its subject, or a placeholder
+func (m ComposeModel) Title() string {
+	if subject := strings.TrimSpace(m.GetSubject()); subject != "" {
+		return subject
+	}
+	return i18n.T("compose.no_subject")
+}
+
 // GetOriginalEmail returns the original email being replied to
 func (m ComposeModel) GetOriginalEmail() *mail.Email {
 	return m.replyEmail
@@ -781,9 +1243,9 @@ func (m ComposeModel) GetOriginalEmail() *mail.Email {
 
 // AddAttachment adds a file attachment to the compose model
 func (m *ComposeModel) AddAttachment(path, name, contentType string, size int64) error {
-	if m.totalAttachSize+size > maxAttachmentSize {
-		return fmt.Errorf("total attachments exceed 25MB limit (current: %s, adding: %s)",
-			formatSize(m.totalAttachSize), formatSize(size))
+	if m.totalAttachSize+size > m.attachLimit {
+		return fmt.Errorf("total attachments exceed the %s limit (current: %s, adding: %s) - %s",
+			formatSize(m.attachLimit), formatSize(m.totalAttachSize), formatSize(size), m.attachAdvice)
 	}
 
 	m.attachments = append(m.attachments, ComposeAttachment{
@@ -796,6 +1258,74 @@ func (m *ComposeModel) AddAttachment(path, name, contentType string, size int64)
 	return nil
 }
 
+// attachPastedPaths attaches each pasted path (one per line), checking that it is an
+// existing regular file that fits in the size limit
+func (m *ComposeModel) attachPastedPaths(text string) PastedAttachmentsMsg {
+	var result PastedAttachmentsMsg
+	for _, path := range parsePastedPaths(text) {
+		if !filepath.IsAbs(path) {
+			result.Errors = append(result.Errors, fmt.Sprintf("%s: not an absolute path", path))
+			continue
+		}
+		info, err := os.Stat(path)
+		if err != nil {
+			result.Errors = append(result.Errors, fmt.Sprintf("%s: file not found", path))
+			continue
+		}
+		if !info.Mode().IsRegular() {
+			result.Errors = append(result.Errors, fmt.Sprintf("%s: not a file", path))
+			continue
+		}
+		if err := m.AddAttachment(path, filepath.Base(path), "", info.Size()); err != nil {
+			result.Errors = append(result.Errors, fmt.Sprintf("%s: %v", filepath.Base(path), err))
+			continue
+		}
+		result.Added = append(result.Added, filepath.Base(path))
+	}
+	return result
+}
+
+// parsePastedPaths splits pasted text into paths. Terminals paste dropped files quoted,
+// with backslash-escaped spaces or as file:// URLs, so those forms are unwrapped.
+func parsePastedPaths(text string) []string {
+	var paths []string
+	for _, line := range strings.FieldsFunc(text, func(r rune) bool { return r == '\n' || r == '\r' }) {
+		path := strings.TrimSpace(line)
+		if len(path) >= 2 && (path[0] == '\'' || path[0] == '"') && path[len(path)-1] == path[0] {
+			path = path[1 : len(path)-1]
+		} else {
+			path = strings.ReplaceAll(path, "\\ ", " ")
+		}
+		if strings.HasPrefix(path, "file://") {
+			if u, err := url.Parse(path); err == nil {
+				path = u.Path
+			}
+		}
+		if path == "~" || strings.HasPrefix(path, "~/") {
+			if home, err := os.UserHomeDir(); err == nil {
+				path = filepath.Join(home, path[1:])
+			}
+		}
+		if path != "" {
+			paths = append(paths, path)
+		}
+	}
+	return paths
+}
+
+// SetAttachmentLimit applies the sending account's attachment size limit
+func (m *ComposeModel) SetAttachmentLimit(account *auth.Account) {
+	m.attachLimit = mail.AttachmentLimit(account)
+	if account != nil {
+		m.attachAdvice = mail.AttachmentAdvice(account.Provider)
+	}
+}
+
+// nearAttachmentLimit reports whether the attachments use most of the allowed size
+func (m ComposeModel) nearAttachmentLimit() bool {
+	return float64(m.totalAttachSize) >= float64(m.attachLimit)*mail.AttachmentWarnRatio
+}
+
 // RemoveAttachment removes the attachment at the given index
 func (m *ComposeModel) RemoveAttachment(idx int) {
 	if idx >= 0 && idx < len(m.attachments) {
@@ -840,7 +1370,7 @@ func (m ComposeModel) renderAttachments() string {
 
 	// Header with total size
 	headerStyle := lipgloss.NewStyle().Foreground(components.Muted)
-	header := headerStyle.Render(fmt.Sprintf("Attachments (%d, %s):", len(m.attachments), formatSize(m.totalAttachSize)))
+	header := headerStyle.Render(i18n.T("compose.attachments", map[string]any{"Count": len(m.attachments), "Size": formatSize(m.totalAttachSize)}))
 	parts = append(parts, header)
 
 	// Attachment items
@@ -878,27 +1408,37 @@ func (m ComposeModel) renderAttachments() string {
 	// Add hint when focused on attachments
 	if m.focused == focusAttachments {
 		hintStyle := lipgloss.NewStyle().Foreground(components.Muted).Italic(true)
-		parts = append(parts, hintStyle.Render("  ←/→ navigate • x remove • a add more"))
+		parts = append(parts, hintStyle.Render("  "+i18n.T("compose.attachments_hint")))
 	}
 
 	return lipgloss.JoinVertical(lipgloss.Left, parts...)
 }
 
-// renderConfirmDialog renders a confirmation dialog
-func (m ComposeModel) renderConfirmDialog() string {
-	var title, message string
+// confirmText returns the title, message and button labels of the confirmation dialog
+func (m ComposeModel) confirmText() (title, message, confirmLabel, cancelLabel string) {
+	confirmLabel, cancelLabel = i18n.T("common.confirm"), i18n.T("common.cancel")
 
 	switch m.confirming {
 	case confirmSend:
-		title = "Send Email?"
-		message = "Are you sure you want to send this email?"
+		title = i18n.T("compose.confirm.send_title")
+		message = i18n.T("compose.confirm.send")
 	case confirmSaveDraft:
-		title = "Save Draft?"
-		message = "Save this email as a draft?"
+		title = i18n.T("compose.confirm.draft_title")
+		message = i18n.T("compose.confirm.draft")
 	case confirmCancel:
-		title = "Discard Draft?"
-		message = "Are you sure? Draft will not be saved."
+		title = i18n.T("compose.confirm.discard_title")
+		message = i18n.T("compose.confirm.discard")
+	case confirmAttachment:
+		title = i18n.T("compose.confirm.attachment_title")
+		message = i18n.T("compose.confirm.attachment")
+		confirmLabel, cancelLabel = i18n.T("compose.confirm.attach_file"), i18n.T("compose.confirm.send_anyway")
 	}
+	return title, message, confirmLabel, cancelLabel
+}
+
+// renderConfirmDialog renders a confirmation dialog
+func (m ComposeModel) renderConfirmDialog() string {
+	title, message, confirmLabel, cancelLabel := m.confirmText()
 
 	titleStyle := lipgloss.NewStyle().
 		Bold(true).
@@ -925,22 +1465,23 @@ func (m ComposeModel) renderConfirmDialog() string {
 	// Render buttons based on focus
 	var confirmBtn, cancelBtn string
 	if m.confirmFocused == 0 {
-		confirmBtn = activeButtonStyle.Render("Confirm")
-		cancelBtn = inactiveButtonStyle.Render("Cancel")
+		confirmBtn = activeButtonStyle.Render(confirmLabel)
+		cancelBtn = inactiveButtonStyle.Render(cancelLabel)
 	} else {
-		confirmBtn = inactiveButtonStyle.Render("Confirm")
-		cancelBtn = activeButtonStyle.Render("Cancel")
+		confirmBtn = inactiveButtonStyle.Render(confirmLabel)
+		cancelBtn = activeButtonStyle.Render(cancelLabel)
 	}
 
 	buttons := lipgloss.JoinHorizontal(lipgloss.Center, confirmBtn, cancelBtn)
 
-	content := lipgloss.JoinVertical(
-		lipgloss.Center,
-		titleStyle.Render(title),
-		messageStyle.Render(message),
-		"",
-		buttons,
-	)
+	parts := []string{titleStyle.Render(title), messageStyle.Render(message)}
+	if m.confirming == confirmSend {
+		if warnings := m.renderAddressWarnings(); warnings != "" {
+			parts = append(parts, warnings)
+		}
+	}
+	parts = append(parts, "", buttons)
+	content := lipgloss.JoinVertical(lipgloss.Center, parts...)
 
 	dialogStyle := lipgloss.NewStyle().
 		Border(lipgloss.RoundedBorder()).
@@ -959,3 +1500,23 @@ func (m ComposeModel) renderConfirmDialog() string {
 		dialog,
 	)
 }
+
+// renderAddressWarnings lists the recipients that probably can't receive the email
+func (m ComposeModel) renderAddressWarnings() string {
+	warningStyle := lipgloss.NewStyle().Foreground(components.Warning)
+	hintStyle := lipgloss.NewStyle().Foreground(components.Muted).Italic(true)
+
+	var lines []string
+	canFix := false
+	for _, w := range m.addressWarnings {
+		lines = append(lines, warningStyle.Render("⚠ "+w.String()))
+		canFix = canFix || w.Suggestion != ""
+	}
+	if m.checkingDomains {
+		lines = append(lines, hintStyle.Render(i18n.T("compose.checking_domains")))
+	}
+	if canFix {
+		lines = append(lines, hintStyle.Render(i18n.T("compose.fix_hint")))
+	}
+	return strings.Join(lines, "\n")
+}
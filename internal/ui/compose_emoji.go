@@ -0,0 +1,44 @@
+package ui
+
+import (
+	"strings"
+	"unicode/utf8"
+
+	"github.com/rivo/uniseg"
+)
+
+// OpenEmojiPickerMsg is sent when user presses ctrl+e in the subject or body
+type OpenEmojiPickerMsg struct{}
+
+// InsertSymbol inserts an emoji or other character picked with ctrl+e at the cursor of
+// the subject or body. A cursor inside a grapheme cluster, such as between a letter and
+// its combining accent or within a flag, inserts after the whole cluster.
+func (m *ComposeModel) InsertSymbol(char string) {
+	switch m.focused {
+	case focusSubject:
+		value := []rune(m.subjectInput.Value())
+		pos := graphemeBoundary(value, m.subjectInput.Position())
+		m.subjectInput.SetValue(string(value[:pos]) + char + string(value[pos:]))
+		m.subjectInput.SetCursor(pos + utf8.RuneCountInString(char))
+	case focusBody:
+		line := []rune(strings.Split(m.body.Value(), "\n")[m.body.Line()])
+		info := m.body.LineInfo()
+		m.body.SetCursor(graphemeBoundary(line, info.StartColumn+info.ColumnOffset))
+		m.body.InsertString(char)
+	}
+}
+
+// graphemeBoundary returns col, a rune offset into line, moved to the end of the
+// grapheme cluster it falls inside
+func graphemeBoundary(line []rune, col int) int {
+	pos := 0
+	graphemes := uniseg.NewGraphemes(string(line))
+	for pos < col && graphemes.Next() {
+		n := len(graphemes.Runes())
+		if col < pos+n {
+			return pos + n
+		}
+		pos += n
+	}
+	return col
+}
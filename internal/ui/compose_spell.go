@@ -0,0 +1,180 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+
+	"maily/internal/i18n"
+	"maily/internal/spell"
+	"maily/internal/ui/components"
+)
+
+// maxSpellSuggestions caps how many corrections the spelling popup offers
+const maxSpellSuggestions = 6
+
+// spellPopup holds the corrections offered for the word at the body cursor
+type spellPopup struct {
+	word        string
+	start, end  int // rune bounds of the word in the cursor's line
+	suggestions []string
+	idx         int
+}
+
+// spellerLoadedMsg carries the spell checker loaded when compose first opened, nil
+// when there is no dictionary
+type spellerLoadedMsg struct {
+	checker *spell.Checker
+}
+
+// loadSpeller returns the command loading the spell checker, the first time an email
+// is written, so dictionaries are read only by those who compose
+func (a *App) loadSpeller() tea.Cmd {
+	if a.spellAsked || !a.cfg.SpellCheck.Enabled() {
+		return nil
+	}
+	a.spellAsked = true
+	cfg := a.cfg
+	return func() tea.Msg {
+		return spellerLoadedMsg{checker: loadSpellChecker(cfg)}
+	}
+}
+
+// SetSpellChecker enables spell checking of the body; nil turns it off
+func (m *ComposeModel) SetSpellChecker(c *spell.Checker) {
+	m.speller = c
+}
+
+// openSpellPopup offers corrections for the misspelled word at the body cursor
+func (m *ComposeModel) openSpellPopup() {
+	line, col := m.bodyCursorLine()
+	start, end, ok := spell.WordAt(line, col)
+	if !ok {
+		return
+	}
+	word := string(line[start:end])
+	if m.speller.Check(word) {
+		return
+	}
+	m.spelling = &spellPopup{
+		word:        word,
+		start:       start,
+		end:         end,
+		suggestions: m.speller.Suggest(word, maxSpellSuggestions),
+	}
+}
+
+// updateSpellPopup handles keys while the spelling popup is open
+func (m *ComposeModel) updateSpellPopup(msg tea.KeyMsg) {
+	p := m.spelling
+	switch key := msg.String(); key {
+	case "up", "ctrl+p":
+		if p.idx > 0 {
+			p.idx--
+		}
+	case "down", "ctrl+n":
+		if p.idx < len(p.suggestions)-1 {
+			p.idx++
+		}
+	case "enter":
+		if len(p.suggestions) > 0 {
+			m.replaceBodyWord(p.suggestions[p.idx])
+		}
+		m.spelling = nil
+	case "ctrl+a":
+		// Errors only mean the word isn't remembered next time
+		_ = m.speller.Add(p.word)
+		m.spelling = nil
+	case "esc", "ctrl+l":
+		m.spelling = nil
+	default:
+		if len(key) == 1 && key[0] >= '1' && key[0] <= '9' {
+			if i := int(key[0] - '1'); i < len(p.suggestions) {
+				m.replaceBodyWord(p.suggestions[i])
+				m.spelling = nil
+			}
+		}
+	}
+}
+
+// replaceBodyWord swaps the popup's word in the cursor's line for a correction,
+// leaving the cursor after it
+func (m *ComposeModel) replaceBodyWord(correction string) {
+	p := m.spelling
+	m.body.SetCursor(p.end)
+	for range p.end - p.start {
+		m.body, _ = m.body.Update(tea.KeyMsg{Type: tea.KeyBackspace})
+	}
+	m.body.InsertString(correction)
+}
+
+// bodyCursorLine returns the body line holding the cursor and the cursor's rune offset
+// in it. ColumnOffset counts runes; CharOffset would count display columns, which
+// differ after wide characters.
+func (m ComposeModel) bodyCursorLine() ([]rune, int) {
+	lines := strings.Split(m.body.Value(), "\n")
+	row := min(m.body.Line(), len(lines)-1)
+	info := m.body.LineInfo()
+	return []rune(lines[row]), info.StartColumn + info.ColumnOffset
+}
+
+// underlineMisspelled marks misspelled words in the rendered body, skipping quoted
+// lines and the word still being typed
+func (m ComposeModel) underlineMisspelled(view string) string {
+	typing := ""
+	if m.focused == focusBody {
+		line, col := m.bodyCursorLine()
+		if start, end, ok := spell.WordAt(line, col); ok && end == col {
+			typing = string(line[start:end])
+		}
+	}
+	return components.UnderlineMisspelled(view, bodyWords, func(word string) bool {
+		return word != typing && !m.speller.Check(word)
+	})
+}
+
+// bodyWords finds the words to check in a rendered body row, past the textarea's
+// prompt and line number. Quoted lines of the email being replied to aren't checked.
+func bodyWords(text []rune) [][2]int {
+	for _, r := range text {
+		if unicode.IsSpace(r) || unicode.IsDigit(r) || r == '┃' {
+			continue
+		}
+		if r == '>' {
+			return nil
+		}
+		break
+	}
+	return spell.Words(text)
+}
+
+// renderSpellPopup lists the corrections for the word at the cursor
+func (m ComposeModel) renderSpellPopup() string {
+	p := m.spelling
+	titleStyle := lipgloss.NewStyle().Bold(true).Foreground(components.Primary)
+	selectedStyle := lipgloss.NewStyle().Foreground(components.Primary).Bold(true)
+	mutedStyle := lipgloss.NewStyle().Foreground(components.Muted)
+
+	lines := []string{titleStyle.Render(i18n.T("spell.title", map[string]any{"Word": p.word}))}
+	if len(p.suggestions) == 0 {
+		lines = append(lines, mutedStyle.Render(i18n.T("spell.no_suggestions")))
+	}
+	for i, s := range p.suggestions {
+		line := fmt.Sprintf("  %d %s", i+1, s)
+		if i == p.idx {
+			line = selectedStyle.Render(fmt.Sprintf("> %d %s", i+1, s))
+		}
+		lines = append(lines, line)
+	}
+	lines = append(lines, mutedStyle.Italic(true).Render(
+		i18n.T("spell.hint", map[string]any{"Count": max(len(p.suggestions), 1), "Dictionary": m.speller.Name()})))
+
+	return lipgloss.NewStyle().
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(components.Primary).
+		Padding(0, 1).
+		Render(strings.Join(lines, "\n"))
+}
@@ -0,0 +1,270 @@
+package ui
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"maily/internal/auth"
+	"maily/internal/cache"
+	"maily/internal/i18n"
+	"maily/internal/mail"
+	"maily/internal/ui/components"
+)
+
+// Several emails can be written at once: Esc sets the one being written aside, C brings
+// it back, or opens a switcher when more than one is waiting. Each email is autosaved on
+// its own into the disk cache while it changes, and deleted from there once it is sent,
+// saved as a server draft or discarded; emails left when the TUI quits come back set
+// aside the next time it starts.
+
+const draftAutosaveInterval = 5 * time.Second
+
+type draftAutosaveTickMsg struct{}
+
+// draftState is what is autosaved of an email being written
+type draftState struct {
+	From        string              `json:"from"`
+	To          string              `json:"to,omitempty"`
+	Subject     string              `json:"subject,omitempty"`
+	Body        string              `json:"body,omitempty"`
+	QuotedBody  string              `json:"quoted_body,omitempty"` // reply quote not yet placed in the body
+	Reply       bool                `json:"reply,omitempty"`
+	ReplyAll    bool                `json:"reply_all,omitempty"`
+	BottomPost  bool                `json:"bottom_post,omitempty"`
+	Original    *mail.Email         `json:"original,omitempty"` // without its body, which the quote holds
+	Attachments []ComposeAttachment `json:"attachments,omitempty"`
+	Focused     int                 `json:"focused,omitempty"`
+}
+
+func scheduleDraftAutosave() tea.Cmd {
+	return tea.Tick(draftAutosaveInterval, func(time.Time) tea.Msg {
+		return draftAutosaveTickMsg{}
+	})
+}
+
+// encodeDraft returns the email's autosaved form
+func (m ComposeModel) encodeDraft() string {
+	state := draftState{
+		From:        m.from,
+		To:          m.toInput.Value(),
+		Subject:     m.subjectInput.Value(),
+		Body:        m.body.Value(),
+		QuotedBody:  m.quotedBody,
+		Reply:       m.isReply,
+		ReplyAll:    m.isReplyAll,
+		BottomPost:  m.bottomPost,
+		Attachments: m.attachments,
+		Focused:     m.focused,
+	}
+	if m.replyEmail != nil {
+		original := *m.replyEmail
+		original.BodyHTML, original.Snippet, original.Attachments = "", "", nil
+		state.Original = &original
+	}
+	data, _ := json.Marshal(state)
+	return string(data)
+}
+
+// decodeDraft rebuilds an email from its autosaved form
+func decodeDraft(draft cache.LocalDraft) (ComposeModel, error) {
+	var state draftState
+	if err := json.Unmarshal([]byte(draft.Data), &state); err != nil {
+		return ComposeModel{}, fmt.Errorf("draft %s: %w", draft.ID, err)
+	}
+	m := NewComposeModel(state.From)
+	if state.ReplyAll {
+		m.toInput.CharLimit = 500
+	}
+	m.toInput.SetValue(state.To)
+	m.subjectInput.SetValue(state.Subject)
+	m.body.SetValue(state.Body)
+	m.quotedBody = state.QuotedBody
+	m.bodyPending = state.QuotedBody != ""
+	m.isReply, m.isReplyAll, m.bottomPost = state.Reply, state.ReplyAll, state.BottomPost
+	m.replyEmail = state.Original
+	if m.isReply {
+		m.body.Placeholder = i18n.T("compose.placeholder.reply_body")
+	}
+	for _, attachment := range state.Attachments {
+		m.attachments = append(m.attachments, attachment)
+		m.totalAttachSize += attachment.Size
+	}
+	m.focusField(state.Focused)
+	m.draftID, m.autosaved, m.autosavedAt = draft.ID, draft.Data, draft.UpdatedAt
+	return m, nil
+}
+
+// loadDrafts returns the emails autosaved by earlier runs
+func loadDrafts(c *cache.Cache) []ComposeModel {
+	if c == nil {
+		return nil
+	}
+	saved, err := c.LocalDrafts()
+	if err != nil {
+		return nil
+	}
+	var drafts []ComposeModel
+	for _, draft := range saved {
+		if m, err := decodeDraft(draft); err == nil {
+			drafts = append(drafts, m)
+		}
+	}
+	return drafts
+}
+
+// autosave returns the command saving the email if it changed since it was last saved
+func (m *ComposeModel) autosave(c *cache.Cache) tea.Cmd {
+	data := m.encodeDraft()
+	if data == m.autosaved {
+		return nil
+	}
+	if m.draftID == "" {
+		m.draftID = fmt.Sprintf("%d", time.Now().UnixNano())
+	}
+	m.autosaved, m.autosavedAt = data, time.Now()
+	draft := cache.LocalDraft{ID: m.draftID, Account: m.from, Data: data, UpdatedAt: m.autosavedAt}
+	return func() tea.Msg {
+		c.SaveLocalDraft(draft)
+		return nil
+	}
+}
+
+// autosaveDrafts saves the email being written and those set aside that changed
+func (a *App) autosaveDrafts() tea.Cmd {
+	if a.diskCache == nil {
+		return nil
+	}
+	var cmds []tea.Cmd
+	if a.view == composeView && a.state != stateLoading {
+		cmds = append(cmds, a.compose.autosave(a.diskCache))
+	}
+	for i := range a.drafts {
+		cmds = append(cmds, a.drafts[i].autosave(a.diskCache))
+	}
+	return tea.Batch(cmds...)
+}
+
+// saveDrafts autosaves the emails being written right away, before quitting
+func (a *App) saveDrafts() {
+	if cmd := a.autosaveDrafts(); cmd != nil {
+		cmd()
+	}
+}
+
+// dropDraft deletes the autosaved copy of an email that was sent, saved or discarded
+func (a App) dropDraft(m ComposeModel) tea.Cmd {
+	if a.diskCache == nil || m.draftID == "" {
+		return nil
+	}
+	c, id := a.diskCache, m.draftID
+	return func() tea.Msg {
+		c.DeleteLocalDraft(id)
+		return nil
+	}
+}
+
+// composeAccount returns the account the email being written is sent from, which is no
+// longer the current one when it is resumed after switching accounts
+func (a App) composeAccount() *auth.Account {
+	if account := a.store.GetAccount(a.compose.from); account != nil {
+		return account
+	}
+	return a.currentAccount()
+}
+
+// setComposeAside moves the email being written to the set aside ones
+func (a *App) setComposeAside() {
+	a.drafts = append(a.drafts, a.compose)
+	a.statusMsg = i18n.T("compose.minimized")
+	if a.compose.isReply {
+		a.view = readView
+	} else {
+		a.view = listView
+	}
+}
+
+// resumeDraft brings back a set aside email where it was left
+func (a *App) resumeDraft(i int) tea.Cmd {
+	a.compose = a.drafts[i]
+	a.drafts = append(a.drafts[:i:i], a.drafts[i+1:]...)
+	a.showDrafts = false
+
+	// Emails restored from an earlier run have no address book or limits yet
+	a.prepareCompose(a.composeAccount())
+	a.view = composeView
+	a.statusMsg = ""
+	return tea.Batch(a.compose.focusField(a.compose.focused), a.loadSpeller())
+}
+
+// openDrafts resumes the only set aside email, or lets the user pick one
+func (a *App) openDrafts() tea.Cmd {
+	if len(a.drafts) == 1 {
+		return a.resumeDraft(0)
+	}
+	a.showDrafts = true
+	a.draftIdx = len(a.drafts) - 1 // the one set aside last
+	a.dropPending = false
+	return nil
+}
+
+// handleDraftsKey handles the drafts switcher
+func (a *App) handleDraftsKey(msg tea.KeyMsg) tea.Cmd {
+	if a.dropPending {
+		a.dropPending = false
+		if msg.String() != "y" {
+			return nil
+		}
+		// Discard the highlighted email
+		dropped := a.drafts[a.draftIdx]
+		a.drafts = append(a.drafts[:a.draftIdx:a.draftIdx], a.drafts[a.draftIdx+1:]...)
+		a.draftIdx = min(a.draftIdx, len(a.drafts)-1)
+		a.showDrafts = len(a.drafts) > 0
+		a.statusMsg = i18n.T("compose.discarded", map[string]any{"Subject": dropped.Title()})
+		return a.dropDraft(dropped)
+	}
+
+	switch msg.String() {
+	case "up", "k", "shift+tab":
+		if a.draftIdx > 0 {
+			a.draftIdx--
+		}
+	case "down", "j", "tab":
+		if a.draftIdx < len(a.drafts)-1 {
+			a.draftIdx++
+		}
+	case "enter":
+		return a.resumeDraft(a.draftIdx)
+	case "d", "x":
+		// Ask before discarding the highlighted email
+		a.dropPending = true
+	case "esc", "q", "C":
+		a.showDrafts = false
+	}
+	return nil
+}
+
+// draftInfos describes the set aside emails for the switcher
+func (a App) draftInfos() []components.DraftInfo {
+	infos := make([]components.DraftInfo, len(a.drafts))
+	for i, m := range a.drafts {
+		infos[i] = components.DraftInfo{
+			Title: m.Title(),
+			To:    m.toInput.Value(),
+			From:  m.from,
+			Saved: m.autosavedAt,
+		}
+	}
+	return infos
+}
+
+// draftTitles returns the titles of the set aside emails, for the status bar chip
+func (a App) draftTitles() []string {
+	titles := make([]string, len(a.drafts))
+	for i, m := range a.drafts {
+		titles[i] = m.Title()
+	}
+	return titles
+}
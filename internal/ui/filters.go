@@ -0,0 +1,53 @@
+package ui
+
+import (
+	"strings"
+
+	"maily/internal/i18n"
+	"maily/internal/ui/components"
+)
+
+// Quick filters narrow the loaded list to unread (u), flagged (*) or attachment-carrying
+// (p) emails without going back to the cache or server; they combine, and pressing a key
+// again turns its filter off. They stay on across folders until turned off.
+
+// filterNames are the message ids naming each quick filter, in the order they are listed
+var filterNames = []struct {
+	filter components.ListFilter
+	name   string
+}{
+	{components.FilterUnread, "list.filter_unread"},
+	{components.FilterFlagged, "list.filter_flagged"},
+	{components.FilterAttachments, "list.filter_attachments"},
+}
+
+// canFilter reports whether the quick filter keys act on the list
+func (a App) canFilter() bool {
+	return a.state == stateReady && a.view == listView && !a.confirmDelete && !a.searchMode
+}
+
+// toggleFilter turns a quick filter on or off and reports what the list shows
+func (a *App) toggleFilter(filter components.ListFilter) {
+	a.mailList.ToggleFilter(filter)
+	a.statusMsg = a.filterStatus()
+}
+
+// filterStatus describes the quick filters on and how many emails they leave listed
+func (a App) filterStatus() string {
+	count := len(a.mailList.Emails())
+	active := a.mailList.Filter()
+	if active == 0 {
+		return i18n.T("list.filter_off", map[string]any{"Count": count})
+	}
+	var names []string
+	for _, f := range filterNames {
+		if active&f.filter != 0 {
+			names = append(names, i18n.T(f.name))
+		}
+	}
+	return i18n.T("list.filter_on", map[string]any{
+		"Shown":   a.mailList.Matching(),
+		"Count":   count,
+		"Filters": strings.Join(names, ", "),
+	})
+}
@@ -0,0 +1,146 @@
+package ui
+
+import (
+	"strings"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	"github.com/charmbracelet/bubbles/viewport"
+	tea "github.com/charmbracelet/bubbletea"
+
+	"maily/internal/i18n"
+	"maily/internal/ui/components"
+)
+
+// bodyFinder searches within the opened email, highlighting matches in the viewport
+type bodyFinder struct {
+	input   textinput.Model
+	typing  bool     // find bar has focus
+	terms   []string // highlighted terms: the typed query, or search result terms
+	content string   // rendered email without highlights
+	matches []int    // viewport line of each match
+	current int      // index into matches
+}
+
+func newBodyFinder() bodyFinder {
+	ti := textinput.New()
+	ti.Prompt = "/"
+	ti.CharLimit = 200
+	ti.Width = 30
+	return bodyFinder{input: ti}
+}
+
+// Reset starts over for a newly opened email, highlighting terms (may be nil)
+func (f *bodyFinder) Reset(terms []string) {
+	f.input.Blur()
+	f.input.SetValue("")
+	f.typing = false
+	f.terms = terms
+	f.content = ""
+	f.matches = nil
+	f.current = 0
+}
+
+// SetContent puts rendered email content into the viewport with the terms highlighted
+func (f *bodyFinder) SetContent(vp *viewport.Model, content string) {
+	f.content = content
+	f.apply(vp)
+}
+
+func (f *bodyFinder) apply(vp *viewport.Model) {
+	highlighted, matches := components.HighlightMatches(f.content, f.terms, f.current)
+	if f.current >= len(matches) && len(matches) > 0 {
+		f.current = 0
+		highlighted, matches = components.HighlightMatches(f.content, f.terms, f.current)
+	}
+	f.matches = matches
+	vp.SetContent(highlighted)
+}
+
+// Start focuses the find bar
+func (f *bodyFinder) Start() tea.Cmd {
+	f.typing = true
+	f.input.SetValue("")
+	return f.input.Focus()
+}
+
+// HandleKey handles a key while the find bar has focus: enter searches, esc cancels
+func (f *bodyFinder) HandleKey(msg tea.KeyMsg, vp *viewport.Model) tea.Cmd {
+	switch msg.String() {
+	case "enter":
+		f.typing = false
+		f.input.Blur()
+		f.terms = nil
+		if query := strings.TrimSpace(f.input.Value()); query != "" {
+			f.terms = []string{query}
+		}
+		f.current = 0
+		f.apply(vp)
+		f.scrollToCurrent(vp)
+		return nil
+	case "esc":
+		f.typing = false
+		f.input.Blur()
+		return nil
+	}
+	var cmd tea.Cmd
+	f.input, cmd = f.input.Update(msg)
+	return cmd
+}
+
+// Next jumps to the next (delta 1) or previous (delta -1) match, wrapping around
+func (f *bodyFinder) Next(vp *viewport.Model, delta int) {
+	if len(f.matches) == 0 {
+		return
+	}
+	f.current = (f.current + delta + len(f.matches)) % len(f.matches)
+	f.apply(vp)
+	f.scrollToCurrent(vp)
+}
+
+// scrollToCurrent scrolls the current match into view, a third of the way down
+func (f *bodyFinder) scrollToCurrent(vp *viewport.Model) {
+	if len(f.matches) == 0 {
+		return
+	}
+	line := f.matches[f.current]
+	if line < vp.YOffset || line >= vp.YOffset+vp.Height {
+		vp.SetYOffset(max(0, line-vp.Height/3))
+	}
+}
+
+// Clear removes the highlights; false if there were none
+func (f *bodyFinder) Clear(vp *viewport.Model) bool {
+	if len(f.terms) == 0 {
+		return false
+	}
+	f.terms = nil
+	f.matches = nil
+	f.current = 0
+	vp.SetContent(f.content)
+	return true
+}
+
+// Active reports whether terms are highlighted, so n/N jump between matches
+func (f bodyFinder) Active() bool {
+	return len(f.terms) > 0
+}
+
+// View renders the find bar: the input while typing, then the match position
+func (f bodyFinder) View() string {
+	if f.typing {
+		return f.input.View() + components.HelpDescStyle.Render("  enter "+i18n.T("find.search")+"  esc "+i18n.T("help.back"))
+	}
+	if len(f.terms) == 0 {
+		return ""
+	}
+
+	query := components.HelpKeyStyle.Render("/" + strings.Join(f.terms, " "))
+	if len(f.matches) == 0 {
+		return query + components.HelpDescStyle.Render("  "+i18n.T("find.no_matches")+"  ") +
+			components.HelpKeyStyle.Render("esc") + components.HelpDescStyle.Render(" "+i18n.T("find.clear"))
+	}
+	position := i18n.T("find.position", map[string]any{"Current": f.current + 1, "Total": len(f.matches)})
+	return query + components.HelpDescStyle.Render("  "+position+"  ") +
+		components.HelpKeyStyle.Render("n/N") + components.HelpDescStyle.Render(" "+i18n.T("find.next_prev")+"  ") +
+		components.HelpKeyStyle.Render("esc") + components.HelpDescStyle.Render(" "+i18n.T("find.clear"))
+}
@@ -0,0 +1,87 @@
+package ui
+
+import (
+	"strconv"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"maily/internal/calendar"
+	"maily/internal/i18n"
+	"maily/internal/ui/components"
+)
+
+// freeSlotOptions is how many free slots are offered instead of a conflicting time
+const freeSlotOptions = 3
+
+// slotCheck is what the calendar already holds at a new event's time: the events it
+// overlaps and the nearest free slots to move it to
+type slotCheck struct {
+	start, end time.Time
+	conflicts  []calendar.Event
+	free       []time.Time
+}
+
+// slotCheckedMsg carries the result of checking a new event's time against the calendar
+type slotCheckedMsg struct {
+	check slotCheck
+}
+
+// checkSlot looks up the events around a new event's time. A calendar that can't be
+// read doesn't stop the event from being created, so errors leave the check empty.
+func checkSlot(client calendar.Client, start, end time.Time) tea.Cmd {
+	if client == nil {
+		return nil
+	}
+	return func() tea.Msg {
+		day := time.Date(start.Year(), start.Month(), start.Day(), 0, 0, 0, 0, start.Location())
+		events, err := client.ListEvents(day, day.AddDate(0, 0, 2))
+		if err != nil {
+			return slotCheckedMsg{}
+		}
+		check := slotCheck{start: start, end: end, conflicts: calendar.Conflicts(events, start, end)}
+		if len(check.conflicts) > 0 {
+			check.free = calendar.FreeSlots(events, start, end, time.Now(), freeSlotOptions)
+		}
+		return slotCheckedMsg{check: check}
+	}
+}
+
+// conflicting reports whether the check found conflicts at start to end, the event's
+// current time rather than one it was moved away from
+func (c slotCheck) conflicting(start, end time.Time) bool {
+	return len(c.conflicts) > 0 && c.start.Equal(start) && c.end.Equal(end)
+}
+
+// pick returns the event's time moved to the free slot numbered by key, 1 for the first
+func (c slotCheck) pick(key string) (start, end time.Time, ok bool) {
+	n, err := strconv.Atoi(key)
+	if err != nil || n < 1 || n > len(c.free) {
+		return time.Time{}, time.Time{}, false
+	}
+	start = c.free[n-1]
+	return start, start.Add(c.end.Sub(c.start)), true
+}
+
+// labels returns the conflicting events and free slots as shown in the warning
+func (c slotCheck) labels() (conflicts, free []string) {
+	for _, e := range c.conflicts {
+		conflicts = append(conflicts, e.Title+"  "+i18n.FormatClock(e.StartTime)+" - "+i18n.FormatClock(e.EndTime))
+	}
+	for _, t := range c.free {
+		label := i18n.FormatClock(t)
+		if t.YearDay() != c.start.YearDay() {
+			label = i18n.T("calendar.next_day", map[string]any{"Time": label})
+		}
+		free = append(free, label)
+	}
+	return conflicts, free
+}
+
+// view renders the conflict warning, empty when the time is free
+func (c slotCheck) view(start, end time.Time) string {
+	if !c.conflicting(start, end) {
+		return ""
+	}
+	return components.RenderSlotConflicts(c.labels())
+}
@@ -0,0 +1,268 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/emersion/go-imap/v2"
+
+	"maily/internal/ai"
+	"maily/internal/cache"
+	"maily/internal/dateparse"
+	"maily/internal/i18n"
+	"maily/internal/mail"
+	"maily/internal/ui/components"
+)
+
+const (
+	// gotoFetchLimit is how many older emails each round fetches from the server while
+	// looking for a date before everything cached
+	gotoFetchLimit = 500
+	// gotoMaxRounds bounds those rounds, so a date before the mailbox began stops
+	gotoMaxRounds = 10
+)
+
+// dateJumper is the "go to date" bar of the mail list
+type dateJumper struct {
+	input  textinput.Model
+	typing bool
+}
+
+func newDateJumper() dateJumper {
+	ti := textinput.New()
+	ti.Prompt = i18n.T("goto.prompt") + " "
+	ti.Placeholder = "2024-12-01, last monday"
+	ti.CharLimit = 100
+	ti.Width = 30
+	return dateJumper{input: ti}
+}
+
+// Start focuses the bar
+func (j *dateJumper) Start() tea.Cmd {
+	j.typing = true
+	j.input.SetValue("")
+	return j.input.Focus()
+}
+
+// View renders the bar in place of the help line
+func (j dateJumper) View() string {
+	if !j.typing {
+		return ""
+	}
+	return j.input.View() + components.HelpDescStyle.Render("  enter "+i18n.T("goto.jump")+"  esc "+i18n.T("help.back"))
+}
+
+// dateJumpedMsg carries the window of the list starting at the first email on or
+// before a date
+type dateJumpedMsg struct {
+	date         time.Time
+	emails       []mail.Email
+	offset       int
+	total        int
+	uid          imap.UID // email to put the cursor on
+	found        bool     // false when every email is newer, uid is then the oldest
+	accountEmail string
+	mailbox      string
+	err          error
+}
+
+// dateParsedMsg carries a date the AI read from the go to date input
+type dateParsedMsg struct {
+	date time.Time
+	err  error
+}
+
+// handleGotoKey handles a key while the go to date bar has focus
+func (a *App) handleGotoKey(msg tea.KeyMsg) tea.Cmd {
+	j := &a.jumper
+	switch msg.String() {
+	case "esc":
+		j.typing = false
+		j.input.Blur()
+		return nil
+	case "enter":
+		input := strings.TrimSpace(j.input.Value())
+		if input == "" {
+			return nil
+		}
+		j.typing = false
+		j.input.Blur()
+		if date, ok := parseDate(input, time.Now()); ok {
+			return a.startJump(date)
+		}
+		if !a.aiClient.Available() {
+			a.statusMsg = i18n.T("goto.unknown_date", map[string]any{"Input": input})
+			return nil
+		}
+		a.state = stateLoading
+		a.statusMsg = i18n.T("goto.parsing", map[string]any{"Provider": a.aiClient.Provider()})
+		return tea.Batch(a.spinner.Tick, a.parseDateWithAI(input))
+	}
+	var cmd tea.Cmd
+	j.input, cmd = j.input.Update(msg)
+	return cmd
+}
+
+// startJump shows progress and loads the list at a date
+func (a *App) startJump(date time.Time) tea.Cmd {
+	cmd := a.jumpToDate(date)
+	if cmd == nil {
+		a.state = stateReady
+		return nil
+	}
+	a.state = stateLoading
+	a.paging = true
+	a.statusMsg = i18n.T("goto.searching", map[string]any{"Date": date.Format("Mon, Jan 2 2006")})
+	return tea.Batch(a.spinner.Tick, cmd)
+}
+
+// parseDateWithAI asks the AI for the date in input the local parser didn't understand
+func (a App) parseDateWithAI(input string) tea.Cmd {
+	aiClient := a.aiClient
+	return func() tea.Msg {
+		response, err := aiClient.Call(ai.ParseDatePrompt(input, time.Now()))
+		if err != nil {
+			return dateParsedMsg{err: err}
+		}
+		date, err := ai.ParseDateResponse(response)
+		return dateParsedMsg{date: date, err: err}
+	}
+}
+
+// jumpToDate finds the position of the first email on or before date in the cache,
+// fetching older mail from the server while the date is older than everything cached,
+// and loads the page starting there
+func (a App) jumpToDate(date time.Time) tea.Cmd {
+	account := a.currentAccount()
+	if account == nil || a.diskCache == nil {
+		return nil
+	}
+	accountEmail := account.Credentials.Email
+	mailbox := a.currentLabel
+	serverClient := a.serverClient
+	diskCache := a.diskCache
+	limit := a.cfg.MaxEmails
+	// Everything received before the next day counts as on or before the date
+	end := time.Date(date.Year(), date.Month(), date.Day()+1, 0, 0, 0, 0, time.Local)
+
+	return func() tea.Msg {
+		msg := dateJumpedMsg{date: date, accountEmail: accountEmail, mailbox: mailbox}
+		var offset, total int
+		for round := 0; ; round++ {
+			if offset, msg.err = diskCache.CountNewer(accountEmail, mailbox, end); msg.err != nil {
+				return msg
+			}
+			if total, msg.err = diskCache.CountEmails(accountEmail, mailbox); msg.err != nil {
+				return msg
+			}
+			if offset < total || serverClient == nil || round == gotoMaxRounds {
+				break
+			}
+			fetched, err := serverClient.LoadOlder(accountEmail, mailbox, gotoFetchLimit)
+			if err != nil {
+				msg.err = err
+				return msg
+			}
+			if fetched == 0 {
+				break
+			}
+		}
+		if total == 0 {
+			msg.err = fmt.Errorf("no emails in %s", mailbox)
+			return msg
+		}
+
+		msg.found = offset < total
+		if !msg.found {
+			// Nothing that old: show the oldest email instead
+			offset = total - 1
+		}
+		emails, total, err := loadEmailPage(serverClient, diskCache, accountEmail, mailbox, offset, limit, cache.SortDate)
+		if err != nil || len(emails) == 0 {
+			msg.err = err
+			return msg
+		}
+		msg.emails, msg.offset, msg.total, msg.uid = emails, offset, total, emails[0].UID
+		return msg
+	}
+}
+
+// applyJump replaces the list window with the page starting at the jumped-to email
+func (a *App) applyJump(msg dateJumpedMsg) {
+	a.paging = false
+	a.state = stateReady
+	day := msg.date.Format("Mon, Jan 2 2006")
+	if msg.err != nil || len(msg.emails) == 0 {
+		a.statusMsg = i18n.T("goto.failed", map[string]any{"Date": day, "Error": msg.err})
+		return
+	}
+	a.mailList.SetEmails(msg.emails)
+	a.mailList.SelectUID(msg.uid)
+	a.pageOffset = msg.offset
+	a.mailboxTotal = msg.total
+	a.emailLimit = uint32(max(len(msg.emails), a.cfg.MaxEmails))
+	if msg.found {
+		a.statusMsg = i18n.T("goto.jumped", map[string]any{"Date": day})
+	} else {
+		a.statusMsg = i18n.T("goto.none_before", map[string]any{"Date": day})
+	}
+}
+
+// parseDate reads the dates people type most: 2024-12-01, Dec 1 2024, 1/12, today,
+// yesterday, monday, last monday, 3 days ago, last week. A day without a year is the
+// most recent one. Other input is left to the AI.
+func parseDate(input string, now time.Time) (time.Time, bool) {
+	words := strings.Fields(strings.ToLower(input))
+	if len(words) == 0 {
+		return time.Time{}, false
+	}
+	for i, w := range words {
+		words[i] = strings.TrimRight(w, ",")
+	}
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+
+	if date, n := dateparse.Date(words, today, dateparse.Past, i18n.DayFirst()); n == len(words) {
+		return date, true
+	}
+
+	switch strings.Join(words, " ") {
+	case "today":
+		return today, true
+	case "yesterday":
+		return today.AddDate(0, 0, -1), true
+	case "last week":
+		return today.AddDate(0, 0, -7), true
+	case "last month":
+		return today.AddDate(0, -1, 0), true
+	case "last year":
+		return today.AddDate(-1, 0, 0), true
+	}
+
+	// monday, last monday: the most recent one before today
+	if words[0] == "last" {
+		words = words[1:]
+	}
+	if len(words) == 1 {
+		if day, ok := dateparse.Weekday(words[0]); ok {
+			return dateparse.WeekdayFrom(today, day, dateparse.Past, true), true
+		}
+	}
+
+	// 3 days ago, 2 weeks ago, a month ago
+	if n, unit, k := dateparse.Amount(words); k > 0 && k == len(words)-1 && words[k] == "ago" && n == float64(int(n)) {
+		switch unit {
+		case 24 * time.Hour:
+			return today.AddDate(0, 0, -int(n)), true
+		case 7 * 24 * time.Hour:
+			return today.AddDate(0, 0, -7*int(n)), true
+		case dateparse.MonthUnit:
+			return today.AddDate(0, -int(n), 0), true
+		case dateparse.YearUnit:
+			return today.AddDate(-int(n), 0, 0), true
+		}
+	}
+	return time.Time{}, false
+}
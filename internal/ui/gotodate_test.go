@@ -0,0 +1,90 @@
+package ui
+
+import (
+	"testing"
+	"time"
+
+	"maily/internal/i18n"
+)
+
+func TestParseDate(t *testing.T) {
+	// A Wednesday afternoon
+	now := time.Date(2025, 3, 5, 15, 30, 0, 0, time.UTC)
+	day := func(y int, m time.Month, d int) time.Time {
+		return time.Date(y, m, d, 0, 0, 0, 0, time.UTC)
+	}
+
+	tests := []struct {
+		input string
+		want  time.Time // zero when the input isn't understood
+	}{
+		// Relative
+		{"today", day(2025, 3, 5)},
+		{"Yesterday", day(2025, 3, 4)},
+		{"last week", day(2025, 2, 26)},
+		{"last month", day(2025, 2, 5)},
+		{"last year", day(2024, 3, 5)},
+		{"monday", day(2025, 3, 3)},
+		{"last monday", day(2025, 3, 3)},
+		{"wednesday", day(2025, 2, 26)},
+		{"fri", day(2025, 2, 28)},
+		{"3 days ago", day(2025, 3, 2)},
+		{"a day ago", day(2025, 3, 4)},
+		{"2 weeks ago", day(2025, 2, 19)},
+		{"a month ago", day(2025, 2, 5)},
+		{"2 years ago", day(2023, 3, 5)},
+
+		// Absolute
+		{"2024-12-01", day(2024, 12, 1)},
+		{"2024/12/01", day(2024, 12, 1)},
+		{"Dec 1 2024", day(2024, 12, 1)},
+		{"Dec 1, 2024", day(2024, 12, 1)},
+		{"1 december", day(2024, 12, 1)},
+		{"the 1st of december", time.Time{}},
+		{"1st of december", day(2024, 12, 1)},
+		{"march 5", day(2025, 3, 5)},
+		{"march 6", day(2024, 3, 6)},
+		{"12/1", day(2024, 12, 1)},
+		{"2/28/2025", day(2025, 2, 28)},
+
+		// Not dates
+		{"", time.Time{}},
+		{"   ", time.Time{}},
+		{"the week before christmas", time.Time{}},
+		{"2025-02-30", time.Time{}},
+		{"13/45", time.Time{}},
+		{"1.5 days ago", time.Time{}},
+		{"3 days", time.Time{}},
+		{"in 3 days", time.Time{}},
+		{"dec 1 2024 please", time.Time{}},
+		{"last", time.Time{}},
+	}
+	for _, tt := range tests {
+		got, ok := parseDate(tt.input, now)
+		if ok != !tt.want.IsZero() || !got.Equal(tt.want) {
+			t.Errorf("parseDate(%q) = %s, %v; want %s", tt.input, got.Format(time.DateOnly), ok, tt.want.Format(time.DateOnly))
+		}
+	}
+}
+
+func TestParseDateDayFirst(t *testing.T) {
+	t.Cleanup(func() { i18n.Init("en") })
+	if err := i18n.Init("de"); err != nil {
+		t.Fatal(err)
+	}
+	now := time.Date(2025, 3, 5, 15, 30, 0, 0, time.UTC)
+
+	tests := map[string]time.Time{
+		"1.12.2024":   time.Date(2024, 12, 1, 0, 0, 0, 0, time.UTC),
+		"12/1":        time.Date(2025, 1, 12, 0, 0, 0, 0, time.UTC),
+		"1. dezember": time.Date(2024, 12, 1, 0, 0, 0, 0, time.UTC),
+		"montag":      time.Date(2025, 3, 3, 0, 0, 0, 0, time.UTC),
+		"3 days ago":  time.Date(2025, 3, 2, 0, 0, 0, 0, time.UTC),
+		"2024-12-01":  time.Date(2024, 12, 1, 0, 0, 0, 0, time.UTC),
+	}
+	for input, want := range tests {
+		if got, ok := parseDate(input, now); !ok || !got.Equal(want) {
+			t.Errorf("parseDate(%q) = %s, %v in German; want %s", input, got.Format(time.DateOnly), ok, want.Format(time.DateOnly))
+		}
+	}
+}
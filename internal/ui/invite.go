@@ -0,0 +1,104 @@
+package ui
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/textinput"
+
+	"maily/internal/auth"
+	"maily/internal/calendar"
+	"maily/internal/client"
+	"maily/internal/i18n"
+)
+
+// newAttendeesInput returns the text input for an event's attendees
+func newAttendeesInput() textinput.Model {
+	input := textinput.New()
+	input.Placeholder = i18n.T("calendar.attendees_placeholder")
+	input.CharLimit = 500
+	input.Width = 40
+	return input
+}
+
+// sendInvitations has the server email an invitation to the event to the attendees in
+// to, from account; the invitation itself lists all of the event's attendees
+func sendInvitations(account *auth.Account, event calendar.Event, to []calendar.Attendee) error {
+	if len(to) == 0 {
+		return nil
+	}
+	if account == nil {
+		return errors.New("no account to send invitations from")
+	}
+	serverClient, err := client.Connect()
+	if err != nil {
+		return err
+	}
+	defer serverClient.Close()
+
+	subject := i18n.T("invite.subject", map[string]any{"Title": event.Title})
+	invitation := calendar.Invitation(event, account.Credentials.Email)
+	return serverClient.SendInvitation(account.Credentials.Email, calendar.FormatAttendees(to), subject, invitationText(event), invitation)
+}
+
+// newAttendees returns the attendees not already invited
+func newAttendees(attendees, invited []calendar.Attendee) []calendar.Attendee {
+	var added []calendar.Attendee
+	for _, a := range attendees {
+		known := false
+		for _, i := range invited {
+			if strings.EqualFold(a.Email, i.Email) {
+				known = true
+				break
+			}
+		}
+		if !known {
+			added = append(added, a)
+		}
+	}
+	return added
+}
+
+// invitationText is the invitation's body for mail clients that don't show invitations
+func invitationText(event calendar.Event) string {
+	when := i18n.FormatDateTime(event.StartTime) + " - " + i18n.FormatClock(event.EndTime)
+	if event.AllDay {
+		when = event.StartTime.Format("Monday, January 2, 2006") + ", " + i18n.T("calendar.all_day")
+	}
+
+	lines := []string{event.Title, "", i18n.T("invite.when", map[string]any{"Time": when})}
+	if event.Location != "" {
+		lines = append(lines, i18n.T("invite.where", map[string]any{"Location": event.Location}))
+	}
+	if event.Notes != "" {
+		lines = append(lines, "", event.Notes)
+	}
+	return strings.Join(lines, "\n") + "\n"
+}
+
+// attendeeNames lists attendees by name, or address for those without one
+func attendeeNames(attendees []calendar.Attendee) string {
+	names := make([]string, len(attendees))
+	for i, a := range attendees {
+		names[i] = a.Name
+		if names[i] == "" {
+			names[i] = a.Email
+		}
+	}
+	return strings.Join(names, ", ")
+}
+
+// attendeeStatus returns an attendee's response as shown beside their name
+func attendeeStatus(status string) string {
+	switch status {
+	case calendar.AttendeeAccepted:
+		return i18n.T("calendar.attendee.accepted")
+	case calendar.AttendeeDeclined:
+		return i18n.T("calendar.attendee.declined")
+	case calendar.AttendeeTentative:
+		return i18n.T("calendar.attendee.tentative")
+	case calendar.AttendeePending:
+		return i18n.T("calendar.attendee.pending")
+	}
+	return ""
+}
@@ -0,0 +1,119 @@
+package ui
+
+import (
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"maily/internal/ui/components"
+)
+
+// How long the status bar keeps showing finished background jobs
+const (
+	jobDoneShown   = 2 * time.Second  // the check mark after they all succeeded
+	jobFailedShown = 15 * time.Second // a failure, which can be retried meanwhile
+)
+
+// job is a server operation run in the background, such as marking an opened email read
+type job struct {
+	id    int
+	label string // message id of what it does, e.g. job.mark_read
+	run   func() error
+}
+
+// jobDoneMsg reports that a background job finished
+type jobDoneMsg struct {
+	id  int
+	err error
+}
+
+// jobClearMsg hides what the status bar shows about finished jobs, unless jobs started
+// since
+type jobClearMsg struct {
+	id     int  // the last job started when the clear was scheduled
+	failed bool // the longer wait for a failure, which alone clears it
+}
+
+// jobTracker runs server operations in the background instead of bare goroutines,
+// keeping what the status bar shows about them: a spinner while they run, a check
+// mark once they succeed, and the last failure until it is retried with ctrl+r
+type jobTracker struct {
+	lastID  int
+	running []job
+	done    bool
+	failed  *job
+	err     error
+}
+
+// start runs a job in the background, returning the command to batch with the others
+func (t *jobTracker) start(label string, run func() error) tea.Cmd {
+	t.lastID++
+	j := job{id: t.lastID, label: label, run: run}
+	t.running = append(t.running[:len(t.running):len(t.running)], j)
+	t.done = false
+	return func() tea.Msg {
+		return jobDoneMsg{id: j.id, err: j.run()}
+	}
+}
+
+// finish records a finished job, returning the command that later hides the result
+func (t *jobTracker) finish(msg jobDoneMsg) tea.Cmd {
+	for i, j := range t.running {
+		if j.id != msg.id {
+			continue
+		}
+		t.running = append(t.running[:i:i], t.running[i+1:]...)
+		if msg.err != nil {
+			t.failed, t.err = &j, msg.err
+		}
+		break
+	}
+	if len(t.running) > 0 {
+		return nil
+	}
+
+	// A job that failed while others ran is still shown, even if the last one succeeded
+	failed := t.failed != nil
+	shown := jobDoneShown
+	if failed {
+		shown = jobFailedShown
+	} else {
+		t.done = true
+	}
+	id := t.lastID
+	return tea.Tick(shown, func(time.Time) tea.Msg { return jobClearMsg{id: id, failed: failed} })
+}
+
+// clear hides the result of the jobs that finished, unless jobs started since
+func (t *jobTracker) clear(msg jobClearMsg) {
+	if msg.id != t.lastID || len(t.running) > 0 {
+		return
+	}
+	t.done = false
+	if msg.failed {
+		t.failed, t.err = nil, nil
+	}
+}
+
+// retry starts the last failed job again; nil when none failed
+func (t *jobTracker) retry() tea.Cmd {
+	if t.failed == nil {
+		return nil
+	}
+	j := *t.failed
+	t.failed, t.err = nil, nil
+	return t.start(j.label, j.run)
+}
+
+// status returns what the status bar shows about the jobs
+func (t jobTracker) status() components.JobStatus {
+	s := components.JobStatus{Running: len(t.running), Done: t.done}
+	if n := len(t.running); n > 0 {
+		s.Label = t.running[n-1].label
+	}
+	if t.failed != nil {
+		s.Failed = t.failed.label
+		s.Error = t.err.Error()
+	}
+	return s
+}
@@ -0,0 +1,54 @@
+package ui
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestJobFailureOutlivesLaterSuccess(t *testing.T) {
+	var jobs jobTracker
+	jobs.start("job.mark_read", func() error { return nil })
+	jobs.start("job.archive", func() error { return nil })
+
+	// The first job fails while the second still runs, which then succeeds
+	if cmd := jobs.finish(jobDoneMsg{id: 1, err: errors.New("offline")}); cmd != nil {
+		t.Error("finish() scheduled a clear with a job still running")
+	}
+	if jobs.finish(jobDoneMsg{id: 2}) == nil {
+		t.Fatal("finish() scheduled no clear after the last job")
+	}
+	if s := jobs.status(); s.Failed != "job.mark_read" || s.Done {
+		t.Errorf("status() = %+v, want the failure shown", s)
+	}
+
+	// A short tick left over from earlier keeps the failure
+	jobs.clear(jobClearMsg{id: 2})
+	if jobs.status().Failed == "" {
+		t.Error("the done tick cleared the failure")
+	}
+	jobs.clear(jobClearMsg{id: 2, failed: true})
+	if s := jobs.status(); s.Failed != "" || s.Done {
+		t.Errorf("status() = %+v after the failure tick, want nothing", s)
+	}
+}
+
+func TestJobRetry(t *testing.T) {
+	var jobs jobTracker
+	jobs.start("job.mark_read", func() error { return errors.New("offline") })
+	jobs.finish(jobDoneMsg{id: 1, err: errors.New("offline")})
+
+	if jobs.retry() == nil {
+		t.Fatal("retry() started nothing after a failure")
+	}
+	if s := jobs.status(); s.Failed != "" || s.Running != 1 || s.Label != "job.mark_read" {
+		t.Errorf("status() = %+v, want the job running again", s)
+	}
+	// The tick from before the retry doesn't hide the running job's result
+	jobs.clear(jobClearMsg{id: 1, failed: true})
+	if jobs.status().Running != 1 {
+		t.Error("clear() dropped a running job")
+	}
+	if jobs.retry() != nil {
+		t.Error("retry() started a job with none failed")
+	}
+}
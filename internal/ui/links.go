@@ -0,0 +1,184 @@
+package ui
+
+import (
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"maily/config"
+	"maily/internal/i18n"
+	"maily/internal/mail"
+	"maily/internal/ui/components"
+	"maily/internal/ui/utils"
+)
+
+// linkOpener lists the links in the opened email and asks before opening one
+type linkOpener struct {
+	links   []mail.Link
+	cursor  int
+	picking bool // link list showing
+	confirm bool // confirmation dialog showing for links[cursor]
+}
+
+// Active reports whether the link list or the confirmation dialog is showing
+func (l linkOpener) Active() bool {
+	return l.picking || l.confirm
+}
+
+// showLinks opens the link list for the opened email
+func (a *App) showLinks(email *mail.Email) {
+	body := email.BodyHTML
+	if body == "" {
+		body = email.Snippet
+	}
+	links := mail.ExtractLinks(body)
+	if len(links) == 0 {
+		a.statusMsg = i18n.T("links.none")
+		return
+	}
+	a.links = linkOpener{links: links, picking: true}
+}
+
+// handleLinkKey handles keys while the link list or confirmation dialog is showing
+func (a *App) handleLinkKey(msg tea.KeyMsg) tea.Cmd {
+	l := &a.links
+	if l.confirm {
+		link := l.links[l.cursor]
+		switch msg.String() {
+		case "enter", "o":
+			l.confirm = false
+			a.openLink(link.URL)
+		case "c", "y":
+			l.confirm = false
+			if err := utils.CopyToClipboard(link.URL); err != nil {
+				a.statusMsg = i18n.T("links.copy_failed", map[string]any{"Error": err})
+			} else {
+				a.statusMsg = i18n.T("links.copied")
+			}
+		case "t":
+			// Trust the sender, so their links open straight away from now on
+			l.confirm = false
+			cmd := a.trustSender()
+			a.openLink(link.URL)
+			return cmd
+		case "esc":
+			l.confirm = false
+			l.picking = len(l.links) > 1
+		case "q":
+			return tea.Quit
+		}
+		return nil
+	}
+
+	switch msg.String() {
+	case "down", "j", "tab":
+		l.cursor = (l.cursor + 1) % len(l.links)
+	case "up", "k", "shift+tab":
+		l.cursor = (l.cursor - 1 + len(l.links)) % len(l.links)
+	case "enter":
+		l.picking = false
+		if a.senderTrusted() {
+			a.openLink(l.links[l.cursor].URL)
+		} else {
+			l.confirm = true
+		}
+	case "esc":
+		l.picking = false
+	case "q":
+		return tea.Quit
+	default:
+		// Number keys pick a link directly
+		if n := msg.String(); len(n) == 1 && n[0] >= '1' && n[0] <= '9' && int(n[0]-'0') <= len(l.links) {
+			l.cursor = int(n[0] - '1')
+			l.picking = false
+			if a.senderTrusted() {
+				a.openLink(l.links[l.cursor].URL)
+			} else {
+				l.confirm = true
+			}
+		}
+	}
+	return nil
+}
+
+// openLink opens a URL in the browser and reports the outcome in the status bar
+func (a *App) openLink(url string) {
+	if err := utils.OpenURL(url); err != nil {
+		a.statusMsg = i18n.T("links.open_failed", map[string]any{"Error": err})
+	} else {
+		a.statusMsg = i18n.T("links.opened")
+	}
+}
+
+// openInWeb opens the selected email in the account's web mail. When the web UI can't
+// be pointed at one email, the subject is copied so it can be pasted into its search.
+func (a *App) openInWeb(email *mail.Email) {
+	account := a.currentAccount()
+	if account == nil {
+		return
+	}
+	link, specific := account.WebMailURL(email.MessageID, email.Subject)
+	if link == "" {
+		a.statusMsg = i18n.T("web.unavailable")
+		return
+	}
+	if err := utils.OpenURL(link); err != nil {
+		a.statusMsg = i18n.T("links.open_failed", map[string]any{"Error": err})
+		return
+	}
+	a.statusMsg = i18n.T("web.opened")
+	if !specific && utils.CopyToClipboard(email.Subject) == nil {
+		a.statusMsg = i18n.T("web.opened_search")
+	}
+}
+
+// senderAddress returns the bare address of the opened email's sender
+func (a App) senderAddress() string {
+	email := a.mailList.SelectedEmail()
+	if email == nil {
+		return ""
+	}
+	return strings.TrimSpace(extractEmail(email.From))
+}
+
+// senderTrusted reports whether links from the opened email's sender skip the confirmation
+func (a App) senderTrusted() bool {
+	sender := a.senderAddress()
+	return sender != "" && a.cfg.Links.Trusted(sender)
+}
+
+// trustSender adds the opened email's sender to the trusted senders and saves the config
+func (a *App) trustSender() tea.Cmd {
+	sender := a.senderAddress()
+	if sender == "" || a.cfg.Links.Trusted(sender) {
+		return nil
+	}
+	links := config.LinksConfig{}
+	if a.cfg.Links != nil {
+		links = *a.cfg.Links
+	}
+	links.TrustedSenders = append(append([]string(nil), links.TrustedSenders...), sender)
+	a.cfg.Links = &links
+
+	return func() tea.Msg {
+		_, err := config.Update(func(cfg *config.Config) {
+			if cfg.Links == nil {
+				cfg.Links = &config.LinksConfig{}
+			}
+			if !cfg.Links.Trusted(sender) {
+				cfg.Links.TrustedSenders = append(cfg.Links.TrustedSenders, sender)
+			}
+		})
+		return configSavedMsg{err: err}
+	}
+}
+
+// renderLinks renders the link list or confirmation dialog
+func (a App) renderLinks() string {
+	l := a.links
+	if l.confirm {
+		link := l.links[l.cursor]
+		return components.RenderLinkConfirm(a.width, a.height, link, mail.ResolveLink(link.URL), mail.LinkSignals(link))
+	}
+	return components.RenderLinkPicker(a.width, a.height, l.links, l.cursor)
+}
@@ -43,6 +43,7 @@ type LoginApp struct {
 	height       int
 	err          error
 	account      *auth.Account
+	reauth       *auth.Account // existing account whose password is being replaced
 }
 
 type verifySuccessMsg struct {
@@ -94,6 +95,17 @@ func NewLoginApp(provider string) LoginApp {
 	}
 }
 
+// NewReauthApp creates a login form that only asks for a new password for an existing account
+func NewReauthApp(account auth.Account) LoginApp {
+	a := NewLoginApp(account.Provider)
+	a.reauth = &account
+	a.emailInput.SetValue(account.Credentials.Email)
+	a.emailInput.Blur()
+	a.passwordInput.Focus()
+	a.focusedField = fieldPassword
+	return a
+}
+
 func (a LoginApp) Init() tea.Cmd {
 	return textinput.Blink
 }
@@ -105,6 +117,10 @@ func (a LoginApp) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	case tea.KeyMsg:
 		switch a.state {
 		case loginStateInput:
+			// The email is fixed when re-authenticating
+			if a.reauth != nil && (msg.String() == "tab" || msg.String() == "up") {
+				return a, nil
+			}
 			switch msg.String() {
 			case "ctrl+c", "esc":
 				return a, tea.Quit
@@ -203,6 +219,10 @@ func (a LoginApp) verifyCredentials() tea.Cmd {
 	}
 	password = cleaned.String()
 
+	if a.reauth != nil {
+		return reauthCredentials(*a.reauth, password)
+	}
+
 	return func() tea.Msg {
 		var creds auth.Credentials
 		switch provider {
@@ -242,6 +262,32 @@ func (a LoginApp) verifyCredentials() tea.Cmd {
 	}
 }
 
+// reauthCredentials verifies a new password and updates the stored account in place
+func reauthCredentials(account auth.Account, password string) tea.Cmd {
+	return func() tea.Msg {
+		account.Credentials.Password = password
+
+		client, err := mail.NewIMAPClient(&account.Credentials)
+		if err != nil {
+			return verifyErrorMsg{err: err}
+		}
+		client.Close()
+
+		store, err := auth.LoadAccountStore()
+		if err != nil {
+			return verifyErrorMsg{err: err}
+		}
+		if !store.UpdatePassword(account.Credentials.Email, password) {
+			return verifyErrorMsg{err: fmt.Errorf("account not found: %s", account.Credentials.Email)}
+		}
+		if err := store.Save(); err != nil {
+			return verifyErrorMsg{err: err}
+		}
+
+		return verifySuccessMsg{account: &account}
+	}
+}
+
 func (a LoginApp) View() string {
 	if a.width == 0 {
 		return "Loading..."
@@ -334,11 +380,14 @@ func (a LoginApp) renderInputForm() string {
 
 	// Title and instructions based on provider
 	var title, instructions string
-	switch a.provider {
-	case "yahoo":
+	switch {
+	case a.reauth != nil:
+		title = titleStyle.Render(i18n.T("login.reauth.title"))
+		instructions = hintStyle.Render(i18n.T("login.reauth.hint", map[string]any{"Email": a.reauth.Credentials.Email}))
+	case a.provider == "yahoo":
 		title = titleStyle.Render(i18n.T("login.yahoo.title"))
 		instructions = hintStyle.Render(i18n.T("login.yahoo.hint"))
-	case "qq":
+	case a.provider == "qq":
 		title = titleStyle.Render(i18n.T("login.qq.title"))
 		instructions = hintStyle.Render(i18n.T("login.qq.hint"))
 	default:
@@ -0,0 +1,177 @@
+package ui
+
+import (
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+
+	"maily/internal/i18n"
+	"maily/internal/ui/components"
+)
+
+// doubleClickTime is the longest gap between two clicks on a row that opens it
+const doubleClickTime = 400 * time.Millisecond
+
+// enterKey stands in for the enter key when a click confirms or opens something
+var enterKey = tea.KeyMsg{Type: tea.KeyEnter}
+
+// clickTracker recognizes a second click on the same row as a double click
+type clickTracker struct {
+	at  time.Time
+	row int
+}
+
+// double records a click on row and reports whether it completes a double click
+func (c *clickTracker) double(row int) bool {
+	now := time.Now()
+	if row == c.row && now.Sub(c.at) < doubleClickTime {
+		c.at = time.Time{}
+		return true
+	}
+	c.at, c.row = now, row
+	return false
+}
+
+// handleClick handles a left click: a dialog button, a folder in the picker, a compose
+// button or field, or an email in the list, which a second click opens
+func (a App) handleClick(msg tea.MouseMsg) (tea.Model, tea.Cmd) {
+	line := components.ScreenLine(a.View(), msg.Y)
+	switch {
+	case a.confirmDelete:
+		option := components.ButtonAt(line, msg.X,
+			i18n.T("dialog.delete.move_trash"), i18n.T("dialog.delete.permanent"), i18n.T("common.cancel"))
+		if option < 0 {
+			return a, nil
+		}
+		a.deleteOption = components.DeleteOption(option)
+		return a.Update(enterKey)
+
+	case a.showLabelPicker:
+		if a.labelPicker.SelectLine(line) {
+			return a.Update(enterKey)
+		}
+
+	case a.view == composeView && !a.showFilePicker:
+		var cmd tea.Cmd
+		a.compose, cmd = a.compose.click(line, msg.X)
+		return a, cmd
+
+	case a.view == listView && a.state == stateReady && !a.overlayOpen():
+		top := lipgloss.Height(components.RenderHeader(a.headerData()))
+		row := a.mailList.RowAt(msg.Y - top)
+		if listWidth, _ := a.listSize(); row < 0 || msg.X >= listWidth {
+			return a, nil
+		}
+		a.mailList.SelectRow(row)
+		if a.clicks.double(row) {
+			return a.Update(enterKey)
+		}
+		cmd := a.pageIfNeeded()
+		return a, cmd
+	}
+	return a, nil
+}
+
+// overlayOpen reports whether a dialog or picker covers the mail list
+func (a App) overlayOpen() bool {
+	return a.confirmDelete || a.confirmEmpty || a.searchMode || a.showLabelPicker ||
+		a.showLabelEditor || a.showOpsReview || a.showCommandPalette || a.showAISetup ||
+		a.showStats || a.showStorage || a.showSyncError || a.showCleanup
+}
+
+// click handles a click on a line of the compose view: a button of the confirmation
+// dialog, one of the Send, Save Draft and Cancel buttons, Add File, or the To and
+// Subject fields
+func (m ComposeModel) click(line string, x int) (ComposeModel, tea.Cmd) {
+	if m.confirming != confirmNone {
+		_, _, confirmLabel, cancelLabel := m.confirmText()
+		button := components.ButtonAt(line, x, confirmLabel, cancelLabel)
+		if button < 0 {
+			return m, nil
+		}
+		m.confirmFocused = button
+		return m.Update(enterKey)
+	}
+
+	buttons := []int{focusSend, focusSaveDraft, focusCancel}
+	if button := components.ButtonAt(line, x, i18n.T("compose.send"), i18n.T("compose.save_draft"), i18n.T("common.cancel")); button >= 0 {
+		m.focusField(buttons[button])
+		return m.Update(enterKey)
+	}
+	if components.LabelAt(line, x, "+ "+i18n.T("compose.add_file")) >= 0 {
+		m.focusField(focusAttach)
+		return m.Update(enterKey)
+	}
+
+	field := strings.TrimSpace(strings.Trim(strings.TrimSpace(line), "│"))
+	switch {
+	case strings.HasPrefix(field, i18n.T("compose.label.to")):
+		return m, m.focusField(focusTo)
+	case strings.HasPrefix(field, i18n.T("compose.label.subject")):
+		return m, m.focusField(focusSubject)
+	}
+	return m, nil
+}
+
+// handleClick handles a left click on a search result, which a second click opens, or
+// on the Yes and No buttons of a confirmation
+func (a SearchApp) handleClick(msg tea.MouseMsg) (tea.Model, tea.Cmd) {
+	if a.state == searchStateConfirm || a.confirmDeleteSingle {
+		line := components.ScreenLine(a.View(), msg.Y)
+		button := components.ButtonAt(line, msg.X, i18n.T("common.yes"), i18n.T("common.no"))
+		if button < 0 {
+			return a, nil
+		}
+		a.confirmSelection = confirmOption(button)
+		return a.Update(enterKey)
+	}
+	if a.state != searchStateReady || a.view != searchListView {
+		return a, nil
+	}
+
+	start, end := a.window()
+	row := start + msg.Y - lipgloss.Height(a.renderHeader())
+	if row < start || row >= end {
+		return a, nil
+	}
+	a.cursor = row
+	if a.clicks.double(row) {
+		return a.Update(enterKey)
+	}
+	return a, nil
+}
+
+// handleClick handles a left click on a day of the month grid, or on a button of the
+// event details and delete confirmation
+func (m *CalendarApp) handleClick(msg tea.MouseMsg) (tea.Model, tea.Cmd) {
+	switch m.view {
+	case viewCalendar:
+		day, ok := m.dayAt(msg.X, msg.Y)
+		if !ok {
+			return m, nil
+		}
+		sameMonth := day.Year() == m.selectedDate.Year() && day.Month() == m.selectedDate.Month()
+		m.selectedDate = day
+		m.selectedIdx = 0
+		if !sameMonth {
+			return m, m.loadEvents()
+		}
+
+	case viewEventDetail:
+		line := components.ScreenLine(m.View(), msg.Y)
+		if button := components.ButtonAt(line, msg.X, i18n.T("common.edit"), i18n.T("common.delete"), i18n.T("common.close")); button >= 0 {
+			m.detailButtonIdx = button
+			return m.handleEventDetailKeys(enterKey)
+		}
+
+	case viewDeleteConfirm:
+		line := components.ScreenLine(m.View(), msg.Y)
+		if button := components.ButtonAt(line, msg.X, i18n.T("common.delete"), i18n.T("common.cancel")); button >= 0 {
+			m.deleteButtonIdx = button
+			return m.handleDeleteKeys(enterKey)
+		}
+	}
+	return m, nil
+}
@@ -0,0 +1,69 @@
+package ui
+
+import (
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"maily/internal/cache"
+)
+
+// Opening a folder marks the emails that arrived since it was last viewed, with a
+// separator between them and the mail already seen. The time each folder was viewed is
+// kept in the disk cache, so the mark survives restarts; it moves on each time the
+// folder's list is loaded, and is kept while the folder stays open.
+
+// lastViewedMsg carries when a folder was last viewed, read before the time was moved on
+type lastViewedMsg struct {
+	folder string
+	since  time.Time
+	read   bool // since was read, rather than only the new time saved
+	err    error
+}
+
+// markNewMail sets the new mail separator after the current folder's emails are loaded.
+// Opening another folder reads when it was last viewed, which sets the separator when it
+// arrives; the new time is saved either way.
+func (a *App) markNewMail() tea.Cmd {
+	account := a.currentAccount()
+	if a.diskCache == nil || account == nil {
+		return nil
+	}
+	if a.isSearchResult || a.smartFolder != nil || a.newsletters {
+		// Not a folder's list
+		a.mailList.SetNewSince(time.Time{})
+		return nil
+	}
+
+	diskCache := a.diskCache
+	email, mailbox := account.Credentials.Email, a.currentLabel
+	folder := email + "\x00" + mailbox
+	read := folder != a.viewedFolder
+	if read {
+		a.viewedFolder = folder
+		a.newSince = time.Time{}
+	}
+	a.showNewSince()
+
+	now := time.Now()
+	return func() tea.Msg {
+		msg := lastViewedMsg{folder: folder, read: read}
+		if read {
+			if msg.since, msg.err = diskCache.LastViewed(email, mailbox); msg.err != nil {
+				return msg
+			}
+		}
+		msg.err = diskCache.SetLastViewed(email, mailbox, now)
+		return msg
+	}
+}
+
+// showNewSince draws the separator for newSince. It splits the list by arrival, which
+// only newest first keeps together.
+func (a *App) showNewSince() {
+	since := a.newSince
+	if a.sortOrder() != cache.SortDate {
+		since = time.Time{}
+	}
+	a.mailList.SetNewSince(since)
+}
@@ -0,0 +1,284 @@
+package ui
+
+import (
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+
+	"maily/internal/auth"
+	"maily/internal/i18n"
+)
+
+// The onboarding wizard walks a first run through picking a language, adding an
+// account with the regular login form and choosing notifications and autostart. The
+// caller applies the choices and opens the inbox.
+
+type onboardingStep int
+
+const (
+	onboardingLanguage onboardingStep = iota
+	onboardingProvider
+	onboardingLogin
+	onboardingOptions
+)
+
+// onboardingSteps is how many steps the wizard shows in its "Step n of m" line
+const onboardingSteps = 4
+
+// Onboarding options, in the order listed
+const (
+	optionNotifications = iota
+	optionAutostart
+	optionCount
+)
+
+// OnboardingChoices are the preferences picked during onboarding
+type OnboardingChoices struct {
+	Language      string // language code, "" to follow the system
+	Notifications bool   // native notifications for new mail and calendar reminders
+	Autostart     bool   // start the server at login
+}
+
+type OnboardingApp struct {
+	step     onboardingStep
+	cursor   int
+	choices  OnboardingChoices
+	login    LoginApp
+	account  *auth.Account
+	finished bool
+	width    int
+	height   int
+}
+
+func NewOnboardingApp() OnboardingApp {
+	return OnboardingApp{
+		choices: OnboardingChoices{Notifications: true},
+	}
+}
+
+func (m OnboardingApp) Init() tea.Cmd {
+	return nil
+}
+
+func (m OnboardingApp) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	if size, ok := msg.(tea.WindowSizeMsg); ok {
+		m.width = size.Width
+		m.height = size.Height
+	}
+	if m.step == onboardingLogin {
+		return m.updateLogin(msg)
+	}
+
+	key, ok := msg.(tea.KeyMsg)
+	if !ok {
+		return m, nil
+	}
+	switch key.String() {
+	case "ctrl+c":
+		return m, tea.Quit
+	case "up", "k":
+		if m.cursor > 0 {
+			m.cursor--
+		}
+		return m, nil
+	case "down", "j":
+		if m.cursor < m.optionCount()-1 {
+			m.cursor++
+		}
+		return m, nil
+	}
+
+	switch m.step {
+	case onboardingLanguage:
+		switch key.String() {
+		case "esc", "q":
+			return m, tea.Quit
+		case "enter":
+			m.choices.Language = ""
+			if m.cursor > 0 {
+				m.choices.Language = i18n.SupportedLanguages[m.cursor-1]
+			}
+			// The rest of the wizard speaks the chosen language
+			i18n.Init(m.choices.Language)
+			m.step, m.cursor = onboardingProvider, 0
+		}
+
+	case onboardingProvider:
+		switch key.String() {
+		case "esc":
+			m.step, m.cursor = onboardingLanguage, 0
+		case "enter":
+			m.login = NewLoginApp(providerIDs[m.cursor])
+			m.login.width, m.login.height = m.width, m.height
+			m.step = onboardingLogin
+			return m, m.login.Init()
+		}
+
+	case onboardingOptions:
+		switch key.String() {
+		case " ", "x":
+			switch m.cursor {
+			case optionNotifications:
+				m.choices.Notifications = !m.choices.Notifications
+			case optionAutostart:
+				m.choices.Autostart = !m.choices.Autostart
+			}
+		case "enter":
+			m.finished = true
+			return m, tea.Quit
+		case "esc":
+			// The account is saved already; leave the preferences as they are
+			return m, tea.Quit
+		}
+	}
+	return m, nil
+}
+
+// updateLogin runs the login form, moving on once the account is verified rather
+// than quitting as the standalone form does
+func (m OnboardingApp) updateLogin(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case verifySuccessMsg:
+		m.account = msg.account
+		m.step, m.cursor = onboardingOptions, 0
+		return m, nil
+
+	case tea.KeyMsg:
+		switch {
+		case msg.String() == "ctrl+c":
+			return m, tea.Quit
+		case m.login.state == loginStateError:
+			// Let the user fix the address or password instead of exiting
+			m.login.state = loginStateInput
+			return m, nil
+		case m.login.state == loginStateInput && msg.String() == "esc":
+			m.step = onboardingProvider
+			return m, nil
+		}
+	}
+
+	model, cmd := m.login.Update(msg)
+	m.login = model.(LoginApp)
+	return m, cmd
+}
+
+func (m OnboardingApp) optionCount() int {
+	switch m.step {
+	case onboardingLanguage:
+		return 1 + len(i18n.SupportedLanguages)
+	case onboardingProvider:
+		return len(providerIDs)
+	case onboardingOptions:
+		return optionCount
+	}
+	return 0
+}
+
+// Account returns the account added during onboarding, nil if the user quit before
+func (m OnboardingApp) Account() *auth.Account {
+	return m.account
+}
+
+// Choices returns the picked preferences, and false if the wizard wasn't finished
+func (m OnboardingApp) Choices() (OnboardingChoices, bool) {
+	return m.choices, m.finished
+}
+
+func (m OnboardingApp) View() string {
+	if m.width == 0 {
+		return "Loading..."
+	}
+	if m.step == onboardingLogin {
+		return m.login.View()
+	}
+
+	titleStyle := lipgloss.NewStyle().
+		Bold(true).
+		Foreground(lipgloss.Color("#7C3AED"))
+
+	stepStyle := lipgloss.NewStyle().
+		Foreground(lipgloss.Color("#9CA3AF")).
+		MarginBottom(1)
+
+	selectedStyle := lipgloss.NewStyle().
+		Bold(true).
+		Foreground(lipgloss.Color("#7C3AED"))
+
+	descStyle := lipgloss.NewStyle().
+		Foreground(lipgloss.Color("#9CA3AF"))
+
+	hintStyle := lipgloss.NewStyle().
+		Foreground(lipgloss.Color("#9CA3AF")).
+		MarginTop(1)
+
+	boxStyle := lipgloss.NewStyle().
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(lipgloss.Color("#7C3AED")).
+		Padding(1, 3)
+
+	var title, hint string
+	var labels, descs []string
+	switch m.step {
+	case onboardingLanguage:
+		title = i18n.T("onboarding.language.title")
+		hint = i18n.T("onboarding.language.hint")
+		labels = append(labels, i18n.T("onboarding.language.auto"))
+		for _, lang := range i18n.SupportedLanguages {
+			labels = append(labels, i18n.DisplayName(lang))
+		}
+	case onboardingProvider:
+		title = i18n.T("onboarding.provider.title")
+		hint = i18n.T("onboarding.provider.hint")
+		for _, id := range providerIDs {
+			labels = append(labels, getProviderName(id))
+			descs = append(descs, getProviderDesc(id))
+		}
+	case onboardingOptions:
+		title = i18n.T("onboarding.options.title")
+		hint = i18n.T("onboarding.options.hint")
+		for i, on := range []bool{m.choices.Notifications, m.choices.Autostart} {
+			box := "[ ]"
+			if on {
+				box = "[x]"
+			}
+			key := "onboarding.options.notifications"
+			if i == optionAutostart {
+				key = "onboarding.options.autostart"
+			}
+			labels = append(labels, box+" "+i18n.T(key))
+		}
+	}
+
+	var items strings.Builder
+	for i, label := range labels {
+		line := "  " + label
+		if i == m.cursor {
+			line = selectedStyle.Render("> " + label)
+		}
+		if i < len(descs) {
+			line += descStyle.Render("  " + descs[i])
+		}
+		items.WriteString(line + "\n")
+	}
+
+	header := titleStyle.Render(i18n.T("onboarding.welcome"))
+	step := stepStyle.Render(i18n.T("onboarding.step", map[string]any{
+		"Step":  int(m.step) + 1,
+		"Total": onboardingSteps,
+	}) + " · " + title)
+
+	parts := []string{header, step, items.String()}
+	if m.step == onboardingProvider {
+		parts = append(parts, descStyle.Render(i18n.T("onboarding.provider.maildir_hint")))
+	}
+	parts = append(parts, hintStyle.Render(hint))
+
+	return lipgloss.Place(
+		m.width,
+		m.height,
+		lipgloss.Center,
+		lipgloss.Center,
+		boxStyle.Render(lipgloss.JoinVertical(lipgloss.Left, parts...)),
+	)
+}
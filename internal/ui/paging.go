@@ -0,0 +1,133 @@
+package ui
+
+import (
+	tea "github.com/charmbracelet/bubbletea"
+
+	"maily/internal/i18n"
+	"maily/internal/mail"
+)
+
+const (
+	// maxListWindow caps how many emails the list keeps loaded; pages beyond it are dropped
+	// from the far end and reloaded from the cache when scrolled back to
+	maxListWindow = 500
+	// pageThreshold is how close to the edge of the loaded window the cursor gets before
+	// the next page is fetched
+	pageThreshold = 10
+)
+
+// emailPageLoadedMsg carries a page of emails fetched while scrolling the list
+type emailPageLoadedMsg struct {
+	emails       []mail.Email
+	offset       int  // position of the first email in the mailbox
+	total        int  // emails in the whole mailbox
+	newer        bool // page precedes the loaded window
+	older        bool // page was fetched from the server past the end of the cache
+	fetched      int  // emails the server added to the cache for an older page
+	accountEmail string
+	mailbox      string
+	err          error
+}
+
+// pageIfNeeded fetches the neighbouring page when the cursor nears either edge of the
+// loaded window, so scrolling through a large mailbox never loads it whole
+func (a *App) pageIfNeeded() tea.Cmd {
+	if a.paging || a.isSearchResult || a.view != listView || a.state != stateReady {
+		return nil
+	}
+	pageSize := a.cfg.MaxEmails
+	loaded := len(a.mailList.Emails())
+
+	switch {
+	case a.mailList.NearEnd(pageThreshold) && a.pageOffset+loaded < a.mailboxTotal:
+		a.paging = true
+		return a.loadPage(a.pageOffset+loaded, pageSize, false)
+	case a.mailList.NearStart(pageThreshold) && a.pageOffset > 0:
+		offset := max(0, a.pageOffset-pageSize)
+		a.paging = true
+		return a.loadPage(offset, a.pageOffset-offset, true)
+	}
+	return nil
+}
+
+// loadPage fetches limit emails starting at offset from the server, or the disk cache
+func (a App) loadPage(offset, limit int, newer bool) tea.Cmd {
+	account := a.currentAccount()
+	if account == nil {
+		return nil
+	}
+	accountEmail := account.Credentials.Email
+	mailbox := a.currentLabel
+	sort := a.sortOrder()
+	serverClient := a.serverClient
+	diskCache := a.diskCache
+
+	return func() tea.Msg {
+		msg := emailPageLoadedMsg{offset: offset, newer: newer, accountEmail: accountEmail, mailbox: mailbox}
+		emails, total, err := loadEmailPage(serverClient, diskCache, accountEmail, mailbox, offset, limit, sort)
+		msg.emails, msg.total, msg.err = emails, total, err
+		return msg
+	}
+}
+
+// loadOlder has the server fetch the page of emails older than everything cached, then
+// loads it into the list like any other page
+func (a App) loadOlder(offset int) tea.Cmd {
+	account := a.currentAccount()
+	if account == nil || a.serverClient == nil {
+		return nil
+	}
+	accountEmail := account.Credentials.Email
+	mailbox := a.currentLabel
+	serverClient := a.serverClient
+	diskCache := a.diskCache
+	limit := a.cfg.MaxEmails
+	sort := a.sortOrder()
+
+	return func() tea.Msg {
+		msg := emailPageLoadedMsg{offset: offset, older: true, accountEmail: accountEmail, mailbox: mailbox}
+		msg.fetched, msg.err = serverClient.LoadOlder(accountEmail, mailbox, limit)
+		if msg.err != nil || msg.fetched == 0 {
+			return msg
+		}
+		emails, total, err := loadEmailPage(serverClient, diskCache, accountEmail, mailbox, offset, limit, sort)
+		msg.emails, msg.total, msg.err = emails, total, err
+		return msg
+	}
+}
+
+// applyPage adds a fetched page to the list and drops emails from the opposite end
+// once the window exceeds maxListWindow
+func (a *App) applyPage(msg emailPageLoadedMsg) {
+	a.paging = false
+	if msg.older {
+		switch {
+		case msg.err != nil:
+			a.statusMsg = i18n.T("email.older_failed", map[string]any{"Error": msg.err})
+			return
+		case msg.fetched == 0:
+			a.statusMsg = i18n.T("email.no_older")
+			return
+		}
+		a.statusMsg = i18n.T("email.older_loaded", map[string]any{"Count": msg.fetched})
+	}
+	if msg.err != nil {
+		return
+	}
+	a.mailboxTotal = msg.total
+
+	if msg.newer {
+		a.mailList.PrependEmails(msg.emails)
+		a.pageOffset = msg.offset
+		if excess := len(a.mailList.Emails()) - maxListWindow; excess > 0 {
+			a.mailList.TrimBack(excess)
+		}
+	} else {
+		a.mailList.AppendEmails(msg.emails)
+		if excess := len(a.mailList.Emails()) - maxListWindow; excess > 0 {
+			a.mailList.TrimFront(excess)
+			a.pageOffset += excess
+		}
+	}
+	a.emailLimit = uint32(max(len(a.mailList.Emails()), a.cfg.MaxEmails))
+}
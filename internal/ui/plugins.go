@@ -0,0 +1,178 @@
+package ui
+
+import (
+	"log/slog"
+
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/viewport"
+	tea "github.com/charmbracelet/bubbletea"
+
+	"maily/internal/i18n"
+	"maily/internal/mail"
+	"maily/internal/plugins"
+	"maily/internal/ui/components"
+)
+
+// pluginsLoadedMsg carries the installed plugins, described in the background at startup
+type pluginsLoadedMsg struct {
+	plugins []plugins.Plugin
+}
+
+// pluginDoneMsg reports a finished plugin action
+type pluginDoneMsg struct {
+	title   string
+	message string
+	err     error
+}
+
+// pluginAction is an action of an installed plugin
+type pluginAction struct {
+	plugin plugins.Plugin
+	action plugins.Action
+}
+
+// loadPlugins describes the installed plugins. Broken ones are logged and left out.
+func loadPlugins() tea.Cmd {
+	return func() tea.Msg {
+		list, err := plugins.Load()
+		if err != nil {
+			slog.Warn("loading plugins", "error", err)
+		}
+		return pluginsLoadedMsg{plugins: list}
+	}
+}
+
+// viewKeys are the keys the list and read views handle in Update's key switch. The
+// mail list and the viewport add their own bindings, see builtinKey.
+var viewKeys = []string{
+	"ctrl+c", "q", "ctrl+r", "f", "*", "esc", "/", "enter", "N", "n", "r", "A", "Q", "C",
+	"R", "s", "e", "a", "1", "2", "3", "4", "5", "6", "7", "8", "9", "u", "d", "left",
+	"h", "right", "G", "O", "l", " ", "m", "S", "J", "T", "X", "E", "F", "V", "w", "H",
+	"v", "z", "p", "L", "U", "M", "P", "y", "W", "o", "!", "tab", "shift+tab", "x",
+}
+
+// builtinKey reports whether the list or read view already uses a key
+func builtinKey(k string) bool {
+	for _, v := range viewKeys {
+		if v == k {
+			return true
+		}
+	}
+	lk := components.DefaultMailListKeyMap
+	vk := viewport.DefaultKeyMap()
+	bindings := []key.Binding{
+		lk.Up, lk.Down, lk.Enter, lk.Delete, lk.MarkRead, lk.Refresh,
+		vk.PageDown, vk.PageUp, vk.HalfPageUp, vk.HalfPageDown, vk.Up, vk.Down, vk.Left, vk.Right,
+	}
+	for _, b := range bindings {
+		for _, bk := range b.Keys() {
+			if bk == k {
+				return true
+			}
+		}
+	}
+	for _, c := range components.AllCommands {
+		if c.Shortcut == k {
+			return true
+		}
+	}
+	return false
+}
+
+// setPlugins adds the plugins' actions to the command palette. Keys maily uses, or an
+// earlier action took, are ignored so a plugin can't take them over; the action stays
+// in the palette.
+func (a *App) setPlugins(list []plugins.Plugin) {
+	a.pluginActions = make(map[string]pluginAction)
+	taken := make(map[string]string)
+	var commands []components.Command
+	for _, p := range list {
+		for _, action := range p.Actions {
+			name := p.Command(action)
+			if action.Key != "" {
+				if builtinKey(action.Key) {
+					slog.Warn("ignoring plugin key maily uses", "action", name, "key", action.Key)
+					action.Key = ""
+				} else if other, ok := taken[action.Key]; ok {
+					slog.Warn("ignoring plugin key another action uses", "action", name, "key", action.Key, "other", other)
+					action.Key = ""
+				} else {
+					taken[action.Key] = name
+				}
+			}
+			a.pluginActions[name] = pluginAction{plugin: p, action: action}
+			commands = append(commands, components.Command{
+				Name:     name,
+				Desc:     action.Title,
+				Shortcut: action.Key,
+				Views:    []string{"list", "read"},
+			})
+		}
+	}
+	a.commandPalette.SetExtraCommands(commands)
+}
+
+// pluginForKey returns the plugin action bound to a key
+func (a App) pluginForKey(key string) (pluginAction, bool) {
+	for _, pa := range a.pluginActions {
+		if pa.action.Key == key {
+			return pa, true
+		}
+	}
+	return pluginAction{}, false
+}
+
+// runPlugin runs a plugin action on the selected emails, or the current one
+func (a *App) runPlugin(pa pluginAction) tea.Cmd {
+	var emails []plugins.Email
+	if a.view == listView && a.selectedCount() > 0 {
+		for _, e := range a.mailList.Emails() {
+			if a.selected[e.UID] {
+				emails = append(emails, pluginEmail(e))
+			}
+		}
+	} else if email := a.mailList.SelectedEmail(); email != nil {
+		emails = append(emails, pluginEmail(*email))
+	}
+	if len(emails) == 0 {
+		return nil
+	}
+
+	req := plugins.Request{Mailbox: a.currentLabel, Emails: emails}
+	if account := a.currentAccount(); account != nil {
+		req.Account = account.Credentials.Email
+	}
+	a.statusMsg = i18n.T("plugin.running", map[string]any{"Action": pa.action.Title})
+	return func() tea.Msg {
+		resp, err := pa.plugin.Run(pa.action.Name, req)
+		return pluginDoneMsg{title: pa.action.Title, message: resp.Message, err: err}
+	}
+}
+
+// pluginEmail converts an email for a plugin's request
+func pluginEmail(e mail.Email) plugins.Email {
+	return plugins.Email{
+		UID:       uint32(e.UID),
+		MessageID: e.MessageID,
+		From:      e.From,
+		To:        e.To,
+		Cc:        e.Cc,
+		Subject:   e.Subject,
+		Date:      e.Date,
+		Snippet:   e.Snippet,
+		BodyHTML:  e.BodyHTML,
+		Labels:    e.Labels,
+	}
+}
+
+// pluginStatus is the status bar text for a finished plugin action
+func pluginStatus(msg pluginDoneMsg) string {
+	switch {
+	case msg.err != nil:
+		return i18n.T("plugin.failed", map[string]any{"Error": msg.err})
+	case msg.message != "":
+		return msg.message
+	default:
+		return i18n.T("plugin.done", map[string]any{"Action": msg.title})
+	}
+}
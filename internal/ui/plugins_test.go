@@ -0,0 +1,30 @@
+package ui
+
+import (
+	"testing"
+
+	"maily/internal/plugins"
+)
+
+func TestSetPluginsIgnoresTakenKeys(t *testing.T) {
+	p := plugins.Plugin{Name: "tickets", Manifest: plugins.Manifest{Actions: []plugins.Action{
+		{Name: "down", Title: "Down", Key: "j"},
+		{Name: "thread", Title: "Thread", Key: "T"},
+		{Name: "ticket", Title: "Ticket", Key: "ctrl+t"},
+		{Name: "again", Title: "Again", Key: "ctrl+t"},
+	}}}
+	var a App
+	a.setPlugins([]plugins.Plugin{p})
+
+	for _, k := range []string{"j", "k", "pgdown", "T"} {
+		if pa, ok := a.pluginForKey(k); ok {
+			t.Errorf("pluginForKey(%q) = %s, want maily's own binding kept", k, pa.action.Name)
+		}
+	}
+	if pa, ok := a.pluginForKey("ctrl+t"); !ok || pa.action.Name != "ticket" {
+		t.Errorf("pluginForKey(ctrl+t) = %+v, %v, want the first action that declared it", pa.action, ok)
+	}
+	if len(a.pluginActions) != 4 {
+		t.Errorf("got %d palette actions, want all 4 kept", len(a.pluginActions))
+	}
+}
@@ -0,0 +1,97 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+
+	"maily/internal/htmlbody"
+	"maily/internal/mail"
+	"maily/internal/ui/components"
+)
+
+// previewCache keeps the last rendered preview body, so redrawing the list doesn't
+// render the highlighted email's HTML again on every frame
+type previewCache struct {
+	key   string
+	width int
+	body  string
+}
+
+// listChrome is the height of the header and status bar around the mail list
+const listChrome = 7
+
+// listSize returns the size of the mail list, less what the preview pane takes
+func (a App) listSize() (width, height int) {
+	width, height = a.width, a.height-listChrome
+	if !a.showPreview {
+		return width, height
+	}
+	if a.cfg.Preview.Below() {
+		return width, height * a.cfg.Preview.ListPercent() / 100
+	}
+	return width * a.cfg.Preview.ListPercent() / 100, height
+}
+
+// resizeList fits the mail list to the screen and the preview pane
+func (a *App) resizeList() {
+	a.mailList.SetSize(a.listSize())
+}
+
+// renderListWithPreview renders the mail list with the preview of the highlighted
+// email beside or below it
+func (a App) renderListWithPreview() string {
+	listWidth, listHeight := a.listSize()
+	list := components.RenderListView(listWidth, a.height, a.mailList.View())
+
+	width, height := a.width-listWidth, listHeight
+	if a.cfg.Preview.Below() {
+		// The list doesn't pad itself to its height, so pad it here to keep the pane below
+		list = lipgloss.NewStyle().Height(listHeight).Render(list)
+		width, height = a.width, a.height-listChrome-listHeight
+	}
+
+	var data *components.PreviewData
+	if email := a.mailList.SelectedEmail(); email != nil {
+		data = &components.PreviewData{
+			From:    email.From,
+			Subject: email.Subject,
+			Date:    email.Date,
+			Body:    a.previewBody(*email, width-4),
+		}
+	}
+	pane := components.RenderPreview(data, width, height)
+
+	if a.cfg.Preview.Below() {
+		return lipgloss.JoinVertical(lipgloss.Left, list, pane)
+	}
+	return lipgloss.JoinHorizontal(lipgloss.Top, list, pane)
+}
+
+// previewBody renders the first lines of an email's cached body, or its snippet when
+// the body hasn't been fetched yet
+func (a App) previewBody(email mail.Email, width int) string {
+	// The body's length tells a fetched body from the snippet shown before it
+	key := fmt.Sprintf("%d/%s/%d", email.UID, email.MessageID, len(email.BodyHTML))
+	if a.preview.key == key && a.preview.width == width {
+		return a.preview.body
+	}
+
+	body := email.BodyHTML
+	if body == "" {
+		body = email.Snippet
+	}
+	var lines []string
+	if body != "" {
+		markdown, _ := components.FoldQuoted(htmlbody.ToMarkdown(body), false)
+		rendered := strings.Trim(components.RenderMarkdown(markdown, max(20, width)), "\n")
+		lines = strings.Split(rendered, "\n")
+		if n := a.cfg.Preview.BodyLines(); len(lines) > n {
+			lines = lines[:n]
+		}
+	}
+
+	*a.preview = previewCache{key: key, width: width, body: strings.Join(lines, "\n")}
+	return a.preview.body
+}
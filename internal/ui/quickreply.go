@@ -0,0 +1,107 @@
+package ui
+
+import (
+	"strings"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/emersion/go-imap/v2"
+
+	"maily/config"
+	"maily/internal/auth"
+	"maily/internal/i18n"
+	"maily/internal/mail"
+	"maily/internal/ui/components"
+)
+
+// quickReplier is the one-line reply bar of the list and read views (Q): the text typed
+// is sent straight away as a reply to the email under the cursor, with the same
+// recipient, subject, threading headers and quote as a reply from the compose form
+type quickReplier struct {
+	input  textinput.Model
+	typing bool
+	email  mail.Email // email being replied to
+}
+
+type quickReplySentMsg struct {
+	to  string
+	uid imap.UID // the email replied to
+	err error
+}
+
+func newQuickReplier() quickReplier {
+	ti := textinput.New()
+	ti.Placeholder = i18n.T("quick_reply.placeholder")
+	ti.CharLimit = 500
+	ti.Width = 50
+	return quickReplier{input: ti}
+}
+
+// Start focuses the bar to reply to an email
+func (q *quickReplier) Start(email mail.Email) tea.Cmd {
+	q.typing = true
+	q.email = email
+	q.input.Prompt = i18n.T("quick_reply.prompt", map[string]any{"Name": senderName(email.From)}) + " "
+	q.input.SetValue("")
+	return q.input.Focus()
+}
+
+// View renders the bar in place of the help line
+func (q quickReplier) View() string {
+	if !q.typing {
+		return ""
+	}
+	return q.input.View() + components.HelpDescStyle.Render("  enter "+i18n.T("quick_reply.send")+"  esc "+i18n.T("help.cancel"))
+}
+
+// handleQuickReplyKey handles a key while the quick reply bar has focus
+func (a *App) handleQuickReplyKey(msg tea.KeyMsg) tea.Cmd {
+	q := &a.replier
+	switch msg.String() {
+	case "esc":
+		q.typing = false
+		q.input.Blur()
+		return nil
+	case "enter":
+		text := strings.TrimSpace(q.input.Value())
+		if text == "" {
+			return nil
+		}
+		q.typing = false
+		q.input.Blur()
+		account := a.currentAccount()
+		if account == nil {
+			return nil
+		}
+		a.state = stateLoading
+		a.statusMsg = i18n.T("compose.send") + "..."
+		return tea.Batch(a.spinner.Tick, sendQuickReply(account, q.email, text, a.cfg.Reply, a.cfg.Signature))
+	}
+	var cmd tea.Cmd
+	q.input, cmd = q.input.Update(msg)
+	return cmd
+}
+
+// sendQuickReply sends text as a reply to an email, quoted and signed the way replies are
+func sendQuickReply(account *auth.Account, email mail.Email, text string, quoting *config.ReplyConfig, signature string) tea.Cmd {
+	reply := NewReplyModel(account.Credentials.Email, &email, quoting)
+	reply.SetSignature(signature)
+	body, _ := replyBody(text, reply.quotedBody, reply.signature, reply.bottomPost)
+	to, subject := reply.GetTo(), reply.GetSubject()
+
+	return func() tea.Msg {
+		smtpClient := mail.NewSMTPClient(&account.Credentials)
+		if err := smtpClient.Reply(to, subject, body, email.MessageID, email.References); err != nil {
+			return quickReplySentMsg{to: to, err: err}
+		}
+		return quickReplySentMsg{to: to, uid: email.UID}
+	}
+}
+
+// senderName returns the display name of an address, or the address without one
+func senderName(address string) string {
+	if i := strings.Index(address, "<"); i > 0 {
+		return strings.Trim(strings.TrimSpace(address[:i]), `"`)
+	}
+	return extractEmail(address)
+}
@@ -13,6 +13,7 @@ import (
 	"maily/internal/auth"
 	"maily/internal/cache"
 	"maily/internal/client"
+	"maily/internal/i18n"
 	"maily/internal/mail"
 	"maily/internal/ui/components"
 	"maily/internal/ui/utils"
@@ -70,8 +71,11 @@ type SearchApp struct {
 	err                 error
 	message             string
 	scrollCount         int
+	clicks              clickTracker // double clicks on result rows
 	confirmDeleteSingle bool
 	confirmSelection    confirmOption // Selected button in confirm dialogs
+	finder              bodyFinder    // find within the opened email
+	jobs                jobTracker    // server operations run in the background
 }
 
 // searchResultsMsg is sent when search results are loaded.
@@ -111,6 +115,7 @@ func NewSearchApp(account *auth.Account, query string) SearchApp {
 		view:     searchListView,
 		spinner:  s,
 		viewport: vp,
+		finder:   newBodyFinder(),
 	}
 }
 
@@ -194,6 +199,12 @@ func (a *SearchApp) fetchEmailBody(uid imap.UID) tea.Cmd {
 func (a SearchApp) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	switch msg := msg.(type) {
 	case tea.KeyMsg:
+		// Retry the background job that failed
+		if msg.String() == "ctrl+r" {
+			if cmd := a.jobs.retry(); cmd != nil {
+				return a, cmd
+			}
+		}
 		switch a.state {
 		case searchStateReady:
 			return a.handleReadyKeys(msg)
@@ -222,6 +233,9 @@ func (a SearchApp) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		a.viewport.Height = msg.Height - 8
 
 	case tea.MouseMsg:
+		if msg.Action == tea.MouseActionPress && msg.Button == tea.MouseButtonLeft {
+			return a.handleClick(msg)
+		}
 		if a.state == searchStateReady {
 			switch msg.Button {
 			case tea.MouseButtonWheelUp:
@@ -260,10 +274,16 @@ func (a SearchApp) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		a.spinner, cmd = a.spinner.Update(msg)
 		return a, cmd
 
+	case jobDoneMsg:
+		return a, a.jobs.finish(msg)
+
+	case jobClearMsg:
+		a.jobs.clear(msg)
+
 	case searchResultsMsg:
 		a.serverClient = msg.client
 		if len(msg.emails) == 0 {
-			a.message = "No emails found matching your query."
+			a.message = i18n.T("search.no_matches")
 			a.state = searchStateDone
 			return a, nil
 		}
@@ -295,15 +315,15 @@ func (a SearchApp) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		if a.view == searchReadView && a.cursor < len(a.emails) {
 			email := a.emails[a.cursor]
 			if email.UID == msg.uid {
-				a.viewport.SetContent(a.renderEmailContent(email))
+				a.finder.SetContent(&a.viewport, a.renderEmailContent(email))
 			}
 		}
 
 	case actionCompleteMsg:
-		actionName := ""
+		doneID := ""
 		switch a.action {
 		case actionDelete:
-			actionName = "deleted"
+			doneID = "search.deleted"
 			// Remove deleted UIDs and rebuild emails map
 			var remainingUIDs []imap.UID
 			newEmails := make(map[int]mail.Email)
@@ -320,7 +340,7 @@ func (a SearchApp) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			a.uids = remainingUIDs
 			a.emails = newEmails
 		case actionMarkRead:
-			actionName = "marked as read"
+			doneID = "search.marked_read"
 			// Update unread status in the map
 			for i := range a.selected {
 				if a.selected[i] {
@@ -343,11 +363,11 @@ func (a SearchApp) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 
 		// Go back to ready state (list view)
 		a.state = searchStateReady
-		a.message = fmt.Sprintf("Successfully %s %d email(s).", actionName, msg.count)
+		a.message = i18n.TPlural(doneID, msg.count, nil)
 
 		// If no emails left, show done state
 		if len(a.uids) == 0 {
-			a.message = fmt.Sprintf("Successfully %s %d email(s). No more results.", actionName, msg.count)
+			a.message += " " + i18n.T("search.no_more")
 			a.state = searchStateDone
 		}
 	}
@@ -383,19 +403,21 @@ func (a SearchApp) handleReadyKeys(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		if len(a.emails) > 0 && a.cursor < len(a.emails) {
 			email := a.emails[a.cursor]
 			a.view = searchReadView
-			a.viewport.SetContent(a.renderEmailContent(email))
+			a.finder.Reset(components.QueryTerms(a.query))
+			a.finder.SetContent(&a.viewport, a.renderEmailContent(email))
 			a.viewport.GotoTop()
 
 			// Mark as read in background
+			var markRead tea.Cmd
 			if email.Unread && a.serverClient != nil {
 				serverClient := a.serverClient
 				uid := email.UID
 				accountEmail := a.account.Credentials.Email
-				go func() {
-					_ = serverClient.MarkRead(accountEmail, "INBOX", uid)
-				}()
+				markRead = a.jobs.start("job.mark_read", func() error {
+					return serverClient.MarkRead(accountEmail, "INBOX", uid)
+				})
 			}
-			return a, a.fetchEmailBody(email.UID)
+			return a, tea.Batch(a.fetchEmailBody(email.UID), markRead)
 		}
 
 	case "up", "k":
@@ -445,6 +467,10 @@ func (a SearchApp) handleReadyKeys(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 }
 
 func (a SearchApp) handleReadViewKeys(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	if a.finder.typing {
+		return a, a.finder.HandleKey(msg, &a.viewport)
+	}
+
 	switch msg.String() {
 	case "q":
 		if a.serverClient != nil {
@@ -453,10 +479,24 @@ func (a SearchApp) handleReadViewKeys(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		return a, tea.Quit
 
 	case "esc":
-		// Go back to list view
+		// Clear find highlights first, then go back to list view
+		if !a.confirmDeleteSingle && a.finder.Clear(&a.viewport) {
+			return a, nil
+		}
 		a.view = searchListView
 		a.confirmDeleteSingle = false
 
+	case "/":
+		if !a.confirmDeleteSingle {
+			return a, a.finder.Start()
+		}
+
+	case "n":
+		a.finder.Next(&a.viewport, 1)
+
+	case "N":
+		a.finder.Next(&a.viewport, -1)
+
 	case "d":
 		// Delete current email - show confirmation
 		if !a.confirmDeleteSingle {
@@ -501,16 +541,18 @@ func (a SearchApp) handleReadViewKeys(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 						a.cursor--
 					}
 					// Delete in background
+					var deleteCmd tea.Cmd
 					if a.serverClient != nil {
 						serverClient := a.serverClient
 						accountEmail := a.account.Credentials.Email
-						go func() {
-							_ = serverClient.QueueDeleteEmail(accountEmail, "INBOX", uid)
-						}()
+						deleteCmd = a.jobs.start("job.delete", func() error {
+							return serverClient.QueueDeleteEmail(accountEmail, "INBOX", uid)
+						})
 					}
 					// Go back to list view
 					a.view = searchListView
 					a.confirmDeleteSingle = false
+					return a, deleteCmd
 				}
 			} else {
 				// Cancel
@@ -588,7 +630,7 @@ func (a SearchApp) selectedCount() int {
 
 func (a SearchApp) View() string {
 	if a.width == 0 {
-		return "Loading..."
+		return i18n.T("common.loading")
 	}
 
 	var content string
@@ -600,7 +642,7 @@ func (a SearchApp) View() string {
 			a.height-4,
 			lipgloss.Center,
 			lipgloss.Center,
-			fmt.Sprintf("%s Searching...", a.spinner.View()),
+			a.spinner.View()+" "+i18n.T("email.searching"),
 		)
 
 	case searchStateReady:
@@ -620,7 +662,7 @@ func (a SearchApp) View() string {
 			a.height-4,
 			lipgloss.Center,
 			lipgloss.Center,
-			fmt.Sprintf("%s Executing...", a.spinner.View()),
+			a.spinner.View()+" "+i18n.T("search.executing"),
 		)
 
 	case searchStateDone:
@@ -629,7 +671,7 @@ func (a SearchApp) View() string {
 			a.height-4,
 			lipgloss.Center,
 			lipgloss.Center,
-			components.SuccessStyle.Render(a.message+"\n\nPress Enter or q to exit."),
+			components.SuccessStyle.Render(a.message+"\n\n"+i18n.T("search.exit_hint")),
 		)
 
 	case searchStateError:
@@ -638,7 +680,7 @@ func (a SearchApp) View() string {
 			a.height-4,
 			lipgloss.Center,
 			lipgloss.Center,
-			components.ErrorStyle.Render(fmt.Sprintf("Error: %v\n\nPress Enter or q to exit.", a.err)),
+			components.ErrorStyle.Render(i18n.T("search.error", map[string]any{"Error": a.err})+"\n\n"+i18n.T("search.exit_hint")),
 		)
 	}
 
@@ -655,29 +697,28 @@ func (a SearchApp) renderHeader() string {
 
 	queryInfo := lipgloss.NewStyle().
 		Foreground(lipgloss.Color("#9CA3AF")).
-		Render(fmt.Sprintf("Query: %s", a.query))
+		Render(i18n.T("search.query", map[string]any{"Query": a.query}))
 
 	return components.HeaderStyle.Width(a.width).Render(title + "  " + queryInfo)
 }
 
-func (a SearchApp) renderResults() string {
-	var b strings.Builder
-
+// window returns the results shown, from start up to end, keeping the cursor in view
+func (a SearchApp) window() (start, end int) {
 	visibleHeight := a.height - 8
 	if visibleHeight < 1 {
 		visibleHeight = 10
 	}
 
-	start := 0
 	if a.cursor >= visibleHeight {
 		start = a.cursor - visibleHeight + 1
 	}
+	return start, min(start+visibleHeight, len(a.emails))
+}
 
-	end := start + visibleHeight
-	if end > len(a.emails) {
-		end = len(a.emails)
-	}
+func (a SearchApp) renderResults() string {
+	var b strings.Builder
 
+	start, end := a.window()
 	for i := start; i < end; i++ {
 		email := a.emails[i]
 		line := a.renderEmailLine(email, i == a.cursor, a.selected[i])
@@ -702,14 +743,14 @@ func (a SearchApp) renderReadView() string {
 		Foreground(lipgloss.Color("#9CA3AF")).
 		Padding(0, 2)
 
-	fromLine := headerStyle.Render(fmt.Sprintf("From: %s", email.From))
-	toLine := headerStyle.Render(fmt.Sprintf("To: %s", email.To))
+	fromLine := headerStyle.Render(i18n.T("today.from") + email.From)
+	toLine := headerStyle.Render(i18n.T("today.to") + email.To)
 	subjectLine := lipgloss.NewStyle().
 		Bold(true).
 		Foreground(lipgloss.Color("#F9FAFB")).
 		Padding(0, 2).
-		Render(fmt.Sprintf("Subject: %s", email.Subject))
-	dateLine := headerStyle.Render(fmt.Sprintf("Date: %s", email.Date.Format("Mon, 02 Jan 2006 15:04:05")))
+		Render(i18n.T("today.subject") + email.Subject)
+	dateLine := headerStyle.Render(i18n.T("today.date") + i18n.FormatDateTime(email.Date))
 
 	header := lipgloss.JoinVertical(lipgloss.Left,
 		fromLine,
@@ -733,18 +774,18 @@ func (a SearchApp) renderReadView() string {
 
 		var yesBtn, noBtn string
 		if a.confirmSelection == confirmOptionYes {
-			yesBtn = selectedStyle.Render("Yes")
-			noBtn = unselectedStyle.Render("No")
+			yesBtn = selectedStyle.Render(i18n.T("common.yes"))
+			noBtn = unselectedStyle.Render(i18n.T("common.no"))
 		} else {
-			yesBtn = unselectedStyle.Render("Yes")
-			noBtn = selectedStyle.Background(lipgloss.Color("#6B7280")).Render("No")
+			yesBtn = unselectedStyle.Render(i18n.T("common.yes"))
+			noBtn = selectedStyle.Background(lipgloss.Color("#6B7280")).Render(i18n.T("common.no"))
 		}
 
 		buttons := lipgloss.JoinHorizontal(lipgloss.Center, yesBtn, "  ", noBtn)
 
 		hint := lipgloss.NewStyle().
 			Foreground(lipgloss.Color("#9CA3AF")).
-			Render("← → to select, enter to confirm, esc to cancel")
+			Render(i18n.T("dialog.delete.hint"))
 
 		confirmDialog := lipgloss.NewStyle().
 			Border(lipgloss.RoundedBorder()).
@@ -753,7 +794,7 @@ func (a SearchApp) renderReadView() string {
 			Align(lipgloss.Center).
 			Render(
 				lipgloss.JoinVertical(lipgloss.Center,
-					lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("#EF4444")).Render("Delete this email?"),
+					lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("#EF4444")).Render(i18n.T("dialog.delete.title")),
 					"",
 					buttons,
 					"",
@@ -784,7 +825,7 @@ func (a SearchApp) renderEmailLine(email mail.Email, cursor bool, selected bool)
 
 	from := utils.TruncateStr(utils.ExtractNameFromEmail(email.From), 20)
 	subject := utils.TruncateStr(email.Subject, maxWidth-35)
-	date := utils.FormatEmailDate(email.Date)
+	date := i18n.FormatDate(email.Date)
 
 	// Selection indicator
 	var checkbox string
@@ -810,10 +851,9 @@ func (a SearchApp) renderEmailLine(email mail.Email, cursor bool, selected bool)
 		attachIcon = "  " // Same width placeholder
 	}
 
-	line := fmt.Sprintf("%-20s │ %-*s │ %s",
-		from,
-		maxWidth-35,
-		subject,
+	line := fmt.Sprintf("%s │ %s │ %s",
+		utils.PadRight(from, 20),
+		utils.PadRight(subject, maxWidth-35),
 		date,
 	)
 
@@ -836,15 +876,15 @@ func (a SearchApp) renderEmailLine(email mail.Email, cursor bool, selected bool)
 }
 
 func (a SearchApp) renderConfirmDialog() string {
-	actionName := ""
+	titleID := ""
 	actionColor := lipgloss.Color("#EF4444")
 
 	switch a.action {
 	case actionDelete:
-		actionName = "Delete"
+		titleID = "search.confirm_delete"
 		actionColor = lipgloss.Color("#EF4444")
 	case actionMarkRead:
-		actionName = "Mark as read"
+		titleID = "search.confirm_mark_read"
 		actionColor = lipgloss.Color("#3B82F6")
 	}
 
@@ -857,7 +897,7 @@ func (a SearchApp) renderConfirmDialog() string {
 	title := lipgloss.NewStyle().
 		Bold(true).
 		Foreground(actionColor).
-		Render(fmt.Sprintf("%s %d email(s)?", actionName, a.selectedCount()))
+		Render(i18n.TPlural(titleID, a.selectedCount(), nil))
 
 	selectedStyle := lipgloss.NewStyle().
 		Bold(true).
@@ -871,18 +911,18 @@ func (a SearchApp) renderConfirmDialog() string {
 
 	var yesBtn, noBtn string
 	if a.confirmSelection == confirmOptionYes {
-		yesBtn = selectedStyle.Render("Yes")
-		noBtn = unselectedStyle.Render("No")
+		yesBtn = selectedStyle.Render(i18n.T("common.yes"))
+		noBtn = unselectedStyle.Render(i18n.T("common.no"))
 	} else {
-		yesBtn = unselectedStyle.Render("Yes")
-		noBtn = selectedStyle.Background(lipgloss.Color("#6B7280")).Render("No")
+		yesBtn = unselectedStyle.Render(i18n.T("common.yes"))
+		noBtn = selectedStyle.Background(lipgloss.Color("#6B7280")).Render(i18n.T("common.no"))
 	}
 
 	buttons := lipgloss.JoinHorizontal(lipgloss.Center, yesBtn, "  ", noBtn)
 
 	hint := lipgloss.NewStyle().
 		Foreground(lipgloss.Color("#9CA3AF")).
-		Render("← → to select, enter to confirm, esc to cancel")
+		Render(i18n.T("dialog.delete.hint"))
 
 	return lipgloss.Place(
 		a.width,
@@ -907,12 +947,15 @@ func (a SearchApp) renderStatusBar() string {
 
 	switch a.state {
 	case searchStateReady:
-		if a.view == searchReadView {
+		if a.view == searchReadView && a.finder.View() != "" {
+			help = a.finder.View()
+		} else if a.view == searchReadView {
 			// Read view help
-			help = components.HelpKeyStyle.Render("esc") + components.HelpDescStyle.Render(" back  ") +
-				components.HelpKeyStyle.Render("d") + components.HelpDescStyle.Render(" delete  ") +
-				components.HelpKeyStyle.Render("j/k") + components.HelpDescStyle.Render(" scroll  ") +
-				components.HelpKeyStyle.Render("q") + components.HelpDescStyle.Render(" quit")
+			help = components.HelpKeyStyle.Render("/") + components.HelpDescStyle.Render(" "+i18n.T("help.find")+"  ") +
+				components.HelpKeyStyle.Render("esc") + components.HelpDescStyle.Render(" "+i18n.T("help.back")+"  ") +
+				components.HelpKeyStyle.Render("d") + components.HelpDescStyle.Render(" "+i18n.T("help.delete")+"  ") +
+				components.HelpKeyStyle.Render("j/k") + components.HelpDescStyle.Render(" "+i18n.T("today.scroll")+"  ") +
+				components.HelpKeyStyle.Render("q") + components.HelpDescStyle.Render(" "+i18n.T("help.quit"))
 		} else {
 			// List view help
 			selectedInfo := ""
@@ -920,15 +963,15 @@ func (a SearchApp) renderStatusBar() string {
 				selectedInfo = lipgloss.NewStyle().
 					Bold(true).
 					Foreground(lipgloss.Color("#10B981")).
-					Render(fmt.Sprintf(" %d selected ", count))
+					Render(" " + i18n.TPlural("email.selected", count, nil) + " ")
 			}
 
-			help = components.HelpKeyStyle.Render("enter") + components.HelpDescStyle.Render(" read  ") +
-				components.HelpKeyStyle.Render("space") + components.HelpDescStyle.Render(" toggle  ") +
-				components.HelpKeyStyle.Render("a") + components.HelpDescStyle.Render(" all  ") +
-				components.HelpKeyStyle.Render("d") + components.HelpDescStyle.Render(" delete  ") +
-				components.HelpKeyStyle.Render("r") + components.HelpDescStyle.Render(" mark read  ") +
-				components.HelpKeyStyle.Render("q") + components.HelpDescStyle.Render(" quit") +
+			help = components.HelpKeyStyle.Render("enter") + components.HelpDescStyle.Render(" "+i18n.T("help.open")+"  ") +
+				components.HelpKeyStyle.Render("space") + components.HelpDescStyle.Render(" "+i18n.T("help.toggle")+"  ") +
+				components.HelpKeyStyle.Render("a") + components.HelpDescStyle.Render(" "+i18n.T("help.select_all")+"  ") +
+				components.HelpKeyStyle.Render("d") + components.HelpDescStyle.Render(" "+i18n.T("help.delete")+"  ") +
+				components.HelpKeyStyle.Render("r") + components.HelpDescStyle.Render(" "+i18n.T("help.mark_read")+"  ") +
+				components.HelpKeyStyle.Render("q") + components.HelpDescStyle.Render(" "+i18n.T("help.quit")) +
 				selectedInfo
 		}
 	default:
@@ -940,8 +983,9 @@ func (a SearchApp) renderStatusBar() string {
 	if a.message != "" && a.state == searchStateReady {
 		status = components.SuccessStyle.Render(a.message)
 	} else {
-		status = components.StatusKeyStyle.Render(fmt.Sprintf("%d/%d emails", len(a.emails), len(a.uids)))
+		status = components.StatusKeyStyle.Render(i18n.T("search.loaded_count", map[string]any{"Loaded": len(a.emails), "Total": len(a.uids)}))
 	}
+	status = components.RenderJobStatus(a.jobs.status(), a.spinner.View()) + status
 
 	gap := a.width - lipgloss.Width(help) - lipgloss.Width(status) - 4
 	if gap < 0 {
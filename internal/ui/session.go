@@ -0,0 +1,142 @@
+package ui
+
+import (
+	"encoding/json"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/emersion/go-imap/v2"
+
+	"maily/internal/cache"
+)
+
+// The TUI reopens where it was left: on the account last shown, in that account's
+// folder, with the cursor on the same email and the email open if it was. Each account
+// keeps its own place, so tabbing to another account lands where it was left too. The
+// places are kept in the cache's settings; with several instances running, the last to
+// quit wins.
+
+const (
+	// sessionSetting holds the session as JSON
+	sessionSetting = "tui_session"
+	// calendarDateSetting holds the day last viewed in the calendar, as YYYY-MM-DD
+	calendarDateSetting = "calendar_date"
+)
+
+// session is where the TUI was left
+type session struct {
+	Account string                  `json:"account,omitempty"` // email of the account shown
+	Places  map[string]accountPlace `json:"places,omitempty"`  // keyed by account email
+}
+
+// accountPlace is where an account was left
+type accountPlace struct {
+	Folder string   `json:"folder,omitempty"`
+	Offset int      `json:"offset,omitempty"` // position of the first loaded email in the folder
+	UID    imap.UID `json:"uid,omitempty"`    // the email under the cursor
+	Open   bool     `json:"open,omitempty"`   // the email was open in the read view
+}
+
+// loadSession returns the saved session, empty when there is none
+func loadSession(c *cache.Cache) session {
+	var s session
+	if c == nil {
+		return s
+	}
+	if value, err := c.Setting(sessionSetting); err == nil && value != "" {
+		json.Unmarshal([]byte(value), &s)
+	}
+	return s
+}
+
+// rememberPlace records where the current account is, before leaving it
+func (a *App) rememberPlace() {
+	account := a.currentAccount()
+	if account == nil {
+		return
+	}
+	place := accountPlace{Folder: a.currentLabel, Offset: a.pageOffset}
+	if a.isSearchResult || a.smartFolder != nil || a.newsletters {
+		// Those lists aren't restored; reopen the folder they were opened from
+		place.Offset = 0
+	} else if email := a.mailList.SelectedEmail(); email != nil {
+		place.UID = email.UID
+		place.Open = a.view == readView
+	}
+	if a.session.Places == nil {
+		a.session.Places = make(map[string]accountPlace)
+	}
+	a.session.Places[account.Credentials.Email] = place
+}
+
+// enterPlace moves to where the current account was left; the cursor is placed once its
+// emails are loaded
+func (a *App) enterPlace() {
+	account := a.currentAccount()
+	if account == nil {
+		return
+	}
+	place, ok := a.session.Places[account.Credentials.Email]
+	if !ok || place.Folder == "" {
+		return
+	}
+	a.currentLabel = place.Folder
+	a.pageOffset = place.Offset
+	a.restore = &place
+}
+
+// restorePlace puts the cursor back on the email it was on, opening it if it was open.
+// It waits for the window size, which the read view is laid out for.
+func (a *App) restorePlace() tea.Cmd {
+	if a.width == 0 {
+		return nil
+	}
+	place := a.restore
+	a.restore = nil
+	if place == nil || place.UID == 0 || !a.mailList.SelectUID(place.UID) {
+		return nil
+	}
+	if place.Open {
+		return a.openSelectedEmail()
+	}
+	return nil
+}
+
+// SaveSession stores where the TUI is, to reopen there next time
+func (a App) SaveSession() error {
+	if a.diskCache == nil || a.composeOnly {
+		return nil
+	}
+	a.rememberPlace()
+	a.saveDrafts()
+	if account := a.currentAccount(); account != nil {
+		a.session.Account = account.Credentials.Email
+	}
+	data, err := json.Marshal(a.session)
+	if err != nil {
+		return err
+	}
+	return a.diskCache.SetSetting(sessionSetting, string(data))
+}
+
+// RestoreDate opens the calendar on the day last viewed
+func (m *CalendarApp) RestoreDate(c *cache.Cache) {
+	if c == nil {
+		return
+	}
+	value, err := c.Setting(calendarDateSetting)
+	if err != nil || value == "" {
+		return
+	}
+	if day, err := time.ParseInLocation(time.DateOnly, value, time.Local); err == nil {
+		m.selectedDate = day
+	}
+}
+
+// SaveDate stores the day being viewed, for RestoreDate
+func (m *CalendarApp) SaveDate(c *cache.Cache) error {
+	if c == nil {
+		return nil
+	}
+	return c.SetSetting(calendarDateSetting, m.selectedDate.Format(time.DateOnly))
+}
@@ -0,0 +1,161 @@
+package ui
+
+import (
+	"fmt"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"maily/config"
+	"maily/internal/cache"
+	"maily/internal/client"
+	"maily/internal/i18n"
+	"maily/internal/mail"
+	"maily/internal/ui/components"
+)
+
+// smartFoldersCountedMsg carries the number of cached emails in each smart folder, -1
+// where it couldn't be counted
+type smartFoldersCountedMsg struct {
+	accountEmail string
+	counts       map[string]int
+}
+
+// smartFolders returns the smart folders shown for the current account
+func (a App) smartFolders() []config.SmartFolder {
+	account := a.currentAccount()
+	if account == nil {
+		return nil
+	}
+	var folders []config.SmartFolder
+	for _, f := range a.cfg.SmartFolders {
+		if f.Name != "" && f.AppliesTo(account.Credentials.Email) {
+			folders = append(folders, f)
+		}
+	}
+	return folders
+}
+
+// smartFilter returns the cache filter selecting a smart folder's emails
+func smartFilter(f config.SmartFolder, now time.Time) cache.EmailFilter {
+	filter := cache.EmailFilter{
+		From:           f.From,
+		Subject:        f.Subject,
+		UnreadOnly:     f.Unread,
+		HasAttachments: f.Attachments,
+		Limit:          maxListWindow,
+	}
+	if f.Days > 0 {
+		filter.Since = now.AddDate(0, 0, -f.Days)
+	}
+	return filter
+}
+
+// openLabelPicker shows the folder picker and counts the emails in the smart folders
+// listed above the folders, so the counts follow what has synced since it was last open
+func (a *App) openLabelPicker() tea.Cmd {
+	if a.smartFolder != nil {
+		a.labelPicker.SetSelectedSmartFolder(a.smartFolder.Name)
+	} else {
+		a.labelPicker.SetSelected(a.currentLabel)
+	}
+	a.showLabelPicker = true
+
+	folders := a.smartFolders()
+	a.labelPicker.SetSmartFolders(a.smartFolderItems(nil))
+	if len(folders) == 0 {
+		return nil
+	}
+
+	accountEmail := a.currentAccount().Credentials.Email
+	serverClient := a.serverClient
+	diskCache := a.diskCache
+	return func() tea.Msg {
+		now := time.Now()
+		counts := make(map[string]int, len(folders))
+		for _, f := range folders {
+			filter := smartFilter(f, now)
+			count, err := -1, fmt.Errorf("no server or cache available")
+			if serverClient != nil {
+				count, err = serverClient.CountEmailsFiltered(accountEmail, f.FolderMailbox(), filter)
+			}
+			if err != nil && diskCache != nil {
+				count, err = diskCache.CountEmailsFiltered(accountEmail, f.FolderMailbox(), filter)
+			}
+			if err != nil {
+				count = -1
+			}
+			counts[f.Name] = count
+		}
+		return smartFoldersCountedMsg{accountEmail: accountEmail, counts: counts}
+	}
+}
+
+// smartFolderItems lists the current account's smart folders for the picker with their
+// counts, -1 for those not counted yet
+func (a App) smartFolderItems(counts map[string]int) []components.SmartFolder {
+	folders := a.smartFolders()
+	items := make([]components.SmartFolder, len(folders))
+	for i, f := range folders {
+		count, ok := counts[f.Name]
+		if !ok {
+			count = -1
+		}
+		items[i] = components.SmartFolder{Name: f.Name, Count: count}
+	}
+	return items
+}
+
+// openSmartFolder shows the emails of the named smart folder
+func (a *App) openSmartFolder(name string) tea.Cmd {
+	for _, f := range a.smartFolders() {
+		if f.Name != name {
+			continue
+		}
+		a.setNewsletters(false)
+		a.smartFolder = &f
+		a.currentLabel = f.FolderMailbox()
+		a.labelPicker.SetSelectedSmartFolder(name)
+		a.pageOffset = 0
+		a.state = stateLoading
+		a.statusMsg = i18n.T("common.loading")
+		return tea.Batch(a.spinner.Tick, a.loadEmails())
+	}
+	return nil
+}
+
+// loadSmartFolder reads the emails matching a smart folder, newest first, from the
+// server or, when it is unavailable, the disk cache
+func loadSmartFolder(serverClient *client.Client, diskCache *cache.Cache, account string, f config.SmartFolder) ([]mail.Email, error) {
+	var cached []cache.CachedEmail
+	err := fmt.Errorf("no server or cache available")
+	filter := smartFilter(f, time.Now())
+
+	if serverClient != nil {
+		cached, err = serverClient.GetEmailsFiltered(account, f.FolderMailbox(), filter)
+	}
+	if err != nil && diskCache != nil {
+		cached, err = diskCache.LoadEmailsFiltered(account, f.FolderMailbox(), filter)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	emails := make([]mail.Email, len(cached))
+	for i, c := range cached {
+		emails[i] = cachedToGmail(c)
+	}
+	return emails, nil
+}
+
+// folderName returns the name of what the list is showing: a smart folder, the
+// newsletters view, or a folder or label
+func (a App) folderName() string {
+	switch {
+	case a.smartFolder != nil:
+		return a.smartFolder.Name
+	case a.newsletters:
+		return i18n.T("list.newsletters")
+	}
+	return components.GetLabelDisplayName(a.currentLabel)
+}
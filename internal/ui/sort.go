@@ -0,0 +1,65 @@
+package ui
+
+import (
+	tea "github.com/charmbracelet/bubbletea"
+
+	"maily/config"
+	"maily/internal/cache"
+	"maily/internal/i18n"
+)
+
+// sortOrder returns how the list is sorted: the current folder's saved order, or newest
+// first for smart folders and the newsletters view
+func (a App) sortOrder() string {
+	if a.smartFolder != nil || a.newsletters {
+		return cache.SortDate
+	}
+	if order := a.cfg.List.SortOrder(a.currentLabel); order != "" {
+		return order
+	}
+	return cache.SortDate
+}
+
+// folderCountStatus is the status shown once a folder's emails are listed, with the sort
+// order unless it is newest first, or what the quick filters leave listed
+func (a App) folderCountStatus(label string, count int) string {
+	if a.mailList.Filter() != 0 {
+		return label + ": " + a.filterStatus()
+	}
+	if order := a.sortOrder(); order != cache.SortDate {
+		return i18n.T("email.folder_count_sorted", map[string]any{"Label": label, "Count": count, "Order": i18n.T("list.sort_" + order)})
+	}
+	return i18n.T("email.folder_count", map[string]any{"Label": label, "Count": count})
+}
+
+// cycleSort switches the current folder to the next sort order, reloads the list from
+// its start and saves the order in the config
+func (a *App) cycleSort() tea.Cmd {
+	current := a.sortOrder()
+	next := cache.SortOrders[0]
+	for i, order := range cache.SortOrders {
+		if order == current {
+			next = cache.SortOrders[(i+1)%len(cache.SortOrders)]
+			break
+		}
+	}
+
+	if a.cfg.List == nil {
+		a.cfg.List = &config.ListConfig{}
+	}
+	a.cfg.List.SetSortOrder(a.currentLabel, next)
+	a.pageOffset = 0
+	a.state = stateLoading
+	a.statusMsg = i18n.T("common.loading")
+
+	mailbox := a.currentLabel
+	return tea.Batch(a.spinner.Tick, a.reloadFromCache(), func() tea.Msg {
+		_, err := config.Update(func(cfg *config.Config) {
+			if cfg.List == nil {
+				cfg.List = &config.ListConfig{}
+			}
+			cfg.List.SetSortOrder(mailbox, next)
+		})
+		return configSavedMsg{err: err}
+	})
+}
@@ -3,6 +3,7 @@ package ui
 import (
 	"fmt"
 	"runtime"
+	"slices"
 	"strings"
 	"time"
 
@@ -13,11 +14,15 @@ import (
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
 	"github.com/emersion/go-imap/v2"
+	"maily/config"
+	"maily/internal/ai"
 	"maily/internal/auth"
+	"maily/internal/cache"
 	"maily/internal/calendar"
 	"maily/internal/client"
 	"maily/internal/i18n"
 	"maily/internal/mail"
+	"maily/internal/server"
 	"maily/internal/ui/components"
 	"maily/internal/ui/utils"
 )
@@ -42,7 +47,9 @@ const (
 
 // AccountEmails holds emails for a single account
 type AccountEmails struct {
-	Email  string // account email address
+	Email  string         // account email address
+	Name   string         // display name
+	Color  lipgloss.Color // accent color
 	Emails []mail.Email
 }
 
@@ -61,26 +68,43 @@ type TodayApp struct {
 
 	// Email state (per account)
 	accountEmails []AccountEmails
-	emails        []mail.Email // flattened list for navigation
+	emails        []mail.Email // flattened list of the emails shown, for navigation
+	emailAccounts []int        // the account of each email in emails
 	emailCursor   int
 
+	// Email filters
+	cfg           *config.Config // saved when accounts are hidden or shown
+	filters       *config.TodayConfig
+	unreadOnly    bool
+	importantOnly bool
+	vipOnly       bool
+	aiClient      *ai.Client
+	scores        map[string]int // AI triage scores, by triageKey
+	triaging      bool
+	triageErr     error
+
 	// Event state
 	events      []calendar.Event
 	eventCursor int
+	settings    *config.CalendarConfig // travel times to events
 
 	// UI
 	spinner  spinner.Model
 	viewport viewport.Model
+	finder   bodyFinder // find within the opened email
+	jobs     jobTracker // server operations run in the background
 
 	// Edit event form
 	editFormTitle    textinput.Model
-	editFormDate     textinput.Model
+	editFormDate     components.DatePicker
 	editFormStart    textinput.Model
 	editFormEnd      textinput.Model
 	editFormLocation textinput.Model
 	editFormNotes    textarea.Model
 	editFormFocus    int
 	editEventID      string
+	editEventDays    int  // the edited event's days, kept when it's moved
+	editEventAllDay  bool // the edited event is all-day, which the form keeps
 }
 
 // Messages
@@ -102,18 +126,27 @@ type todayEmailDeletedMsg struct{}
 type todayEventDeletedMsg struct{}
 type todayEventUpdatedMsg struct{}
 
+// todayServerReadyMsg carries the connection to the server, nil when none is running
+// and emails are read over IMAP instead
 type todayServerReadyMsg struct {
 	client *client.Client
 }
 
+// todayTriagedMsg carries the AI's scores of how much emails need attention
+type todayTriagedMsg struct {
+	scores map[string]int
+	err    error
+}
+
 type todayEmailBodyLoadedMsg struct {
 	uid      imap.UID
 	bodyHTML string
 	snippet  string
 }
 
-// NewTodayApp creates a new today dashboard TUI
-func NewTodayApp(store *auth.AccountStore, calClient calendar.Client) *TodayApp {
+// NewTodayApp creates a new today dashboard TUI; cfg sets the travel times used to
+// tell when to leave for events and the email filters
+func NewTodayApp(store *auth.AccountStore, calClient calendar.Client, cfg *config.Config) *TodayApp {
 	s := spinner.New()
 	s.Spinner = spinner.Dot
 	s.Style = components.SpinnerStyle
@@ -124,6 +157,13 @@ func NewTodayApp(store *auth.AccountStore, calClient calendar.Client) *TodayApp
 	return &TodayApp{
 		store:         store,
 		calClient:     calClient,
+		settings:      cfg.Calendar,
+		cfg:           cfg,
+		filters:       cfg.Today,
+		unreadOnly:    cfg.Today != nil && cfg.Today.UnreadOnly,
+		importantOnly: cfg.Today != nil && cfg.Today.ImportantOnly,
+		aiClient:      ai.NewClient(),
+		scores:        make(map[string]int),
 		activePanel:   emailPanel,
 		view:          todayDashboard,
 		loading:       true,
@@ -131,6 +171,7 @@ func NewTodayApp(store *auth.AccountStore, calClient calendar.Client) *TodayApp
 		accountEmails: make([]AccountEmails, len(store.Accounts)),
 		spinner:       s,
 		viewport:      vp,
+		finder:        newBodyFinder(),
 	}
 }
 
@@ -148,33 +189,45 @@ func (m *TodayApp) connectServer() tea.Cmd {
 	return func() tea.Msg {
 		serverClient, err := client.Connect()
 		if err != nil {
-			return todayErrMsg{err: err}
+			return todayServerReadyMsg{}
 		}
 		return todayServerReadyMsg{client: serverClient}
 	}
 }
 
+// loadTodayEmails loads the emails the account received today from the server's
+// cache, or straight from the inbox when no server is running
 func (m *TodayApp) loadTodayEmails(accountIdx int) tea.Cmd {
+	account := m.store.Accounts[accountIdx]
+	serverClient := m.serverClient
 	return func() tea.Msg {
-		account := m.store.Accounts[accountIdx]
-		if m.serverClient == nil {
-			return todayErrMsg{err: fmt.Errorf("server unavailable")}
-		}
-
-		// Load today's emails (received today)
-		cached, err := m.serverClient.GetEmails(account.Credentials.Email, "INBOX", 50)
-		if err != nil {
-			return todayErrMsg{err}
-		}
-
-		// Filter to today's emails only
 		today := time.Now()
 		todayStart := time.Date(today.Year(), today.Month(), today.Day(), 0, 0, 0, 0, today.Location())
 
+		var emails []mail.Email
+		if serverClient != nil {
+			cached, err := serverClient.GetEmailsFiltered(account.Credentials.Email, "INBOX", cache.EmailFilter{Since: todayStart, Limit: 500})
+			if err != nil {
+				return todayErrMsg{err}
+			}
+			for _, c := range cached {
+				emails = append(emails, cachedToGmail(c))
+			}
+		} else {
+			imapClient, err := mail.NewClient(&account.Credentials)
+			if err != nil {
+				return todayErrMsg{err}
+			}
+			defer imapClient.Close()
+			if emails, err = imapClient.FetchMessagesSince("INBOX", todayStart, 200); err != nil {
+				return todayErrMsg{err}
+			}
+		}
+
+		// Older servers ignore the since filter and send the newest emails of any day
 		var todayEmails []mail.Email
-		for _, c := range cached {
-			email := cachedToGmail(c)
-			if email.Date.After(todayStart) || email.Date.Equal(todayStart) {
+		for _, email := range emails {
+			if !email.InternalDate.Before(todayStart) {
 				todayEmails = append(todayEmails, email)
 			}
 		}
@@ -191,8 +244,9 @@ func (m *TodayApp) loadTodayEvents() tea.Cmd {
 	return func() tea.Msg {
 		today := time.Now()
 		start := time.Date(today.Year(), today.Month(), today.Day(), 0, 0, 0, 0, today.Location())
-		end := start.Add(24 * time.Hour)
+		end := start.AddDate(0, 0, 1)
 
+		// Multi-day events that began before today are included as well
 		events, err := m.calClient.ListEvents(start, end)
 		if err != nil {
 			return todayErrMsg{err}
@@ -222,22 +276,58 @@ func (m *TodayApp) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		for i := range m.store.Accounts {
 			cmds = append(cmds, m.loadTodayEmails(i))
 		}
+		if m.serverClient != nil {
+			cmds = append(cmds, waitForServerEvent(m.serverClient.Events()))
+		}
+		return m, tea.Batch(cmds...)
+
+	case serverEventMsg:
+		// Reload an account's emails when the server syncs new ones in
+		cmds := []tea.Cmd{waitForServerEvent(m.serverClient.Events())}
+		switch msg.event.Type {
+		case server.EventReconnected:
+			// A new server took over; catch up on emails missed while disconnected
+			for i := range m.store.Accounts {
+				cmds = append(cmds, m.loadTodayEmails(i))
+			}
+		case server.EventSyncCompleted, server.EventNewEmails:
+			for i, account := range m.store.Accounts {
+				if account.Credentials.Email == msg.event.Account {
+					cmds = append(cmds, m.loadTodayEmails(i))
+				}
+			}
+		}
 		return m, tea.Batch(cmds...)
 
 	case todayEmailsLoadedMsg:
 		// Store emails for this account
+		account := m.store.Accounts[msg.accountIdx]
 		m.accountEmails[msg.accountIdx] = AccountEmails{
 			Email:  msg.email,
+			Name:   account.DisplayName(),
+			Color:  components.AccountColor(account.Color, msg.accountIdx),
 			Emails: msg.emails,
 		}
 		// Rebuild flattened list
 		m.rebuildEmailList()
-		m.loadingCount--
-		if m.loadingCount <= 0 {
-			m.loading = false
+		if m.loading {
+			m.loadingCount--
+			m.loading = m.loadingCount > 0
+		}
+		if m.importantOnly {
+			return m, m.triageEmails()
 		}
 		return m, nil
 
+	case todayTriagedMsg:
+		m.triaging = false
+		m.triageErr = msg.err
+		for key, score := range msg.scores {
+			m.scores[key] = score
+		}
+		m.rebuildEmailList()
+		return m, nil
+
 	case todayEventsLoadedMsg:
 		m.events = msg.events
 		return m, nil
@@ -271,12 +361,25 @@ func (m *TodayApp) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		if m.view == todayEmailContent && m.emailCursor < len(m.emails) {
 			email := m.emails[m.emailCursor]
 			if email.UID == msg.uid {
-				m.viewport.SetContent(m.renderEmailContent(email))
+				m.finder.SetContent(&m.viewport, m.renderEmailContent(email))
 			}
 		}
 		return m, nil
 
+	case jobDoneMsg:
+		return m, m.jobs.finish(msg)
+
+	case jobClearMsg:
+		m.jobs.clear(msg)
+		return m, nil
+
 	case tea.KeyMsg:
+		// Retry the background job that failed, outside the event form's fields
+		if msg.String() == "ctrl+r" && m.view != todayEditEvent {
+			if cmd := m.jobs.retry(); cmd != nil {
+				return m, cmd
+			}
+		}
 		// Route to appropriate handler based on view
 		switch m.view {
 		case todayDeleteConfirm:
@@ -292,28 +395,146 @@ func (m *TodayApp) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 }
 
 func (m *TodayApp) rebuildEmailList() {
-	// Flatten all account emails into a single list for navigation
-	m.emails = nil
-	for _, acc := range m.accountEmails {
-		m.emails = append(m.emails, acc.Emails...)
+	// Keep the cursor on the email it was on, when the filters still let it through
+	selectedAccount, selectedUID := -1, imap.UID(0)
+	if m.emailCursor < len(m.emails) {
+		selectedAccount, selectedUID = m.emailAccounts[m.emailCursor], m.emails[m.emailCursor].UID
+	}
+
+	// Flatten the account emails the filters let through into a single list for navigation
+	m.emails, m.emailAccounts = nil, nil
+	for i, acc := range m.accountEmails {
+		for _, email := range acc.Emails {
+			if m.shown(i, email) {
+				if i == selectedAccount && email.UID == selectedUID {
+					m.emailCursor = len(m.emails)
+				}
+				m.emails = append(m.emails, email)
+				m.emailAccounts = append(m.emailAccounts, i)
+			}
+		}
 	}
+	m.emailCursor = max(0, min(m.emailCursor, len(m.emails)-1))
 }
 
 func (m *TodayApp) findAccountForEmail(emailIdx int) int {
 	// Find which account the email at emailIdx belongs to
-	idx := 0
+	if emailIdx >= 0 && emailIdx < len(m.emailAccounts) {
+		return m.emailAccounts[emailIdx]
+	}
+	return 0
+}
+
+// toggleAccount hides the account's emails from the dashboard, or shows them again,
+// and saves the choice in the config
+func (m *TodayApp) toggleAccount(accountIdx int) tea.Cmd {
+	account := m.store.Accounts[accountIdx].Credentials.Email
+	today := config.TodayConfig{}
+	if m.cfg.Today != nil {
+		today = *m.cfg.Today
+	}
+	if today.HiddenAccount(account) {
+		today.HiddenAccounts = slices.DeleteFunc(slices.Clone(today.HiddenAccounts), func(a string) bool {
+			return strings.EqualFold(strings.TrimSpace(a), account)
+		})
+	} else {
+		today.HiddenAccounts = append(slices.Clone(today.HiddenAccounts), account)
+	}
+	m.cfg.Today = &today
+	m.filters = &today
+	m.rebuildEmailList()
+
+	cfg := *m.cfg
+	return m.jobs.start("job.save_config", cfg.Save)
+}
+
+// importantScore is the AI triage score from which an email is important
+const importantScore = 4
+
+// shown reports whether the email filters let an email of the account's through
+func (m *TodayApp) shown(accountIdx int, email mail.Email) bool {
+	switch {
+	case m.filters.HiddenAccount(m.store.Accounts[accountIdx].Credentials.Email):
+		return false
+	case m.unreadOnly && !email.Unread:
+		return false
+	case m.vipOnly && !m.vip(email):
+		return false
+	case m.importantOnly && !m.important(accountIdx, email):
+		return false
+	}
+	return true
+}
+
+// vip reports whether the email is from one of the VIP senders in the config
+func (m *TodayApp) vip(email mail.Email) bool {
+	return m.filters.VIP(strings.TrimSpace(extractEmail(email.From)))
+}
+
+// important reports whether an email needs attention: it's from a VIP, Gmail marked
+// it important, or the AI triage scored it high enough
+func (m *TodayApp) important(accountIdx int, email mail.Email) bool {
+	if m.vip(email) || slices.Contains(email.Labels, `\Important`) {
+		return true
+	}
+	return m.scores[triageKey(accountIdx, email.UID)] >= importantScore
+}
+
+// triageKey identifies an email among all accounts' for the triage scores
+func triageKey(accountIdx int, uid imap.UID) string {
+	return fmt.Sprintf("%d/%d", accountIdx, uid)
+}
+
+// triageEmails asks the AI to score the emails it hasn't scored yet; nil when there's
+// nothing to score or no AI
+func (m *TodayApp) triageEmails() tea.Cmd {
+	if m.triaging || !m.aiClient.Available() {
+		return nil
+	}
+	var keys []string
+	var emails []ai.TriageEmail
 	for i, acc := range m.accountEmails {
-		if emailIdx < idx+len(acc.Emails) {
-			return i
+		for _, email := range acc.Emails {
+			key := triageKey(i, email.UID)
+			if _, ok := m.scores[key]; !ok {
+				keys = append(keys, key)
+				emails = append(emails, ai.TriageEmail{
+					From:    email.From,
+					Subject: email.Subject,
+					Snippet: utils.TruncateStr(email.Snippet, 200),
+				})
+			}
 		}
-		idx += len(acc.Emails)
 	}
-	return 0
+	if len(emails) == 0 {
+		return nil
+	}
+
+	m.triaging = true
+	aiClient := m.aiClient
+	return func() tea.Msg {
+		response, err := aiClient.Call(ai.TriagePrompt(emails))
+		if err != nil {
+			return todayTriagedMsg{err: err}
+		}
+		list, err := ai.ParseTriageResponse(response, len(emails))
+		if err != nil {
+			return todayTriagedMsg{err: err}
+		}
+		scores := make(map[string]int, len(keys))
+		for i, key := range keys {
+			scores[key] = list[i]
+		}
+		return todayTriagedMsg{scores: scores}
+	}
 }
 
 func (m *TodayApp) handleKeyPress(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 	// Handle email content view
 	if m.view == todayEmailContent {
+		if m.finder.typing {
+			return m, m.finder.HandleKey(msg, &m.viewport)
+		}
 		switch msg.String() {
 		case "q", "ctrl+c":
 			if m.serverClient != nil {
@@ -321,8 +542,20 @@ func (m *TodayApp) handleKeyPress(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 			}
 			return m, tea.Quit
 		case "esc":
+			// Clear find highlights first, then go back to the dashboard
+			if m.finder.Clear(&m.viewport) {
+				return m, nil
+			}
 			m.view = todayDashboard
 			return m, nil
+		case "/":
+			return m, m.finder.Start()
+		case "n":
+			m.finder.Next(&m.viewport, 1)
+			return m, nil
+		case "N":
+			m.finder.Next(&m.viewport, -1)
+			return m, nil
 		case "up":
 			m.viewport.ScrollUp(3)
 			return m, nil
@@ -382,28 +615,37 @@ func (m *TodayApp) handleKeyPress(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		if m.activePanel == emailPanel && len(m.emails) > 0 && m.emailCursor < len(m.emails) {
 			email := m.emails[m.emailCursor]
 			m.view = todayEmailContent
-			m.viewport.SetContent(m.renderEmailContent(email))
+			m.finder.Reset(nil)
+			m.finder.SetContent(&m.viewport, m.renderEmailContent(email))
 			m.viewport.GotoTop()
 			accountIdx := m.findAccountForEmail(m.emailCursor)
 
 			// Mark as read - find the right client and update local state
+			var markRead tea.Cmd
 			if email.Unread {
 				// Update local state immediately for responsive UI
 				m.markEmailAsRead(m.emailCursor)
 
+				uid := email.UID
 				if m.serverClient != nil {
-					uid := email.UID
 					accountEmail := m.store.Accounts[accountIdx].Credentials.Email
 					serverClient := m.serverClient
-					go func() {
-						_ = serverClient.MarkRead(accountEmail, "INBOX", uid)
-					}()
+					markRead = m.jobs.start("job.mark_read", func() error {
+						return serverClient.MarkRead(accountEmail, "INBOX", uid)
+					})
+				} else {
+					creds := m.store.Accounts[accountIdx].Credentials
+					markRead = m.jobs.start("job.mark_read", func() error {
+						return withInbox(creds, func(c mail.Client) error { return c.MarkAsRead(uid) })
+					})
 				}
 			}
 
+			// Without a server the email was read with its body
 			if m.serverClient != nil {
-				return m, m.fetchEmailBody(accountIdx, email.UID)
+				return m, tea.Batch(m.fetchEmailBody(accountIdx, email.UID), markRead)
 			}
+			return m, markRead
 		}
 
 	case "r":
@@ -419,6 +661,28 @@ func (m *TodayApp) handleKeyPress(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		}
 		return m, tea.Batch(cmds...)
 
+	case "u":
+		// Email filters: unread, important (AI triage, VIPs and Gmail) and VIP senders
+		m.unreadOnly = !m.unreadOnly
+		m.rebuildEmailList()
+
+	case "i":
+		m.importantOnly = !m.importantOnly
+		m.rebuildEmailList()
+		if m.importantOnly {
+			return m, m.triageEmails()
+		}
+
+	case "v":
+		m.vipOnly = !m.vipOnly
+		m.rebuildEmailList()
+
+	case "1", "2", "3", "4", "5", "6", "7", "8", "9":
+		// Hide or show an account's emails, remembered in the config
+		if i := int(msg.String()[0] - '1'); i < len(m.store.Accounts) {
+			return m, m.toggleAccount(i)
+		}
+
 	case "d":
 		// Delete selected item
 		if m.activePanel == emailPanel && len(m.emails) > 0 {
@@ -446,16 +710,23 @@ func (m *TodayApp) handleDeleteConfirm(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 			email := m.emails[m.emailCursor]
 			uid := email.UID
 			accountIdx := m.findAccountForEmail(m.emailCursor)
+			var deleteCmd tea.Cmd
 			if m.serverClient != nil {
 				accountEmail := m.store.Accounts[accountIdx].Credentials.Email
 				serverClient := m.serverClient
-				go func() {
-					_ = serverClient.QueueDeleteEmail(accountEmail, "INBOX", uid)
-				}()
+				deleteCmd = m.jobs.start("job.delete", func() error {
+					return serverClient.QueueDeleteEmail(accountEmail, "INBOX", uid)
+				})
+			} else {
+				creds := m.store.Accounts[accountIdx].Credentials
+				deleteCmd = m.jobs.start("job.delete", func() error {
+					return withInbox(creds, func(c mail.Client) error { return c.DeleteMessage(uid) })
+				})
 			}
 			// Remove from cache by UID
 			m.removeEmailByUID(uid)
 			m.view = todayDashboard
+			return m, deleteCmd
 		} else if m.activePanel == eventPanel && len(m.events) > 0 && m.eventCursor < len(m.events) {
 			// Delete event
 			event := m.events[m.eventCursor]
@@ -600,6 +871,15 @@ func (m *TodayApp) renderEmailPanel(width, height int) string {
 		titleStyle = titleStyle.Foreground(components.Text)
 	}
 	b.WriteString(titleStyle.Render(fmt.Sprintf("%s (%d)", i18n.T("today.emails_today"), len(m.emails))))
+	filterStyle := lipgloss.NewStyle().Foreground(components.Secondary)
+	for _, f := range []struct {
+		on bool
+		id string
+	}{{m.unreadOnly, "today.filter.unread"}, {m.importantOnly, "today.filter.important"}, {m.vipOnly, "today.filter.vip"}} {
+		if f.on {
+			b.WriteString(filterStyle.Render(" · " + i18n.T(f.id)))
+		}
+	}
 	b.WriteString("\n")
 
 	// Separator line
@@ -607,37 +887,63 @@ func (m *TodayApp) renderEmailPanel(width, height int) string {
 	b.WriteString(separatorStyle.Render(strings.Repeat("─", width-4)))
 	b.WriteString("\n")
 
+	// The accounts, numbered for the keys that hide and show them
+	if len(m.accountEmails) > 1 {
+		var chips []string
+		used := 0
+		for i, account := range m.store.Accounts[:min(len(m.store.Accounts), 9)] {
+			chip := fmt.Sprintf("%d %s", i+1, account.DisplayName())
+			if used += lipgloss.Width(chip) + 2; used > width-2 {
+				break
+			}
+			style := lipgloss.NewStyle().Foreground(components.AccountColor(account.Color, i))
+			if m.filters.HiddenAccount(account.Credentials.Email) {
+				style = lipgloss.NewStyle().Foreground(components.Muted).Strikethrough(true)
+			}
+			chips = append(chips, style.Render(chip))
+		}
+		b.WriteString(strings.Join(chips, "  "))
+		b.WriteString("\n")
+	}
+
+	// The AI triage behind the important filter
+	noteStyle := lipgloss.NewStyle().Foreground(components.Muted).Italic(true)
+	if m.importantOnly && m.triaging {
+		b.WriteString(noteStyle.Render("  " + i18n.T("today.triaging")))
+		b.WriteString("\n")
+	} else if m.importantOnly && m.triageErr != nil {
+		b.WriteString(noteStyle.Render("  " + i18n.T("today.triage_failed", map[string]any{"Error": m.triageErr})))
+		b.WriteString("\n")
+	}
+
 	// Email list grouped by account
 	if len(m.emails) == 0 {
 		emptyStyle := lipgloss.NewStyle().Foreground(components.Muted).Italic(true)
-		b.WriteString(emptyStyle.Render("  " + i18n.T("today.no_emails")))
+		empty := i18n.T("today.no_emails")
+		if m.unreadOnly || m.importantOnly || m.vipOnly {
+			empty = i18n.T("today.no_filtered_emails")
+		}
+		b.WriteString(emptyStyle.Render("  " + empty))
 	} else {
-		// Track global index for cursor
-		globalIdx := 0
-
-		for _, acc := range m.accountEmails {
-			if len(acc.Emails) == 0 {
-				continue
-			}
+		// Render the flattened list the cursor moves through, so an email read while
+		// only unread ones are shown stays where the cursor is until the list is rebuilt
+		for i, email := range m.emails {
+			accIdx := m.emailAccounts[i]
 
 			// Account header (only show if multiple accounts)
-			if len(m.accountEmails) > 1 {
-				accountStyle := lipgloss.NewStyle().Foreground(components.Secondary).Bold(true)
-				b.WriteString(accountStyle.Render(acc.Email))
+			if len(m.accountEmails) > 1 && (i == 0 || m.emailAccounts[i-1] != accIdx) {
+				acc := m.accountEmails[accIdx]
+				b.WriteString(components.RenderAccountBadge(acc.Name, acc.Color))
 				b.WriteString("\n")
 			}
 
-			// Emails for this account
-			for _, email := range acc.Emails {
-				indent := ""
-				if len(m.accountEmails) > 1 {
-					indent = "  " // indent if multiple accounts
-				}
-				line := m.renderCompactEmailLine(email, globalIdx == m.emailCursor, width-4-len(indent))
-				b.WriteString(indent + line)
-				b.WriteString("\n")
-				globalIdx++
+			indent := ""
+			if len(m.accountEmails) > 1 {
+				indent = "  " // indent if multiple accounts
 			}
+			line := m.renderCompactEmailLine(email, i == m.emailCursor, width-4-len(indent))
+			b.WriteString(indent + line)
+			b.WriteString("\n")
 		}
 	}
 
@@ -673,9 +979,7 @@ func (m *TodayApp) renderCompactEmailLine(email mail.Email, isCursor bool, maxWi
 
 	// Truncate subject
 	availWidth := maxWidth - 4 // prefix + padding
-	if len(subject) > availWidth {
-		subject = subject[:availWidth-3] + "..."
-	}
+	subject = utils.TruncateStr(subject, availWidth)
 
 	style := lipgloss.NewStyle().Foreground(components.Text)
 	if isCursor && m.activePanel == emailPanel {
@@ -726,9 +1030,17 @@ func (m *TodayApp) renderEventPanel(width, height int) string {
 func (m *TodayApp) renderEventLine(event calendar.Event, isCursor bool, maxWidth int) string {
 	var b strings.Builder
 
-	// Time on first line
+	// Time on first line; multi-day events show where today falls in them
 	timeStr := event.StartTime.Format("3:04pm")
-	if event.AllDay {
+	day, days := event.DayOf(time.Now())
+	switch {
+	case days > 1 && !event.AllDay && day == 1:
+		timeStr += " →"
+	case days > 1 && !event.AllDay && day == days:
+		timeStr = "→ " + event.EndTime.Format("3:04pm")
+	case days > 1:
+		timeStr = i18n.T("calendar.day_of", map[string]any{"Day": day, "Days": days})
+	case event.AllDay:
 		timeStr = i18n.T("calendar.all_day")
 	}
 
@@ -740,8 +1052,12 @@ func (m *TodayApp) renderEventLine(event calendar.Event, isCursor bool, maxWidth
 		titleStyle = titleStyle.Bold(true).Background(components.Primary)
 	}
 
-	// Time line
+	// Time line, with when to leave for events somewhere else
 	b.WriteString(timeStyle.Render(timeStr))
+	if leave, ok := m.leaveIn(event, time.Now()); ok {
+		b.WriteString(" ")
+		b.WriteString(lipgloss.NewStyle().Foreground(components.Warning).Render(leave))
+	}
 	b.WriteString("\n")
 
 	// Title line (indented)
@@ -753,9 +1069,7 @@ func (m *TodayApp) renderEventLine(event calendar.Event, isCursor bool, maxWidth
 	// Truncate title
 	title := event.Title
 	availWidth := maxWidth - 3
-	if len(title) > availWidth {
-		title = title[:availWidth-3] + "..."
-	}
+	title = utils.TruncateStr(title, availWidth)
 
 	b.WriteString(prefix)
 	b.WriteString(titleStyle.Render(title))
@@ -763,6 +1077,20 @@ func (m *TodayApp) renderEventLine(event calendar.Event, isCursor bool, maxWidth
 	return b.String()
 }
 
+// leaveIn says how soon to leave for an upcoming event that takes travel to get to,
+// e.g. "leave in 20 min"
+func (m *TodayApp) leaveIn(event calendar.Event, now time.Time) (string, bool) {
+	travel := travelTime(m.settings, event.Location)
+	if travel == 0 || event.AllDay || !event.StartTime.After(now) {
+		return "", false
+	}
+	wait := event.StartTime.Add(-travel).Sub(now)
+	if wait <= 0 {
+		return i18n.T("today.leave_now"), true
+	}
+	return i18n.T("today.leave_in", map[string]any{"Minutes": int(wait.Round(time.Minute) / time.Minute)}), true
+}
+
 func (m *TodayApp) renderHelpBar() string {
 	helpStyle := lipgloss.NewStyle().Foreground(components.Muted).Padding(1, 2)
 	keyStyle := lipgloss.NewStyle().Bold(true).Foreground(components.Secondary)
@@ -776,9 +1104,14 @@ func (m *TodayApp) renderHelpBar() string {
 		key("d", i18n.T("help.delete")),
 	}
 
-	// Show edit only for events panel
+	// Show edit only for events panel, the email filters for the email panel
 	if m.activePanel == eventPanel {
 		items = append(items, key("e", i18n.T("help.edit")))
+	} else {
+		items = append(items, key("u/i/v", i18n.T("today.filters")))
+		if len(m.store.Accounts) > 1 {
+			items = append(items, key("1-9", i18n.T("today.accounts")))
+		}
 	}
 
 	items = append(items,
@@ -786,7 +1119,7 @@ func (m *TodayApp) renderHelpBar() string {
 		key("q", i18n.T("help.quit")),
 	)
 
-	return helpStyle.Render(strings.Join(items, "  "))
+	return helpStyle.Render(strings.Join(items, "  ") + "  " + components.RenderJobStatus(m.jobs.status(), m.spinner.View()))
 }
 
 func (m *TodayApp) renderEmailView() string {
@@ -805,7 +1138,7 @@ func (m *TodayApp) renderEmailView() string {
 		labelStyle.Render(i18n.T("today.from")), fromStyle.Render(email.From),
 		labelStyle.Render(i18n.T("today.to")), email.To,
 		labelStyle.Render(i18n.T("today.subject")), subjectStyle.Render(email.Subject),
-		labelStyle.Render(i18n.T("today.date")), email.Date.Format("Mon, Jan 2, 2006 3:04 PM"),
+		labelStyle.Render(i18n.T("today.date")), i18n.FormatDateTime(email.Date),
 	))
 
 	// Separator
@@ -817,7 +1150,11 @@ func (m *TodayApp) renderEmailView() string {
 	helpStyle := lipgloss.NewStyle().Foreground(components.Muted).Padding(0, 2)
 	keyStyle := lipgloss.NewStyle().Bold(true).Foreground(components.Secondary)
 	key := func(k, label string) string { return fmt.Sprintf("%s %s", keyStyle.Render(k), label) }
-	help := helpStyle.Render(fmt.Sprintf("%s  %s  %s  %s", key("esc", i18n.T("help.back")), key("↑↓", i18n.T("today.scroll")), key("d", i18n.T("help.delete")), key("q", i18n.T("help.quit"))))
+	help := helpStyle.Render(fmt.Sprintf("%s  %s  %s  %s  %s  %s", key("/", i18n.T("help.find")), key("esc", i18n.T("help.back")), key("↑↓", i18n.T("today.scroll")), key("d", i18n.T("help.delete")), key("q", i18n.T("help.quit")),
+		components.RenderJobStatus(m.jobs.status(), m.spinner.View())))
+	if find := m.finder.View(); find != "" {
+		help = helpStyle.Render(find)
+	}
 
 	return lipgloss.JoinVertical(
 		lipgloss.Left,
@@ -846,6 +1183,20 @@ func (m *TodayApp) renderEmailContent(email mail.Email) string {
 
 // Helper functions for delete/edit
 
+// withInbox runs op on a connection of its own to the account's inbox, for when no
+// server is running
+func withInbox(creds auth.Credentials, op func(mail.Client) error) error {
+	c, err := mail.NewClient(&creds)
+	if err != nil {
+		return err
+	}
+	defer c.Close()
+	if err := c.SelectMailbox("INBOX"); err != nil {
+		return err
+	}
+	return op(c)
+}
+
 func (m *TodayApp) fetchEmailBody(accountIdx int, uid imap.UID) tea.Cmd {
 	serverClient := m.serverClient
 	accountEmail := m.store.Accounts[accountIdx].Credentials.Email
@@ -910,20 +1261,20 @@ func (m *TodayApp) removeEmailByUID(uid imap.UID) {
 }
 
 func (m *TodayApp) markEmailAsRead(emailIdx int) {
-	// Update in the flattened list
-	if emailIdx >= 0 && emailIdx < len(m.emails) {
-		m.emails[emailIdx].Unread = false
+	if emailIdx < 0 || emailIdx >= len(m.emails) {
+		return
 	}
 
+	// Update in the flattened list, which keeps the email until it's next rebuilt even
+	// when only unread emails are shown
+	m.emails[emailIdx].Unread = false
+
 	// Also update in the per-account list
-	idx := 0
-	for i := range m.accountEmails {
-		for j := range m.accountEmails[i].Emails {
-			if idx == emailIdx {
-				m.accountEmails[i].Emails[j].Unread = false
-				return
-			}
-			idx++
+	emails := m.accountEmails[m.findAccountForEmail(emailIdx)].Emails
+	for j := range emails {
+		if emails[j].UID == m.emails[emailIdx].UID {
+			emails[j].Unread = false
+			return
 		}
 	}
 }
@@ -943,9 +1294,8 @@ func (m *TodayApp) initEditEventForm(event calendar.Event) {
 	m.editFormTitle.SetValue(event.Title)
 	m.editFormTitle.Focus()
 
-	m.editFormDate = textinput.New()
-	m.editFormDate.Placeholder = "YYYY-MM-DD"
-	m.editFormDate.SetValue(event.StartTime.Format("2006-01-02"))
+	m.editFormDate = components.NewDatePicker()
+	m.editFormDate.SetDate(event.StartTime)
 
 	m.editFormStart = textinput.New()
 	m.editFormStart.Placeholder = "HH:MM"
@@ -956,11 +1306,11 @@ func (m *TodayApp) initEditEventForm(event calendar.Event) {
 	m.editFormEnd.SetValue(event.EndTime.Format("15:04"))
 
 	m.editFormLocation = textinput.New()
-	m.editFormLocation.Placeholder = "Location (optional)"
+	m.editFormLocation.Placeholder = i18n.T("extract.placeholder.location")
 	m.editFormLocation.SetValue(event.Location)
 
 	m.editFormNotes = textarea.New()
-	m.editFormNotes.Placeholder = "Notes (optional)"
+	m.editFormNotes.Placeholder = i18n.T("extract.placeholder.notes")
 	m.editFormNotes.SetValue(event.Notes)
 	m.editFormNotes.SetWidth(40)
 	m.editFormNotes.SetHeight(6)
@@ -968,6 +1318,8 @@ func (m *TodayApp) initEditEventForm(event calendar.Event) {
 
 	m.editFormFocus = 0
 	m.editEventID = event.ID
+	_, m.editEventDays = event.DayOf(event.StartTime)
+	m.editEventAllDay = event.AllDay
 }
 
 func (m *TodayApp) updateEditFormFocus() {
@@ -996,10 +1348,7 @@ func (m *TodayApp) updateEditFormFocus() {
 
 func (m *TodayApp) saveEditedEvent() tea.Cmd {
 	return func() tea.Msg {
-		date, err := time.Parse("2006-01-02", m.editFormDate.Value())
-		if err != nil {
-			return todayErrMsg{fmt.Errorf("invalid date: %v", err)}
-		}
+		date := m.editFormDate.Value()
 
 		startTime, err := time.Parse("15:04", m.editFormStart.Value())
 		if err != nil {
@@ -1013,8 +1362,12 @@ func (m *TodayApp) saveEditedEvent() tea.Cmd {
 
 		start := time.Date(date.Year(), date.Month(), date.Day(),
 			startTime.Hour(), startTime.Minute(), 0, 0, time.Local)
-		end := time.Date(date.Year(), date.Month(), date.Day(),
+		end := time.Date(date.Year(), date.Month(), date.Day()+m.editEventDays-1,
 			endTime.Hour(), endTime.Minute(), 0, 0, time.Local)
+		if m.editEventAllDay {
+			start = time.Date(date.Year(), date.Month(), date.Day(), 0, 0, 0, 0, time.Local)
+			end = time.Date(date.Year(), date.Month(), date.Day()+m.editEventDays-1, 23, 59, 59, 0, time.Local)
+		}
 
 		// Delete old event first (EventKit doesn't have update)
 		if m.editEventID != "" {
@@ -1025,6 +1378,7 @@ func (m *TodayApp) saveEditedEvent() tea.Cmd {
 			Title:     m.editFormTitle.Value(),
 			StartTime: start,
 			EndTime:   end,
+			AllDay:    m.editEventAllDay,
 			Location:  m.editFormLocation.Value(),
 			Notes:     m.editFormNotes.Value(),
 		}
@@ -1093,8 +1447,7 @@ func (m *TodayApp) renderEditEventForm() string {
 	if m.editFormFocus == 1 {
 		label = focusedStyle.Render(label)
 	}
-	b.WriteString(labelStyle.Render(label))
-	b.WriteString(m.editFormDate.View())
+	b.WriteString(lipgloss.JoinHorizontal(lipgloss.Top, labelStyle.Render(label), datePickerView(m.editFormDate)))
 	b.WriteString("\n")
 
 	// Start time
@@ -0,0 +1,18 @@
+package utils
+
+import (
+	"os"
+
+	"github.com/atotto/clipboard"
+	"github.com/aymanbagabas/go-osc52/v2"
+)
+
+// CopyToClipboard puts text on the system clipboard. Without a clipboard tool, as over
+// SSH, it asks the terminal to do it with an OSC 52 escape sequence instead.
+func CopyToClipboard(text string) error {
+	if err := clipboard.WriteAll(text); err == nil {
+		return nil
+	}
+	_, err := osc52.New(text).WriteTo(os.Stderr)
+	return err
+}
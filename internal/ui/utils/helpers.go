@@ -2,29 +2,21 @@ package utils
 
 import (
 	"strings"
-	"time"
 
 	"github.com/charmbracelet/lipgloss"
+	"github.com/mattn/go-runewidth"
 )
 
-// TruncateStr truncates a string to maxLen visual width using unicode ellipsis
+// TruncateStr truncates a string to maxLen visual width using unicode ellipsis, so wide
+// characters such as CJK and emoji count as the two columns they take up
 func TruncateStr(s string, maxLen int) string {
-	width := lipgloss.Width(s)
-	if width <= maxLen {
-		return s
-	}
 	if maxLen <= 1 {
-		return "…"
-	}
-	// Truncate rune by rune until we fit
-	runes := []rune(s)
-	for i := len(runes) - 1; i >= 0; i-- {
-		truncated := string(runes[:i]) + "…"
-		if lipgloss.Width(truncated) <= maxLen {
-			return truncated
+		if runewidth.StringWidth(s) <= maxLen {
+			return s
 		}
+		return "…"
 	}
-	return "…"
+	return runewidth.Truncate(s, maxLen, "…")
 }
 
 func ExtractNameFromEmail(from string) string {
@@ -34,17 +26,6 @@ func ExtractNameFromEmail(from string) string {
 	return from
 }
 
-func FormatEmailDate(t time.Time) string {
-	now := time.Now()
-	if t.Year() == now.Year() && t.YearDay() == now.YearDay() {
-		return t.Format("15:04")
-	}
-	if t.Year() == now.Year() {
-		return t.Format("Jan 02")
-	}
-	return t.Format("02/01/06")
-}
-
 // PadRight pads a string to targetWidth using visual width (handles double-width chars)
 func PadRight(s string, targetWidth int) string {
 	currentWidth := lipgloss.Width(s)
@@ -53,3 +34,8 @@ func PadRight(s string, targetWidth int) string {
 	}
 	return s + strings.Repeat(" ", targetWidth-currentWidth)
 }
+
+// FitWidth truncates or pads a string to exactly width columns
+func FitWidth(s string, width int) string {
+	return PadRight(TruncateStr(s, width), width)
+}